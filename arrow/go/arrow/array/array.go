@@ -14,14 +14,16 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package array // import "github.com/apache/arrow/go/arrow/array"
+package array // import "github.com/gomem/gomem/arrow/go/arrow/array"
 
 import (
+	"errors"
+	"fmt"
 	"sync/atomic"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/bitutil"
-	"github.com/apache/arrow/go/arrow/internal/debug"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/debug"
 )
 
 // Interface 表示一个不可变的值序列，可以视作一个 Array 。
@@ -71,6 +73,35 @@ const (
 	UnknownNullCount = -1
 )
 
+// ErrIndexOutOfRange is reported, through an *IndexError, by the checked
+// accessors of arrays given an index outside of the array.
+var ErrIndexOutOfRange = errors.New("arrow/array: index out of range")
+
+// IndexError reports an index outside of an array of Len elements. It
+// matches ErrIndexOutOfRange with errors.Is.
+type IndexError struct {
+	Index int
+	Len   int
+}
+
+func (e *IndexError) Error() string {
+	return fmt.Sprintf("arrow/array: index %d out of range [0, %d)", e.Index, e.Len)
+}
+
+// Is reports whether target is ErrIndexOutOfRange.
+func (e *IndexError) Is(target error) bool { return target == ErrIndexOutOfRange }
+
+// CheckIndex returns an *IndexError when i is not the index of an element of
+// arr. Code handling indexes it does not control, such as row numbers sent by
+// users, checks them with CheckIndex or with the CheckedValue methods of the
+// arrays instead of letting the accessors panic.
+func CheckIndex(arr Interface, i int) error {
+	if i < 0 || i >= arr.Len() {
+		return &IndexError{Index: i, Len: arr.Len()}
+	}
+	return nil
+}
+
 // array 作为一个内部结构，用来封装 *Data ，把 GC 和 null bit map 的公共逻辑从 Data 中剥离出去。
 type array struct {
 	refCount        int64  // 引用计数
@@ -209,13 +240,11 @@ func init() {
 		arrow.LIST:              func(data *Data) Interface { return NewListData(data) },
 		arrow.STRUCT:            func(data *Data) Interface { return NewStructData(data) },
 		arrow.UNION:             unsupportedArrayType,
-		arrow.DICTIONARY:        unsupportedArrayType,
+		arrow.DICTIONARY:        func(data *Data) Interface { return NewDictionaryData(data) },
 		arrow.MAP:               unsupportedArrayType,
 		arrow.EXTENSION:         unsupportedArrayType,
 		arrow.FIXED_SIZE_LIST:   func(data *Data) Interface { return NewFixedSizeListData(data) },
 		arrow.DURATION:          func(data *Data) Interface { return NewDurationData(data) },
-
-		// invalid data types to fill out array size 2⁵-1
-		31: invalidDataType,
+		arrow.RUN_END_ENCODED:   func(data *Data) Interface { return NewRunEndEncodedData(data) },
 	}
 }
@@ -22,7 +22,7 @@ import (
 	"strings"
 	"unsafe"
 
-	"github.com/apache/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow"
 )
 
 // A type which represents an immutable sequence of variable-length binary strings.
@@ -49,6 +49,15 @@ func (a *Binary) Value(i int) []byte {
 	return a.bytes[a.offsets[idx]:a.offsets[idx+1]]
 }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Binary) CheckedValue(i int) ([]byte, error) {
+	if err := CheckIndex(a, i); err != nil {
+		return nil, err
+	}
+	return a.Value(i), nil
+}
+
 // ValueString returns the string at index i without performing additional allocations.
 // The string is only valid for the lifetime of the Binary array.
 func (a *Binary) ValueString(i int) string {
@@ -20,9 +20,9 @@ import (
 	"math"
 	"sync/atomic"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/internal/debug"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/debug"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 )
 
 const (
@@ -194,7 +194,12 @@ func (b *BinaryBuilder) NewBinaryArray() (a *Binary) {
 }
 
 func (b *BinaryBuilder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	b.appendNextOffset()
+	if b.shrink {
+		b.offsets.shrinkToFit()
+		b.values.shrinkToFit()
+	}
 
 	offsets := b.offsets.Finish() // 取底层数组
 	values := b.values.Finish()   // 取底层数组
@@ -207,6 +212,7 @@ func (b *BinaryBuilder) newData() (data *Data) {
 		b.nulls,
 		0,
 	)
+	b.builder.attachStatistics(data)
 
 	if offsets != nil {
 		offsets.Release()
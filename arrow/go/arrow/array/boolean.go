@@ -20,9 +20,9 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/bitutil"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 )
 
 // A type which represents an immutable sequence of boolean values.
@@ -61,6 +61,15 @@ func (a *Boolean) Value(i int) bool {
 	return bitutil.BitIsSet(a.values, a.array.data.offset+i)
 }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Boolean) CheckedValue(i int) (bool, error) {
+	if err := CheckIndex(a, i); err != nil {
+		return false, err
+	}
+	return a.Value(i), nil
+}
+
 func (a *Boolean) String() string {
 	o := new(strings.Builder)
 	o.WriteString("[")
@@ -19,10 +19,10 @@ package array
 import (
 	"sync/atomic"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/bitutil"
-	"github.com/apache/arrow/go/arrow/internal/debug"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/debug"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 )
 
 type BooleanBuilder struct {
@@ -161,6 +161,7 @@ func (b *BooleanBuilder) NewBooleanArray() (a *Boolean) {
 }
 
 func (b *BooleanBuilder) newData() *Data {
+	b.builder.shrinkBitmap()
 	// 计算 n 个 boolean 需要占用多少个 bytes
 	bytesRequired := arrow.BooleanTraits.BytesRequired(b.length)
 	// 缩减 data buffer
@@ -178,6 +179,7 @@ func (b *BooleanBuilder) newData() *Data {
 		b.nulls,
 		0,
 	)
+	b.builder.attachStatistics(res)
 
 	// reset `nullBitmap`
 	b.reset()
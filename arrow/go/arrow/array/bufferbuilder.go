@@ -19,9 +19,9 @@ package array
 import (
 	"sync/atomic"
 
-	"github.com/apache/arrow/go/arrow/bitutil"
-	"github.com/apache/arrow/go/arrow/internal/debug"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/debug"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 )
 
 // bufferBuilder 可以认为是支持自动扩容的 []byte 数组。
@@ -128,6 +128,15 @@ func (b *bufferBuilder) Finish() (buffer *memory.Buffer) {
 	return
 }
 
+// shrinkToFit trims the capacity of the buffer to its length.
+func (b *bufferBuilder) shrinkToFit() {
+	if b.buffer != nil && b.length > 0 && b.length < b.capacity {
+		b.buffer.Resize(b.length)
+		b.capacity = b.buffer.Cap()
+		b.bytes = b.buffer.Buf()
+	}
+}
+
 func (b *bufferBuilder) unsafeAppend(data []byte) {
 	copy(b.bytes[b.length:], data)
 	b.length += len(data)
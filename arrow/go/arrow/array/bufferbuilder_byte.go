@@ -16,7 +16,7 @@
 
 package array
 
-import "github.com/apache/arrow/go/arrow/memory"
+import "github.com/gomem/gomem/arrow/go/arrow/memory"
 
 type byteBufferBuilder struct {
 	bufferBuilder
@@ -19,9 +19,9 @@
 package array
 
 import (
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/bitutil"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 )
 
 
@@ -20,9 +20,9 @@ import (
 	"fmt"
 	"sync/atomic"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/bitutil"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 )
 
 const (
@@ -68,6 +68,18 @@ type Builder interface {
 	// 从 memory buffers 中构造一个 arrow array ，构造完后会重置 builder 以便复用。
 	NewArray() Interface
 
+	// SetSizeHint tells the builder how many elements the arrays it builds are
+	// expected to hold, see builder.SetSizeHint.
+	SetSizeHint(n int)
+
+	// SetShrinkToFit makes the builder trim its buffers to their length when an
+	// array is built, see builder.SetShrinkToFit.
+	SetShrinkToFit(shrink bool)
+
+	// SetStatistics makes the builder compute the statistics of the arrays it
+	// builds, see builder.SetStatistics.
+	SetStatistics(enable bool)
+
 	init(capacity int)
 	resize(newBits int, init func(int))
 }
@@ -82,6 +94,9 @@ type builder struct {
 	nulls      int              // 空元素计数
 	length     int              // 长度
 	capacity   int              // 容量
+	sizeHint   int              // 预期元素个数，reserve 在此范围内按需精确分配
+	shrink     bool             // 构造 array 时是否将 buffers 缩容到实际长度
+	stats      bool             // 构造 array 时是否计算并缓存统计信息
 }
 
 // Retain increases the reference count by 1.
@@ -99,6 +114,24 @@ func (b *builder) Cap() int { return b.capacity }
 // NullN returns the number of null values in the array builder.
 func (b *builder) NullN() int { return b.nulls }
 
+// SetSizeHint tells the builder how many elements the arrays it builds are
+// expected to hold. Growing the builder within the hint allocates room for
+// exactly n elements at once, rather than for the next power of two; growing
+// past the hint falls back to doubling. The hint is kept across NewArray calls,
+// zero removes it.
+func (b *builder) SetSizeHint(n int) { b.sizeHint = n }
+
+// SetShrinkToFit makes the builder trim the capacity of its buffers to their
+// length when an array is built, so that the array does not keep the slack
+// left by growing the builder.
+func (b *builder) SetShrinkToFit(shrink bool) { b.shrink = shrink }
+
+// SetStatistics makes the builder compute the statistics of the arrays it
+// builds and cache them on their data, where Statistics finds them. Only the
+// numeric, temporal, boolean, binary and string builders compute statistics,
+// the others ignore the setting.
+func (b *builder) SetStatistics(enable bool) { b.stats = enable }
+
 // 首先通过 bitutil.CeilByte(capacity) / 8 计算出需要分配的空间大小，并将其赋值给 toAlloc 变量
 // 然后调用 memory.NewResizableBuffer(b.mem) 创建一个新的可调整大小的缓冲区，并将其赋值给 nullBitmap
 // 接着调用 nullBitmap.Resize(toAlloc) 方法将 nullBitmap 缓冲区的大小调整为 toAlloc
@@ -144,14 +177,35 @@ func (b *builder) resize(newBits int, init func(int)) {
 	}
 }
 
-// 如果新增 elements 个元素会导致超过容量，则进行 2 倍扩容。
+// 如果新增 elements 个元素会导致超过容量，则进行 2 倍扩容；
+// 若未超过 sizeHint ，则直接扩容到 sizeHint 。
 func (b *builder) reserve(elements int, resize func(int)) {
 	if b.length+elements > b.capacity {
-		newCap := bitutil.NextPowerOf2(b.length + elements)
+		newCap := b.sizeHint
+		if b.length+elements > newCap {
+			newCap = bitutil.NextPowerOf2(b.length + elements)
+		}
 		resize(newCap)
 	}
 }
 
+// shrinkBitmap trims the validity bitmap to the length of the builder when
+// shrinking is enabled. It is called by newData before the bitmap is handed
+// over to the array.
+func (b *builder) shrinkBitmap() {
+	if b.shrink && b.nullBitmap != nil && b.length > 0 {
+		b.nullBitmap.Resize(bitutil.CeilByte(b.length) / 8)
+	}
+}
+
+// attachStatistics caches the statistics of data on it when statistics are
+// enabled. It is called by newData once the data is built.
+func (b *builder) attachStatistics(data *Data) {
+	if b.stats {
+		data.setStatistics(computeStats(data))
+	}
+}
+
 // unsafeAppendBoolsToBitmap appends the contents of valid to the validity bitmap.
 // As an optimization, if the valid slice is empty, the next length bits will be set to valid (not null).
 func (b *builder) unsafeAppendBoolsToBitmap(valid []bool, length int) {
@@ -281,6 +335,8 @@ func NewBuilder(mem memory.Allocator, dtype arrow.DataType) Builder {
 			return NewDayTimeIntervalBuilder(mem)
 		case *arrow.MonthIntervalType:
 			return NewMonthIntervalBuilder(mem)
+		case *arrow.MonthDayNanoIntervalType:
+			return NewMonthDayNanoIntervalBuilder(mem)
 		}
 	case arrow.DECIMAL:
 		if typ, ok := dtype.(*arrow.Decimal128Type); ok {
@@ -302,6 +358,9 @@ func NewBuilder(mem memory.Allocator, dtype arrow.DataType) Builder {
 	case arrow.DURATION:
 		typ := dtype.(*arrow.DurationType)
 		return NewDurationBuilder(mem, typ)
+	case arrow.RUN_END_ENCODED:
+		typ := dtype.(*arrow.RunEndEncodedType)
+		return NewRunEndEncodedBuilder(mem, typ.ValueType)
 	}
 	panic(fmt.Errorf("arrow/array: unsupported builder for %T", dtype))
 }
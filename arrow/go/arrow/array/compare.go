@@ -19,8 +19,8 @@ package array
 import (
 	"math"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/float16"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/float16"
 	"golang.org/x/xerrors"
 )
 
@@ -146,6 +146,12 @@ func ArrayEqual(left, right Interface) bool {
 	case *List:
 		r := right.(*List)
 		return arrayEqualList(l, r)
+	case *Dictionary:
+		r := right.(*Dictionary)
+		return arrayEqualDictionary(l, r)
+	case *RunEndEncoded:
+		r := right.(*RunEndEncoded)
+		return arrayEqualRunEndEncoded(l, r)
 	case *FixedSizeList:
 		r := right.(*FixedSizeList)
 		return arrayEqualFixedSizeList(l, r)
@@ -158,6 +164,9 @@ func ArrayEqual(left, right Interface) bool {
 	case *DayTimeInterval:
 		r := right.(*DayTimeInterval)
 		return arrayEqualDayTimeInterval(l, r)
+	case *MonthDayNanoInterval:
+		r := right.(*MonthDayNanoInterval)
+		return arrayEqualMonthDayNanoInterval(l, r)
 	case *Duration:
 		r := right.(*Duration)
 		return arrayEqualDuration(l, r)
@@ -347,6 +356,9 @@ func arrayApproxEqual(left, right Interface, opt equalOption) bool {
 	case *DayTimeInterval:
 		r := right.(*DayTimeInterval)
 		return arrayEqualDayTimeInterval(l, r)
+	case *MonthDayNanoInterval:
+		r := right.(*MonthDayNanoInterval)
+		return arrayEqualMonthDayNanoInterval(l, r)
 	case *Duration:
 		r := right.(*Duration)
 		return arrayEqualDuration(l, r)
@@ -354,8 +366,6 @@ func arrayApproxEqual(left, right Interface, opt equalOption) bool {
 	default:
 		panic(xerrors.Errorf("arrow/array: unknown array type %T", l))
 	}
-
-	return false
 }
 
 func baseArrayEqual(left, right Interface) bool {
@@ -18,10 +18,11 @@ package array
 
 import (
 	"sync/atomic"
+	"unsafe"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/internal/debug"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/debug"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 )
 
 // Data represents the memory and metadata of an Arrow array.
@@ -33,6 +34,7 @@ type Data struct {
 	length    int
 	buffers   []*memory.Buffer // TODO(sgc): should this be an interface?
 	childData []*Data          // TODO(sgc): managed by ListArray, StructArray and UnionArray types
+	stats     unsafe.Pointer   // *Stats 缓存的统计信息，见 ComputeStatistics
 }
 
 // NewData creates a new Data.
@@ -98,6 +100,7 @@ func (d *Data) Reset(typ arrow.DataType, length int, buffers []*memory.Buffer, c
 	d.length = length
 	d.nulls = nulls
 	d.offset = offset
+	atomic.StorePointer(&d.stats, nil)
 }
 
 // Retain increases the reference count by 1.
@@ -141,6 +144,9 @@ func (d *Data) Offset() int { return d.offset }
 // Buffers returns the buffers.
 func (d *Data) Buffers() []*memory.Buffer { return d.buffers }
 
+// Children returns the child data of nested, dictionary and run-end encoded arrays.
+func (d *Data) Children() []*Data { return d.childData }
+
 // NewSliceData returns a new slice that shares backing data with the input.
 // The returned Data slice starts at i and extends j-i elements, such as:
 //    slice := data[i:j]
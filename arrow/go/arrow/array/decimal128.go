@@ -14,18 +14,18 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package array // import "github.com/apache/arrow/go/arrow/array"
+package array // import "github.com/gomem/gomem/arrow/go/arrow/array"
 
 import (
 	"fmt"
 	"strings"
 	"sync/atomic"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/bitutil"
-	"github.com/apache/arrow/go/arrow/decimal128"
-	"github.com/apache/arrow/go/arrow/internal/debug"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/decimal128"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/debug"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 )
 
 // A type which represents an immutable sequence of 128-bit decimal values.
@@ -44,6 +44,15 @@ func NewDecimal128Data(data *Data) *Decimal128 {
 
 func (a *Decimal128) Value(i int) decimal128.Num { return a.values[i] }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Decimal128) CheckedValue(i int) (decimal128.Num, error) {
+	if err := CheckIndex(a, i); err != nil {
+		return decimal128.Num{}, err
+	}
+	return a.Value(i), nil
+}
+
 func (a *Decimal128) Values() []decimal128.Num { return a.values }
 
 func (a *Decimal128) String() string {
@@ -212,6 +221,7 @@ func (b *Decimal128Builder) NewDecimal128Array() (a *Decimal128) {
 }
 
 func (b *Decimal128Builder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	bytesRequired := arrow.Decimal128Traits.BytesRequired(b.length)
 	if bytesRequired > 0 && bytesRequired < b.data.Len() {
 		// trim buffers
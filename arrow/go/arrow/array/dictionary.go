@@ -0,0 +1,162 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package array
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"golang.org/x/xerrors"
+)
+
+// Dictionary represents an immutable sequence of indices into a dictionary of values.
+//
+// The indices are stored in the buffers of the array data, the dictionary
+// values are stored as its only child data.
+// 字典数组：buffers 保存 indices，childData[0] 保存 dictionary 。
+type Dictionary struct {
+	array
+	indices Interface
+	dict    Interface
+}
+
+// NewDictionaryArray returns a new Dictionary array combining indices and dict.
+// The indices must be an integer array matching typ.IndexType and dict must
+// hold values of typ.ValueType.
+func NewDictionaryArray(typ *arrow.DictionaryType, indices, dict Interface) *Dictionary {
+	switch {
+	case !arrow.TypeEqual(indices.DataType(), typ.IndexType):
+		panic(xerrors.Errorf("arrow/array: dictionary indices of type %s do not match %s", indices.DataType(), typ.IndexType))
+	case !arrow.TypeEqual(dict.DataType(), typ.ValueType):
+		panic(xerrors.Errorf("arrow/array: dictionary values of type %s do not match %s", dict.DataType(), typ.ValueType))
+	}
+
+	idx := indices.Data()
+	data := NewData(typ, idx.Len(), idx.Buffers(), []*Data{dict.Data()}, idx.NullN(), idx.Offset())
+	defer data.Release()
+	return NewDictionaryData(data)
+}
+
+// NewDictionaryData returns a new Dictionary array value, from data.
+func NewDictionaryData(data *Data) *Dictionary {
+	a := &Dictionary{}
+	a.refCount = 1
+	a.setData(data)
+	return a
+}
+
+// Indices returns the array of indices into the dictionary.
+func (a *Dictionary) Indices() Interface { return a.indices }
+
+// Dictionary returns the array of dictionary values.
+func (a *Dictionary) Dictionary() Interface { return a.dict }
+
+// GetValueIndex returns the dictionary index of the i-th element.
+func (a *Dictionary) GetValueIndex(i int) int {
+	switch idx := a.indices.(type) {
+	case *Int8:
+		return int(idx.Value(i))
+	case *Int16:
+		return int(idx.Value(i))
+	case *Int32:
+		return int(idx.Value(i))
+	case *Int64:
+		return int(idx.Value(i))
+	case *Uint8:
+		return int(idx.Value(i))
+	case *Uint16:
+		return int(idx.Value(i))
+	case *Uint32:
+		return int(idx.Value(i))
+	case *Uint64:
+		return int(idx.Value(i))
+	default:
+		panic(xerrors.Errorf("arrow/array: invalid dictionary index type %T", a.indices))
+	}
+}
+
+func (a *Dictionary) String() string {
+	o := new(strings.Builder)
+	o.WriteString("[")
+	for i := 0; i < a.Len(); i++ {
+		if i > 0 {
+			o.WriteString(" ")
+		}
+		if !a.IsValid(i) {
+			o.WriteString("(null)")
+			continue
+		}
+		v := a.newDictionaryValue(i)
+		str := fmt.Sprintf("%v", v)
+		v.Release()
+		// the value is formatted as a single element array: [v]
+		o.WriteString(str[1 : len(str)-1])
+	}
+	o.WriteString("]")
+	return o.String()
+}
+
+// newDictionaryValue returns the dictionary value of the i-th element as a single element array.
+func (a *Dictionary) newDictionaryValue(i int) Interface {
+	j := int64(a.GetValueIndex(i))
+	return NewSlice(a.dict, j, j+1)
+}
+
+func (a *Dictionary) setData(data *Data) {
+	a.array.setData(data)
+	typ := data.dtype.(*arrow.DictionaryType)
+	idx := NewData(typ.IndexType, data.length, data.buffers, nil, data.nulls, data.offset)
+	defer idx.Release()
+	a.indices = MakeFromData(idx)
+	a.dict = MakeFromData(data.childData[0])
+}
+
+func (a *Dictionary) Retain() {
+	a.array.Retain()
+	a.indices.Retain()
+	a.dict.Retain()
+}
+
+func (a *Dictionary) Release() {
+	a.array.Release()
+	a.indices.Release()
+	a.dict.Release()
+}
+
+func arrayEqualDictionary(left, right *Dictionary) bool {
+	for i := 0; i < left.Len(); i++ {
+		if left.IsNull(i) {
+			continue
+		}
+		o := func() bool {
+			l := left.newDictionaryValue(i)
+			defer l.Release()
+			r := right.newDictionaryValue(i)
+			defer r.Release()
+			return ArrayEqual(l, r)
+		}()
+		if !o {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	_ Interface = (*Dictionary)(nil)
+)
@@ -0,0 +1,331 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package array
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/debug"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"golang.org/x/xerrors"
+)
+
+// RunEndEncoded represents an immutable sequence of runs of repeated values.
+//
+// The array data has no buffers. Its first child data holds the logical end
+// of each run, its second child data holds the value of each run.
+// A run whose value is null makes all the elements of the run null.
+// 游程编码数组：childData[0] 保存每个游程的结束位置，childData[1] 保存每个游程的值。
+type RunEndEncoded struct {
+	array
+	ends   Interface
+	values Interface
+	endAt  func(j int) int
+}
+
+// NewRunEndEncodedArray returns a new RunEndEncoded array of the given logical
+// length and offset. The run ends must be strictly increasing and values must
+// hold one element per run.
+func NewRunEndEncodedArray(runEnds, values Interface, length, offset int) *RunEndEncoded {
+	switch {
+	case runEnds.Len() != values.Len():
+		panic(xerrors.Errorf("arrow/array: %d run ends for %d values", runEnds.Len(), values.Len()))
+	case runEnds.NullN() != 0:
+		panic(xerrors.New("arrow/array: run ends must not be null"))
+	}
+
+	typ := &arrow.RunEndEncodedType{RunEndType: runEnds.DataType(), ValueType: values.DataType()}
+
+	nulls := runNullCount(values, runEndGetter(runEnds), offset, length)
+
+	data := NewData(typ, length, []*memory.Buffer{nil}, []*Data{runEnds.Data(), values.Data()}, nulls, offset)
+	defer data.Release()
+	return NewRunEndEncodedData(data)
+}
+
+// NewRunEndEncodedData returns a new RunEndEncoded array value, from data.
+func NewRunEndEncodedData(data *Data) *RunEndEncoded {
+	a := &RunEndEncoded{}
+	a.refCount = 1
+	a.setData(data)
+	return a
+}
+
+// RunEnds returns the array of run ends.
+func (a *RunEndEncoded) RunEnds() Interface { return a.ends }
+
+// Values returns the array of run values.
+func (a *RunEndEncoded) Values() Interface { return a.values }
+
+// GetPhysicalIndex returns the index of the run holding the i-th element.
+func (a *RunEndEncoded) GetPhysicalIndex(i int) int {
+	pos := a.data.offset + i
+	return sort.Search(a.ends.Len(), func(j int) bool { return a.endAt(j) > pos })
+}
+
+// PhysicalOffset returns the index of the run holding the first element.
+func (a *RunEndEncoded) PhysicalOffset() int { return a.GetPhysicalIndex(0) }
+
+// PhysicalLength returns the number of runs spanned by the array.
+func (a *RunEndEncoded) PhysicalLength() int {
+	if a.Len() == 0 {
+		return 0
+	}
+	return a.GetPhysicalIndex(a.Len()-1) - a.PhysicalOffset() + 1
+}
+
+// RunEnd returns the end of the j-th run relative to the start of the array,
+// clamped to the length of the array.
+func (a *RunEndEncoded) RunEnd(j int) int {
+	end := a.endAt(j) - a.data.offset
+	if end > a.Len() {
+		end = a.Len()
+	}
+	return end
+}
+
+// IsNull returns true if the value of the run holding the i-th element is null.
+func (a *RunEndEncoded) IsNull(i int) bool { return a.values.IsNull(a.GetPhysicalIndex(i)) }
+
+// IsValid returns true if the value of the run holding the i-th element is not null.
+func (a *RunEndEncoded) IsValid(i int) bool { return a.values.IsValid(a.GetPhysicalIndex(i)) }
+
+func (a *RunEndEncoded) String() string {
+	o := new(strings.Builder)
+	o.WriteString("[")
+	beg, first := 0, a.PhysicalOffset()
+	for j := first; j < first+a.PhysicalLength(); j++ {
+		end := a.RunEnd(j)
+		str := "(null)"
+		if a.values.IsValid(j) {
+			v := NewSlice(a.values, int64(j), int64(j+1))
+			str = fmt.Sprintf("%v", v)
+			v.Release()
+			// the value is formatted as a single element array: [v]
+			str = str[1 : len(str)-1]
+		}
+		for i := beg; i < end; i++ {
+			if i > 0 {
+				o.WriteString(" ")
+			}
+			o.WriteString(str)
+		}
+		beg = end
+	}
+	o.WriteString("]")
+	return o.String()
+}
+
+func (a *RunEndEncoded) setData(data *Data) {
+	a.array.setData(data)
+	a.ends = MakeFromData(data.childData[0])
+	a.values = MakeFromData(data.childData[1])
+	a.endAt = runEndGetter(a.ends)
+	if data.nulls < 0 {
+		// a slice has no validity bitmap to count its nulls from.
+		data.nulls = runNullCount(a.values, a.endAt, data.offset, data.length)
+	}
+}
+
+func (a *RunEndEncoded) Retain() {
+	a.array.Retain()
+	a.ends.Retain()
+	a.values.Retain()
+}
+
+func (a *RunEndEncoded) Release() {
+	a.array.Release()
+	a.ends.Release()
+	a.values.Release()
+}
+
+// runEndGetter returns an accessor widening the run ends of arr to int.
+func runEndGetter(arr Interface) func(j int) int {
+	switch ends := arr.(type) {
+	case *Int16:
+		return func(j int) int { return int(ends.Value(j)) }
+	case *Int32:
+		return func(j int) int { return int(ends.Value(j)) }
+	case *Int64:
+		return func(j int) int { return int(ends.Value(j)) }
+	default:
+		panic(xerrors.Errorf("arrow/array: invalid run end type %s", arr.DataType()))
+	}
+}
+
+// runNullCount returns the number of elements of [offset, offset+length) in the null runs.
+func runNullCount(values Interface, endAt func(j int) int, offset, length int) int {
+	nulls := 0
+	if values.NullN() > 0 {
+		beg := 0
+		for j := 0; j < values.Len(); j++ {
+			end := endAt(j)
+			if values.IsNull(j) {
+				nulls += overlap(beg, end, offset, offset+length)
+			}
+			beg = end
+		}
+	}
+	return nulls
+}
+
+// overlap returns the number of elements shared by [beg1, end1) and [beg2, end2).
+func overlap(beg1, end1, beg2, end2 int) int {
+	if beg2 > beg1 {
+		beg1 = beg2
+	}
+	if end2 < end1 {
+		end1 = end2
+	}
+	if end1 < beg1 {
+		return 0
+	}
+	return end1 - beg1
+}
+
+func arrayEqualRunEndEncoded(left, right *RunEndEncoded) bool {
+	for i := 0; i < left.Len(); i++ {
+		if left.IsNull(i) {
+			continue
+		}
+		o := func() bool {
+			lj, rj := int64(left.GetPhysicalIndex(i)), int64(right.GetPhysicalIndex(i))
+			l := NewSlice(left.values, lj, lj+1)
+			defer l.Release()
+			r := NewSlice(right.values, rj, rj+1)
+			defer r.Release()
+			return ArrayEqual(l, r)
+		}()
+		if !o {
+			return false
+		}
+	}
+	return true
+}
+
+// RunEndEncodedBuilder builds a RunEndEncoded array with int32 run ends.
+//
+// A run is started with Append and its value is then appended to ValueBuilder.
+// 游程编码数组构造器：Append 开始一个新的游程，随后将其值追加到 ValueBuilder 。
+type RunEndEncodedBuilder struct {
+	builder
+
+	ends    *Int32Builder
+	values  Builder
+	nullRun bool // the last run was appended by AppendNull
+}
+
+// NewRunEndEncodedBuilder returns a builder of runs of values of type dtype,
+// using the provided memory allocator.
+func NewRunEndEncodedBuilder(mem memory.Allocator, dtype arrow.DataType) *RunEndEncodedBuilder {
+	return &RunEndEncodedBuilder{
+		builder: builder{refCount: 1, mem: mem},
+		ends:    NewInt32Builder(mem),
+		values:  NewBuilder(mem, dtype),
+	}
+}
+
+// Release decreases the reference count by 1.
+// When the reference count goes to zero, the memory is freed.
+func (b *RunEndEncodedBuilder) Release() {
+	debug.Assert(atomic.LoadInt64(&b.refCount) > 0, "too many releases")
+
+	if atomic.AddInt64(&b.refCount, -1) == 0 {
+		b.ends.Release()
+		b.values.Release()
+	}
+}
+
+// ValueBuilder returns the builder of the run values.
+func (b *RunEndEncodedBuilder) ValueBuilder() Builder { return b.values }
+
+// Append starts a new run of n elements. The value of the run must then be
+// appended to ValueBuilder.
+func (b *RunEndEncodedBuilder) Append(n int) {
+	b.length += n
+	b.ends.Append(int32(b.length))
+	b.nullRun = false
+}
+
+// ContinueRun adds n elements to the last run.
+func (b *RunEndEncodedBuilder) ContinueRun(n int) {
+	debug.Assert(b.ends.Len() > 0, "arrow/array: no run to continue")
+	if b.nullRun {
+		b.nulls += n
+	}
+	b.length += n
+	b.ends.rawData[b.ends.Len()-1] = int32(b.length)
+}
+
+// AppendNull adds a null element, extending the last run when it is null.
+func (b *RunEndEncodedBuilder) AppendNull() {
+	if b.nullRun {
+		b.ContinueRun(1)
+		return
+	}
+	b.Append(1)
+	b.values.AppendNull()
+	b.nullRun = true
+	b.nulls++
+}
+
+func (b *RunEndEncodedBuilder) init(capacity int) {}
+
+func (b *RunEndEncodedBuilder) resize(newBits int, init func(int)) {}
+
+// Reserve ensures there is enough space for appending n runs.
+func (b *RunEndEncodedBuilder) Reserve(n int) {
+	b.ends.Reserve(n)
+	b.values.Reserve(n)
+}
+
+// Resize adjusts the space allocated by b to n runs.
+func (b *RunEndEncodedBuilder) Resize(n int) {
+	b.ends.Resize(n)
+	b.values.Resize(n)
+}
+
+// Cap returns the number of runs that can be stored without allocating additional memory.
+func (b *RunEndEncodedBuilder) Cap() int { return b.ends.Cap() }
+
+// NewArray creates a RunEndEncoded array from the memory buffers used by the builder and resets the
+// RunEndEncodedBuilder so it can be used to build a new array.
+func (b *RunEndEncodedBuilder) NewArray() Interface {
+	return b.NewRunEndEncodedArray()
+}
+
+// NewRunEndEncodedArray creates a RunEndEncoded array from the memory buffers used by the builder and resets the
+// RunEndEncodedBuilder so it can be used to build a new array.
+func (b *RunEndEncodedBuilder) NewRunEndEncodedArray() *RunEndEncoded {
+	ends := b.ends.NewArray()
+	defer ends.Release()
+	values := b.values.NewArray()
+	defer values.Release()
+
+	a := NewRunEndEncodedArray(ends, values, b.length, 0)
+	b.length, b.nulls, b.nullRun = 0, 0, false
+	return a
+}
+
+var (
+	_ Interface = (*RunEndEncoded)(nil)
+	_ Builder   = (*RunEndEncodedBuilder)(nil)
+)
@@ -21,10 +21,10 @@ import (
 	"strings"
 	"sync/atomic"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/bitutil"
-	"github.com/apache/arrow/go/arrow/internal/debug"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/debug"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 )
 
 // FixedSizeList represents an immutable sequence of N array values.
@@ -219,6 +219,7 @@ func (b *FixedSizeListBuilder) NewListArray() (a *FixedSizeList) {
 }
 
 func (b *FixedSizeListBuilder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	values := b.values.NewArray()
 	defer values.Release()
 
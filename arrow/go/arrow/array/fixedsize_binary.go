@@ -21,7 +21,7 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/apache/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow"
 )
 
 // A type which represents an immutable sequence of fixed-length binary strings.
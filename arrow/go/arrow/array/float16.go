@@ -20,8 +20,8 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/float16"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/float16"
 )
 
 // A type which represents an immutable sequence of Float16 values.
@@ -39,6 +39,15 @@ func NewFloat16Data(data *Data) *Float16 {
 
 func (a *Float16) Value(i int) float16.Num { return a.values[i] }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Float16) CheckedValue(i int) (float16.Num, error) {
+	if err := CheckIndex(a, i); err != nil {
+		return float16.Num{}, err
+	}
+	return a.Value(i), nil
+}
+
 func (a *Float16) Values() []float16.Num { return a.values }
 
 func (a *Float16) String() string {
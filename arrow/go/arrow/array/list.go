@@ -21,10 +21,10 @@ import (
 	"strings"
 	"sync/atomic"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/bitutil"
-	"github.com/apache/arrow/go/arrow/internal/debug"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/debug"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 )
 
 // List represents an immutable sequence of array values.
@@ -42,6 +42,48 @@ func NewListData(data *Data) *List {
 	return a
 }
 
+// NewListFromOffsets returns a List array of the lists of values delimited by
+// the int32 offsets held in offsets, one more than the number of lists. The
+// lists are valid where the bits of nullBitmap are set, or all valid when it
+// is nil. The List takes ownership of offsets, values and nullBitmap, even
+// when an error is returned: their data is not copied and the caller must not
+// release them.
+func NewListFromOffsets(offsets *memory.Buffer, values Interface, nullBitmap *memory.Buffer) (*List, error) {
+	defer func() {
+		offsets.Release()
+		values.Release()
+		if nullBitmap != nil {
+			nullBitmap.Release()
+		}
+	}()
+
+	offs := arrow.Int32Traits.CastFromBytes(offsets.Bytes())
+	if len(offs) == 0 {
+		return nil, fmt.Errorf("arrow/array: list offsets must hold at least one offset")
+	}
+	n := len(offs) - 1
+	if offs[0] < 0 || int(offs[n]) > values.Len() {
+		return nil, fmt.Errorf("arrow/array: list offsets [%d, %d] out of the %d values", offs[0], offs[n], values.Len())
+	}
+	for i := 1; i <= n; i++ {
+		if offs[i] < offs[i-1] {
+			return nil, fmt.Errorf("arrow/array: list offset %d at %d is smaller than the previous one", offs[i], i)
+		}
+	}
+
+	nulls := 0
+	if nullBitmap != nil {
+		if nullBitmap.Len() < int(bitutil.BytesForBits(int64(n))) {
+			return nil, fmt.Errorf("arrow/array: validity bitmap of %d bytes too short for %d lists", nullBitmap.Len(), n)
+		}
+		nulls = n - bitutil.CountSetBits(nullBitmap.Bytes(), 0, n)
+	}
+
+	data := NewData(arrow.ListOf(values.DataType()), n, []*memory.Buffer{nullBitmap, offsets}, []*Data{values.Data()}, nulls, 0)
+	defer data.Release()
+	return NewListData(data), nil
+}
+
 func (a *List) ListValues() Interface { return a.values }
 
 func (a *List) String() string {
@@ -238,6 +280,7 @@ func (b *ListBuilder) NewListArray() (a *List) {
 }
 
 func (b *ListBuilder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	values := b.values.NewArray()
 	defer values.Release()
 
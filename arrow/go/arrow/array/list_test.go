@@ -0,0 +1,154 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package array_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// newInt32Buffer returns a buffer allocated with mem holding vs.
+func newInt32Buffer(mem memory.Allocator, vs ...int32) *memory.Buffer {
+	buf := memory.NewResizableBuffer(mem)
+	buf.Resize(arrow.Int32Traits.BytesRequired(len(vs)))
+	copy(arrow.Int32Traits.CastFromBytes(buf.Bytes()), vs)
+	return buf
+}
+
+// newListValues returns an Int64 array of n values 0, 1, ... allocated with
+// mem.
+func newListValues(mem memory.Allocator, n int) *array.Int64 {
+	b := array.NewInt64Builder(mem)
+	defer b.Release()
+	for i := 0; i < n; i++ {
+		b.Append(int64(i))
+	}
+	return b.NewInt64Array()
+}
+
+func TestNewListFromOffsets(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	offsets := newInt32Buffer(mem, 0, 2, 2, 5)
+	values := newListValues(mem, 5)
+	// Lists 0 and 2 are valid, list 1 is null.
+	bitmap := memory.NewResizableBuffer(mem)
+	bitmap.Resize(1)
+	bitmap.Bytes()[0] = 0x05
+
+	// Keep references to check the List took the ones passed.
+	offsets.Retain()
+	values.Retain()
+	bitmap.Retain()
+
+	list, err := array.NewListFromOffsets(offsets, values, bitmap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := list.Len(), 3; got != want {
+		t.Fatalf("len=%d, want=%d", got, want)
+	}
+	if got, want := list.NullN(), 1; got != want {
+		t.Fatalf("nulls=%d, want=%d", got, want)
+	}
+	if list.IsNull(0) || list.IsValid(1) || list.IsNull(2) {
+		t.Fatalf("invalid validity: %v", list)
+	}
+	if got, want := list.String(), "[[0 1] (null) [2 3 4]]"; got != want {
+		t.Fatalf("list=%s, want=%s", got, want)
+	}
+	if !arrow.TypeEqual(list.DataType(), arrow.ListOf(arrow.PrimitiveTypes.Int64)) {
+		t.Fatalf("type=%v", list.DataType())
+	}
+
+	// The List shares the buffers, it does not copy them.
+	if &list.Data().Buffers()[1].Bytes()[0] != &offsets.Bytes()[0] {
+		t.Fatal("offsets were copied")
+	}
+	if list.ListValues().Data() != values.Data() {
+		t.Fatal("values were copied")
+	}
+
+	list.Release()
+	if mem.CurrentAlloc() == 0 {
+		t.Fatal("releasing the list freed the buffers still referenced")
+	}
+	if got := arrow.Int32Traits.CastFromBytes(offsets.Bytes()); got[3] != 5 {
+		t.Fatalf("offsets=%v", got)
+	}
+	offsets.Release()
+	values.Release()
+	bitmap.Release()
+}
+
+func TestNewListFromOffsetsAllValid(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	list, err := array.NewListFromOffsets(newInt32Buffer(mem, 0, 3), newListValues(mem, 3), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer list.Release()
+
+	if list.Len() != 1 || list.NullN() != 0 || list.IsNull(0) {
+		t.Fatalf("invalid list: %v", list)
+	}
+}
+
+func TestNewListFromOffsetsErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		offsets []int32
+		bitmap  []byte
+		want    string
+	}{
+		{"no offsets", nil, nil, "at least one offset"},
+		{"negative first", []int32{-1, 2}, nil, "list offsets [-1, 2] out of the 5 values"},
+		{"past the values", []int32{0, 2, 6}, nil, "list offsets [0, 6] out of the 5 values"},
+		{"decreasing", []int32{0, 3, 1, 5}, nil, "list offset 1 at 2 is smaller than the previous one"},
+		{"short bitmap", []int32{0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, []byte{0xff}, "validity bitmap of 1 bytes too short for 9 lists"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer mem.AssertSize(t, 0)
+
+			var bitmap *memory.Buffer
+			if tc.bitmap != nil {
+				bitmap = memory.NewResizableBuffer(mem)
+				bitmap.Resize(len(tc.bitmap))
+				copy(bitmap.Bytes(), tc.bitmap)
+			}
+			list, err := array.NewListFromOffsets(newInt32Buffer(mem, tc.offsets...), newListValues(mem, 5), bitmap)
+			if err == nil {
+				list.Release()
+				t.Fatalf("NewListFromOffsets(%v) did not fail", tc.offsets)
+			}
+			if list != nil {
+				t.Fatalf("got a list with error %v", err)
+			}
+			if !strings.HasPrefix(err.Error(), "arrow/array: ") || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("error=%q, want %q", err, tc.want)
+			}
+		})
+	}
+}
@@ -22,7 +22,7 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/apache/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow"
 )
 
 // A type which represents an immutable sequence of int64 values.
@@ -47,6 +47,16 @@ func (a *Int64) Reset(data *Data) {
 // Value returns the value at the specified index.
 func (a *Int64) Value(i int) int64 { return a.values[i] }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Int64) CheckedValue(i int) (int64, error) {
+	if err := CheckIndex(a, i); err != nil {
+		var zero int64
+		return zero, err
+	}
+	return a.Value(i), nil
+}
+
 // Values returns the values.
 func (a *Int64) Int64Values() []int64 { return a.values }
 
@@ -114,6 +124,16 @@ func (a *Uint64) Reset(data *Data) {
 // Value returns the value at the specified index.
 func (a *Uint64) Value(i int) uint64 { return a.values[i] }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Uint64) CheckedValue(i int) (uint64, error) {
+	if err := CheckIndex(a, i); err != nil {
+		var zero uint64
+		return zero, err
+	}
+	return a.Value(i), nil
+}
+
 // Values returns the values.
 func (a *Uint64) Uint64Values() []uint64 { return a.values }
 
@@ -181,6 +201,16 @@ func (a *Float64) Reset(data *Data) {
 // Value returns the value at the specified index.
 func (a *Float64) Value(i int) float64 { return a.values[i] }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Float64) CheckedValue(i int) (float64, error) {
+	if err := CheckIndex(a, i); err != nil {
+		var zero float64
+		return zero, err
+	}
+	return a.Value(i), nil
+}
+
 // Values returns the values.
 func (a *Float64) Float64Values() []float64 { return a.values }
 
@@ -248,6 +278,16 @@ func (a *Int32) Reset(data *Data) {
 // Value returns the value at the specified index.
 func (a *Int32) Value(i int) int32 { return a.values[i] }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Int32) CheckedValue(i int) (int32, error) {
+	if err := CheckIndex(a, i); err != nil {
+		var zero int32
+		return zero, err
+	}
+	return a.Value(i), nil
+}
+
 // Values returns the values.
 func (a *Int32) Int32Values() []int32 { return a.values }
 
@@ -315,6 +355,16 @@ func (a *Uint32) Reset(data *Data) {
 // Value returns the value at the specified index.
 func (a *Uint32) Value(i int) uint32 { return a.values[i] }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Uint32) CheckedValue(i int) (uint32, error) {
+	if err := CheckIndex(a, i); err != nil {
+		var zero uint32
+		return zero, err
+	}
+	return a.Value(i), nil
+}
+
 // Values returns the values.
 func (a *Uint32) Uint32Values() []uint32 { return a.values }
 
@@ -382,6 +432,16 @@ func (a *Float32) Reset(data *Data) {
 // Value returns the value at the specified index.
 func (a *Float32) Value(i int) float32 { return a.values[i] }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Float32) CheckedValue(i int) (float32, error) {
+	if err := CheckIndex(a, i); err != nil {
+		var zero float32
+		return zero, err
+	}
+	return a.Value(i), nil
+}
+
 // Values returns the values.
 func (a *Float32) Float32Values() []float32 { return a.values }
 
@@ -453,6 +513,16 @@ func (a *Int16) Reset(data *Data) {
 // Value returns the value at the specified index.
 func (a *Int16) Value(i int) int16 { return a.values[i] }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Int16) CheckedValue(i int) (int16, error) {
+	if err := CheckIndex(a, i); err != nil {
+		var zero int16
+		return zero, err
+	}
+	return a.Value(i), nil
+}
+
 // Values returns the values.
 func (a *Int16) Int16Values() []int16 { return a.values }
 
@@ -520,6 +590,16 @@ func (a *Uint16) Reset(data *Data) {
 // Value returns the value at the specified index.
 func (a *Uint16) Value(i int) uint16 { return a.values[i] }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Uint16) CheckedValue(i int) (uint16, error) {
+	if err := CheckIndex(a, i); err != nil {
+		var zero uint16
+		return zero, err
+	}
+	return a.Value(i), nil
+}
+
 // Values returns the values.
 func (a *Uint16) Uint16Values() []uint16 { return a.values }
 
@@ -587,6 +667,16 @@ func (a *Int8) Reset(data *Data) {
 // Value returns the value at the specified index.
 func (a *Int8) Value(i int) int8 { return a.values[i] }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Int8) CheckedValue(i int) (int8, error) {
+	if err := CheckIndex(a, i); err != nil {
+		var zero int8
+		return zero, err
+	}
+	return a.Value(i), nil
+}
+
 // Values returns the values.
 func (a *Int8) Int8Values() []int8 { return a.values }
 
@@ -654,6 +744,16 @@ func (a *Uint8) Reset(data *Data) {
 // Value returns the value at the specified index.
 func (a *Uint8) Value(i int) uint8 { return a.values[i] }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Uint8) CheckedValue(i int) (uint8, error) {
+	if err := CheckIndex(a, i); err != nil {
+		var zero uint8
+		return zero, err
+	}
+	return a.Value(i), nil
+}
+
 // Values returns the values.
 func (a *Uint8) Uint8Values() []uint8 { return a.values }
 
@@ -721,6 +821,16 @@ func (a *Timestamp) Reset(data *Data) {
 // Value returns the value at the specified index.
 func (a *Timestamp) Value(i int) arrow.Timestamp { return a.values[i] }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Timestamp) CheckedValue(i int) (arrow.Timestamp, error) {
+	if err := CheckIndex(a, i); err != nil {
+		var zero arrow.Timestamp
+		return zero, err
+	}
+	return a.Value(i), nil
+}
+
 // Values returns the values.
 func (a *Timestamp) TimestampValues() []arrow.Timestamp { return a.values }
 
@@ -788,6 +898,16 @@ func (a *Time32) Reset(data *Data) {
 // Value returns the value at the specified index.
 func (a *Time32) Value(i int) arrow.Time32 { return a.values[i] }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Time32) CheckedValue(i int) (arrow.Time32, error) {
+	if err := CheckIndex(a, i); err != nil {
+		var zero arrow.Time32
+		return zero, err
+	}
+	return a.Value(i), nil
+}
+
 // Values returns the values.
 func (a *Time32) Time32Values() []arrow.Time32 { return a.values }
 
@@ -855,6 +975,16 @@ func (a *Time64) Reset(data *Data) {
 // Value returns the value at the specified index.
 func (a *Time64) Value(i int) arrow.Time64 { return a.values[i] }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Time64) CheckedValue(i int) (arrow.Time64, error) {
+	if err := CheckIndex(a, i); err != nil {
+		var zero arrow.Time64
+		return zero, err
+	}
+	return a.Value(i), nil
+}
+
 // Values returns the values.
 func (a *Time64) Time64Values() []arrow.Time64 { return a.values }
 
@@ -922,6 +1052,16 @@ func (a *Date32) Reset(data *Data) {
 // Value returns the value at the specified index.
 func (a *Date32) Value(i int) arrow.Date32 { return a.values[i] }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Date32) CheckedValue(i int) (arrow.Date32, error) {
+	if err := CheckIndex(a, i); err != nil {
+		var zero arrow.Date32
+		return zero, err
+	}
+	return a.Value(i), nil
+}
+
 // Values returns the values.
 func (a *Date32) Date32Values() []arrow.Date32 { return a.values }
 
@@ -989,6 +1129,16 @@ func (a *Date64) Reset(data *Data) {
 // Value returns the value at the specified index.
 func (a *Date64) Value(i int) arrow.Date64 { return a.values[i] }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Date64) CheckedValue(i int) (arrow.Date64, error) {
+	if err := CheckIndex(a, i); err != nil {
+		var zero arrow.Date64
+		return zero, err
+	}
+	return a.Value(i), nil
+}
+
 // Values returns the values.
 func (a *Date64) Date64Values() []arrow.Date64 { return a.values }
 
@@ -1056,6 +1206,16 @@ func (a *Duration) Reset(data *Data) {
 // Value returns the value at the specified index.
 func (a *Duration) Value(i int) arrow.Duration { return a.values[i] }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *Duration) CheckedValue(i int) (arrow.Duration, error) {
+	if err := CheckIndex(a, i); err != nil {
+		var zero arrow.Duration
+		return zero, err
+	}
+	return a.Value(i), nil
+}
+
 // Values returns the values.
 func (a *Duration) DurationValues() []arrow.Duration { return a.values }
 
@@ -21,10 +21,10 @@ package array
 import (
 	"sync/atomic"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/bitutil"
-	"github.com/apache/arrow/go/arrow/internal/debug"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/debug"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 )
 
 type Int64Builder struct {
@@ -151,12 +151,14 @@ func (b *Int64Builder) NewInt64Array() (a *Int64) {
 }
 
 func (b *Int64Builder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	bytesRequired := arrow.Int64Traits.BytesRequired(b.length)
 	if bytesRequired > 0 && bytesRequired < b.data.Len() {
 		// trim buffers
 		b.data.Resize(bytesRequired)
 	}
 	data = NewData(arrow.PrimitiveTypes.Int64, b.length, []*memory.Buffer{b.nullBitmap, b.data}, nil, b.nulls, 0)
+	b.builder.attachStatistics(data)
 	b.reset()
 
 	if b.data != nil {
@@ -287,12 +289,14 @@ func (b *Uint64Builder) NewUint64Array() (a *Uint64) {
 }
 
 func (b *Uint64Builder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	bytesRequired := arrow.Uint64Traits.BytesRequired(b.length)
 	if bytesRequired > 0 && bytesRequired < b.data.Len() {
 		// trim buffers
 		b.data.Resize(bytesRequired)
 	}
 	data = NewData(arrow.PrimitiveTypes.Uint64, b.length, []*memory.Buffer{b.nullBitmap, b.data}, nil, b.nulls, 0)
+	b.builder.attachStatistics(data)
 	b.reset()
 
 	if b.data != nil {
@@ -423,12 +427,14 @@ func (b *Float64Builder) NewFloat64Array() (a *Float64) {
 }
 
 func (b *Float64Builder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	bytesRequired := arrow.Float64Traits.BytesRequired(b.length)
 	if bytesRequired > 0 && bytesRequired < b.data.Len() {
 		// trim buffers
 		b.data.Resize(bytesRequired)
 	}
 	data = NewData(arrow.PrimitiveTypes.Float64, b.length, []*memory.Buffer{b.nullBitmap, b.data}, nil, b.nulls, 0)
+	b.builder.attachStatistics(data)
 	b.reset()
 
 	if b.data != nil {
@@ -570,12 +576,14 @@ func (b *Int32Builder) NewInt32Array() (a *Int32) {
 }
 
 func (b *Int32Builder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	bytesRequired := arrow.Int32Traits.BytesRequired(b.length)
 	if bytesRequired > 0 && bytesRequired < b.data.Len() {
 		// trim buffers
 		b.data.Resize(bytesRequired)
 	}
 	data = NewData(arrow.PrimitiveTypes.Int32, b.length, []*memory.Buffer{b.nullBitmap, b.data}, nil, b.nulls, 0)
+	b.builder.attachStatistics(data)
 	b.reset()
 
 	if b.data != nil {
@@ -706,12 +714,14 @@ func (b *Uint32Builder) NewUint32Array() (a *Uint32) {
 }
 
 func (b *Uint32Builder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	bytesRequired := arrow.Uint32Traits.BytesRequired(b.length)
 	if bytesRequired > 0 && bytesRequired < b.data.Len() {
 		// trim buffers
 		b.data.Resize(bytesRequired)
 	}
 	data = NewData(arrow.PrimitiveTypes.Uint32, b.length, []*memory.Buffer{b.nullBitmap, b.data}, nil, b.nulls, 0)
+	b.builder.attachStatistics(data)
 	b.reset()
 
 	if b.data != nil {
@@ -842,12 +852,14 @@ func (b *Float32Builder) NewFloat32Array() (a *Float32) {
 }
 
 func (b *Float32Builder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	bytesRequired := arrow.Float32Traits.BytesRequired(b.length)
 	if bytesRequired > 0 && bytesRequired < b.data.Len() {
 		// trim buffers
 		b.data.Resize(bytesRequired)
 	}
 	data = NewData(arrow.PrimitiveTypes.Float32, b.length, []*memory.Buffer{b.nullBitmap, b.data}, nil, b.nulls, 0)
+	b.builder.attachStatistics(data)
 	b.reset()
 
 	if b.data != nil {
@@ -978,12 +990,14 @@ func (b *Int16Builder) NewInt16Array() (a *Int16) {
 }
 
 func (b *Int16Builder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	bytesRequired := arrow.Int16Traits.BytesRequired(b.length)
 	if bytesRequired > 0 && bytesRequired < b.data.Len() {
 		// trim buffers
 		b.data.Resize(bytesRequired)
 	}
 	data = NewData(arrow.PrimitiveTypes.Int16, b.length, []*memory.Buffer{b.nullBitmap, b.data}, nil, b.nulls, 0)
+	b.builder.attachStatistics(data)
 	b.reset()
 
 	if b.data != nil {
@@ -1114,12 +1128,14 @@ func (b *Uint16Builder) NewUint16Array() (a *Uint16) {
 }
 
 func (b *Uint16Builder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	bytesRequired := arrow.Uint16Traits.BytesRequired(b.length)
 	if bytesRequired > 0 && bytesRequired < b.data.Len() {
 		// trim buffers
 		b.data.Resize(bytesRequired)
 	}
 	data = NewData(arrow.PrimitiveTypes.Uint16, b.length, []*memory.Buffer{b.nullBitmap, b.data}, nil, b.nulls, 0)
+	b.builder.attachStatistics(data)
 	b.reset()
 
 	if b.data != nil {
@@ -1250,12 +1266,14 @@ func (b *Int8Builder) NewInt8Array() (a *Int8) {
 }
 
 func (b *Int8Builder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	bytesRequired := arrow.Int8Traits.BytesRequired(b.length)
 	if bytesRequired > 0 && bytesRequired < b.data.Len() {
 		// trim buffers
 		b.data.Resize(bytesRequired)
 	}
 	data = NewData(arrow.PrimitiveTypes.Int8, b.length, []*memory.Buffer{b.nullBitmap, b.data}, nil, b.nulls, 0)
+	b.builder.attachStatistics(data)
 	b.reset()
 
 	if b.data != nil {
@@ -1386,12 +1404,14 @@ func (b *Uint8Builder) NewUint8Array() (a *Uint8) {
 }
 
 func (b *Uint8Builder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	bytesRequired := arrow.Uint8Traits.BytesRequired(b.length)
 	if bytesRequired > 0 && bytesRequired < b.data.Len() {
 		// trim buffers
 		b.data.Resize(bytesRequired)
 	}
 	data = NewData(arrow.PrimitiveTypes.Uint8, b.length, []*memory.Buffer{b.nullBitmap, b.data}, nil, b.nulls, 0)
+	b.builder.attachStatistics(data)
 	b.reset()
 
 	if b.data != nil {
@@ -1523,12 +1543,14 @@ func (b *TimestampBuilder) NewTimestampArray() (a *Timestamp) {
 }
 
 func (b *TimestampBuilder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	bytesRequired := arrow.TimestampTraits.BytesRequired(b.length)
 	if bytesRequired > 0 && bytesRequired < b.data.Len() {
 		// trim buffers
 		b.data.Resize(bytesRequired)
 	}
 	data = NewData(b.dtype, b.length, []*memory.Buffer{b.nullBitmap, b.data}, nil, b.nulls, 0)
+	b.builder.attachStatistics(data)
 	b.reset()
 
 	if b.data != nil {
@@ -1660,12 +1682,14 @@ func (b *Time32Builder) NewTime32Array() (a *Time32) {
 }
 
 func (b *Time32Builder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	bytesRequired := arrow.Time32Traits.BytesRequired(b.length)
 	if bytesRequired > 0 && bytesRequired < b.data.Len() {
 		// trim buffers
 		b.data.Resize(bytesRequired)
 	}
 	data = NewData(b.dtype, b.length, []*memory.Buffer{b.nullBitmap, b.data}, nil, b.nulls, 0)
+	b.builder.attachStatistics(data)
 	b.reset()
 
 	if b.data != nil {
@@ -1797,12 +1821,14 @@ func (b *Time64Builder) NewTime64Array() (a *Time64) {
 }
 
 func (b *Time64Builder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	bytesRequired := arrow.Time64Traits.BytesRequired(b.length)
 	if bytesRequired > 0 && bytesRequired < b.data.Len() {
 		// trim buffers
 		b.data.Resize(bytesRequired)
 	}
 	data = NewData(b.dtype, b.length, []*memory.Buffer{b.nullBitmap, b.data}, nil, b.nulls, 0)
+	b.builder.attachStatistics(data)
 	b.reset()
 
 	if b.data != nil {
@@ -1933,12 +1959,14 @@ func (b *Date32Builder) NewDate32Array() (a *Date32) {
 }
 
 func (b *Date32Builder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	bytesRequired := arrow.Date32Traits.BytesRequired(b.length)
 	if bytesRequired > 0 && bytesRequired < b.data.Len() {
 		// trim buffers
 		b.data.Resize(bytesRequired)
 	}
 	data = NewData(arrow.PrimitiveTypes.Date32, b.length, []*memory.Buffer{b.nullBitmap, b.data}, nil, b.nulls, 0)
+	b.builder.attachStatistics(data)
 	b.reset()
 
 	if b.data != nil {
@@ -2069,12 +2097,14 @@ func (b *Date64Builder) NewDate64Array() (a *Date64) {
 }
 
 func (b *Date64Builder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	bytesRequired := arrow.Date64Traits.BytesRequired(b.length)
 	if bytesRequired > 0 && bytesRequired < b.data.Len() {
 		// trim buffers
 		b.data.Resize(bytesRequired)
 	}
 	data = NewData(arrow.PrimitiveTypes.Date64, b.length, []*memory.Buffer{b.nullBitmap, b.data}, nil, b.nulls, 0)
+	b.builder.attachStatistics(data)
 	b.reset()
 
 	if b.data != nil {
@@ -2206,12 +2236,14 @@ func (b *DurationBuilder) NewDurationArray() (a *Duration) {
 }
 
 func (b *DurationBuilder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	bytesRequired := arrow.DurationTraits.BytesRequired(b.length)
 	if bytesRequired > 0 && bytesRequired < b.data.Len() {
 		// trim buffers
 		b.data.Resize(bytesRequired)
 	}
 	data = NewData(b.dtype, b.length, []*memory.Buffer{b.nullBitmap, b.data}, nil, b.nulls, 0)
+	b.builder.attachStatistics(data)
 	b.reset()
 
 	if b.data != nil {
@@ -0,0 +1,356 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package array
+
+import (
+	"bytes"
+	"container/heap"
+	"math"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+)
+
+// statsSketchSize is the number of smallest value hashes kept to estimate the
+// number of distinct values, which is exact below it.
+const statsSketchSize = 1024
+
+// Stats are the statistics of the values of an array, such as the ones a
+// zone map keeps per chunk.
+type Stats struct {
+	// NullN is the number of null values.
+	NullN int
+	// Min and Max are the smallest and the largest non-null values, nil when
+	// there are none or the type is not supported. They hold an int64 for the
+	// signed integer and temporal types, an uint64 for the unsigned integers,
+	// a float64 for the floating point numbers, ignoring NaNs, a bool for the
+	// booleans, a string for the strings and a []byte for the binaries.
+	Min, Max interface{}
+	// DistinctN is the number of distinct non-null values, estimated from
+	// their hashes past statsSketchSize values. It is zero when the type is
+	// not supported.
+	DistinctN int64
+}
+
+// Statistics returns the statistics cached on the data of arr, either when it
+// was built by a builder with SetStatistics enabled or by ComputeStatistics.
+// Slices do not share the statistics of the array they are taken from.
+func Statistics(arr Interface) (*Stats, bool) {
+	stats := arr.Data().statistics()
+	return stats, stats != nil
+}
+
+// ComputeStatistics returns the statistics of arr, computing and caching them
+// on its data when they are not cached yet. It may be called simultaneously
+// from multiple goroutines.
+func ComputeStatistics(arr Interface) *Stats {
+	data := arr.Data()
+	if stats := data.statistics(); stats != nil {
+		return stats
+	}
+	stats := computeStats(data)
+	data.setStatistics(stats)
+	return stats
+}
+
+func (d *Data) statistics() *Stats {
+	return (*Stats)(atomic.LoadPointer(&d.stats))
+}
+
+func (d *Data) setStatistics(stats *Stats) {
+	atomic.StorePointer(&d.stats, unsafe.Pointer(stats))
+}
+
+// computeStats computes the statistics of the values of d.
+func computeStats(d *Data) *Stats {
+	stats := &Stats{NullN: d.nulls}
+	if d.nulls < 0 {
+		stats.NullN = d.length
+		if len(d.buffers) > 0 && d.buffers[0] != nil {
+			stats.NullN = 0
+			for i := 0; i < d.length; i++ {
+				if !validAt(d, d.offset+i) {
+					stats.NullN++
+				}
+			}
+		}
+	}
+	if d.length == 0 || stats.NullN == d.length {
+		return stats
+	}
+
+	switch d.dtype.ID() {
+	case arrow.INT8, arrow.INT16, arrow.INT32, arrow.INT64,
+		arrow.DATE32, arrow.DATE64, arrow.TIME32, arrow.TIME64,
+		arrow.TIMESTAMP, arrow.DURATION:
+		signedStats(d, stats)
+	case arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64:
+		unsignedStats(d, stats)
+	case arrow.FLOAT32, arrow.FLOAT64:
+		floatStats(d, stats)
+	case arrow.BOOL:
+		booleanStats(d, stats)
+	case arrow.STRING, arrow.BINARY:
+		bytesStats(d, stats)
+	}
+	return stats
+}
+
+func signedStats(d *Data, stats *Stats) {
+	b := d.buffers[1].Bytes()
+	var value func(i int) int64
+	switch d.dtype.(arrow.FixedWidthDataType).BitWidth() {
+	case 8:
+		values := arrow.Int8Traits.CastFromBytes(b)
+		value = func(i int) int64 { return int64(values[i]) }
+	case 16:
+		values := arrow.Int16Traits.CastFromBytes(b)
+		value = func(i int) int64 { return int64(values[i]) }
+	case 32:
+		values := arrow.Int32Traits.CastFromBytes(b)
+		value = func(i int) int64 { return int64(values[i]) }
+	default:
+		values := arrow.Int64Traits.CastFromBytes(b)
+		value = func(i int) int64 { return values[i] }
+	}
+
+	sketch := newDistinctSketch()
+	var min, max int64
+	found := false
+	for i := d.offset; i < d.offset+d.length; i++ {
+		if !validAt(d, i) {
+			continue
+		}
+		v := value(i)
+		if !found || v < min {
+			min = v
+		}
+		if !found || v > max {
+			max = v
+		}
+		found = true
+		sketch.add(mixHash(uint64(v)))
+	}
+	stats.Min, stats.Max, stats.DistinctN = min, max, sketch.estimate()
+}
+
+func unsignedStats(d *Data, stats *Stats) {
+	b := d.buffers[1].Bytes()
+	var value func(i int) uint64
+	switch d.dtype.ID() {
+	case arrow.UINT8:
+		values := arrow.Uint8Traits.CastFromBytes(b)
+		value = func(i int) uint64 { return uint64(values[i]) }
+	case arrow.UINT16:
+		values := arrow.Uint16Traits.CastFromBytes(b)
+		value = func(i int) uint64 { return uint64(values[i]) }
+	case arrow.UINT32:
+		values := arrow.Uint32Traits.CastFromBytes(b)
+		value = func(i int) uint64 { return uint64(values[i]) }
+	default:
+		values := arrow.Uint64Traits.CastFromBytes(b)
+		value = func(i int) uint64 { return values[i] }
+	}
+
+	sketch := newDistinctSketch()
+	var min, max uint64
+	found := false
+	for i := d.offset; i < d.offset+d.length; i++ {
+		if !validAt(d, i) {
+			continue
+		}
+		v := value(i)
+		if !found || v < min {
+			min = v
+		}
+		if !found || v > max {
+			max = v
+		}
+		found = true
+		sketch.add(mixHash(v))
+	}
+	stats.Min, stats.Max, stats.DistinctN = min, max, sketch.estimate()
+}
+
+func floatStats(d *Data, stats *Stats) {
+	b := d.buffers[1].Bytes()
+	var value func(i int) float64
+	if d.dtype.ID() == arrow.FLOAT32 {
+		values := arrow.Float32Traits.CastFromBytes(b)
+		value = func(i int) float64 { return float64(values[i]) }
+	} else {
+		values := arrow.Float64Traits.CastFromBytes(b)
+		value = func(i int) float64 { return values[i] }
+	}
+
+	sketch := newDistinctSketch()
+	var min, max float64
+	found := false
+	for i := d.offset; i < d.offset+d.length; i++ {
+		if !validAt(d, i) {
+			continue
+		}
+		v := value(i)
+		switch {
+		case math.IsNaN(v):
+			sketch.add(mixHash(math.Float64bits(math.NaN())))
+			continue
+		case v == 0:
+			// -0 and +0 are the same value.
+			v = 0
+		}
+		if !found || v < min {
+			min = v
+		}
+		if !found || v > max {
+			max = v
+		}
+		found = true
+		sketch.add(mixHash(math.Float64bits(v)))
+	}
+	if found {
+		stats.Min, stats.Max = min, max
+	}
+	stats.DistinctN = sketch.estimate()
+}
+
+func booleanStats(d *Data, stats *Stats) {
+	values := d.buffers[1].Bytes()
+	var seen [2]bool
+	for i := d.offset; i < d.offset+d.length; i++ {
+		if !validAt(d, i) {
+			continue
+		}
+		if values[i/8]&(1<<uint(i%8)) != 0 {
+			seen[1] = true
+		} else {
+			seen[0] = true
+		}
+	}
+	stats.Min, stats.Max = !seen[0], seen[1]
+	for _, ok := range seen {
+		if ok {
+			stats.DistinctN++
+		}
+	}
+}
+
+func bytesStats(d *Data, stats *Stats) {
+	offsets := arrow.Int32Traits.CastFromBytes(d.buffers[1].Bytes())
+	var values []byte
+	if d.buffers[2] != nil {
+		values = d.buffers[2].Bytes()
+	}
+
+	sketch := newDistinctSketch()
+	var min, max []byte
+	found := false
+	for i := d.offset; i < d.offset+d.length; i++ {
+		if !validAt(d, i) {
+			continue
+		}
+		v := values[offsets[i]:offsets[i+1]]
+		if !found || bytes.Compare(v, min) < 0 {
+			min = v
+		}
+		if !found || bytes.Compare(v, max) > 0 {
+			max = v
+		}
+		found = true
+		sketch.add(mixHash(hashBytes(v)))
+	}
+	// The bounds are copied out of the buffers, which stats may outlive.
+	if d.dtype.ID() == arrow.STRING {
+		stats.Min, stats.Max = string(min), string(max)
+	} else {
+		stats.Min, stats.Max = append([]byte{}, min...), append([]byte{}, max...)
+	}
+	stats.DistinctN = sketch.estimate()
+}
+
+// distinctSketch estimates the number of distinct values from the
+// statsSketchSize smallest of their hashes (k minimum values).
+type distinctSketch struct {
+	seen   map[uint64]struct{}
+	hashes hashHeap
+}
+
+func newDistinctSketch() *distinctSketch {
+	return &distinctSketch{seen: make(map[uint64]struct{})}
+}
+
+func (s *distinctSketch) add(h uint64) {
+	if _, ok := s.seen[h]; ok {
+		return
+	}
+	if len(s.hashes) < statsSketchSize {
+		s.seen[h] = struct{}{}
+		heap.Push(&s.hashes, h)
+		return
+	}
+	if h >= s.hashes[0] {
+		return
+	}
+	delete(s.seen, s.hashes[0])
+	s.seen[h] = struct{}{}
+	s.hashes[0] = h
+	heap.Fix(&s.hashes, 0)
+}
+
+func (s *distinctSketch) estimate() int64 {
+	if len(s.hashes) < statsSketchSize {
+		return int64(len(s.hashes))
+	}
+	// The largest of the k smallest hashes, as a fraction of the hash space,
+	// is about k over the number of distinct hashes.
+	return int64(float64(statsSketchSize-1) / (float64(s.hashes[0]) / math.MaxUint64))
+}
+
+// hashHeap is a max-heap of hashes.
+type hashHeap []uint64
+
+func (h hashHeap) Len() int            { return len(h) }
+func (h hashHeap) Less(i, j int) bool  { return h[i] > h[j] }
+func (h hashHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *hashHeap) Push(x interface{}) { *h = append(*h, x.(uint64)) }
+func (h *hashHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// mixHash spreads the bits of x over the hash space (splitmix64 finalizer).
+func mixHash(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// hashBytes returns the FNV-1a hash of b.
+func hashBytes(b []byte) uint64 {
+	h := uint64(14695981039346656037)
+	for _, c := range b {
+		h ^= uint64(c)
+		h *= 1099511628211
+	}
+	return h
+}
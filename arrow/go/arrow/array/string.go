@@ -20,10 +20,11 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"unicode/utf8"
 	"unsafe"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 )
 
 const (
@@ -64,6 +65,15 @@ func (a *String) Value(i int) string {
 	return a.values[a.offsets[i]:a.offsets[i+1]]
 }
 
+// CheckedValue returns the value at the specified index, or an *IndexError
+// when there is none.
+func (a *String) CheckedValue(i int) (string, error) {
+	if err := CheckIndex(a, i); err != nil {
+		return "", err
+	}
+	return a.Value(i), nil
+}
+
 // ValueOffset returns the offset of the value at index i.
 func (a *String) ValueOffset(i int) int { return int(a.offsets[i]) }
 
@@ -119,9 +129,72 @@ func arrayEqualString(left, right *String) bool {
 	return true
 }
 
+// UTF8Policy selects what is done with strings that are not valid UTF-8.
+type UTF8Policy int8
+
+const (
+	// UTF8Unchecked keeps the strings as they are. It is the default.
+	UTF8Unchecked UTF8Policy = iota
+	// UTF8Replace replaces every invalid sequence of a string with the
+	// Unicode replacement character U+FFFD.
+	UTF8Replace
+	// UTF8Error rejects the strings: a StringBuilder appends a null instead
+	// and reports the string with Err, and readers fail.
+	UTF8Error
+)
+
+func (p UTF8Policy) String() string {
+	switch p {
+	case UTF8Unchecked:
+		return "unchecked"
+	case UTF8Replace:
+		return "replace"
+	case UTF8Error:
+		return "error"
+	default:
+		return fmt.Sprintf("UTF8Policy(%d)", int(p))
+	}
+}
+
+// FindInvalidUTF8 returns the index of the first non-null string of a that is
+// not valid UTF-8, or -1 when they all are.
+func FindInvalidUTF8(a *String) int {
+	for i := 0; i < a.Len(); i++ {
+		if a.IsValid(i) && !utf8.ValidString(a.Value(i)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ReplaceInvalidUTF8 returns a String array holding the strings of a with
+// their invalid UTF-8 sequences replaced by U+FFFD. It returns a retained
+// when its strings are all valid.
+func ReplaceInvalidUTF8(mem memory.Allocator, a *String) *String {
+	if FindInvalidUTF8(a) < 0 {
+		a.Retain()
+		return a
+	}
+	b := NewStringBuilder(mem)
+	defer b.Release()
+	b.SetUTF8Policy(UTF8Replace)
+	b.Reserve(a.Len())
+	for i := 0; i < a.Len(); i++ {
+		if a.IsNull(i) {
+			b.AppendNull()
+			continue
+		}
+		b.Append(a.Value(i))
+	}
+	return b.NewStringArray()
+}
+
 // A StringBuilder is used to build a String array using the Append methods.
 type StringBuilder struct {
 	builder *BinaryBuilder
+
+	utf8 UTF8Policy
+	err  error // first string rejected by the UTF8Error policy
 }
 
 // NewStringBuilder creates a new StringBuilder.
@@ -155,8 +228,27 @@ func (b *StringBuilder) Cap() int { return b.builder.Cap() }
 // NullN returns the number of null values in the array builder.
 func (b *StringBuilder) NullN() int { return b.builder.NullN() }
 
+// SetUTF8Policy selects what Append and AppendValues do with strings that are
+// not valid UTF-8. They are not checked by default.
+func (b *StringBuilder) SetUTF8Policy(policy UTF8Policy) { b.utf8 = policy }
+
+// Err returns the error reporting the first string rejected by the UTF8Error
+// policy since the last array was built, nil when there is none.
+func (b *StringBuilder) Err() error { return b.err }
+
 // Append appends a string to the builder.
 func (b *StringBuilder) Append(v string) {
+	if b.utf8 != UTF8Unchecked && !utf8.ValidString(v) {
+		if b.utf8 == UTF8Replace {
+			v = strings.ToValidUTF8(v, string(utf8.RuneError))
+		} else {
+			if b.err == nil {
+				b.err = fmt.Errorf("arrow/array: string at %d is not valid UTF-8", b.Len())
+			}
+			b.AppendNull()
+			return
+		}
+	}
 	b.builder.Append([]byte(v))
 }
 
@@ -169,7 +261,21 @@ func (b *StringBuilder) AppendNull() {
 // in v are valid (not null). The valid slice must either be empty or be equal in length to v. If empty,
 // all values in v are appended and considered valid.
 func (b *StringBuilder) AppendValues(v []string, valid []bool) {
-	b.builder.AppendStringValues(v, valid)
+	if b.utf8 == UTF8Unchecked {
+		b.builder.AppendStringValues(v, valid)
+		return
+	}
+	if len(v) != len(valid) && len(valid) != 0 {
+		panic("len(v) != len(valid) && len(valid) != 0")
+	}
+	b.Reserve(len(v))
+	for i, s := range v {
+		if len(valid) > 0 && !valid[i] {
+			b.AppendNull()
+			continue
+		}
+		b.Append(s)
+	}
 }
 
 // Value returns the string at index i.
@@ -177,6 +283,18 @@ func (b *StringBuilder) Value(i int) string {
 	return string(b.builder.Value(i))
 }
 
+// SetSizeHint tells the builder how many elements the arrays it builds are
+// expected to hold.
+func (b *StringBuilder) SetSizeHint(n int) { b.builder.SetSizeHint(n) }
+
+// SetShrinkToFit makes the builder trim its buffers to their length when an
+// array is built.
+func (b *StringBuilder) SetShrinkToFit(shrink bool) { b.builder.SetShrinkToFit(shrink) }
+
+// SetStatistics makes the builder compute the statistics of the arrays it
+// builds.
+func (b *StringBuilder) SetStatistics(enable bool) { b.builder.SetStatistics(enable) }
+
 func (b *StringBuilder) init(capacity int) {
 	b.builder.init(capacity)
 }
@@ -191,6 +309,12 @@ func (b *StringBuilder) Reserve(n int) {
 	b.builder.Reserve(n)
 }
 
+// ReserveData ensures there is enough space for appending n bytes
+// by checking the capacity and resizing the data buffer if necessary.
+func (b *StringBuilder) ReserveData(n int) {
+	b.builder.ReserveData(n)
+}
+
 // Resize adjusts the space allocated by b to n elements. If n is greater than b.Cap(),
 // additional memory will be allocated. If n is smaller, the allocated memory may reduced.
 func (b *StringBuilder) Resize(n int) {
@@ -209,6 +333,7 @@ func (b *StringBuilder) NewStringArray() (a *String) {
 	data := b.builder.newData()
 	a = NewStringData(data)
 	data.Release()
+	b.err = nil
 	return
 }
 
@@ -22,10 +22,12 @@ import (
 	"strings"
 	"sync/atomic"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/bitutil"
-	"github.com/apache/arrow/go/arrow/internal/debug"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/decimal128"
+	"github.com/gomem/gomem/arrow/go/arrow/float16"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/debug"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 )
 
 // Struct represents an ordered sequence of relative types.
@@ -234,6 +236,183 @@ func (b *StructBuilder) resizeHelper(n int) {
 func (b *StructBuilder) NumField() int              { return len(b.fields) }
 func (b *StructBuilder) FieldBuilder(i int) Builder { return b.fields[i] }
 
+// AppendRow appends a valid struct holding one value per field, nil being
+// null. The values must have the Go type the builder of their field appends,
+// such as int64 for Int64 fields, string for String fields or arrow.Timestamp
+// for Timestamp fields, and a []interface{} for Struct fields, appended with
+// AppendRow. Every value is checked before any is appended, so a rejected row
+// leaves the builder unchanged.
+func (b *StructBuilder) AppendRow(values ...interface{}) error {
+	appendRow, err := b.rowAppender(values)
+	if err != nil {
+		return fmt.Errorf("arrow/array: %w", err)
+	}
+	appendRow()
+	return nil
+}
+
+// AppendNullRow appends a null struct and a null to the builder of every
+// field, so that the fields keep the length of the struct.
+func (b *StructBuilder) AppendNullRow() { b.AppendNull() }
+
+// CheckLengths returns an error when the builder of a field does not hold
+// one value per struct appended, which happens when values are appended to
+// the builders of the fields and to the struct builder separately.
+func (b *StructBuilder) CheckLengths() error {
+	for i, f := range b.fields {
+		if f.Len() != b.Len() {
+			name := b.dtype.(*arrow.StructType).Field(i).Name
+			return fmt.Errorf("arrow/array: struct field %q has %d values, want %d", name, f.Len(), b.Len())
+		}
+	}
+	return nil
+}
+
+// rowAppender checks a row of values for AppendRow and returns the function
+// appending it.
+func (b *StructBuilder) rowAppender(values []interface{}) (func(), error) {
+	if len(values) != len(b.fields) {
+		return nil, fmt.Errorf("struct row has %d values, want %d", len(values), len(b.fields))
+	}
+	appenders := make([]func(), len(values))
+	for i, v := range values {
+		fn, err := valueAppender(b.fields[i], v)
+		if err != nil {
+			name := b.dtype.(*arrow.StructType).Field(i).Name
+			return nil, fmt.Errorf("struct field %q: %w", name, err)
+		}
+		appenders[i] = fn
+	}
+	return func() {
+		b.Append(true)
+		for _, fn := range appenders {
+			fn()
+		}
+	}, nil
+}
+
+// valueAppender returns the function appending v to bldr, or an error when
+// bldr cannot append a value of the type of v.
+func valueAppender(bldr Builder, v interface{}) (func(), error) {
+	if v == nil {
+		return bldr.AppendNull, nil
+	}
+	var fn func()
+	switch b := bldr.(type) {
+	case *BooleanBuilder:
+		if x, ok := v.(bool); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *Int8Builder:
+		if x, ok := v.(int8); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *Int16Builder:
+		if x, ok := v.(int16); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *Int32Builder:
+		if x, ok := v.(int32); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *Int64Builder:
+		if x, ok := v.(int64); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *Uint8Builder:
+		if x, ok := v.(uint8); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *Uint16Builder:
+		if x, ok := v.(uint16); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *Uint32Builder:
+		if x, ok := v.(uint32); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *Uint64Builder:
+		if x, ok := v.(uint64); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *Float16Builder:
+		if x, ok := v.(float16.Num); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *Float32Builder:
+		if x, ok := v.(float32); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *Float64Builder:
+		if x, ok := v.(float64); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *Decimal128Builder:
+		if x, ok := v.(decimal128.Num); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *Date32Builder:
+		if x, ok := v.(arrow.Date32); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *Date64Builder:
+		if x, ok := v.(arrow.Date64); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *Time32Builder:
+		if x, ok := v.(arrow.Time32); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *Time64Builder:
+		if x, ok := v.(arrow.Time64); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *TimestampBuilder:
+		if x, ok := v.(arrow.Timestamp); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *DurationBuilder:
+		if x, ok := v.(arrow.Duration); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *MonthIntervalBuilder:
+		if x, ok := v.(arrow.MonthInterval); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *DayTimeIntervalBuilder:
+		if x, ok := v.(arrow.DayTimeInterval); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *MonthDayNanoIntervalBuilder:
+		if x, ok := v.(arrow.MonthDayNanoInterval); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *StringBuilder:
+		if x, ok := v.(string); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *BinaryBuilder:
+		if x, ok := v.([]byte); ok {
+			fn = func() { b.Append(x) }
+		}
+	case *FixedSizeBinaryBuilder:
+		if x, ok := v.([]byte); ok {
+			if len(x) != b.dtype.ByteWidth {
+				return nil, fmt.Errorf("value of %d bytes, want %d", len(x), b.dtype.ByteWidth)
+			}
+			fn = func() { b.Append(x) }
+		}
+	case *StructBuilder:
+		if x, ok := v.([]interface{}); ok {
+			return b.rowAppender(x)
+		}
+	}
+	if fn == nil {
+		return nil, fmt.Errorf("cannot append a %T to a %T", v, bldr)
+	}
+	return fn, nil
+}
+
 // NewArray creates a Struct array from the memory buffers used by the builder and resets the StructBuilder
 // so it can be used to build a new array.
 func (b *StructBuilder) NewArray() Interface {
@@ -250,6 +429,7 @@ func (b *StructBuilder) NewStructArray() (a *Struct) {
 }
 
 func (b *StructBuilder) newData() (data *Data) {
+	b.builder.shrinkBitmap()
 	fields := make([]*Data, len(b.fields))
 	for i, f := range b.fields {
 		arr := f.NewArray()
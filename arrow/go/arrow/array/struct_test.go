@@ -0,0 +1,180 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package array_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+var rowType = arrow.StructOf(
+	arrow.Field{Name: "id", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+	arrow.Field{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	arrow.Field{Name: "pos", Type: arrow.StructOf(
+		arrow.Field{Name: "x", Type: arrow.PrimitiveTypes.Float64},
+		arrow.Field{Name: "ok", Type: arrow.FixedWidthTypes.Boolean},
+	), Nullable: true},
+)
+
+// checkFieldLengths fails t unless every field builder of b holds n values.
+func checkFieldLengths(t *testing.T, b *array.StructBuilder, n int) {
+	t.Helper()
+	if b.Len() != n {
+		t.Fatalf("struct length=%d, want=%d", b.Len(), n)
+	}
+	for i := 0; i < b.NumField(); i++ {
+		if got := b.FieldBuilder(i).Len(); got != n {
+			t.Fatalf("field %d length=%d, want=%d", i, got, n)
+		}
+	}
+	if err := b.CheckLengths(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStructBuilderAppendRow(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	b := array.NewStructBuilder(mem, rowType)
+	defer b.Release()
+
+	if err := b.AppendRow(int64(1), "a", []interface{}{1.5, true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.AppendRow(nil, "b", nil); err != nil {
+		t.Fatal(err)
+	}
+	b.AppendNullRow()
+	if err := b.AppendRow(int64(4), nil, []interface{}{nil, false}); err != nil {
+		t.Fatal(err)
+	}
+	checkFieldLengths(t, b, 4)
+
+	arr := b.NewStructArray()
+	defer arr.Release()
+
+	if got, want := arr.NullN(), 1; got != want {
+		t.Fatalf("nulls=%d, want=%d", got, want)
+	}
+	if !arr.IsNull(2) || arr.IsNull(1) {
+		t.Fatalf("invalid struct validity")
+	}
+	ids := arr.Field(0).(*array.Int64)
+	names := arr.Field(1).(*array.String)
+	pos := arr.Field(2).(*array.Struct)
+	xs := pos.Field(0).(*array.Float64)
+	oks := pos.Field(1).(*array.Boolean)
+
+	if ids.Value(0) != 1 || ids.IsValid(1) || ids.IsValid(2) || ids.Value(3) != 4 {
+		t.Errorf("invalid ids: %v", ids)
+	}
+	if names.Value(0) != "a" || names.Value(1) != "b" || names.IsValid(2) || names.IsValid(3) {
+		t.Errorf("invalid names: %v", names)
+	}
+	// The null row is null in the nested struct and in its fields too.
+	if pos.IsNull(0) || pos.IsValid(1) || pos.IsValid(2) || pos.IsNull(3) {
+		t.Errorf("invalid pos validity: %v", pos)
+	}
+	if xs.Value(0) != 1.5 || xs.IsValid(3) || !oks.Value(0) || oks.Value(3) || oks.IsNull(3) {
+		t.Errorf("invalid pos: %v", pos)
+	}
+	for i := 0; i < pos.Len(); i++ {
+		if pos.IsNull(i) && (xs.IsValid(i) || oks.IsValid(i)) {
+			t.Errorf("pos[%d] is null but its fields are not", i)
+		}
+	}
+}
+
+func TestStructBuilderAppendRowErrors(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	b := array.NewStructBuilder(mem, rowType)
+	defer b.Release()
+
+	if err := b.AppendRow(int64(1), "a", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		row  []interface{}
+		want string
+	}{
+		{"too few", []interface{}{int64(1), "a"}, "struct row has 2 values, want 3"},
+		{"too many", []interface{}{int64(1), "a", nil, nil}, "struct row has 4 values, want 3"},
+		{"int32 for int64", []interface{}{int32(1), "a", nil}, `struct field "id": cannot append a int32`},
+		// The first fields are valid: nothing must be appended to them.
+		{"[]byte for string", []interface{}{int64(1), []byte("a"), nil}, `struct field "name": cannot append a []uint8`},
+		{"nested arity", []interface{}{int64(1), "a", []interface{}{1.5}}, `struct field "pos": struct row has 1 values, want 2`},
+		{"nested type", []interface{}{int64(1), "a", []interface{}{1.5, 1}}, `struct field "pos": struct field "ok": cannot append a int`},
+		{"scalar for struct", []interface{}{int64(1), "a", 1.5}, `struct field "pos": cannot append a float64`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := b.AppendRow(tc.row...)
+			if err == nil {
+				t.Fatalf("AppendRow(%v) did not fail", tc.row)
+			}
+			if !strings.HasPrefix(err.Error(), "arrow/array: ") || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("error=%q, want %q", err, tc.want)
+			}
+			checkFieldLengths(t, b, 1)
+			pos := b.FieldBuilder(2).(*array.StructBuilder)
+			for i := 0; i < pos.NumField(); i++ {
+				if got := pos.FieldBuilder(i).Len(); got != 1 {
+					t.Fatalf("pos field %d length=%d, want=1", i, got)
+				}
+			}
+		})
+	}
+}
+
+func TestStructBuilderCheckLengths(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	b := array.NewStructBuilder(mem, rowType)
+	defer b.Release()
+
+	b.AppendNullRow()
+	checkFieldLengths(t, b, 1)
+
+	// Appending to the struct and to its fields separately skews them.
+	b.Append(true)
+	b.FieldBuilder(0).(*array.Int64Builder).Append(2)
+	err := b.CheckLengths()
+	if err == nil {
+		t.Fatal("CheckLengths did not detect the skewed fields")
+	}
+	if want := `arrow/array: struct field "name" has 1 values, want 2`; err.Error() != want {
+		t.Fatalf("error=%q, want %q", err, want)
+	}
+
+	b.FieldBuilder(1).AppendNull()
+	b.FieldBuilder(2).AppendNull()
+	checkFieldLengths(t, b, 2)
+
+	b.FieldBuilder(1).AppendNull()
+	if err := b.CheckLengths(); err == nil || !strings.Contains(err.Error(), `"name" has 3 values, want 2`) {
+		t.Fatalf("CheckLengths=%v, want a longer field error", err)
+	}
+}
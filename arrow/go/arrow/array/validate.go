@@ -0,0 +1,279 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package array
+
+import (
+	"unicode/utf8"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"golang.org/x/xerrors"
+)
+
+// ValidateFull checks that the buffers of arr are consistent with each other
+// and with its length: the buffers are large enough, the offsets of variable
+// length values never decrease and stay within their values, the null count
+// matches the validity bitmap, the children are long enough, and dictionary
+// indices and run ends address their values. Arrays built from buffers
+// without a builder, such as by NewListFromOffsets, should be checked with it.
+func ValidateFull(arr Interface) error {
+	return validateData(arr.Data(), false)
+}
+
+// ValidateFullUTF8 is ValidateFull also checking that the non-null values of
+// strings, including nested ones, are valid UTF-8.
+func ValidateFullUTF8(arr Interface) error {
+	return validateData(arr.Data(), true)
+}
+
+func validateData(d *Data, checkUTF8 bool) error {
+	if d.length < 0 || d.offset < 0 {
+		return xerrors.Errorf("arrow/array: %s array has length %d and offset %d", d.dtype, d.length, d.offset)
+	}
+	end := d.offset + d.length
+
+	switch dtype := d.dtype.(type) {
+	case *arrow.NullType:
+		if d.nulls >= 0 && d.nulls != d.length {
+			return xerrors.Errorf("arrow/array: null array of length %d has %d nulls", d.length, d.nulls)
+		}
+		return nil
+	case *arrow.RunEndEncodedType:
+		return validateRunEnds(d, checkUTF8)
+	case *arrow.DictionaryType:
+		if err := validateBitmap(d); err != nil {
+			return err
+		}
+		if err := validateFixedWidth(d, dtype.IndexType.(arrow.FixedWidthDataType).BitWidth()); err != nil {
+			return err
+		}
+		if err := validateChildren(d, 1, checkUTF8); err != nil {
+			return err
+		}
+		n := int64(d.childData[0].length)
+		for i := d.offset; i < end; i++ {
+			if !validAt(d, i) {
+				continue
+			}
+			if k := integerAt(d, i); k < 0 || k >= n {
+				return xerrors.Errorf("arrow/array: dictionary index %d at %d out of the %d values", k, i-d.offset, n)
+			}
+		}
+		return nil
+	}
+
+	if err := validateBitmap(d); err != nil {
+		return err
+	}
+
+	switch dtype := d.dtype.(type) {
+	case *arrow.Decimal128Type:
+		return validateFixedWidth(d, 128)
+	case arrow.FixedWidthDataType:
+		return validateFixedWidth(d, dtype.BitWidth())
+	case *arrow.StringType, *arrow.BinaryType:
+		if len(d.buffers) < 3 {
+			return xerrors.Errorf("arrow/array: %s array has %d buffers, want 3", d.dtype, len(d.buffers))
+		}
+		var n int
+		if d.buffers[2] != nil {
+			n = d.buffers[2].Len()
+		}
+		offsets, err := validateOffsets(d, n)
+		if err != nil || !checkUTF8 || d.dtype.ID() != arrow.STRING {
+			return err
+		}
+		for i := 0; i < d.length; i++ {
+			if !validAt(d, d.offset+i) {
+				continue
+			}
+			if !utf8.Valid(d.buffers[2].Bytes()[offsets[i]:offsets[i+1]]) {
+				return xerrors.Errorf("arrow/array: string at %d is not valid UTF-8", i)
+			}
+		}
+		return nil
+	case *arrow.ListType:
+		if err := validateChildren(d, 1, checkUTF8); err != nil {
+			return err
+		}
+		_, err := validateOffsets(d, d.childData[0].length)
+		return err
+	case *arrow.FixedSizeListType:
+		if err := validateChildren(d, 1, checkUTF8); err != nil {
+			return err
+		}
+		if n := end * int(dtype.Len()); d.childData[0].length < n {
+			return xerrors.Errorf("arrow/array: %s array of %d lists has %d values, want %d", d.dtype, end, d.childData[0].length, n)
+		}
+		return nil
+	case *arrow.StructType:
+		if err := validateChildren(d, len(dtype.Fields()), checkUTF8); err != nil {
+			return err
+		}
+		for i, child := range d.childData {
+			if child.length < end {
+				return xerrors.Errorf("arrow/array: struct field %q has %d values, want %d", dtype.Field(i).Name, child.length, end)
+			}
+		}
+		return nil
+	default:
+		return xerrors.Errorf("arrow/array: cannot validate %s arrays", d.dtype)
+	}
+}
+
+// validateBitmap checks the validity bitmap of d against its null count.
+func validateBitmap(d *Data) error {
+	if len(d.buffers) == 0 || d.buffers[0] == nil {
+		if d.nulls > 0 {
+			return xerrors.Errorf("arrow/array: %s array has %d nulls and no validity bitmap", d.dtype, d.nulls)
+		}
+		return nil
+	}
+	end := d.offset + d.length
+	if n := int(bitutil.BytesForBits(int64(end))); d.buffers[0].Len() < n {
+		return xerrors.Errorf("arrow/array: validity bitmap of %d bytes, want %d", d.buffers[0].Len(), n)
+	}
+	if d.nulls < 0 {
+		return nil
+	}
+	if nulls := d.length - bitutil.CountSetBits(d.buffers[0].Bytes(), d.offset, d.length); nulls != d.nulls {
+		return xerrors.Errorf("arrow/array: %s array has a null count of %d, its validity bitmap %d", d.dtype, d.nulls, nulls)
+	}
+	return nil
+}
+
+// validateFixedWidth checks the size of the values buffer of d, holding
+// values of the given width in bits.
+func validateFixedWidth(d *Data, bits int) error {
+	if len(d.buffers) < 2 {
+		return xerrors.Errorf("arrow/array: %s array has %d buffers, want 2", d.dtype, len(d.buffers))
+	}
+	n := int(bitutil.BytesForBits(int64(d.offset+d.length) * int64(bits)))
+	if d.length > 0 && (d.buffers[1] == nil || d.buffers[1].Len() < n) {
+		size := 0
+		if d.buffers[1] != nil {
+			size = d.buffers[1].Len()
+		}
+		return xerrors.Errorf("arrow/array: %s values buffer of %d bytes, want %d", d.dtype, size, n)
+	}
+	return nil
+}
+
+// validateOffsets checks the int32 offsets of d into n values and returns the
+// offsets of its elements.
+func validateOffsets(d *Data, n int) ([]int32, error) {
+	if d.length == 0 {
+		return nil, nil
+	}
+	if len(d.buffers) < 2 || d.buffers[1] == nil {
+		return nil, xerrors.Errorf("arrow/array: %s array has no offsets", d.dtype)
+	}
+	all := arrow.Int32Traits.CastFromBytes(d.buffers[1].Bytes())
+	if len(all) < d.offset+d.length+1 {
+		return nil, xerrors.Errorf("arrow/array: %s array has %d offsets, want %d", d.dtype, len(all), d.offset+d.length+1)
+	}
+	offsets := all[d.offset : d.offset+d.length+1]
+	if offsets[0] < 0 {
+		return nil, xerrors.Errorf("arrow/array: %s array has a negative offset %d", d.dtype, offsets[0])
+	}
+	for i := 1; i < len(offsets); i++ {
+		if offsets[i] < offsets[i-1] {
+			return nil, xerrors.Errorf("arrow/array: %s offset %d at %d is smaller than the previous one", d.dtype, offsets[i], i)
+		}
+	}
+	if last := int(offsets[len(offsets)-1]); last > n {
+		return nil, xerrors.Errorf("arrow/array: %s offset %d out of the %d values", d.dtype, last, n)
+	}
+	return offsets, nil
+}
+
+// validateChildren checks that d has n children and validates them.
+func validateChildren(d *Data, n int, checkUTF8 bool) error {
+	if len(d.childData) != n {
+		return xerrors.Errorf("arrow/array: %s array has %d children, want %d", d.dtype, len(d.childData), n)
+	}
+	for _, child := range d.childData {
+		if child == nil {
+			return xerrors.Errorf("arrow/array: %s array has a nil child", d.dtype)
+		}
+		if err := validateData(child, checkUTF8); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRunEnds checks a run-end encoded array: its run ends are positive,
+// increasing and not null, and cover its elements.
+func validateRunEnds(d *Data, checkUTF8 bool) error {
+	if err := validateChildren(d, 2, checkUTF8); err != nil {
+		return err
+	}
+	ends, values := d.childData[0], d.childData[1]
+	if ends.length != values.length {
+		return xerrors.Errorf("arrow/array: %d run ends for %d values", ends.length, values.length)
+	}
+	if ends.nulls > 0 {
+		return xerrors.Errorf("arrow/array: run ends must not be null")
+	}
+	prev := int64(0)
+	for i := ends.offset; i < ends.offset+ends.length; i++ {
+		e := integerAt(ends, i)
+		if e <= prev {
+			return xerrors.Errorf("arrow/array: run end %d at %d is not greater than the previous one", e, i-ends.offset)
+		}
+		prev = e
+	}
+	if end := int64(d.offset + d.length); prev < end {
+		return xerrors.Errorf("arrow/array: runs cover %d elements, want %d", prev, end)
+	}
+	return nil
+}
+
+// validAt reports whether the element at position i of the buffers of d,
+// offset included, is valid.
+func validAt(d *Data, i int) bool {
+	return len(d.buffers) == 0 || d.buffers[0] == nil || bitutil.BitIsSet(d.buffers[0].Bytes(), i)
+}
+
+// integerAt returns the integer at position i of the values buffer of d,
+// offset included, holding integers of the type of d or of its indices.
+func integerAt(d *Data, i int) int64 {
+	dtype := d.dtype
+	if dict, ok := dtype.(*arrow.DictionaryType); ok {
+		dtype = dict.IndexType
+	}
+	b := d.buffers[1].Bytes()
+	switch dtype.ID() {
+	case arrow.INT8:
+		return int64(arrow.Int8Traits.CastFromBytes(b)[i])
+	case arrow.INT16:
+		return int64(arrow.Int16Traits.CastFromBytes(b)[i])
+	case arrow.INT32:
+		return int64(arrow.Int32Traits.CastFromBytes(b)[i])
+	case arrow.INT64:
+		return arrow.Int64Traits.CastFromBytes(b)[i]
+	case arrow.UINT8:
+		return int64(arrow.Uint8Traits.CastFromBytes(b)[i])
+	case arrow.UINT16:
+		return int64(arrow.Uint16Traits.CastFromBytes(b)[i])
+	case arrow.UINT32:
+		return int64(arrow.Uint32Traits.CastFromBytes(b)[i])
+	default:
+		return int64(arrow.Uint64Traits.CastFromBytes(b)[i])
+	}
+}
@@ -0,0 +1,186 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package array_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// int32Bytes returns the buffer of vs.
+func int32Bytes(vs ...int32) *memory.Buffer {
+	b := make([]byte, arrow.Int32Traits.BytesRequired(len(vs)))
+	copy(arrow.Int32Traits.CastFromBytes(b), vs)
+	return memory.NewBufferBytes(b)
+}
+
+// rawData returns the Data of a dtype array of length n made of the given
+// buffers, without checking them.
+func rawData(dtype arrow.DataType, n int, nulls int, buffers []*memory.Buffer, children ...*array.Data) *array.Data {
+	return array.NewData(dtype, n, buffers, children, nulls, 0)
+}
+
+// rawStrings returns the Data of a string array of the values delimited by
+// offsets in data, valid where bitmap is set or everywhere when it is nil.
+func rawStrings(offsets []int32, data string, bitmap []byte, nulls int) *array.Data {
+	var valid *memory.Buffer
+	if bitmap != nil {
+		valid = memory.NewBufferBytes(bitmap)
+	}
+	return rawData(arrow.BinaryTypes.String, len(offsets)-1, nulls, []*memory.Buffer{valid, int32Bytes(offsets...), memory.NewBufferBytes([]byte(data))})
+}
+
+func TestValidateFull(t *testing.T) {
+	dictType := &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: arrow.BinaryTypes.String}
+	dict := rawStrings([]int32{0, 1, 2}, "ab", nil, 0)
+	listOf := func(offsets []int32, values *array.Data) *array.Data {
+		return rawData(arrow.ListOf(values.DataType()), len(offsets)-1, 0, []*memory.Buffer{nil, int32Bytes(offsets...)}, values)
+	}
+
+	for _, tc := range []struct {
+		name string
+		data *array.Data
+		// want and wantUTF8 are the errors of ValidateFull and ValidateFullUTF8,
+		// empty when the array is valid.
+		want, wantUTF8 string
+	}{
+		{
+			name: "strings",
+			data: rawStrings([]int32{0, 1, 1, 3}, "aé", nil, 0),
+		},
+		{
+			name: "decreasing offsets",
+			data: rawStrings([]int32{0, 2, 1, 3}, "abc", nil, 0),
+			want: "utf8 offset 1 at 2 is smaller than the previous one",
+		},
+		{
+			name: "offsets past the data",
+			data: rawStrings([]int32{0, 2, 5}, "abc", nil, 0),
+			want: "utf8 offset 5 out of the 3 values",
+		},
+		{
+			name: "negative offset",
+			data: rawStrings([]int32{-1, 2}, "abc", nil, 0),
+			want: "utf8 array has a negative offset -1",
+		},
+		{
+			name: "missing offsets",
+			data: rawData(arrow.BinaryTypes.String, 3, 0, []*memory.Buffer{nil, int32Bytes(0, 1), memory.NewBufferBytes([]byte("abc"))}),
+			want: "utf8 array has 2 offsets, want 4",
+		},
+		{
+			name: "null count",
+			data: rawStrings([]int32{0, 1, 2, 3}, "abc", []byte{0x05}, 0),
+			want: "utf8 array has a null count of 0, its validity bitmap 1",
+		},
+		{
+			name:     "invalid UTF-8",
+			data:     rawStrings([]int32{0, 1, 3}, "a\xff\xfe", nil, 0),
+			wantUTF8: "string at 1 is not valid UTF-8",
+		},
+		{
+			// Invalid bytes in a null value are never read.
+			name: "invalid UTF-8 in a null",
+			data: rawStrings([]int32{0, 1, 3}, "a\xff\xfe", []byte{0x01}, 1),
+		},
+		{
+			name: "invalid UTF-8 in binary",
+			data: rawData(arrow.BinaryTypes.Binary, 1, 0, []*memory.Buffer{nil, int32Bytes(0, 2), memory.NewBufferBytes([]byte("\xff\xfe"))}),
+		},
+		{
+			name:     "invalid UTF-8 in a list",
+			data:     listOf([]int32{0, 2, 3}, rawStrings([]int32{0, 1, 2, 3}, "ab\xff", nil, 0)),
+			wantUTF8: "string at 2 is not valid UTF-8",
+		},
+		{
+			name: "list offsets past the values",
+			data: listOf([]int32{0, 2, 4}, rawStrings([]int32{0, 1, 2, 3}, "abc", nil, 0)),
+			want: "list<item: utf8> offset 4 out of the 3 values",
+		},
+		{
+			name: "dictionary",
+			data: rawData(dictType, 3, 1, []*memory.Buffer{memory.NewBufferBytes([]byte{0x05}), int32Bytes(1, 9, 0)}, dict),
+		},
+		{
+			name: "dictionary index out of range",
+			data: rawData(dictType, 3, 0, []*memory.Buffer{nil, int32Bytes(1, 2, 0)}, dict),
+			want: "dictionary index 2 at 1 out of the 2 values",
+		},
+		{
+			name: "negative dictionary index",
+			data: rawData(dictType, 2, 0, []*memory.Buffer{nil, int32Bytes(0, -1)}, dict),
+			want: "dictionary index -1 at 1 out of the 2 values",
+		},
+		{
+			name:     "invalid UTF-8 in a dictionary",
+			data:     rawData(dictType, 1, 0, []*memory.Buffer{nil, int32Bytes(0)}, rawStrings([]int32{0, 1}, "\xff", nil, 0)),
+			wantUTF8: "string at 0 is not valid UTF-8",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			arr := array.MakeFromData(tc.data)
+			defer arr.Release()
+			tc.data.Release()
+
+			wantUTF8 := tc.wantUTF8
+			if wantUTF8 == "" {
+				wantUTF8 = tc.want
+			}
+			checkValidate(t, "ValidateFull", array.ValidateFull(arr), tc.want)
+			checkValidate(t, "ValidateFullUTF8", array.ValidateFullUTF8(arr), wantUTF8)
+		})
+	}
+}
+
+func TestValidateFullSlice(t *testing.T) {
+	data := rawStrings([]int32{0, 1, 0, 2, 3}, "ab\xff", nil, 0)
+	defer data.Release()
+	arr := array.MakeFromData(data)
+	defer arr.Release()
+
+	// The decreasing offset and the invalid value lie outside of the slice.
+	slice := array.NewSlice(arr, 2, 3)
+	defer slice.Release()
+	checkValidate(t, "ValidateFullUTF8", array.ValidateFullUTF8(slice), "")
+
+	slice = array.NewSlice(arr, 1, 3)
+	defer slice.Release()
+	checkValidate(t, "ValidateFull", array.ValidateFull(slice), "utf8 offset 0 at 1 is smaller than the previous one")
+
+	slice = array.NewSlice(arr, 3, 4)
+	defer slice.Release()
+	checkValidate(t, "ValidateFullUTF8", array.ValidateFullUTF8(slice), "string at 0 is not valid UTF-8")
+}
+
+// checkValidate fails t unless err is nil when want is empty, or an
+// arrow/array error containing want.
+func checkValidate(t *testing.T, name string, err error, want string) {
+	t.Helper()
+	switch {
+	case want == "" && err != nil:
+		t.Fatalf("%s: unexpected error: %v", name, err)
+	case want == "":
+	case err == nil:
+		t.Fatalf("%s: no error, want %q", name, want)
+	case !strings.HasPrefix(err.Error(), "arrow/array: ") || !strings.Contains(err.Error(), want):
+		t.Fatalf("%s: error=%q, want %q", name, err, want)
+	}
+}
@@ -0,0 +1,89 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitutil
+
+import (
+	"math/bits"
+	"unsafe"
+)
+
+// The bitmap kernels below are selected at init time by CPU feature detection:
+// see bitmap_ops_amd64.go. The pure Go versions process 64-bit words, which
+// compilers turn into POPCNT and wide AND/OR instructions where available.
+var (
+	bitmapAnd     = bitmapAndGo
+	bitmapOr      = bitmapOrGo
+	popcountWords = popcountWordsGo
+)
+
+// BitmapAnd sets dst to the bitwise AND of a and b, byte by byte, for the
+// len(dst) bytes of dst. a and b must be at least as long as dst.
+func BitmapAnd(dst, a, b []byte) {
+	n := len(dst)
+	_, _ = a[:n], b[:n]
+	bitmapAnd(dst, a[:n], b[:n])
+}
+
+// BitmapOr sets dst to the bitwise OR of a and b, byte by byte, for the
+// len(dst) bytes of dst. a and b must be at least as long as dst.
+func BitmapOr(dst, a, b []byte) {
+	n := len(dst)
+	_, _ = a[:n], b[:n]
+	bitmapOr(dst, a[:n], b[:n])
+}
+
+func bitmapAndGo(dst, a, b []byte) {
+	words := len(dst) / uint64SizeBytes
+	dw, aw, bw := bytesToUint64(dst)[:words], bytesToUint64(a)[:words], bytesToUint64(b)[:words]
+	for i := range dw {
+		dw[i] = aw[i] & bw[i]
+	}
+	for i := words * uint64SizeBytes; i < len(dst); i++ {
+		dst[i] = a[i] & b[i]
+	}
+}
+
+func bitmapOrGo(dst, a, b []byte) {
+	words := len(dst) / uint64SizeBytes
+	dw, aw, bw := bytesToUint64(dst)[:words], bytesToUint64(a)[:words], bytesToUint64(b)[:words]
+	for i := range dw {
+		dw[i] = aw[i] | bw[i]
+	}
+	for i := words * uint64SizeBytes; i < len(dst); i++ {
+		dst[i] = a[i] | b[i]
+	}
+}
+
+// popcountWordsGo counts the set bits of words, with independent counters so
+// that the POPCNT instructions of the loop can run in parallel.
+func popcountWordsGo(words []uint64) int {
+	var c0, c1, c2, c3 int
+	n := len(words) &^ 3
+	for i := 0; i < n; i += 4 {
+		c0 += bits.OnesCount64(words[i])
+		c1 += bits.OnesCount64(words[i+1])
+		c2 += bits.OnesCount64(words[i+2])
+		c3 += bits.OnesCount64(words[i+3])
+	}
+	for _, w := range words[n:] {
+		c0 += bits.OnesCount64(w)
+	}
+	return c0 + c1 + c2 + c3
+}
+
+// wordPtr returns a pointer to the first word of words, which must not be empty.
+func wordPtr(words []uint64) unsafe.Pointer { return unsafe.Pointer(&words[0]) }
@@ -0,0 +1,73 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noasm
+// +build !noasm
+
+package bitutil
+
+import (
+	"unsafe"
+
+	"github.com/gomem/gomem/arrow/go/arrow/internal/cpu"
+)
+
+func init() {
+	if cpu.X86.HasAVX2 {
+		bitmapAnd = bitmapAndAVX2
+		bitmapOr = bitmapOrAVX2
+	}
+	if cpu.X86.HasPOPCNT {
+		popcountWords = popcountWordsPOPCNT
+	}
+}
+
+//go:noescape
+func _bitmap_and_avx2(dst, a, b unsafe.Pointer, blocks int)
+
+//go:noescape
+func _bitmap_or_avx2(dst, a, b unsafe.Pointer, blocks int)
+
+//go:noescape
+func _popcount_popcnt(words unsafe.Pointer, n int) int
+
+// avx2Block is the number of bytes processed by an iteration of the AVX2 kernels.
+const avx2Block = 32
+
+func bitmapAndAVX2(dst, a, b []byte) {
+	blocks := len(dst) / avx2Block
+	if blocks > 0 {
+		_bitmap_and_avx2(unsafe.Pointer(&dst[0]), unsafe.Pointer(&a[0]), unsafe.Pointer(&b[0]), blocks)
+	}
+	n := blocks * avx2Block
+	bitmapAndGo(dst[n:], a[n:], b[n:])
+}
+
+func bitmapOrAVX2(dst, a, b []byte) {
+	blocks := len(dst) / avx2Block
+	if blocks > 0 {
+		_bitmap_or_avx2(unsafe.Pointer(&dst[0]), unsafe.Pointer(&a[0]), unsafe.Pointer(&b[0]), blocks)
+	}
+	n := blocks * avx2Block
+	bitmapOrGo(dst[n:], a[n:], b[n:])
+}
+
+func popcountWordsPOPCNT(words []uint64) int {
+	if len(words) == 0 {
+		return 0
+	}
+	return _popcount_popcnt(wordPtr(words), len(words))
+}
@@ -0,0 +1,99 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noasm
+// +build !noasm
+
+#include "textflag.h"
+
+// func _bitmap_and_avx2(dst, a, b unsafe.Pointer, blocks int)
+TEXT ·_bitmap_and_avx2(SB), NOSPLIT, $0-32
+	MOVQ dst+0(FP), DI
+	MOVQ a+8(FP), SI
+	MOVQ b+16(FP), DX
+	MOVQ blocks+24(FP), CX
+
+and_loop:
+	VMOVDQU (SI), Y0
+	VPAND   (DX), Y0, Y0
+	VMOVDQU Y0, (DI)
+	ADDQ    $32, SI
+	ADDQ    $32, DX
+	ADDQ    $32, DI
+	DECQ    CX
+	JNZ     and_loop
+	VZEROUPPER
+	RET
+
+// func _bitmap_or_avx2(dst, a, b unsafe.Pointer, blocks int)
+TEXT ·_bitmap_or_avx2(SB), NOSPLIT, $0-32
+	MOVQ dst+0(FP), DI
+	MOVQ a+8(FP), SI
+	MOVQ b+16(FP), DX
+	MOVQ blocks+24(FP), CX
+
+or_loop:
+	VMOVDQU (SI), Y0
+	VPOR    (DX), Y0, Y0
+	VMOVDQU Y0, (DI)
+	ADDQ    $32, SI
+	ADDQ    $32, DX
+	ADDQ    $32, DI
+	DECQ    CX
+	JNZ     or_loop
+	VZEROUPPER
+	RET
+
+// func _popcount_popcnt(words unsafe.Pointer, n int) int
+TEXT ·_popcount_popcnt(SB), NOSPLIT, $0-24
+	MOVQ words+0(FP), SI
+	MOVQ n+8(FP), CX
+	XORQ AX, AX
+	XORQ BX, BX
+
+	// Four words per iteration, counted into independent registers.
+	MOVQ CX, DX
+	SHRQ $2, DX
+	JZ   popcnt_tail
+
+popcnt_loop4:
+	POPCNTQ 0(SI), R8
+	POPCNTQ 8(SI), R9
+	POPCNTQ 16(SI), R10
+	POPCNTQ 24(SI), R11
+	ADDQ    R8, AX
+	ADDQ    R9, BX
+	ADDQ    R10, AX
+	ADDQ    R11, BX
+	ADDQ    $32, SI
+	DECQ    DX
+	JNZ     popcnt_loop4
+
+popcnt_tail:
+	ANDQ $3, CX
+	JZ   popcnt_done
+
+popcnt_loop1:
+	POPCNTQ (SI), R8
+	ADDQ    R8, AX
+	ADDQ    $8, SI
+	DECQ    CX
+	JNZ     popcnt_loop1
+
+popcnt_done:
+	ADDQ BX, AX
+	MOVQ AX, ret+16(FP)
+	RET
@@ -14,10 +14,9 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// +build noasm
+//go:build noasm || !amd64
+// +build noasm !amd64
 
-package memory
+package bitutil
 
-func init() {
-	memset = memory_memset_go
-}
+// Without assembly, the pure Go kernels of bitmap_ops.go are used.
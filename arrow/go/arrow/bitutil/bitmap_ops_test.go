@@ -0,0 +1,124 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitutil
+
+import (
+	"bytes"
+	"math/bits"
+	"math/rand"
+	"testing"
+)
+
+// The kernels selected for the CPU, in assembly on amd64 with AVX2 or POPCNT,
+// are checked along with the pure Go ones.
+var bitmapOpKernels = []struct {
+	name    string
+	and, or func(dst, a, b []byte)
+	popcnt  func(words []uint64) int
+}{
+	{"selected", bitmapAnd, bitmapOr, popcountWords},
+	{"go", bitmapAndGo, bitmapOrGo, popcountWordsGo},
+}
+
+func randomBytes(rnd *rand.Rand, n int) []byte {
+	buf := make([]byte, n)
+	rnd.Read(buf)
+	return buf
+}
+
+// TestBitmapAndOr checks the kernels against byte by byte operations for
+// lengths around the 8 bytes of a word and 32 bytes of an AVX2 block, on
+// slices starting at unaligned addresses, and that the bytes of dst past its
+// length are left alone.
+func TestBitmapAndOr(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, k := range bitmapOpKernels {
+		for n := 0; n <= 100; n++ {
+			for start := 0; start < 4; start++ {
+				a := randomBytes(rnd, start+n+1)[start:]
+				b := randomBytes(rnd, start+n+1)[start:]
+				for _, op := range []struct {
+					name   string
+					kernel func(dst, a, b []byte)
+					ref    func(x, y byte) byte
+				}{
+					{"and", k.and, func(x, y byte) byte { return x & y }},
+					{"or", k.or, func(x, y byte) byte { return x | y }},
+				} {
+					want := make([]byte, n+1)
+					for i := 0; i < n; i++ {
+						want[i] = op.ref(a[i], b[i])
+					}
+					want[n] = 0xa5
+					got := make([]byte, start+n+1)[start:]
+					got[n] = 0xa5
+					op.kernel(got[:n], a[:n], b[:n])
+					if !bytes.Equal(got, want) {
+						t.Fatalf("%s %s of %d bytes at %d: got %x, want=%x", k.name, op.name, n, start, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestBitmapAndOrShortOperands(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for an operand shorter than dst")
+		}
+	}()
+	BitmapAnd(make([]byte, 4), make([]byte, 4), make([]byte, 3))
+}
+
+func TestPopcountWords(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, k := range bitmapOpKernels {
+		for n := 0; n <= 40; n++ {
+			words := make([]uint64, n)
+			want := 0
+			for i := range words {
+				switch i % 3 {
+				case 0:
+					words[i] = rnd.Uint64()
+				case 1:
+					words[i] = ^uint64(0)
+				}
+				want += bits.OnesCount64(words[i])
+			}
+			if got := k.popcnt(words); got != want {
+				t.Fatalf("%s: popcount of %d words=%d, want=%d", k.name, n, got, want)
+			}
+		}
+	}
+}
+
+func TestCountSetBits(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for n := 0; n <= 80; n++ {
+		for start := 0; start < 4; start++ {
+			buf := randomBytes(rnd, start+n)[start:]
+			want := 0
+			for _, v := range buf {
+				want += bits.OnesCount8(v)
+			}
+			if got := CountSetBits(buf, 0, 8*n); got != want {
+				t.Fatalf("CountSetBits of %d bytes at %d=%d, want=%d", n, start, got, want)
+			}
+		}
+	}
+}
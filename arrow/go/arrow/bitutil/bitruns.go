@@ -0,0 +1,119 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitutil
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// BitRun is a run of consecutive bits with the same value.
+type BitRun struct {
+	Len int
+	Set bool
+}
+
+// BitRunReader reads a bitmap as runs of set and unset bits.
+type BitRunReader struct {
+	buf []byte
+	pos int
+	end int
+}
+
+// NewBitRunReader returns a reader over the length bits of buf starting at
+// bit offset.
+func NewBitRunReader(buf []byte, offset, length int) *BitRunReader {
+	return &BitRunReader{buf: buf, pos: offset, end: offset + length}
+}
+
+// NextRun returns the next run of bits, or a run of length 0 when all bits
+// have been read.
+func (r *BitRunReader) NextRun() BitRun {
+	if r.pos >= r.end {
+		return BitRun{}
+	}
+
+	start := r.pos
+	set := BitIsSet(r.buf, r.pos)
+	for r.pos < r.end {
+		word := bitsAt(r.buf, r.pos)
+		if set {
+			word = ^word
+		}
+		n := bits.TrailingZeros64(word)
+		r.pos += n
+		if n < uint64SizeBits {
+			break
+		}
+	}
+	if r.pos > r.end {
+		r.pos = r.end
+	}
+	return BitRun{Len: r.pos - start, Set: set}
+}
+
+// CopyBitmap copies the length bits of src starting at bit srcOffset to dst
+// starting at bit dstOffset. src and dst must not overlap.
+func CopyBitmap(src []byte, srcOffset, length int, dst []byte, dstOffset int) {
+	for ; length > 0 && dstOffset%8 != 0; length-- {
+		SetBitTo(dst, dstOffset, BitIsSet(src, srcOffset))
+		srcOffset++
+		dstOffset++
+	}
+
+	// dst is byte aligned from here on
+	for ; length >= uint64SizeBits; length -= uint64SizeBits {
+		binary.LittleEndian.PutUint64(dst[dstOffset/8:], bitsAt(src, srcOffset))
+		srcOffset += uint64SizeBits
+		dstOffset += uint64SizeBits
+	}
+	for ; length >= 8; length -= 8 {
+		dst[dstOffset/8] = byte(bitsAt(src, srcOffset))
+		srcOffset += 8
+		dstOffset += 8
+	}
+
+	for ; length > 0; length-- {
+		SetBitTo(dst, dstOffset, BitIsSet(src, srcOffset))
+		srcOffset++
+		dstOffset++
+	}
+}
+
+// bitsAt returns the 64 bits of buf starting at bit offset. Bits past the end
+// of buf are 0.
+func bitsAt(buf []byte, offset int) uint64 {
+	i, shift := offset/8, uint(offset%8)
+	word := load64(buf, i) >> shift
+	if shift != 0 {
+		word |= load64(buf, i+8) << (64 - shift)
+	}
+	return word
+}
+
+// load64 returns the little endian word at byte i of buf. Bytes past the end
+// of buf are 0.
+func load64(buf []byte, i int) uint64 {
+	if i+uint64SizeBytes <= len(buf) {
+		return binary.LittleEndian.Uint64(buf[i:])
+	}
+	var word uint64
+	for j := len(buf) - 1; j >= i; j-- {
+		word = word<<8 | uint64(buf[j])
+	}
+	return word
+}
@@ -14,24 +14,24 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package memory
+//go:build go1.18
+// +build go1.18
 
-import "unsafe"
+package bitutil
 
-func roundToPowerOf2(v, round int) int {
-	forceCarry := round - 1
-	truncateMask := ^forceCarry
-	return (v + forceCarry) & truncateMask
-}
-
-func roundUpToMultipleOf64(v int) int {
-	return roundToPowerOf2(v, 64)
-}
-
-func isMultipleOfPowerOf2(v int, d int) bool {
-	return (v & (d - 1)) == 0
-}
+import "testing"
 
-func addressOf(b []byte) uintptr {
-	return uintptr(unsafe.Pointer(&b[0]))
+func FuzzBitmapOps(f *testing.F) {
+	f.Add([]byte{0xff, 0x00, 0x0f, 0xf0, 0xaa, 0x55, 0x01, 0x80, 0xff, 0x7f}, uint16(3), uint16(70), uint8(5))
+	f.Add([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff}, uint16(0), uint16(72), uint8(0))
+	f.Fuzz(func(t *testing.T, buf []byte, offset, length uint16, dstOffset uint8) {
+		bits := 8 * len(buf)
+		if int(offset) > bits {
+			offset = uint16(bits)
+		}
+		if int(offset)+int(length) > bits {
+			length = uint16(bits - int(offset))
+		}
+		checkBitmapOps(t, buf, int(offset), int(length), int(dstOffset))
+	})
 }
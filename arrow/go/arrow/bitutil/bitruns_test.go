@@ -0,0 +1,152 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitutil
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// The naive versions below read and write the bitmaps one bit at a time.
+
+func countSetBitsRef(buf []byte, offset, length int) int {
+	n := 0
+	for i := offset; i < offset+length; i++ {
+		if BitIsSet(buf, i) {
+			n++
+		}
+	}
+	return n
+}
+
+func bitRunsRef(buf []byte, offset, length int) []BitRun {
+	var runs []BitRun
+	for i := offset; i < offset+length; i++ {
+		set := BitIsSet(buf, i)
+		if len(runs) > 0 && runs[len(runs)-1].Set == set {
+			runs[len(runs)-1].Len++
+		} else {
+			runs = append(runs, BitRun{Len: 1, Set: set})
+		}
+	}
+	return runs
+}
+
+func copyBitmapRef(src []byte, srcOffset, length int, dst []byte, dstOffset int) {
+	for i := 0; i < length; i++ {
+		SetBitTo(dst, dstOffset+i, BitIsSet(src, srcOffset+i))
+	}
+}
+
+func bitRuns(buf []byte, offset, length int) []BitRun {
+	var runs []BitRun
+	r := NewBitRunReader(buf, offset, length)
+	for run := r.NextRun(); run.Len != 0; run = r.NextRun() {
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// checkBitmapOps checks CountSetBitsOffset, BitRunReader and CopyBitmap
+// against the naive versions over the length bits of buf at offset, copied to
+// dstOffset.
+func checkBitmapOps(t *testing.T, buf []byte, offset, length, dstOffset int) {
+	t.Helper()
+	if got, want := CountSetBitsOffset(buf, offset, length), countSetBitsRef(buf, offset, length); got != want {
+		t.Fatalf("CountSetBitsOffset(%x, %d, %d)=%d, want=%d", buf, offset, length, got, want)
+	}
+
+	got, want := bitRuns(buf, offset, length), bitRunsRef(buf, offset, length)
+	if len(got) != len(want) {
+		t.Fatalf("BitRunReader(%x, %d, %d)=%v, want=%v", buf, offset, length, got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("BitRunReader(%x, %d, %d)=%v, want=%v", buf, offset, length, got, want)
+		}
+	}
+
+	// the bits of dst around the copy are set, and must stay so.
+	size := int(BytesForBits(int64(dstOffset + length)))
+	gotDst, wantDst := bytes.Repeat([]byte{0xff}, size), bytes.Repeat([]byte{0xff}, size)
+	CopyBitmap(buf, offset, length, gotDst, dstOffset)
+	copyBitmapRef(buf, offset, length, wantDst, dstOffset)
+	if !bytes.Equal(gotDst, wantDst) {
+		t.Fatalf("CopyBitmap(%x, %d, %d, dst, %d)=%x, want=%x", buf, offset, length, dstOffset, gotDst, wantDst)
+	}
+}
+
+// runsBitmap returns a bitmap of alternating runs of unset and set bits of
+// the given lengths.
+func runsBitmap(lengths ...int) []byte {
+	total := 0
+	for _, n := range lengths {
+		total += n
+	}
+	buf := make([]byte, BytesForBits(int64(total)))
+	pos := 0
+	for i, n := range lengths {
+		for j := 0; j < n; j++ {
+			SetBitTo(buf, pos, i%2 == 1)
+			pos++
+		}
+	}
+	return buf
+}
+
+func TestBitmapOps(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	random := make([]byte, 40)
+	rnd.Read(random)
+
+	for _, tc := range []struct {
+		name string
+		buf  []byte
+	}{
+		{"random", random},
+		{"zeros", make([]byte, 40)},
+		{"ones", bytes.Repeat([]byte{0xff}, 40)},
+		{"alternating", bytes.Repeat([]byte{0x55}, 40)},
+		// runs ending on, before and after word boundaries, and spanning
+		// several words.
+		{"word runs", runsBitmap(64, 64, 63, 1, 65, 127, 2, 130)},
+		{"unaligned runs", runsBitmap(3, 61, 5, 70, 1, 1, 150, 9)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			bits := 8 * len(tc.buf)
+			for offset := 0; offset < 70; offset++ {
+				for length := 0; offset+length <= bits; length++ {
+					if length > 130 && length%17 != 0 {
+						// past two words, sample the lengths.
+						continue
+					}
+					checkBitmapOps(t, tc.buf, offset, length, (offset+length)%13)
+				}
+			}
+		})
+	}
+}
+
+func TestBitRunReaderEnd(t *testing.T) {
+	r := NewBitRunReader([]byte{0xff, 0x0f}, 4, 9)
+	for _, want := range []BitRun{{Len: 8, Set: true}, {Len: 1, Set: false}, {}, {}} {
+		if got := r.NextRun(); got != want {
+			t.Fatalf("got run %v, want=%v", got, want)
+		}
+	}
+}
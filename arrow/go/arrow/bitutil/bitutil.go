@@ -64,62 +64,34 @@ func SetBitTo(buf []byte, i int, val bool) {
 
 // CountSetBits counts the number of 1's in buf up to n bits.
 func CountSetBits(buf []byte, offset, n int) int {
-	if offset > 0 {
-		return countSetBitsWithOffset(buf, offset, n)
-	}
-
-	count := 0
-
-	uint64Bytes := n / uint64SizeBits * 8
-	for _, v := range bytesToUint64(buf[:uint64Bytes]) {
-		count += bits.OnesCount64(v)
-	}
-
-	for _, v := range buf[uint64Bytes : n/8] {
-		count += bits.OnesCount8(v)
-	}
-
-	// tail bits
-	for i := n &^ 0x7; i < n; i++ {
-		if BitIsSet(buf, i) {
-			count++
-		}
-	}
-
-	return count
+	return CountSetBitsOffset(buf, offset, n)
 }
 
-func countSetBitsWithOffset(buf []byte, offset, n int) int {
+// CountSetBitsOffset counts the number of 1's in the length bits of buf
+// starting at bit offset. The bits up to the first byte boundary and after the
+// last one are counted one by one, the bytes in between by 64-bit words.
+func CountSetBitsOffset(buf []byte, offset, length int) int {
 	count := 0
 
-	beg := offset
-	end := offset + n
-
-	begU8 := roundUp(beg, uint64SizeBits)
-
-	init := min(n, begU8-beg)
-	for i := offset; i < beg+init; i++ {
-		if BitIsSet(buf, i) {
+	// leading bits
+	for ; length > 0 && offset%8 != 0; offset, length = offset+1, length-1 {
+		if BitIsSet(buf, offset) {
 			count++
 		}
 	}
 
-	nU64 := (n - init) / uint64SizeBits
-	begU64 := begU8 / uint64SizeBits
-	endU64 := begU64 + nU64
-	bufU64 := bytesToUint64(buf)
-	if begU64 < len(bufU64) {
-		for _, v := range bufU64[begU64:endU64] {
-			count += bits.OnesCount64(v)
-		}
+	whole := buf[offset/8 : offset/8+length/8]
+	words := len(whole) / uint64SizeBytes * uint64SizeBytes
+	count += popcountWords(bytesToUint64(whole[:words]))
+	for _, v := range whole[words:] {
+		count += bits.OnesCount8(v)
 	}
+	offset += len(whole) * 8
+	length -= len(whole) * 8
 
-	// FIXME: use a fallback to bits.OnesCount8
-	// before counting the tail bits.
-
-	tail := beg + init + nU64*uint64SizeBits
-	for i := tail; i < end; i++ {
-		if BitIsSet(buf, i) {
+	// tail bits
+	for ; length > 0; offset, length = offset+1, length-1 {
+		if BitIsSet(buf, offset) {
 			count++
 		}
 	}
@@ -127,17 +99,6 @@ func countSetBitsWithOffset(buf []byte, offset, n int) int {
 	return count
 }
 
-func roundUp(v, f int) int {
-	return (v + (f - 1)) / f * f
-}
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
 const (
 	uint64SizeBytes = int(unsafe.Sizeof(uint64(0)))
 	uint64SizeBits  = uint64SizeBytes * 8
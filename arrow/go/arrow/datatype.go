@@ -120,6 +120,10 @@ const (
 	// Measure of elapsed time in either seconds, milliseconds, microseconds
 	// or nanoseconds.
 	DURATION
+
+	// RUN_END_ENCODED stores runs of repeated values as the end of each run
+	// and the value of the run.
+	RUN_END_ENCODED
 )
 
 // DataType is the representation of an Arrow type.
@@ -0,0 +1,41 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow
+
+import "fmt"
+
+// DictionaryType represents categorical data: an array of integer indices
+// referencing a dictionary of unique values.
+// 字典编码类型，每个元素保存的是指向 dictionary 中某个值的整数下标。
+type DictionaryType struct {
+	IndexType DataType // must be a signed or unsigned integer type
+	ValueType DataType
+	Ordered   bool // whether the order of the dictionary values is meaningful
+}
+
+func (*DictionaryType) ID() Type     { return DICTIONARY }
+func (*DictionaryType) Name() string { return "dictionary" }
+func (t *DictionaryType) String() string {
+	return fmt.Sprintf("%s<values=%s, indices=%s, ordered=%t>", t.Name(), t.ValueType, t.IndexType, t.Ordered)
+}
+
+// BitWidth returns the number of bits required to store a single index in memory.
+func (t *DictionaryType) BitWidth() int { return t.IndexType.(FixedWidthDataType).BitWidth() }
+
+var (
+	_ DataType = (*DictionaryType)(nil)
+)
@@ -16,25 +16,23 @@
 
 package arrow
 
-{{range .In}}
-type {{.Name}}Type struct {}
+import "fmt"
 
-func (t *{{.Name}}Type) ID() Type       { return {{.Name|upper}} }
-func (t *{{.Name}}Type) Name() string   { return "{{.Name|lower}}" }
-func (t *{{.Name}}Type) String() string { return "{{.Name|lower}}" }
-func (t *{{.Name}}Type) BitWidth() int  { return {{.Size}} }
+// RunEndEncodedType represents runs of repeated values. The array stores the
+// logical end of each run in a run ends array and the value of each run in a
+// values array.
+// 游程编码类型，run ends 保存每个游程的结束位置，values 保存每个游程的值。
+type RunEndEncodedType struct {
+	RunEndType DataType // must be INT16, INT32 or INT64
+	ValueType  DataType
+}
 
-
-{{end}}
+func (*RunEndEncodedType) ID() Type     { return RUN_END_ENCODED }
+func (*RunEndEncodedType) Name() string { return "run_end_encoded" }
+func (t *RunEndEncodedType) String() string {
+	return fmt.Sprintf("%s<run_ends=%s, values=%s>", t.Name(), t.RunEndType, t.ValueType)
+}
 
 var (
-        PrimitiveTypes = struct {
-{{range .In}}
-                {{.Name}} DataType
-{{- end}}
-        }{
-{{range .In}}
-                {{.Name}}: &{{.Name}}Type{},
-{{- end}}
-        }
+	_ DataType = (*RunEndEncodedType)(nil)
 )
@@ -0,0 +1,11 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build arm64
+
+package cpu
+
+// CacheLineSize pads the feature structs. The ARM64 features are not
+// detected: no kernel of this module has an arm64 version to select.
+const CacheLineSize = 64
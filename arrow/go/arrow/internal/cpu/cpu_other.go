@@ -0,0 +1,12 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !386,!amd64,!amd64p32,!arm64
+
+package cpu
+
+// CacheLineSize is a conservative value for the architectures without a file
+// of their own, used only to pad the feature structs. No features are
+// detected on them.
+const CacheLineSize = 64
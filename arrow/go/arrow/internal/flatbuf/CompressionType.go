@@ -18,16 +18,14 @@
 
 package flatbuf
 
-type Precision = int16
+type CompressionType = int8
 const (
-	PrecisionHALF Precision = 0
-	PrecisionSINGLE Precision = 1
-	PrecisionDOUBLE Precision = 2
+	CompressionTypeLZ4_FRAME CompressionType = 0
+	CompressionTypeZSTD CompressionType = 1
 )
 
-var EnumNamesPrecision = map[Precision]string{
-	PrecisionHALF:"HALF",
-	PrecisionSINGLE:"SINGLE",
-	PrecisionDOUBLE:"DOUBLE",
+var EnumNamesCompressionType = map[CompressionType]string{
+	CompressionTypeLZ4_FRAME:"LZ4_FRAME",
+	CompressionTypeZSTD:"ZSTD",
 }
 
@@ -22,10 +22,12 @@ type IntervalUnit = int16
 const (
 	IntervalUnitYEAR_MONTH IntervalUnit = 0
 	IntervalUnitDAY_TIME IntervalUnit = 1
+	IntervalUnitMONTH_DAY_NANO IntervalUnit = 2
 )
 
 var EnumNamesIntervalUnit = map[IntervalUnit]string{
 	IntervalUnitYEAR_MONTH:"YEAR_MONTH",
 	IntervalUnitDAY_TIME:"DAY_TIME",
+	IntervalUnitMONTH_DAY_NANO:"MONTH_DAY_NANO",
 }
 
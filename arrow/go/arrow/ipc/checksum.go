@@ -0,0 +1,94 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipc // import "github.com/gomem/gomem/arrow/go/arrow/ipc"
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/crc32"
+	"io"
+
+	"golang.org/x/xerrors"
+)
+
+// ErrChecksum is the error wrapped by stream readers created with
+// WithChecksum when a message does not match its checksum.
+const ErrChecksum = errString("arrow/ipc: message checksum mismatch")
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// checksumWriter writes to w, computing the CRC-32C of the bytes written since
+// the last checksum it wrote.
+type checksumWriter struct {
+	w   io.Writer
+	crc hash.Hash32
+}
+
+func newChecksumWriter(w io.Writer) *checksumWriter {
+	return &checksumWriter{w: w, crc: crc32.New(castagnoli)}
+}
+
+func (w *checksumWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.crc.Write(p[:n])
+	return n, err
+}
+
+// writeChecksum writes the checksum of the bytes written since the last
+// checksum and starts a new one.
+func (w *checksumWriter) writeChecksum() error {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], w.crc.Sum32())
+	w.crc.Reset()
+	_, err := w.w.Write(buf[:])
+	if err != nil {
+		return xerrors.Errorf("arrow/ipc: could not write message checksum: %w", err)
+	}
+	return nil
+}
+
+// checksumReader reads from r, computing the CRC-32C of the bytes read since
+// the last checksum it verified.
+type checksumReader struct {
+	r   io.Reader
+	crc hash.Hash32
+}
+
+func newChecksumReader(r io.Reader) *checksumReader {
+	return &checksumReader{r: r, crc: crc32.New(castagnoli)}
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.crc.Write(p[:n])
+	return n, err
+}
+
+// verify reads the checksum of the bytes read since the last checksum and
+// compares it with theirs. part names what the bytes hold in errors.
+func (r *checksumReader) verify(part string) error {
+	var buf [4]byte
+	if _, err := io.ReadFull(r.r, buf[:]); err != nil {
+		return xerrors.Errorf("arrow/ipc: could not read message %s checksum: %w", part, err)
+	}
+	sum := r.crc.Sum32()
+	r.crc.Reset()
+	if binary.LittleEndian.Uint32(buf[:]) != sum {
+		return xerrors.Errorf("arrow/ipc: invalid message %s: %w", part, ErrChecksum)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipc // import "github.com/gomem/gomem/arrow/go/arrow/ipc"
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/gomem/gomem/arrow/go/arrow/internal/flatbuf"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"golang.org/x/xerrors"
+)
+
+// CompressionType identifies the codec used to compress the buffers of a
+// record batch body, as recorded in the message metadata.
+type CompressionType int8
+
+const (
+	// LZ4Frame compresses each buffer as an LZ4 frame.
+	LZ4Frame = CompressionType(flatbuf.CompressionTypeLZ4_FRAME)
+	// Zstd compresses each buffer as a Zstandard frame.
+	Zstd = CompressionType(flatbuf.CompressionTypeZSTD)
+)
+
+func (t CompressionType) String() string {
+	if name, ok := flatbuf.EnumNamesCompressionType[flatbuf.CompressionType(t)]; ok {
+		return name
+	}
+	return "CompressionType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Codec compresses and decompresses the buffers of record batch bodies.
+// A Codec must be safe for concurrent use.
+//
+// Codec 用于压缩、解压 record batch body 中的每个 buffer 。
+type Codec interface {
+	// Type returns the compression type recorded in the message metadata.
+	Type() CompressionType
+	// Compress appends the compressed form of src to dst and returns the extended slice.
+	Compress(dst, src []byte) ([]byte, error)
+	// Decompress fills dst, sized to the uncompressed length, from src.
+	// src may be followed by padding bytes. Decompress fails when src
+	// decompresses to fewer or more bytes than len(dst).
+	Decompress(dst, src []byte) error
+}
+
+var codecs = struct {
+	sync.RWMutex
+	m map[CompressionType]Codec
+}{
+	m: map[CompressionType]Codec{
+		LZ4Frame: lz4Codec{},
+		Zstd:     &zstdCodec{},
+	},
+}
+
+// RegisterCodec makes codec available to readers for the compression type it
+// reports, replacing any codec registered for that type.
+func RegisterCodec(codec Codec) {
+	codecs.Lock()
+	defer codecs.Unlock()
+	codecs.m[codec.Type()] = codec
+}
+
+// codecFor returns the codec registered for t.
+func codecFor(t CompressionType) (Codec, error) {
+	codecs.RLock()
+	defer codecs.RUnlock()
+	codec, ok := codecs.m[t]
+	if !ok {
+		return nil, xerrors.Errorf("arrow/ipc: no codec registered for compression type %s", t)
+	}
+	return codec, nil
+}
+
+// MaxDecompressedBufferSize bounds the uncompressed length readers accept for
+// a compressed body buffer, so that a corrupted length prefix is reported as an
+// error rather than attempting a huge allocation. It defaults to 256 MiB;
+// raise it to read streams with larger buffers.
+var MaxDecompressedBufferSize int64 = 1 << 28
+
+// uncompressedLength is the prefix written before a buffer whose bytes
+// are stored uncompressed because compressing them did not save space.
+const uncompressedLength = -1
+
+// compressBuffer returns buf compressed with codec, prefixed by the uncompressed
+// length as a 64-bit little-endian integer, following the IPC body compression spec.
+func compressBuffer(codec Codec, buf []byte) ([]byte, error) {
+	out := make([]byte, 8, 8+len(buf))
+	out, err := codec.Compress(out, buf)
+	if err != nil {
+		return nil, err
+	}
+	if len(out)-8 >= len(buf) {
+		out = append(out[:8], buf...)
+		putInt64(out, uncompressedLength)
+		return out, nil
+	}
+	putInt64(out, int64(len(buf)))
+	return out, nil
+}
+
+func putInt64(b []byte, v int64) { binary.LittleEndian.PutUint64(b, uint64(v)) }
+
+// decompressBuffer reverses compressBuffer.
+func decompressBuffer(codec Codec, raw []byte) ([]byte, error) {
+	if len(raw) < 8 {
+		return nil, xerrors.Errorf("arrow/ipc: compressed buffer too short (%d bytes)", len(raw))
+	}
+	n := int64(binary.LittleEndian.Uint64(raw))
+	switch {
+	case n == uncompressedLength:
+		return raw[8:], nil
+	case n < 0:
+		return nil, xerrors.Errorf("arrow/ipc: invalid uncompressed buffer length %d", n)
+	case n > MaxDecompressedBufferSize:
+		return nil, xerrors.Errorf("arrow/ipc: uncompressed buffer length %d exceeds the limit of %d bytes", n, MaxDecompressedBufferSize)
+	}
+	out := make([]byte, n)
+	if err := codec.Decompress(out, raw[8:]); err != nil {
+		return nil, xerrors.Errorf("arrow/ipc: could not decompress buffer with %s: %w", codec.Type(), err)
+	}
+	return out, nil
+}
+
+type lz4Codec struct{}
+
+func (lz4Codec) Type() CompressionType { return LZ4Frame }
+
+func (lz4Codec) Compress(dst, src []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	w := lz4.NewWriter(buf)
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (lz4Codec) Decompress(dst, src []byte) error {
+	return readExactly(lz4.NewReader(bytes.NewReader(src)), dst)
+}
+
+type zstdCodec struct {
+	once sync.Once
+	enc  *zstd.Encoder
+	err  error
+}
+
+func (*zstdCodec) Type() CompressionType { return Zstd }
+
+func (c *zstdCodec) Compress(dst, src []byte) ([]byte, error) {
+	c.once.Do(func() {
+		c.enc, c.err = zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+	})
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.enc.EncodeAll(src, dst), nil
+}
+
+func (*zstdCodec) Decompress(dst, src []byte) error {
+	// the stream decoder stops at the end of the frame, ignoring the padding.
+	dec, err := zstd.NewReader(bytes.NewReader(src), zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return err
+	}
+	defer dec.Close()
+	return readExactly(dec, dst)
+}
+
+// readExactly fills dst from the decompressed stream r and checks that the
+// stream ends there. Only decompressed bytes count as trailing data: the
+// padding after a frame makes the decoder fail without yielding any.
+func readExactly(r io.Reader, dst []byte) error {
+	if n, err := io.ReadFull(r, dst); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return xerrors.Errorf("decompressed %d bytes, expected %d", n, len(dst))
+		}
+		return err
+	}
+	var extra [1]byte
+	if n, _ := io.ReadFull(r, extra[:]); n != 0 {
+		return xerrors.Errorf("decompressed more than the expected %d bytes", len(dst))
+	}
+	return nil
+}
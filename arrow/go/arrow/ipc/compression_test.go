@@ -0,0 +1,343 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/flatbuf"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func newCompressionRecord(mem memory.Allocator, random bool) array.Record {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "i64", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "str", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		v := int64(i % 10)
+		if random {
+			v = rnd.Int63()
+		}
+		if i%7 == 0 {
+			b.Field(0).(*array.Int64Builder).AppendNull()
+		} else {
+			b.Field(0).(*array.Int64Builder).Append(v)
+		}
+		b.Field(1).(*array.StringBuilder).Append(strings.Repeat("x", i%5))
+	}
+	return b.NewRecord()
+}
+
+func TestCompressionStreamRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opt  Option
+	}{
+		{"lz4", WithLZ4()},
+		{"zstd", WithZstd()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer mem.AssertSize(t, 0)
+
+			rec := newCompressionRecord(mem, false)
+			defer rec.Release()
+
+			var plain, compressed bytes.Buffer
+			for _, out := range []struct {
+				buf  *bytes.Buffer
+				opts []Option
+			}{
+				{&plain, nil},
+				{&compressed, []Option{tc.opt}},
+			} {
+				w := NewWriter(out.buf, append(out.opts, WithSchema(rec.Schema()), WithAllocator(mem))...)
+				if err := w.Write(rec); err != nil {
+					t.Fatal(err)
+				}
+				if err := w.Close(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if compressed.Len() >= plain.Len() {
+				t.Fatalf("compressed stream of %d bytes, plain stream of %d", compressed.Len(), plain.Len())
+			}
+
+			r, err := NewReader(&compressed, WithAllocator(mem))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Release()
+			if !r.Next() {
+				t.Fatalf("no record read: %v", r.Err())
+			}
+			if !array.RecordEqual(r.Record(), rec) {
+				t.Fatalf("got %v, want=%v", r.Record(), rec)
+			}
+			if r.Next() || r.Err() != nil {
+				t.Fatalf("unexpected end of stream: %v", r.Err())
+			}
+		})
+	}
+}
+
+func TestCompressionFileRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		opt    Option
+		random bool
+	}{
+		{"lz4", WithLZ4(), false},
+		{"zstd", WithZstd(), false},
+		{"zstd-incompressible", WithZstd(), true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer mem.AssertSize(t, 0)
+
+			rec := newCompressionRecord(mem, tc.random)
+			defer rec.Release()
+
+			f, err := ioutil.TempFile("", "arrow-ipc-")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(f.Name())
+			defer f.Close()
+
+			w, err := NewFileWriter(f, WithSchema(rec.Schema()), WithAllocator(mem), tc.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 2; i++ {
+				if err := w.Write(rec); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := NewFileReader(f, WithAllocator(mem))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			if got, want := r.NumRecords(), 2; got != want {
+				t.Fatalf("got %d records, want=%d", got, want)
+			}
+			for i := 0; i < r.NumRecords(); i++ {
+				got, err := r.Record(i)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !array.RecordEqual(got, rec) {
+					t.Fatalf("record %d: got %v, want=%v", i, got, rec)
+				}
+			}
+		})
+	}
+}
+
+func TestCompressBufferStoresIncompressible(t *testing.T) {
+	codec, err := codecFor(LZ4Frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 256)
+	rand.New(rand.NewSource(1)).Read(buf)
+
+	raw, err := compressBuffer(codec, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := int64(binary.LittleEndian.Uint64(raw)), int64(uncompressedLength); got != want {
+		t.Fatalf("got length prefix %d, want=%d", got, want)
+	}
+	if !bytes.Equal(raw[8:], buf) {
+		t.Fatal("incompressible buffer not stored as is")
+	}
+	out, err := decompressBuffer(codec, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, buf) {
+		t.Fatal("stored buffer not read back as is")
+	}
+}
+
+func TestDecompressBufferInvalidLength(t *testing.T) {
+	codec, err := codecFor(Zstd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int64{-2, -1 << 63, MaxDecompressedBufferSize + 1, 1 << 62} {
+		raw := make([]byte, 16)
+		putInt64(raw, n)
+		if _, err := decompressBuffer(codec, raw); err == nil {
+			t.Errorf("length %d: expected an error", n)
+		}
+	}
+	if _, err := decompressBuffer(codec, make([]byte, 4)); err == nil {
+		t.Error("expected an error for a buffer shorter than its length prefix")
+	}
+}
+
+func TestDecompressBufferLengthMismatch(t *testing.T) {
+	buf := bytes.Repeat([]byte("arrow"), 300)
+	for _, typ := range []CompressionType{LZ4Frame, Zstd} {
+		codec, err := codecFor(typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := compressBuffer(codec, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the writer pads buffers, the padding is not trailing data.
+		out, err := decompressBuffer(codec, append(raw[:len(raw):len(raw)], make([]byte, 7)...))
+		if err != nil {
+			t.Fatalf("%s: padded buffer: %v", typ, err)
+		}
+		if !bytes.Equal(out, buf) {
+			t.Fatalf("%s: padded buffer not read back", typ)
+		}
+
+		for _, n := range []int{0, 1, len(buf) / 2, len(buf) - 1, len(buf) + 1, 2 * len(buf)} {
+			bad := append([]byte(nil), raw...)
+			putInt64(bad, int64(n))
+			if _, err := decompressBuffer(codec, bad); err == nil {
+				t.Errorf("%s: length prefix %d for %d bytes: expected an error", typ, n, len(buf))
+			}
+		}
+		if _, err := decompressBuffer(codec, raw[:len(raw)/2]); err == nil {
+			t.Errorf("%s: expected an error for a truncated stream", typ)
+		}
+	}
+}
+
+func TestCompressedBufferOutOfBody(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	rec := newCompressionRecord(mem, false)
+	defer rec.Release()
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, WithSchema(rec.Schema()), WithAllocator(mem), WithLZ4())
+	if err := w.Write(rec); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// skip the schema and keep the metadata and body of the record batch.
+	mr := NewMessageReader(&buf, WithAllocator(mem))
+	defer mr.Release()
+	var meta, body []byte
+	for i := 0; i < 2; i++ {
+		msg, err := mr.Message()
+		if err != nil {
+			t.Fatal(err)
+		}
+		meta = append([]byte(nil), msg.meta.Bytes()...)
+		body = append([]byte(nil), msg.body.Bytes()...)
+	}
+
+	size := int64(len(body))
+	for _, tc := range []struct {
+		name   string
+		off, n int64
+	}{
+		{"negative offset", -8, 8},
+		{"negative length", 0, -8},
+		{"offset past the body", size + 8, 8},
+		{"length past the body", 0, size + 1},
+		{"huge length", 8, 1 << 40},
+		{"overflowing end", 1 << 62, 1 << 62},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			corrupt := append([]byte(nil), meta...)
+			var md flatbuf.RecordBatch
+			initFB(&md, flatbuf.GetRootAsMessage(corrupt, 0).Header)
+			var fb flatbuf.Buffer
+			if !md.Buffers(&fb, 1) || !fb.MutateOffset(tc.off) || !fb.MutateLength(tc.n) {
+				t.Fatal("could not corrupt the buffer metadata")
+			}
+
+			for _, r := range []ReadAtSeeker{bytes.NewReader(body), newBytesBody(body)} {
+				got, err := newRecord(rec.Schema(), memory.NewBufferBytes(corrupt), r)
+				if err == nil {
+					got.Release()
+					t.Fatalf("%T: expected an error", r)
+				}
+				if !strings.Contains(err.Error(), "out of the message body") {
+					t.Fatalf("%T: got error %v, want one about the message body", r, err)
+				}
+			}
+		})
+	}
+}
+
+// unregisteredCodec compresses with LZ4 under a compression type no reader knows.
+type unregisteredCodec struct{ lz4Codec }
+
+func (unregisteredCodec) Type() CompressionType { return CompressionType(42) }
+
+func TestCompressionUnregisteredCodec(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	rec := newCompressionRecord(mem, false)
+	defer rec.Release()
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, WithSchema(rec.Schema()), WithAllocator(mem), WithCompression(unregisteredCodec{}))
+	if err := w.Write(rec); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&buf, WithAllocator(mem))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+	if r.Next() {
+		t.Fatal("read a record compressed with an unregistered codec")
+	}
+	if err := r.Err(); err == nil || !strings.Contains(err.Error(), "no codec registered") {
+		t.Fatalf("got error %v, want one about the unregistered codec", err)
+	}
+}
@@ -14,18 +14,18 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package ipc // import "github.com/apache/arrow/go/arrow/ipc"
+package ipc // import "github.com/gomem/gomem/arrow/go/arrow/ipc"
 
 import (
 	"bytes"
 	"encoding/binary"
 	"io"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/array"
-	"github.com/apache/arrow/go/arrow/bitutil"
-	"github.com/apache/arrow/go/arrow/internal/flatbuf"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/flatbuf"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 	"golang.org/x/xerrors"
 )
 
@@ -44,6 +44,9 @@ type FileReader struct {
 
 	schema *arrow.Schema
 	record array.Record
+	xform  RecordTransform
+	utf8   array.UTF8Policy
+	mem    memory.Allocator
 
 	irec int   // current record index. used for the arrio.Reader interface
 	err  error // last error
@@ -59,6 +62,9 @@ func NewFileReader(r ReadAtSeeker, opts ...Option) (*FileReader, error) {
 			r:      r,
 			fields: make(dictTypeMap),
 			memo:   newMemo(),
+			xform:  cfg.xform,
+			utf8:   cfg.utf8,
+			mem:    cfg.alloc,
 		}
 	)
 
@@ -273,9 +279,17 @@ func (f *FileReader) Record(i int) (array.Record, error) {
 
 	if f.record != nil {
 		f.record.Release()
+		f.record = nil
 	}
 
-	f.record = newRecord(f.schema, msg.meta, bytes.NewReader(msg.body.Bytes()))
+	rec, err := newRecord(f.schema, msg.meta, bytes.NewReader(msg.body.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	defer rec.Release()
+	if f.record, err = readRecord(f.mem, f.utf8, f.xform, rec); err != nil {
+		return nil, err
+	}
 	return f.record, nil
 }
 
@@ -298,7 +312,18 @@ func (f *FileReader) ReadAt(i int64) (array.Record, error) {
 	return f.Record(int(i))
 }
 
-func newRecord(schema *arrow.Schema, meta *memory.Buffer, body ReadAtSeeker) array.Record {
+func newRecord(schema *arrow.Schema, meta *memory.Buffer, body ReadAtSeeker) (rec array.Record, err error) {
+	// the loaders panic on malformed bodies, report them as errors instead.
+	defer func() {
+		if e := recover(); e != nil {
+			if pErr, ok := e.(error); ok {
+				err = xerrors.Errorf("arrow/ipc: could not read record: %w", pErr)
+			} else {
+				err = xerrors.Errorf("arrow/ipc: could not read record: %v", e)
+			}
+		}
+	}()
+
 	var (
 		msg = flatbuf.GetRootAsMessage(meta.Bytes(), 0)
 		md  flatbuf.RecordBatch
@@ -306,10 +331,27 @@ func newRecord(schema *arrow.Schema, meta *memory.Buffer, body ReadAtSeeker) arr
 	initFB(&md, msg.Header)
 	rows := md.Length()
 
+	var codec Codec
+	if compression := md.Compression(nil); compression != nil {
+		if compression.Method() != flatbuf.BodyCompressionMethodBUFFER {
+			return nil, xerrors.Errorf("arrow/ipc: unsupported body compression method %d", compression.Method())
+		}
+		if codec, err = codecFor(CompressionType(compression.Codec())); err != nil {
+			return nil, err
+		}
+	}
+
+	size, err := body.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, xerrors.Errorf("arrow/ipc: could not size message body: %w", err)
+	}
+
 	ctx := &arrayLoaderContext{
 		src: ipcSource{
-			meta: &md,
-			r:    body,
+			meta:  &md,
+			r:     body,
+			size:  size,
+			codec: codec,
 		},
 		max: kMaxNestingDepth,
 	}
@@ -319,12 +361,14 @@ func newRecord(schema *arrow.Schema, meta *memory.Buffer, body ReadAtSeeker) arr
 		cols[i] = ctx.loadArray(field.Type)
 	}
 
-	return array.NewRecord(schema, cols, rows)
+	return array.NewRecord(schema, cols, rows), nil
 }
 
 type ipcSource struct {
-	meta *flatbuf.RecordBatch
-	r    ReadAtSeeker
+	meta  *flatbuf.RecordBatch
+	r     ReadAtSeeker
+	size  int64 // length of the body, which bounds every buffer
+	codec Codec // decompresses the buffers when not nil
 }
 
 func (src *ipcSource) buffer(i int) *memory.Buffer {
@@ -336,15 +380,52 @@ func (src *ipcSource) buffer(i int) *memory.Buffer {
 		return memory.NewBufferBytes(nil)
 	}
 
-	raw := make([]byte, buf.Length())
-	_, err := src.r.ReadAt(raw, buf.Offset())
-	if err != nil {
-		panic(err)
+	// the offset and length come from the message, check them before
+	// allocating so that a corrupted one cannot request a huge buffer.
+	if off, n := buf.Offset(), buf.Length(); off < 0 || n < 0 || off > src.size || n > src.size-off {
+		panic(xerrors.Errorf("arrow/ipc: buffer %d at offset %d with length %d out of the message body of %d bytes", i, off, n, src.size))
+	}
+
+	var raw []byte
+	if body, ok := src.r.(bytesBody); ok {
+		raw = body.slice(buf.Offset(), buf.Length())
+	} else {
+		raw = make([]byte, buf.Length())
+		_, err := src.r.ReadAt(raw, buf.Offset())
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	if src.codec != nil {
+		var err error
+		if raw, err = decompressBuffer(src.codec, raw); err != nil {
+			panic(err)
+		}
 	}
 
 	return memory.NewBufferBytes(raw)
 }
 
+// bytesBody is the body of a message held in memory, whose buffers are sliced
+// out of it rather than copied.
+type bytesBody struct {
+	*bytes.Reader
+	data []byte
+}
+
+func newBytesBody(data []byte) bytesBody {
+	return bytesBody{Reader: bytes.NewReader(data), data: data}
+}
+
+// slice returns the n bytes of the body at off.
+func (b bytesBody) slice(off, n int64) []byte {
+	if off < 0 || n < 0 || off > int64(len(b.data)) || n > int64(len(b.data))-off {
+		panic(xerrors.Errorf("arrow/ipc: buffer [%d, %d) out of the message body of %d bytes", off, off+n, len(b.data)))
+	}
+	return b.data[off : off+n : off+n]
+}
+
 func (src *ipcSource) fieldMetadata(i int) *flatbuf.FieldNode {
 	var node flatbuf.FieldNode
 	if !src.meta.Nodes(&node, i) {
@@ -385,7 +466,7 @@ func (ctx *arrayLoaderContext) loadArray(dt arrow.DataType) array.Interface {
 		*arrow.Time32Type, *arrow.Time64Type,
 		*arrow.TimestampType,
 		*arrow.Date32Type, *arrow.Date64Type,
-		*arrow.MonthIntervalType, *arrow.DayTimeIntervalType,
+		*arrow.MonthIntervalType, *arrow.DayTimeIntervalType, *arrow.MonthDayNanoIntervalType,
 		*arrow.DurationType:
 		return ctx.loadPrimitive(dt)
 
@@ -14,17 +14,17 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package ipc // import "github.com/apache/arrow/go/arrow/ipc"
+package ipc // import "github.com/gomem/gomem/arrow/go/arrow/ipc"
 
 import (
 	"encoding/binary"
 	"io"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/array"
-	"github.com/apache/arrow/go/arrow/bitutil"
-	"github.com/apache/arrow/go/arrow/internal/flatbuf"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/flatbuf"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 	"golang.org/x/xerrors"
 )
 
@@ -157,8 +157,11 @@ func writeIPCPayload(w io.Writer, p payload) (int, error) {
 	if err != nil {
 		return n, err
 	}
+	return n, writePayloadBody(w, p)
+}
 
-	// now write the buffers
+// writePayloadBody writes the buffers of the body of p, padded to 8 bytes.
+func writePayloadBody(w io.Writer, p payload) error {
 	for _, buf := range p.body {
 		var (
 			size    int64
@@ -172,21 +175,21 @@ func writeIPCPayload(w io.Writer, p payload) (int, error) {
 		}
 
 		if size > 0 {
-			_, err = w.Write(buf.Bytes())
+			_, err := w.Write(buf.Bytes())
 			if err != nil {
-				return n, xerrors.Errorf("arrow/ipc: could not write payload message body: %w", err)
+				return xerrors.Errorf("arrow/ipc: could not write payload message body: %w", err)
 			}
 		}
 
 		if padding > 0 {
-			_, err = w.Write(paddingBytes[:padding])
+			_, err := w.Write(paddingBytes[:padding])
 			if err != nil {
-				return n, xerrors.Errorf("arrow/ipc: could not write payload message padding: %w", err)
+				return xerrors.Errorf("arrow/ipc: could not write payload message padding: %w", err)
 			}
 		}
 	}
 
-	return n, err
+	return nil
 }
 
 type payload struct {
@@ -236,6 +239,8 @@ type FileWriter struct {
 	pw payloadWriter
 
 	schema *arrow.Schema
+	codec  Codec
+	xform  RecordTransform
 }
 
 // NewFileWriter opens an Arrow file using the provided writer w.
@@ -250,6 +255,8 @@ func NewFileWriter(w io.WriteSeeker, opts ...Option) (*FileWriter, error) {
 		pw:     &pwriter{w: w, schema: cfg.schema, pos: -1},
 		mem:    cfg.alloc,
 		schema: cfg.schema,
+		codec:  cfg.codec,
+		xform:  cfg.xform,
 	}
 
 	pos, err := f.w.Seek(0, io.SeekCurrent)
@@ -290,10 +297,16 @@ func (f *FileWriter) Write(rec array.Record) error {
 		return xerrors.Errorf("arrow/ipc: could not write header: %w", err)
 	}
 
+	rec, err := transformRecord(f.xform, rec)
+	if err != nil {
+		return err
+	}
+	defer rec.Release()
+
 	const allow64b = true
 	var (
 		data = payload{msg: MessageRecordBatch}
-		enc  = newRecordEncoder(f.mem, 0, kMaxNestingDepth, allow64b)
+		enc  = newRecordEncoder(f.mem, 0, kMaxNestingDepth, allow64b, f.codec)
 	)
 	defer data.Release()
 
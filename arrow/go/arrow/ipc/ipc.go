@@ -14,14 +14,15 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package ipc // import "github.com/apache/arrow/go/arrow/ipc"
+package ipc // import "github.com/gomem/gomem/arrow/go/arrow/ipc"
 
 import (
 	"io"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/arrio"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/arrio"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 )
 
 const (
@@ -62,6 +63,10 @@ type ReadAtSeeker interface {
 type config struct {
 	alloc  memory.Allocator // 内存分配器
 	schema *arrow.Schema    //
+	codec  Codec            // 写入时用于压缩 body buffers，nil 表示不压缩
+	xform  RecordTransform  // 写入前、读取后改写 record，nil 表示不改写
+	crc    bool             // stream 的每个 message 之后是否带 CRC-32C 校验和
+	utf8   array.UTF8Policy // 读取时如何处理非法 UTF-8 字符串
 	footer struct {
 		offset int64
 	}
@@ -104,6 +109,62 @@ func WithSchema(schema *arrow.Schema) Option {
 	}
 }
 
+// WithCompression specifies the codec used by writers to compress the buffers
+// of record batch bodies. Readers decompress such bodies with the codec
+// registered for their compression type, see RegisterCodec.
+func WithCompression(codec Codec) Option {
+	return func(cfg *config) {
+		cfg.codec = codec
+	}
+}
+
+// RecordTransform rewrites a record as it is written or read. The returned
+// record must have the schema of rec and is released by the caller, so a
+// transform keeping rec unchanged returns it retained.
+type RecordTransform func(rec array.Record) (array.Record, error)
+
+// WithRecordTransform specifies a transform applied by writers to every
+// record before encoding it, and by readers to every record they decode.
+func WithRecordTransform(fn RecordTransform) Option {
+	return func(cfg *config) {
+		cfg.xform = fn
+	}
+}
+
+// WithChecksum makes stream writers follow the metadata and the body of every
+// message with their CRC-32C checksum, and stream readers verify them before
+// decoding the message, so that a corrupted stream is reported as ErrChecksum.
+// Both ends of a stream must use the option. Files are not affected.
+func WithChecksum() Option {
+	return func(cfg *config) {
+		cfg.crc = true
+	}
+}
+
+// WithUTF8Validation makes readers check that the strings of the String
+// columns of the records they read are valid UTF-8, applying policy to the
+// strings that are not: UTF8Replace replaces their invalid sequences and
+// UTF8Error fails the read.
+func WithUTF8Validation(policy array.UTF8Policy) Option {
+	return func(cfg *config) {
+		cfg.utf8 = policy
+	}
+}
+
+// WithLZ4 is WithCompression using the LZ4 frame codec.
+func WithLZ4() Option { return withRegisteredCodec(LZ4Frame) }
+
+// WithZstd is WithCompression using the Zstandard codec.
+func WithZstd() Option { return withRegisteredCodec(Zstd) }
+
+func withRegisteredCodec(t CompressionType) Option {
+	codec, err := codecFor(t)
+	if err != nil {
+		panic(err)
+	}
+	return WithCompression(codec)
+}
+
 var (
 	_ arrio.Reader = (*Reader)(nil)
 	_ arrio.Writer = (*Writer)(nil)
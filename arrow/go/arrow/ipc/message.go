@@ -14,17 +14,18 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package ipc // import "github.com/apache/arrow/go/arrow/ipc"
+package ipc // import "github.com/gomem/gomem/arrow/go/arrow/ipc"
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
 	"sync/atomic"
 
-	"github.com/apache/arrow/go/arrow/internal/debug"
-	"github.com/apache/arrow/go/arrow/internal/flatbuf"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/debug"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/flatbuf"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 	"golang.org/x/xerrors"
 )
 
@@ -143,15 +144,38 @@ func (msg *Message) BodyLen() int64 {
 
 // MessageReader reads messages from an io.Reader.
 type MessageReader struct {
-	r io.Reader
+	r   io.Reader
+	crc *checksumReader // verifies the checksums of the messages when not nil
+
+	data      []byte // unread messages, when reading from bytes
+	fromBytes bool
 
 	refCount int64
 	msg      *Message
 }
 
 // NewMessageReader returns a reader that reads messages from an input stream.
-func NewMessageReader(r io.Reader) *MessageReader {
-	return &MessageReader{r: r, refCount: 1}
+// With WithChecksum, the checksums of the messages are verified.
+func NewMessageReader(r io.Reader, opts ...Option) *MessageReader {
+	cfg := newConfig(opts...)
+	mr := &MessageReader{r: r, refCount: 1}
+	if cfg.crc {
+		mr.crc = newChecksumReader(r)
+		mr.r = mr.crc
+	}
+	return mr
+}
+
+// NewMessageReaderFromBytes returns a reader that reads messages from data
+// without copying them: the metadata and the body of the messages point into
+// data, which must not be modified while they are in use. With WithChecksum,
+// the messages are copied as their checksums are verified.
+func NewMessageReaderFromBytes(data []byte, opts ...Option) *MessageReader {
+	cfg := newConfig(opts...)
+	if cfg.crc {
+		return NewMessageReader(bytes.NewReader(data), opts...)
+	}
+	return &MessageReader{data: data, fromBytes: true, refCount: 1}
 }
 
 // Retain increases the reference count by 1.
@@ -179,7 +203,7 @@ func (r *MessageReader) Release() {
 // It is valid until the next call to Message.
 func (r *MessageReader) Message() (*Message, error) {
 	var buf = make([]byte, 4)
-	_, err := io.ReadFull(r.r, buf)
+	_, err := r.read(buf)
 	if err != nil {
 		return nil, xerrors.Errorf("arrow/ipc: could not read continuation indicator: %w", err)
 	}
@@ -192,7 +216,7 @@ func (r *MessageReader) Message() (*Message, error) {
 		// EOS message.
 		return nil, io.EOF // FIXME(sbinet): send nil instead? or a special EOS error?
 	case kIPCContToken:
-		_, err = io.ReadFull(r.r, buf)
+		_, err = r.read(buf)
 		if err != nil {
 			return nil, xerrors.Errorf("arrow/ipc: could not read message length: %w", err)
 		}
@@ -208,20 +232,28 @@ func (r *MessageReader) Message() (*Message, error) {
 		msgLen = int32(cid)
 	}
 
-	buf = make([]byte, msgLen)
-	_, err = io.ReadFull(r.r, buf)
+	buf, err = r.next(int64(msgLen))
 	if err != nil {
 		return nil, xerrors.Errorf("arrow/ipc: could not read message metadata: %w", err)
 	}
+	if r.crc != nil {
+		if err := r.crc.verify("metadata"); err != nil {
+			return nil, err
+		}
+	}
 
 	meta := flatbuf.GetRootAsMessage(buf, 0)
 	bodyLen := meta.BodyLength()
 
-	buf = make([]byte, bodyLen)
-	_, err = io.ReadFull(r.r, buf)
+	buf, err = r.next(bodyLen)
 	if err != nil {
 		return nil, xerrors.Errorf("arrow/ipc: could not read message body: %w", err)
 	}
+	if r.crc != nil {
+		if err := r.crc.verify("body"); err != nil {
+			return nil, err
+		}
+	}
 	body := memory.NewBufferBytes(buf)
 
 	if r.msg != nil {
@@ -232,3 +264,34 @@ func (r *MessageReader) Message() (*Message, error) {
 
 	return r.msg, nil
 }
+
+// read fills buf with the next bytes of the stream.
+func (r *MessageReader) read(buf []byte) (int, error) {
+	if !r.fromBytes {
+		return io.ReadFull(r.r, buf)
+	}
+	next, err := r.next(int64(len(buf)))
+	return copy(buf, next), err
+}
+
+// next returns the next n bytes of the stream, sliced out of the data of a
+// reader from bytes and read into a new slice otherwise.
+func (r *MessageReader) next(n int64) ([]byte, error) {
+	if !r.fromBytes {
+		buf := make([]byte, n)
+		_, err := io.ReadFull(r.r, buf)
+		return buf, err
+	}
+	switch {
+	case n < 0:
+		return nil, xerrors.Errorf("arrow/ipc: invalid message size %d", n)
+	case len(r.data) == 0 && n > 0:
+		return nil, io.EOF
+	case int64(len(r.data)) < n:
+		r.data = nil
+		return nil, io.ErrUnexpectedEOF
+	}
+	buf := r.data[:n:n]
+	r.data = r.data[n:]
+	return buf, nil
+}
@@ -14,18 +14,18 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package ipc // import "github.com/apache/arrow/go/arrow/ipc"
+package ipc // import "github.com/gomem/gomem/arrow/go/arrow/ipc"
 
 import (
 	"bytes"
 	"io"
 	"sync/atomic"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/array"
-	"github.com/apache/arrow/go/arrow/internal/debug"
-	"github.com/apache/arrow/go/arrow/internal/flatbuf"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/debug"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/flatbuf"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 	"golang.org/x/xerrors"
 )
 
@@ -43,23 +43,41 @@ type Reader struct {
 	types dictTypeMap
 	memo  dictMemo
 
-	mem memory.Allocator
+	mem   memory.Allocator
+	xform RecordTransform
+	utf8  array.UTF8Policy
 
 	done bool
 }
 
 // NewReader returns a reader that reads records from an input stream.
 func NewReader(r io.Reader, opts ...Option) (*Reader, error) {
+	return newReader(NewMessageReader(r, opts...), opts...)
+}
+
+// NewReaderFromBytes returns a reader that reads records from a stream held
+// in data without copying it: the buffers of the records point into data,
+// which must not be modified while they are in use. The buffers of
+// compressed records are still decompressed into new memory, and WithChecksum
+// copies the messages as their checksums are verified.
+func NewReaderFromBytes(data []byte, opts ...Option) (*Reader, error) {
+	return newReader(NewMessageReaderFromBytes(data, opts...), opts...)
+}
+
+func newReader(mr *MessageReader, opts ...Option) (*Reader, error) {
 	cfg := newConfig()
 	for _, opt := range opts {
 		opt(cfg)
 	}
 
 	rr := &Reader{
-		r:     NewMessageReader(r),
-		types: make(dictTypeMap),
-		memo:  newMemo(),
-		mem:   cfg.alloc,
+		r:        mr,
+		refCount: 1,
+		types:    make(dictTypeMap),
+		memo:     newMemo(),
+		mem:      cfg.alloc,
+		xform:    cfg.xform,
+		utf8:     cfg.utf8,
 	}
 
 	err := rr.readSchema(cfg.schema)
@@ -168,8 +186,52 @@ func (r *Reader) next() bool {
 		return false
 	}
 
-	r.rec = newRecord(r.schema, msg.meta, bytes.NewReader(msg.body.Bytes()))
-	return true
+	var body ReadAtSeeker = bytes.NewReader(msg.body.Bytes())
+	if r.r.fromBytes {
+		body = newBytesBody(msg.body.Bytes())
+	}
+	rec, err := newRecord(r.schema, msg.meta, body)
+	if err != nil {
+		r.err = err
+		return false
+	}
+	defer rec.Release()
+	r.rec, r.err = readRecord(r.mem, r.utf8, r.xform, rec)
+	return r.err == nil
+}
+
+// readRecord applies the UTF-8 policy and then the transform of a reader to a
+// record it decoded.
+func readRecord(mem memory.Allocator, policy array.UTF8Policy, xform RecordTransform, rec array.Record) (array.Record, error) {
+	if policy == array.UTF8Unchecked {
+		return transformRecord(xform, rec)
+	}
+
+	var cols []array.Interface
+	for i, col := range rec.Columns() {
+		strs, ok := col.(*array.String)
+		if !ok {
+			continue
+		}
+		j := array.FindInvalidUTF8(strs)
+		if j < 0 {
+			continue
+		}
+		if policy == array.UTF8Error {
+			return nil, xerrors.Errorf("arrow/ipc: string %d of column %q is not valid UTF-8", j, rec.ColumnName(i))
+		}
+		if cols == nil {
+			cols = append([]array.Interface(nil), rec.Columns()...)
+		}
+		cols[i] = array.ReplaceInvalidUTF8(mem, strs)
+		defer cols[i].Release()
+	}
+	if cols == nil {
+		return transformRecord(xform, rec)
+	}
+	valid := array.NewRecord(rec.Schema(), cols, rec.NumRows())
+	defer valid.Release()
+	return transformRecord(xform, valid)
 }
 
 // Record returns the current record that has been extracted from the
@@ -14,22 +14,23 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package ipc // import "github.com/apache/arrow/go/arrow/ipc"
+package ipc // import "github.com/gomem/gomem/arrow/go/arrow/ipc"
 
 import (
 	"io"
 	"math"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/array"
-	"github.com/apache/arrow/go/arrow/bitutil"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 	"golang.org/x/xerrors"
 )
 
 type swriter struct {
 	w   io.Writer
 	pos int64
+	crc bool // follow the metadata and the body of messages with their checksum
 }
 
 func (w *swriter) start() error { return nil }
@@ -39,11 +40,22 @@ func (w *swriter) Close() error {
 }
 
 func (w *swriter) write(p payload) error {
-	_, err := writeIPCPayload(w, p)
-	if err != nil {
+	if !w.crc {
+		_, err := writeIPCPayload(w, p)
 		return err
 	}
-	return nil
+
+	cw := newChecksumWriter(w)
+	if _, err := writeMessage(p.meta, kArrowIPCAlignment, cw); err != nil {
+		return err
+	}
+	if err := cw.writeChecksum(); err != nil {
+		return err
+	}
+	if err := writePayloadBody(cw, p); err != nil {
+		return err
+	}
+	return cw.writeChecksum()
 }
 
 func (w *swriter) Write(p []byte) (int, error) {
@@ -61,6 +73,8 @@ type Writer struct {
 
 	started bool
 	schema  *arrow.Schema
+	codec   Codec
+	xform   RecordTransform
 }
 
 // NewWriter returns a writer that writes records to the provided output stream.
@@ -69,8 +83,10 @@ func NewWriter(w io.Writer, opts ...Option) *Writer {
 	return &Writer{
 		w:      w,
 		mem:    cfg.alloc,
-		pw:     &swriter{w: w},
+		pw:     &swriter{w: w, crc: cfg.crc},
 		schema: cfg.schema,
+		codec:  cfg.codec,
+		xform:  cfg.xform,
 	}
 }
 
@@ -108,10 +124,16 @@ func (w *Writer) Write(rec array.Record) error {
 		return errInconsistentSchema
 	}
 
+	rec, err := transformRecord(w.xform, rec)
+	if err != nil {
+		return err
+	}
+	defer rec.Release()
+
 	const allow64b = true
 	var (
 		data = payload{msg: MessageRecordBatch}
-		enc  = newRecordEncoder(w.mem, 0, kMaxNestingDepth, allow64b)
+		enc  = newRecordEncoder(w.mem, 0, kMaxNestingDepth, allow64b, w.codec)
 	)
 	defer data.Release()
 
@@ -122,6 +144,23 @@ func (w *Writer) Write(rec array.Record) error {
 	return w.pw.write(data)
 }
 
+// transformRecord applies xform to rec, returning a record the caller releases.
+func transformRecord(xform RecordTransform, rec array.Record) (array.Record, error) {
+	if xform == nil {
+		rec.Retain()
+		return rec, nil
+	}
+	out, err := xform(rec)
+	if err != nil {
+		return nil, xerrors.Errorf("arrow/ipc: could not transform record: %w", err)
+	}
+	if !out.Schema().Equal(rec.Schema()) {
+		out.Release()
+		return nil, errInconsistentSchema
+	}
+	return out, nil
+}
+
 func (w *Writer) start() error {
 	w.started = true
 
@@ -148,14 +187,16 @@ type recordEncoder struct {
 	depth    int64
 	start    int64
 	allow64b bool
+	codec    Codec // compresses the body buffers when not nil
 }
 
-func newRecordEncoder(mem memory.Allocator, startOffset, maxDepth int64, allow64b bool) *recordEncoder {
+func newRecordEncoder(mem memory.Allocator, startOffset, maxDepth int64, allow64b bool, codec Codec) *recordEncoder {
 	return &recordEncoder{
 		mem:      mem,
 		start:    startOffset,
 		depth:    maxDepth,
 		allow64b: allow64b,
+		codec:    codec,
 	}
 }
 
@@ -169,6 +210,12 @@ func (w *recordEncoder) Encode(p *payload, rec array.Record) error {
 		}
 	}
 
+	if w.codec != nil {
+		if err := w.compressBodyBuffers(p); err != nil {
+			return xerrors.Errorf("arrow/ipc: could not compress record batch body: %w", err)
+		}
+	}
+
 	// position for the start of a buffer relative to the passed frame of reference.
 	// may be 0 or some other position in an address space.
 	offset := w.start
@@ -421,8 +468,25 @@ func (w *recordEncoder) getZeroBasedValueOffsets(arr array.Interface) (*memory.B
 	return voffsets, nil
 }
 
+// compressBodyBuffers replaces every non-empty buffer of the body by its
+// compressed form, each buffer being compressed on its own.
+func (w *recordEncoder) compressBodyBuffers(p *payload) error {
+	for i, buf := range p.body {
+		if buf == nil || buf.Len() == 0 {
+			continue
+		}
+		out, err := compressBuffer(w.codec, buf.Bytes())
+		if err != nil {
+			return err
+		}
+		buf.Release()
+		p.body[i] = memory.NewBufferBytes(out)
+	}
+	return nil
+}
+
 func (w *recordEncoder) encodeMetadata(p *payload, nrows int64) error {
-	p.meta = writeRecordMessage(w.mem, nrows, p.size, w.fields, w.meta)
+	p.meta = writeRecordMessage(w.mem, nrows, p.size, w.fields, w.meta, w.codec)
 	return nil
 }
 
@@ -14,11 +14,13 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// +build !assert
-
-package debug
-
-// Assert will panic with msg if cond is false.
+// Package math provides optimized mathematical functions for processing Arrow arrays.
+//
+// The kernels are selected at init time by CPU feature detection: on amd64
+// with AVX2 they run in assembly, elsewhere, or when built with the noasm tag,
+// they run in pure Go loops written so that compilers can keep several
+// independent accumulators in flight.
 //
-// msg must be a string, func() string or fmt.Stringer.
-func Assert(cond bool, msg interface{}) {}
+// There are no arm64 kernels: the tests do not run on arm64, so the pure Go
+// loops are used there rather than assembly that could not be checked.
+package math
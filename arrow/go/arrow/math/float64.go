@@ -0,0 +1,184 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package math
+
+import (
+	"math"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+)
+
+// Float64Funcs holds the kernels of Float64 arrays.
+type Float64Funcs struct {
+	sum      func(values []float64) float64
+	min, max func(values []float64) float64
+}
+
+// Float64 holds the kernels of Float64 arrays selected for the CPU.
+var Float64 = Float64Funcs{sum: sumFloat64Go, min: minFloat64Go, max: maxFloat64Go}
+
+// Sum returns the sum of the non-null values of a. The values are added in
+// several independent sums, so the result may differ in the last bits from the
+// sum computed in order.
+func (f Float64Funcs) Sum(a *array.Float64) float64 {
+	values := a.Float64Values()
+	if a.NullN() == 0 {
+		return f.sum(values)
+	}
+	var sum float64
+	for i, v := range values {
+		if a.IsValid(i) {
+			sum += v
+		}
+	}
+	return sum
+}
+
+// Min returns the smallest non-null value of a, and false if there is none.
+// It returns NaN if a value is NaN, even when another is -Inf, unlike
+// math.Min, and -0 is smaller than +0.
+func (f Float64Funcs) Min(a *array.Float64) (float64, bool) {
+	return f.extreme(a, f.min, minFloat64)
+}
+
+// Max returns the largest non-null value of a, and false if there is none.
+// It returns NaN if a value is NaN, even when another is +Inf, unlike
+// math.Max, and +0 is larger than -0.
+func (f Float64Funcs) Max(a *array.Float64) (float64, bool) {
+	return f.extreme(a, f.max, maxFloat64)
+}
+
+func (f Float64Funcs) extreme(a *array.Float64, kernel func([]float64) float64, pick func(x, y float64) float64) (float64, bool) {
+	values := a.Float64Values()
+	if a.NullN() == 0 {
+		if len(values) == 0 {
+			return 0, false
+		}
+		return kernel(values), true
+	}
+
+	var m float64
+	ok := false
+	for i, v := range values {
+		if !a.IsValid(i) {
+			continue
+		}
+		if ok {
+			m = pick(m, v)
+		} else {
+			m, ok = v, true
+		}
+	}
+	return m, ok
+}
+
+func sumFloat64Go(values []float64) float64 {
+	var s0, s1, s2, s3 float64
+	n := len(values) &^ 3
+	for i := 0; i < n; i += 4 {
+		s0 += values[i]
+		s1 += values[i+1]
+		s2 += values[i+2]
+		s3 += values[i+3]
+	}
+	for _, v := range values[n:] {
+		s0 += v
+	}
+	return (s0 + s1) + (s2 + s3)
+}
+
+// minFloat64 returns the smaller of x and y like Float64Funcs.Min: NaN if
+// either is NaN, and -0 for zeros of different signs.
+func minFloat64(x, y float64) float64 {
+	switch {
+	case x != x:
+		return x
+	case y != y:
+		return y
+	case y < x, y == x && math.Signbit(y):
+		return y
+	}
+	return x
+}
+
+// maxFloat64 returns the larger of x and y like Float64Funcs.Max: NaN if
+// either is NaN, and +0 for zeros of different signs.
+func maxFloat64(x, y float64) float64 {
+	switch {
+	case x != x:
+		return x
+	case y != y:
+		return y
+	case y > x, y == x && !math.Signbit(y):
+		return y
+	}
+	return x
+}
+
+// minFloat64Go returns the smallest of values, which must not be empty, like
+// a fold of minFloat64 would but with plain comparisons in the loop.
+func minFloat64Go(values []float64) float64 {
+	m := values[0]
+	if m != m {
+		return m
+	}
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		} else if v != v {
+			return v
+		}
+	}
+	if m == 0 {
+		return signedZero(values, true)
+	}
+	return m
+}
+
+// maxFloat64Go returns the largest of values, which must not be empty, like
+// a fold of maxFloat64 would but with plain comparisons in the loop.
+func maxFloat64Go(values []float64) float64 {
+	m := values[0]
+	if m != m {
+		return m
+	}
+	for _, v := range values[1:] {
+		if v > m {
+			m = v
+		} else if v != v {
+			return v
+		}
+	}
+	if m == 0 {
+		return signedZero(values, false)
+	}
+	return m
+}
+
+// signedZero returns -0 if negative and values hold a -0, or +0 if not
+// negative and values hold a +0, and the other zero otherwise.
+func signedZero(values []float64, negative bool) float64 {
+	for _, v := range values {
+		if v == 0 && math.Signbit(v) == negative {
+			return v
+		}
+	}
+	if negative {
+		return 0
+	}
+	return math.Copysign(0, -1)
+}
@@ -0,0 +1,111 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package math
+
+import (
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+)
+
+// Int64Funcs holds the kernels of Int64 arrays.
+type Int64Funcs struct {
+	sum      func(values []int64) int64
+	min, max func(values []int64) int64
+}
+
+// Int64 holds the kernels of Int64 arrays selected for the CPU.
+var Int64 = Int64Funcs{sum: sumInt64Go, min: minInt64Go, max: maxInt64Go}
+
+// Sum returns the sum of the non-null values of a, wrapping around on overflow.
+func (f Int64Funcs) Sum(a *array.Int64) int64 {
+	values := a.Int64Values()
+	if a.NullN() == 0 {
+		return f.sum(values)
+	}
+	var sum int64
+	for i, v := range values {
+		if a.IsValid(i) {
+			sum += v
+		}
+	}
+	return sum
+}
+
+// Min returns the smallest non-null value of a, and false if there is none.
+func (f Int64Funcs) Min(a *array.Int64) (int64, bool) {
+	return f.extreme(a, f.min, func(v, m int64) bool { return v < m })
+}
+
+// Max returns the largest non-null value of a, and false if there is none.
+func (f Int64Funcs) Max(a *array.Int64) (int64, bool) {
+	return f.extreme(a, f.max, func(v, m int64) bool { return v > m })
+}
+
+func (f Int64Funcs) extreme(a *array.Int64, kernel func([]int64) int64, better func(v, m int64) bool) (int64, bool) {
+	values := a.Int64Values()
+	if a.NullN() == 0 {
+		if len(values) == 0 {
+			return 0, false
+		}
+		return kernel(values), true
+	}
+
+	var m int64
+	ok := false
+	for i, v := range values {
+		if a.IsValid(i) && (!ok || better(v, m)) {
+			m, ok = v, true
+		}
+	}
+	return m, ok
+}
+
+func sumInt64Go(values []int64) int64 {
+	var s0, s1, s2, s3 int64
+	n := len(values) &^ 3
+	for i := 0; i < n; i += 4 {
+		s0 += values[i]
+		s1 += values[i+1]
+		s2 += values[i+2]
+		s3 += values[i+3]
+	}
+	for _, v := range values[n:] {
+		s0 += v
+	}
+	return s0 + s1 + s2 + s3
+}
+
+// minInt64Go returns the smallest of values, which must not be empty.
+func minInt64Go(values []int64) int64 {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// maxInt64Go returns the largest of values, which must not be empty.
+func maxInt64Go(values []int64) int64 {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v > m {
+			m = v
+		}
+	}
+	return m
+}
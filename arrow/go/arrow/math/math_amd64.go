@@ -0,0 +1,143 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noasm
+// +build !noasm
+
+package math
+
+import (
+	"math"
+	"unsafe"
+
+	"github.com/gomem/gomem/arrow/go/arrow/internal/cpu"
+)
+
+func init() {
+	if cpu.X86.HasAVX2 {
+		Int64 = Int64Funcs{sum: sumInt64AVX2, min: minInt64AVX2, max: maxInt64AVX2}
+		Float64 = Float64Funcs{sum: sumFloat64AVX2, min: minFloat64AVX2, max: maxFloat64AVX2}
+	}
+}
+
+// The sums process 16 values per iteration in four registers, the extremes 8
+// values in two registers. The values left over are handled in Go.
+const (
+	sumBlock     = 16
+	extremeBlock = 8
+)
+
+//go:noescape
+func _sum_int64_avx2(values unsafe.Pointer, blocks int) int64
+
+//go:noescape
+func _min_int64_avx2(values unsafe.Pointer, blocks int) int64
+
+//go:noescape
+func _max_int64_avx2(values unsafe.Pointer, blocks int) int64
+
+//go:noescape
+func _sum_float64_avx2(values unsafe.Pointer, blocks int) float64
+
+//go:noescape
+func _min_float64_avx2(values unsafe.Pointer, blocks int) (m float64, nan bool)
+
+//go:noescape
+func _max_float64_avx2(values unsafe.Pointer, blocks int) (m float64, nan bool)
+
+func sumInt64AVX2(values []int64) int64 {
+	blocks := len(values) / sumBlock
+	if blocks == 0 {
+		return sumInt64Go(values)
+	}
+	return _sum_int64_avx2(unsafe.Pointer(&values[0]), blocks) + sumInt64Go(values[blocks*sumBlock:])
+}
+
+func minInt64AVX2(values []int64) int64 {
+	blocks := len(values) / extremeBlock
+	if blocks == 0 {
+		return minInt64Go(values)
+	}
+	m := _min_int64_avx2(unsafe.Pointer(&values[0]), blocks)
+	if rest := values[blocks*extremeBlock:]; len(rest) > 0 {
+		if r := minInt64Go(rest); r < m {
+			m = r
+		}
+	}
+	return m
+}
+
+func maxInt64AVX2(values []int64) int64 {
+	blocks := len(values) / extremeBlock
+	if blocks == 0 {
+		return maxInt64Go(values)
+	}
+	m := _max_int64_avx2(unsafe.Pointer(&values[0]), blocks)
+	if rest := values[blocks*extremeBlock:]; len(rest) > 0 {
+		if r := maxInt64Go(rest); r > m {
+			m = r
+		}
+	}
+	return m
+}
+
+func sumFloat64AVX2(values []float64) float64 {
+	blocks := len(values) / sumBlock
+	if blocks == 0 {
+		return sumFloat64Go(values)
+	}
+	return _sum_float64_avx2(unsafe.Pointer(&values[0]), blocks) + sumFloat64Go(values[blocks*sumBlock:])
+}
+
+// VMINPD and VMAXPD neither propagate NaN nor order zeros of different signs
+// like Float64Funcs.Min and Max, so the kernels report NaNs and zero results
+// are computed again in Go.
+
+func minFloat64AVX2(values []float64) float64 {
+	blocks := len(values) / extremeBlock
+	if blocks == 0 {
+		return minFloat64Go(values)
+	}
+	m, nan := _min_float64_avx2(unsafe.Pointer(&values[0]), blocks)
+	if nan {
+		return math.NaN()
+	}
+	if rest := values[blocks*extremeBlock:]; len(rest) > 0 {
+		m = minFloat64(m, minFloat64Go(rest))
+	}
+	if m == 0 {
+		return minFloat64Go(values)
+	}
+	return m
+}
+
+func maxFloat64AVX2(values []float64) float64 {
+	blocks := len(values) / extremeBlock
+	if blocks == 0 {
+		return maxFloat64Go(values)
+	}
+	m, nan := _max_float64_avx2(unsafe.Pointer(&values[0]), blocks)
+	if nan {
+		return math.NaN()
+	}
+	if rest := values[blocks*extremeBlock:]; len(rest) > 0 {
+		m = maxFloat64(m, maxFloat64Go(rest))
+	}
+	if m == 0 {
+		return maxFloat64Go(values)
+	}
+	return m
+}
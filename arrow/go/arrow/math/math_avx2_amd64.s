@@ -0,0 +1,231 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noasm
+// +build !noasm
+
+#include "textflag.h"
+
+// func _sum_int64_avx2(values unsafe.Pointer, blocks int) int64
+TEXT ·_sum_int64_avx2(SB), NOSPLIT, $0-24
+	MOVQ  values+0(FP), SI
+	MOVQ  blocks+8(FP), CX
+	VPXOR Y0, Y0, Y0
+	VPXOR Y1, Y1, Y1
+	VPXOR Y2, Y2, Y2
+	VPXOR Y3, Y3, Y3
+
+sum_int64_loop:
+	VPADDQ 0(SI), Y0, Y0
+	VPADDQ 32(SI), Y1, Y1
+	VPADDQ 64(SI), Y2, Y2
+	VPADDQ 96(SI), Y3, Y3
+	ADDQ   $128, SI
+	DECQ   CX
+	JNZ    sum_int64_loop
+
+	VPADDQ       Y1, Y0, Y0
+	VPADDQ       Y3, Y2, Y2
+	VPADDQ       Y2, Y0, Y0
+	VEXTRACTI128 $1, Y0, X1
+	VPADDQ       X1, X0, X0
+	VPSHUFD      $0x4e, X0, X1
+	VPADDQ       X1, X0, X0
+	VMOVQ        X0, AX
+	VZEROUPPER
+	MOVQ         AX, ret+16(FP)
+	RET
+
+// func _min_int64_avx2(values unsafe.Pointer, blocks int) int64
+TEXT ·_min_int64_avx2(SB), NOSPLIT, $0-24
+	MOVQ    values+0(FP), SI
+	MOVQ    blocks+8(FP), CX
+	VMOVDQU 0(SI), Y0
+	VMOVDQU 32(SI), Y1
+	ADDQ    $64, SI
+	DECQ    CX
+	JZ      min_int64_reduce
+
+min_int64_loop:
+	// Y0 = Y0 > Y2 ? Y2 : Y0
+	VMOVDQU   0(SI), Y2
+	VMOVDQU   32(SI), Y3
+	VPCMPGTQ  Y2, Y0, Y4
+	VPCMPGTQ  Y3, Y1, Y5
+	VPBLENDVB Y4, Y2, Y0, Y0
+	VPBLENDVB Y5, Y3, Y1, Y1
+	ADDQ      $64, SI
+	DECQ      CX
+	JNZ       min_int64_loop
+
+min_int64_reduce:
+	VPCMPGTQ     Y1, Y0, Y4
+	VPBLENDVB    Y4, Y1, Y0, Y0
+	VEXTRACTI128 $1, Y0, X1
+	VPCMPGTQ     X1, X0, X4
+	VPBLENDVB    X4, X1, X0, X0
+	VPSHUFD      $0x4e, X0, X1
+	VPCMPGTQ     X1, X0, X4
+	VPBLENDVB    X4, X1, X0, X0
+	VMOVQ        X0, AX
+	VZEROUPPER
+	MOVQ         AX, ret+16(FP)
+	RET
+
+// func _max_int64_avx2(values unsafe.Pointer, blocks int) int64
+TEXT ·_max_int64_avx2(SB), NOSPLIT, $0-24
+	MOVQ    values+0(FP), SI
+	MOVQ    blocks+8(FP), CX
+	VMOVDQU 0(SI), Y0
+	VMOVDQU 32(SI), Y1
+	ADDQ    $64, SI
+	DECQ    CX
+	JZ      max_int64_reduce
+
+max_int64_loop:
+	// Y0 = Y2 > Y0 ? Y2 : Y0
+	VMOVDQU   0(SI), Y2
+	VMOVDQU   32(SI), Y3
+	VPCMPGTQ  Y0, Y2, Y4
+	VPCMPGTQ  Y1, Y3, Y5
+	VPBLENDVB Y4, Y2, Y0, Y0
+	VPBLENDVB Y5, Y3, Y1, Y1
+	ADDQ      $64, SI
+	DECQ      CX
+	JNZ       max_int64_loop
+
+max_int64_reduce:
+	VPCMPGTQ     Y0, Y1, Y4
+	VPBLENDVB    Y4, Y1, Y0, Y0
+	VEXTRACTI128 $1, Y0, X1
+	VPCMPGTQ     X0, X1, X4
+	VPBLENDVB    X4, X1, X0, X0
+	VPSHUFD      $0x4e, X0, X1
+	VPCMPGTQ     X0, X1, X4
+	VPBLENDVB    X4, X1, X0, X0
+	VMOVQ        X0, AX
+	VZEROUPPER
+	MOVQ         AX, ret+16(FP)
+	RET
+
+// func _sum_float64_avx2(values unsafe.Pointer, blocks int) float64
+TEXT ·_sum_float64_avx2(SB), NOSPLIT, $0-24
+	MOVQ   values+0(FP), SI
+	MOVQ   blocks+8(FP), CX
+	VXORPD Y0, Y0, Y0
+	VXORPD Y1, Y1, Y1
+	VXORPD Y2, Y2, Y2
+	VXORPD Y3, Y3, Y3
+
+sum_float64_loop:
+	VADDPD 0(SI), Y0, Y0
+	VADDPD 32(SI), Y1, Y1
+	VADDPD 64(SI), Y2, Y2
+	VADDPD 96(SI), Y3, Y3
+	ADDQ   $128, SI
+	DECQ   CX
+	JNZ    sum_float64_loop
+
+	VADDPD       Y1, Y0, Y0
+	VADDPD       Y3, Y2, Y2
+	VADDPD       Y2, Y0, Y0
+	VEXTRACTF128 $1, Y0, X1
+	VADDPD       X1, X0, X0
+	VPERMILPD    $1, X0, X1
+	VADDSD       X1, X0, X0
+	VZEROUPPER
+	MOVSD        X0, ret+16(FP)
+	RET
+
+// func _min_float64_avx2(values unsafe.Pointer, blocks int) (m float64, nan bool)
+TEXT ·_min_float64_avx2(SB), NOSPLIT, $0-25
+	MOVQ    values+0(FP), SI
+	MOVQ    blocks+8(FP), CX
+	VMOVUPD 0(SI), Y0
+	VMOVUPD 32(SI), Y1
+
+	// Y8 accumulates the lanes holding NaNs.
+	VCMPPD  $3, Y0, Y0, Y8
+	VCMPPD  $3, Y1, Y1, Y9
+	VORPD   Y9, Y8, Y8
+	ADDQ    $64, SI
+	DECQ    CX
+	JZ      min_float64_reduce
+
+min_float64_loop:
+	VMOVUPD 0(SI), Y2
+	VMOVUPD 32(SI), Y3
+	VCMPPD  $3, Y2, Y2, Y4
+	VCMPPD  $3, Y3, Y3, Y5
+	VORPD   Y4, Y8, Y8
+	VORPD   Y5, Y8, Y8
+	VMINPD  Y2, Y0, Y0
+	VMINPD  Y3, Y1, Y1
+	ADDQ    $64, SI
+	DECQ    CX
+	JNZ     min_float64_loop
+
+min_float64_reduce:
+	VMINPD       Y1, Y0, Y0
+	VEXTRACTF128 $1, Y0, X1
+	VMINPD       X1, X0, X0
+	VPERMILPD    $1, X0, X1
+	VMINSD       X1, X0, X0
+	VMOVMSKPD    Y8, AX
+	VZEROUPPER
+	MOVSD        X0, m+16(FP)
+	TESTL        AX, AX
+	SETNE        nan+24(FP)
+	RET
+
+// func _max_float64_avx2(values unsafe.Pointer, blocks int) (m float64, nan bool)
+TEXT ·_max_float64_avx2(SB), NOSPLIT, $0-25
+	MOVQ    values+0(FP), SI
+	MOVQ    blocks+8(FP), CX
+	VMOVUPD 0(SI), Y0
+	VMOVUPD 32(SI), Y1
+	VCMPPD  $3, Y0, Y0, Y8
+	VCMPPD  $3, Y1, Y1, Y9
+	VORPD   Y9, Y8, Y8
+	ADDQ    $64, SI
+	DECQ    CX
+	JZ      max_float64_reduce
+
+max_float64_loop:
+	VMOVUPD 0(SI), Y2
+	VMOVUPD 32(SI), Y3
+	VCMPPD  $3, Y2, Y2, Y4
+	VCMPPD  $3, Y3, Y3, Y5
+	VORPD   Y4, Y8, Y8
+	VORPD   Y5, Y8, Y8
+	VMAXPD  Y2, Y0, Y0
+	VMAXPD  Y3, Y1, Y1
+	ADDQ    $64, SI
+	DECQ    CX
+	JNZ     max_float64_loop
+
+max_float64_reduce:
+	VMAXPD       Y1, Y0, Y0
+	VEXTRACTF128 $1, Y0, X1
+	VMAXPD       X1, X0, X0
+	VPERMILPD    $1, X0, X1
+	VMAXSD       X1, X0, X0
+	VMOVMSKPD    Y8, AX
+	VZEROUPPER
+	MOVSD        X0, m+16(FP)
+	TESTL        AX, AX
+	SETNE        nan+24(FP)
+	RET
@@ -14,10 +14,9 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// +build wasm
+//go:build noasm || !amd64
+// +build noasm !amd64
 
-package memory
+package math
 
-func init() {
-	memset = memory_memset_go
-}
+// Without assembly, the pure Go kernels are used.
@@ -0,0 +1,230 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package math
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// The kernels selected for the CPU, in assembly on amd64 with AVX2, are
+// checked along with the pure Go ones.
+var (
+	float64Kernels = map[string]Float64Funcs{
+		"selected": Float64,
+		"go":       {sum: sumFloat64Go, min: minFloat64Go, max: maxFloat64Go},
+	}
+	int64Kernels = map[string]Int64Funcs{
+		"selected": Int64,
+		"go":       {sum: sumInt64Go, min: minInt64Go, max: maxInt64Go},
+	}
+)
+
+func newFloat64(mem memory.Allocator, values []float64, valid []bool) *array.Float64 {
+	b := array.NewFloat64Builder(mem)
+	defer b.Release()
+	b.AppendValues(values, valid)
+	return b.NewFloat64Array()
+}
+
+func newInt64(mem memory.Allocator, values []int64, valid []bool) *array.Int64 {
+	b := array.NewInt64Builder(mem)
+	defer b.Release()
+	b.AppendValues(values, valid)
+	return b.NewInt64Array()
+}
+
+// sameFloat64 reports whether x and y are both NaN, or equal with the same sign.
+func sameFloat64(x, y float64) bool {
+	if x != x || y != y {
+		return x != x && y != y
+	}
+	return x == y && math.Signbit(x) == math.Signbit(y)
+}
+
+func TestFloat64MinMax(t *testing.T) {
+	var (
+		nan  = math.NaN()
+		inf  = math.Inf(1)
+		neg0 = math.Copysign(0, -1)
+	)
+	for _, tc := range []struct {
+		name     string
+		values   []float64
+		valid    []bool
+		min, max float64
+		ok       bool
+	}{
+		{name: "empty"},
+		{name: "all null", values: []float64{1, 2}, valid: []bool{false, false}},
+		{name: "one", values: []float64{3}, min: 3, max: 3, ok: true},
+		{name: "mixed", values: []float64{3, -1, 7, 2}, min: -1, max: 7, ok: true},
+		{name: "null extremes", values: []float64{3, -1, 7, 2}, valid: []bool{true, false, false, true}, min: 2, max: 3, ok: true},
+		{name: "inf", values: []float64{-inf, 1, inf}, min: -inf, max: inf, ok: true},
+		{name: "nan first", values: []float64{nan, 1, 2}, min: nan, max: nan, ok: true},
+		{name: "nan last", values: []float64{1, 2, nan}, min: nan, max: nan, ok: true},
+		{name: "nan and inf", values: []float64{inf, -inf, nan}, min: nan, max: nan, ok: true},
+		{name: "nan and inf with nulls", values: []float64{inf, 5, -inf, nan}, valid: []bool{true, false, true, true}, min: nan, max: nan, ok: true},
+		{name: "null nan", values: []float64{inf, nan, -inf}, valid: []bool{true, false, true}, min: -inf, max: inf, ok: true},
+		{name: "zeros", values: []float64{0, neg0, 0}, min: neg0, max: 0, ok: true},
+		{name: "zeros reversed", values: []float64{neg0, 0, neg0}, min: neg0, max: 0, ok: true},
+		{name: "negative zeros", values: []float64{neg0, neg0}, min: neg0, max: neg0, ok: true},
+		{name: "zeros with nulls", values: []float64{0, neg0, 1}, valid: []bool{true, true, false}, min: neg0, max: 0, ok: true},
+		// past the 8 values of a block, so that the assembly kernels see them.
+		{name: "long nan and inf", values: []float64{1, 2, 3, 4, 5, 6, 7, 8, inf, 9, -inf, nan}, min: nan, max: nan, ok: true},
+		{name: "long nan in block", values: []float64{1, inf, 3, nan, 5, 6, 7, -inf, 9, 10}, min: nan, max: nan, ok: true},
+		{name: "long zeros", values: []float64{0, 0, 0, 0, 0, 0, 0, 0, 0, neg0}, min: neg0, max: 0, ok: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer mem.AssertSize(t, 0)
+
+			a := newFloat64(mem, tc.values, tc.valid)
+			defer a.Release()
+			for name, f := range float64Kernels {
+				if min, ok := f.Min(a); ok != tc.ok || ok && !sameFloat64(min, tc.min) {
+					t.Errorf("%s: Min=%v, %v, want=%v, %v", name, min, ok, tc.min, tc.ok)
+				}
+				if max, ok := f.Max(a); ok != tc.ok || ok && !sameFloat64(max, tc.max) {
+					t.Errorf("%s: Max=%v, %v, want=%v, %v", name, max, ok, tc.max, tc.ok)
+				}
+			}
+		})
+	}
+}
+
+// TestFloat64Kernels checks the kernels against folds over the valid values,
+// for every length up to a few blocks, unaligned starts, with and without
+// nulls and with NaN, infinities and zeros of both signs mixed in.
+func TestFloat64Kernels(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	specials := []float64{math.NaN(), math.Inf(1), math.Inf(-1), 0, math.Copysign(0, -1)}
+	rnd := rand.New(rand.NewSource(1))
+	for n := 0; n < 70; n++ {
+		for iter := 0; iter < 20; iter++ {
+			const off = 3
+			values := make([]float64, n+off)
+			valid := make([]bool, n+off)
+			for i := range values {
+				// small integers keep the sums exact in any order.
+				values[i] = float64(rnd.Intn(200) - 100)
+				if rnd.Intn(n+1) == 0 {
+					values[i] = specials[rnd.Intn(len(specials))]
+				}
+				valid[i] = iter%2 == 0 || rnd.Intn(4) != 0
+			}
+
+			var (
+				sum, min, max float64
+				ok            bool
+			)
+			for i, v := range values[off:] {
+				if !valid[off+i] {
+					continue
+				}
+				sum += v
+				if ok {
+					min, max = minFloat64(min, v), maxFloat64(max, v)
+				} else {
+					min, max, ok = v, v, true
+				}
+			}
+
+			full := newFloat64(mem, values, valid)
+			a := array.NewSlice(full, off, int64(len(values))).(*array.Float64)
+			full.Release()
+			for name, f := range float64Kernels {
+				if got := f.Sum(a); !sameFloat64(got, sum) {
+					t.Fatalf("%s: Sum(%v)=%v, want=%v", name, a, got, sum)
+				}
+				if got, gotOK := f.Min(a); gotOK != ok || ok && !sameFloat64(got, min) {
+					t.Fatalf("%s: Min(%v)=%v, %v, want=%v, %v", name, a, got, gotOK, min, ok)
+				}
+				if got, gotOK := f.Max(a); gotOK != ok || ok && !sameFloat64(got, max) {
+					t.Fatalf("%s: Max(%v)=%v, %v, want=%v, %v", name, a, got, gotOK, max, ok)
+				}
+			}
+			a.Release()
+		}
+	}
+}
+
+func TestInt64Kernels(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	rnd := rand.New(rand.NewSource(1))
+	for n := 0; n < 70; n++ {
+		for iter := 0; iter < 20; iter++ {
+			const off = 3
+			values := make([]int64, n+off)
+			valid := make([]bool, n+off)
+			for i := range values {
+				switch rnd.Intn(n + 1) {
+				case 0:
+					values[i] = math.MinInt64
+				case 1:
+					values[i] = math.MaxInt64
+				default:
+					values[i] = rnd.Int63() - math.MaxInt64/2
+				}
+				valid[i] = iter%2 == 0 || rnd.Intn(4) != 0
+			}
+
+			var (
+				sum, min, max int64
+				ok            bool
+			)
+			for i, v := range values[off:] {
+				if !valid[off+i] {
+					continue
+				}
+				sum += v
+				switch {
+				case !ok:
+					min, max, ok = v, v, true
+				case v < min:
+					min = v
+				case v > max:
+					max = v
+				}
+			}
+
+			full := newInt64(mem, values, valid)
+			a := array.NewSlice(full, off, int64(len(values))).(*array.Int64)
+			full.Release()
+			for name, f := range int64Kernels {
+				if got := f.Sum(a); got != sum {
+					t.Fatalf("%s: Sum(%v)=%d, want=%d", name, a, got, sum)
+				}
+				if got, gotOK := f.Min(a); gotOK != ok || got != min {
+					t.Fatalf("%s: Min(%v)=%d, %v, want=%d, %v", name, a, got, gotOK, min, ok)
+				}
+				if got, gotOK := f.Max(a); gotOK != ok || got != max {
+					t.Fatalf("%s: Max(%v)=%d, %v, want=%d, %v", name, a, got, gotOK, max, ok)
+				}
+			}
+			a.Release()
+		}
+	}
+}
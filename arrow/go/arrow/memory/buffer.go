@@ -17,9 +17,10 @@
 package memory
 
 import (
+	"fmt"
 	"sync/atomic"
 
-	"github.com/apache/arrow/go/arrow/internal/debug"
+	"github.com/gomem/gomem/arrow/go/arrow/internal/debug"
 )
 
 // Buffer is a wrapper type for a buffer of bytes.
@@ -29,6 +30,7 @@ type Buffer struct {
 	length   int
 	mutable  bool
 	mem      Allocator
+	parent   *Buffer // set by SliceOwned, holds the memory of the buffer
 }
 
 // NewBufferBytes creates a fixed-size buffer from the specified data.
@@ -45,9 +47,48 @@ func NewResizableBuffer(mem Allocator) *Buffer {
 	}
 }
 
+// SliceOwned returns a buffer of the bytes [i:j) of b that shares the memory
+// of b. The slice holds a reference to b, so b stays valid until both b and the
+// slice have been released. The slice is immutable and must not be resized.
+// Like slicing, it panics unless 0 <= i <= j <= b.Len().
+func (b *Buffer) SliceOwned(i, j int) *Buffer {
+	b.assertLive()
+	if i < 0 || j < i || j > b.length {
+		panic(fmt.Sprintf("arrow/memory: slice bounds out of range [%d:%d] with length %d", i, j, b.length))
+	}
+
+	if b.mem == nil {
+		return NewBufferBytes(b.buf[i:j])
+	}
+	b.Retain()
+	return &Buffer{refCount: 1, buf: b.buf[i:j], length: j - i, mem: b.mem, parent: b}
+}
+
+// MoveInto transfers the memory of b, and the reference to it held by the
+// caller, to dst, releasing the memory dst held before. b is left empty, as if
+// released, and must not be used afterwards.
+//
+// The reference of the caller must be the only one, so that no one else can
+// observe the move.
+func (b *Buffer) MoveInto(dst *Buffer) {
+	b.assertLive()
+	debug.Assert(dst != b, "move of a buffer into itself")
+	debug.Assert(b.mem == nil || atomic.LoadInt64(&b.refCount) == 1, "move of a shared buffer")
+	debug.Assert(dst.mem == nil || atomic.LoadInt64(&dst.refCount) <= 1, "move into a shared buffer")
+
+	if dst.mem != nil && atomic.LoadInt64(&dst.refCount) == 1 {
+		dst.Release()
+	}
+	dst.refCount, dst.buf, dst.length = b.refCount, b.buf, b.length
+	dst.mutable, dst.mem, dst.parent = b.mutable, b.mem, b.parent
+
+	b.refCount, b.buf, b.length, b.parent = 0, nil, 0, nil
+}
+
 // Retain increases the reference count by 1.
 func (b *Buffer) Retain() {
 	if b.mem != nil {
+		debug.Assert(atomic.LoadInt64(&b.refCount) > 0, "retain of a released buffer")
 		atomic.AddInt64(&b.refCount, 1)
 	}
 }
@@ -59,12 +100,22 @@ func (b *Buffer) Release() {
 		debug.Assert(atomic.LoadInt64(&b.refCount) > 0, "too many releases")
 
 		if atomic.AddInt64(&b.refCount, -1) == 0 {
-			b.mem.Free(b.buf)
+			if b.parent != nil {
+				b.parent.Release()
+				b.parent = nil
+			} else {
+				b.mem.Free(b.buf)
+			}
 			b.buf, b.length = nil, 0
 		}
 	}
 }
 
+// assertLive panics in debug builds when b is used after its last release.
+func (b *Buffer) assertLive() {
+	debug.Assert(b.mem == nil || atomic.LoadInt64(&b.refCount) > 0, "use of a released buffer")
+}
+
 // Reset resets the buffer for reuse.
 func (b *Buffer) Reset(buf []byte) {
 	b.buf = buf
@@ -72,10 +123,16 @@ func (b *Buffer) Reset(buf []byte) {
 }
 
 // Buf returns the slice of memory allocated by the Buffer, which is adjusted by calling Reserve.
-func (b *Buffer) Buf() []byte { return b.buf }
+func (b *Buffer) Buf() []byte {
+	b.assertLive()
+	return b.buf
+}
 
 // Bytes returns a slice of size Len, which is adjusted by calling Resize.
-func (b *Buffer) Bytes() []byte { return b.buf[:b.length] }
+func (b *Buffer) Bytes() []byte {
+	b.assertLive()
+	return b.buf[:b.length]
+}
 
 // Mutable returns a bool indicating whether the buffer is mutable or not.
 func (b *Buffer) Mutable() bool { return b.mutable }
@@ -91,6 +148,8 @@ func (b *Buffer) Cap() int { return len(b.buf) }
 // 如果 capacity 小于等于 len(b.buf) ，不做处理；
 // 如果 capacity 大于 len(b.buf) ，新建 buffer 并将 b.buf 拷贝进去，b.length 值不变；
 func (b *Buffer) Reserve(capacity int) {
+	b.assertLive()
+	debug.Assert(b.parent == nil, "resize of a sliced buffer")
 	if capacity > len(b.buf) {
 		newCap := roundUpToMultipleOf64(capacity)
 		if len(b.buf) == 0 {
@@ -112,8 +171,16 @@ func (b *Buffer) ResizeNoShrink(newSize int) {
 	b.resize(newSize, false)
 }
 
+// ShrinkToFit releases the capacity of the buffer beyond its length, rounded
+// up to a multiple of 64 bytes.
+func (b *Buffer) ShrinkToFit() {
+	b.resize(b.length, true)
+}
+
 // 调整 Buffer 大小
 func (b *Buffer) resize(newSize int, shrink bool) {
+	b.assertLive()
+	debug.Assert(b.parent == nil, "resize of a sliced buffer")
 	// 如果 shrink 为 false ，直接 reserve ，reserve 不会减少 buffer ;
 	// 如果 shrink 为 true 但是 newSize 比 b.length 大，直接 reserve ，reserve 不会减少 buffer ;
 	if !shrink || newSize > b.length {
@@ -0,0 +1,129 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func newBuffer(mem memory.Allocator, data string) *memory.Buffer {
+	buf := memory.NewResizableBuffer(mem)
+	buf.Resize(len(data))
+	copy(buf.Bytes(), data)
+	return buf
+}
+
+func TestBufferSliceOwned(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	buf := newBuffer(mem, "0123456789")
+	slice := buf.SliceOwned(2, 8)
+	if got, want := string(slice.Bytes()), "234567"; got != want {
+		t.Fatalf("got %q, want=%q", got, want)
+	}
+	if slice.Mutable() {
+		t.Fatal("slice of a buffer is mutable")
+	}
+
+	// The slice keeps the memory of buf alive after buf is released.
+	buf.Release()
+	if got := mem.CurrentAlloc(); got == 0 {
+		t.Fatal("memory freed while a slice holds it")
+	}
+	nested := slice.SliceOwned(1, 3)
+	if got, want := string(nested.Bytes()), "34"; got != want {
+		t.Fatalf("got %q, want=%q", got, want)
+	}
+	slice.Release()
+	if got := mem.CurrentAlloc(); got == 0 {
+		t.Fatal("memory freed while a nested slice holds it")
+	}
+	if got, want := string(nested.Bytes()), "34"; got != want {
+		t.Fatalf("got %q after releasing the parent, want=%q", got, want)
+	}
+	nested.Release()
+	mem.AssertSize(t, 0)
+
+	// Slices of buffers without an allocator do not hold references.
+	plain := memory.NewBufferBytes([]byte("abc"))
+	if got, want := string(plain.SliceOwned(1, 3).Bytes()), "bc"; got != want {
+		t.Fatalf("got %q, want=%q", got, want)
+	}
+}
+
+func TestBufferSliceOwnedOutOfRange(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	buf := newBuffer(mem, "0123")
+	defer buf.Release()
+	// The length bounds the slices, not the capacity of 64 bytes.
+	for _, tc := range []struct{ i, j int }{{-1, 2}, {3, 2}, {0, 5}, {5, 5}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("SliceOwned(%d, %d) did not panic", tc.i, tc.j)
+				}
+			}()
+			buf.SliceOwned(tc.i, tc.j).Release()
+		}()
+	}
+	buf.SliceOwned(4, 4).Release()
+}
+
+func TestBufferMoveInto(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	src := newBuffer(mem, "moved")
+	dst := newBuffer(mem, "a much longer buffer that is freed by the move")
+	alloc := src.Cap()
+
+	src.MoveInto(dst)
+	if got, want := string(dst.Bytes()), "moved"; got != want {
+		t.Fatalf("got %q, want=%q", got, want)
+	}
+	if src.Len() != 0 || src.Cap() != 0 {
+		t.Fatalf("source left with len=%d cap=%d", src.Len(), src.Cap())
+	}
+	mem.AssertSize(t, alloc)
+
+	// dst owns the memory and can still be resized.
+	dst.Resize(64)
+	copy(dst.Bytes()[5:], "!")
+	if !bytes.HasPrefix(dst.Bytes(), []byte("moved!")) {
+		t.Fatalf("got %q after resize", dst.Bytes())
+	}
+	dst.Release()
+	mem.AssertSize(t, 0)
+
+	// Moving a slice moves its reference to the parent.
+	parent := newBuffer(mem, "0123456789")
+	slice := parent.SliceOwned(5, 10)
+	parent.Release()
+	dst = memory.NewResizableBuffer(mem)
+	slice.MoveInto(dst)
+	if got, want := string(dst.Bytes()), "56789"; got != want {
+		t.Fatalf("got %q, want=%q", got, want)
+	}
+	dst.Release()
+	mem.AssertSize(t, 0)
+}
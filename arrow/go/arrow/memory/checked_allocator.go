@@ -26,6 +26,9 @@ func NewCheckedAllocator(mem Allocator) *CheckedAllocator {
 	return &CheckedAllocator{mem: mem}
 }
 
+// CurrentAlloc returns the number of bytes currently allocated.
+func (a *CheckedAllocator) CurrentAlloc() int { return a.sz }
+
 func (a *CheckedAllocator) Allocate(size int) []byte {
 	a.sz += size
 	return a.mem.Allocate(size)
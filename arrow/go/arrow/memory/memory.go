@@ -27,7 +27,19 @@ func Set(buf []byte, c byte) {
 
 // memory_memset_go reference implementation
 func memory_memset_go(buf []byte, c byte) {
-	for i := 0; i < len(buf); i++ {
-		buf[i] = c
+	if c == 0 {
+		// compiled to a memclr
+		for i := range buf {
+			buf[i] = 0
+		}
+		return
+	}
+	if len(buf) == 0 {
+		return
+	}
+	// copies of doubling size let the vectorized memmove of the runtime do the work
+	buf[0] = c
+	for i := 1; i < len(buf); i *= 2 {
+		copy(buf[i:], buf[:i])
 	}
 }
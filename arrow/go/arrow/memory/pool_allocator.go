@@ -0,0 +1,139 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// minPoolClass is the size of the smallest buffers handed out by a
+// PoolAllocator.
+const minPoolClass = 64
+
+// PoolAllocator is an Allocator keeping the buffers freed to it, so that
+// later allocations of a similar size reuse them instead of allocating from
+// its parent allocator. Buffers are allocated from the parent in power of two
+// size classes, and reused buffers are zeroed like fresh ones.
+//
+// The buffers kept are only returned to the parent by SetLimit, so a pool
+// should be given a limit once it is no longer used.
+//
+// PoolAllocator is safe to use from multiple goroutines.
+type PoolAllocator struct {
+	mem Allocator
+
+	mu     sync.Mutex
+	free   map[int][][]byte // 按 size class 缓存的空闲 buffers
+	pooled int              // 缓存的字节数
+	limit  int              // 缓存字节数上限
+}
+
+// NewPoolAllocator returns a PoolAllocator allocating from mem, keeping every
+// buffer freed to it until SetLimit is called.
+func NewPoolAllocator(mem Allocator) *PoolAllocator {
+	return &PoolAllocator{mem: mem, free: make(map[int][][]byte), limit: int(^uint(0) >> 1)}
+}
+
+// Pooled returns the number of bytes of the buffers kept for reuse.
+func (a *PoolAllocator) Pooled() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.pooled
+}
+
+// SetLimit sets the number of bytes of the buffers kept for reuse, returning
+// the buffers past the limit to the parent allocator. A zero limit returns
+// every buffer kept, and every buffer freed later.
+func (a *PoolAllocator) SetLimit(n int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.limit = n
+	for class, bufs := range a.free {
+		for len(bufs) > 0 && a.pooled > a.limit {
+			last := len(bufs) - 1
+			a.mem.Free(bufs[last])
+			bufs[last] = nil
+			bufs = bufs[:last]
+			a.pooled -= class
+		}
+		a.free[class] = bufs
+	}
+}
+
+func (a *PoolAllocator) Allocate(size int) []byte {
+	if size == 0 {
+		return a.mem.Allocate(0)
+	}
+	class := poolClass(size)
+	a.mu.Lock()
+	bufs := a.free[class]
+	if n := len(bufs); n > 0 {
+		b := bufs[n-1]
+		bufs[n-1] = nil
+		a.free[class] = bufs[:n-1]
+		a.pooled -= class
+		a.mu.Unlock()
+		Set(b, 0)
+		return b[:size]
+	}
+	a.mu.Unlock()
+	return a.mem.Allocate(class)[:size]
+}
+
+func (a *PoolAllocator) Reallocate(size int, b []byte) []byte {
+	if cap(b) > 0 && size <= cap(b) && poolClass(size) == cap(b) {
+		if size > len(b) {
+			Set(b[len(b):size], 0)
+		}
+		return b[:size]
+	}
+	newBuf := a.Allocate(size)
+	copy(newBuf, b)
+	a.Free(b)
+	return newBuf
+}
+
+func (a *PoolAllocator) Free(b []byte) {
+	b = b[:cap(b)]
+	class := len(b)
+	if class < minPoolClass || class&(class-1) != 0 {
+		// Not a buffer of the pool.
+		a.mem.Free(b)
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.pooled+class > a.limit {
+		a.mem.Free(b)
+		return
+	}
+	a.free[class] = append(a.free[class], b)
+	a.pooled += class
+}
+
+// poolClass returns the size class of buffers of the given size.
+func poolClass(size int) int {
+	if size <= minPoolClass {
+		return minPoolClass
+	}
+	return 1 << uint(bits.Len(uint(size-1)))
+}
+
+var (
+	_ Allocator = (*PoolAllocator)(nil)
+)
@@ -23,8 +23,9 @@ import (
 )
 
 var (
-	MonthIntervalTraits   monthTraits
-	DayTimeIntervalTraits daytimeTraits
+	MonthIntervalTraits        monthTraits
+	DayTimeIntervalTraits      daytimeTraits
+	MonthDayNanoIntervalTraits monthDayNanoTraits
 )
 
 // MonthInterval traits
@@ -123,3 +124,53 @@ func (daytimeTraits) CastToBytes(b []DayTimeInterval) []byte {
 
 // Copy copies src to dst.
 func (daytimeTraits) Copy(dst, src []DayTimeInterval) { copy(dst, src) }
+
+// MonthDayNanoInterval traits
+
+const (
+	// MonthDayNanoIntervalSizeBytes specifies the number of bytes required to store a single MonthDayNanoInterval in memory
+	MonthDayNanoIntervalSizeBytes = int(unsafe.Sizeof(MonthDayNanoInterval{}))
+)
+
+type monthDayNanoTraits struct{}
+
+// BytesRequired returns the number of bytes required to store n elements in memory.
+func (monthDayNanoTraits) BytesRequired(n int) int { return MonthDayNanoIntervalSizeBytes * n }
+
+// PutValue
+func (monthDayNanoTraits) PutValue(b []byte, v MonthDayNanoInterval) {
+	binary.LittleEndian.PutUint32(b[0:4], uint32(v.Months))
+	binary.LittleEndian.PutUint32(b[4:8], uint32(v.Days))
+	binary.LittleEndian.PutUint64(b[8:16], uint64(v.Nanoseconds))
+}
+
+// CastFromBytes reinterprets the slice b to a slice of type MonthDayNanoInterval.
+//
+// NOTE: len(b) must be a multiple of MonthDayNanoIntervalSizeBytes.
+func (monthDayNanoTraits) CastFromBytes(b []byte) []MonthDayNanoInterval {
+	h := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+
+	var res []MonthDayNanoInterval
+	s := (*reflect.SliceHeader)(unsafe.Pointer(&res))
+	s.Data = h.Data
+	s.Len = h.Len / MonthDayNanoIntervalSizeBytes
+	s.Cap = h.Cap / MonthDayNanoIntervalSizeBytes
+
+	return res
+}
+
+// CastToBytes reinterprets the slice b to a slice of bytes.
+func (monthDayNanoTraits) CastToBytes(b []MonthDayNanoInterval) []byte {
+	h := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+
+	var res []byte
+	s := (*reflect.SliceHeader)(unsafe.Pointer(&res))
+	s.Data = h.Data
+	s.Len = h.Len * MonthDayNanoIntervalSizeBytes
+	s.Cap = h.Cap * MonthDayNanoIntervalSizeBytes
+
+	return res
+}
+
+// Copy copies src to dst.
+func (monthDayNanoTraits) Copy(dst, src []MonthDayNanoInterval) { copy(dst, src) }
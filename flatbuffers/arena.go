@@ -0,0 +1,76 @@
+package flatbuffers
+
+// BuilderArena vends Builders whose buffers are carved from a single backing
+// buffer, so that the many small messages of a request can be built without
+// an allocation per message and released together with Reset.
+//
+// The bytes of a Builder, FinishedBytes included, stay valid until the next
+// Reset of its arena, after which the Builder must not be used. A Builder
+// outgrowing its part of the backing buffer moves to a buffer of its own,
+// and Builders are vended from the heap once the backing buffer is used up.
+//
+// BuilderArena 持有一块大的 backing buffer ，每个 Builder 的 buffer 都是从中切出来的，
+// Reset 一次即可回收所有 Builder ，避免逐个管理成千上万个 slice 。
+type BuilderArena struct {
+	buf      []byte
+	used     int
+	builders []*Builder // 已分配的 Builder ，Reset 之后复用
+	n        int        // 当前使用中的 Builder 个数
+}
+
+// NewBuilderArena returns an arena whose backing buffer holds size bytes.
+func NewBuilderArena(size int) *BuilderArena {
+	if size < 0 {
+		size = 0
+	}
+	return &BuilderArena{buf: make([]byte, size)}
+}
+
+// NewBuilder returns a Builder of size initialSize carved from the arena.
+func (a *BuilderArena) NewBuilder(initialSize int) *Builder {
+	if initialSize < 0 {
+		initialSize = 0
+	}
+
+	var b *Builder
+	if a.n < len(a.builders) {
+		b = a.builders[a.n]
+	} else {
+		b = &Builder{vtables: make([]UOffsetT, 0, 16)}
+		a.builders = append(a.builders, b)
+	}
+	a.n++
+
+	if a.used+initialSize <= len(a.buf) {
+		// 限制 cap ，使 Builder 扩容时不会覆盖相邻 Builder 的数据
+		b.Bytes = a.buf[a.used : a.used+initialSize : a.used+initialSize]
+		a.used += initialSize
+	} else {
+		b.Bytes = make([]byte, initialSize)
+	}
+	b.deterministic = false
+	b.alignMode = AlignmentPanic
+	b.vtableCache = nil
+	b.vtableIndex = nil
+	b.Reset()
+	return b
+}
+
+// Len returns the number of Builders vended since the last Reset.
+func (a *BuilderArena) Len() int {
+	return a.n
+}
+
+// Available returns the number of bytes of the backing buffer not carved yet.
+func (a *BuilderArena) Available() int {
+	return len(a.buf) - a.used
+}
+
+// Reset reclaims the backing buffer and every Builder vended by the arena.
+func (a *BuilderArena) Reset() {
+	for _, b := range a.builders[:a.n] {
+		b.Bytes = nil
+	}
+	a.used = 0
+	a.n = 0
+}
@@ -0,0 +1,107 @@
+package flatbuffers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestBuilderArena(t *testing.T) {
+	const size = 64
+	arena := NewBuilderArena(3 * size)
+
+	var builders []*Builder
+	for i := 0; i < 3; i++ {
+		b := arena.NewBuilder(size)
+		if got := cap(b.Bytes); got != size {
+			t.Fatalf("builder %d: got capacity %d, want=%d", i, got, size)
+		}
+		builders = append(builders, b)
+	}
+	if got := arena.Available(); got != 0 {
+		t.Fatalf("got %d bytes available, want=0", got)
+	}
+	for i := 1; i < len(builders); i++ {
+		prev := uintptr(unsafe.Pointer(&builders[i-1].Bytes[0]))
+		cur := uintptr(unsafe.Pointer(&builders[i].Bytes[0]))
+		if cur-prev != size {
+			t.Fatalf("builders %d and %d are %d bytes apart, want=%d", i-1, i, cur-prev, size)
+		}
+	}
+
+	// Build in every builder, the middle one outgrowing its part of the
+	// backing buffer: the neighbours must be left untouched.
+	var finished [][]byte
+	for i, b := range builders {
+		name := fmt.Sprint("builder", i)
+		if i == 1 {
+			name = strings.Repeat("z", 3*size)
+		}
+		finished = append(finished, buildSample(b, name, int32(i)))
+	}
+	for i, buf := range finished {
+		name, id := readSample(t, buf)
+		if id != int32(i) || (i != 1 && name != fmt.Sprint("builder", i)) || (i == 1 && len(name) != 3*size) {
+			t.Fatalf("builder %d: got %q, %d", i, name, id)
+		}
+	}
+
+	// Builders past the end of the backing buffer come from the heap.
+	heap := arena.NewBuilder(size)
+	if got := arena.Len(); got != 4 {
+		t.Fatalf("got %d builders, want=4", got)
+	}
+	if name, id := readSample(t, buildSample(heap, "heap", 4)); name != "heap" || id != 4 {
+		t.Fatalf("got %q, %d", name, id)
+	}
+	if name, _ := readSample(t, finished[2]); name != "builder2" {
+		t.Fatalf("heap builder overwrote the arena: got %q", name)
+	}
+
+	// A builder too large for the space left comes from the heap too.
+	small := NewBuilderArena(size + 8)
+	small.NewBuilder(size)
+	b := small.NewBuilder(16)
+	if got := small.Available(); got != 8 {
+		t.Fatalf("got %d bytes available, want=8", got)
+	}
+	if name, _ := readSample(t, buildSample(b, "fallback", 0)); name != "fallback" {
+		t.Fatalf("got %q", name)
+	}
+}
+
+func TestBuilderArenaReset(t *testing.T) {
+	const size = 64
+	arena := NewBuilderArena(2 * size)
+	first := arena.NewBuilder(size)
+	first.SetDeterministic(true)
+	first.SetAlignmentMode(AlignmentError)
+	first.SetVtableHashing(true)
+	buildSample(first, "before", 1)
+	arena.NewBuilder(size)
+
+	arena.Reset()
+	if got := arena.Len(); got != 0 {
+		t.Fatalf("got %d builders after Reset, want=0", got)
+	}
+	if got := arena.Available(); got != 2*size {
+		t.Fatalf("got %d bytes available after Reset, want=%d", got, 2*size)
+	}
+
+	// Builders are reused, carved from the start of the backing buffer again,
+	// with the settings of a new Builder.
+	b := arena.NewBuilder(size)
+	if b != first {
+		t.Fatal("Builder not reused after Reset")
+	}
+	if b.Deterministic() || b.alignMode != AlignmentPanic || b.vtableIndex != nil {
+		t.Fatal("settings of the Builder kept across Reset")
+	}
+	if b.Offset() != 0 || b.Head() != size {
+		t.Fatalf("got offset %d, head %d after Reset", b.Offset(), b.Head())
+	}
+	if name, id := readSample(t, buildSample(b, "after", 2)); name != "after" || id != 2 {
+		t.Fatalf("got %q, %d", name, id)
+	}
+}
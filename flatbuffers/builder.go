@@ -1,5 +1,10 @@
 package flatbuffers
 
+import (
+	"errors"
+	"fmt"
+)
+
 // FlatBuffers 中，minalign（也称为对齐因子，表示内存对齐）用于指定表中字段的内存对齐方式。
 // FlatBuffers 使用自定义二进制格式来表示数据，通过将数据结构组织成表，可以在不进行任何解析的情况下进行直接访问。
 // minalign 用于确保字段在内存中的正确对齐，以提高访问效率。
@@ -31,6 +36,15 @@ package flatbuffers
 //
 // A Builder constructs byte buffers in a last-first manner for simplicity and
 // performance.
+//
+// The finished bytes only depend on the calls made to the Builder: vtables are
+// deduplicated against the ones already written, most recent first, and
+// alignment padding is written as zeros. The Builder has no shared string pool,
+// so strings are written in the order they are created. In deterministic mode,
+// see SetDeterministic, the whole of Bytes also is: the bytes the Builder has
+// not written yet are zero rather than left over from before a Reset or a growth
+// of the buffer, so hashing or comparing Bytes gives the same result for the
+// same input across runs.
 type Builder struct {
 	// `Bytes` gives raw access to the buffer. Most users will want to use
 	// FinishedBytes() instead.
@@ -43,8 +57,35 @@ type Builder struct {
 	head      UOffsetT
 	nested    bool
 	finished  bool
+
+	deterministic bool          // 未写入的字节保持为 0 ，使 Bytes 只取决于输入
+	alignMode     AlignmentMode // PlaceBytes 校验对齐失败时 panic 还是返回 error
+
+	vtableCache   *VTableCache // 多个 Builder 共享的 vtable 布局，nil 表示逆向线性查找 vtables
+	cachedVtables []UOffsetT   // 按 vtableCache 中的 id 索引，本 buffer 中对应 vtable 的 offset ，0 表示尚未写入
+	layout        []byte       // vtableLayout 的暂存空间
+
+	vtableIndex map[uint64][]UOffsetT // vtable 字段内容的 hash -> vtables 中的 offset ，nil 表示不启用
+
+	streamElemSize int      // 正在构建的 streamed vector 的元素大小，0 表示没有
+	streamAlign    int      // streamed vector 的对齐
+	streamEnd      UOffsetT // streamed vector 数据结尾的 offset
 }
 
+// ErrMisaligned is the error reported by PlaceBytes when the bytes would not
+// start at a multiple of their alignment.
+var ErrMisaligned = errors.New("flatbuffers: misaligned bytes")
+
+// AlignmentMode selects how PlaceBytes and PrependBytes report invalid placements.
+type AlignmentMode int
+
+const (
+	// AlignmentPanic panics, like the other checks of the Builder. It is the default.
+	AlignmentPanic AlignmentMode = iota
+	// AlignmentError returns the error.
+	AlignmentError
+)
+
 const fileIdentifierLength = 4
 
 // NewBuilder initializes a Builder of size `initial_size`.
@@ -70,6 +111,9 @@ func NewBuilder(initialSize int) *Builder {
 func (b *Builder) Reset() {
 	if b.Bytes != nil {
 		b.Bytes = b.Bytes[:cap(b.Bytes)]
+		if b.deterministic {
+			zero(b.Bytes)
+		}
 	}
 
 	if b.vtables != nil {
@@ -80,12 +124,40 @@ func (b *Builder) Reset() {
 		b.vtable = b.vtable[:0]
 	}
 
+	b.cachedVtables = b.cachedVtables[:0]
+	for h, offs := range b.vtableIndex {
+		// 保留 slice ，复用时不再分配
+		b.vtableIndex[h] = offs[:0]
+	}
+
 	b.head = UOffsetT(len(b.Bytes))
 	b.minalign = 1
 	b.nested = false
+	b.streamElemSize = 0
 	b.finished = false
 }
 
+// SetDeterministic sets whether the Builder is in deterministic mode, where
+// the bytes of Bytes it has not written are zero. The mode costs a clear of the
+// buffer on Reset and of its free part when it grows, so it is off by default.
+// Switching it on clears the free part of the buffer.
+func (b *Builder) SetDeterministic(enabled bool) {
+	b.deterministic = enabled
+	if enabled {
+		zero(b.Bytes[:b.head])
+	}
+}
+
+// SetAlignmentMode sets how PlaceBytes and PrependBytes report invalid placements.
+func (b *Builder) SetAlignmentMode(mode AlignmentMode) {
+	b.alignMode = mode
+}
+
+// Deterministic reports whether the Builder is in deterministic mode.
+func (b *Builder) Deterministic() bool {
+	return b.deterministic
+}
+
 // FinishedBytes returns a pointer to the written data in the byte buffer.
 // Panics if the builder is not in a finished state (which is caused by calling
 // `Finish()`).
@@ -161,6 +233,15 @@ func (b *Builder) WriteVtable() (n UOffsetT) {
 	}
 	b.vtable = b.vtable[:i+1]
 
+	// With a VTableCache, look the layout up instead of scanning the vtables.
+	cacheID := -1
+	var hash uint64
+	if b.vtableCache != nil {
+		cacheID, existingVtable = b.findCachedVtable(objectOffset)
+	} else if b.vtableIndex != nil {
+		hash, existingVtable = b.findIndexedVtable(objectOffset)
+	}
+
 	// Search backwards through existing vtables, because similar vtables
 	// are likely to have been recently appended. See
 	// BenchmarkVtableDeduplication for a case in which this heuristic
@@ -169,7 +250,7 @@ func (b *Builder) WriteVtable() (n UOffsetT) {
 	//
 	// 从 vtables 中逆向搜索已经存储过的 vtable ，如果存在相同的且已经存储过的 vtable ，直接找到它，索引指向它即可；
 	// 可以查看 BenchmarkVtableDeduplication 的测试结果，通过索引指向相同的 vtable，而不是新建一个，这种做法可以提高 30% 性能；
-	for i := len(b.vtables) - 1; i >= 0; i-- {
+	for i := len(b.vtables) - 1; i >= 0 && cacheID < 0 && b.vtableIndex == nil; i-- {
 		// Find the other vtable, which is associated with `i`:
 		// 选定一个 vtable ，这里 vtables[i] 存储着第 i 个 vtable 的 offset
 		vt2Offset := b.vtables[i]
@@ -242,6 +323,11 @@ func (b *Builder) WriteVtable() (n UOffsetT) {
 		//
 		// 保存当前 vtable 的 offset 到 vtables 中，便于后续查找去重。
 		b.vtables = append(b.vtables, b.Offset())
+		if cacheID >= 0 {
+			b.recordCachedVtable(cacheID, b.Offset())
+		} else if b.vtableIndex != nil {
+			b.vtableIndex[hash] = append(b.vtableIndex[hash], b.Offset())
+		}
 
 	} else {
 		// Found a duplicate vtable.
@@ -296,6 +382,16 @@ func (b *Builder) growByteBuffer() {
 
 	middle := newLen / 2
 	copy(b.Bytes[middle:], b.Bytes[:middle])
+	if b.deterministic {
+		zero(b.Bytes[:middle])
+	}
+}
+
+// zero sets the bytes of p to 0.
+func zero(p []byte) {
+	for i := range p {
+		p[i] = 0
+	}
 }
 
 // Head gives the start of useful data in the underlying byte buffer.
@@ -674,6 +770,102 @@ func (b *Builder) PrependFloat64Slot(o int, x, d float64) {
 	}
 }
 
+// PrependBoolSlotOptional prepends a bool onto the object at vtable slot `o`.
+// Unlike PrependBoolSlot, the value is always written, so readers can tell it
+// from a missing field with GetBoolSlotOptional.
+func (b *Builder) PrependBoolSlotOptional(o int, x bool) {
+	b.PrependBool(x)
+	b.Slot(o)
+}
+
+// PrependByteSlotOptional prepends a byte onto the object at vtable slot `o`.
+// Unlike PrependByteSlot, the value is always written, so readers can tell it
+// from a missing field with GetByteSlotOptional.
+func (b *Builder) PrependByteSlotOptional(o int, x byte) {
+	b.PrependByte(x)
+	b.Slot(o)
+}
+
+// PrependUint8SlotOptional prepends a uint8 onto the object at vtable slot `o`.
+// Unlike PrependUint8Slot, the value is always written, so readers can tell it
+// from a missing field with GetUint8SlotOptional.
+func (b *Builder) PrependUint8SlotOptional(o int, x uint8) {
+	b.PrependUint8(x)
+	b.Slot(o)
+}
+
+// PrependUint16SlotOptional prepends a uint16 onto the object at vtable slot `o`.
+// Unlike PrependUint16Slot, the value is always written, so readers can tell it
+// from a missing field with GetUint16SlotOptional.
+func (b *Builder) PrependUint16SlotOptional(o int, x uint16) {
+	b.PrependUint16(x)
+	b.Slot(o)
+}
+
+// PrependUint32SlotOptional prepends a uint32 onto the object at vtable slot `o`.
+// Unlike PrependUint32Slot, the value is always written, so readers can tell it
+// from a missing field with GetUint32SlotOptional.
+func (b *Builder) PrependUint32SlotOptional(o int, x uint32) {
+	b.PrependUint32(x)
+	b.Slot(o)
+}
+
+// PrependUint64SlotOptional prepends a uint64 onto the object at vtable slot `o`.
+// Unlike PrependUint64Slot, the value is always written, so readers can tell it
+// from a missing field with GetUint64SlotOptional.
+func (b *Builder) PrependUint64SlotOptional(o int, x uint64) {
+	b.PrependUint64(x)
+	b.Slot(o)
+}
+
+// PrependInt8SlotOptional prepends a int8 onto the object at vtable slot `o`.
+// Unlike PrependInt8Slot, the value is always written, so readers can tell it
+// from a missing field with GetInt8SlotOptional.
+func (b *Builder) PrependInt8SlotOptional(o int, x int8) {
+	b.PrependInt8(x)
+	b.Slot(o)
+}
+
+// PrependInt16SlotOptional prepends a int16 onto the object at vtable slot `o`.
+// Unlike PrependInt16Slot, the value is always written, so readers can tell it
+// from a missing field with GetInt16SlotOptional.
+func (b *Builder) PrependInt16SlotOptional(o int, x int16) {
+	b.PrependInt16(x)
+	b.Slot(o)
+}
+
+// PrependInt32SlotOptional prepends a int32 onto the object at vtable slot `o`.
+// Unlike PrependInt32Slot, the value is always written, so readers can tell it
+// from a missing field with GetInt32SlotOptional.
+func (b *Builder) PrependInt32SlotOptional(o int, x int32) {
+	b.PrependInt32(x)
+	b.Slot(o)
+}
+
+// PrependInt64SlotOptional prepends a int64 onto the object at vtable slot `o`.
+// Unlike PrependInt64Slot, the value is always written, so readers can tell it
+// from a missing field with GetInt64SlotOptional.
+func (b *Builder) PrependInt64SlotOptional(o int, x int64) {
+	b.PrependInt64(x)
+	b.Slot(o)
+}
+
+// PrependFloat32SlotOptional prepends a float32 onto the object at vtable slot `o`.
+// Unlike PrependFloat32Slot, the value is always written, so readers can tell it
+// from a missing field with GetFloat32SlotOptional.
+func (b *Builder) PrependFloat32SlotOptional(o int, x float32) {
+	b.PrependFloat32(x)
+	b.Slot(o)
+}
+
+// PrependFloat64SlotOptional prepends a float64 onto the object at vtable slot `o`.
+// Unlike PrependFloat64Slot, the value is always written, so readers can tell it
+// from a missing field with GetFloat64SlotOptional.
+func (b *Builder) PrependFloat64SlotOptional(o int, x float64) {
+	b.PrependFloat64(x)
+	b.Slot(o)
+}
+
 // PrependUOffsetTSlot prepends an UOffsetT onto the object at vtable slot `o`.
 // If value `x` equals default `d`, then the slot will be set to zero and no
 // other data will be written.
@@ -958,3 +1150,56 @@ func (b *Builder) PlaceUOffsetT(x UOffsetT) {
 	b.head -= UOffsetT(SizeUOffsetT)   // 向前挪动 4 个位置，腾出一个 UOffsetT 的空间
 	WriteUOffsetT(b.Bytes[b.head:], x) // 存入 4 byte 的 x
 }
+
+// PrependBytes prepends blob, such as a pre-encoded struct or nested buffer,
+// to the Builder buffer, aligned to align bytes, a power of two.
+// Aligns and checks for space.
+func (b *Builder) PrependBytes(blob []byte, align int) error {
+	if err := b.checkAlignment(align); err != nil {
+		return err
+	}
+	b.Prep(align, len(blob))
+	return b.PlaceBytes(blob, align)
+}
+
+// PlaceBytes prepends blob to the Builder, without growing the buffer or
+// padding it. Unlike the other Place functions, it checks that there is space
+// for blob and that blob starts at a multiple of align, a power of two, from
+// the end of the buffer, as Prep(align, len(blob)) ensures. Invalid placements
+// panic or return an error according to the AlignmentMode of the Builder,
+// wrapping ErrMisaligned when blob would be misaligned.
+//
+// PlaceBytes 一次性复制整个 blob ，代替逐字节调用 PlaceByte 。
+func (b *Builder) PlaceBytes(blob []byte, align int) error {
+	if err := b.checkAlignment(align); err != nil {
+		return err
+	}
+	if int(b.head) < len(blob) {
+		return b.placementError(fmt.Errorf("flatbuffers: no space to place %d bytes, %d left", len(blob), b.head))
+	}
+	if off := int(b.Offset()) + len(blob); off%align != 0 {
+		return b.placementError(fmt.Errorf("%w: %d bytes at offset %d are not aligned to %d", ErrMisaligned, len(blob), off, align))
+	}
+
+	if align > b.minalign {
+		b.minalign = align
+	}
+	b.head -= UOffsetT(len(blob))
+	copy(b.Bytes[b.head:], blob)
+	return nil
+}
+
+func (b *Builder) checkAlignment(align int) error {
+	if align <= 0 || align&(align-1) != 0 {
+		return b.placementError(fmt.Errorf("flatbuffers: alignment %d is not a power of two", align))
+	}
+	return nil
+}
+
+// placementError panics with err or returns it, according to the AlignmentMode.
+func (b *Builder) placementError(err error) error {
+	if b.alignMode == AlignmentPanic {
+		panic(err)
+	}
+	return err
+}
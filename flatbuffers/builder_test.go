@@ -0,0 +1,265 @@
+package flatbuffers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// buildSample builds a table of an int32, a string and a bool and finishes
+// the buffer with it.
+func buildSample(b *Builder, name string, id int32) []byte {
+	s := b.CreateString(name)
+	b.StartObject(3)
+	b.PrependInt32Slot(0, id, 0)
+	b.PrependUOffsetTSlot(1, s, 0)
+	b.PrependBoolSlot(2, true, false)
+	b.Finish(b.EndObject())
+	return b.FinishedBytes()
+}
+
+// readSample reads back the fields of a buffer built by buildSample.
+func readSample(t *testing.T, buf []byte) (string, int32) {
+	t.Helper()
+	tab := Table{Bytes: buf, Pos: GetUOffsetT(buf)}
+	name := ""
+	if off := tab.Offset(6); off != 0 {
+		name = tab.String(tab.Pos + UOffsetT(off))
+	}
+	if !tab.GetBoolSlot(8, false) {
+		t.Fatal("bool field not set")
+	}
+	return name, tab.GetInt32Slot(4, 0)
+}
+
+func TestBuilderDeterministic(t *testing.T) {
+	const size = 256
+	dirty := func(b *Builder) {
+		buildSample(b, string(bytes.Repeat([]byte{'x'}, 150)), -1)
+		b.Reset()
+	}
+
+	// Without deterministic mode, the bytes of a previous build are left over.
+	reused := NewBuilder(size)
+	dirty(reused)
+	buildSample(reused, "a", 1)
+	fresh := NewBuilder(size)
+	buildSample(fresh, "a", 1)
+	if !bytes.Equal(reused.FinishedBytes(), fresh.FinishedBytes()) {
+		t.Fatal("finished bytes depend on a previous build")
+	}
+	if bytes.Equal(reused.Bytes, fresh.Bytes) {
+		t.Fatal("expected leftover bytes without deterministic mode")
+	}
+
+	// In deterministic mode, the whole buffer only depends on the input.
+	for _, tc := range []struct {
+		name string
+		prep func(b *Builder)
+	}{
+		{"reset", func(b *Builder) { b.SetDeterministic(true); dirty(b) }},
+		{"set after build", func(b *Builder) { dirty(b); b.SetDeterministic(true) }},
+		{"set on a dirty buffer", func(b *Builder) {
+			dirty(b)
+			buildSample(b, "partial", 2)
+			b.Reset()
+			b.SetDeterministic(true)
+			b.Reset()
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewBuilder(size)
+			tc.prep(b)
+			buildSample(b, "a", 1)
+
+			want := NewBuilder(size)
+			want.SetDeterministic(true)
+			buildSample(want, "a", 1)
+			if !bytes.Equal(b.Bytes, want.Bytes) {
+				t.Fatalf("got buffer\n%x\nwant\n%x", b.Bytes, want.Bytes)
+			}
+			if !b.Deterministic() {
+				t.Fatal("deterministic mode lost")
+			}
+		})
+	}
+
+	// The part of the buffer freed by a growth is cleared too.
+	b := NewBuilder(0)
+	b.SetDeterministic(true)
+	buildSample(b, string(bytes.Repeat([]byte{'y'}, 100)), 3)
+	for i, c := range b.Bytes[:b.Head()] {
+		if c != 0 {
+			t.Fatalf("byte %d of the free part is %#x after growing", i, c)
+		}
+	}
+	if name, id := readSample(t, b.FinishedBytes()); id != 3 || len(name) != 100 {
+		t.Fatalf("got %q, %d", name, id)
+	}
+}
+
+func TestBuilderPrependBytes(t *testing.T) {
+	b := NewBuilder(0)
+	b.PrependByte(1)
+	blob := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	if err := b.PrependBytes(blob, 8); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Offset(); got%8 != 0 {
+		t.Fatalf("blob placed at offset %d, want a multiple of 8", got)
+	}
+	if got := b.Bytes[b.Head() : b.Head()+8]; !bytes.Equal(got, blob) {
+		t.Fatalf("got %v, want=%v", got, blob)
+	}
+	if b.minalign != 8 {
+		t.Fatalf("got minalign %d, want=8", b.minalign)
+	}
+
+	// The padding of Prep makes room for PlaceBytes.
+	b.Prep(4, 3)
+	if err := b.PlaceBytes([]byte{9, 9, 9}, 4); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuilderPlaceBytesInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		place      func(b *Builder) error
+		misaligned bool
+	}{
+		{"zero alignment", func(b *Builder) error { return b.PrependBytes([]byte{1}, 0) }, false},
+		{"alignment not a power of two", func(b *Builder) error { return b.PlaceBytes([]byte{1, 2, 3}, 3) }, false},
+		{"misaligned", func(b *Builder) error { return b.PlaceBytes([]byte{1, 2, 3}, 4) }, true},
+		{"no space", func(b *Builder) error { return b.PlaceBytes(make([]byte, 64), 1) }, false},
+	}
+	newBuilder := func(mode AlignmentMode) *Builder {
+		b := NewBuilder(16)
+		b.SetAlignmentMode(mode)
+		b.PrependUint32(7)
+		return b
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newBuilder(AlignmentError)
+			head := b.Head()
+			err := tc.place(b)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := errors.Is(err, ErrMisaligned); got != tc.misaligned {
+				t.Fatalf("got errors.Is(%v, ErrMisaligned) = %v, want=%v", err, got, tc.misaligned)
+			}
+			if b.Head() != head {
+				t.Fatal("invalid placement wrote to the buffer")
+			}
+
+			b = newBuilder(AlignmentPanic)
+			defer func() {
+				e := recover()
+				err, ok := e.(error)
+				if !ok {
+					t.Fatalf("got panic %v, want an error", e)
+				}
+				if got := errors.Is(err, ErrMisaligned); got != tc.misaligned {
+					t.Fatalf("got errors.Is(%v, ErrMisaligned) = %v, want=%v", err, got, tc.misaligned)
+				}
+			}()
+			tc.place(b)
+			t.Fatal("expected a panic")
+		})
+	}
+}
+
+// buildShapes builds n tables cycling through the given number of distinct
+// shapes, the fields of a shape being the bits set in its number, and finishes
+// the buffer with the last one.
+func buildShapes(b *Builder, n, shapes int) {
+	var last UOffsetT
+	for i := 0; i < n; i++ {
+		shape := i % shapes
+		b.StartObject(12)
+		for f := 0; f < 12; f++ {
+			if shape>>f&1 == 0 {
+				continue
+			}
+			switch f % 3 {
+			case 0:
+				b.PrependInt8Slot(f, int8(i), -1)
+			case 1:
+				b.PrependInt32Slot(f, int32(i), -1)
+			default:
+				b.PrependInt64Slot(f, int64(i), -1)
+			}
+		}
+		last = b.EndObject()
+	}
+	b.Finish(last)
+}
+
+func TestVtableHashing(t *testing.T) {
+	const n, shapes = 2000, 300
+
+	linear := NewBuilder(0)
+	buildShapes(linear, n, shapes)
+	if got := len(linear.vtables); got >= n {
+		t.Fatalf("got %d vtables for %d tables, expected duplicates", got, n)
+	}
+
+	hashed := NewBuilder(0)
+	hashed.SetVtableHashing(true)
+	buildShapes(hashed, n, shapes)
+	if !bytes.Equal(hashed.FinishedBytes(), linear.FinishedBytes()) {
+		t.Fatal("hashed deduplication wrote different bytes than the linear scan")
+	}
+
+	// Reused after a Reset, the index holds no stale offsets.
+	hashed.Reset()
+	buildShapes(hashed, n, shapes)
+	if !bytes.Equal(hashed.FinishedBytes(), linear.FinishedBytes()) {
+		t.Fatal("hashed deduplication after Reset wrote different bytes than the linear scan")
+	}
+
+	// Layouts colliding on their hash are told apart by their bytes.
+	for _, tc := range []struct {
+		name string
+		hash func([]byte) uint64
+	}{
+		{"all colliding", func([]byte) uint64 { return 0 }},
+		{"colliding by length", func(p []byte) uint64 { return uint64(len(p)) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func(hash func([]byte) uint64) { vtableHash = hash }(vtableHash)
+			vtableHash = tc.hash
+
+			b := NewBuilder(0)
+			b.SetVtableHashing(true)
+			buildShapes(b, n, shapes)
+			if !bytes.Equal(b.FinishedBytes(), linear.FinishedBytes()) {
+				t.Fatal("colliding hashes wrote different bytes than the linear scan")
+			}
+		})
+	}
+}
+
+func BenchmarkVtableDeduplication(b *testing.B) {
+	for _, shapes := range []int{10, 1000} {
+		for _, hashing := range []bool{false, true} {
+			name := fmt.Sprintf("shapes=%d/linear", shapes)
+			if hashing {
+				name = fmt.Sprintf("shapes=%d/hashed", shapes)
+			}
+			b.Run(name, func(b *testing.B) {
+				builder := NewBuilder(1 << 20)
+				builder.SetVtableHashing(hashing)
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					builder.Reset()
+					buildShapes(builder, 2000, shapes)
+				}
+			})
+		}
+	}
+}
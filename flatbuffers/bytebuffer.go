@@ -0,0 +1,392 @@
+package flatbuffers
+
+// ByteBuffer is read-only memory holding a FlatBuffer, such as an mmap'd
+// region, memory owned by C or the chunks of a network buffer, which a
+// BufferTable reads without first copying it to a []byte.
+type ByteBuffer interface {
+	// Len returns the number of bytes of the buffer.
+	Len() int
+	// At returns the byte at offset i.
+	At(i int) byte
+	// Slice returns the bytes from offset lo to hi. The result may share
+	// memory with the buffer and must not be modified, or be a copy when the
+	// bytes are not contiguous.
+	Slice(lo, hi int) []byte
+}
+
+// ByteSlice is the ByteBuffer of a []byte, which BufferTable.Table turns into
+// a Table to keep the []byte fast path.
+type ByteSlice []byte
+
+// Len returns the number of bytes of the buffer.
+func (b ByteSlice) Len() int { return len(b) }
+
+// At returns the byte at offset i.
+func (b ByteSlice) At(i int) byte { return b[i] }
+
+// Slice returns the bytes from offset lo to hi, sharing memory with b.
+func (b ByteSlice) Slice(lo, hi int) []byte { return b[lo:hi] }
+
+// ChunkedBytes is the ByteBuffer of consecutive chunks of bytes, such as the
+// buffers of a network read. Slice copies the bytes spanning several chunks.
+type ChunkedBytes struct {
+	chunks [][]byte
+	starts []int // starts[i] 为第 i 个 chunk 的起始 offset ，最后一项为总长度
+}
+
+// NewChunkedBytes returns the ByteBuffer of chunks, in order.
+func NewChunkedBytes(chunks ...[]byte) *ChunkedBytes {
+	c := &ChunkedBytes{chunks: chunks, starts: make([]int, len(chunks)+1)}
+	for i, chunk := range chunks {
+		c.starts[i+1] = c.starts[i] + len(chunk)
+	}
+	return c
+}
+
+// Len returns the number of bytes of the buffer.
+func (c *ChunkedBytes) Len() int { return c.starts[len(c.chunks)] }
+
+// At returns the byte at offset i.
+func (c *ChunkedBytes) At(i int) byte {
+	k := c.chunk(i)
+	return c.chunks[k][i-c.starts[k]]
+}
+
+// Slice returns the bytes from offset lo to hi.
+func (c *ChunkedBytes) Slice(lo, hi int) []byte {
+	if lo < 0 || hi < lo || hi > c.Len() {
+		panic("flatbuffers: ChunkedBytes slice out of range")
+	}
+	if lo == hi {
+		return nil
+	}
+	k := c.chunk(lo)
+	if hi <= c.starts[k+1] {
+		return c.chunks[k][lo-c.starts[k] : hi-c.starts[k]]
+	}
+
+	// 跨越多个 chunk ，只能复制
+	out := make([]byte, 0, hi-lo)
+	for ; lo < hi; k++ {
+		end := c.starts[k+1]
+		if end > hi {
+			end = hi
+		}
+		out = append(out, c.chunks[k][lo-c.starts[k]:end-c.starts[k]]...)
+		lo = end
+	}
+	return out
+}
+
+// chunk returns the index of the chunk holding offset i.
+func (c *ChunkedBytes) chunk(i int) int {
+	lo, hi := 0, len(c.chunks)
+	for lo < hi {
+		m := (lo + hi) / 2
+		if c.starts[m+1] <= i {
+			lo = m + 1
+		} else {
+			hi = m
+		}
+	}
+	if lo == len(c.chunks) {
+		panic("flatbuffers: ChunkedBytes index out of range")
+	}
+	return lo
+}
+
+// BufferTable is a Table reading from a ByteBuffer.
+//
+// The variable `Pos` indicates the root of the FlatBuffers object therein.
+type BufferTable struct {
+	Buf ByteBuffer
+	Pos UOffsetT // Always < 1<<31.
+}
+
+// Table returns the Table reading the same bytes when Buf is a ByteSlice, so
+// that generated accessors can be used, and false otherwise.
+func (t *BufferTable) Table() (Table, bool) {
+	b, ok := t.Buf.(ByteSlice)
+	if !ok {
+		return Table{}, false
+	}
+	return Table{Bytes: b, Pos: t.Pos}, true
+}
+
+func (t *BufferTable) read(off UOffsetT, n int) []byte {
+	return t.Buf.Slice(int(off), int(off)+n)
+}
+
+// Offset provides access into the Table's vtable.
+//
+// Fields which are deprecated are ignored by checking against the vtable's length.
+func (t *BufferTable) Offset(vtableOffset VOffsetT) VOffsetT {
+	vtable := UOffsetT(SOffsetT(t.Pos) - t.GetSOffsetT(t.Pos))
+	if vtableOffset < t.GetVOffsetT(vtable) {
+		return t.GetVOffsetT(vtable + UOffsetT(vtableOffset))
+	}
+	return 0
+}
+
+// Indirect retrieves the relative offset stored at `offset`.
+func (t *BufferTable) Indirect(off UOffsetT) UOffsetT {
+	return off + t.GetUOffsetT(off)
+}
+
+// String gets a string from data stored inside the flatbuffer. Unlike
+// Table.String, the string is a copy, so it outlives foreign memory.
+func (t *BufferTable) String(off UOffsetT) string {
+	return string(t.ByteVector(off))
+}
+
+// ByteVector gets a byte slice from data stored inside the flatbuffer.
+func (t *BufferTable) ByteVector(off UOffsetT) []byte {
+	off += t.GetUOffsetT(off)
+	length := t.GetUOffsetT(off)
+	start := off + UOffsetT(SizeUOffsetT)
+	return t.read(start, int(length))
+}
+
+// VectorLen retrieves the length of the vector whose offset is stored at
+// "off" in this object.
+func (t *BufferTable) VectorLen(off UOffsetT) int {
+	off += t.Pos
+	off += t.GetUOffsetT(off)
+	return int(t.GetUOffsetT(off))
+}
+
+// Vector retrieves the start of data of the vector whose offset is stored
+// at "off" in this object.
+func (t *BufferTable) Vector(off UOffsetT) UOffsetT {
+	off += t.Pos
+	x := off + t.GetUOffsetT(off)
+	// data starts after metadata containing the vector length
+	x += UOffsetT(SizeUOffsetT)
+	return x
+}
+
+// Union initializes t2 to point to the union at the given offset.
+func (t *BufferTable) Union(t2 *BufferTable, off UOffsetT) {
+	off += t.Pos
+	t2.Pos = off + t.GetUOffsetT(off)
+	t2.Buf = t.Buf
+}
+
+// GetBool retrieves a bool at the given offset.
+func (t *BufferTable) GetBool(off UOffsetT) bool {
+	return t.Buf.At(int(off)) != 0
+}
+
+// GetByte retrieves a byte at the given offset.
+func (t *BufferTable) GetByte(off UOffsetT) byte {
+	return t.Buf.At(int(off))
+}
+
+// GetUint8 retrieves a uint8 at the given offset.
+func (t *BufferTable) GetUint8(off UOffsetT) uint8 {
+	return t.Buf.At(int(off))
+}
+
+// GetUint16 retrieves a uint16 at the given offset.
+func (t *BufferTable) GetUint16(off UOffsetT) uint16 {
+	return GetUint16(t.read(off, SizeUint16))
+}
+
+// GetUint32 retrieves a uint32 at the given offset.
+func (t *BufferTable) GetUint32(off UOffsetT) uint32 {
+	return GetUint32(t.read(off, SizeUint32))
+}
+
+// GetUint64 retrieves a uint64 at the given offset.
+func (t *BufferTable) GetUint64(off UOffsetT) uint64 {
+	return GetUint64(t.read(off, SizeUint64))
+}
+
+// GetInt8 retrieves a int8 at the given offset.
+func (t *BufferTable) GetInt8(off UOffsetT) int8 {
+	return int8(t.Buf.At(int(off)))
+}
+
+// GetInt16 retrieves a int16 at the given offset.
+func (t *BufferTable) GetInt16(off UOffsetT) int16 {
+	return GetInt16(t.read(off, SizeInt16))
+}
+
+// GetInt32 retrieves a int32 at the given offset.
+func (t *BufferTable) GetInt32(off UOffsetT) int32 {
+	return GetInt32(t.read(off, SizeInt32))
+}
+
+// GetInt64 retrieves a int64 at the given offset.
+func (t *BufferTable) GetInt64(off UOffsetT) int64 {
+	return GetInt64(t.read(off, SizeInt64))
+}
+
+// GetFloat32 retrieves a float32 at the given offset.
+func (t *BufferTable) GetFloat32(off UOffsetT) float32 {
+	return GetFloat32(t.read(off, SizeFloat32))
+}
+
+// GetFloat64 retrieves a float64 at the given offset.
+func (t *BufferTable) GetFloat64(off UOffsetT) float64 {
+	return GetFloat64(t.read(off, SizeFloat64))
+}
+
+// GetUOffsetT retrieves an UOffsetT at the given offset.
+func (t *BufferTable) GetUOffsetT(off UOffsetT) UOffsetT {
+	return GetUOffsetT(t.read(off, SizeUOffsetT))
+}
+
+// GetVOffsetT retrieves an VOffsetT at the given offset.
+func (t *BufferTable) GetVOffsetT(off UOffsetT) VOffsetT {
+	return GetVOffsetT(t.read(off, SizeVOffsetT))
+}
+
+// GetSOffsetT retrieves an SOffsetT at the given offset.
+func (t *BufferTable) GetSOffsetT(off UOffsetT) SOffsetT {
+	return GetSOffsetT(t.read(off, SizeSOffsetT))
+}
+
+// GetBoolSlot retrieves the bool that the given vtable location
+// points to. If the vtable value is zero, the default value `d`
+// will be returned.
+func (t *BufferTable) GetBoolSlot(slot VOffsetT, d bool) bool {
+	off := t.Offset(slot)
+	if off == 0 {
+		return d
+	}
+
+	return t.GetBool(t.Pos + UOffsetT(off))
+}
+
+// GetByteSlot retrieves the byte that the given vtable location
+// points to. If the vtable value is zero, the default value `d`
+// will be returned.
+func (t *BufferTable) GetByteSlot(slot VOffsetT, d byte) byte {
+	off := t.Offset(slot)
+	if off == 0 {
+		return d
+	}
+
+	return t.GetByte(t.Pos + UOffsetT(off))
+}
+
+// GetUint8Slot retrieves the uint8 that the given vtable location
+// points to. If the vtable value is zero, the default value `d`
+// will be returned.
+func (t *BufferTable) GetUint8Slot(slot VOffsetT, d uint8) uint8 {
+	off := t.Offset(slot)
+	if off == 0 {
+		return d
+	}
+
+	return t.GetUint8(t.Pos + UOffsetT(off))
+}
+
+// GetUint16Slot retrieves the uint16 that the given vtable location
+// points to. If the vtable value is zero, the default value `d`
+// will be returned.
+func (t *BufferTable) GetUint16Slot(slot VOffsetT, d uint16) uint16 {
+	off := t.Offset(slot)
+	if off == 0 {
+		return d
+	}
+
+	return t.GetUint16(t.Pos + UOffsetT(off))
+}
+
+// GetUint32Slot retrieves the uint32 that the given vtable location
+// points to. If the vtable value is zero, the default value `d`
+// will be returned.
+func (t *BufferTable) GetUint32Slot(slot VOffsetT, d uint32) uint32 {
+	off := t.Offset(slot)
+	if off == 0 {
+		return d
+	}
+
+	return t.GetUint32(t.Pos + UOffsetT(off))
+}
+
+// GetUint64Slot retrieves the uint64 that the given vtable location
+// points to. If the vtable value is zero, the default value `d`
+// will be returned.
+func (t *BufferTable) GetUint64Slot(slot VOffsetT, d uint64) uint64 {
+	off := t.Offset(slot)
+	if off == 0 {
+		return d
+	}
+
+	return t.GetUint64(t.Pos + UOffsetT(off))
+}
+
+// GetInt8Slot retrieves the int8 that the given vtable location
+// points to. If the vtable value is zero, the default value `d`
+// will be returned.
+func (t *BufferTable) GetInt8Slot(slot VOffsetT, d int8) int8 {
+	off := t.Offset(slot)
+	if off == 0 {
+		return d
+	}
+
+	return t.GetInt8(t.Pos + UOffsetT(off))
+}
+
+// GetInt16Slot retrieves the int16 that the given vtable location
+// points to. If the vtable value is zero, the default value `d`
+// will be returned.
+func (t *BufferTable) GetInt16Slot(slot VOffsetT, d int16) int16 {
+	off := t.Offset(slot)
+	if off == 0 {
+		return d
+	}
+
+	return t.GetInt16(t.Pos + UOffsetT(off))
+}
+
+// GetInt32Slot retrieves the int32 that the given vtable location
+// points to. If the vtable value is zero, the default value `d`
+// will be returned.
+func (t *BufferTable) GetInt32Slot(slot VOffsetT, d int32) int32 {
+	off := t.Offset(slot)
+	if off == 0 {
+		return d
+	}
+
+	return t.GetInt32(t.Pos + UOffsetT(off))
+}
+
+// GetInt64Slot retrieves the int64 that the given vtable location
+// points to. If the vtable value is zero, the default value `d`
+// will be returned.
+func (t *BufferTable) GetInt64Slot(slot VOffsetT, d int64) int64 {
+	off := t.Offset(slot)
+	if off == 0 {
+		return d
+	}
+
+	return t.GetInt64(t.Pos + UOffsetT(off))
+}
+
+// GetFloat32Slot retrieves the float32 that the given vtable location
+// points to. If the vtable value is zero, the default value `d`
+// will be returned.
+func (t *BufferTable) GetFloat32Slot(slot VOffsetT, d float32) float32 {
+	off := t.Offset(slot)
+	if off == 0 {
+		return d
+	}
+
+	return t.GetFloat32(t.Pos + UOffsetT(off))
+}
+
+// GetFloat64Slot retrieves the float64 that the given vtable location
+// points to. If the vtable value is zero, the default value `d`
+// will be returned.
+func (t *BufferTable) GetFloat64Slot(slot VOffsetT, d float64) float64 {
+	off := t.Offset(slot)
+	if off == 0 {
+		return d
+	}
+
+	return t.GetFloat64(t.Pos + UOffsetT(off))
+}
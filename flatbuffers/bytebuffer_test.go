@@ -0,0 +1,134 @@
+package flatbuffers
+
+import (
+	"bytes"
+	"testing"
+)
+
+// chunks splits buf into chunks of n bytes, so that reads span them.
+func chunks(buf []byte, n int) [][]byte {
+	var out [][]byte
+	for len(buf) > n {
+		out = append(out, buf[:n:n])
+		buf = buf[n:]
+	}
+	return append(out, buf)
+}
+
+func TestChunkedBytes(t *testing.T) {
+	data := []byte("0123456789")
+	c := NewChunkedBytes([]byte("012"), nil, []byte("3456"), []byte("789"))
+	if got := c.Len(); got != len(data) {
+		t.Fatalf("got length %d, want=%d", got, len(data))
+	}
+	for i := range data {
+		if got := c.At(i); got != data[i] {
+			t.Fatalf("At(%d): got %q, want=%q", i, got, data[i])
+		}
+	}
+	for lo := 0; lo <= len(data); lo++ {
+		for hi := lo; hi <= len(data); hi++ {
+			if got := c.Slice(lo, hi); !bytes.Equal(got, data[lo:hi]) {
+				t.Fatalf("Slice(%d, %d): got %q, want=%q", lo, hi, got, data[lo:hi])
+			}
+		}
+	}
+
+	// Slices within a chunk share its memory, others are copies.
+	if got := c.Slice(3, 5); &got[0] != &c.chunks[2][0] {
+		t.Fatal("slice within a chunk was copied")
+	}
+
+	for _, fn := range []func(){
+		func() { c.At(10) },
+		func() { c.At(-1) },
+		func() { c.Slice(4, 11) },
+		func() { c.Slice(5, 4) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected an out of range panic")
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func TestBufferTable(t *testing.T) {
+	b := NewBuilder(0)
+	child := func() UOffsetT {
+		b.StartObject(1)
+		b.PrependInt16Slot(0, -7, 0)
+		return b.EndObject()
+	}()
+	name := b.CreateString("a string spanning chunks")
+	blob := b.CreateByteVector([]byte{1, 2, 3, 4, 5})
+	b.StartVector(SizeInt64, 3, SizeInt64)
+	for _, v := range []int64{3, 2, 1} {
+		b.PrependInt64(v)
+	}
+	ints := b.EndVector(3)
+	tab := finishTable(b, 8, func(b *Builder) {
+		b.PrependUOffsetTSlot(0, name, 0)
+		b.PrependUOffsetTSlot(1, blob, 0)
+		b.PrependUOffsetTSlot(2, ints, 0)
+		b.PrependUOffsetTSlot(3, child, 0)
+		b.PrependBoolSlot(4, true, false)
+		b.PrependFloat64Slot(5, 2.5, 0)
+		b.PrependUint32Slot(6, 0xdeadbeef, 0)
+	})
+
+	for _, n := range []int{1, 3, 7, len(tab.Bytes)} {
+		bt := BufferTable{Buf: NewChunkedBytes(chunks(tab.Bytes, n)...), Pos: tab.Pos}
+		for i := 0; i < 9; i++ {
+			if got, want := bt.Offset(slot(i)), tab.Offset(slot(i)); got != want {
+				t.Fatalf("chunks of %d: field %d: got offset %d, want=%d", n, i, got, want)
+			}
+		}
+		field := func(i int) UOffsetT { return tab.Pos + UOffsetT(tab.Offset(slot(i))) }
+
+		if got, want := bt.String(field(0)), tab.String(field(0)); got != want {
+			t.Errorf("chunks of %d: got string %q, want=%q", n, got, want)
+		}
+		if got, want := bt.ByteVector(field(1)), tab.ByteVector(field(1)); !bytes.Equal(got, want) {
+			t.Errorf("chunks of %d: got byte vector %v, want=%v", n, got, want)
+		}
+		off := UOffsetT(tab.Offset(slot(2)))
+		if got, want := bt.VectorLen(off), tab.VectorLen(off); got != want {
+			t.Fatalf("chunks of %d: got vector length %d, want=%d", n, got, want)
+		}
+		for i, start := 0, bt.Vector(off); i < 3; i++ {
+			if got, want := bt.GetInt64(start+UOffsetT(i*SizeInt64)), int64(i+1); got != want {
+				t.Errorf("chunks of %d: got element %d = %d, want=%d", n, i, got, want)
+			}
+		}
+		var sub BufferTable
+		bt.Union(&sub, UOffsetT(tab.Offset(slot(3))))
+		if got := sub.GetInt16Slot(slot(0), 0); got != -7 {
+			t.Errorf("chunks of %d: got child field %d, want=-7", n, got)
+		}
+		if got := bt.Indirect(field(3)); got != sub.Pos {
+			t.Errorf("chunks of %d: got indirect %d, want=%d", n, got, sub.Pos)
+		}
+		if !bt.GetBoolSlot(slot(4), false) || bt.GetFloat64Slot(slot(5), 0) != 2.5 ||
+			bt.GetUint32Slot(slot(6), 0) != 0xdeadbeef || bt.GetInt32Slot(slot(7), 9) != 9 {
+			t.Errorf("chunks of %d: scalar fields differ from the Table", n)
+		}
+
+		if _, ok := bt.Table(); ok {
+			t.Errorf("chunks of %d: got a Table of chunked bytes", n)
+		}
+	}
+
+	// ByteSlice buffers convert back to a Table for generated accessors.
+	bt := BufferTable{Buf: ByteSlice(tab.Bytes), Pos: tab.Pos}
+	got, ok := bt.Table()
+	if !ok || got.Pos != tab.Pos || &got.Bytes[0] != &tab.Bytes[0] {
+		t.Fatal("ByteSlice not converted to a Table sharing its bytes")
+	}
+	if s := bt.String(UOffsetT(tab.Offset(slot(0))) + tab.Pos); s != "a string spanning chunks" {
+		t.Fatalf("got %q", s)
+	}
+}
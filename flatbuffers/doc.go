@@ -1,5 +1,11 @@
 // Package flatbuffers provides facilities to read and write flatbuffers
 // objects.
+//
+// Building with the flatbuffers_strict tag changes two things, for hosts or
+// checkers that forbid package unsafe on buffers: the multi-byte scalars are
+// encoded with encoding/binary instead of byte shifts, and Table.String
+// copies the string out of the buffer instead of aliasing it. The encoded
+// bytes are the same in both builds; Strict reports which one is in use.
 package flatbuffers
 
 // 简单来说 FlatBuffers 就是把对象数据，保存在一个一维的数组中，将数据都缓存在一个 ByteBuffer 中，每个对象在数组中被分为两部分。
@@ -0,0 +1,264 @@
+package flatbuffers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Dump returns an annotated hex layout of the finished buffer buf: the root
+// offset, the file identifier, and the vtables, objects and fields of the
+// tables reachable from the root, like the narrative of David-Comments.go but
+// computed from the bytes. Bytes outside of any region are shown as padding
+// when zero and unknown otherwise.
+//
+// Without a schema, Dump cannot tell offsets from scalars: it follows the
+// 4-byte fields that point to a plausible table, string or vector, and marks
+// these with a question mark. It is meant for debugging and never panics on
+// malformed buffers.
+func Dump(buf []byte) string {
+	d := &dumper{buf: buf, seen: make(map[int]bool)}
+	if len(buf) < SizeUOffsetT {
+		var sb strings.Builder
+		d.lines(&sb, 0, len(buf), "truncated buffer")
+		return sb.String()
+	}
+
+	root := int(GetUOffsetT(buf))
+	label := fmt.Sprintf("root offset -> table @%#04x", root)
+	if !d.inBounds(root, 0) || root == len(buf) {
+		// 根表不在 buffer 内，没有可以标注的 region
+		label += ", invalid root table"
+	}
+	d.add(0, SizeUOffsetT, label)
+	if root >= 2*SizeUOffsetT && len(buf) >= 2*SizeUOffsetT && isPrintable(buf[SizeUOffsetT:2*SizeUOffsetT]) {
+		d.add(SizeUOffsetT, 2*SizeUOffsetT, fmt.Sprintf("file identifier %q", buf[SizeUOffsetT:2*SizeUOffsetT]))
+	}
+	if !d.isTable(root) {
+		d.add(root, len(buf), "invalid root table")
+		return d.String()
+	}
+	d.table(root, "root table")
+	return d.String()
+}
+
+// region is a labelled byte range [start, end) of a dumped buffer.
+type region struct {
+	start, end int
+	label      string
+}
+
+type dumper struct {
+	buf     []byte
+	regions []region
+	seen    map[int]bool // 已经添加过的 region 起始位置，避免共享的 vtable 或循环引用重复展开
+}
+
+func (d *dumper) add(start, end int, label string) {
+	if start < 0 || end > len(d.buf) || start >= end || d.seen[start] {
+		return
+	}
+	d.seen[start] = true
+	d.regions = append(d.regions, region{start: start, end: end, label: label})
+}
+
+func (d *dumper) inBounds(pos, n int) bool {
+	return pos >= 0 && n >= 0 && pos+n <= len(d.buf)
+}
+
+// isTable reports whether a table with a sane vtable starts at pos.
+func (d *dumper) isTable(pos int) bool {
+	if !d.inBounds(pos, SizeSOffsetT) {
+		return false
+	}
+	vt := pos - int(GetSOffsetT(d.buf[pos:]))
+	if !d.inBounds(vt, VtableMetadataFields*SizeVOffsetT) {
+		return false
+	}
+	vtSize := int(GetVOffsetT(d.buf[vt:]))
+	objSize := int(GetVOffsetT(d.buf[vt+SizeVOffsetT:]))
+	if vtSize < VtableMetadataFields*SizeVOffsetT || vtSize%SizeVOffsetT != 0 || !d.inBounds(vt, vtSize) {
+		return false
+	}
+	if objSize < SizeSOffsetT || !d.inBounds(pos, objSize) {
+		return false
+	}
+	for i := VtableMetadataFields * SizeVOffsetT; i < vtSize; i += SizeVOffsetT {
+		if int(GetVOffsetT(d.buf[vt+i:])) >= objSize {
+			return false
+		}
+	}
+	return true
+}
+
+// table adds the regions of the table at pos, which isTable accepted.
+func (d *dumper) table(pos int, label string) {
+	if d.seen[pos] {
+		return
+	}
+	vt := pos - int(GetSOffsetT(d.buf[pos:]))
+	vtSize := int(GetVOffsetT(d.buf[vt:]))
+	objSize := int(GetVOffsetT(d.buf[vt+SizeVOffsetT:]))
+	d.add(pos, pos+SizeSOffsetT, fmt.Sprintf("%s: soffset -> vtable @%#04x", label, vt))
+
+	if !d.seen[vt] {
+		d.add(vt, vt+VtableMetadataFields*SizeVOffsetT, fmt.Sprintf("vtable: size %d, object size %d", vtSize, objSize))
+		for i := VtableMetadataFields * SizeVOffsetT; i < vtSize; i += SizeVOffsetT {
+			field := i/SizeVOffsetT - VtableMetadataFields
+			off := GetVOffsetT(d.buf[vt+i:])
+			if off == 0 {
+				d.add(vt+i, vt+i+SizeVOffsetT, fmt.Sprintf("  field %d: absent", field))
+			} else {
+				d.add(vt+i, vt+i+SizeVOffsetT, fmt.Sprintf("  field %d: offset %d", field, off))
+			}
+		}
+	}
+
+	// 字段的大小没有记录，按照相邻字段的偏移推算，最后一个字段可能包含 padding
+	type field struct{ index, off int }
+	var fields []field
+	for i := VtableMetadataFields * SizeVOffsetT; i < vtSize; i += SizeVOffsetT {
+		if off := int(GetVOffsetT(d.buf[vt+i:])); off != 0 {
+			fields = append(fields, field{index: i/SizeVOffsetT - VtableMetadataFields, off: off})
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].off < fields[j].off })
+	var targets []int
+	for i, f := range fields {
+		end := objSize
+		if i+1 < len(fields) {
+			end = fields[i+1].off
+		}
+		start := pos + f.off
+		label := fmt.Sprintf("  field %d", f.index)
+		if end-f.off >= SizeUOffsetT && start%SizeUOffsetT == 0 {
+			if target := start + int(GetUOffsetT(d.buf[start:])); target > start && target < len(d.buf) {
+				if kind := d.kind(target); kind != "" {
+					label += fmt.Sprintf(" (uoffset?) -> %s @%#04x", kind, target)
+					targets = append(targets, target)
+					end = f.off + SizeUOffsetT
+				}
+			}
+		}
+		d.add(start, pos+end, label)
+	}
+
+	for _, target := range targets {
+		d.follow(target)
+	}
+}
+
+// kind returns what the data at pos looks like: a table, a string, a vector or
+// nothing.
+func (d *dumper) kind(pos int) string {
+	switch {
+	case d.isTable(pos):
+		return "table"
+	case d.isString(pos):
+		return "string"
+	case d.isVector(pos):
+		return "vector"
+	default:
+		return ""
+	}
+}
+
+func (d *dumper) isVector(pos int) bool {
+	return d.inBounds(pos, SizeUOffsetT) && d.inBounds(pos+SizeUOffsetT, int(GetUOffsetT(d.buf[pos:])))
+}
+
+func (d *dumper) isString(pos int) bool {
+	if !d.isVector(pos) {
+		return false
+	}
+	n := int(GetUOffsetT(d.buf[pos:]))
+	end := pos + SizeUOffsetT + n
+	return end < len(d.buf) && d.buf[end] == 0 && isPrintable(d.buf[pos+SizeUOffsetT:end])
+}
+
+func (d *dumper) follow(pos int) {
+	switch d.kind(pos) {
+	case "table":
+		d.table(pos, "table?")
+	case "string":
+		n := int(GetUOffsetT(d.buf[pos:]))
+		d.add(pos, pos+SizeUOffsetT, fmt.Sprintf("string?: length %d", n))
+		d.add(pos+SizeUOffsetT, pos+SizeUOffsetT+n+1, fmt.Sprintf("  %q + NUL", d.buf[pos+SizeUOffsetT:pos+SizeUOffsetT+n]))
+	case "vector":
+		d.add(pos, pos+SizeUOffsetT, fmt.Sprintf("vector?: length %d", GetUOffsetT(d.buf[pos:])))
+	}
+}
+
+// dumpBytesPerLine is the number of bytes of a line of Dump.
+const dumpBytesPerLine = 8
+
+func (d *dumper) String() string {
+	sort.Slice(d.regions, func(i, j int) bool { return d.regions[i].start < d.regions[j].start })
+
+	var sb strings.Builder
+	pos := 0
+	for _, r := range d.regions {
+		if r.start < pos {
+			// 与前一个 region 重叠，只输出未覆盖的部分
+			if r.end <= pos {
+				continue
+			}
+			r.start = pos
+		}
+		d.gap(&sb, pos, r.start)
+		d.lines(&sb, r.start, r.end, r.label)
+		pos = r.end
+	}
+	d.gap(&sb, pos, len(d.buf))
+	return sb.String()
+}
+
+// gap writes the bytes [start, end) outside of any region.
+func (d *dumper) gap(sb *strings.Builder, start, end int) {
+	for start < end {
+		zero := d.buf[start] == 0
+		n := start + 1
+		for n < end && (d.buf[n] == 0) == zero {
+			n++
+		}
+		if zero {
+			d.lines(sb, start, n, "padding")
+		} else {
+			d.lines(sb, start, n, "unknown")
+		}
+		start = n
+	}
+}
+
+// lines writes the bytes [start, end), labelling the first line. An empty
+// range still gets a line for its label.
+func (d *dumper) lines(sb *strings.Builder, start, end int, label string) {
+	for pos := start; pos < end || pos == start; pos += dumpBytesPerLine {
+		n := end - pos
+		if n > dumpBytesPerLine {
+			n = dumpBytesPerLine
+		}
+		fmt.Fprintf(sb, "%04x: ", pos)
+		for i := 0; i < dumpBytesPerLine; i++ {
+			if i < n {
+				fmt.Fprintf(sb, "%02x ", d.buf[pos+i])
+			} else {
+				sb.WriteString("   ")
+			}
+		}
+		if pos == start {
+			sb.WriteString(" ")
+			sb.WriteString(label)
+		}
+		sb.WriteString("\n")
+	}
+}
+
+func isPrintable(b []byte) bool {
+	for _, c := range b {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package flatbuffers
+
+import (
+	"encoding/hex"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	b := NewBuilder(0)
+	name := b.CreateString("hi")
+	b.StartObject(3)
+	b.PrependInt16Slot(0, 7, 0)
+	b.PrependUOffsetTSlot(2, name, 0)
+	b.FinishWithFileIdentifier(b.EndObject(), []byte("TEST"))
+
+	want := `
+0000: 14 00 00 00              root offset -> table @0x0014
+0004: 54 45 53 54              file identifier "TEST"
+0008: 00 00                    padding
+000a: 0a 00 0c 00              vtable: size 10, object size 12
+000e: 0a 00                      field 0: offset 10
+0010: 00 00                      field 1: absent
+0012: 04 00                      field 2: offset 4
+0014: 0a 00 00 00              root table: soffset -> vtable @0x000a
+0018: 08 00 00 00                field 2 (uoffset?) -> string @0x0020
+001c: 00 00                    padding
+001e: 07 00                      field 0
+0020: 02 00 00 00              string?: length 2
+0024: 68 69 00                   "hi" + NUL
+0027: 00                       padding
+`
+	if got := Dump(b.FinishedBytes()); got != want[1:] {
+		t.Fatalf("got\n%s\nwant\n%s", got, want[1:])
+	}
+}
+
+// dumpedBytes returns the bytes listed by the lines of a Dump.
+func dumpedBytes(t *testing.T, dump string) []byte {
+	t.Helper()
+	var out []byte
+	for _, line := range strings.Split(strings.TrimSuffix(dump, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		if len(line) < 6+3*dumpBytesPerLine {
+			t.Fatalf("short line %q", line)
+		}
+		hexBytes := strings.ReplaceAll(line[6:6+3*dumpBytesPerLine], " ", "")
+		b, err := hex.DecodeString(hexBytes)
+		if err != nil {
+			t.Fatalf("line %q: %v", line, err)
+		}
+		out = append(out, b...)
+	}
+	return out
+}
+
+func TestDumpMalformed(t *testing.T) {
+	valid := buildSample(NewBuilder(0), "sample", 1)
+
+	bufs := map[string][]byte{
+		"empty":               nil,
+		"truncated":           {1, 2, 3},
+		"root out of range":   {0xff, 0, 0, 0, 0, 0, 0, 0},
+		"vtable out of range": {8, 0, 0, 0, 0, 0, 0, 0, 0x80, 0, 0, 0},
+		"cut":                 valid[:len(valid)-3],
+	}
+	// Self-referencing tables and offsets must not loop.
+	loop := append([]byte(nil), valid...)
+	root := int(GetUOffsetT(loop))
+	WriteSOffsetT(loop[root:], 0)
+	bufs["vtable on the table"] = loop
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		buf := append([]byte(nil), valid...)
+		for j := 0; j < 1+i%4; j++ {
+			buf[rnd.Intn(len(buf))] = byte(rnd.Intn(256))
+		}
+		bufs["corrupted "+string(rune('a'+i%26))+string(rune('a'+i/26))] = buf
+	}
+
+	for name, buf := range bufs {
+		dump := Dump(buf)
+		if got := dumpedBytes(t, dump); string(got) != string(buf) {
+			t.Fatalf("%s: dump does not list every byte once:\n%s", name, dump)
+		}
+	}
+	for name, label := range map[string]string{
+		"empty":             "truncated buffer",
+		"truncated":         "truncated buffer",
+		"root out of range": "invalid root table",
+	} {
+		if dump := Dump(bufs[name]); !strings.Contains(dump, label) {
+			t.Errorf("%s: want %q in\n%s", name, label, dump)
+		}
+	}
+	if dump := Dump(valid); !strings.Contains(dump, `"sample" + NUL`) || !strings.Contains(dump, "root table") {
+		t.Fatalf("dump of a valid buffer misses its fields:\n%s", dump)
+	}
+}
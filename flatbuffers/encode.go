@@ -40,38 +40,6 @@ func GetUint8(buf []byte) (n uint8) {
 	return
 }
 
-// GetUint16 decodes a little-endian uint16 from a byte slice.
-func GetUint16(buf []byte) (n uint16) {
-	_ = buf[1] // Force one bounds check. See: golang.org/issue/14808
-	n |= uint16(buf[0])
-	n |= uint16(buf[1]) << 8
-	return
-}
-
-// GetUint32 decodes a little-endian uint32 from a byte slice.
-func GetUint32(buf []byte) (n uint32) {
-	_ = buf[3] // Force one bounds check. See: golang.org/issue/14808
-	n |= uint32(buf[0])
-	n |= uint32(buf[1]) << 8
-	n |= uint32(buf[2]) << 16
-	n |= uint32(buf[3]) << 24
-	return
-}
-
-// GetUint64 decodes a little-endian uint64 from a byte slice.
-func GetUint64(buf []byte) (n uint64) {
-	_ = buf[7] // Force one bounds check. See: golang.org/issue/14808
-	n |= uint64(buf[0])
-	n |= uint64(buf[1]) << 8
-	n |= uint64(buf[2]) << 16
-	n |= uint64(buf[3]) << 24
-	n |= uint64(buf[4]) << 32
-	n |= uint64(buf[5]) << 40
-	n |= uint64(buf[6]) << 48
-	n |= uint64(buf[7]) << 56
-	return
-}
-
 // GetInt8 decodes a little-endian int8 from a byte slice.
 func GetInt8(buf []byte) (n int8) {
 	n = int8(buf[0])
@@ -159,35 +127,6 @@ func WriteUint8(buf []byte, n uint8) {
 	buf[0] = byte(n)
 }
 
-// WriteUint16 encodes a little-endian uint16 into a byte slice.
-func WriteUint16(buf []byte, n uint16) {
-	_ = buf[1] // Force one bounds check. See: golang.org/issue/14808
-	buf[0] = byte(n)
-	buf[1] = byte(n >> 8)
-}
-
-// WriteUint32 encodes a little-endian uint32 into a byte slice.
-func WriteUint32(buf []byte, n uint32) {
-	_ = buf[3] // Force one bounds check. See: golang.org/issue/14808
-	buf[0] = byte(n)
-	buf[1] = byte(n >> 8)
-	buf[2] = byte(n >> 16)
-	buf[3] = byte(n >> 24)
-}
-
-// WriteUint64 encodes a little-endian uint64 into a byte slice.
-func WriteUint64(buf []byte, n uint64) {
-	_ = buf[7] // Force one bounds check. See: golang.org/issue/14808
-	buf[0] = byte(n)
-	buf[1] = byte(n >> 8)
-	buf[2] = byte(n >> 16)
-	buf[3] = byte(n >> 24)
-	buf[4] = byte(n >> 32)
-	buf[5] = byte(n >> 40)
-	buf[6] = byte(n >> 48)
-	buf[7] = byte(n >> 56)
-}
-
 // WriteInt8 encodes a little-endian int8 into a byte slice.
 func WriteInt8(buf []byte, n int8) {
 	buf[0] = byte(n)
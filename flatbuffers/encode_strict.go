@@ -0,0 +1,50 @@
+//go:build flatbuffers_strict
+// +build flatbuffers_strict
+
+package flatbuffers
+
+import "encoding/binary"
+
+// With the flatbuffers_strict tag, the multi-byte scalars are encoded with
+// encoding/binary and strings are copied out of buffers, so that the package
+// never uses package unsafe on buffers, for hosts or checkers that forbid it.
+//
+// strict 模式：使用 encoding/binary ，字符串总是复制。
+
+// Strict reports whether the package was built with the flatbuffers_strict tag.
+const Strict = true
+
+// GetUint16 decodes a little-endian uint16 from a byte slice.
+func GetUint16(buf []byte) uint16 {
+	return binary.LittleEndian.Uint16(buf)
+}
+
+// GetUint32 decodes a little-endian uint32 from a byte slice.
+func GetUint32(buf []byte) uint32 {
+	return binary.LittleEndian.Uint32(buf)
+}
+
+// GetUint64 decodes a little-endian uint64 from a byte slice.
+func GetUint64(buf []byte) uint64 {
+	return binary.LittleEndian.Uint64(buf)
+}
+
+// WriteUint16 encodes a little-endian uint16 into a byte slice.
+func WriteUint16(buf []byte, n uint16) {
+	binary.LittleEndian.PutUint16(buf, n)
+}
+
+// WriteUint32 encodes a little-endian uint32 into a byte slice.
+func WriteUint32(buf []byte, n uint32) {
+	binary.LittleEndian.PutUint32(buf, n)
+}
+
+// WriteUint64 encodes a little-endian uint64 into a byte slice.
+func WriteUint64(buf []byte, n uint64) {
+	binary.LittleEndian.PutUint64(buf, n)
+}
+
+// byteSliceToString converts a []byte to string, copying it.
+func byteSliceToString(b []byte) string {
+	return string(b)
+}
@@ -0,0 +1,136 @@
+package flatbuffers
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+// The expected bytes are spelled out rather than computed with the host byte
+// order, so that running the tests on a big-endian or alignment-strict host,
+// e.g. GOARCH=s390x under qemu, or with -tags flatbuffers_strict, checks the
+// encoding is the same everywhere.
+
+func TestEncodeScalars(t *testing.T) {
+	tests := []struct {
+		name  string
+		want  []byte
+		write func(buf []byte)
+		check func(buf []byte) bool
+	}{
+		{"bool", []byte{1},
+			func(buf []byte) { WriteBool(buf, true) },
+			func(buf []byte) bool { return GetBool(buf) }},
+		{"int8", []byte{0xfe},
+			func(buf []byte) { WriteInt8(buf, -2) },
+			func(buf []byte) bool { return GetInt8(buf) == -2 }},
+		{"uint16", []byte{0x34, 0x12},
+			func(buf []byte) { WriteUint16(buf, 0x1234) },
+			func(buf []byte) bool { return GetUint16(buf) == 0x1234 }},
+		{"int16", []byte{0xfe, 0xff},
+			func(buf []byte) { WriteInt16(buf, -2) },
+			func(buf []byte) bool { return GetInt16(buf) == -2 }},
+		{"uint32", []byte{0x78, 0x56, 0x34, 0x12},
+			func(buf []byte) { WriteUint32(buf, 0x12345678) },
+			func(buf []byte) bool { return GetUint32(buf) == 0x12345678 }},
+		{"int32", []byte{0xfe, 0xff, 0xff, 0xff},
+			func(buf []byte) { WriteInt32(buf, -2) },
+			func(buf []byte) bool { return GetInt32(buf) == -2 }},
+		{"uint64", []byte{0xef, 0xcd, 0xab, 0x89, 0x67, 0x45, 0x23, 0x01},
+			func(buf []byte) { WriteUint64(buf, 0x0123456789abcdef) },
+			func(buf []byte) bool { return GetUint64(buf) == 0x0123456789abcdef }},
+		{"int64", []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			func(buf []byte) { WriteInt64(buf, -2) },
+			func(buf []byte) bool { return GetInt64(buf) == -2 }},
+		{"float32", []byte{0x00, 0x00, 0xa0, 0xbf},
+			func(buf []byte) { WriteFloat32(buf, -1.25) },
+			func(buf []byte) bool { return GetFloat32(buf) == -1.25 }},
+		{"float64", []byte{0x18, 0x2d, 0x44, 0x54, 0xfb, 0x21, 0x09, 0x40},
+			func(buf []byte) { WriteFloat64(buf, math.Pi) },
+			func(buf []byte) bool { return GetFloat64(buf) == math.Pi }},
+		{"soffset", []byte{0xf4, 0xff, 0xff, 0xff},
+			func(buf []byte) { WriteSOffsetT(buf, -12) },
+			func(buf []byte) bool { return GetSOffsetT(buf) == -12 }},
+		{"voffset", []byte{0x0a, 0x00},
+			func(buf []byte) { WriteVOffsetT(buf, 10) },
+			func(buf []byte) bool { return GetVOffsetT(buf) == 10 }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Every offset of the buffer, to read and write unaligned values.
+			for off := 0; off < 8; off++ {
+				buf := make([]byte, 16)
+				tc.write(buf[off:])
+				if got := buf[off : off+len(tc.want)]; !bytes.Equal(got, tc.want) {
+					t.Fatalf("offset %d: got=%#v, want=%#v", off, got, tc.want)
+				}
+				if !tc.check(buf[off:]) {
+					t.Fatalf("offset %d: decoded value differs", off)
+				}
+			}
+		})
+	}
+}
+
+func TestBuilderBytes(t *testing.T) {
+	b := NewBuilder(0)
+	name := b.CreateString("gomem")
+	b.StartObject(4)
+	b.PrependUOffsetTSlot(0, name, 0)
+	b.PrependInt64Slot(1, -2, 0)
+	b.PrependFloat32Slot(2, 1.5, 0)
+	b.PrependUint16Slot(3, 0xbeef, 0)
+	b.Finish(b.EndObject())
+
+	want := []byte{
+		0x14, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x0c, 0x00, 0x18, 0x00, 0x14, 0x00, 0x0c, 0x00,
+		0x08, 0x00, 0x06, 0x00, 0x0c, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0xef, 0xbe, 0x00, 0x00, 0xc0, 0x3f,
+		0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0x04, 0x00, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00,
+		0x67, 0x6f, 0x6d, 0x65, 0x6d, 0x00, 0x00, 0x00,
+	}
+	got := b.FinishedBytes()
+	if !bytes.Equal(got, want) {
+		t.Fatalf("invalid bytes:\n%s", Dump(got))
+	}
+
+	tab := &Table{Bytes: got, Pos: GetUOffsetT(got)}
+	if v := tab.String(tab.Pos + UOffsetT(tab.Offset(4))); v != "gomem" {
+		t.Fatalf("got=%q, want=%q", v, "gomem")
+	}
+	if v := tab.GetInt64Slot(6, 0); v != -2 {
+		t.Fatalf("got=%d, want=%d", v, -2)
+	}
+	if v := tab.GetFloat32Slot(8, 0); v != 1.5 {
+		t.Fatalf("got=%v, want=%v", v, 1.5)
+	}
+	if v := tab.GetUint16Slot(10, 0); v != 0xbeef {
+		t.Fatalf("got=%#x, want=%#x", v, 0xbeef)
+	}
+}
+
+// TestTableStringAliasing checks the one behavior the flatbuffers_strict tag
+// changes beyond the encoding: whether strings alias the buffer.
+func TestTableStringAliasing(t *testing.T) {
+	b := NewBuilder(0)
+	b.Finish(b.CreateString("gomem"))
+	buf := b.FinishedBytes()
+
+	tab := &Table{Bytes: buf}
+	s := tab.String(0)
+	if s != "gomem" {
+		t.Fatalf("got=%q, want=%q", s, "gomem")
+	}
+	copy(buf[len(buf)-8:], "GOMEM")
+
+	want := "GOMEM"
+	if Strict {
+		want = "gomem"
+	}
+	if s != want {
+		t.Fatalf("strict=%v: got=%q after changing the buffer, want=%q", Strict, s, want)
+	}
+}
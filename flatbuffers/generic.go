@@ -0,0 +1,104 @@
+//go:build go1.18
+// +build go1.18
+
+package flatbuffers
+
+import "unsafe"
+
+// Scalar is the constraint of the types a FlatBuffer stores as scalars: bool
+// and the numbers, including types defined on them such as UOffsetT, VOffsetT
+// and SOffsetT or generated enums.
+//
+// The generic functions below give code generators one API for every scalar
+// type instead of the per-type methods, which remain for older toolchains.
+type Scalar interface {
+	bool | ~int8 | ~uint8 | ~int16 | ~uint16 | ~int32 | ~uint32 | ~int64 | ~uint64 | ~float32 | ~float64
+}
+
+// SizeOf returns the byte size of a T.
+func SizeOf[T Scalar]() int {
+	var v T
+	return int(unsafe.Sizeof(v))
+}
+
+// Get decodes a little-endian T from a byte slice.
+func Get[T Scalar](buf []byte) T {
+	var v T
+	// 按照大小解码为无符号整数，再按位重新解释为 T ，与字节序无关
+	p := unsafe.Pointer(&v)
+	switch unsafe.Sizeof(v) {
+	case 1:
+		if b, ok := any(&v).(*bool); ok {
+			*b = GetBool(buf)
+		} else {
+			*(*uint8)(p) = GetUint8(buf)
+		}
+	case 2:
+		*(*uint16)(p) = GetUint16(buf)
+	case 4:
+		*(*uint32)(p) = GetUint32(buf)
+	case 8:
+		*(*uint64)(p) = GetUint64(buf)
+	}
+	return v
+}
+
+// Write encodes a little-endian T into a byte slice.
+func Write[T Scalar](buf []byte, v T) {
+	p := unsafe.Pointer(&v)
+	switch unsafe.Sizeof(v) {
+	case 1:
+		if b, ok := any(v).(bool); ok {
+			WriteBool(buf, b)
+		} else {
+			WriteUint8(buf, *(*uint8)(p))
+		}
+	case 2:
+		WriteUint16(buf, *(*uint16)(p))
+	case 4:
+		WriteUint32(buf, *(*uint32)(p))
+	case 8:
+		WriteUint64(buf, *(*uint64)(p))
+	}
+}
+
+// Prepend prepends a T to the Builder buffer.
+// Aligns and checks for space.
+func Prepend[T Scalar](b *Builder, v T) {
+	size := SizeOf[T]()
+	b.Prep(size, 0)
+	Place(b, v)
+}
+
+// Place prepends a T to the Builder, without checking for space.
+func Place[T Scalar](b *Builder, v T) {
+	b.head -= UOffsetT(SizeOf[T]())
+	Write(b.Bytes[b.head:], v)
+}
+
+// PrependSlot prepends a T onto the object at vtable slot `o`.
+// If value `x` equals default `d`, then the slot will be set to zero and no
+// other data will be written.
+func PrependSlot[T Scalar](b *Builder, o int, x, d T) {
+	if x != d {
+		Prepend(b, x)
+		b.Slot(o)
+	}
+}
+
+// GetAt retrieves a T at the given offset of the Table.
+func GetAt[T Scalar](t *Table, off UOffsetT) T {
+	return Get[T](t.Bytes[off:])
+}
+
+// GetSlot retrieves the T that the given vtable location of the Table
+// points to. If the vtable value is zero, the default value `d`
+// will be returned.
+func GetSlot[T Scalar](t *Table, slot VOffsetT, d T) T {
+	off := t.Offset(slot)
+	if off == 0 {
+		return d
+	}
+
+	return Get[T](t.Bytes[t.Pos+UOffsetT(off):])
+}
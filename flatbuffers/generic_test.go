@@ -0,0 +1,152 @@
+//go:build go1.18
+// +build go1.18
+
+package flatbuffers
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+// typedScalar holds the per-type functions and methods the generic ones
+// replace.
+type typedScalar[T Scalar] struct {
+	get         func(buf []byte) T
+	write       func(buf []byte, v T)
+	prepend     func(b *Builder, v T)
+	place       func(b *Builder, v T)
+	prependSlot func(b *Builder, o int, x, d T)
+	getAt       func(t *Table, off UOffsetT) T
+	getSlot     func(t *Table, slot VOffsetT, d T) T
+}
+
+// testEnum is a type defined on a scalar, like a generated enum.
+type testEnum int16
+
+// checkGeneric checks that the generic functions encode and decode each of
+// vals to the same bytes and values as the typed ones, d being the default of
+// the slots.
+func checkGeneric[T Scalar](t *testing.T, name string, typed typedScalar[T], d T, vals ...T) {
+	t.Helper()
+	for _, v := range vals {
+		// Bytes past the encoded value stay zero in both buffers.
+		got, want := make([]byte, 8), make([]byte, 8)
+		Write(got, v)
+		typed.write(want, v)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("%s(%v): Write=%x, want=%x", name, v, got, want)
+		}
+		if got, want := Get[T](want), typed.get(want); got != want || got != v {
+			t.Fatalf("%s(%v): Get=%v, typed=%v", name, v, got, want)
+		}
+
+		// An odd head checks that Prepend aligns like the typed methods.
+		gb, tb := NewBuilder(0), NewBuilder(0)
+		gb.PrependByte(1)
+		tb.PrependByte(1)
+		Prepend(gb, v)
+		typed.prepend(tb, v)
+		gb.Prep(SizeOf[T](), 0)
+		tb.Prep(SizeOf[T](), 0)
+		Place(gb, v)
+		typed.place(tb, v)
+		if !bytes.Equal(gb.Bytes[gb.Head():], tb.Bytes[tb.Head():]) {
+			t.Fatalf("%s(%v): Prepend/Place=%x, want=%x", name, v, gb.Bytes[gb.Head():], tb.Bytes[tb.Head():])
+		}
+
+		gtab := finishTable(NewBuilder(0), 2, func(b *Builder) {
+			PrependSlot(b, 0, v, d)
+			PrependSlot(b, 1, d, d)
+		})
+		ttab := finishTable(NewBuilder(0), 2, func(b *Builder) {
+			typed.prependSlot(b, 0, v, d)
+			typed.prependSlot(b, 1, d, d)
+		})
+		if !bytes.Equal(gtab.Bytes, ttab.Bytes) {
+			t.Fatalf("%s(%v): PrependSlot=%x, want=%x", name, v, gtab.Bytes, ttab.Bytes)
+		}
+		if got, want := GetSlot(&ttab, slot(0), d), typed.getSlot(&ttab, slot(0), d); got != want || got != v {
+			t.Fatalf("%s(%v): GetSlot=%v, typed=%v", name, v, got, want)
+		}
+		if got := GetSlot(&ttab, slot(1), d); got != d {
+			t.Fatalf("%s(%v): GetSlot of a defaulted slot=%v, want=%v", name, v, got, d)
+		}
+		if off := ttab.Offset(slot(0)); off != 0 {
+			pos := ttab.Pos + UOffsetT(off)
+			if got, want := GetAt[T](&ttab, pos), typed.getAt(&ttab, pos); got != want || got != v {
+				t.Fatalf("%s(%v): GetAt=%v, typed=%v", name, v, got, want)
+			}
+		}
+	}
+}
+
+func TestGenericScalars(t *testing.T) {
+	checkGeneric(t, "bool", typedScalar[bool]{
+		GetBool, WriteBool, (*Builder).PrependBool, (*Builder).PlaceBool,
+		(*Builder).PrependBoolSlot, (*Table).GetBool, (*Table).GetBoolSlot,
+	}, false, true, false)
+	checkGeneric(t, "int8", typedScalar[int8]{
+		GetInt8, WriteInt8, (*Builder).PrependInt8, (*Builder).PlaceInt8,
+		(*Builder).PrependInt8Slot, (*Table).GetInt8, (*Table).GetInt8Slot,
+	}, 0, math.MinInt8, -1, 0, math.MaxInt8)
+	checkGeneric(t, "uint8", typedScalar[uint8]{
+		GetUint8, WriteUint8, (*Builder).PrependUint8, (*Builder).PlaceUint8,
+		(*Builder).PrependUint8Slot, (*Table).GetUint8, (*Table).GetUint8Slot,
+	}, 3, 0, 3, math.MaxUint8)
+	checkGeneric(t, "int16", typedScalar[int16]{
+		GetInt16, WriteInt16, (*Builder).PrependInt16, (*Builder).PlaceInt16,
+		(*Builder).PrependInt16Slot, (*Table).GetInt16, (*Table).GetInt16Slot,
+	}, 0, math.MinInt16, -2, 0, 0x1234)
+	checkGeneric(t, "uint16", typedScalar[uint16]{
+		GetUint16, WriteUint16, (*Builder).PrependUint16, (*Builder).PlaceUint16,
+		(*Builder).PrependUint16Slot, (*Table).GetUint16, (*Table).GetUint16Slot,
+	}, 0, 0, 0x1234, math.MaxUint16)
+	checkGeneric(t, "int32", typedScalar[int32]{
+		GetInt32, WriteInt32, (*Builder).PrependInt32, (*Builder).PlaceInt32,
+		(*Builder).PrependInt32Slot, (*Table).GetInt32, (*Table).GetInt32Slot,
+	}, -1, math.MinInt32, -1, 0, 0x12345678)
+	checkGeneric(t, "uint32", typedScalar[uint32]{
+		GetUint32, WriteUint32, (*Builder).PrependUint32, (*Builder).PlaceUint32,
+		(*Builder).PrependUint32Slot, (*Table).GetUint32, (*Table).GetUint32Slot,
+	}, 0, 0, 0x12345678, math.MaxUint32)
+	checkGeneric(t, "int64", typedScalar[int64]{
+		GetInt64, WriteInt64, (*Builder).PrependInt64, (*Builder).PlaceInt64,
+		(*Builder).PrependInt64Slot, (*Table).GetInt64, (*Table).GetInt64Slot,
+	}, 0, math.MinInt64, -1, 0, 0x123456789abcdef)
+	checkGeneric(t, "uint64", typedScalar[uint64]{
+		GetUint64, WriteUint64, (*Builder).PrependUint64, (*Builder).PlaceUint64,
+		(*Builder).PrependUint64Slot, (*Table).GetUint64, (*Table).GetUint64Slot,
+	}, 0, 0, 0x123456789abcdef, math.MaxUint64)
+	checkGeneric(t, "float32", typedScalar[float32]{
+		GetFloat32, WriteFloat32, (*Builder).PrependFloat32, (*Builder).PlaceFloat32,
+		(*Builder).PrependFloat32Slot, (*Table).GetFloat32, (*Table).GetFloat32Slot,
+	}, 1.5, 0, 1.5, -3.25, math.MaxFloat32, float32(math.Inf(-1)))
+	checkGeneric(t, "float64", typedScalar[float64]{
+		GetFloat64, WriteFloat64, (*Builder).PrependFloat64, (*Builder).PlaceFloat64,
+		(*Builder).PrependFloat64Slot, (*Table).GetFloat64, (*Table).GetFloat64Slot,
+	}, 0, 0, math.Pi, -math.SmallestNonzeroFloat64, math.Inf(1))
+
+	// A defined type is encoded like its underlying type.
+	checkGeneric(t, "testEnum", typedScalar[testEnum]{
+		get:     func(buf []byte) testEnum { return testEnum(GetInt16(buf)) },
+		write:   func(buf []byte, v testEnum) { WriteInt16(buf, int16(v)) },
+		prepend: func(b *Builder, v testEnum) { b.PrependInt16(int16(v)) },
+		place:   func(b *Builder, v testEnum) { b.PlaceInt16(int16(v)) },
+		prependSlot: func(b *Builder, o int, x, d testEnum) {
+			b.PrependInt16Slot(o, int16(x), int16(d))
+		},
+		getAt: func(t *Table, off UOffsetT) testEnum { return testEnum(t.GetInt16(off)) },
+		getSlot: func(t *Table, slot VOffsetT, d testEnum) testEnum {
+			return testEnum(t.GetInt16Slot(slot, int16(d)))
+		},
+	}, 2, -1, 0, 2, 7)
+	checkGeneric(t, "UOffsetT", typedScalar[UOffsetT]{
+		GetUOffsetT, WriteUOffsetT,
+		func(b *Builder, v UOffsetT) { b.PrependUint32(uint32(v)) },
+		(*Builder).PlaceUOffsetT,
+		func(b *Builder, o int, x, d UOffsetT) { b.PrependUint32Slot(o, uint32(x), uint32(d)) },
+		(*Table).GetUOffsetT,
+		func(t *Table, slot VOffsetT, d UOffsetT) UOffsetT { return UOffsetT(t.GetUint32Slot(slot, uint32(d))) },
+	}, 0, 0, 1, math.MaxUint32)
+}
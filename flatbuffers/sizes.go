@@ -1,9 +1,5 @@
 package flatbuffers
 
-import (
-	"unsafe"
-)
-
 const (
 	// See http://golang.org/ref/spec#Numeric_types
 
@@ -48,8 +44,3 @@ const (
 	// The `VOffsetT` type is aliased (by flatbuffers convention) to uint16.
 	SizeVOffsetT = 2
 )
-
-// byteSliceToString converts a []byte to string without a heap allocation.
-func byteSliceToString(b []byte) string {
-	return *(*string)(unsafe.Pointer(&b))
-}
@@ -16,6 +16,9 @@ package flatbuffers
 type Table struct {
 	Bytes []byte
 	Pos   UOffsetT // Always < 1<<31.
+
+	slots    []UOffsetT // SetSlotCache 提供的缓存，slots[i] 为第 i 个字段的 offset + 1 ，0 表示尚未解析
+	slotsPos UOffsetT   // slots 对应的 Pos ，Pos 变化后缓存失效
 }
 
 // 假设 vtable 的起始偏移为 `vtable` ，因为每个 vtable 有 2 个 meta field ，占用 4 Byte ，
@@ -37,6 +40,13 @@ type Table struct {
 //
 // Fields which are deprecated are ignored by checking against the vtable's length.
 func (t *Table) Offset(vtableOffset VOffsetT) VOffsetT {
+	if t.slots != nil {
+		return t.cachedOffset(vtableOffset)
+	}
+	return t.vtableOffset(vtableOffset)
+}
+
+func (t *Table) vtableOffset(vtableOffset VOffsetT) VOffsetT {
 	// t.pos 记录着当前 object 的 root pos ，开始 4B 存储着 vtable 的相对偏移，这里计算出 vtable 的 offset ；
 	vtable := UOffsetT(SOffsetT(t.Pos) - t.GetSOffsetT(t.Pos))
 	// vtable 的开始 2B 存储着 vtable 的大小，这里读取出来后，校验参数合法性；
@@ -48,6 +58,45 @@ func (t *Table) Offset(vtableOffset VOffsetT) VOffsetT {
 	return 0
 }
 
+// SetSlotCache makes the Table memoize in cache the offsets Offset reads from
+// the vtable, so that hot fields skip the vtable indirection. Field i, at
+// vtable offset 4+2*i, is cached in cache[i]; fields past the end of cache are
+// not cached. SetSlotCache clears cache, and the Table clears it again when
+// Pos moves; after changing Bytes alone, call SetSlotCache again. A nil cache
+// turns memoization off.
+func (t *Table) SetSlotCache(cache []UOffsetT) {
+	for i := range cache {
+		cache[i] = 0
+	}
+	t.slots = cache
+	t.slotsPos = t.Pos
+}
+
+func (t *Table) cachedOffset(vtableOffset VOffsetT) VOffsetT {
+	if t.slotsPos != t.Pos {
+		t.SetSlotCache(t.slots)
+	}
+	i := int(vtableOffset)/SizeVOffsetT - VtableMetadataFields
+	if i < 0 || i >= len(t.slots) {
+		return t.vtableOffset(vtableOffset)
+	}
+	if t.slots[i] == 0 {
+		t.slots[i] = UOffsetT(t.vtableOffset(vtableOffset)) + 1
+	}
+	return VOffsetT(t.slots[i] - 1)
+}
+
+// ResolveSlot returns the position in Bytes of the field at the given vtable
+// location, or 0 if the field is not present. Callers may keep the position to
+// read the field later without going through the vtable.
+func (t *Table) ResolveSlot(slot VOffsetT) UOffsetT {
+	off := t.Offset(slot)
+	if off == 0 {
+		return 0
+	}
+	return t.Pos + UOffsetT(off)
+}
+
 // Indirect retrieves the relative offset stored at `offset`.
 // 间接寻址：off 处存储了相对于 off 的偏移量(4B)
 func (t *Table) Indirect(off UOffsetT) UOffsetT {
@@ -333,6 +382,150 @@ func (t *Table) GetFloat64Slot(slot VOffsetT, d float64) float64 {
 	return t.GetFloat64(t.Pos + UOffsetT(off))
 }
 
+// GetBoolSlotOptional retrieves the bool that the given vtable location
+// points to and whether it is present. If the vtable value is zero, the
+// zero value and false will be returned.
+func (t *Table) GetBoolSlotOptional(slot VOffsetT) (bool, bool) {
+	off := t.Offset(slot)
+	if off == 0 {
+		return false, false
+	}
+
+	return t.GetBool(t.Pos + UOffsetT(off)), true
+}
+
+// GetByteSlotOptional retrieves the byte that the given vtable location
+// points to and whether it is present. If the vtable value is zero, the
+// zero value and false will be returned.
+func (t *Table) GetByteSlotOptional(slot VOffsetT) (byte, bool) {
+	off := t.Offset(slot)
+	if off == 0 {
+		return 0, false
+	}
+
+	return t.GetByte(t.Pos + UOffsetT(off)), true
+}
+
+// GetUint8SlotOptional retrieves the uint8 that the given vtable location
+// points to and whether it is present. If the vtable value is zero, the
+// zero value and false will be returned.
+func (t *Table) GetUint8SlotOptional(slot VOffsetT) (uint8, bool) {
+	off := t.Offset(slot)
+	if off == 0 {
+		return 0, false
+	}
+
+	return t.GetUint8(t.Pos + UOffsetT(off)), true
+}
+
+// GetUint16SlotOptional retrieves the uint16 that the given vtable location
+// points to and whether it is present. If the vtable value is zero, the
+// zero value and false will be returned.
+func (t *Table) GetUint16SlotOptional(slot VOffsetT) (uint16, bool) {
+	off := t.Offset(slot)
+	if off == 0 {
+		return 0, false
+	}
+
+	return t.GetUint16(t.Pos + UOffsetT(off)), true
+}
+
+// GetUint32SlotOptional retrieves the uint32 that the given vtable location
+// points to and whether it is present. If the vtable value is zero, the
+// zero value and false will be returned.
+func (t *Table) GetUint32SlotOptional(slot VOffsetT) (uint32, bool) {
+	off := t.Offset(slot)
+	if off == 0 {
+		return 0, false
+	}
+
+	return t.GetUint32(t.Pos + UOffsetT(off)), true
+}
+
+// GetUint64SlotOptional retrieves the uint64 that the given vtable location
+// points to and whether it is present. If the vtable value is zero, the
+// zero value and false will be returned.
+func (t *Table) GetUint64SlotOptional(slot VOffsetT) (uint64, bool) {
+	off := t.Offset(slot)
+	if off == 0 {
+		return 0, false
+	}
+
+	return t.GetUint64(t.Pos + UOffsetT(off)), true
+}
+
+// GetInt8SlotOptional retrieves the int8 that the given vtable location
+// points to and whether it is present. If the vtable value is zero, the
+// zero value and false will be returned.
+func (t *Table) GetInt8SlotOptional(slot VOffsetT) (int8, bool) {
+	off := t.Offset(slot)
+	if off == 0 {
+		return 0, false
+	}
+
+	return t.GetInt8(t.Pos + UOffsetT(off)), true
+}
+
+// GetInt16SlotOptional retrieves the int16 that the given vtable location
+// points to and whether it is present. If the vtable value is zero, the
+// zero value and false will be returned.
+func (t *Table) GetInt16SlotOptional(slot VOffsetT) (int16, bool) {
+	off := t.Offset(slot)
+	if off == 0 {
+		return 0, false
+	}
+
+	return t.GetInt16(t.Pos + UOffsetT(off)), true
+}
+
+// GetInt32SlotOptional retrieves the int32 that the given vtable location
+// points to and whether it is present. If the vtable value is zero, the
+// zero value and false will be returned.
+func (t *Table) GetInt32SlotOptional(slot VOffsetT) (int32, bool) {
+	off := t.Offset(slot)
+	if off == 0 {
+		return 0, false
+	}
+
+	return t.GetInt32(t.Pos + UOffsetT(off)), true
+}
+
+// GetInt64SlotOptional retrieves the int64 that the given vtable location
+// points to and whether it is present. If the vtable value is zero, the
+// zero value and false will be returned.
+func (t *Table) GetInt64SlotOptional(slot VOffsetT) (int64, bool) {
+	off := t.Offset(slot)
+	if off == 0 {
+		return 0, false
+	}
+
+	return t.GetInt64(t.Pos + UOffsetT(off)), true
+}
+
+// GetFloat32SlotOptional retrieves the float32 that the given vtable location
+// points to and whether it is present. If the vtable value is zero, the
+// zero value and false will be returned.
+func (t *Table) GetFloat32SlotOptional(slot VOffsetT) (float32, bool) {
+	off := t.Offset(slot)
+	if off == 0 {
+		return 0, false
+	}
+
+	return t.GetFloat32(t.Pos + UOffsetT(off)), true
+}
+
+// GetFloat64SlotOptional retrieves the float64 that the given vtable location
+// points to and whether it is present. If the vtable value is zero, the
+// zero value and false will be returned.
+func (t *Table) GetFloat64SlotOptional(slot VOffsetT) (float64, bool) {
+	off := t.Offset(slot)
+	if off == 0 {
+		return 0, false
+	}
+
+	return t.GetFloat64(t.Pos + UOffsetT(off)), true
+}
+
 // GetVOffsetTSlot retrieves the VOffsetT that the given vtable location
 // points to. If the vtable value is zero, the default value `d`
 // will be returned.
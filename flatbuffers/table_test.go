@@ -0,0 +1,141 @@
+package flatbuffers
+
+import (
+	"testing"
+)
+
+// finishTable finishes b with the object built by fields and returns a Table
+// reading it.
+func finishTable(b *Builder, numFields int, fields func(b *Builder)) Table {
+	b.StartObject(numFields)
+	fields(b)
+	b.Finish(b.EndObject())
+	buf := b.FinishedBytes()
+	return Table{Bytes: buf, Pos: GetUOffsetT(buf)}
+}
+
+// slot returns the vtable offset of field i.
+func slot(i int) VOffsetT {
+	return VOffsetT((VtableMetadataFields + i) * SizeVOffsetT)
+}
+
+func TestOptionalScalarSlots(t *testing.T) {
+	tab := finishTable(NewBuilder(0), 8, func(b *Builder) {
+		// Optional values equal to the zero value are still written.
+		b.PrependInt32SlotOptional(0, 0)
+		b.PrependBoolSlotOptional(1, false)
+		b.PrependFloat64SlotOptional(2, 0)
+		b.PrependUint8SlotOptional(3, 200)
+		// Plain values equal to their default are not.
+		b.PrependInt32Slot(4, 0, 0)
+		b.PrependBoolSlot(5, false, false)
+		b.PrependFloat64Slot(6, 1.5, 0)
+		// Field 7 is never set.
+	})
+
+	if v, ok := tab.GetInt32SlotOptional(slot(0)); !ok || v != 0 {
+		t.Errorf("int32: got %v, %v, want=0, true", v, ok)
+	}
+	if v, ok := tab.GetBoolSlotOptional(slot(1)); !ok || v {
+		t.Errorf("bool: got %v, %v, want=false, true", v, ok)
+	}
+	if v, ok := tab.GetFloat64SlotOptional(slot(2)); !ok || v != 0 {
+		t.Errorf("float64: got %v, %v, want=0, true", v, ok)
+	}
+	if v, ok := tab.GetUint8SlotOptional(slot(3)); !ok || v != 200 {
+		t.Errorf("uint8: got %v, %v, want=200, true", v, ok)
+	}
+
+	// Values left at their default read as absent, with the zero value.
+	if v, ok := tab.GetInt32SlotOptional(slot(4)); ok || v != 0 {
+		t.Errorf("defaulted int32: got %v, %v, want=0, false", v, ok)
+	}
+	if v, ok := tab.GetBoolSlotOptional(slot(5)); ok || v {
+		t.Errorf("defaulted bool: got %v, %v, want=false, false", v, ok)
+	}
+	if v, ok := tab.GetFloat64SlotOptional(slot(6)); !ok || v != 1.5 {
+		t.Errorf("float64: got %v, %v, want=1.5, true", v, ok)
+	}
+	if v, ok := tab.GetInt64SlotOptional(slot(7)); ok || v != 0 {
+		t.Errorf("missing int64: got %v, %v, want=0, false", v, ok)
+	}
+	// Fields past the end of the vtable, as written by older code, too.
+	if v, ok := tab.GetInt16SlotOptional(slot(20)); ok || v != 0 {
+		t.Errorf("unknown field: got %v, %v, want=0, false", v, ok)
+	}
+
+	// The plain getters read present optional values, and defaults otherwise.
+	if got := tab.GetInt32Slot(slot(0), 42); got != 0 {
+		t.Errorf("got %d for a present zero, want=0", got)
+	}
+	if got := tab.GetInt32Slot(slot(4), 42); got != 42 {
+		t.Errorf("got %d for an absent field, want the default 42", got)
+	}
+}
+
+func TestTableSlotCache(t *testing.T) {
+	// The root holds offsets to two tables of different layouts.
+	b := NewBuilder(0)
+	b.StartObject(3)
+	b.PrependInt32Slot(0, 1, 0)
+	b.PrependInt32Slot(2, 3, 0)
+	a := b.EndObject()
+	b.StartObject(3)
+	b.PrependInt32Slot(1, 2, 0)
+	c := b.EndObject()
+	root := finishTable(b, 2, func(b *Builder) {
+		b.PrependUOffsetTSlot(0, a, 0)
+		b.PrependUOffsetTSlot(1, c, 0)
+	})
+	posA := root.Indirect(root.ResolveSlot(slot(0)))
+	posC := root.Indirect(root.ResolveSlot(slot(1)))
+
+	cache := make([]UOffsetT, 2) // fields 0 and 1, field 2 is not cached
+	tab := Table{Bytes: append([]byte(nil), root.Bytes...), Pos: posA}
+	tab.SetSlotCache(cache)
+	plain := Table{Bytes: root.Bytes, Pos: posA}
+	check := func(name string, want [3]int32) {
+		t.Helper()
+		for i, w := range want {
+			if got := tab.GetInt32Slot(slot(i), -1); got != w {
+				t.Errorf("%s: field %d: got %d, want=%d", name, i, got, w)
+			}
+		}
+	}
+	check("table a", [3]int32{1, -1, 3})
+	for i := 0; i < 3; i++ {
+		if got, want := tab.Offset(slot(i)), plain.Offset(slot(i)); got != want {
+			t.Errorf("field %d: got offset %d, want=%d", i, got, want)
+		}
+	}
+
+	// ResolveSlot gives the position of present fields, to read them later.
+	pos := tab.ResolveSlot(slot(0))
+	if pos == 0 || tab.GetInt32(pos) != 1 {
+		t.Fatalf("got position %d of field 0", pos)
+	}
+	if got := tab.ResolveSlot(slot(1)); got != 0 {
+		t.Fatalf("got position %d of an absent field, want=0", got)
+	}
+	if got := tab.ResolveSlot(slot(2)); got == 0 || tab.GetInt32(got) != 3 {
+		t.Fatalf("got position %d of the uncached field 2", got)
+	}
+
+	// Cached offsets no longer read the vtable, until the cache is set again.
+	vtable := UOffsetT(SOffsetT(posA) - tab.GetSOffsetT(posA))
+	WriteVOffsetT(tab.Bytes[vtable+UOffsetT(slot(0)):], 0)
+	WriteVOffsetT(tab.Bytes[vtable+UOffsetT(slot(2)):], 0)
+	check("memoized", [3]int32{1, -1, -1})
+	tab.SetSlotCache(cache)
+	check("after SetSlotCache", [3]int32{-1, -1, -1})
+
+	// Moving Pos to another table clears the cache.
+	tab.Pos = posC
+	check("table c", [3]int32{-1, 2, -1})
+
+	// A nil cache turns memoization off.
+	tab.SetSlotCache(nil)
+	tab.Bytes = root.Bytes
+	tab.Pos = posA
+	check("uncached", [3]int32{1, -1, 3})
+}
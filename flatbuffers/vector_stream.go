@@ -0,0 +1,92 @@
+package flatbuffers
+
+// Streamed vectors are built from chunks of unknown total length, such as the
+// reads of an io.Reader, without buffering them first.
+//
+// Builder 从后向前写入，而 chunk 是按顺序到达的：每个 chunk 逆序写入，结束时整体再逆序一次，
+// 即可得到正确的顺序；长度在结束时才知道，所以对齐所需的 padding 也在结束时通过移动数据补上。
+
+// BeginStreamVector starts a vector of elements of elemSize bytes, aligned to
+// alignment bytes, whose length is not known upfront. Append the encoded
+// elements with AppendToStreamVector and finish with EndStreamVector.
+func (b *Builder) BeginStreamVector(elemSize, alignment int) {
+	b.assertNotNested()
+	if elemSize <= 0 || alignment <= 0 || alignment&(alignment-1) != 0 {
+		panic("flatbuffers: invalid streamed vector element size or alignment")
+	}
+	b.nested = true
+
+	if alignment < SizeUOffsetT {
+		alignment = SizeUOffsetT
+	}
+	b.streamElemSize = elemSize
+	b.streamAlign = alignment
+	b.streamEnd = b.Offset()
+}
+
+// AppendToStreamVector appends chunk, holding little-endian encoded elements,
+// to the vector started by BeginStreamVector. Elements may span chunks.
+func (b *Builder) AppendToStreamVector(chunk []byte) {
+	b.assertStreaming()
+	b.Prep(1, len(chunk))
+
+	// 逆序写入 chunk
+	for i, c := range chunk {
+		b.Bytes[int(b.head)-1-i] = c
+	}
+	b.head -= UOffsetT(len(chunk))
+}
+
+// EndStreamVector writes the length of the vector started by
+// BeginStreamVector, aligning it, and returns its offset like EndVector.
+func (b *Builder) EndStreamVector() UOffsetT {
+	b.assertStreaming()
+	n := int(b.Offset() - b.streamEnd)
+	if n%b.streamElemSize != 0 {
+		panic("flatbuffers: streamed vector holds a partial element")
+	}
+
+	data := b.Bytes[b.head : int(b.head)+n]
+	for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+		data[i], data[j] = data[j], data[i]
+	}
+
+	// 数据起始位置对齐到 streamAlign ：把数据向前移动 pad 字节，并在其后补 0
+	pad := (b.streamAlign - int(b.Offset())%b.streamAlign) % b.streamAlign
+	b.Prep(1, pad+SizeUOffsetT) // 为 padding 和长度预留空间
+	if pad > 0 {
+		head := int(b.head)
+		copy(b.Bytes[head-pad:], b.Bytes[head:head+n])
+		zero(b.Bytes[head-pad+n : head+n])
+		b.head -= UOffsetT(pad)
+	}
+	if b.streamAlign > b.minalign {
+		b.minalign = b.streamAlign
+	}
+
+	numElems := n / b.streamElemSize
+	b.streamElemSize = 0
+	return b.EndVector(numElems)
+}
+
+// BeginByteVector starts a byte vector whose length is not known upfront.
+func (b *Builder) BeginByteVector() {
+	b.BeginStreamVector(SizeByte, SizeByte)
+}
+
+// AppendToByteVector appends chunk to the vector started by BeginByteVector.
+func (b *Builder) AppendToByteVector(chunk []byte) {
+	b.AppendToStreamVector(chunk)
+}
+
+// EndByteVector finishes the vector started by BeginByteVector and returns its
+// offset, like CreateByteVector.
+func (b *Builder) EndByteVector() UOffsetT {
+	return b.EndStreamVector()
+}
+
+func (b *Builder) assertStreaming() {
+	if b.streamElemSize == 0 {
+		panic("Incorrect creation order: must be inside a streamed vector.")
+	}
+}
@@ -0,0 +1,126 @@
+package flatbuffers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// finishVector finishes b with a table holding the vector at off.
+func finishVector(b *Builder, off UOffsetT) []byte {
+	b.StartObject(1)
+	b.PrependUOffsetTSlot(0, off, 0)
+	b.Finish(b.EndObject())
+	return b.FinishedBytes()
+}
+
+func TestStreamByteVector(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 20)[:317]
+	for _, prefix := range []int{0, 1, 2, 3} {
+		for _, size := range []int{1, 7, 64, len(data)} {
+			// Start from an empty buffer so that it grows mid-stream.
+			want, got := NewBuilder(0), NewBuilder(0)
+			for i := 0; i < prefix; i++ {
+				want.PrependByte(0xff)
+				got.PrependByte(0xff)
+			}
+			wantOff := want.CreateByteVector(data)
+
+			got.BeginByteVector()
+			for _, chunk := range chunks(data, size) {
+				got.AppendToByteVector(chunk)
+			}
+			gotOff := got.EndByteVector()
+
+			if gotOff != wantOff {
+				t.Fatalf("prefix %d, chunks of %d: got offset %d, want=%d", prefix, size, gotOff, wantOff)
+			}
+			if !bytes.Equal(finishVector(got, gotOff), finishVector(want, wantOff)) {
+				t.Fatalf("prefix %d, chunks of %d: streamed vector differs from CreateByteVector", prefix, size)
+			}
+		}
+	}
+}
+
+func TestStreamInt64Vector(t *testing.T) {
+	values := make([]int64, 50)
+	encoded := make([]byte, 8*len(values))
+	for i := range values {
+		values[i] = int64(i*i) - 1000
+		binary.LittleEndian.PutUint64(encoded[8*i:], uint64(values[i]))
+	}
+
+	for _, prefix := range []int{0, 1, 4, 5} {
+		// Chunks of 5 and 12 bytes split elements between chunks.
+		for _, size := range []int{5, 8, 12, len(encoded)} {
+			want, got := NewBuilder(0), NewBuilder(16)
+			for i := 0; i < prefix; i++ {
+				want.PrependByte(0xff)
+				got.PrependByte(0xff)
+			}
+			want.StartVector(SizeInt64, len(values), SizeInt64)
+			for i := len(values) - 1; i >= 0; i-- {
+				want.PrependInt64(values[i])
+			}
+			wantOff := want.EndVector(len(values))
+
+			got.BeginStreamVector(SizeInt64, SizeInt64)
+			for _, chunk := range chunks(encoded, size) {
+				got.AppendToStreamVector(chunk)
+			}
+			gotOff := got.EndStreamVector()
+
+			if gotOff != wantOff {
+				t.Fatalf("prefix %d, chunks of %d: got offset %d, want=%d", prefix, size, gotOff, wantOff)
+			}
+			if got.minalign != want.minalign {
+				t.Fatalf("prefix %d, chunks of %d: got minalign %d, want=%d", prefix, size, got.minalign, want.minalign)
+			}
+			gotBuf, wantBuf := finishVector(got, gotOff), finishVector(want, wantOff)
+			if !bytes.Equal(gotBuf, wantBuf) {
+				t.Fatalf("prefix %d, chunks of %d: streamed vector differs from StartVector", prefix, size)
+			}
+
+			tab := Table{Bytes: gotBuf, Pos: GetUOffsetT(gotBuf)}
+			start := tab.Vector(UOffsetT(tab.Offset(slot(0))))
+			if start%SizeInt64 != 0 {
+				t.Fatalf("prefix %d, chunks of %d: elements at %d are not aligned", prefix, size, start)
+			}
+			for i, v := range values {
+				if got := tab.GetInt64(start + UOffsetT(8*i)); got != v {
+					t.Fatalf("prefix %d, chunks of %d: element %d: got %d, want=%d", prefix, size, i, got, v)
+				}
+			}
+		}
+	}
+}
+
+func TestStreamVectorPanics(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fn   func(b *Builder)
+	}{
+		{"partial element", func(b *Builder) {
+			b.BeginStreamVector(SizeInt64, SizeInt64)
+			b.AppendToStreamVector(make([]byte, 12))
+			b.EndStreamVector()
+		}},
+		{"zero element size", func(b *Builder) { b.BeginStreamVector(0, 4) }},
+		{"alignment not a power of two", func(b *Builder) { b.BeginStreamVector(4, 6) }},
+		{"append outside of a vector", func(b *Builder) { b.AppendToStreamVector([]byte{1}) }},
+		{"end outside of a vector", func(b *Builder) { b.EndStreamVector() }},
+		{"nested in an object", func(b *Builder) {
+			b.StartObject(1)
+			b.BeginByteVector()
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected a panic")
+				}
+			}()
+			tc.fn(NewBuilder(0))
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package flatbuffers
+
+import (
+	"bytes"
+	"sync"
+)
+
+// VTableCache interns vtable layouts for the Builders sharing it, so that
+// services building many tables of the same shapes find the vtable of an
+// object with a map lookup instead of scanning every vtable of the buffer.
+//
+// A layout is the encoded field offsets of a vtable, which fingerprint the
+// shape of a table: like the search of WriteVtable without a cache, the object
+// size is left out, so a Builder writes the same bytes with or without one. The cache hands out a dense id per
+// layout, and every Builder remembers where it wrote the vtable of each id.
+// Buffers stay self-contained: each still holds one copy of every vtable it
+// uses. A VTableCache is safe for concurrent use by multiple goroutines.
+//
+// VTableCache 只在多个 Builder 之间共享 vtable 的布局，不共享 buffer 中的字节。
+type VTableCache struct {
+	mu  sync.RWMutex
+	ids map[string]int
+}
+
+// NewVTableCache returns an empty VTableCache.
+func NewVTableCache() *VTableCache {
+	return &VTableCache{ids: make(map[string]int)}
+}
+
+// Len returns the number of layouts in the cache.
+func (c *VTableCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.ids)
+}
+
+// id returns the id of layout, adding it to the cache if needed.
+func (c *VTableCache) id(layout []byte) int {
+	c.mu.RLock()
+	id, ok := c.ids[string(layout)]
+	c.mu.RUnlock()
+	if ok {
+		return id
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if id, ok := c.ids[string(layout)]; ok {
+		return id
+	}
+	id = len(c.ids)
+	c.ids[string(layout)] = id
+	return id
+}
+
+// SetVTableCache makes the Builder find and record its vtables in c, or in
+// its own buffer only if c is nil. Set it before building or after a Reset.
+func (b *Builder) SetVTableCache(c *VTableCache) {
+	b.vtableCache = c
+	b.cachedVtables = b.cachedVtables[:0]
+}
+
+// vtableLayout encodes the field offsets of the vtable of the object at
+// objectOffset as they are written to the buffer, reusing the scratch space of
+// the Builder.
+func (b *Builder) vtableLayout(objectOffset UOffsetT) []byte {
+	n := len(b.vtable) * SizeVOffsetT
+	if cap(b.layout) < n {
+		b.layout = make([]byte, n)
+	}
+	layout := b.layout[:n]
+
+	for i, field := range b.vtable {
+		var off UOffsetT
+		if field != 0 {
+			off = objectOffset - field
+		}
+		WriteVOffsetT(layout[i*SizeVOffsetT:], VOffsetT(off))
+	}
+	return layout
+}
+
+// findCachedVtable returns the id of the layout of the object at objectOffset
+// in the cache of the Builder, and the offset of the vtable with that layout
+// in the buffer, or 0 if there is none yet.
+func (b *Builder) findCachedVtable(objectOffset UOffsetT) (int, UOffsetT) {
+	id := b.vtableCache.id(b.vtableLayout(objectOffset))
+	if id < len(b.cachedVtables) {
+		return id, b.cachedVtables[id]
+	}
+	return id, 0
+}
+
+// recordCachedVtable records that the vtable of layout id was written at offset.
+func (b *Builder) recordCachedVtable(id int, offset UOffsetT) {
+	for len(b.cachedVtables) <= id {
+		b.cachedVtables = append(b.cachedVtables, 0)
+	}
+	b.cachedVtables[id] = offset
+}
+
+// SetVtableHashing sets whether the Builder finds duplicate vtables with a hash
+// index of the vtables it wrote instead of scanning them backwards. Scanning
+// costs a comparison per distinct vtable of the buffer, which dominates the
+// build time of buffers holding thousands of tables of different shapes; the
+// index costs a map entry per distinct vtable, so it is off by default. A
+// VTableCache, if set, takes precedence. Set it before building or after a Reset.
+func (b *Builder) SetVtableHashing(enabled bool) {
+	if !enabled {
+		b.vtableIndex = nil
+		return
+	}
+	b.vtableIndex = make(map[uint64][]UOffsetT)
+}
+
+// findIndexedVtable returns the hash of the layout of the object at
+// objectOffset and the offset of a vtable with that layout in the buffer, or 0
+// if there is none.
+func (b *Builder) findIndexedVtable(objectOffset UOffsetT) (uint64, UOffsetT) {
+	layout := b.vtableLayout(objectOffset)
+	hash := vtableHash(layout)
+	for _, vtOffset := range b.vtableIndex[hash] {
+		vtStart := len(b.Bytes) - int(vtOffset)
+		vtLen := int(GetVOffsetT(b.Bytes[vtStart:]))
+		fields := b.Bytes[vtStart+VtableMetadataFields*SizeVOffsetT : vtStart+vtLen]
+		if bytes.Equal(layout, fields) {
+			return hash, vtOffset
+		}
+	}
+	return hash, 0
+}
+
+// vtableHash hashes the layouts indexed by SetVtableHashing. It is a variable
+// so that tests can force collisions.
+var vtableHash = fnv1a
+
+// fnv1a returns the 64-bit FNV-1a hash of p.
+//
+// 内联计算以避免 hash.Hash64 的分配
+func fnv1a(p []byte) uint64 {
+	hash := uint64(14695981039346656037)
+	for _, c := range p {
+		hash ^= uint64(c)
+		hash *= 1099511628211
+	}
+	return hash
+}
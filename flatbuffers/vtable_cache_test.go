@@ -0,0 +1,142 @@
+package flatbuffers
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+// linearShapes returns the bytes buildShapes writes with the linear scan of
+// the vtables, and the number of distinct vtable layouts among them: the
+// layout of a shape depends on the padding of its fields, so a shape may have
+// several.
+func linearShapes(n, shapes int) ([]byte, int) {
+	b := NewBuilder(0)
+	buildShapes(b, n, shapes)
+	return b.FinishedBytes(), len(b.vtables)
+}
+
+func TestVTableCacheShared(t *testing.T) {
+	const n, shapes = 2000, 300
+	want, layouts := linearShapes(n, shapes)
+
+	cache := NewVTableCache()
+	first := NewBuilder(0)
+	first.SetVTableCache(cache)
+	buildShapes(first, n, shapes)
+	if !bytes.Equal(first.FinishedBytes(), want) {
+		t.Fatal("cached deduplication wrote different bytes than the linear scan")
+	}
+	if got := cache.Len(); got != layouts {
+		t.Fatalf("got %d layouts in the cache, want=%d", got, layouts)
+	}
+
+	// A second Builder finds every layout in the cache, yet writes its own
+	// copy of each vtable.
+	second := NewBuilder(0)
+	second.SetVTableCache(cache)
+	buildShapes(second, n, shapes)
+	if !bytes.Equal(second.FinishedBytes(), want) {
+		t.Fatal("cache hits wrote different bytes than the linear scan")
+	}
+	if got := cache.Len(); got != layouts {
+		t.Fatalf("got %d layouts in the cache after hits, want=%d", got, layouts)
+	}
+
+	// Layouts known to the cache but not yet written by a Builder, in a
+	// different order than they were added, are written on first use.
+	third := NewBuilder(0)
+	third.SetVTableCache(cache)
+	buildShapes(third, 500, 37)
+	if want, _ := linearShapes(500, 37); !bytes.Equal(third.FinishedBytes(), want) {
+		t.Fatal("a subset of the cached layouts wrote different bytes than the linear scan")
+	}
+
+	// The cache takes precedence over the hash index.
+	hashed := NewBuilder(0)
+	hashed.SetVtableHashing(true)
+	hashed.SetVTableCache(cache)
+	buildShapes(hashed, n, shapes)
+	if !bytes.Equal(hashed.FinishedBytes(), want) {
+		t.Fatal("cache and hashing wrote different bytes than the linear scan")
+	}
+}
+
+func TestVTableCacheReset(t *testing.T) {
+	const n, shapes = 1000, 100
+	want, layouts := linearShapes(n, shapes)
+
+	cache := NewVTableCache()
+	b := NewBuilder(0)
+	b.SetVTableCache(cache)
+	buildShapes(b, n, shapes)
+
+	// After a Reset the Builder holds no offsets of the vtables of the
+	// previous buffer, while the cache keeps its layouts.
+	b.Reset()
+	if len(b.cachedVtables) != 0 {
+		t.Fatalf("got %d cached vtables after Reset, want none", len(b.cachedVtables))
+	}
+	buildShapes(b, n, shapes)
+	if !bytes.Equal(b.FinishedBytes(), want) {
+		t.Fatal("cached deduplication after Reset wrote different bytes than the linear scan")
+	}
+	if got := cache.Len(); got != layouts {
+		t.Fatalf("got %d layouts in the cache, want=%d", got, layouts)
+	}
+
+	// Set to a fresh cache after a Reset, ids of the old one are forgotten.
+	b.Reset()
+	fresh := NewVTableCache()
+	b.SetVTableCache(fresh)
+	buildShapes(b, 300, 7)
+	freshWant, freshLayouts := linearShapes(300, 7)
+	if !bytes.Equal(b.FinishedBytes(), freshWant) {
+		t.Fatal("a fresh cache wrote different bytes than the linear scan")
+	}
+	if got := fresh.Len(); got != freshLayouts {
+		t.Fatalf("got %d layouts in the fresh cache, want=%d", got, freshLayouts)
+	}
+
+	// Unset after a Reset, the Builder scans its vtables again.
+	b.Reset()
+	b.SetVTableCache(nil)
+	buildShapes(b, n, shapes)
+	if !bytes.Equal(b.FinishedBytes(), want) {
+		t.Fatal("unsetting the cache wrote different bytes than the linear scan")
+	}
+}
+
+func TestVTableCacheConcurrent(t *testing.T) {
+	const n, shapes, builders = 1000, 200, 8
+	want, layouts := linearShapes(n, shapes)
+
+	cache := NewVTableCache()
+	var wg sync.WaitGroup
+	got := make([][]byte, builders)
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			b := NewBuilder(0)
+			b.SetVTableCache(cache)
+			// Each Builder builds the buffer twice, the second time hitting
+			// layouts added concurrently by the others.
+			for j := 0; j < 2; j++ {
+				b.Reset()
+				buildShapes(b, n, shapes)
+			}
+			got[i] = b.FinishedBytes()
+		}(i)
+	}
+	wg.Wait()
+
+	for i, buf := range got {
+		if !bytes.Equal(buf, want) {
+			t.Fatalf("builder %d wrote different bytes than the linear scan", i)
+		}
+	}
+	if got := cache.Len(); got != layouts {
+		t.Fatalf("got %d layouts in the cache, want=%d", got, layouts)
+	}
+}
@@ -16,4 +16,9 @@ module github.com/gomem/gomem
 
 go 1.14
 
-require github.com/apache/arrow/go/arrow v0.0.0-20200711183337-7b49cbc23f22
+require (
+	github.com/google/flatbuffers v1.11.0
+	github.com/klauspost/compress v1.15.9
+	github.com/pierrec/lz4/v4 v4.1.15
+	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543
+)
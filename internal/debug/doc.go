@@ -22,5 +22,10 @@ the binary.
 
 To enable runtime assertions, build with the assert tag. When the assert tag is omitted,
 the code for the assertions will be ommitted from the binary.
+
+To audit reference counts, build with the refaudit tag. Every reference taken
+on a tracked object records the stack of the caller, so that the references
+still held can be reported along with the code that took them, and releasing
+an object too many times panics with the stack of the release that freed it.
 */
 package debug
@@ -0,0 +1,29 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !refaudit
+
+package debug
+
+// RefAudit is true when references are tracked.
+const RefAudit = false
+
+// TrackRetain records the stack of the caller as holding a reference to obj.
+func TrackRetain(obj interface{}, kind string) {}
+
+// TrackRelease drops the most recent reference recorded for obj.
+func TrackRelease(obj interface{}) {}
+
+// Leaks returns the objects still referenced.
+func Leaks() string { return "" }
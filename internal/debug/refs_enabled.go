@@ -0,0 +1,119 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build refaudit
+
+package debug
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RefAudit is true when references are tracked.
+const RefAudit = true
+
+// refRecord holds the references taken on an object.
+type refRecord struct {
+	kind     string
+	holders  []string // stacks of the outstanding references, oldest first
+	released string   // stack of the release that dropped the last reference
+}
+
+var refs = struct {
+	sync.Mutex
+	records map[interface{}]*refRecord
+	order   []interface{}
+}{records: make(map[interface{}]*refRecord)}
+
+// TrackRetain records the stack of the caller as holding a reference to obj.
+// Objects are identified by pointer, kind describes them in reports.
+func TrackRetain(obj interface{}, kind string) {
+	stack := callers()
+	refs.Lock()
+	defer refs.Unlock()
+	rec, ok := refs.records[obj]
+	if !ok {
+		rec = &refRecord{}
+		refs.records[obj] = rec
+		refs.order = append(refs.order, obj)
+	}
+	rec.kind = kind
+	rec.released = ""
+	rec.holders = append(rec.holders, stack)
+}
+
+// TrackRelease drops the most recent reference recorded for obj.
+// It panics if obj holds no reference, reporting the release that dropped the last one.
+func TrackRelease(obj interface{}) {
+	stack := callers()
+	refs.Lock()
+	defer refs.Unlock()
+	rec, ok := refs.records[obj]
+	switch {
+	case !ok:
+		panic(fmt.Sprintf("debug: release of an untracked object %p\n%s", obj, stack))
+	case len(rec.holders) == 0:
+		panic(fmt.Sprintf("debug: too many releases of %s %p\n%s\nlast reference released at:\n%s",
+			rec.kind, obj, stack, rec.released))
+	}
+	rec.holders = rec.holders[:len(rec.holders)-1]
+	if len(rec.holders) == 0 {
+		rec.released = stack
+	}
+}
+
+// Leaks returns the objects still referenced, with the stack of each call
+// holding a reference, or an empty string when every reference was released.
+func Leaks() string {
+	refs.Lock()
+	defer refs.Unlock()
+
+	var live []interface{}
+	for _, obj := range refs.order {
+		if len(refs.records[obj].holders) > 0 {
+			live = append(live, obj)
+		}
+	}
+	sort.SliceStable(live, func(i, j int) bool { return refs.records[live[i]].kind < refs.records[live[j]].kind })
+
+	var b strings.Builder
+	for _, obj := range live {
+		rec := refs.records[obj]
+		fmt.Fprintf(&b, "%s %p has %d reference(s):\n", rec.kind, obj, len(rec.holders))
+		for _, stack := range rec.holders {
+			b.WriteString(stack)
+		}
+	}
+	return b.String()
+}
+
+// callers returns the stack of the caller of the tracking function.
+func callers() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "\t%s\n\t\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
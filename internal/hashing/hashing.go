@@ -0,0 +1,191 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hashing
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+)
+
+const (
+	// NullHash is the hash given to null values.
+	NullHash uint64 = 0x9ae16a3b2f90404f
+
+	fnvOffset64 uint64 = 14695981039346656037
+	fnvPrime64  uint64 = 1099511628211
+)
+
+// Uint64 mixes v into a well distributed 64-bit hash (splitmix64 finalizer).
+func Uint64(v uint64) uint64 {
+	v ^= v >> 30
+	v *= 0xbf58476d1ce4e5b9
+	v ^= v >> 27
+	v *= 0x94d049bb133111eb
+	v ^= v >> 31
+	return v
+}
+
+// Bytes hashes b using FNV-1a followed by a final mix.
+func Bytes(b []byte) uint64 {
+	h := fnvOffset64
+	for _, c := range b {
+		h ^= uint64(c)
+		h *= fnvPrime64
+	}
+	return Uint64(h)
+}
+
+// String hashes s the same way Bytes hashes []byte(s).
+func String(s string) uint64 {
+	h := fnvOffset64
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= fnvPrime64
+	}
+	return Uint64(h)
+}
+
+// Float64 hashes v so that 0 and -0 as well as all NaNs hash the same.
+func Float64(v float64) uint64 {
+	switch {
+	case v == 0:
+		return Uint64(0)
+	case math.IsNaN(v):
+		return Uint64(0x7ff8000000000001)
+	}
+	return Uint64(math.Float64bits(v))
+}
+
+// Combine folds the hash h into seed. It is used to hash multiple columns of a row.
+func Combine(seed, h uint64) uint64 {
+	return Uint64(seed ^ (h + 0x9e3779b97f4a7c15 + (seed << 6) + (seed >> 2)))
+}
+
+// Array calls fn with the hash of every value in arr.
+// Null values are passed NullHash and valid set to false.
+func Array(arr array.Interface, fn func(i int, h uint64, valid bool)) error {
+	n := arr.Len()
+	hashAt, err := hasherFor(arr)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if arr.IsNull(i) {
+			fn(i, NullHash, false)
+			continue
+		}
+		fn(i, hashAt(i), true)
+	}
+	return nil
+}
+
+// Hashes returns the hash of every value in arr. Null values hash to NullHash.
+func Hashes(arr array.Interface) ([]uint64, error) {
+	hashes := make([]uint64, arr.Len())
+	err := Array(arr, func(i int, h uint64, _ bool) {
+		hashes[i] = h
+	})
+	return hashes, err
+}
+
+// hasherFor returns a function that hashes the i-th value of arr.
+// The function does not check validity.
+func hasherFor(arr array.Interface) (func(i int) uint64, error) {
+	switch a := arr.(type) {
+	case *array.Boolean:
+		return func(i int) uint64 {
+			if a.Value(i) {
+				return Uint64(1)
+			}
+			return Uint64(0)
+		}, nil
+	case *array.Int8:
+		return func(i int) uint64 { return Uint64(uint64(a.Value(i))) }, nil
+	case *array.Int16:
+		return func(i int) uint64 { return Uint64(uint64(a.Value(i))) }, nil
+	case *array.Int32:
+		return func(i int) uint64 { return Uint64(uint64(a.Value(i))) }, nil
+	case *array.Int64:
+		return func(i int) uint64 { return Uint64(uint64(a.Value(i))) }, nil
+	case *array.Uint8:
+		return func(i int) uint64 { return Uint64(uint64(a.Value(i))) }, nil
+	case *array.Uint16:
+		return func(i int) uint64 { return Uint64(uint64(a.Value(i))) }, nil
+	case *array.Uint32:
+		return func(i int) uint64 { return Uint64(uint64(a.Value(i))) }, nil
+	case *array.Uint64:
+		return func(i int) uint64 { return Uint64(a.Value(i)) }, nil
+	case *array.Float16:
+		return func(i int) uint64 { return Float64(float64(a.Value(i).Float32())) }, nil
+	case *array.Float32:
+		return func(i int) uint64 { return Float64(float64(a.Value(i))) }, nil
+	case *array.Float64:
+		return func(i int) uint64 { return Float64(a.Value(i)) }, nil
+	case *array.Date32:
+		return func(i int) uint64 { return Uint64(uint64(a.Value(i))) }, nil
+	case *array.Date64:
+		return func(i int) uint64 { return Uint64(uint64(a.Value(i))) }, nil
+	case *array.Time32:
+		return func(i int) uint64 { return Uint64(uint64(a.Value(i))) }, nil
+	case *array.Time64:
+		return func(i int) uint64 { return Uint64(uint64(a.Value(i))) }, nil
+	case *array.Timestamp:
+		return func(i int) uint64 { return Uint64(uint64(a.Value(i))) }, nil
+	case *array.Duration:
+		return func(i int) uint64 { return Uint64(uint64(a.Value(i))) }, nil
+	case *array.MonthInterval:
+		return func(i int) uint64 { return Uint64(uint64(a.Value(i))) }, nil
+	case *array.DayTimeInterval:
+		return func(i int) uint64 {
+			v := a.Value(i)
+			return Combine(Uint64(uint64(v.Days)), Uint64(uint64(v.Milliseconds)))
+		}, nil
+	case *array.MonthDayNanoInterval:
+		return func(i int) uint64 {
+			v := a.Value(i)
+			h := Combine(Uint64(uint64(v.Months)), Uint64(uint64(v.Days)))
+			return Combine(h, Uint64(uint64(v.Nanoseconds)))
+		}, nil
+	case *array.Decimal128:
+		return func(i int) uint64 {
+			v := a.Value(i)
+			return Combine(Uint64(uint64(v.HighBits())), Uint64(v.LowBits()))
+		}, nil
+	case *array.String:
+		return func(i int) uint64 { return String(a.Value(i)) }, nil
+	case *array.Binary:
+		return func(i int) uint64 { return Bytes(a.Value(i)) }, nil
+	case *array.FixedSizeBinary:
+		return func(i int) uint64 { return Bytes(a.Value(i)) }, nil
+	case *array.Dictionary:
+		// Hash each dictionary value once and look the hashes up by index.
+		dict, err := Hashes(a.Dictionary())
+		if err != nil {
+			return nil, err
+		}
+		return func(i int) uint64 { return dict[a.GetValueIndex(i)] }, nil
+	case *array.RunEndEncoded:
+		// Hash each run value once and look the hashes up by run.
+		values, err := Hashes(a.Values())
+		if err != nil {
+			return nil, err
+		}
+		return func(i int) uint64 { return values[a.GetPhysicalIndex(i)] }, nil
+	default:
+		return nil, fmt.Errorf("hashing: unsupported array type %T", arr)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hashing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// The golden values below were computed independently of this package. They
+// pin the hashes, which must not change between releases: sketches built by
+// one process are merged with those of others.
+
+func TestUint64Golden(t *testing.T) {
+	for _, tc := range []struct {
+		v, want uint64
+	}{
+		{0, 0},
+		{1, 0x5692161d100b05e5},
+		{2, 0xdbd238973a2b148a},
+		// the first output of the splitmix64 generator seeded with 0.
+		{0x9e3779b97f4a7c15, 0xe220a8397b1dcdaf},
+		{math.MaxUint64, 0xb4d055fcf2cbbd7b},
+	} {
+		if got := Uint64(tc.v); got != tc.want {
+			t.Errorf("Uint64(%#x)=%#x, want=%#x", tc.v, got, tc.want)
+		}
+	}
+}
+
+func TestBytesGolden(t *testing.T) {
+	for _, tc := range []struct {
+		v    string
+		want uint64
+	}{
+		// the mixes of the FNV-1a offset basis and of 0xaf63dc4c8601ec8c,
+		// the FNV-1a hash of "a".
+		{"", 0xf52a15e9a9b5e89b},
+		{"a", 0x02c0bdbf481420f8},
+		{"foobar", 0x404da9e3b74078c2},
+		{"\x00\xff", 0xa70bee94c6c8447b},
+	} {
+		if got := Bytes([]byte(tc.v)); got != tc.want {
+			t.Errorf("Bytes(%q)=%#x, want=%#x", tc.v, got, tc.want)
+		}
+		if got := String(tc.v); got != tc.want {
+			t.Errorf("String(%q)=%#x, want=%#x", tc.v, got, tc.want)
+		}
+	}
+}
+
+func TestFloat64Golden(t *testing.T) {
+	for _, tc := range []struct {
+		v    float64
+		want uint64
+	}{
+		{0, 0},
+		{math.Copysign(0, -1), 0},
+		{1.5, 0xe72b41d4576e3468},
+		{-2.25, 0xf410198f3476314a},
+		{math.Inf(1), 0xce5683aaaedc68d0},
+		{math.NaN(), 0x4dbf121bf93450d1},
+		{math.Float64frombits(0xfff0000000000123), 0x4dbf121bf93450d1},
+	} {
+		if got := Float64(tc.v); got != tc.want {
+			t.Errorf("Float64(%v)=%#x, want=%#x", tc.v, got, tc.want)
+		}
+	}
+}
+
+func TestCombineGolden(t *testing.T) {
+	for _, tc := range []struct {
+		seed, h, want uint64
+	}{
+		{0, 0, 0xe220a8397b1dcdaf},
+		{1, 2, 0x3706970b052f16b1},
+		{NullHash, 0x5692161d100b05e5, 0x8b9ca0a046345a64},
+	} {
+		if got := Combine(tc.seed, tc.h); got != tc.want {
+			t.Errorf("Combine(%#x, %#x)=%#x, want=%#x", tc.seed, tc.h, got, tc.want)
+		}
+	}
+}
+
+func TestHashesGolden(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	ib := array.NewInt64Builder(mem)
+	defer ib.Release()
+	ib.AppendValues([]int64{1, 0, -1}, []bool{true, false, true})
+	ints := ib.NewArray()
+	defer ints.Release()
+
+	fb := array.NewFloat32Builder(mem)
+	defer fb.Release()
+	fb.AppendValues([]float32{1.5, -2.25, float32(math.Copysign(0, -1))}, nil)
+	floats := fb.NewArray()
+	defer floats.Release()
+
+	sb := array.NewStringBuilder(mem)
+	defer sb.Release()
+	sb.AppendValues([]string{"a", "", "foobar"}, nil)
+	strs := sb.NewArray()
+	defer strs.Release()
+
+	xb := array.NewInt8Builder(mem)
+	defer xb.Release()
+	xb.AppendValues([]int8{2, 0, 2, 1}, []bool{true, true, false, true})
+	indices := xb.NewArray()
+	defer indices.Release()
+	dict := array.NewDictionaryArray(&arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int8, ValueType: arrow.BinaryTypes.String}, indices, strs)
+	defer dict.Release()
+
+	for _, tc := range []struct {
+		name string
+		arr  array.Interface
+		want []uint64
+	}{
+		{"int64", ints, []uint64{0x5692161d100b05e5, NullHash, 0xb4d055fcf2cbbd7b}},
+		{"float32", floats, []uint64{0xe72b41d4576e3468, 0xf410198f3476314a, 0}},
+		{"string", strs, []uint64{0x02c0bdbf481420f8, 0xf52a15e9a9b5e89b, 0x404da9e3b74078c2}},
+		// dictionary values hash like the values they stand for.
+		{"dictionary", dict, []uint64{0x404da9e3b74078c2, 0x02c0bdbf481420f8, NullHash, 0xf52a15e9a9b5e89b}},
+	} {
+		got, err := Hashes(tc.arr)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if len(got) != len(tc.want) {
+			t.Fatalf("%s: got %d hashes, want=%d", tc.name, len(got), len(tc.want))
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s: hash %d=%#x, want=%#x", tc.name, i, got[i], tc.want[i])
+			}
+		}
+	}
+}
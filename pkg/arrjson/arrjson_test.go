@@ -0,0 +1,248 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrjson
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/decimal128"
+	"github.com/gomem/gomem/arrow/go/arrow/float16"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// newTestRecord returns a record with a column of every supported type.
+func newTestRecord(t *testing.T, mem memory.Allocator) array.Record {
+	t.Helper()
+
+	dictType := &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int8, ValueType: arrow.BinaryTypes.String}
+	reeType := &arrow.RunEndEncodedType{RunEndType: arrow.PrimitiveTypes.Int32, ValueType: arrow.PrimitiveTypes.Int64}
+	fields := []arrow.Field{
+		{Name: "null", Type: arrow.Null, Nullable: true},
+		{Name: "bool", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+		{Name: "int8", Type: arrow.PrimitiveTypes.Int8, Nullable: true},
+		{Name: "int64", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "uint16", Type: arrow.PrimitiveTypes.Uint16, Nullable: true},
+		{Name: "uint64", Type: arrow.PrimitiveTypes.Uint64, Nullable: true},
+		{Name: "float16", Type: arrow.FixedWidthTypes.Float16, Nullable: true},
+		{Name: "float64", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "binary", Type: arrow.BinaryTypes.Binary, Nullable: true},
+		{Name: "string", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "fixed", Type: &arrow.FixedSizeBinaryType{ByteWidth: 2}, Nullable: true},
+		{Name: "decimal", Type: &arrow.Decimal128Type{Precision: 38, Scale: 2}, Nullable: true},
+		{Name: "date32", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+		{Name: "time64", Type: arrow.FixedWidthTypes.Time64us, Nullable: true},
+		{Name: "timestamp", Type: &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "UTC"}, Nullable: true},
+		{Name: "duration", Type: &arrow.DurationType{Unit: arrow.Second}, Nullable: true},
+		{Name: "day_time", Type: arrow.FixedWidthTypes.DayTimeInterval, Nullable: true},
+		{Name: "month_day_nano", Type: arrow.FixedWidthTypes.MonthDayNanoInterval, Nullable: true},
+		{Name: "list", Type: arrow.ListOf(arrow.PrimitiveTypes.Int32), Nullable: true},
+		{Name: "fixed_list", Type: arrow.FixedSizeListOf(2, arrow.PrimitiveTypes.Int32), Nullable: true},
+		{Name: "struct", Type: arrow.StructOf(
+			arrow.Field{Name: "a", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+			arrow.Field{Name: "b", Type: arrow.BinaryTypes.String, Nullable: true},
+		), Nullable: true},
+		{Name: "dict", Type: dictType, Nullable: true},
+		{Name: "ree", Type: reeType, Nullable: true},
+	}
+	md := arrow.NewMetadata([]string{"k"}, []string{"v"})
+	schema := arrow.NewSchema(fields, &md)
+
+	// the dictionary column cannot be built by a RecordBuilder, it is built apart.
+	bldrFields := append([]arrow.Field{}, fields...)
+	bldrFields[21].Type = arrow.PrimitiveTypes.Int8
+	bldr := array.NewRecordBuilder(mem, arrow.NewSchema(bldrFields, nil))
+	defer bldr.Release()
+
+	valid := []bool{true, false, true}
+	bldr.Field(0).(*array.NullBuilder).AppendNull()
+	bldr.Field(0).(*array.NullBuilder).AppendNull()
+	bldr.Field(0).(*array.NullBuilder).AppendNull()
+	bldr.Field(1).(*array.BooleanBuilder).AppendValues([]bool{true, false, false}, valid)
+	bldr.Field(2).(*array.Int8Builder).AppendValues([]int8{-1, 0, 127}, valid)
+	bldr.Field(3).(*array.Int64Builder).AppendValues([]int64{-1 << 62, 0, 1<<63 - 1}, valid)
+	bldr.Field(4).(*array.Uint16Builder).AppendValues([]uint16{1, 0, 65535}, valid)
+	bldr.Field(5).(*array.Uint64Builder).AppendValues([]uint64{1, 0, 1<<64 - 1}, valid)
+	bldr.Field(6).(*array.Float16Builder).AppendValues([]float16.Num{float16.New(1.5), {}, float16.New(-2)}, valid)
+	bldr.Field(7).(*array.Float64Builder).AppendValues([]float64{1.25, 0, -1e300}, valid)
+	bldr.Field(8).(*array.BinaryBuilder).AppendValues([][]byte{{0xde, 0xad}, nil, {}}, valid)
+	bldr.Field(9).(*array.StringBuilder).AppendValues([]string{"héllo", "", "\"quoted\""}, valid)
+	bldr.Field(10).(*array.FixedSizeBinaryBuilder).AppendValues([][]byte{{1, 2}, {0, 0}, {0xff, 0}}, valid)
+	bldr.Field(11).(*array.Decimal128Builder).AppendValues([]decimal128.Num{decimal128.FromI64(-12345), {}, decimal128.New(1, 7)}, valid)
+	bldr.Field(12).(*array.Date32Builder).AppendValues([]arrow.Date32{18262, 0, -1}, valid)
+	bldr.Field(13).(*array.Time64Builder).AppendValues([]arrow.Time64{1, 0, 86399999999}, valid)
+	bldr.Field(14).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{1577880000123, 0, -1}, valid)
+	bldr.Field(15).(*array.DurationBuilder).AppendValues([]arrow.Duration{60, 0, -60}, valid)
+	bldr.Field(16).(*array.DayTimeIntervalBuilder).AppendValues([]arrow.DayTimeInterval{{Days: 1, Milliseconds: 2}, {}, {Days: -1}}, valid)
+	bldr.Field(17).(*array.MonthDayNanoIntervalBuilder).AppendValues(
+		[]arrow.MonthDayNanoInterval{{Months: 1, Days: 2, Nanoseconds: 1 << 40}, {}, {Months: -1}}, valid)
+
+	lb := bldr.Field(18).(*array.ListBuilder)
+	lb.Append(true)
+	lb.ValueBuilder().(*array.Int32Builder).AppendValues([]int32{1, 2}, nil)
+	lb.AppendNull()
+	lb.Append(true)
+
+	fl := bldr.Field(19).(*array.FixedSizeListBuilder)
+	fl.Append(true)
+	fl.ValueBuilder().(*array.Int32Builder).AppendValues([]int32{1, 2}, nil)
+	fl.AppendNull()
+	fl.ValueBuilder().(*array.Int32Builder).AppendValues([]int32{0, 0}, nil)
+	fl.Append(true)
+	fl.ValueBuilder().(*array.Int32Builder).AppendValues([]int32{3, 4}, []bool{false, true})
+
+	sb := bldr.Field(20).(*array.StructBuilder)
+	sb.AppendValues(valid)
+	sb.FieldBuilder(0).(*array.Int32Builder).AppendValues([]int32{1, 0, 3}, []bool{true, true, false})
+	sb.FieldBuilder(1).(*array.StringBuilder).AppendValues([]string{"a", "", "c"}, nil)
+
+	bldr.Field(21).(*array.Int8Builder).AppendValues([]int8{1, 0, 1}, valid)
+
+	rb := bldr.Field(22).(*array.RunEndEncodedBuilder)
+	rb.Append(2)
+	rb.ValueBuilder().(*array.Int64Builder).Append(7)
+	rb.AppendNull()
+
+	rec := bldr.NewRecord()
+	defer rec.Release()
+
+	values := array.NewStringBuilder(mem)
+	defer values.Release()
+	values.AppendValues([]string{"x", "y"}, nil)
+	dict := values.NewArray()
+	defer dict.Release()
+	dictArr := array.NewDictionaryArray(dictType, rec.Column(21), dict)
+	defer dictArr.Release()
+
+	cols := append([]array.Interface{}, rec.Columns()...)
+	cols[21] = dictArr
+	return array.NewRecord(schema, cols, 3)
+}
+
+func TestRoundTrip(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	rec := newTestRecord(t, pool)
+	defer rec.Release()
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, rec.Schema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(rec); err != nil {
+		t.Fatal(err)
+	}
+	slice := rec.NewSlice(1, 3)
+	defer slice.Release()
+	if err := w.Write(slice); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&buf, WithAllocator(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+
+	if !r.Schema().Equal(rec.Schema()) {
+		t.Fatalf("got schema=\n%v\nwant=\n%v", r.Schema(), rec.Schema())
+	}
+	if r.NumRecords() != 2 {
+		t.Fatalf("got %d records, want 2", r.NumRecords())
+	}
+	for i, want := range []array.Record{rec, slice} {
+		got, err := r.Read(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := range want.Columns() {
+			if !array.ArrayEqual(got.Column(j), want.Column(j)) {
+				t.Errorf("record %d column %q: got=%v, want=%v", i, want.ColumnName(j), got.Column(j), want.Column(j))
+			}
+		}
+		got.Release()
+	}
+}
+
+func TestReadIntegrationFile(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	const data = `{
+  "schema": {"fields": [
+    {"name": "ints", "nullable": true, "type": {"name": "int", "isSigned": true, "bitWidth": 32}, "children": []},
+    {"name": "lists", "nullable": true, "type": {"name": "list"}, "children": [
+      {"name": "item", "nullable": true, "type": {"name": "utf8"}, "children": []}
+    ]}
+  ]},
+  "batches": [{"count": 3, "columns": [
+    {"name": "ints", "count": 3, "VALIDITY": [1, 0, 1], "DATA": [1, 0, -3]},
+    {"name": "lists", "count": 3, "VALIDITY": [1, 1, 0], "OFFSET": [0, 2, 2, 2], "children": [
+      {"name": "item", "count": 2, "VALIDITY": [1, 1], "OFFSET": [0, 1, 3], "DATA": ["a", "bc"]}
+    ]}
+  ]}]
+}`
+	r, err := NewReader(strings.NewReader(data), WithAllocator(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+
+	rec, err := r.Read(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rec.Release()
+
+	for i, want := range []string{`[1 (null) -3]`, `[["a" "bc"] [] (null)]`} {
+		if got := rec.Column(i).(interface{ String() string }).String(); got != want {
+			t.Errorf("column %d: got=%s, want=%s", i, got, want)
+		}
+	}
+	if _, err := r.Read(1); err == nil {
+		t.Fatal("expected an error for a missing record")
+	}
+}
+
+func TestMarshalArray(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	rec := newTestRecord(t, pool)
+	defer rec.Release()
+
+	for i, col := range rec.Columns() {
+		data, err := MarshalArray(rec.ColumnName(i), col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := UnmarshalArray(pool, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !array.ArrayEqual(got, col) {
+			t.Errorf("column %q: got=%v, want=%v", rec.ColumnName(i), got, col)
+		}
+		got.Release()
+	}
+}
@@ -0,0 +1,523 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/decimal128"
+	"github.com/gomem/gomem/arrow/go/arrow/float16"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// columnDecoder turns JSON columns into arrays. The dictionaries must be
+// decoded before the columns referencing them.
+type columnDecoder struct {
+	mem   memory.Allocator
+	ids   *dictionaryIDs
+	dicts map[int64]array.Interface
+}
+
+func (d *columnDecoder) decode(f arrow.Field, col jsonColumn, path string) (array.Interface, error) {
+	n := col.Count
+	switch f.Type.ID() {
+	case arrow.NULL, arrow.LIST, arrow.FIXED_SIZE_LIST, arrow.STRUCT, arrow.RUN_END_ENCODED:
+	default:
+		if len(col.Data) != n {
+			return nil, fmt.Errorf("arrjson: column %q has %d values for a count of %d", col.Name, len(col.Data), n)
+		}
+	}
+	var valid []bool
+	if len(col.Validity) > 0 {
+		if len(col.Validity) != n {
+			return nil, fmt.Errorf("arrjson: column %q has %d validity bits for a count of %d", col.Name, len(col.Validity), n)
+		}
+		valid = make([]bool, n)
+		for i, v := range col.Validity {
+			valid[i] = v != 0
+		}
+	}
+
+	var err error
+	ints := func() []int64 {
+		vs := make([]int64, n)
+		for i := 0; i < n && err == nil; i++ {
+			if vs[i], err = asInt64(col.Data[i]); err != nil {
+				err = fmt.Errorf("arrjson: column %q: %w", col.Name, err)
+			}
+		}
+		return vs
+	}
+	uints := func() []uint64 {
+		vs := make([]uint64, n)
+		for i := 0; i < n && err == nil; i++ {
+			if vs[i], err = asUint64(col.Data[i]); err != nil {
+				err = fmt.Errorf("arrjson: column %q: %w", col.Name, err)
+			}
+		}
+		return vs
+	}
+	floats := func() []float64 {
+		vs := make([]float64, n)
+		for i := 0; i < n && err == nil; i++ {
+			if vs[i], err = asFloat64(col.Data[i]); err != nil {
+				err = fmt.Errorf("arrjson: column %q: %w", col.Name, err)
+			}
+		}
+		return vs
+	}
+
+	switch dtype := f.Type.(type) {
+	case *arrow.NullType:
+		return array.NewNull(n), nil
+
+	case *arrow.BooleanType:
+		vs := make([]bool, n)
+		for i := range vs {
+			b, ok := col.Data[i].(bool)
+			if !ok {
+				return nil, fmt.Errorf("arrjson: column %q: %v is not a boolean", col.Name, col.Data[i])
+			}
+			vs[i] = b
+		}
+		bldr := array.NewBooleanBuilder(d.mem)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), nil
+
+	case *arrow.Int8Type:
+		src := ints()
+		vs := make([]int8, n)
+		for i, v := range src {
+			vs[i] = int8(v)
+		}
+		bldr := array.NewInt8Builder(d.mem)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), err
+
+	case *arrow.Int16Type:
+		src := ints()
+		vs := make([]int16, n)
+		for i, v := range src {
+			vs[i] = int16(v)
+		}
+		bldr := array.NewInt16Builder(d.mem)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), err
+
+	case *arrow.Int32Type:
+		src := ints()
+		vs := make([]int32, n)
+		for i, v := range src {
+			vs[i] = int32(v)
+		}
+		bldr := array.NewInt32Builder(d.mem)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), err
+
+	case *arrow.Int64Type:
+		vs := ints()
+		bldr := array.NewInt64Builder(d.mem)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), err
+
+	case *arrow.Uint8Type:
+		src := uints()
+		vs := make([]uint8, n)
+		for i, v := range src {
+			vs[i] = uint8(v)
+		}
+		bldr := array.NewUint8Builder(d.mem)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), err
+
+	case *arrow.Uint16Type:
+		src := uints()
+		vs := make([]uint16, n)
+		for i, v := range src {
+			vs[i] = uint16(v)
+		}
+		bldr := array.NewUint16Builder(d.mem)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), err
+
+	case *arrow.Uint32Type:
+		src := uints()
+		vs := make([]uint32, n)
+		for i, v := range src {
+			vs[i] = uint32(v)
+		}
+		bldr := array.NewUint32Builder(d.mem)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), err
+
+	case *arrow.Uint64Type:
+		vs := uints()
+		bldr := array.NewUint64Builder(d.mem)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), err
+
+	case *arrow.Float16Type:
+		src := floats()
+		vs := make([]float16.Num, n)
+		for i, v := range src {
+			vs[i] = float16.New(float32(v))
+		}
+		bldr := array.NewFloat16Builder(d.mem)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), err
+
+	case *arrow.Float32Type:
+		src := floats()
+		vs := make([]float32, n)
+		for i, v := range src {
+			vs[i] = float32(v)
+		}
+		bldr := array.NewFloat32Builder(d.mem)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), err
+
+	case *arrow.Float64Type:
+		vs := floats()
+		bldr := array.NewFloat64Builder(d.mem)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), err
+
+	case *arrow.BinaryType:
+		vs := make([][]byte, n)
+		for i := range vs {
+			if vs[i], err = decodeHex(col.Data[i]); err != nil {
+				return nil, fmt.Errorf("arrjson: column %q: %w", col.Name, err)
+			}
+		}
+		bldr := array.NewBinaryBuilder(d.mem, dtype)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), nil
+
+	case *arrow.StringType:
+		vs := make([]string, n)
+		for i := range vs {
+			s, ok := col.Data[i].(string)
+			if !ok {
+				return nil, fmt.Errorf("arrjson: column %q: %v is not a string", col.Name, col.Data[i])
+			}
+			vs[i] = s
+		}
+		bldr := array.NewStringBuilder(d.mem)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), nil
+
+	case *arrow.FixedSizeBinaryType:
+		vs := make([][]byte, n)
+		for i := range vs {
+			if vs[i], err = decodeHex(col.Data[i]); err != nil {
+				return nil, fmt.Errorf("arrjson: column %q: %w", col.Name, err)
+			}
+			if len(vs[i]) != dtype.ByteWidth {
+				return nil, fmt.Errorf("arrjson: column %q: %d bytes for a width of %d", col.Name, len(vs[i]), dtype.ByteWidth)
+			}
+		}
+		bldr := array.NewFixedSizeBinaryBuilder(d.mem, dtype)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), nil
+
+	case *arrow.Decimal128Type:
+		vs := make([]decimal128.Num, n)
+		for i := range vs {
+			s, ok := col.Data[i].(string)
+			if !ok {
+				return nil, fmt.Errorf("arrjson: column %q: %v is not a decimal string", col.Name, col.Data[i])
+			}
+			if vs[i], err = decimalFromString(s); err != nil {
+				return nil, err
+			}
+		}
+		bldr := array.NewDecimal128Builder(d.mem, dtype)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), nil
+
+	case *arrow.Date32Type:
+		src := ints()
+		vs := make([]arrow.Date32, n)
+		for i, v := range src {
+			vs[i] = arrow.Date32(v)
+		}
+		bldr := array.NewDate32Builder(d.mem)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), err
+
+	case *arrow.Date64Type:
+		src := ints()
+		vs := make([]arrow.Date64, n)
+		for i, v := range src {
+			vs[i] = arrow.Date64(v)
+		}
+		bldr := array.NewDate64Builder(d.mem)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), err
+
+	case *arrow.Time32Type:
+		src := ints()
+		vs := make([]arrow.Time32, n)
+		for i, v := range src {
+			vs[i] = arrow.Time32(v)
+		}
+		bldr := array.NewTime32Builder(d.mem, dtype)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), err
+
+	case *arrow.Time64Type:
+		src := ints()
+		vs := make([]arrow.Time64, n)
+		for i, v := range src {
+			vs[i] = arrow.Time64(v)
+		}
+		bldr := array.NewTime64Builder(d.mem, dtype)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), err
+
+	case *arrow.TimestampType:
+		src := ints()
+		vs := make([]arrow.Timestamp, n)
+		for i, v := range src {
+			vs[i] = arrow.Timestamp(v)
+		}
+		bldr := array.NewTimestampBuilder(d.mem, dtype)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), err
+
+	case *arrow.DurationType:
+		src := ints()
+		vs := make([]arrow.Duration, n)
+		for i, v := range src {
+			vs[i] = arrow.Duration(v)
+		}
+		bldr := array.NewDurationBuilder(d.mem, dtype)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), err
+
+	case *arrow.MonthIntervalType:
+		src := ints()
+		vs := make([]arrow.MonthInterval, n)
+		for i, v := range src {
+			vs[i] = arrow.MonthInterval(v)
+		}
+		bldr := array.NewMonthIntervalBuilder(d.mem)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), err
+
+	case *arrow.DayTimeIntervalType:
+		vs := make([]arrow.DayTimeInterval, n)
+		for i := range vs {
+			m, err := asObject(col.Data[i], "days", "milliseconds")
+			if err != nil {
+				return nil, fmt.Errorf("arrjson: column %q: %w", col.Name, err)
+			}
+			vs[i] = arrow.DayTimeInterval{Days: int32(m["days"]), Milliseconds: int32(m["milliseconds"])}
+		}
+		bldr := array.NewDayTimeIntervalBuilder(d.mem)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), nil
+
+	case *arrow.MonthDayNanoIntervalType:
+		vs := make([]arrow.MonthDayNanoInterval, n)
+		for i := range vs {
+			m, err := asObject(col.Data[i], "months", "days", "nanoseconds")
+			if err != nil {
+				return nil, fmt.Errorf("arrjson: column %q: %w", col.Name, err)
+			}
+			vs[i] = arrow.MonthDayNanoInterval{Months: int32(m["months"]), Days: int32(m["days"]), Nanoseconds: m["nanoseconds"]}
+		}
+		bldr := array.NewMonthDayNanoIntervalBuilder(d.mem)
+		defer bldr.Release()
+		bldr.AppendValues(vs, valid)
+		return bldr.NewArray(), nil
+
+	case *arrow.ListType:
+		offsets := col.Offset
+		if n == 0 && len(offsets) == 0 {
+			offsets = []int32{0}
+		}
+		if len(offsets) != n+1 {
+			return nil, fmt.Errorf("arrjson: column %q has %d offsets for a count of %d", col.Name, len(offsets), n)
+		}
+		children, err := d.decodeChildren(dtype, col, path)
+		if err != nil {
+			return nil, err
+		}
+		defer releaseAll(children)
+		return d.newNested(dtype, n, valid, children, offsets), nil
+
+	case *arrow.FixedSizeListType, *arrow.StructType:
+		children, err := d.decodeChildren(dtype, col, path)
+		if err != nil {
+			return nil, err
+		}
+		defer releaseAll(children)
+		return d.newNested(dtype, n, valid, children, nil), nil
+
+	case *arrow.RunEndEncodedType:
+		children, err := d.decodeChildren(dtype, col, path)
+		if err != nil {
+			return nil, err
+		}
+		defer releaseAll(children)
+		return array.NewRunEndEncodedArray(children[0], children[1], n, 0), nil
+
+	case *arrow.DictionaryType:
+		dict, ok := d.dicts[d.ids.byPath[path]]
+		if !ok {
+			return nil, fmt.Errorf("arrjson: no dictionary for column %q", col.Name)
+		}
+		indices, err := d.decode(arrow.Field{Name: f.Name, Type: dtype.IndexType}, col, path)
+		if err != nil {
+			return nil, err
+		}
+		defer indices.Release()
+		return array.NewDictionaryArray(dtype, indices, dict), nil
+
+	default:
+		return nil, fmt.Errorf("arrjson: unsupported type %s", f.Type)
+	}
+}
+
+func (d *columnDecoder) decodeChildren(dtype arrow.DataType, col jsonColumn, path string) ([]array.Interface, error) {
+	_, fields, err := typeToJSON(dtype)
+	if err != nil {
+		return nil, err
+	}
+	if len(col.Children) != len(fields) {
+		return nil, fmt.Errorf("arrjson: column %q has %d children, want %d", col.Name, len(col.Children), len(fields))
+	}
+	children := make([]array.Interface, 0, len(fields))
+	for i, f := range fields {
+		child, err := d.decode(f, col.Children[i], childPath(path, i))
+		if err != nil {
+			releaseAll(children)
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	return children, nil
+}
+
+// newNested builds a list, fixed size list or struct array from its validity,
+// its children and, for lists, its offsets.
+func (d *columnDecoder) newNested(dtype arrow.DataType, n int, valid []bool, children []array.Interface, offsets []int32) array.Interface {
+	var (
+		bitmap *memory.Buffer
+		nulls  int
+	)
+	if valid != nil {
+		bitmap = memory.NewResizableBuffer(d.mem)
+		defer bitmap.Release()
+		bitmap.Resize(int(bitutil.BytesForBits(int64(n))))
+		for i, v := range valid {
+			bitutil.SetBitTo(bitmap.Bytes(), i, v)
+			if !v {
+				nulls++
+			}
+		}
+	}
+
+	buffers := []*memory.Buffer{bitmap}
+	if offsets != nil {
+		buf := memory.NewResizableBuffer(d.mem)
+		defer buf.Release()
+		buf.Resize(arrow.Int32Traits.BytesRequired(len(offsets)))
+		copy(arrow.Int32Traits.CastFromBytes(buf.Bytes()), offsets)
+		buffers = append(buffers, buf)
+	}
+
+	childData := make([]*array.Data, len(children))
+	for i, child := range children {
+		childData[i] = child.Data()
+	}
+
+	data := array.NewData(dtype, n, buffers, childData, nulls, 0)
+	defer data.Release()
+	return array.MakeFromData(data)
+}
+
+func releaseAll(arrs []array.Interface) {
+	for _, arr := range arrs {
+		arr.Release()
+	}
+}
+
+func asUint64(v interface{}) (uint64, error) {
+	switch v := v.(type) {
+	case json.Number:
+		return strconv.ParseUint(string(v), 10, 64)
+	case string:
+		return strconv.ParseUint(v, 10, 64)
+	}
+	return 0, fmt.Errorf("arrjson: %v is not an unsigned integer", v)
+}
+
+func asFloat64(v interface{}) (float64, error) {
+	switch v := v.(type) {
+	case json.Number:
+		return v.Float64()
+	case string:
+		return strconv.ParseFloat(v, 64)
+	}
+	return 0, fmt.Errorf("arrjson: %v is not a number", v)
+}
+
+// asObject returns the integer members of a JSON object.
+func asObject(v interface{}, keys ...string) (map[string]int64, error) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("arrjson: %v is not an object", v)
+	}
+	m := make(map[string]int64, len(keys))
+	for _, k := range keys {
+		x, err := asInt64(obj[k])
+		if err != nil {
+			return nil, fmt.Errorf("arrjson: member %q: %w", k, err)
+		}
+		m[k] = x
+	}
+	return m, nil
+}
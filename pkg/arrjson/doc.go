@@ -0,0 +1,30 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package arrjson reads and writes the JSON format used by the Arrow
+integration tests.
+
+The format describes a schema, its dictionaries and a list of record
+batches, with every buffer spelled out as JSON values. It lets gomem arrays
+be checked against golden files produced by other Arrow implementations,
+and doubles as a human readable dump of any array:
+
+	data, err := arrjson.MarshalArray("prices", arr)
+	...
+	arr, err := arrjson.UnmarshalArray(pool, data)
+
+Dictionaries may not change between the batches of a file.
+*/
+package arrjson
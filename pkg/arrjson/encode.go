@@ -0,0 +1,266 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrjson
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/decimal128"
+)
+
+// columnEncoder turns arrays into JSON columns, collecting the dictionaries
+// of the dictionary encoded arrays it meets.
+type columnEncoder struct {
+	ids   *dictionaryIDs
+	dicts map[int64]array.Interface
+}
+
+func (e *columnEncoder) encode(f arrow.Field, arr array.Interface, path string) (jsonColumn, error) {
+	col := jsonColumn{Name: f.Name, Count: arr.Len()}
+	if arr.DataType().ID() != arrow.NULL && arr.DataType().ID() != arrow.RUN_END_ENCODED {
+		col.Validity = make([]int, arr.Len())
+		for i := range col.Validity {
+			if arr.IsValid(i) {
+				col.Validity[i] = 1
+			}
+		}
+	}
+
+	n := arr.Len()
+	data := func(v func(i int) interface{}) {
+		col.Data = make([]interface{}, n)
+		for i := range col.Data {
+			col.Data[i] = v(i)
+		}
+	}
+
+	switch a := arr.(type) {
+	case *array.Null:
+		col.Validity = nil
+	case *array.Boolean:
+		data(func(i int) interface{} { return a.Value(i) })
+	case *array.Int8:
+		data(func(i int) interface{} { return a.Value(i) })
+	case *array.Int16:
+		data(func(i int) interface{} { return a.Value(i) })
+	case *array.Int32:
+		data(func(i int) interface{} { return a.Value(i) })
+	case *array.Int64:
+		data(func(i int) interface{} { return strconv.FormatInt(a.Value(i), 10) })
+	case *array.Uint8:
+		data(func(i int) interface{} { return a.Value(i) })
+	case *array.Uint16:
+		data(func(i int) interface{} { return a.Value(i) })
+	case *array.Uint32:
+		data(func(i int) interface{} { return a.Value(i) })
+	case *array.Uint64:
+		data(func(i int) interface{} { return strconv.FormatUint(a.Value(i), 10) })
+	case *array.Float16:
+		data(func(i int) interface{} { return a.Value(i).Float32() })
+	case *array.Float32:
+		data(func(i int) interface{} { return a.Value(i) })
+	case *array.Float64:
+		data(func(i int) interface{} { return a.Value(i) })
+	case *array.Binary:
+		data(func(i int) interface{} { return hexString(a.Value(i)) })
+		col.Offset = binaryOffsets(a)
+	case *array.String:
+		data(func(i int) interface{} { return a.Value(i) })
+		col.Offset = stringOffsets(a)
+	case *array.FixedSizeBinary:
+		data(func(i int) interface{} { return hexString(a.Value(i)) })
+	case *array.Decimal128:
+		data(func(i int) interface{} { return decimalToBig(a.Value(i)).String() })
+	case *array.Date32:
+		data(func(i int) interface{} { return a.Value(i) })
+	case *array.Date64:
+		data(func(i int) interface{} { return strconv.FormatInt(int64(a.Value(i)), 10) })
+	case *array.Time32:
+		data(func(i int) interface{} { return a.Value(i) })
+	case *array.Time64:
+		data(func(i int) interface{} { return strconv.FormatInt(int64(a.Value(i)), 10) })
+	case *array.Timestamp:
+		data(func(i int) interface{} { return strconv.FormatInt(int64(a.Value(i)), 10) })
+	case *array.Duration:
+		data(func(i int) interface{} { return strconv.FormatInt(int64(a.Value(i)), 10) })
+	case *array.MonthInterval:
+		data(func(i int) interface{} { return a.Value(i) })
+	case *array.DayTimeInterval:
+		data(func(i int) interface{} { return a.Value(i) })
+	case *array.MonthDayNanoInterval:
+		data(func(i int) interface{} {
+			v := a.Value(i)
+			return map[string]interface{}{
+				"months":      v.Months,
+				"days":        v.Days,
+				"nanoseconds": strconv.FormatInt(v.Nanoseconds, 10),
+			}
+		})
+	case *array.List:
+		// the offsets and values of a sliced list are rebased to start at zero.
+		offsets := a.Offsets()[a.Data().Offset() : a.Data().Offset()+n+1]
+		col.Offset = make([]int32, n+1)
+		for i, o := range offsets {
+			col.Offset[i] = o - offsets[0]
+		}
+		values := array.NewSlice(a.ListValues(), int64(offsets[0]), int64(offsets[n]))
+		defer values.Release()
+		if err := e.encodeChildren(&col, arr.DataType(), []array.Interface{values}, path); err != nil {
+			return col, err
+		}
+	case *array.FixedSizeList:
+		size := int64(a.DataType().(*arrow.FixedSizeListType).Len())
+		beg := int64(a.Data().Offset()) * size
+		values := array.NewSlice(a.ListValues(), beg, beg+int64(n)*size)
+		defer values.Release()
+		if err := e.encodeChildren(&col, arr.DataType(), []array.Interface{values}, path); err != nil {
+			return col, err
+		}
+	case *array.Struct:
+		fields := make([]array.Interface, a.NumField())
+		for i := range fields {
+			fields[i] = a.Field(i)
+		}
+		if err := e.encodeChildren(&col, arr.DataType(), fields, path); err != nil {
+			return col, err
+		}
+	case *array.RunEndEncoded:
+		// the runs of a sliced array are trimmed to the slice.
+		beg, runs := a.PhysicalOffset(), a.PhysicalLength()
+		_, fields, err := typeToJSON(arr.DataType())
+		if err != nil {
+			return col, err
+		}
+		ends := jsonColumn{Name: fields[0].Name, Count: runs, Validity: make([]int, runs), Data: make([]interface{}, runs)}
+		for j := 0; j < runs; j++ {
+			ends.Validity[j] = 1
+			ends.Data[j] = a.RunEnd(beg + j)
+			if a.RunEnds().DataType().ID() == arrow.INT64 {
+				ends.Data[j] = strconv.Itoa(a.RunEnd(beg + j))
+			}
+		}
+		values := array.NewSlice(a.Values(), int64(beg), int64(beg+runs))
+		defer values.Release()
+		vals, err := e.encode(fields[1], values, childPath(path, 1))
+		if err != nil {
+			return col, err
+		}
+		col.Children = []jsonColumn{ends, vals}
+	case *array.Dictionary:
+		id, ok := e.ids.byPath[path]
+		if !ok {
+			return col, fmt.Errorf("arrjson: no dictionary id for column %q", f.Name)
+		}
+		if err := e.addDictionary(id, a.Dictionary()); err != nil {
+			return col, fmt.Errorf("arrjson: column %q: %w", f.Name, err)
+		}
+		indices, err := e.encode(f, a.Indices(), path)
+		if err != nil {
+			return col, err
+		}
+		col.Data = indices.Data
+	default:
+		return col, fmt.Errorf("arrjson: unsupported array type %T", arr)
+	}
+	return col, nil
+}
+
+func (e *columnEncoder) encodeChildren(col *jsonColumn, dtype arrow.DataType, children []array.Interface, path string) error {
+	_, fields, err := typeToJSON(dtype)
+	if err != nil {
+		return err
+	}
+	col.Children = make([]jsonColumn, len(children))
+	for i, child := range children {
+		if col.Children[i], err = e.encode(fields[i], child, childPath(path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addDictionary records the dictionary of id, which must not change once recorded.
+func (e *columnEncoder) addDictionary(id int64, dict array.Interface) error {
+	if prev, ok := e.dicts[id]; ok {
+		if !array.ArrayEqual(prev, dict) {
+			return fmt.Errorf("dictionary %d changed between batches", id)
+		}
+		return nil
+	}
+	dict.Retain()
+	e.dicts[id] = dict
+	return nil
+}
+
+func (e *columnEncoder) release() {
+	for id, dict := range e.dicts {
+		dict.Release()
+		delete(e.dicts, id)
+	}
+}
+
+func binaryOffsets(a *array.Binary) []int32 {
+	offsets := make([]int32, a.Len()+1)
+	for i := 0; i < a.Len(); i++ {
+		offsets[i+1] = offsets[i] + int32(a.ValueLen(i))
+	}
+	return offsets
+}
+
+func stringOffsets(a *array.String) []int32 {
+	offsets := make([]int32, a.Len()+1)
+	for i := 0; i < a.Len(); i++ {
+		offsets[i+1] = offsets[i] + int32(len(a.Value(i)))
+	}
+	return offsets
+}
+
+func hexString(b []byte) string {
+	return fmt.Sprintf("%X", b)
+}
+
+func decodeHex(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("arrjson: %v is not a hex string", v)
+	}
+	return hex.DecodeString(s)
+}
+
+var twoTo128 = new(big.Int).Lsh(big.NewInt(1), 128)
+
+func decimalToBig(n decimal128.Num) *big.Int {
+	v := big.NewInt(n.HighBits())
+	v.Lsh(v, 64)
+	return v.Add(v, new(big.Int).SetUint64(n.LowBits()))
+}
+
+func decimalFromString(s string) (decimal128.Num, error) {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return decimal128.Num{}, fmt.Errorf("arrjson: invalid decimal %q", s)
+	}
+	if v.Sign() < 0 {
+		v.Add(v, twoTo128)
+	}
+	lo := new(big.Int).And(v, new(big.Int).SetUint64(^uint64(0))).Uint64()
+	hi := new(big.Int).Rsh(v, 64).Uint64()
+	return decimal128.New(int64(hi), lo), nil
+}
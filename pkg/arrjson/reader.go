@@ -0,0 +1,142 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// Reader reads the record batches of an integration JSON file.
+// The whole file is decoded by NewReader, the records are built on demand.
+type Reader struct {
+	file    jsonFile
+	schema  *arrow.Schema
+	decoder *columnDecoder
+}
+
+// NewReader decodes the integration JSON file read from r.
+func NewReader(r io.Reader, opts ...Option) (*Reader, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	rr := &Reader{}
+	if err := dec.Decode(&rr.file); err != nil {
+		return nil, fmt.Errorf("arrjson: could not decode file: %w", err)
+	}
+
+	ids := newDictionaryIDs()
+	if rr.schema, err = schemaFromJSON(rr.file.Schema, ids); err != nil {
+		return nil, err
+	}
+	rr.decoder = &columnDecoder{mem: cfg.mem, ids: ids, dicts: make(map[int64]array.Interface)}
+
+	for _, jd := range rr.file.Dictionaries {
+		dtype, ok := ids.types[jd.ID]
+		if !ok {
+			rr.Release()
+			return nil, fmt.Errorf("arrjson: dictionary %d is not used by the schema", jd.ID)
+		}
+		if len(jd.Data.Columns) != 1 {
+			rr.Release()
+			return nil, fmt.Errorf("arrjson: dictionary %d has %d columns", jd.ID, len(jd.Data.Columns))
+		}
+		f := arrow.Field{Name: jd.Data.Columns[0].Name, Type: dtype.ValueType, Nullable: true}
+		dict, err := rr.decoder.decode(f, jd.Data.Columns[0], ids.paths[jd.ID])
+		if err != nil {
+			rr.Release()
+			return nil, err
+		}
+		rr.decoder.dicts[jd.ID] = dict
+	}
+	return rr, nil
+}
+
+// Schema returns the schema of the records.
+func (r *Reader) Schema() *arrow.Schema { return r.schema }
+
+// NumRecords returns the number of record batches in the file.
+func (r *Reader) NumRecords() int { return len(r.file.Batches) }
+
+// Read returns the i-th record batch. The caller must release it.
+func (r *Reader) Read(i int) (array.Record, error) {
+	if i < 0 || i >= len(r.file.Batches) {
+		return nil, fmt.Errorf("arrjson: record %d out of range [0, %d)", i, len(r.file.Batches))
+	}
+	batch := r.file.Batches[i]
+	if len(batch.Columns) != len(r.schema.Fields()) {
+		return nil, fmt.Errorf("arrjson: record %d has %d columns, want %d", i, len(batch.Columns), len(r.schema.Fields()))
+	}
+
+	cols := make([]array.Interface, 0, len(batch.Columns))
+	defer func() { releaseAll(cols) }()
+	for j, f := range r.schema.Fields() {
+		col, err := r.decoder.decode(f, batch.Columns[j], childPath("", j))
+		if err != nil {
+			return nil, err
+		}
+		if col.Len() != batch.Count {
+			col.Release()
+			return nil, fmt.Errorf("arrjson: column %q has %d rows, want %d", f.Name, col.Len(), batch.Count)
+		}
+		cols = append(cols, col)
+	}
+	return array.NewRecord(r.schema, cols, int64(batch.Count)), nil
+}
+
+// Release releases the dictionaries held by the reader.
+func (r *Reader) Release() {
+	if r.decoder == nil {
+		return
+	}
+	for id, dict := range r.decoder.dicts {
+		dict.Release()
+		delete(r.decoder.dicts, id)
+	}
+}
+
+// UnmarshalArray returns the array of a file written by MarshalArray.
+// The caller must release it.
+func UnmarshalArray(mem memory.Allocator, data []byte) (array.Interface, error) {
+	r, err := NewReader(bytes.NewReader(data), WithAllocator(mem))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Release()
+
+	if len(r.schema.Fields()) != 1 || r.NumRecords() != 1 {
+		return nil, fmt.Errorf("arrjson: want a single column and a single record, got %d and %d",
+			len(r.schema.Fields()), r.NumRecords())
+	}
+	rec, err := r.Read(0)
+	if err != nil {
+		return nil, err
+	}
+	defer rec.Release()
+
+	arr := rec.Column(0)
+	arr.Retain()
+	return arr, nil
+}
@@ -0,0 +1,432 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+)
+
+type jsonFile struct {
+	Schema       jsonSchema       `json:"schema"`
+	Batches      []jsonBatch      `json:"batches"`
+	Dictionaries []jsonDictionary `json:"dictionaries,omitempty"`
+}
+
+type jsonSchema struct {
+	Fields   []jsonField `json:"fields"`
+	Metadata []jsonKV    `json:"metadata,omitempty"`
+}
+
+type jsonField struct {
+	Name       string                  `json:"name"`
+	Nullable   bool                    `json:"nullable"`
+	Type       jsonType                `json:"type"`
+	Children   []jsonField             `json:"children"`
+	Dictionary *jsonDictionaryEncoding `json:"dictionary,omitempty"`
+	Metadata   []jsonKV                `json:"metadata,omitempty"`
+}
+
+type jsonKV struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// jsonType is the union of the attributes of every type. Precision is a
+// string for floating point types and a number for decimals.
+type jsonType struct {
+	Name      string      `json:"name"`
+	IsSigned  *bool       `json:"isSigned,omitempty"`
+	BitWidth  int         `json:"bitWidth,omitempty"`
+	Precision interface{} `json:"precision,omitempty"`
+	Scale     *int        `json:"scale,omitempty"`
+	ByteWidth int         `json:"byteWidth,omitempty"`
+	ListSize  int         `json:"listSize,omitempty"`
+	Unit      string      `json:"unit,omitempty"`
+	Timezone  string      `json:"timezone,omitempty"`
+}
+
+type jsonDictionaryEncoding struct {
+	ID        int64    `json:"id"`
+	IndexType jsonType `json:"indexType"`
+	IsOrdered bool     `json:"isOrdered"`
+}
+
+type jsonDictionary struct {
+	ID   int64     `json:"id"`
+	Data jsonBatch `json:"data"`
+}
+
+type jsonBatch struct {
+	Count   int          `json:"count"`
+	Columns []jsonColumn `json:"columns"`
+}
+
+// jsonColumn holds the buffers of an array. DATA values are numbers, strings,
+// booleans or objects depending on the type, 64-bit integers are strings.
+type jsonColumn struct {
+	Name     string        `json:"name"`
+	Count    int           `json:"count"`
+	Validity []int         `json:"VALIDITY,omitempty"`
+	Data     []interface{} `json:"DATA,omitempty"`
+	Offset   []int32       `json:"OFFSET,omitempty"`
+	Children []jsonColumn  `json:"children,omitempty"`
+}
+
+var unitNames = map[arrow.TimeUnit]string{
+	arrow.Second:      "SECOND",
+	arrow.Millisecond: "MILLISECOND",
+	arrow.Microsecond: "MICROSECOND",
+	arrow.Nanosecond:  "NANOSECOND",
+}
+
+func unitFromName(name string) (arrow.TimeUnit, error) {
+	for u, n := range unitNames {
+		if n == name {
+			return u, nil
+		}
+	}
+	return 0, fmt.Errorf("arrjson: unknown time unit %q", name)
+}
+
+func intType(signed bool, bitWidth int) jsonType {
+	return jsonType{Name: "int", IsSigned: &signed, BitWidth: bitWidth}
+}
+
+// typeToJSON returns the JSON form of dtype and the fields of its children.
+func typeToJSON(dtype arrow.DataType) (jsonType, []arrow.Field, error) {
+	switch dtype := dtype.(type) {
+	case *arrow.NullType:
+		return jsonType{Name: "null"}, nil, nil
+	case *arrow.BooleanType:
+		return jsonType{Name: "bool"}, nil, nil
+	case *arrow.Int8Type:
+		return intType(true, 8), nil, nil
+	case *arrow.Int16Type:
+		return intType(true, 16), nil, nil
+	case *arrow.Int32Type:
+		return intType(true, 32), nil, nil
+	case *arrow.Int64Type:
+		return intType(true, 64), nil, nil
+	case *arrow.Uint8Type:
+		return intType(false, 8), nil, nil
+	case *arrow.Uint16Type:
+		return intType(false, 16), nil, nil
+	case *arrow.Uint32Type:
+		return intType(false, 32), nil, nil
+	case *arrow.Uint64Type:
+		return intType(false, 64), nil, nil
+	case *arrow.Float16Type:
+		return jsonType{Name: "floatingpoint", Precision: "HALF"}, nil, nil
+	case *arrow.Float32Type:
+		return jsonType{Name: "floatingpoint", Precision: "SINGLE"}, nil, nil
+	case *arrow.Float64Type:
+		return jsonType{Name: "floatingpoint", Precision: "DOUBLE"}, nil, nil
+	case *arrow.BinaryType:
+		return jsonType{Name: "binary"}, nil, nil
+	case *arrow.StringType:
+		return jsonType{Name: "utf8"}, nil, nil
+	case *arrow.FixedSizeBinaryType:
+		return jsonType{Name: "fixedsizebinary", ByteWidth: dtype.ByteWidth}, nil, nil
+	case *arrow.Decimal128Type:
+		scale := int(dtype.Scale)
+		return jsonType{Name: "decimal", Precision: int(dtype.Precision), Scale: &scale, BitWidth: 128}, nil, nil
+	case *arrow.Date32Type:
+		return jsonType{Name: "date", Unit: "DAY"}, nil, nil
+	case *arrow.Date64Type:
+		return jsonType{Name: "date", Unit: "MILLISECOND"}, nil, nil
+	case *arrow.Time32Type:
+		return jsonType{Name: "time", Unit: unitNames[dtype.Unit], BitWidth: 32}, nil, nil
+	case *arrow.Time64Type:
+		return jsonType{Name: "time", Unit: unitNames[dtype.Unit], BitWidth: 64}, nil, nil
+	case *arrow.TimestampType:
+		return jsonType{Name: "timestamp", Unit: unitNames[dtype.Unit], Timezone: dtype.TimeZone}, nil, nil
+	case *arrow.DurationType:
+		return jsonType{Name: "duration", Unit: unitNames[dtype.Unit]}, nil, nil
+	case *arrow.MonthIntervalType:
+		return jsonType{Name: "interval", Unit: "YEAR_MONTH"}, nil, nil
+	case *arrow.DayTimeIntervalType:
+		return jsonType{Name: "interval", Unit: "DAY_TIME"}, nil, nil
+	case *arrow.MonthDayNanoIntervalType:
+		return jsonType{Name: "interval", Unit: "MONTH_DAY_NANO"}, nil, nil
+	case *arrow.ListType:
+		return jsonType{Name: "list"}, []arrow.Field{{Name: "item", Type: dtype.Elem(), Nullable: true}}, nil
+	case *arrow.FixedSizeListType:
+		return jsonType{Name: "fixedsizelist", ListSize: int(dtype.Len())},
+			[]arrow.Field{{Name: "item", Type: dtype.Elem(), Nullable: true}}, nil
+	case *arrow.StructType:
+		return jsonType{Name: "struct"}, dtype.Fields(), nil
+	case *arrow.RunEndEncodedType:
+		return jsonType{Name: "runendencoded"}, []arrow.Field{
+			{Name: "run_ends", Type: dtype.RunEndType},
+			{Name: "values", Type: dtype.ValueType, Nullable: true},
+		}, nil
+	default:
+		return jsonType{}, nil, fmt.Errorf("arrjson: unsupported type %s", dtype)
+	}
+}
+
+// typeFromJSON returns the type described by t with the given children.
+func typeFromJSON(t jsonType, children []arrow.Field) (arrow.DataType, error) {
+	switch t.Name {
+	case "null":
+		return arrow.Null, nil
+	case "bool":
+		return arrow.FixedWidthTypes.Boolean, nil
+	case "int":
+		signed := t.IsSigned != nil && *t.IsSigned
+		switch {
+		case signed && t.BitWidth == 8:
+			return arrow.PrimitiveTypes.Int8, nil
+		case signed && t.BitWidth == 16:
+			return arrow.PrimitiveTypes.Int16, nil
+		case signed && t.BitWidth == 32:
+			return arrow.PrimitiveTypes.Int32, nil
+		case signed && t.BitWidth == 64:
+			return arrow.PrimitiveTypes.Int64, nil
+		case t.BitWidth == 8:
+			return arrow.PrimitiveTypes.Uint8, nil
+		case t.BitWidth == 16:
+			return arrow.PrimitiveTypes.Uint16, nil
+		case t.BitWidth == 32:
+			return arrow.PrimitiveTypes.Uint32, nil
+		case t.BitWidth == 64:
+			return arrow.PrimitiveTypes.Uint64, nil
+		}
+	case "floatingpoint":
+		switch t.Precision {
+		case "HALF":
+			return arrow.FixedWidthTypes.Float16, nil
+		case "SINGLE":
+			return arrow.PrimitiveTypes.Float32, nil
+		case "DOUBLE":
+			return arrow.PrimitiveTypes.Float64, nil
+		}
+	case "binary":
+		return arrow.BinaryTypes.Binary, nil
+	case "utf8":
+		return arrow.BinaryTypes.String, nil
+	case "fixedsizebinary":
+		return &arrow.FixedSizeBinaryType{ByteWidth: t.ByteWidth}, nil
+	case "decimal":
+		precision, err := asInt64(t.Precision)
+		if err != nil || (t.BitWidth != 0 && t.BitWidth != 128) {
+			break
+		}
+		scale := 0
+		if t.Scale != nil {
+			scale = *t.Scale
+		}
+		return &arrow.Decimal128Type{Precision: int32(precision), Scale: int32(scale)}, nil
+	case "date":
+		switch t.Unit {
+		case "DAY":
+			return arrow.FixedWidthTypes.Date32, nil
+		case "MILLISECOND":
+			return arrow.FixedWidthTypes.Date64, nil
+		}
+	case "time", "timestamp", "duration":
+		unit, err := unitFromName(t.Unit)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case t.Name == "timestamp":
+			return &arrow.TimestampType{Unit: unit, TimeZone: t.Timezone}, nil
+		case t.Name == "duration":
+			return &arrow.DurationType{Unit: unit}, nil
+		case t.BitWidth == 32:
+			return &arrow.Time32Type{Unit: unit}, nil
+		case t.BitWidth == 64:
+			return &arrow.Time64Type{Unit: unit}, nil
+		}
+	case "interval":
+		switch t.Unit {
+		case "YEAR_MONTH":
+			return arrow.FixedWidthTypes.MonthInterval, nil
+		case "DAY_TIME":
+			return arrow.FixedWidthTypes.DayTimeInterval, nil
+		case "MONTH_DAY_NANO":
+			return arrow.FixedWidthTypes.MonthDayNanoInterval, nil
+		}
+	case "list":
+		if len(children) == 1 {
+			return arrow.ListOf(children[0].Type), nil
+		}
+	case "fixedsizelist":
+		if len(children) == 1 {
+			return arrow.FixedSizeListOf(int32(t.ListSize), children[0].Type), nil
+		}
+	case "struct":
+		return arrow.StructOf(children...), nil
+	case "runendencoded":
+		if len(children) == 2 {
+			return &arrow.RunEndEncodedType{RunEndType: children[0].Type, ValueType: children[1].Type}, nil
+		}
+	}
+	return nil, fmt.Errorf("arrjson: unsupported type %+v", t)
+}
+
+func metadataToJSON(md arrow.Metadata) []jsonKV {
+	var kvs []jsonKV
+	for i, k := range md.Keys() {
+		kvs = append(kvs, jsonKV{Key: k, Value: md.Values()[i]})
+	}
+	return kvs
+}
+
+func metadataFromJSON(kvs []jsonKV) arrow.Metadata {
+	keys := make([]string, len(kvs))
+	values := make([]string, len(kvs))
+	for i, kv := range kvs {
+		keys[i], values[i] = kv.Key, kv.Value
+	}
+	return arrow.NewMetadata(keys, values)
+}
+
+// dictionaryIDs assigns the ids of the dictionary encoded fields of a schema.
+// Fields are identified by their path, the dot separated indices leading to them.
+type dictionaryIDs struct {
+	byPath map[string]int64
+	paths  map[int64]string
+	types  map[int64]*arrow.DictionaryType
+	next   int64
+}
+
+func newDictionaryIDs() *dictionaryIDs {
+	return &dictionaryIDs{
+		byPath: make(map[string]int64),
+		paths:  make(map[int64]string),
+		types:  make(map[int64]*arrow.DictionaryType),
+	}
+}
+
+func (ids *dictionaryIDs) add(id int64, path string, dtype *arrow.DictionaryType) {
+	ids.byPath[path] = id
+	ids.paths[id] = path
+	ids.types[id] = dtype
+}
+
+func childPath(path string, i int) string {
+	if path == "" {
+		return strconv.Itoa(i)
+	}
+	return path + "." + strconv.Itoa(i)
+}
+
+func schemaToJSON(schema *arrow.Schema, ids *dictionaryIDs) (jsonSchema, error) {
+	js := jsonSchema{Fields: make([]jsonField, len(schema.Fields())), Metadata: metadataToJSON(schema.Metadata())}
+	for i, f := range schema.Fields() {
+		var err error
+		if js.Fields[i], err = fieldToJSON(f, childPath("", i), ids); err != nil {
+			return js, err
+		}
+	}
+	return js, nil
+}
+
+func fieldToJSON(f arrow.Field, path string, ids *dictionaryIDs) (jsonField, error) {
+	jf := jsonField{Name: f.Name, Nullable: f.Nullable, Metadata: metadataToJSON(f.Metadata)}
+
+	dtype := f.Type
+	if dict, ok := dtype.(*arrow.DictionaryType); ok {
+		index, _, err := typeToJSON(dict.IndexType)
+		if err != nil {
+			return jf, err
+		}
+		id := ids.next
+		ids.next++
+		ids.add(id, path, dict)
+		jf.Dictionary = &jsonDictionaryEncoding{ID: id, IndexType: index, IsOrdered: dict.Ordered}
+		dtype = dict.ValueType
+	}
+
+	jt, children, err := typeToJSON(dtype)
+	if err != nil {
+		return jf, err
+	}
+	jf.Type = jt
+	jf.Children = make([]jsonField, len(children))
+	for i, child := range children {
+		if jf.Children[i], err = fieldToJSON(child, childPath(path, i), ids); err != nil {
+			return jf, err
+		}
+	}
+	return jf, nil
+}
+
+func schemaFromJSON(js jsonSchema, ids *dictionaryIDs) (*arrow.Schema, error) {
+	fields := make([]arrow.Field, len(js.Fields))
+	for i, jf := range js.Fields {
+		var err error
+		if fields[i], err = fieldFromJSON(jf, childPath("", i), ids); err != nil {
+			return nil, err
+		}
+	}
+	var md *arrow.Metadata
+	if len(js.Metadata) > 0 {
+		m := metadataFromJSON(js.Metadata)
+		md = &m
+	}
+	return arrow.NewSchema(fields, md), nil
+}
+
+func fieldFromJSON(jf jsonField, path string, ids *dictionaryIDs) (arrow.Field, error) {
+	children := make([]arrow.Field, len(jf.Children))
+	for i, child := range jf.Children {
+		var err error
+		if children[i], err = fieldFromJSON(child, childPath(path, i), ids); err != nil {
+			return arrow.Field{}, err
+		}
+	}
+	dtype, err := typeFromJSON(jf.Type, children)
+	if err != nil {
+		return arrow.Field{}, err
+	}
+
+	if jf.Dictionary != nil {
+		index, err := typeFromJSON(jf.Dictionary.IndexType, nil)
+		if err != nil {
+			return arrow.Field{}, err
+		}
+		dict := &arrow.DictionaryType{IndexType: index, ValueType: dtype, Ordered: jf.Dictionary.IsOrdered}
+		ids.add(jf.Dictionary.ID, path, dict)
+		dtype = dict
+	}
+
+	f := arrow.Field{Name: jf.Name, Type: dtype, Nullable: jf.Nullable}
+	if len(jf.Metadata) > 0 {
+		f.Metadata = metadataFromJSON(jf.Metadata)
+	}
+	return f, nil
+}
+
+// asInt64 returns the integer held by a JSON number or string.
+func asInt64(v interface{}) (int64, error) {
+	switch v := v.(type) {
+	case json.Number:
+		return strconv.ParseInt(string(v), 10, 64)
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	case float64:
+		return int64(v), nil
+	case int:
+		return int64(v), nil
+	}
+	return 0, fmt.Errorf("arrjson: %v is not an integer", v)
+}
@@ -0,0 +1,117 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+)
+
+// Writer writes record batches as an integration JSON file.
+// The file is written by Close, since the dictionaries come before the batches.
+type Writer struct {
+	w       io.Writer
+	schema  *arrow.Schema
+	file    jsonFile
+	encoder *columnEncoder
+}
+
+// NewWriter returns a Writer of records matching schema.
+func NewWriter(w io.Writer, schema *arrow.Schema) (*Writer, error) {
+	ids := newDictionaryIDs()
+	js, err := schemaToJSON(schema, ids)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{
+		w:       w,
+		schema:  schema,
+		file:    jsonFile{Schema: js, Batches: []jsonBatch{}},
+		encoder: &columnEncoder{ids: ids, dicts: make(map[int64]array.Interface)},
+	}, nil
+}
+
+// Write adds rec to the file.
+func (w *Writer) Write(rec array.Record) error {
+	if !rec.Schema().Equal(w.schema) {
+		return fmt.Errorf("arrjson: record schema does not match the writer schema")
+	}
+	batch := jsonBatch{Count: int(rec.NumRows()), Columns: make([]jsonColumn, rec.NumCols())}
+	for i, f := range w.schema.Fields() {
+		var err error
+		if batch.Columns[i], err = w.encoder.encode(f, rec.Column(i), childPath("", i)); err != nil {
+			return err
+		}
+	}
+	w.file.Batches = append(w.file.Batches, batch)
+	return nil
+}
+
+// Close writes the file and releases the dictionaries held by the writer.
+func (w *Writer) Close() error {
+	defer w.encoder.release()
+
+	ids := make([]int64, 0, len(w.encoder.dicts))
+	for id := range w.encoder.dicts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		dict := w.encoder.dicts[id]
+		f := arrow.Field{Name: fmt.Sprintf("DICT%d", id), Type: dict.DataType(), Nullable: true}
+		col, err := w.encoder.encode(f, dict, w.encoder.ids.paths[id])
+		if err != nil {
+			return err
+		}
+		w.file.Dictionaries = append(w.file.Dictionaries, jsonDictionary{
+			ID:   id,
+			Data: jsonBatch{Count: dict.Len(), Columns: []jsonColumn{col}},
+		})
+	}
+
+	data, err := json.MarshalIndent(&w.file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("arrjson: could not encode file: %w", err)
+	}
+	_, err = w.w.Write(append(data, '\n'))
+	return err
+}
+
+// MarshalArray returns arr as a file with a single column named name and a single record.
+func MarshalArray(name string, arr array.Interface) ([]byte, error) {
+	schema := arrow.NewSchema([]arrow.Field{{Name: name, Type: arr.DataType(), Nullable: true}}, nil)
+	rec := array.NewRecord(schema, []array.Interface{arr}, int64(arr.Len()))
+	defer rec.Release()
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, schema)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Write(rec); err != nil {
+		w.encoder.release()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
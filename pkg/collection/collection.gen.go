@@ -19,7 +19,7 @@ package collection
 import (
 	"fmt"
 
-	"github.com/apache/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
 	"github.com/gomem/gomem/pkg/object"
 )
 
@@ -374,6 +374,33 @@ func (c *Int8Collection) AppendObject(v object.Object) error {
 	return nil
 }
 
+// NewMonthDayNanoIntervalCollection creates a new MonthDayNanoInterval collection builder.
+func NewMonthDayNanoIntervalCollection(builder *array.MonthDayNanoIntervalBuilder) *MonthDayNanoIntervalCollection {
+	return &MonthDayNanoIntervalCollection{
+		builder: builder,
+	}
+}
+
+// MonthDayNanoIntervalCollection has logic to apply to this type.
+type MonthDayNanoIntervalCollection struct {
+	builder *array.MonthDayNanoIntervalBuilder
+}
+
+func (c *MonthDayNanoIntervalCollection) AppendObject(v object.Object) error {
+	if v == nil {
+		c.builder.AppendNull()
+		return nil
+	}
+
+	b, chkd := object.CastToMonthDayNanoInterval(v)
+	if !chkd {
+		return fmt.Errorf("cannot cast %T to object.MonthDayNanoInterval", v)
+	}
+
+	c.builder.Append(b.Value())
+	return nil
+}
+
 // NewMonthIntervalCollection creates a new MonthInterval collection builder.
 func NewMonthIntervalCollection(builder *array.MonthIntervalBuilder) *MonthIntervalCollection {
 	return &MonthIntervalCollection{
@@ -631,6 +658,7 @@ var (
 	_ Collection = (*Int32Collection)(nil)
 	_ Collection = (*Int64Collection)(nil)
 	_ Collection = (*Int8Collection)(nil)
+	_ Collection = (*MonthDayNanoIntervalCollection)(nil)
 	_ Collection = (*MonthIntervalCollection)(nil)
 	_ Collection = (*StringCollection)(nil)
 	_ Collection = (*Time32Collection)(nil)
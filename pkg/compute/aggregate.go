@@ -0,0 +1,317 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/decimal128"
+	arrowmath "github.com/gomem/gomem/arrow/go/arrow/math"
+	"github.com/gomem/gomem/pkg/object"
+)
+
+// Sum returns the sum of the non-null values of a column, or object.Null when
+// it has none. The sum keeps the kind of the values: signed integers sum to an
+// object.Int64, unsigned integers to an object.Uint64, floating point numbers
+// to an object.Float64, decimals to an object.Decimal128 and durations to an
+// object.Duration. Integer sums wrap around on overflow.
+//
+// Run-end encoded columns are summed run by run. Float64 chunks are summed by
+// the vectorized kernels of the arrow math package, in several independent
+// sums, so the result may differ in the last bits from the sum in row order.
+// NaNs are summed, see NaNPolicy.Sum to skip them.
+func Sum(col *array.Column) (object.Object, error) {
+	return NaNPropagate.Sum(col)
+}
+
+// Sum is Sum treating NaNs as set by p.
+func (p NaNPolicy) Sum(col *array.Column) (object.Object, error) {
+	s, err := sumColumn(col, p)
+	if err != nil {
+		return nil, err
+	}
+	return s.value(), nil
+}
+
+// Mean returns the mean of the non-null values of a numeric column as an
+// object.Float64, or object.Null when it has none. NaNs are averaged, see
+// NaNPolicy.Mean to skip them.
+func Mean(col *array.Column) (object.Object, error) {
+	return NaNPropagate.Mean(col)
+}
+
+// Mean is Mean treating NaNs as set by p.
+func (p NaNPolicy) Mean(col *array.Column) (object.Object, error) {
+	s, err := sumColumn(col, p)
+	if err != nil {
+		return nil, err
+	}
+	return s.mean()
+}
+
+// MinMax returns the smallest and the largest non-null values of a column as
+// objects of the type of the column, or object.Null for both when it has
+// none. Values are ordered as by SortIndices, so floating point NaNs are
+// greater than every other number, see NaNPolicy.MinMax to skip them.
+func MinMax(col *array.Column) (min, max object.Object, err error) {
+	return NaNPropagate.MinMax(col)
+}
+
+// MinMax is MinMax treating NaNs as set by p.
+func (p NaNPolicy) MinMax(col *array.Column) (min, max object.Object, err error) {
+	// The values of run-end encoded chunks are compared run by run.
+	chunks := col.Data().Chunks()
+	values := make([]array.Interface, len(chunks))
+	for c, chunk := range chunks {
+		values[c] = chunk
+		if ree, ok := chunk.(*array.RunEndEncoded); ok {
+			values[c] = ree.Values()
+		}
+	}
+	cmp, err := newFieldComparator(col.Field(), values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lo, hi, found := p.extremes(chunks, cmp)
+	if !found {
+		return object.NewNull(), object.NewNull(), nil
+	}
+	if min, err = ScalarAt(values[lo.chunk], lo.index); err != nil {
+		return nil, nil, err
+	}
+	if max, err = ScalarAt(values[hi.chunk], hi.index); err != nil {
+		return nil, nil, err
+	}
+	return min, max, nil
+}
+
+// extremes returns the positions of the smallest and the largest values of
+// chunks that are not missing under p, as ordered by cmp. The values of
+// run-end encoded chunks are addressed in their values array. found is false
+// when there are none.
+func (p NaNPolicy) extremes(chunks []array.Interface, cmp valueComparator) (lo, hi position, found bool) {
+	visit := func(pos position) {
+		if !found {
+			lo, hi, found = pos, pos, true
+			return
+		}
+		if cmp(pos, lo) < 0 {
+			lo = pos
+		}
+		if cmp(pos, hi) > 0 {
+			hi = pos
+		}
+	}
+	for c, chunk := range chunks {
+		if ree, ok := chunk.(*array.RunEndEncoded); ok {
+			missing := p.missing(ree.Values())
+			_ = forEachRun(ree, func(j, n int) error {
+				if n > 0 && !missing(j) {
+					visit(position{chunk: c, index: j})
+				}
+				return nil
+			})
+			continue
+		}
+		missing := p.missing(chunk)
+		for i := 0; i < chunk.Len(); i++ {
+			if !missing(i) {
+				visit(position{chunk: c, index: i})
+			}
+		}
+	}
+	return lo, hi, found
+}
+
+// sum accumulates the values of a column in the type of their sum.
+type sum struct {
+	dtype arrow.DataType // type of the values
+	class numberClass
+	n     int64 // number of values summed
+
+	i  int64   // signed integers and durations
+	u  uint64  // unsigned integers
+	f  float64 // floating point numbers
+	hi int64   // decimals, two's complement
+	lo uint64
+}
+
+// newSum returns an empty sum of values of dtype.
+func newSum(dtype arrow.DataType) (*sum, error) {
+	s := &sum{dtype: dtype}
+	if ree, ok := s.dtype.(*arrow.RunEndEncodedType); ok {
+		s.dtype = ree.ValueType
+	}
+	s.class = classOf(s.dtype)
+	if s.class == otherClass && s.dtype.ID() != arrow.DECIMAL && s.dtype.ID() != arrow.DURATION {
+		return nil, fmt.Errorf("compute: cannot sum %s values", s.dtype)
+	}
+	return s, nil
+}
+
+// sumColumn sums the values of col that are not missing under p.
+func sumColumn(col *array.Column, p NaNPolicy) (*sum, error) {
+	s, err := newSum(col.DataType())
+	if err != nil {
+		return nil, err
+	}
+	for _, chunk := range col.Data().Chunks() {
+		s.update(chunk, p)
+	}
+	return s, nil
+}
+
+// update adds the values of arr that are not missing under p.
+func (s *sum) update(arr array.Interface, p NaNPolicy) {
+	if f, ok := arr.(*array.Float64); ok && p == NaNPropagate {
+		s.f += arrowmath.Float64.Sum(f)
+		s.n += int64(f.Len() - f.NullN())
+		return
+	}
+	if ree, ok := arr.(*array.RunEndEncoded); ok {
+		add := s.adder(ree.Values())
+		missing := p.missing(ree.Values())
+		_ = forEachRun(ree, func(j, n int) error {
+			if !missing(j) {
+				add(j, n)
+			}
+			return nil
+		})
+		return
+	}
+
+	add := s.adder(arr)
+	missing := p.missing(arr)
+	for i := 0; i < arr.Len(); i++ {
+		if !missing(i) {
+			add(i, 1)
+		}
+	}
+}
+
+// merge adds the values summed by o, a sum of the same type.
+func (s *sum) merge(o *sum) {
+	s.n += o.n
+	s.i += o.i
+	s.u += o.u
+	s.f += o.f
+	var carry uint64
+	s.lo, carry = bits.Add64(s.lo, o.lo, 0)
+	s.hi = int64(uint64(s.hi) + uint64(o.hi) + carry)
+}
+
+// value returns the sum as an object of the kind documented by Sum.
+func (s *sum) value() object.Object {
+	if s.n == 0 {
+		return object.NewNull()
+	}
+	switch {
+	case s.dtype.ID() == arrow.DECIMAL:
+		return object.NewDecimal128(decimal128.New(s.hi, s.lo))
+	case s.dtype.ID() == arrow.DURATION:
+		return object.NewDuration(arrow.Duration(s.i))
+	case s.class == signedClass:
+		return object.NewInt64(s.i)
+	case s.class == unsignedClass:
+		return object.NewUint64(s.u)
+	default:
+		return object.NewFloat64(s.f)
+	}
+}
+
+// mean returns the mean of the values summed as an object.Float64, or
+// object.Null when there are none.
+func (s *sum) mean() (object.Object, error) {
+	if s.class == otherClass {
+		return nil, fmt.Errorf("compute: %s is not a numeric type", s.dtype)
+	}
+	if s.n == 0 {
+		return object.NewNull(), nil
+	}
+	var sum float64
+	switch s.class {
+	case signedClass:
+		sum = float64(s.i)
+	case unsignedClass:
+		sum = float64(s.u)
+	default:
+		sum = s.f
+	}
+	return object.NewFloat64(sum / float64(s.n)), nil
+}
+
+// adder returns a function adding n times the value at index i of arr.
+func (s *sum) adder(arr array.Interface) func(i, n int) {
+	switch a := arr.(type) {
+	case *array.Decimal128:
+		return func(i, n int) {
+			s.hi, s.lo = addDecimal(s.hi, s.lo, a.Value(i), uint64(n))
+			s.n += int64(n)
+		}
+	case *array.Duration:
+		return func(i, n int) {
+			s.i += int64(a.Value(i)) * int64(n)
+			s.n += int64(n)
+		}
+	}
+	switch s.class {
+	case signedClass:
+		get := int64Getter(arr)
+		return func(i, n int) {
+			s.i += get(i) * int64(n)
+			s.n += int64(n)
+		}
+	case unsignedClass:
+		get := uint64Getter(arr)
+		return func(i, n int) {
+			s.u += get(i) * uint64(n)
+			s.n += int64(n)
+		}
+	default:
+		get := float64Getter(arr)
+		return func(i, n int) {
+			s.f += get(i) * float64(n)
+			s.n += int64(n)
+		}
+	}
+}
+
+// addDecimal returns hi:lo + v*n in 128-bit two's complement.
+func addDecimal(hi int64, lo uint64, v decimal128.Num, n uint64) (int64, uint64) {
+	carry, plo := bits.Mul64(v.LowBits(), n)
+	phi := uint64(v.HighBits())*n + carry
+	lo, c := bits.Add64(lo, plo, 0)
+	return int64(uint64(hi) + phi + c), lo
+}
+
+// numberGetter returns an accessor converting the numeric values of arr to float64.
+func numberGetter(arr array.Interface) (func(int) float64, error) {
+	switch arr.(type) {
+	case *array.Int8, *array.Int16, *array.Int32, *array.Int64:
+		get := int64Getter(arr)
+		return func(i int) float64 { return float64(get(i)) }, nil
+	case *array.Uint8, *array.Uint16, *array.Uint32, *array.Uint64:
+		get := uint64Getter(arr)
+		return func(i int) float64 { return float64(get(i)) }, nil
+	case *array.Float16, *array.Float32, *array.Float64:
+		return float64Getter(arr), nil
+	default:
+		return nil, fmt.Errorf("compute: %s is not a numeric type", arr.DataType())
+	}
+}
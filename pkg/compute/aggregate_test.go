@@ -0,0 +1,132 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/decimal128"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/object"
+)
+
+func TestSumFloat64(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	// Long enough chunks for the vectorized kernels, with exact sums.
+	b := array.NewFloat64Builder(pool)
+	defer b.Release()
+	var chunks []array.Interface
+	var want float64
+	for c := 0; c < 3; c++ {
+		for i := 0; i < 37; i++ {
+			v := float64(c*100 + i)
+			if c == 1 && i%5 == 0 {
+				b.AppendNull()
+				continue
+			}
+			b.Append(v)
+			want += v
+		}
+		chunks = append(chunks, b.NewArray())
+	}
+	chunked := array.NewChunked(arrow.PrimitiveTypes.Float64, chunks)
+	for _, chunk := range chunks {
+		chunk.Release()
+	}
+	col := array.NewColumn(arrow.Field{Name: "v", Type: arrow.PrimitiveTypes.Float64, Nullable: true}, chunked)
+	chunked.Release()
+	defer col.Release()
+
+	sum, err := Sum(col)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum != object.NewFloat64(want) {
+		t.Fatalf("got=%v, want=%v", sum, want)
+	}
+
+	mean, err := Mean(col)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := float64(col.Len() - col.NullN()); mean != object.NewFloat64(want/n) {
+		t.Fatalf("got=%v, want=%v", mean, want/n)
+	}
+}
+
+func TestTypedReductions(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	ub := array.NewUint32Builder(pool)
+	defer ub.Release()
+	ub.AppendValues([]uint32{7, 0, 3, 1}, []bool{true, false, true, true})
+	uarr := ub.NewArray()
+	defer uarr.Release()
+
+	db := array.NewDecimal128Builder(pool, &arrow.Decimal128Type{Precision: 10, Scale: 2})
+	defer db.Release()
+	db.AppendValues([]decimal128.Num{decimal128.FromI64(150), decimal128.FromI64(-275), decimal128.FromI64(25)}, nil)
+	darr := db.NewArray()
+	defer darr.Release()
+
+	tb := array.NewTimestampBuilder(pool, &arrow.TimestampType{Unit: arrow.Second})
+	defer tb.Release()
+	tb.AppendValues([]arrow.Timestamp{30, 10, 0, 20}, []bool{true, true, false, true})
+	tarr := tb.NewArray()
+	defer tarr.Release()
+
+	nb := array.NewInt64Builder(pool)
+	defer nb.Release()
+	nb.AppendNull()
+	narr := nb.NewArray()
+	defer narr.Release()
+
+	for _, tc := range []struct {
+		arr               array.Interface
+		sum, min, max     object.Object
+		sumErr, minMaxErr bool
+	}{
+		{arr: uarr, sum: object.NewUint64(11), min: object.NewUint32(1), max: object.NewUint32(7)},
+		{arr: darr, sum: object.NewDecimal128(decimal128.FromI64(-100)), min: object.NewDecimal128(decimal128.FromI64(-275)), max: object.NewDecimal128(decimal128.FromI64(150))},
+		{arr: tarr, sumErr: true, min: object.NewTimestamp(10), max: object.NewTimestamp(30)},
+		{arr: narr, sum: object.NewNull(), min: object.NewNull(), max: object.NewNull()},
+	} {
+		col := newSingleChunkColumn("v", tc.arr)
+		sum, err := Sum(col)
+		switch {
+		case tc.sumErr && err == nil:
+			t.Errorf("%s: expected a sum error", tc.arr.DataType())
+		case !tc.sumErr && (err != nil || sum != tc.sum):
+			t.Errorf("%s: got sum=%v (%v), want=%v", tc.arr.DataType(), sum, err, tc.sum)
+		}
+		min, max, err := MinMax(col)
+		if err != nil || min != tc.min || max != tc.max {
+			t.Errorf("%s: got min=%v max=%v (%v), want min=%v max=%v", tc.arr.DataType(), min, max, err, tc.min, tc.max)
+		}
+		col.Release()
+	}
+
+	ucol := newSingleChunkColumn("v", uarr)
+	defer ucol.Release()
+	mean, err := Mean(ucol)
+	if err != nil || mean != object.NewFloat64(11.0/3) {
+		t.Fatalf("got mean=%v (%v), want=%v", mean, err, 11.0/3)
+	}
+}
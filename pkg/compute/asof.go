@@ -0,0 +1,230 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// AsofDirection selects the right rows an as-of join matches a left row with.
+type AsofDirection int
+
+const (
+	// AsofBackward matches the last right row at or before the left value.
+	AsofBackward AsofDirection = iota
+	// AsofForward matches the first right row at or after the left value.
+	AsofForward
+	// AsofNearest matches the closest right row, the backward one on a tie.
+	AsofNearest
+)
+
+func (d AsofDirection) String() string {
+	switch d {
+	case AsofBackward:
+		return "backward"
+	case AsofForward:
+		return "forward"
+	case AsofNearest:
+		return "nearest"
+	default:
+		return fmt.Sprintf("AsofDirection(%d)", int(d))
+	}
+}
+
+// AsofJoin matches every row of leftOn with a row of rightOn holding the
+// closest value in the given direction, among the right rows whose leftBy
+// keys equal the rightBy keys when by keys are given. It returns the index of
+// the matched right row of every left row, null when there is none. Neither
+// side needs to be sorted.
+//
+// The on columns hold integers, floats or temporal values such as timestamps.
+// A match must lie within tolerance of the left value, in the unit of the on
+// columns; pass math.Inf(1) for no limit. Among right rows with equal values
+// the last one is matched backward and the first one forward. Null values and
+// null keys never match.
+func AsofJoin(mem memory.Allocator, leftOn, rightOn *array.Column, leftBy, rightBy []*array.Column, direction AsofDirection, tolerance float64) (*array.Int64, error) {
+	if len(leftBy) != len(rightBy) {
+		return nil, fmt.Errorf("compute: AsofJoin needs the same number of left and right keys (%d != %d)", len(leftBy), len(rightBy))
+	}
+	if direction < AsofBackward || direction > AsofNearest {
+		return nil, fmt.Errorf("compute: unknown as-of direction %v", direction)
+	}
+	cmp, dist, err := asofComparators(leftOn, rightOn)
+	if err != nil {
+		return nil, err
+	}
+
+	leftCodes, rightCodes := make([]int32, leftOn.Len()), make([]int32, rightOn.Len())
+	if len(leftBy) > 0 {
+		enc := NewKeyEncoder(false)
+		if rightCodes, err = enc.Encode(rightBy...); err != nil {
+			return nil, err
+		}
+		if leftCodes, err = enc.Encode(leftBy...); err != nil {
+			return nil, err
+		}
+	}
+
+	// Gather the valid right rows of every key, ordered by value then row.
+	rightNulls := nullMask(rightOn)
+	groups := make(map[int32][]int)
+	for row, code := range rightCodes {
+		if code >= 0 && !rightNulls[row] {
+			groups[code] = append(groups[code], row)
+		}
+	}
+	for _, rows := range groups {
+		sort.SliceStable(rows, func(i, j int) bool { return cmp.rr(rows[i], rows[j]) < 0 })
+	}
+
+	leftNulls := nullMask(leftOn)
+	bldr := array.NewInt64Builder(mem)
+	defer bldr.Release()
+	bldr.Reserve(leftOn.Len())
+	for row, code := range leftCodes {
+		rows := groups[code]
+		if code < 0 || leftNulls[row] || len(rows) == 0 {
+			bldr.AppendNull()
+			continue
+		}
+
+		// after is the first right row with a greater value, so rows[after-1]
+		// is the last one at or before the left value.
+		after := sort.Search(len(rows), func(k int) bool { return cmp.lr(row, rows[k]) < 0 })
+		from := sort.Search(after, func(k int) bool { return cmp.lr(row, rows[k]) <= 0 })
+		match := -1
+		switch direction {
+		case AsofBackward:
+			if after > 0 {
+				match = rows[after-1]
+			}
+		case AsofForward:
+			if from < len(rows) {
+				match = rows[from]
+			}
+		case AsofNearest:
+			switch {
+			case after > 0 && (from == len(rows) || dist(row, rows[after-1]) <= dist(row, rows[from])):
+				match = rows[after-1]
+			case from < len(rows):
+				match = rows[from]
+			}
+		}
+
+		if match < 0 || dist(row, match) > tolerance {
+			bldr.AppendNull()
+			continue
+		}
+		bldr.Append(int64(match))
+	}
+	return bldr.NewInt64Array(), nil
+}
+
+// asofComparator compares values of the left and right on columns.
+type asofComparator struct {
+	lr func(l, r int) int
+	rr func(a, b int) int
+}
+
+// asofComparators materializes the on columns and returns functions comparing
+// their values and computing the distance between a left and a right value.
+func asofComparators(left, right *array.Column) (asofComparator, func(l, r int) float64, error) {
+	lclass, rclass := asofClass(left), asofClass(right)
+	temporal := classOf(left.DataType()) == otherClass || classOf(right.DataType()) == otherClass
+	switch {
+	case lclass == otherClass || rclass == otherClass || (lclass == floatClass) != (rclass == floatClass),
+		temporal && !arrow.TypeEqual(left.DataType(), right.DataType()):
+		return asofComparator{}, nil, fmt.Errorf("compute: cannot as-of join %s with %s", left.DataType(), right.DataType())
+	case lclass == floatClass:
+		lv, rv := floatValues(left), floatValues(right)
+		return asofComparator{
+			lr: func(l, r int) int { return compareFloat64(lv[l], rv[r]) },
+			rr: func(a, b int) int { return compareFloat64(rv[a], rv[b]) },
+		}, func(l, r int) float64 {
+			return math.Abs(lv[l] - rv[r])
+		}, nil
+	default:
+		lv, rv := intValues(left), intValues(right)
+		return asofComparator{
+			lr: func(l, r int) int { return compareInt64(lv[l], rv[r]) },
+			rr: func(a, b int) int { return compareInt64(rv[a], rv[b]) },
+		}, func(l, r int) float64 {
+			if lv[l] > rv[r] {
+				return float64(lv[l] - rv[r])
+			}
+			return float64(rv[r] - lv[l])
+		}, nil
+	}
+}
+
+// asofClass classifies the on columns, temporal types compare as signed integers.
+func asofClass(col *array.Column) numberClass {
+	if class := classOf(col.DataType()); class != otherClass {
+		return class
+	}
+	for _, chunk := range col.Data().Chunks() {
+		switch chunk.(type) {
+		case *array.Date32, *array.Date64, *array.Time32, *array.Time64, *array.Timestamp, *array.Duration:
+			return signedClass
+		}
+		return otherClass
+	}
+	return signedClass
+}
+
+func intValues(col *array.Column) []int64 {
+	values := make([]int64, 0, col.Len())
+	for _, chunk := range col.Data().Chunks() {
+		if classOf(chunk.DataType()) == unsignedClass {
+			get := uint64Getter(chunk)
+			for i := 0; i < chunk.Len(); i++ {
+				values = append(values, int64(get(i)))
+			}
+			continue
+		}
+		get := int64Getter(chunk)
+		for i := 0; i < chunk.Len(); i++ {
+			values = append(values, get(i))
+		}
+	}
+	return values
+}
+
+func floatValues(col *array.Column) []float64 {
+	values := make([]float64, 0, col.Len())
+	for _, chunk := range col.Data().Chunks() {
+		get := float64Getter(chunk)
+		for i := 0; i < chunk.Len(); i++ {
+			values = append(values, get(i))
+		}
+	}
+	return values
+}
+
+func nullMask(col *array.Column) []bool {
+	nulls := make([]bool, 0, col.Len())
+	for _, chunk := range col.Data().Chunks() {
+		for i := 0; i < chunk.Len(); i++ {
+			nulls = append(nulls, chunk.IsNull(i))
+		}
+	}
+	return nulls
+}
@@ -0,0 +1,101 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestAsofJoin(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	// The right side is not sorted and holds a tie at 20 and a null.
+	leftTs := newNullableInt64Column(pool, "ts", []int64{5, 10, 20, 26, 40, 0}, []bool{true, true, true, true, true, false})
+	defer leftTs.Release()
+	rightTs := newNullableInt64Column(pool, "ts", []int64{30, 20, 10, 20, 0}, []bool{true, true, true, true, false})
+	defer rightTs.Release()
+
+	for _, tc := range []struct {
+		direction AsofDirection
+		tolerance float64
+		want      string
+	}{
+		{AsofBackward, math.Inf(1), "[(null) 2 3 3 0 (null)]"},
+		{AsofForward, math.Inf(1), "[2 2 1 0 (null) (null)]"},
+		{AsofNearest, math.Inf(1), "[2 2 3 0 0 (null)]"},
+		{AsofBackward, 5, "[(null) 2 3 (null) (null) (null)]"},
+		{AsofNearest, 4, "[(null) 2 3 0 (null) (null)]"},
+	} {
+		got, err := AsofJoin(pool, leftTs, rightTs, nil, nil, tc.direction, tc.tolerance)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := fmt.Sprint(got); s != tc.want {
+			t.Errorf("%v within %v: got %s, want %s", tc.direction, tc.tolerance, s, tc.want)
+		}
+		got.Release()
+	}
+}
+
+func TestAsofJoinBy(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	dtype := &arrow.TimestampType{Unit: arrow.Millisecond}
+	newTimestamps := func(values []arrow.Timestamp) *array.Column {
+		b := array.NewTimestampBuilder(pool, dtype)
+		defer b.Release()
+		b.AppendValues(values, nil)
+		arr := b.NewArray()
+		defer arr.Release()
+		chunked := array.NewChunked(dtype, []array.Interface{arr})
+		defer chunked.Release()
+		return array.NewColumn(arrow.Field{Name: "ts", Type: dtype}, chunked)
+	}
+
+	trades := newTimestamps([]arrow.Timestamp{100, 100, 250})
+	defer trades.Release()
+	tradeSyms := newStringColumn(pool, "sym", []string{"a", "b", "a"}, nil)
+	defer tradeSyms.Release()
+	quotes := newTimestamps([]arrow.Timestamp{90, 95, 200, 300})
+	defer quotes.Release()
+	quoteSyms := newStringColumn(pool, "sym", []string{"a", "b", "a", "a"}, nil)
+	defer quoteSyms.Release()
+
+	got, err := AsofJoin(pool, trades, quotes, []*array.Column{tradeSyms}, []*array.Column{quoteSyms}, AsofBackward, math.Inf(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Release()
+	if s, want := fmt.Sprint(got), "[0 1 2]"; s != want {
+		t.Errorf("got %s, want %s", s, want)
+	}
+
+	if _, err := AsofJoin(pool, trades, tradeSyms, nil, nil, AsofBackward, 0); err == nil {
+		t.Error("expected an error joining timestamps with strings")
+	}
+	ints := newInt64Column(pool, "ts", []int64{1, 2, 3})
+	defer ints.Release()
+	if _, err := AsofJoin(pool, trades, ints, nil, nil, AsofBackward, 0); err == nil {
+		t.Error("expected an error joining timestamps with integers")
+	}
+}
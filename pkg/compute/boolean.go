@@ -0,0 +1,182 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// BooleanLogic selects how the boolean kernels treat nulls.
+type BooleanLogic int
+
+const (
+	// NullAsFalse treats nulls as false, so the results are never null. This
+	// is how masks are combined, a null row being filtered out.
+	NullAsFalse BooleanLogic = iota
+	// Kleene follows three-valued logic, where null is an unknown value:
+	// false AND null is false and true OR null is true, the other operations
+	// with a null operand are null.
+	Kleene
+)
+
+func (l BooleanLogic) String() string {
+	switch l {
+	case NullAsFalse:
+		return "null-as-false"
+	case Kleene:
+		return "kleene"
+	default:
+		return fmt.Sprintf("BooleanLogic(%d)", int(l))
+	}
+}
+
+// And returns the elementwise conjunction of a and b, which must have the
+// same length, with nulls treated as set by logic.
+//
+// The boolean kernels work on the bitmaps of their operands a byte at a time,
+// 8 rows per operation, rather than row by row.
+func And(mem memory.Allocator, a, b *array.Boolean, logic BooleanLogic) (*array.Boolean, error) {
+	return booleanBinary(mem, a, b, logic, "And", func(va, ma, vb, mb byte) (byte, byte) {
+		// A known false operand makes the result known.
+		return va & vb, ma&mb | ma&^va | mb&^vb
+	})
+}
+
+// Or returns the elementwise disjunction of a and b, which must have the same
+// length, with nulls treated as set by logic.
+func Or(mem memory.Allocator, a, b *array.Boolean, logic BooleanLogic) (*array.Boolean, error) {
+	return booleanBinary(mem, a, b, logic, "Or", func(va, ma, vb, mb byte) (byte, byte) {
+		// A known true operand makes the result known.
+		return va | vb, ma&mb | ma&va | mb&vb
+	})
+}
+
+// Xor returns the elementwise exclusive disjunction of a and b, which must
+// have the same length, with nulls treated as set by logic.
+func Xor(mem memory.Allocator, a, b *array.Boolean, logic BooleanLogic) (*array.Boolean, error) {
+	return booleanBinary(mem, a, b, logic, "Xor", func(va, ma, vb, mb byte) (byte, byte) {
+		return va ^ vb, ma & mb
+	})
+}
+
+// Not returns the elementwise negation of a, with nulls treated as set by
+// logic: a null is true with NullAsFalse and stays null with Kleene.
+func Not(mem memory.Allocator, a *array.Boolean, logic BooleanLogic) *array.Boolean {
+	n := a.Len()
+	va, ma := booleanBits(a)
+	values := make([]byte, len(va))
+	var valid []byte
+	if logic == Kleene {
+		valid = ma
+	}
+	for k := range values {
+		if logic == NullAsFalse {
+			values[k] = ^(va[k] & ma[k])
+			continue
+		}
+		values[k] = ^va[k] & ma[k]
+	}
+	return newBooleanFromBits(mem, n, values, valid)
+}
+
+// booleanBinary applies op to the bytes of the values and validity bitmaps
+// of a and b. op returns the values and validity of the result under Kleene
+// logic, given operand values cleared where they are null.
+func booleanBinary(mem memory.Allocator, a, b *array.Boolean, logic BooleanLogic, name string, op func(va, ma, vb, mb byte) (byte, byte)) (*array.Boolean, error) {
+	if a.Len() != b.Len() {
+		return nil, fmt.Errorf("compute: %s of boolean arrays of lengths %d and %d", name, a.Len(), b.Len())
+	}
+	n := a.Len()
+	va, ma := booleanBits(a)
+	vb, mb := booleanBits(b)
+	values := make([]byte, len(va))
+	var valid []byte
+	if logic == Kleene {
+		valid = make([]byte, len(va))
+	}
+	for k := range values {
+		v, m := op(va[k]&ma[k], ma[k], vb[k]&mb[k], mb[k])
+		if logic == NullAsFalse {
+			// Cleared operands are false, so every result is known.
+			values[k] = v
+			continue
+		}
+		values[k], valid[k] = v&m, m
+	}
+	return newBooleanFromBits(mem, n, values, valid), nil
+}
+
+// booleanBits returns the values and validity bitmaps of arr starting at bit
+// 0, copied when arr is sliced at an offset that is not a multiple of 8. The
+// validity of an array without nulls is all set.
+func booleanBits(arr *array.Boolean) (values, valid []byte) {
+	n := arr.Len()
+	size := int(bitutil.BytesForBits(int64(n)))
+	data := arr.Data()
+	offset := data.Offset()
+	bits := func(buf *memory.Buffer) []byte {
+		if buf == nil {
+			return make([]byte, size)
+		}
+		if offset%8 == 0 {
+			return buf.Bytes()[offset/8 : offset/8+size]
+		}
+		out := make([]byte, size)
+		bitutil.CopyBitmap(buf.Bytes(), offset, n, out, 0)
+		return out
+	}
+
+	values = bits(data.Buffers()[1])
+	if arr.NullN() == 0 || data.Buffers()[0] == nil {
+		valid = make([]byte, size)
+		for k := range valid {
+			valid[k] = 0xff
+		}
+		return values, valid
+	}
+	return values, bits(data.Buffers()[0])
+}
+
+// newBooleanFromBits returns a Boolean array of n rows holding the bitmaps
+// values and valid, which is nil when every row is valid.
+func newBooleanFromBits(mem memory.Allocator, n int, values, valid []byte) *array.Boolean {
+	toBuffer := func(bits []byte) *memory.Buffer {
+		buf := memory.NewResizableBuffer(mem)
+		buf.Resize(len(bits))
+		copy(buf.Bytes(), bits)
+		return buf
+	}
+
+	nulls := 0
+	var bitmap *memory.Buffer
+	if valid != nil {
+		nulls = n - bitutil.CountSetBits(valid, 0, n)
+	}
+	if nulls > 0 {
+		bitmap = toBuffer(valid)
+		defer bitmap.Release()
+	}
+	data := toBuffer(values)
+	defer data.Release()
+
+	arrData := array.NewData(arrow.FixedWidthTypes.Boolean, n, []*memory.Buffer{bitmap, data}, nil, nulls, 0)
+	defer arrData.Release()
+	return array.NewBooleanData(arrData)
+}
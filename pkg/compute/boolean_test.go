@@ -0,0 +1,82 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestBooleanKernels(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	// Every combination of true, false and null, after 3 padding rows so
+	// that the slices below start in the middle of a byte.
+	b := array.NewBooleanBuilder(pool)
+	defer b.Release()
+	b.AppendValues([]bool{true, true, true, true, true, true, false, false, false, false, false, false}, []bool{true, true, true, true, true, true, true, true, true, false, false, false})
+	left := b.NewBooleanArray()
+	defer left.Release()
+	b.AppendValues([]bool{false, false, false, true, false, false, true, false, false, true, false, false}, []bool{true, true, true, true, true, false, true, true, false, true, true, false})
+	right := b.NewBooleanArray()
+	defer right.Release()
+
+	x := array.NewSlice(left, 3, 12).(*array.Boolean)
+	defer x.Release()
+	y := array.NewSlice(right, 3, 12).(*array.Boolean)
+	defer y.Release()
+
+	for _, tc := range []struct {
+		name  string
+		fn    func(mem memory.Allocator, a, b *array.Boolean, logic BooleanLogic) (*array.Boolean, error)
+		logic BooleanLogic
+		want  string
+	}{
+		{"and", And, NullAsFalse, "[true false false false false false false false false]"},
+		{"and", And, Kleene, "[true false (null) false false false (null) false (null)]"},
+		{"or", Or, NullAsFalse, "[true true true true false false true false false]"},
+		{"or", Or, Kleene, "[true true true true false (null) true (null) (null)]"},
+		{"xor", Xor, NullAsFalse, "[false true true true false false true false false]"},
+		{"xor", Xor, Kleene, "[false true (null) true false (null) (null) (null) (null)]"},
+	} {
+		got, err := tc.fn(pool, x, y, tc.logic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := fmt.Sprint(got); s != tc.want {
+			t.Errorf("%s %v: got=%s, want=%s", tc.name, tc.logic, s, tc.want)
+		}
+		got.Release()
+	}
+
+	for logic, want := range map[BooleanLogic]string{
+		NullAsFalse: "[false false false true true true true true true]",
+		Kleene:      "[false false false true true true (null) (null) (null)]",
+	} {
+		got := Not(pool, x, logic)
+		if s := fmt.Sprint(got); s != want {
+			t.Errorf("not %v: got=%s, want=%s", logic, s, want)
+		}
+		got.Release()
+	}
+
+	if _, err := And(pool, left, x, Kleene); err == nil {
+		t.Fatal("expected an error for arrays of different lengths")
+	}
+}
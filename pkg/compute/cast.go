@@ -0,0 +1,352 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/float16"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/scalar"
+)
+
+// Cast converts the values of col to the type to, keeping the nulls.
+//
+// Booleans, integers, floating point numbers and strings convert to each
+// other. The conversion is checked: an integer that does not fit in the
+// target type, a float with a fractional part cast to an integer or a string
+// that does not parse returns an error rather than a wrapped or truncated
+// value. Dictionary columns are decoded first.
+func Cast(mem memory.Allocator, col *array.Column, to arrow.DataType) (*array.Column, error) {
+	if arrow.TypeEqual(col.DataType(), to) {
+		col.Retain()
+		return col, nil
+	}
+	if _, ok := col.DataType().(*arrow.DictionaryType); ok {
+		decoded, err := DictionaryDecode(mem, col)
+		if err != nil {
+			return nil, err
+		}
+		defer decoded.Release()
+		return Cast(mem, decoded, to)
+	}
+
+	read, err := castReader(col.DataType())
+	if err != nil {
+		return nil, err
+	}
+	bldr := array.NewBuilder(mem, to)
+	defer bldr.Release()
+	write, err := castWriter(bldr, to)
+	if err != nil {
+		return nil, err
+	}
+
+	bldr.Reserve(col.Len())
+	for _, chunk := range col.Data().Chunks() {
+		for i := 0; i < chunk.Len(); i++ {
+			if chunk.IsNull(i) {
+				bldr.AppendNull()
+				continue
+			}
+			if err := write(read(chunk, i)); err != nil {
+				return nil, fmt.Errorf("compute: Cast of column %q to %s: %w", col.Name(), to, err)
+			}
+		}
+	}
+
+	field := col.Field()
+	return newColumnFromBuilder(field, bldr), nil
+}
+
+// Fill returns a column of n rows all holding value, a bool, an integer, a
+// float, a string, a scalar.Scalar or nil for nulls, cast to the type of
+// field.
+func Fill(mem memory.Allocator, field arrow.Field, value interface{}, n int) (*array.Column, error) {
+	if s, ok := value.(scalar.Scalar); ok {
+		return fillScalar(mem, field, s, n)
+	}
+
+	bldr := array.NewBuilder(mem, field.Type)
+	defer bldr.Release()
+	if value == nil {
+		for i := 0; i < n; i++ {
+			bldr.AppendNull()
+		}
+		return newColumnFromBuilder(field, bldr), nil
+	}
+
+	write, err := castWriter(bldr, field.Type)
+	if err != nil {
+		return nil, err
+	}
+	v, err := fillValue(value)
+	if err != nil {
+		return nil, fmt.Errorf("compute: Fill of column %q: %w", field.Name, err)
+	}
+
+	bldr.Reserve(n)
+	for i := 0; i < n; i++ {
+		if err := write(v); err != nil {
+			return nil, fmt.Errorf("compute: Fill of column %q: %w", field.Name, err)
+		}
+	}
+	return newColumnFromBuilder(field, bldr), nil
+}
+
+// fillScalar broadcasts s, cast through its object when its type is not the
+// type of field.
+func fillScalar(mem memory.Allocator, field arrow.Field, s scalar.Scalar, n int) (*array.Column, error) {
+	if !arrow.TypeEqual(s.DataType(), field.Type) {
+		var err error
+		if s, err = scalar.FromObject(field.Type, s.Object()); err != nil {
+			return nil, fmt.Errorf("compute: Fill of column %q: %w", field.Name, err)
+		}
+	}
+	arr, err := scalar.MakeArray(mem, s, n)
+	if err != nil {
+		return nil, fmt.Errorf("compute: Fill of column %q: %w", field.Name, err)
+	}
+	defer arr.Release()
+
+	chunked := array.NewChunked(arr.DataType(), []array.Interface{arr})
+	defer chunked.Release()
+	return array.NewColumn(field, chunked), nil
+}
+
+// fillValue normalizes a value given by the user to the bool, int64, uint64,
+// float64 or string expected by the function returned by castWriter.
+func fillValue(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case bool, int64, uint64, float64, string:
+		return value, nil
+	case int:
+		return int64(value), nil
+	case int32:
+		return int64(value), nil
+	case uint32:
+		return uint64(value), nil
+	case float32:
+		return float64(value), nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+// castReader returns the function reading the value of a row as a bool,
+// int64, uint64, float64 or string.
+func castReader(dtype arrow.DataType) (func(arr array.Interface, i int) interface{}, error) {
+	switch dtype.ID() {
+	case arrow.BOOL:
+		return func(arr array.Interface, i int) interface{} { return arr.(*array.Boolean).Value(i) }, nil
+	case arrow.INT8:
+		return func(arr array.Interface, i int) interface{} { return int64(arr.(*array.Int8).Value(i)) }, nil
+	case arrow.INT16:
+		return func(arr array.Interface, i int) interface{} { return int64(arr.(*array.Int16).Value(i)) }, nil
+	case arrow.INT32:
+		return func(arr array.Interface, i int) interface{} { return int64(arr.(*array.Int32).Value(i)) }, nil
+	case arrow.INT64:
+		return func(arr array.Interface, i int) interface{} { return arr.(*array.Int64).Value(i) }, nil
+	case arrow.UINT8:
+		return func(arr array.Interface, i int) interface{} { return uint64(arr.(*array.Uint8).Value(i)) }, nil
+	case arrow.UINT16:
+		return func(arr array.Interface, i int) interface{} { return uint64(arr.(*array.Uint16).Value(i)) }, nil
+	case arrow.UINT32:
+		return func(arr array.Interface, i int) interface{} { return uint64(arr.(*array.Uint32).Value(i)) }, nil
+	case arrow.UINT64:
+		return func(arr array.Interface, i int) interface{} { return arr.(*array.Uint64).Value(i) }, nil
+	case arrow.FLOAT16:
+		return func(arr array.Interface, i int) interface{} { return float64(arr.(*array.Float16).Value(i).Float32()) }, nil
+	case arrow.FLOAT32:
+		return func(arr array.Interface, i int) interface{} { return float64(arr.(*array.Float32).Value(i)) }, nil
+	case arrow.FLOAT64:
+		return func(arr array.Interface, i int) interface{} { return arr.(*array.Float64).Value(i) }, nil
+	case arrow.STRING:
+		return func(arr array.Interface, i int) interface{} { return arr.(*array.String).Value(i) }, nil
+	default:
+		return nil, fmt.Errorf("compute: Cast from %s is not supported", dtype)
+	}
+}
+
+// castWriter returns the function appending a value returned by a castReader
+// to bldr, converting it to the type of the builder.
+func castWriter(bldr array.Builder, dtype arrow.DataType) (func(v interface{}) error, error) {
+	switch b := bldr.(type) {
+	case *array.BooleanBuilder:
+		return func(v interface{}) error {
+			switch v := v.(type) {
+			case bool:
+				b.Append(v)
+			case string:
+				x, err := strconv.ParseBool(v)
+				if err != nil {
+					return err
+				}
+				b.Append(x)
+			default:
+				f, _ := toFloat(v)
+				b.Append(f != 0)
+			}
+			return nil
+		}, nil
+	case *array.Int8Builder:
+		return intWriter(math.MinInt8, math.MaxInt8, func(x int64) { b.Append(int8(x)) }), nil
+	case *array.Int16Builder:
+		return intWriter(math.MinInt16, math.MaxInt16, func(x int64) { b.Append(int16(x)) }), nil
+	case *array.Int32Builder:
+		return intWriter(math.MinInt32, math.MaxInt32, func(x int64) { b.Append(int32(x)) }), nil
+	case *array.Int64Builder:
+		return intWriter(math.MinInt64, math.MaxInt64, b.Append), nil
+	case *array.Uint8Builder:
+		return uintWriter(math.MaxUint8, func(x uint64) { b.Append(uint8(x)) }), nil
+	case *array.Uint16Builder:
+		return uintWriter(math.MaxUint16, func(x uint64) { b.Append(uint16(x)) }), nil
+	case *array.Uint32Builder:
+		return uintWriter(math.MaxUint32, func(x uint64) { b.Append(uint32(x)) }), nil
+	case *array.Uint64Builder:
+		return uintWriter(math.MaxUint64, b.Append), nil
+	case *array.Float16Builder:
+		return floatWriter(func(x float64) { b.Append(float16.New(float32(x))) }), nil
+	case *array.Float32Builder:
+		return floatWriter(func(x float64) { b.Append(float32(x)) }), nil
+	case *array.Float64Builder:
+		return floatWriter(b.Append), nil
+	case *array.StringBuilder:
+		return func(v interface{}) error {
+			switch v := v.(type) {
+			case bool:
+				b.Append(strconv.FormatBool(v))
+			case int64:
+				b.Append(strconv.FormatInt(v, 10))
+			case uint64:
+				b.Append(strconv.FormatUint(v, 10))
+			case float64:
+				b.Append(strconv.FormatFloat(v, 'g', -1, 64))
+			case string:
+				b.Append(v)
+			}
+			return nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("compute: Cast to %s is not supported", dtype)
+	}
+}
+
+func intWriter(min, max int64, appendValue func(int64)) func(v interface{}) error {
+	return func(v interface{}) error {
+		var x int64
+		switch v := v.(type) {
+		case bool:
+			if v {
+				x = 1
+			}
+		case int64:
+			x = v
+		case uint64:
+			if v > math.MaxInt64 {
+				return fmt.Errorf("value %d out of range", v)
+			}
+			x = int64(v)
+		case float64:
+			if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+				return fmt.Errorf("value %v is not an integer in range", v)
+			}
+			x = int64(v)
+		case string:
+			var err error
+			if x, err = strconv.ParseInt(v, 10, 64); err != nil {
+				return err
+			}
+		}
+		if x < min || x > max {
+			return fmt.Errorf("value %d out of range", x)
+		}
+		appendValue(x)
+		return nil
+	}
+}
+
+func uintWriter(max uint64, appendValue func(uint64)) func(v interface{}) error {
+	return func(v interface{}) error {
+		var x uint64
+		switch v := v.(type) {
+		case bool:
+			if v {
+				x = 1
+			}
+		case int64:
+			if v < 0 {
+				return fmt.Errorf("value %d out of range", v)
+			}
+			x = uint64(v)
+		case uint64:
+			x = v
+		case float64:
+			if v != math.Trunc(v) || v < 0 || v >= math.MaxUint64 {
+				return fmt.Errorf("value %v is not an integer in range", v)
+			}
+			x = uint64(v)
+		case string:
+			var err error
+			if x, err = strconv.ParseUint(v, 10, 64); err != nil {
+				return err
+			}
+		}
+		if x > max {
+			return fmt.Errorf("value %d out of range", x)
+		}
+		appendValue(x)
+		return nil
+	}
+}
+
+func floatWriter(appendValue func(float64)) func(v interface{}) error {
+	return func(v interface{}) error {
+		if s, ok := v.(string); ok {
+			x, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return err
+			}
+			appendValue(x)
+			return nil
+		}
+		x, _ := toFloat(v)
+		appendValue(x)
+		return nil
+	}
+}
+
+// toFloat converts a value returned by a castReader, other than a string, to a float64.
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
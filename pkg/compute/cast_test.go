@@ -0,0 +1,101 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/scalar"
+)
+
+func TestCast(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	ints := newNullableInt64Column(pool, "i", []int64{1, 0, 300, -2}, []bool{true, false, true, true})
+	defer ints.Release()
+	floats := newFloat64Column(pool, "f", []float64{1.5, 2, 0}, []bool{true, true, false})
+	defer floats.Release()
+	strs := newStringColumn(pool, "s", []string{"12", "", "x"}, []bool{true, false, true})
+	defer strs.Release()
+
+	for _, tc := range []struct {
+		col  string
+		to   arrow.DataType
+		want string
+	}{
+		{"i", arrow.PrimitiveTypes.Float64, "[1 (null) 300 -2]"},
+		{"i", arrow.BinaryTypes.String, `["1" (null) "300" "-2"]`},
+		{"i", arrow.FixedWidthTypes.Boolean, "[true (null) true true]"},
+		{"i", arrow.PrimitiveTypes.Int8, `compute: Cast of column "i" to int8: value 300 out of range`},
+		{"i", arrow.PrimitiveTypes.Uint16, `compute: Cast of column "i" to uint16: value -2 out of range`},
+		{"f", arrow.PrimitiveTypes.Int32, `compute: Cast of column "f" to int32: value 1.5 is not an integer in range`},
+		{"f", arrow.BinaryTypes.String, `["1.5" "2" (null)]`},
+		{"s", arrow.PrimitiveTypes.Int64, `compute: Cast of column "s" to int64: strconv.ParseInt: parsing "x": invalid syntax`},
+		{"s", arrow.BinaryTypes.String, `["12" (null) "x"]`},
+		{"s", arrow.ListOf(arrow.PrimitiveTypes.Int64), "compute: Cast to list<item: int64> is not supported"},
+	} {
+		col := map[string]*array.Column{"i": ints, "f": floats, "s": strs}[tc.col]
+		got, err := Cast(pool, col, tc.to)
+		if err != nil {
+			if err.Error() != tc.want {
+				t.Errorf("cast %s to %s: got error %q, want %q", tc.col, tc.to, err, tc.want)
+			}
+			continue
+		}
+		if !arrow.TypeEqual(got.DataType(), tc.to) {
+			t.Errorf("cast %s to %s: invalid type %s", tc.col, tc.to, got.DataType())
+		}
+		if s := fmt.Sprint(got.Data().Chunk(0)); s != tc.want {
+			t.Errorf("cast %s to %s: got=%s, want=%s", tc.col, tc.to, s, tc.want)
+		}
+		got.Release()
+	}
+}
+
+func TestFill(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	for _, tc := range []struct {
+		dtype arrow.DataType
+		value interface{}
+		want  string
+	}{
+		{arrow.PrimitiveTypes.Int32, 7, "[7 7 7]"},
+		{arrow.PrimitiveTypes.Float64, nil, "[(null) (null) (null)]"},
+		{arrow.BinaryTypes.String, "n/a", `["n/a" "n/a" "n/a"]`},
+		{arrow.PrimitiveTypes.Uint8, -1, `compute: Fill of column "c": value -1 out of range`},
+		{arrow.PrimitiveTypes.Float64, scalar.NewFloat64(1.5), "[1.5 1.5 1.5]"},
+		{arrow.PrimitiveTypes.Int64, scalar.NewInt32(4), "[4 4 4]"},
+		{arrow.PrimitiveTypes.Int64, scalar.Int64{}, "[(null) (null) (null)]"},
+	} {
+		col, err := Fill(pool, arrow.Field{Name: "c", Type: tc.dtype, Nullable: true}, tc.value, 3)
+		if err != nil {
+			if err.Error() != tc.want {
+				t.Errorf("fill %v: got error %q, want %q", tc.value, err, tc.want)
+			}
+			continue
+		}
+		if s := fmt.Sprint(col.Data().Chunk(0)); s != tc.want {
+			t.Errorf("fill %v: got=%s, want=%s", tc.value, s, tc.want)
+		}
+		col.Release()
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/pkg/metadata"
+)
+
+// WithCategories returns field with metadata declaring the categories of its
+// values, from smallest to largest. SortIndices, TopK, MinMax, Rank and the
+// min and max aggregates order the values of such a field as its categories
+// instead of as strings.
+func WithCategories(field arrow.Field, categories []string) arrow.Field {
+	field.Metadata = metadata.WithCategories(field.Metadata, categories)
+	return field
+}
+
+// FieldCategories returns the categories declared in the metadata of field by
+// WithCategories, if any.
+func FieldCategories(field arrow.Field) ([]string, bool) {
+	return metadata.Categories(field.Metadata)
+}
+
+// Categories returns the categories of a dictionary column of strings: the
+// categories declared by its field or, when there are none, its distinct
+// values in lexical order.
+func Categories(col *array.Column) ([]string, error) {
+	if err := checkCategorical(col); err != nil {
+		return nil, err
+	}
+	if categories, ok := FieldCategories(col.Field()); ok {
+		return categories, nil
+	}
+
+	seen := make(map[string]struct{})
+	err := forEachCategoryValue(col.Data().Chunks(), func(_, _ int, v string) error {
+		seen[v] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	categories := make([]string, 0, len(seen))
+	for v := range seen {
+		categories = append(categories, v)
+	}
+	sort.Strings(categories)
+	return categories, nil
+}
+
+// ReorderCategories returns a column sharing the data of a dictionary column
+// of strings, with a field declaring categories. The categories must be
+// distinct and hold every value of col.
+func ReorderCategories(col *array.Column, categories []string) (*array.Column, error) {
+	if err := checkCategorical(col); err != nil {
+		return nil, err
+	}
+	rank, err := categoryRanks(categories)
+	if err != nil {
+		return nil, err
+	}
+	err = forEachCategoryValue(col.Data().Chunks(), func(_, _ int, v string) error {
+		if _, ok := rank[v]; !ok {
+			return fmt.Errorf("compute: value %q of column %q is not a category", v, col.Name())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return array.NewColumn(WithCategories(col.Field(), categories), col.Data()), nil
+}
+
+// AddCategories returns a column sharing the data of a dictionary column of
+// strings, with the given categories declared after the categories of col.
+func AddCategories(col *array.Column, categories ...string) (*array.Column, error) {
+	current, err := Categories(col)
+	if err != nil {
+		return nil, err
+	}
+	all := make([]string, 0, len(current)+len(categories))
+	all = append(all, current...)
+	all = append(all, categories...)
+	return ReorderCategories(col, all)
+}
+
+// checkCategorical checks that col is a dictionary column of strings.
+func checkCategorical(col *array.Column) error {
+	dtype, ok := col.DataType().(*arrow.DictionaryType)
+	if !ok || !arrow.TypeEqual(dtype.ValueType, arrow.BinaryTypes.String) {
+		return fmt.Errorf("compute: column %q of type %s is not a dictionary of strings", col.Name(), col.DataType())
+	}
+	return nil
+}
+
+// categoryRanks returns the position of each of the categories.
+func categoryRanks(categories []string) (map[string]int, error) {
+	rank := make(map[string]int, len(categories))
+	for i, c := range categories {
+		if _, ok := rank[c]; ok {
+			return nil, fmt.Errorf("compute: duplicate category %q", c)
+		}
+		rank[c] = i
+	}
+	return rank, nil
+}
+
+// forEachCategoryValue calls fn with the chunk, the index and the value of
+// each non-null string of chunks, which are strings or dictionaries of
+// strings.
+func forEachCategoryValue(chunks []array.Interface, fn func(c, i int, v string) error) error {
+	for c, chunk := range chunks {
+		var value func(int) string
+		switch arr := chunk.(type) {
+		case *array.String:
+			value = arr.Value
+		case *array.Dictionary:
+			dict, ok := arr.Dictionary().(*array.String)
+			if !ok {
+				return fmt.Errorf("compute: categories not defined for %s", chunk.DataType())
+			}
+			value = func(i int) string { return dict.Value(arr.GetValueIndex(i)) }
+		default:
+			return fmt.Errorf("compute: categories not defined for %s", chunk.DataType())
+		}
+		for i := 0; i < chunk.Len(); i++ {
+			if chunk.IsNull(i) {
+				continue
+			}
+			if err := fn(c, i, value(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// newCategoryComparator returns a comparator ordering the values of the given
+// chunks as categories. Every non-null value must be a category.
+func newCategoryComparator(categories []string, chunks []array.Interface) (valueComparator, error) {
+	rank, err := categoryRanks(categories)
+	if err != nil {
+		return nil, err
+	}
+	ranks := make([][]int, len(chunks))
+	for c, chunk := range chunks {
+		ranks[c] = make([]int, chunk.Len())
+	}
+	err = forEachCategoryValue(chunks, func(c, i int, v string) error {
+		r, ok := rank[v]
+		if !ok {
+			return fmt.Errorf("compute: value %q is not a category", v)
+		}
+		ranks[c][i] = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func(a, b position) int {
+		return compareInt64(int64(ranks[a.chunk][a.index]), int64(ranks[b.chunk][b.index]))
+	}, nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/object"
+)
+
+func TestCategories(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	values := []string{"high", "low", "", "critical", "medium", "low"}
+	valid := []bool{true, true, false, true, true, true}
+	plain := newStringColumn(pool, "severity", values, valid)
+	defer plain.Release()
+	col, err := DictionaryEncode(pool, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer col.Release()
+
+	cats, err := Categories(col)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cats, []string{"critical", "high", "low", "medium"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got categories=%v, want=%v", got, want)
+	}
+
+	// Without declared categories, dictionaries order as their values.
+	sorted, err := SortIndices(pool, []*array.Column{col}, []SortOrder{Ascending})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sorted.String(), "[3 0 1 5 4 2]"; got != want {
+		t.Fatalf("got sort=%s, want=%s", got, want)
+	}
+	sorted.Release()
+
+	if _, err := ReorderCategories(col, []string{"low", "high"}); err == nil {
+		t.Fatal("expected an error for missing categories")
+	}
+	if _, err := ReorderCategories(col, []string{"low", "low", "medium", "high", "critical"}); err == nil {
+		t.Fatal("expected an error for duplicate categories")
+	}
+	if _, err := Categories(plain); err == nil {
+		t.Fatal("expected an error for a column that is not a dictionary")
+	}
+
+	ordered, err := ReorderCategories(col, []string{"low", "medium", "high", "critical"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ordered.Release()
+
+	sorted, err = SortIndices(pool, []*array.Column{ordered}, []SortOrder{Descending})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sorted.String(), "[3 0 4 1 5 2]"; got != want {
+		t.Fatalf("got sort=%s, want=%s", got, want)
+	}
+	sorted.Release()
+
+	top, err := TopK(pool, ordered, 2, Ascending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := top.String(), "[1 5]"; got != want {
+		t.Fatalf("got top=%s, want=%s", got, want)
+	}
+	top.Release()
+
+	min, max, err := MinMax(ordered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if min != object.NewString("low") || max != object.NewString("critical") {
+		t.Fatalf("got min=%v max=%v, want min=low max=critical", min, max)
+	}
+
+	ranks, err := Rank(pool, ordered, RankDense, Ascending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ranks.Data().Chunk(0).(*array.Int64).String(), "[3 1 (null) 4 2 1]"; got != want {
+		t.Fatalf("got ranks=%s, want=%s", got, want)
+	}
+	ranks.Release()
+
+	added, err := AddCategories(ordered, "blocker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer added.Release()
+	cats, err = Categories(added)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cats, []string{"low", "medium", "high", "critical", "blocker"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got categories=%v, want=%v", got, want)
+	}
+	if _, err := AddCategories(added, "low"); err == nil {
+		t.Fatal("expected an error for an existing category")
+	}
+}
@@ -0,0 +1,386 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/scalar"
+)
+
+// CompareOp is a comparison operator of Compare.
+type CompareOp int
+
+const (
+	Equal CompareOp = iota
+	NotEqual
+	Less
+	LessEqual
+	Greater
+	GreaterEqual
+)
+
+func (op CompareOp) String() string {
+	switch op {
+	case Equal:
+		return "="
+	case NotEqual:
+		return "!="
+	case Less:
+		return "<"
+	case LessEqual:
+		return "<="
+	case Greater:
+		return ">"
+	case GreaterEqual:
+		return ">="
+	default:
+		return fmt.Sprintf("CompareOp(%d)", int(op))
+	}
+}
+
+// holds reports whether op holds for values comparing as c.
+func (op CompareOp) holds(c int) bool {
+	switch op {
+	case Equal:
+		return c == 0
+	case NotEqual:
+		return c != 0
+	case Less:
+		return c < 0
+	case LessEqual:
+		return c <= 0
+	case Greater:
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+// Compare compares the values of left with right, row by row, and returns a
+// Boolean column named as left, null where either value is null. right is a
+// column of the same length, a scalar.Scalar, or a bool, integer, float,
+// string, []byte or time.Time value compared with every row.
+//
+// Values of different types are promoted before being compared:
+//   - signed and unsigned integers compare exactly, floats compare with any
+//     number as float64;
+//   - decimals of different scales, and decimals and integers, compare
+//     exactly;
+//   - timestamps and durations of different units compare in the finer unit,
+//     and timestamps compare as instants whatever their time zones;
+//   - strings and binaries compare bytewise;
+//   - dictionaries compare through the values they index.
+//
+// Other types compare with values of the same type only. NaNs are equal to
+// each other and greater than every other number, as ordered by SortIndices.
+func Compare(mem memory.Allocator, left *array.Column, right interface{}, op CompareOp) (*array.Column, error) {
+	if op < Equal || op > GreaterEqual {
+		return nil, fmt.Errorf("compute: unknown comparison %v", op)
+	}
+	if _, ok := left.DataType().(*arrow.DictionaryType); ok {
+		decoded, err := DictionaryDecode(mem, left)
+		if err != nil {
+			return nil, err
+		}
+		defer decoded.Release()
+		left = decoded
+	}
+
+	// next returns the chunk and the index of the next row of right.
+	var next func() (array.Interface, int)
+	var rtype arrow.DataType
+	switch r := right.(type) {
+	case *array.Column:
+		if r.Len() != left.Len() {
+			return nil, fmt.Errorf("compute: cannot compare column %q of %d rows with column %q of %d rows", left.Name(), left.Len(), r.Name(), r.Len())
+		}
+		if _, ok := r.DataType().(*arrow.DictionaryType); ok {
+			decoded, err := DictionaryDecode(mem, r)
+			if err != nil {
+				return nil, err
+			}
+			defer decoded.Release()
+			r = decoded
+		}
+		next, rtype = newRowCursor(r).next, r.DataType()
+	default:
+		s, err := compareScalar(right)
+		if err != nil {
+			return nil, err
+		}
+		arr, err := scalar.MakeArray(mem, s, 1)
+		if err != nil {
+			return nil, err
+		}
+		defer arr.Release()
+		next, rtype = func() (array.Interface, int) { return arr, 0 }, arr.DataType()
+	}
+
+	newCmp, err := newPairComparator(left.DataType(), rtype)
+	if err != nil {
+		return nil, err
+	}
+	bldr := array.NewBooleanBuilder(mem)
+	defer bldr.Release()
+	bldr.Reserve(left.Len())
+	var rchunk array.Interface
+	var cmp func(i, j int) int
+	for _, chunk := range left.Data().Chunks() {
+		for i := 0; i < chunk.Len(); i++ {
+			r, j := next()
+			if chunk.IsNull(i) || r.IsNull(j) {
+				bldr.AppendNull()
+				continue
+			}
+			if cmp == nil || r != rchunk {
+				cmp, rchunk = newCmp(chunk, r), r
+			}
+			bldr.Append(op.holds(cmp(i, j)))
+		}
+		cmp = nil
+	}
+	return newResultColumn(left.Name(), bldr.NewArray()), nil
+}
+
+// compareScalar returns the scalar compared by Compare with every row.
+func compareScalar(v interface{}) (scalar.Scalar, error) {
+	switch v := v.(type) {
+	case scalar.Scalar:
+		return v, nil
+	case bool:
+		return scalar.NewBoolean(v), nil
+	case int:
+		return scalar.NewInt64(int64(v)), nil
+	case int8:
+		return scalar.NewInt64(int64(v)), nil
+	case int16:
+		return scalar.NewInt64(int64(v)), nil
+	case int32:
+		return scalar.NewInt64(int64(v)), nil
+	case int64:
+		return scalar.NewInt64(v), nil
+	case uint:
+		return scalar.NewUint64(uint64(v)), nil
+	case uint8:
+		return scalar.NewUint64(uint64(v)), nil
+	case uint16:
+		return scalar.NewUint64(uint64(v)), nil
+	case uint32:
+		return scalar.NewUint64(uint64(v)), nil
+	case uint64:
+		return scalar.NewUint64(v), nil
+	case float32:
+		return scalar.NewFloat64(float64(v)), nil
+	case float64:
+		return scalar.NewFloat64(v), nil
+	case string:
+		return scalar.NewString(v), nil
+	case []byte:
+		// Strings and binaries compare bytewise.
+		return scalar.NewString(string(v)), nil
+	case time.Time:
+		return scalar.NewTimestamp(arrow.Timestamp(v.UnixNano()), &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}), nil
+	default:
+		return nil, fmt.Errorf("compute: cannot compare with a value of type %T", v)
+	}
+}
+
+// pairComparator returns a function comparing the value at index i of the
+// chunk l with the value at index j of the chunk r, returning -1, 0 or +1.
+type pairComparator func(l, r array.Interface) func(i, j int) int
+
+// newPairComparator returns a pairComparator for chunks of types lt and rt.
+func newPairComparator(lt, rt arrow.DataType) (pairComparator, error) {
+	lc, rc := classOf(lt), classOf(rt)
+	switch {
+	case lc == signedClass && rc == signedClass:
+		return func(l, r array.Interface) func(i, j int) int {
+			lget, rget := int64Getter(l), int64Getter(r)
+			return func(i, j int) int { return compareInt64(lget(i), rget(j)) }
+		}, nil
+
+	case lc == unsignedClass && rc == unsignedClass:
+		return func(l, r array.Interface) func(i, j int) int {
+			lget, rget := uint64Getter(l), uint64Getter(r)
+			return func(i, j int) int { return compareUint64(lget(i), rget(j)) }
+		}, nil
+
+	case lc == signedClass && rc == unsignedClass:
+		return func(l, r array.Interface) func(i, j int) int {
+			lget, rget := int64Getter(l), uint64Getter(r)
+			return func(i, j int) int { return compareSignedUnsigned(lget(i), rget(j)) }
+		}, nil
+
+	case lc == unsignedClass && rc == signedClass:
+		return func(l, r array.Interface) func(i, j int) int {
+			lget, rget := uint64Getter(l), int64Getter(r)
+			return func(i, j int) int { return -compareSignedUnsigned(rget(j), lget(i)) }
+		}, nil
+
+	case (lc == floatClass || rc == floatClass) && isNumber(lt) && isNumber(rt):
+		return func(l, r array.Interface) func(i, j int) int {
+			lget, rget := float64Reader(l), float64Reader(r)
+			return func(i, j int) int { return compareFloat64(lget(i), rget(j)) }
+		}, nil
+
+	case (lt.ID() == arrow.DECIMAL || rt.ID() == arrow.DECIMAL) && isNumber(lt) && isNumber(rt):
+		// The side of the smaller scale is rescaled to the larger one.
+		lscale, rscale := decimalScale(lt), decimalScale(rt)
+		lmul, rmul := big.NewInt(1), big.NewInt(1)
+		if lscale < rscale {
+			lmul.Exp(big.NewInt(10), big.NewInt(int64(rscale-lscale)), nil)
+		} else {
+			rmul.Exp(big.NewInt(10), big.NewInt(int64(lscale-rscale)), nil)
+		}
+		return func(l, r array.Interface) func(i, j int) int {
+			lget, rget := bigIntReader(l), bigIntReader(r)
+			return func(i, j int) int {
+				a, b := lget(i), rget(j)
+				return a.Mul(a, lmul).Cmp(b.Mul(b, rmul))
+			}
+		}, nil
+
+	case lt.ID() == rt.ID() && (lt.ID() == arrow.TIMESTAMP || lt.ID() == arrow.DURATION):
+		lunit, runit := timeUnit(lt), timeUnit(rt)
+		unit := finerUnit(lunit, runit)
+		lmul := unitNanoseconds(lunit) / unitNanoseconds(unit)
+		rmul := unitNanoseconds(runit) / unitNanoseconds(unit)
+		return func(l, r array.Interface) func(i, j int) int {
+			lget, rget := int64Getter(l), int64Getter(r)
+			return func(i, j int) int { return compareInt64(lget(i)*lmul, rget(j)*rmul) }
+		}, nil
+
+	case isBytes(lt) && isBytes(rt):
+		return func(l, r array.Interface) func(i, j int) int {
+			return func(i, j int) int { return bytes.Compare(bytesValue(l, i), bytesValue(r, j)) }
+		}, nil
+	}
+
+	if !arrow.TypeEqual(lt, rt) {
+		return nil, fmt.Errorf("compute: cannot compare %s with %s", lt, rt)
+	}
+	// Other values of the same type are compared as they are sorted.
+	if err := checkOrdered(lt); err != nil {
+		return nil, err
+	}
+	return func(l, r array.Interface) func(i, j int) int {
+		cmp, _ := newValueComparator([]array.Interface{l, r})
+		return func(i, j int) int {
+			return cmp(position{chunk: 0, index: i}, position{chunk: 1, index: j})
+		}
+	}, nil
+}
+
+// checkOrdered returns an error if the values of dtype have no ordering.
+func checkOrdered(dtype arrow.DataType) error {
+	bldr := array.NewBuilder(memory.NewGoAllocator(), dtype)
+	defer bldr.Release()
+	arr := bldr.NewArray()
+	defer arr.Release()
+	_, err := newValueComparator([]array.Interface{arr})
+	return err
+}
+
+// compareSignedUnsigned compares a signed integer with an unsigned one.
+func compareSignedUnsigned(s int64, u uint64) int {
+	if s < 0 {
+		return -1
+	}
+	return compareUint64(uint64(s), u)
+}
+
+func isNumber(dtype arrow.DataType) bool {
+	return classOf(dtype) != otherClass || dtype.ID() == arrow.DECIMAL
+}
+
+func isBytes(dtype arrow.DataType) bool {
+	switch dtype.ID() {
+	case arrow.STRING, arrow.BINARY:
+		return true
+	default:
+		return false
+	}
+}
+
+func bytesValue(arr array.Interface, i int) []byte {
+	switch a := arr.(type) {
+	case *array.String:
+		return []byte(a.Value(i))
+	default:
+		return arr.(*array.Binary).Value(i)
+	}
+}
+
+func timeUnit(dtype arrow.DataType) arrow.TimeUnit {
+	switch t := dtype.(type) {
+	case *arrow.TimestampType:
+		return t.Unit
+	case *arrow.DurationType:
+		return t.Unit
+	default:
+		return arrow.Nanosecond
+	}
+}
+
+// float64Reader returns an accessor converting the numeric values of arr,
+// decimals included, to float64.
+func float64Reader(arr array.Interface) func(int) float64 {
+	if d, ok := arr.(*array.Decimal128); ok {
+		div := math.Pow10(int(decimalScale(d.DataType())))
+		get := bigIntReader(d)
+		return func(i int) float64 {
+			f, _ := new(big.Float).SetInt(get(i)).Float64()
+			return f / div
+		}
+	}
+	get, _ := numberGetter(arr)
+	return get
+}
+
+// bigIntReader returns an accessor reading the values of arr, decimals or
+// integers, as unscaled integers.
+func bigIntReader(arr array.Interface) func(int) *big.Int {
+	switch classOf(arr.DataType()) {
+	case signedClass:
+		get := int64Getter(arr)
+		return func(i int) *big.Int { return big.NewInt(get(i)) }
+	case unsignedClass:
+		get := uint64Getter(arr)
+		return func(i int) *big.Int { return new(big.Int).SetUint64(get(i)) }
+	}
+	d := arr.(*array.Decimal128)
+	return func(i int) *big.Int {
+		v := d.Value(i)
+		n := big.NewInt(v.HighBits())
+		n.Lsh(n, 64)
+		return n.Add(n, new(big.Int).SetUint64(v.LowBits()))
+	}
+}
+
+// decimalScale returns the scale of the values of type dtype, 0 for integers.
+func decimalScale(dtype arrow.DataType) int32 {
+	if d, ok := dtype.(*arrow.Decimal128Type); ok {
+		return d.Scale
+	}
+	return 0
+}
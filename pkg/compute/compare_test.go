@@ -0,0 +1,113 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/decimal128"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestCompare(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	build := func(name string, bldr array.Builder, fill func()) *array.Column {
+		defer bldr.Release()
+		fill()
+		return newSingleChunkColumn(name, bldr.NewArray())
+	}
+	release := func(cols ...*array.Column) {
+		for _, col := range cols {
+			col.Data().Chunk(0).Release()
+			col.Release()
+		}
+	}
+
+	// Signed integers in two chunks, to walk chunks of different layouts.
+	ints := newInt64Column(pool, "i", []int64{-1, 2}, []int64{3, math.MaxInt64})
+	defer ints.Release()
+	ub := array.NewUint64Builder(pool)
+	uints := build("u", ub, func() { ub.AppendValues([]uint64{1, 2, 4, math.MaxUint64}, nil) })
+	fb := array.NewFloat64Builder(pool)
+	floats := build("f", fb, func() { fb.AppendValues([]float64{-1, 2.5, math.NaN(), 0}, []bool{true, true, true, false}) })
+	d2b := array.NewDecimal128Builder(pool, &arrow.Decimal128Type{Precision: 10, Scale: 2})
+	dec2 := build("d2", d2b, func() {
+		d2b.AppendValues([]decimal128.Num{decimal128.FromI64(-100), decimal128.FromI64(250), decimal128.FromI64(300), decimal128.FromI64(1)}, nil)
+	})
+	d1b := array.NewDecimal128Builder(pool, &arrow.Decimal128Type{Precision: 10, Scale: 1})
+	dec1 := build("d1", d1b, func() {
+		d1b.AppendValues([]decimal128.Num{decimal128.FromI64(-10), decimal128.FromI64(20), decimal128.FromI64(30), decimal128.FromI64(0)}, nil)
+	})
+	sb := array.NewTimestampBuilder(pool, &arrow.TimestampType{Unit: arrow.Second})
+	secs := build("s", sb, func() { sb.AppendValues([]arrow.Timestamp{1, 2, 3, 4}, nil) })
+	mb := array.NewTimestampBuilder(pool, &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "Europe/Paris"})
+	millis := build("ms", mb, func() { mb.AppendValues([]arrow.Timestamp{1000, 2500, 2999, 4000}, nil) })
+	defer release(uints, floats, dec2, dec1, secs, millis)
+
+	strs := newStringColumn(pool, "str", []string{"a", "b", "", "d"}, []bool{true, true, false, true})
+	defer strs.Release()
+	dict, err := DictionaryEncode(pool, strs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dict.Release()
+
+	for _, tc := range []struct {
+		left  *array.Column
+		right interface{}
+		op    CompareOp
+		want  string
+	}{
+		{ints, uints, Less, "[true false true true]"},
+		{uints, ints, Greater, "[true false true true]"},
+		{ints, 2, Equal, "[false true false false]"},
+		{ints, 2.5, GreaterEqual, "[false false true true]"},
+		{floats, ints, LessEqual, "[true false false (null)]"},
+		{floats, math.NaN(), Equal, "[false false true (null)]"},
+		{dec2, dec1, Equal, "[true false true false]"},
+		{dec2, ints, Less, "[false false false true]"},
+		{dec2, 2.5, Equal, "[false true false false]"},
+		{secs, millis, Less, "[false true false false]"},
+		{millis, time.Unix(3, 0), LessEqual, "[true true true false]"},
+		{dict, "b", GreaterEqual, "[false true (null) true]"},
+		{strs, dict, Equal, "[true true (null) true]"},
+		{strs, []byte("b"), NotEqual, "[true false (null) true]"},
+	} {
+		got, err := Compare(pool, tc.left, tc.right, tc.op)
+		if err != nil {
+			t.Fatalf("%s %v %T: %v", tc.left.Name(), tc.op, tc.right, err)
+		}
+		if s := fmt.Sprint(got.Data().Chunk(0)); s != tc.want {
+			t.Errorf("%s %v %T: got=%s, want=%s", tc.left.Name(), tc.op, tc.right, s, tc.want)
+		}
+		got.Release()
+	}
+
+	if _, err := Compare(pool, strs, ints, Equal); err == nil {
+		t.Fatal("expected an error comparing strings with integers")
+	}
+	short := ints.NewSlice(0, 2)
+	defer short.Release()
+	if _, err := Compare(pool, ints, short, Equal); err == nil {
+		t.Fatal("expected an error comparing columns of different lengths")
+	}
+}
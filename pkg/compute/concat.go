@@ -0,0 +1,131 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// Concat appends the rows of cols, in order, into a single chunk Column named
+// after the first column. All the columns must have the same type.
+//
+// When there is more than one row and every row holds the same value, or every
+// row is null, the result is run-end encoded as a single run.
+// Run-end encoded columns otherwise stay encoded.
+func Concat(mem memory.Allocator, cols ...*array.Column) (*array.Column, error) {
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("compute: Concat needs at least one column")
+	}
+	dtype := cols[0].DataType()
+	var chunks []array.Interface
+	for _, col := range cols {
+		if !arrow.TypeEqual(col.DataType(), dtype) {
+			return nil, fmt.Errorf("compute: Concat column types differ (%s != %s)", col.DataType(), dtype)
+		}
+		chunks = append(chunks, col.Data().Chunks()...)
+	}
+	field := cols[0].Field()
+
+	if arr, rows, ok := constantValue(chunks); ok {
+		return concatConstant(mem, field, arr, rows)
+	}
+
+	if ree, ok := dtype.(*arrow.RunEndEncodedType); ok {
+		runs := newRunAppender(mem, ree.ValueType)
+		defer runs.release()
+		for _, chunk := range chunks {
+			for i := 0; i < chunk.Len(); i++ {
+				if err := runs.append(chunk.(*array.RunEndEncoded), i); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return runs.newColumn(field), nil
+	}
+
+	bldr := array.NewBuilder(mem, dtype)
+	defer bldr.Release()
+	for _, chunk := range chunks {
+		for i := 0; i < chunk.Len(); i++ {
+			if err := AppendValue(bldr, chunk, i); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return newColumnFromBuilder(field, bldr), nil
+}
+
+// constantValue reports whether there is more than one row in chunks and all
+// the rows hold the same value, or are all null. It returns the chunk holding
+// the first row and the number of rows.
+func constantValue(chunks []array.Interface) (array.Interface, int, bool) {
+	var (
+		first     array.Interface
+		firstNull bool
+		want      interface{}
+		rows      int
+	)
+	for _, chunk := range chunks {
+		if chunk.Len() == 0 {
+			continue
+		}
+		key, err := keyGetter(chunk)
+		if err != nil {
+			return nil, 0, false
+		}
+		for i := 0; i < chunk.Len(); i++ {
+			null := chunk.IsNull(i)
+			if first == nil {
+				first, firstNull = chunk, null
+				if !null {
+					want = key(i)
+				}
+				continue
+			}
+			if null != firstNull || (!null && key(i) != want) {
+				return nil, 0, false
+			}
+		}
+		rows += chunk.Len()
+	}
+	return first, rows, rows > 1
+}
+
+// concatConstant returns a run-end encoded column made of a single run of
+// the given number of rows, repeating the first value of arr.
+func concatConstant(mem memory.Allocator, field arrow.Field, arr array.Interface, rows int) (*array.Column, error) {
+	values, i := arr, 0
+	if ree, ok := arr.(*array.RunEndEncoded); ok {
+		values, i = ree.Values(), ree.GetPhysicalIndex(0)
+	}
+
+	bldr := array.NewRunEndEncodedBuilder(mem, values.DataType())
+	defer bldr.Release()
+
+	if values.IsNull(i) {
+		bldr.AppendNull()
+		bldr.ContinueRun(rows - 1)
+	} else {
+		bldr.Append(rows)
+		if err := AppendValue(bldr.ValueBuilder(), values, i); err != nil {
+			return nil, err
+		}
+	}
+	return newColumnFromBuilder(field, bldr), nil
+}
@@ -0,0 +1,328 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// NullHandling selects how the cumulative kernels treat nulls.
+type NullHandling int
+
+const (
+	// SkipNulls leaves the rows holding nulls null and accumulates over the
+	// other rows.
+	SkipNulls NullHandling = iota
+	// PropagateNulls makes every row from the first null on null.
+	PropagateNulls
+)
+
+// cumulativeOp combines the running value with the next value, for each of
+// the widened types of the numeric columns.
+type cumulativeOp struct {
+	name string
+	i    func(acc, v int64) int64
+	u    func(acc, v uint64) uint64
+	f    func(acc, v float64) float64
+}
+
+var (
+	cumSum = cumulativeOp{
+		name: "CumSum",
+		i:    func(acc, v int64) int64 { return acc + v },
+		u:    func(acc, v uint64) uint64 { return acc + v },
+		f:    func(acc, v float64) float64 { return acc + v },
+	}
+	cumProd = cumulativeOp{
+		name: "CumProd",
+		i:    func(acc, v int64) int64 { return acc * v },
+		u:    func(acc, v uint64) uint64 { return acc * v },
+		f:    func(acc, v float64) float64 { return acc * v },
+	}
+	cumMin = cumulativeOp{
+		name: "CumMin",
+		i: func(acc, v int64) int64 {
+			if v < acc {
+				return v
+			}
+			return acc
+		},
+		u: func(acc, v uint64) uint64 {
+			if v < acc {
+				return v
+			}
+			return acc
+		},
+		f: math.Min,
+	}
+	cumMax = cumulativeOp{
+		name: "CumMax",
+		i: func(acc, v int64) int64 {
+			if v > acc {
+				return v
+			}
+			return acc
+		},
+		u: func(acc, v uint64) uint64 {
+			if v > acc {
+				return v
+			}
+			return acc
+		},
+		f: math.Max,
+	}
+)
+
+// CumSum returns the running sums of the numeric column col. Signed integers
+// accumulate as int64, unsigned integers as uint64 and floats as float64,
+// integers wrap around on overflow.
+func CumSum(mem memory.Allocator, col *array.Column, nulls NullHandling) (*array.Column, error) {
+	return cumulative(mem, col, cumSum, nulls)
+}
+
+// CumProd returns the running products of the numeric column col, see CumSum.
+func CumProd(mem memory.Allocator, col *array.Column, nulls NullHandling) (*array.Column, error) {
+	return cumulative(mem, col, cumProd, nulls)
+}
+
+// CumMin returns the running minimums of the numeric column col, see CumSum.
+func CumMin(mem memory.Allocator, col *array.Column, nulls NullHandling) (*array.Column, error) {
+	return cumulative(mem, col, cumMin, nulls)
+}
+
+// CumMax returns the running maximums of the numeric column col, see CumSum.
+func CumMax(mem memory.Allocator, col *array.Column, nulls NullHandling) (*array.Column, error) {
+	return cumulative(mem, col, cumMax, nulls)
+}
+
+func cumulative(mem memory.Allocator, col *array.Column, op cumulativeOp, nulls NullHandling) (*array.Column, error) {
+	// bind returns the function appending the running value at row i of chunk.
+	var (
+		bldr array.Builder
+		bind func(chunk array.Interface) func(i int, first bool)
+	)
+	switch classOf(col.DataType()) {
+	case signedClass:
+		b := array.NewInt64Builder(mem)
+		var acc int64
+		bind = func(chunk array.Interface) func(int, bool) {
+			get := int64Getter(chunk)
+			return func(i int, first bool) {
+				if v := get(i); first {
+					acc = v
+				} else {
+					acc = op.i(acc, v)
+				}
+				b.Append(acc)
+			}
+		}
+		bldr = b
+	case unsignedClass:
+		b := array.NewUint64Builder(mem)
+		var acc uint64
+		bind = func(chunk array.Interface) func(int, bool) {
+			get := uint64Getter(chunk)
+			return func(i int, first bool) {
+				if v := get(i); first {
+					acc = v
+				} else {
+					acc = op.u(acc, v)
+				}
+				b.Append(acc)
+			}
+		}
+		bldr = b
+	case floatClass:
+		b := array.NewFloat64Builder(mem)
+		var acc float64
+		bind = func(chunk array.Interface) func(int, bool) {
+			get := float64Getter(chunk)
+			return func(i int, first bool) {
+				if v := get(i); first {
+					acc = v
+				} else {
+					acc = op.f(acc, v)
+				}
+				b.Append(acc)
+			}
+		}
+		bldr = b
+	default:
+		return nil, fmt.Errorf("compute: %s of %s is not supported", op.name, col.DataType())
+	}
+	defer bldr.Release()
+
+	bldr.Reserve(col.Len())
+	first, broken := true, false
+	for _, chunk := range col.Data().Chunks() {
+		add := bind(chunk)
+		for i := 0; i < chunk.Len(); i++ {
+			if broken || chunk.IsNull(i) {
+				broken = nulls == PropagateNulls
+				bldr.AppendNull()
+				continue
+			}
+			add(i, first)
+			first = false
+		}
+	}
+	return newResultColumn(col.Name(), bldr.NewArray()), nil
+}
+
+// Diff returns the differences between every row of the numeric column col and
+// the row periods rows before it, or after it when periods is negative. Rows
+// without a counterpart or where either value is null are null. Integers give
+// int64 differences and floats float64 differences.
+func Diff(mem memory.Allocator, col *array.Column, periods int) (*array.Column, error) {
+	chunks := col.Data().Chunks()
+	var (
+		bldr array.Builder
+		diff func(a, i, b, j int)
+	)
+	switch classOf(col.DataType()) {
+	case signedClass:
+		b := array.NewInt64Builder(mem)
+		gets := make([]func(int) int64, len(chunks))
+		for k, chunk := range chunks {
+			gets[k] = int64Getter(chunk)
+		}
+		diff = func(x, i, y, j int) { b.Append(gets[x](i) - gets[y](j)) }
+		bldr = b
+	case unsignedClass:
+		b := array.NewInt64Builder(mem)
+		gets := make([]func(int) uint64, len(chunks))
+		for k, chunk := range chunks {
+			gets[k] = uint64Getter(chunk)
+		}
+		diff = func(x, i, y, j int) { b.Append(int64(gets[x](i) - gets[y](j))) }
+		bldr = b
+	case floatClass:
+		b := array.NewFloat64Builder(mem)
+		gets := make([]func(int) float64, len(chunks))
+		for k, chunk := range chunks {
+			gets[k] = float64Getter(chunk)
+		}
+		diff = func(x, i, y, j int) { b.Append(gets[x](i) - gets[y](j)) }
+		bldr = b
+	default:
+		return nil, fmt.Errorf("compute: Diff of %s is not supported", col.DataType())
+	}
+	defer bldr.Release()
+
+	locate := newChunkLocator(chunks)
+	n := col.Len()
+	bldr.Reserve(n)
+	for row := 0; row < n; row++ {
+		other := row - periods
+		if other < 0 || other >= n {
+			bldr.AppendNull()
+			continue
+		}
+		x, i, err := locate(int64(row))
+		if err != nil {
+			return nil, err
+		}
+		y, j, err := locate(int64(other))
+		if err != nil {
+			return nil, err
+		}
+		if chunks[x].IsNull(i) || chunks[y].IsNull(j) {
+			bldr.AppendNull()
+			continue
+		}
+		diff(x, i, y, j)
+	}
+	return newResultColumn(col.Name(), bldr.NewArray()), nil
+}
+
+// Shift returns col with its rows moved n rows down, or up when n is negative.
+// The rows left without a value hold fill, cast to the type of col, or null
+// when fill is nil.
+func Shift(mem memory.Allocator, col *array.Column, n int, fill interface{}) (*array.Column, error) {
+	rows := col.Len()
+	if fill == nil {
+		bldr := array.NewInt64Builder(mem)
+		defer bldr.Release()
+		bldr.Reserve(rows)
+		for row := 0; row < rows; row++ {
+			if src := row - n; src >= 0 && src < rows {
+				bldr.Append(int64(src))
+			} else {
+				bldr.AppendNull()
+			}
+		}
+		indices := bldr.NewInt64Array()
+		defer indices.Release()
+		return Take(mem, col, indices)
+	}
+
+	bldr := array.NewBuilder(mem, col.DataType())
+	defer bldr.Release()
+	write, err := castWriter(bldr, col.DataType())
+	if err != nil {
+		return nil, fmt.Errorf("compute: Shift of %s with a fill value is not supported", col.DataType())
+	}
+	value, err := fillValue(fill)
+	if err != nil {
+		return nil, fmt.Errorf("compute: Shift: %w", err)
+	}
+
+	locate := newChunkLocator(col.Data().Chunks())
+	bldr.Reserve(rows)
+	for row := 0; row < rows; row++ {
+		src := row - n
+		if src < 0 || src >= rows {
+			if err := write(value); err != nil {
+				return nil, fmt.Errorf("compute: Shift fill value %v: %w", fill, err)
+			}
+			continue
+		}
+		c, i, err := locate(int64(src))
+		if err != nil {
+			return nil, err
+		}
+		if err := AppendValue(bldr, col.Data().Chunk(c), i); err != nil {
+			return nil, err
+		}
+	}
+	return newColumnFromBuilder(col.Field(), bldr), nil
+}
+
+type numberClass int
+
+const (
+	otherClass numberClass = iota
+	signedClass
+	unsignedClass
+	floatClass
+)
+
+func classOf(dtype arrow.DataType) numberClass {
+	switch dtype.ID() {
+	case arrow.INT8, arrow.INT16, arrow.INT32, arrow.INT64:
+		return signedClass
+	case arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64:
+		return unsignedClass
+	case arrow.FLOAT16, arrow.FLOAT32, arrow.FLOAT64:
+		return floatClass
+	default:
+		return otherClass
+	}
+}
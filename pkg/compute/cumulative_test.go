@@ -0,0 +1,102 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestCumulative(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	ints := newInt64Column(pool, "i", []int64{3, -1}, []int64{4, 2})
+	defer ints.Release()
+	floats := newFloat64Column(pool, "f", []float64{1.5, 0, 2, 0.5}, []bool{true, false, true, true})
+	defer floats.Release()
+	strs := newStringColumn(pool, "s", []string{"a"}, nil)
+	defer strs.Release()
+
+	type kernel func(memory.Allocator, *array.Column, NullHandling) (*array.Column, error)
+	for _, tc := range []struct {
+		name  string
+		fn    kernel
+		col   *array.Column
+		nulls NullHandling
+		want  string
+	}{
+		{"CumSum", CumSum, ints, SkipNulls, "[3 2 6 8]"},
+		{"CumProd", CumProd, ints, SkipNulls, "[3 -3 -12 -24]"},
+		{"CumMin", CumMin, ints, SkipNulls, "[3 -1 -1 -1]"},
+		{"CumMax", CumMax, ints, SkipNulls, "[3 3 4 4]"},
+		{"CumSum", CumSum, floats, SkipNulls, "[1.5 (null) 3.5 4]"},
+		{"CumSum", CumSum, floats, PropagateNulls, "[1.5 (null) (null) (null)]"},
+		{"CumMax", CumMax, floats, SkipNulls, "[1.5 (null) 2 2]"},
+		{"CumSum", CumSum, strs, SkipNulls, "compute: CumSum of utf8 is not supported"},
+	} {
+		got, err := tc.fn(pool, tc.col, tc.nulls)
+		if err != nil {
+			if err.Error() != tc.want {
+				t.Errorf("%s(%s): got error %q, want %q", tc.name, tc.col.Name(), err, tc.want)
+			}
+			continue
+		}
+		if s := fmt.Sprint(got.Data().Chunk(0)); s != tc.want {
+			t.Errorf("%s(%s): got %s, want %s", tc.name, tc.col.Name(), s, tc.want)
+		}
+		got.Release()
+	}
+}
+
+func TestDiffShift(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	ints := newInt64Column(pool, "i", []int64{1, 4}, []int64{9, 16})
+	defer ints.Release()
+	strs := newStringColumn(pool, "s", []string{"a", "b", "c"}, []bool{true, false, true})
+	defer strs.Release()
+
+	for _, tc := range []struct {
+		name string
+		fn   func() (*array.Column, error)
+		want string
+	}{
+		{"diff 1", func() (*array.Column, error) { return Diff(pool, ints, 1) }, "[(null) 3 5 7]"},
+		{"diff 2", func() (*array.Column, error) { return Diff(pool, ints, 2) }, "[(null) (null) 8 12]"},
+		{"diff -1", func() (*array.Column, error) { return Diff(pool, ints, -1) }, "[-3 -5 -7 (null)]"},
+		{"diff strings", func() (*array.Column, error) { return Diff(pool, strs, 1) }, "compute: Diff of utf8 is not supported"},
+		{"shift 1", func() (*array.Column, error) { return Shift(pool, ints, 1, nil) }, "[(null) 1 4 9]"},
+		{"shift -3 fill", func() (*array.Column, error) { return Shift(pool, ints, -3, 0) }, "[16 0 0 0]"},
+		{"shift strings fill", func() (*array.Column, error) { return Shift(pool, strs, 1, "z") }, `["z" "a" (null)]`},
+		{"shift bad fill", func() (*array.Column, error) { return Shift(pool, ints, 1, "x") }, `compute: Shift fill value x: strconv.ParseInt: parsing "x": invalid syntax`},
+	} {
+		got, err := tc.fn()
+		if err != nil {
+			if err.Error() != tc.want {
+				t.Errorf("%s: got error %q, want %q", tc.name, err, tc.want)
+			}
+			continue
+		}
+		if s := fmt.Sprint(got.Data().Chunk(0)); s != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, s, tc.want)
+		}
+		got.Release()
+	}
+}
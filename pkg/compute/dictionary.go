@@ -0,0 +1,263 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// DictionaryEncode encodes col as a dictionary column with int32 indices.
+// All the chunks of the result share a single dictionary holding the distinct
+// values of col in order of first appearance. Nulls are kept as null indices.
+func DictionaryEncode(mem memory.Allocator, col *array.Column) (*array.Column, error) {
+	if _, ok := col.DataType().(*arrow.DictionaryType); ok {
+		return array.NewColumn(col.Field(), col.Data()), nil
+	}
+
+	chunks := col.Data().Chunks()
+	enc := newColumnEncoder()
+
+	dictBldr := array.NewBuilder(mem, col.DataType())
+	defer dictBldr.Release()
+
+	indices := make([]array.Interface, 0, len(chunks))
+	defer func() {
+		for _, arr := range indices {
+			arr.Release()
+		}
+	}()
+
+	for _, chunk := range chunks {
+		codes, err := enc.encode(chunk, false)
+		if err != nil {
+			return nil, fmt.Errorf("compute: cannot dictionary encode %s: %w", col.DataType(), err)
+		}
+
+		bldr := array.NewInt32Builder(mem)
+		bldr.Reserve(len(codes))
+		for i, code := range codes {
+			if code < 0 {
+				bldr.AppendNull()
+				continue
+			}
+			if int(code) == dictBldr.Len() {
+				// First appearance of this value.
+				if err := AppendValue(dictBldr, chunk, i); err != nil {
+					bldr.Release()
+					return nil, err
+				}
+			}
+			bldr.Append(code)
+		}
+		indices = append(indices, bldr.NewArray())
+		bldr.Release()
+	}
+
+	dict := dictBldr.NewArray()
+	defer dict.Release()
+
+	dtype := &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: col.DataType()}
+	arrs := make([]array.Interface, len(indices))
+	for i, idx := range indices {
+		arrs[i] = array.NewDictionaryArray(dtype, idx, dict)
+	}
+	defer func() {
+		for _, arr := range arrs {
+			arr.Release()
+		}
+	}()
+
+	chunked := array.NewChunked(dtype, arrs)
+	defer chunked.Release()
+
+	field := col.Field()
+	field.Type = dtype
+	return array.NewColumn(field, chunked), nil
+}
+
+// DictionaryDecode converts a dictionary column back to a column of its value type.
+func DictionaryDecode(mem memory.Allocator, col *array.Column) (*array.Column, error) {
+	dtype, ok := col.DataType().(*arrow.DictionaryType)
+	if !ok {
+		return nil, fmt.Errorf("compute: DictionaryDecode expects a dictionary column, got %s", col.DataType())
+	}
+
+	arrs := make([]array.Interface, 0, len(col.Data().Chunks()))
+	defer func() {
+		for _, arr := range arrs {
+			arr.Release()
+		}
+	}()
+
+	for _, chunk := range col.Data().Chunks() {
+		dict := chunk.(*array.Dictionary)
+		bldr := array.NewBuilder(mem, dtype.ValueType)
+		bldr.Reserve(dict.Len())
+		for i := 0; i < dict.Len(); i++ {
+			if dict.IsNull(i) {
+				bldr.AppendNull()
+				continue
+			}
+			if err := AppendValue(bldr, dict.Dictionary(), dict.GetValueIndex(i)); err != nil {
+				bldr.Release()
+				return nil, err
+			}
+		}
+		arrs = append(arrs, bldr.NewArray())
+		bldr.Release()
+	}
+
+	chunked := array.NewChunked(dtype.ValueType, arrs)
+	defer chunked.Release()
+
+	field := col.Field()
+	field.Type = dtype.ValueType
+	return array.NewColumn(field, chunked), nil
+}
+
+// takeDictionary is Take for dictionary columns. The indices of the result
+// reference a single dictionary: the dictionary of col when all the chunks
+// share one, otherwise the unified dictionary of all the chunks.
+func takeDictionary(mem memory.Allocator, col *array.Column, indices *array.Int64) (*array.Column, error) {
+	dtype := col.DataType().(*arrow.DictionaryType)
+	chunks := col.Data().Chunks()
+	locate := newChunkLocator(chunks)
+
+	dict, remaps, err := unifyDictionaries(mem, chunks)
+	if err != nil {
+		return nil, err
+	}
+	defer dict.Release()
+
+	bldr := array.NewBuilder(mem, dtype.IndexType)
+	defer bldr.Release()
+	bldr.Reserve(indices.Len())
+
+	for i := 0; i < indices.Len(); i++ {
+		if indices.IsNull(i) {
+			bldr.AppendNull()
+			continue
+		}
+		c, j, err := locate(indices.Value(i))
+		if err != nil {
+			return nil, err
+		}
+		arr := chunks[c].(*array.Dictionary)
+		if arr.IsNull(j) {
+			bldr.AppendNull()
+			continue
+		}
+		idx := int64(arr.GetValueIndex(j))
+		if remaps != nil {
+			if idx = int64(remaps[c][idx]); idx < 0 {
+				// The dictionary value itself is null.
+				bldr.AppendNull()
+				continue
+			}
+		}
+		if err := appendIndex(bldr, idx); err != nil {
+			return nil, err
+		}
+	}
+
+	idx := bldr.NewArray()
+	defer idx.Release()
+
+	arr := array.NewDictionaryArray(dtype, idx, dict)
+	defer arr.Release()
+
+	chunked := array.NewChunked(dtype, []array.Interface{arr})
+	defer chunked.Release()
+
+	return array.NewColumn(col.Field(), chunked), nil
+}
+
+// unifyDictionaries returns a dictionary holding the values of the dictionaries
+// of all the chunks. When every chunk uses the same dictionary it is returned
+// as is, with nil remaps. Otherwise remaps[c][i] is the index in the unified
+// dictionary of the i-th value of the dictionary of chunk c.
+func unifyDictionaries(mem memory.Allocator, chunks []array.Interface) (array.Interface, [][]int32, error) {
+	var first array.Interface
+	shared := true
+	for _, chunk := range chunks {
+		dict := chunk.(*array.Dictionary).Dictionary()
+		if first == nil {
+			first = dict
+			continue
+		}
+		if dict.Data() != first.Data() {
+			shared = false
+		}
+	}
+	if first != nil && shared {
+		first.Retain()
+		return first, nil, nil
+	}
+	if first == nil {
+		return nil, nil, fmt.Errorf("compute: cannot unify the dictionaries of an empty column")
+	}
+
+	enc := newColumnEncoder()
+	bldr := array.NewBuilder(mem, first.DataType())
+	defer bldr.Release()
+
+	remaps := make([][]int32, len(chunks))
+	for c, chunk := range chunks {
+		dict := chunk.(*array.Dictionary).Dictionary()
+		codes, err := enc.encode(dict, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i, code := range codes {
+			if code >= 0 && int(code) == bldr.Len() {
+				if err := AppendValue(bldr, dict, i); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+		remaps[c] = codes
+	}
+
+	return bldr.NewArray(), remaps, nil
+}
+
+// appendIndex appends a dictionary index to an integer builder.
+func appendIndex(bldr array.Builder, idx int64) error {
+	switch b := bldr.(type) {
+	case *array.Int8Builder:
+		b.Append(int8(idx))
+	case *array.Int16Builder:
+		b.Append(int16(idx))
+	case *array.Int32Builder:
+		b.Append(int32(idx))
+	case *array.Int64Builder:
+		b.Append(idx)
+	case *array.Uint8Builder:
+		b.Append(uint8(idx))
+	case *array.Uint16Builder:
+		b.Append(uint16(idx))
+	case *array.Uint32Builder:
+		b.Append(uint32(idx))
+	case *array.Uint64Builder:
+		b.Append(uint64(idx))
+	default:
+		return fmt.Errorf("compute: invalid dictionary index builder %T", bldr)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func newStringColumn(mem memory.Allocator, name string, values []string, valid []bool) *array.Column {
+	b := array.NewStringBuilder(mem)
+	defer b.Release()
+	b.AppendValues(values, valid)
+	arr := b.NewArray()
+	defer arr.Release()
+	return newSingleChunkColumn(name, arr)
+}
+
+func TestDictionaryEncodeDecode(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	col := newStringColumn(pool, "s", []string{"b", "a", "", "b", "c"}, []bool{true, true, false, true, true})
+	defer col.Release()
+
+	enc, err := DictionaryEncode(pool, col)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Release()
+
+	if _, ok := enc.DataType().(*arrow.DictionaryType); !ok {
+		t.Fatalf("got type=%s, want a dictionary", enc.DataType())
+	}
+	dict := enc.Data().Chunk(0).(*array.Dictionary)
+	if got, want := dict.Dictionary().(*array.String).String(), `["b" "a" "c"]`; got != want {
+		t.Fatalf("got dictionary=%s, want=%s", got, want)
+	}
+	if got, want := dict.Indices().(*array.Int32).String(), "[0 1 (null) 0 2]"; got != want {
+		t.Fatalf("got indices=%s, want=%s", got, want)
+	}
+
+	dec, err := DictionaryDecode(pool, enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Release()
+
+	if got, want := dec.Data().Chunk(0).(*array.String).String(), `["b" "a" (null) "b" "c"]`; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+}
+
+func TestKeyEncoderRemapsDictionaries(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	// The two columns are encoded with different dictionaries.
+	a := newStringColumn(pool, "a", []string{"x", "y", "z"}, nil)
+	defer a.Release()
+	b := newStringColumn(pool, "b", []string{"z", "x", "w", "x"}, nil)
+	defer b.Release()
+
+	da, err := DictionaryEncode(pool, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer da.Release()
+	db, err := DictionaryEncode(pool, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Release()
+
+	enc := NewKeyEncoder(false)
+	ca, err := enc.Encode(da)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb, err := enc.Encode(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []int32{2, 0, 3, 0}
+	for i := range want {
+		if cb[i] != want[i] {
+			t.Fatalf("got codes=%v, want=%v (first column %v)", cb, want, ca)
+		}
+	}
+	if got, want := enc.NumKeys(), 4; got != want {
+		t.Fatalf("got=%d keys, want=%d", got, want)
+	}
+}
+
+func TestHashJoin(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	left := newStringColumn(pool, "k", []string{"a", "b", "", "c"}, []bool{true, true, false, true})
+	defer left.Release()
+	right := newStringColumn(pool, "k", []string{"c", "a", "", "a"}, []bool{true, true, false, true})
+	defer right.Release()
+
+	dright, err := DictionaryEncode(pool, right)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dright.Release()
+
+	cases := []struct {
+		how         JoinType
+		left, right string
+	}{
+		{InnerJoin, "[0 0 3]", "[1 3 0]"},
+		{LeftJoin, "[0 0 1 2 3]", "[1 3 (null) (null) 0]"},
+	}
+	for _, c := range cases {
+		li, ri, err := HashJoin(pool, []*array.Column{left}, []*array.Column{dright}, c.how)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := li.String(); got != c.left {
+			t.Errorf("how=%d: got left=%s, want=%s", c.how, got, c.left)
+		}
+		if got := ri.String(); got != c.right {
+			t.Errorf("how=%d: got right=%s, want=%s", c.how, got, c.right)
+		}
+		li.Release()
+		ri.Release()
+	}
+
+	for _, c := range []struct {
+		how  JoinType
+		want string
+	}{
+		{SemiJoin, "[0 3]"},
+		{AntiJoin, "[1 2]"},
+	} {
+		rows, err := SemiJoinRows(pool, []*array.Column{left}, []*array.Column{dright}, c.how)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := rows.String(); got != c.want {
+			t.Errorf("how=%s: got rows=%s, want=%s", c.how, got, c.want)
+		}
+		rows.Release()
+	}
+	if _, _, err := HashJoin(pool, []*array.Column{left}, []*array.Column{dright}, SemiJoin); err == nil {
+		t.Error("expected an error running HashJoin with a semi join")
+	}
+	if _, err := SemiJoinRows(pool, []*array.Column{left}, []*array.Column{dright}, InnerJoin); err == nil {
+		t.Error("expected an error running SemiJoinRows with an inner join")
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// ExplodePolicy selects the rows Explode produces for null and empty lists.
+// The policies combine, the zero policy drops both.
+type ExplodePolicy int
+
+const (
+	// ExplodeKeepNull keeps a row with a null element for every null list.
+	ExplodeKeepNull ExplodePolicy = 1 << iota
+	// ExplodeKeepEmpty keeps a row with a null element for every empty list.
+	ExplodeKeepEmpty
+)
+
+// Explode flattens the list column col to a column holding every element of
+// every list on a row of its own, named like col. It also returns the row of
+// col every element comes from, which takes the rows of the other columns to
+// repeat alongside the elements. Null and empty lists produce no rows unless
+// policy keeps them.
+//
+// col holds lists or fixed size lists.
+func Explode(mem memory.Allocator, col *array.Column, policy ExplodePolicy) (*array.Column, *array.Int64, error) {
+	elemType, err := listElem(col)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := array.NewInt64Builder(mem)
+	defer rows.Release()
+	elems := array.NewInt64Builder(mem)
+	defer elems.Release()
+
+	// The elements of every chunk follow those of the previous chunks.
+	var values []array.Interface
+	var row, base int64
+	for _, chunk := range col.Data().Chunks() {
+		chunkValues, bounds := listChunk(chunk)
+		values = append(values, chunkValues)
+
+		for i := 0; i < chunk.Len(); i++ {
+			keep := policy&ExplodeKeepEmpty != 0
+			if chunk.IsNull(i) {
+				keep = policy&ExplodeKeepNull != 0
+			} else if beg, end := bounds(i); beg < end {
+				for k := beg; k < end; k++ {
+					rows.Append(row)
+					elems.Append(base + k)
+				}
+				keep = false
+			}
+			if keep {
+				rows.Append(row)
+				elems.AppendNull()
+			}
+			row++
+		}
+		base += int64(chunkValues.Len())
+	}
+
+	chunked := array.NewChunked(elemType, values)
+	defer chunked.Release()
+	flat := array.NewColumn(arrow.Field{Name: col.Name(), Type: elemType, Nullable: true}, chunked)
+	defer flat.Release()
+
+	indices := elems.NewInt64Array()
+	defer indices.Release()
+	exploded, err := Take(mem, flat, indices)
+	if err != nil {
+		return nil, nil, err
+	}
+	return exploded, rows.NewInt64Array(), nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// newInt64ListColumn returns a list column with a chunk per element of chunks, nil lists are null.
+func newInt64ListColumn(mem memory.Allocator, name string, chunks ...[][]int64) *array.Column {
+	dtype := arrow.ListOf(arrow.PrimitiveTypes.Int64)
+	arrs := make([]array.Interface, 0, len(chunks))
+	for _, lists := range chunks {
+		bldr := array.NewListBuilder(mem, arrow.PrimitiveTypes.Int64)
+		for _, list := range lists {
+			if list == nil {
+				bldr.AppendNull()
+				continue
+			}
+			bldr.Append(true)
+			bldr.ValueBuilder().(*array.Int64Builder).AppendValues(list, nil)
+		}
+		arrs = append(arrs, bldr.NewArray())
+		bldr.Release()
+	}
+	chunked := array.NewChunked(dtype, arrs)
+	defer chunked.Release()
+	for _, arr := range arrs {
+		arr.Release()
+	}
+	return array.NewColumn(arrow.Field{Name: name, Type: dtype, Nullable: true}, chunked)
+}
+
+func TestExplode(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	col := newInt64ListColumn(pool, "l", [][]int64{{1, 2}, nil, {}, {3}}, [][]int64{{4, 5}})
+	defer col.Release()
+
+	for _, tc := range []struct {
+		policy       ExplodePolicy
+		values, rows string
+	}{
+		{0, "[1 2 3 4 5]", "[0 0 3 4 4]"},
+		{ExplodeKeepNull, "[1 2 (null) 3 4 5]", "[0 0 1 3 4 4]"},
+		{ExplodeKeepNull | ExplodeKeepEmpty, "[1 2 (null) (null) 3 4 5]", "[0 0 1 2 3 4 4]"},
+	} {
+		values, rows, err := Explode(pool, col, tc.policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprint(values.Data().Chunk(0)); got != tc.values {
+			t.Errorf("policy %d: got values=%s, want=%s", tc.policy, got, tc.values)
+		}
+		if got := fmt.Sprint(rows); got != tc.rows {
+			t.Errorf("policy %d: got rows=%s, want=%s", tc.policy, got, tc.rows)
+		}
+		if values.Name() != "l" || !arrow.TypeEqual(values.DataType(), arrow.PrimitiveTypes.Int64) {
+			t.Errorf("policy %d: got field %v", tc.policy, values.Field())
+		}
+		values.Release()
+		rows.Release()
+	}
+
+	ints := newInt64Column(pool, "i", []int64{1})
+	defer ints.Release()
+	if _, _, err := Explode(pool, ints, 0); err == nil || err.Error() != `compute: column "i" of type int64 does not hold lists` {
+		t.Fatalf("got error %v", err)
+	}
+}
@@ -0,0 +1,334 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// Groups assigns the rows of one or more key columns to groups of equal keys.
+type Groups struct {
+	// IDs holds the group of every row. Groups are numbered in order of first appearance.
+	IDs []int32
+	// First holds the first row of every group.
+	First []int64
+}
+
+// GroupRows groups the rows of the key columns by key. Nulls are equal to each
+// other, so the rows with null keys form groups of their own.
+func GroupRows(keys ...*array.Column) (*Groups, error) {
+	enc := NewKeyEncoder(true)
+	codes, err := enc.Encode(keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The codes of null keys are not handed out in order of appearance, so renumber them.
+	remap := make([]int32, enc.NumKeys())
+	for i := range remap {
+		remap[i] = -1
+	}
+	g := &Groups{IDs: codes, First: make([]int64, 0, enc.NumKeys())}
+	for row, code := range codes {
+		if remap[code] < 0 {
+			remap[code] = int32(len(g.First))
+			g.First = append(g.First, int64(row))
+		}
+		g.IDs[row] = remap[code]
+	}
+	return g, nil
+}
+
+// NumGroups returns the number of groups.
+func (g *Groups) NumGroups() int { return len(g.First) }
+
+// AggregateKind selects the aggregate computed by AggregateGroups.
+type AggregateKind int
+
+const (
+	// AggCount counts the non-null values.
+	AggCount AggregateKind = iota
+	// AggSum sums the non-null values.
+	AggSum
+	// AggMean averages the non-null values.
+	AggMean
+	// AggMin keeps the smallest non-null value.
+	AggMin
+	// AggMax keeps the largest non-null value.
+	AggMax
+	// AggList collects the values, nulls included, to a list.
+	AggList
+	// AggCountDistinct counts the distinct non-null values.
+	AggCountDistinct
+)
+
+func (k AggregateKind) String() string {
+	switch k {
+	case AggCount:
+		return "count"
+	case AggSum:
+		return "sum"
+	case AggMean:
+		return "mean"
+	case AggMin:
+		return "min"
+	case AggMax:
+		return "max"
+	case AggList:
+		return "list"
+	case AggCountDistinct:
+		return "count_distinct"
+	default:
+		return fmt.Sprintf("AggregateKind(%d)", int(k))
+	}
+}
+
+// AggregateGroups computes an aggregate of the values of col for every group
+// of g, returning a column with a row per group.
+//
+// AggCount counts the non-null values of each group, or its rows when col is
+// nil, and is never null. AggSum sums integers to an int64 and floating point
+// numbers to a float64, AggMean averages to a float64, and AggMin and AggMax
+// keep the type of col and support every ordered type. These are null for
+// groups without non-null values. AggList collects the values of each group in
+// row order to a list of the type of col. AggCountDistinct counts the distinct
+// non-null values of each group, compared like the keys of GroupRows, and is
+// never null. NaNs are aggregated as values, see NaNPolicy.AggregateGroups to
+// skip them.
+func AggregateGroups(mem memory.Allocator, col *array.Column, g *Groups, kind AggregateKind) (*array.Column, error) {
+	return NaNPropagate.AggregateGroups(mem, col, g, kind)
+}
+
+// AggregateGroups is AggregateGroups treating NaNs as set by p. AggList keeps
+// every value.
+func (p NaNPolicy) AggregateGroups(mem memory.Allocator, col *array.Column, g *Groups, kind AggregateKind) (*array.Column, error) {
+	if col == nil {
+		if kind != AggCount {
+			return nil, fmt.Errorf("compute: %v needs a column", kind)
+		}
+		counts := make([]int64, g.NumGroups())
+		for _, id := range g.IDs {
+			counts[id]++
+		}
+		return newInt64Result(mem, "count", counts, nil), nil
+	}
+	if col.Len() != len(g.IDs) {
+		return nil, fmt.Errorf("compute: column %q has %d rows, want %d", col.Name(), col.Len(), len(g.IDs))
+	}
+
+	switch kind {
+	case AggCount:
+		counts := make([]int64, g.NumGroups())
+		forEachGroupValue(col, g, p, func(id int32, chunk array.Interface, i int, row int64) {
+			counts[id]++
+		})
+		return newInt64Result(mem, col.Name(), counts, nil), nil
+
+	case AggSum, AggMean:
+		return aggregateNumbers(mem, col, g, kind, p)
+
+	case AggMin, AggMax:
+		return aggregateExtremes(mem, col, g, kind, p)
+
+	case AggList:
+		return aggregateList(mem, col, g)
+
+	case AggCountDistinct:
+		codes, err := NewKeyEncoder(false).Encode(col)
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[[2]int32]struct{})
+		counts := make([]int64, g.NumGroups())
+		forEachGroupValue(col, g, p, func(id int32, chunk array.Interface, i int, row int64) {
+			pair := [2]int32{id, codes[row]}
+			if _, ok := seen[pair]; !ok {
+				seen[pair] = struct{}{}
+				counts[id]++
+			}
+		})
+		return newInt64Result(mem, col.Name(), counts, nil), nil
+
+	default:
+		return nil, fmt.Errorf("compute: unknown aggregate %v", kind)
+	}
+}
+
+// forEachGroupValue calls fn with the group of every value of col that is not
+// missing under p.
+func forEachGroupValue(col *array.Column, g *Groups, p NaNPolicy, fn func(id int32, chunk array.Interface, i int, row int64)) {
+	var row int64
+	for _, chunk := range col.Data().Chunks() {
+		missing := p.missing(chunk)
+		for i := 0; i < chunk.Len(); i++ {
+			if !missing(i) {
+				fn(g.IDs[row], chunk, i, row)
+			}
+			row++
+		}
+	}
+}
+
+func aggregateNumbers(mem memory.Allocator, col *array.Column, g *Groups, kind AggregateKind, p NaNPolicy) (*array.Column, error) {
+	counts := make([]int64, g.NumGroups())
+	valid := make([]bool, g.NumGroups())
+
+	if kind == AggSum && isInteger(col.DataType()) {
+		getters := make(map[array.Interface]func(int) int64)
+		for _, chunk := range col.Data().Chunks() {
+			switch chunk.(type) {
+			case *array.Uint8, *array.Uint16, *array.Uint32, *array.Uint64:
+				get := uint64Getter(chunk)
+				getters[chunk] = func(i int) int64 { return int64(get(i)) }
+			case *array.RunEndEncoded:
+				return nil, fmt.Errorf("compute: %v of run-end encoded columns is not supported", kind)
+			default:
+				getters[chunk] = int64Getter(chunk)
+			}
+		}
+
+		sums := make([]int64, g.NumGroups())
+		forEachGroupValue(col, g, p, func(id int32, chunk array.Interface, i int, _ int64) {
+			sums[id] += getters[chunk](i)
+			valid[id] = true
+		})
+		return newInt64Result(mem, col.Name(), sums, valid), nil
+	}
+
+	getters := make(map[array.Interface]func(int) float64)
+	for _, chunk := range col.Data().Chunks() {
+		get, err := numberGetter(chunk)
+		if err != nil {
+			return nil, err
+		}
+		getters[chunk] = get
+	}
+
+	sums := make([]float64, g.NumGroups())
+	forEachGroupValue(col, g, p, func(id int32, chunk array.Interface, i int, _ int64) {
+		sums[id] += getters[chunk](i)
+		counts[id]++
+		valid[id] = true
+	})
+	if kind == AggMean {
+		for id := range sums {
+			if counts[id] > 0 {
+				sums[id] /= float64(counts[id])
+			}
+		}
+	}
+
+	return newFloat64Result(mem, col.Name(), sums, valid), nil
+}
+
+func aggregateExtremes(mem memory.Allocator, col *array.Column, g *Groups, kind AggregateKind, p NaNPolicy) (*array.Column, error) {
+	chunks := col.Data().Chunks()
+	cmp, err := newFieldComparator(col.Field(), chunks)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[array.Interface]int, len(chunks))
+	for c, chunk := range chunks {
+		index[chunk] = c
+	}
+
+	best := make([]position, g.NumGroups())
+	rows := make([]int64, g.NumGroups())
+	valid := make([]bool, g.NumGroups())
+	forEachGroupValue(col, g, p, func(id int32, chunk array.Interface, i int, row int64) {
+		pos := position{chunk: index[chunk], index: i}
+		if valid[id] {
+			c := cmp(pos, best[id])
+			if kind == AggMax {
+				c = -c
+			}
+			if c >= 0 {
+				return
+			}
+		}
+		best[id], rows[id], valid[id] = pos, row, true
+	})
+
+	indices := array.NewInt64Builder(mem)
+	defer indices.Release()
+	indices.AppendValues(rows, valid)
+	idx := indices.NewInt64Array()
+	defer idx.Release()
+
+	taken, err := Take(mem, col, idx)
+	if err != nil {
+		return nil, err
+	}
+	defer taken.Release()
+
+	field := col.Field()
+	field.Nullable = true
+	return array.NewColumn(field, taken.Data()), nil
+}
+
+func aggregateList(mem memory.Allocator, col *array.Column, g *Groups) (*array.Column, error) {
+	rows := make([][]int64, g.NumGroups())
+	for row, id := range g.IDs {
+		rows[id] = append(rows[id], int64(row))
+	}
+
+	chunks := col.Data().Chunks()
+	locate := newChunkLocator(chunks)
+	bldr := array.NewListBuilder(mem, col.DataType())
+	defer bldr.Release()
+	for _, group := range rows {
+		bldr.Append(true)
+		for _, row := range group {
+			c, i, err := locate(row)
+			if err != nil {
+				return nil, err
+			}
+			if err := AppendValue(bldr.ValueBuilder(), chunks[c], i); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return newResultColumn(col.Name(), bldr.NewArray()), nil
+}
+
+// isInteger reports whether dtype is a signed or unsigned integer type.
+func isInteger(dtype arrow.DataType) bool {
+	switch dtype.ID() {
+	case arrow.INT8, arrow.INT16, arrow.INT32, arrow.INT64,
+		arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64:
+		return true
+	default:
+		return false
+	}
+}
+
+// newInt64Result returns a single chunk Column named name holding values.
+func newInt64Result(mem memory.Allocator, name string, values []int64, valid []bool) *array.Column {
+	bldr := array.NewInt64Builder(mem)
+	defer bldr.Release()
+	bldr.AppendValues(values, valid)
+	return newResultColumn(name, bldr.NewArray())
+}
+
+func newFloat64Result(mem memory.Allocator, name string, values []float64, valid []bool) *array.Column {
+	bldr := array.NewFloat64Builder(mem)
+	defer bldr.Release()
+	bldr.AppendValues(values, valid)
+	return newResultColumn(name, bldr.NewArray())
+}
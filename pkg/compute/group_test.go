@@ -0,0 +1,75 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestAggregateGroups(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	keys := newStringColumn(pool, "k", []string{"a", "b", "a", "", "b", "c"}, []bool{true, true, true, false, true, true})
+	defer keys.Release()
+	values := newNullableInt64Column(pool, "v", []int64{1, 2, 3, 4, 5, 0}, []bool{true, true, true, true, true, false})
+	defer values.Release()
+	floats := newFloat64Column(pool, "f", []float64{1, 2, 4, 8, 3, 0}, []bool{true, true, true, true, true, false})
+	defer floats.Release()
+	dups := newNullableInt64Column(pool, "d", []int64{1, 2, 1, 4, 3, 0}, []bool{true, true, true, true, true, false})
+	defer dups.Release()
+
+	g, err := GroupRows(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(g.IDs, g.First), "[0 1 0 2 1 3] [0 1 3 5]"; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+
+	cases := []struct {
+		name string
+		col  *array.Column
+		kind AggregateKind
+		want string
+	}{
+		{name: "count(*)", col: nil, kind: AggCount, want: "[2 2 1 1]"},
+		{name: "count", col: values, kind: AggCount, want: "[2 2 1 0]"},
+		{name: "sum", col: values, kind: AggSum, want: "[4 7 4 (null)]"},
+		{name: "mean", col: floats, kind: AggMean, want: "[2.5 2.5 8 (null)]"},
+		{name: "min", col: values, kind: AggMin, want: "[1 2 4 (null)]"},
+		{name: "max", col: values, kind: AggMax, want: "[3 5 4 (null)]"},
+		{name: "list", col: values, kind: AggList, want: "[[1 3] [2 5] [4] [(null)]]"},
+		{name: "count_distinct", col: dups, kind: AggCountDistinct, want: "[1 2 1 0]"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := AggregateGroups(pool, c.col, g, c.kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer got.Release()
+
+			if s := fmt.Sprintf("%v", got.Data().Chunk(0)); s != c.want {
+				t.Fatalf("got=%s, want=%s", s, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/internal/hashing"
+)
+
+const (
+	// MinHLLPrecision is the smallest precision accepted by NewHyperLogLog.
+	MinHLLPrecision = 4
+	// MaxHLLPrecision is the largest precision accepted by NewHyperLogLog.
+	MaxHLLPrecision = 18
+	// DefaultHLLPrecision gives a standard error of about 0.8% using 16KiB of registers.
+	DefaultHLLPrecision = 14
+
+	hllVersion = 1
+)
+
+// HyperLogLog is a sketch for estimating the number of distinct values.
+// Its memory usage is fixed at 2^precision bytes regardless of the
+// number of values added. Sketches with the same precision can be merged,
+// so partitions can be sketched independently and combined afterwards.
+type HyperLogLog struct {
+	precision uint8
+	registers []uint8
+}
+
+// NewHyperLogLog creates a new empty sketch with 2^precision registers.
+func NewHyperLogLog(precision uint8) (*HyperLogLog, error) {
+	if precision < MinHLLPrecision || precision > MaxHLLPrecision {
+		return nil, fmt.Errorf("compute/hll: precision must be in [%d, %d], got %d", MinHLLPrecision, MaxHLLPrecision, precision)
+	}
+	return &HyperLogLog{
+		precision: precision,
+		registers: make([]uint8, 1<<precision),
+	}, nil
+}
+
+// Precision returns the precision the sketch was created with.
+func (h *HyperLogLog) Precision() uint8 { return h.precision }
+
+// AddHash adds a 64-bit hash to the sketch.
+func (h *HyperLogLog) AddHash(hash uint64) {
+	idx := hash >> (64 - h.precision)
+	// Rank of the first set bit in the remaining bits. The guard bit
+	// bounds the rank when the remaining bits are all zero.
+	w := hash<<h.precision | 1<<(h.precision-1)
+	rank := uint8(bits.LeadingZeros64(w)) + 1
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+	}
+}
+
+// Update adds all the non-null values of arr to the sketch.
+func (h *HyperLogLog) Update(arr array.Interface) error {
+	return hashing.Array(arr, func(_ int, hash uint64, valid bool) {
+		if valid {
+			h.AddHash(hash)
+		}
+	})
+}
+
+// UpdateColumn adds all the non-null values of every chunk in col to the sketch.
+func (h *HyperLogLog) UpdateColumn(col *array.Column) error {
+	for _, chunk := range col.Data().Chunks() {
+		if err := h.Update(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Merge folds the state of other into h. Both sketches must have the same precision.
+func (h *HyperLogLog) Merge(other *HyperLogLog) error {
+	if other == nil {
+		return nil
+	}
+	if h.precision != other.precision {
+		return fmt.Errorf("compute/hll: cannot merge precision %d into precision %d", other.precision, h.precision)
+	}
+	for i, r := range other.registers {
+		if r > h.registers[i] {
+			h.registers[i] = r
+		}
+	}
+	return nil
+}
+
+// Count returns the estimated number of distinct values added to the sketch.
+func (h *HyperLogLog) Count() uint64 {
+	m := float64(len(h.registers))
+
+	var sum float64
+	var zeros int
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	estimate := hllAlpha(len(h.registers)) * m * m / sum
+
+	// Small range correction using linear counting.
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+
+	return uint64(estimate + 0.5)
+}
+
+// Reset clears the sketch so it can be reused.
+func (h *HyperLogLog) Reset() {
+	for i := range h.registers {
+		h.registers[i] = 0
+	}
+}
+
+// MarshalBinary encodes the sketch so it can be shipped to another process and merged.
+func (h *HyperLogLog) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 2+len(h.registers))
+	buf[0] = hllVersion
+	buf[1] = h.precision
+	copy(buf[2:], h.registers)
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a sketch encoded with MarshalBinary.
+func (h *HyperLogLog) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return errors.New("compute/hll: sketch data too short")
+	}
+	if data[0] != hllVersion {
+		return fmt.Errorf("compute/hll: unsupported sketch version %d", data[0])
+	}
+	precision := data[1]
+	if precision < MinHLLPrecision || precision > MaxHLLPrecision {
+		return fmt.Errorf("compute/hll: invalid precision %d", precision)
+	}
+	if len(data)-2 != 1<<precision {
+		return fmt.Errorf("compute/hll: expected %d registers, got %d", 1<<precision, len(data)-2)
+	}
+	h.precision = precision
+	h.registers = make([]uint8, 1<<precision)
+	copy(h.registers, data[2:])
+	return nil
+}
+
+// ApproxCountDistinct estimates the number of distinct non-null values in col
+// using a HyperLogLog sketch with 2^precision registers.
+func ApproxCountDistinct(col *array.Column, precision uint8) (uint64, error) {
+	h, err := NewHyperLogLog(precision)
+	if err != nil {
+		return 0, err
+	}
+	if err := h.UpdateColumn(col); err != nil {
+		return 0, err
+	}
+	return h.Count(), nil
+}
+
+func hllAlpha(m int) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/float64(m))
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func newInt64Column(mem memory.Allocator, name string, chunks ...[]int64) *array.Column {
+	arrs := make([]array.Interface, 0, len(chunks))
+	for _, chunk := range chunks {
+		b := array.NewInt64Builder(mem)
+		b.AppendValues(chunk, nil)
+		arrs = append(arrs, b.NewArray())
+		b.Release()
+	}
+	chunked := array.NewChunked(arrow.PrimitiveTypes.Int64, arrs)
+	defer chunked.Release()
+	for _, arr := range arrs {
+		arr.Release()
+	}
+	return array.NewColumn(arrow.Field{Name: name, Type: arrow.PrimitiveTypes.Int64}, chunked)
+}
+
+func withinRelErr(got, want uint64, relErr float64) bool {
+	return math.Abs(float64(got)-float64(want)) <= relErr*float64(want)
+}
+
+func TestApproxCountDistinct(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	const distinct = 50000
+	a := make([]int64, distinct)
+	b := make([]int64, distinct)
+	for i := range a {
+		a[i] = int64(i)
+		b[i] = int64(i % 1000) // all duplicates of values already in a
+	}
+
+	col := newInt64Column(pool, "ids", a, b)
+	defer col.Release()
+
+	got, err := ApproxCountDistinct(col, DefaultHLLPrecision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !withinRelErr(got, distinct, 0.03) {
+		t.Fatalf("got=%d, want=%d (+/- 3%%)", got, distinct)
+	}
+}
+
+func TestApproxCountDistinctSmall(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	b := array.NewStringBuilder(pool)
+	defer b.Release()
+	b.AppendValues([]string{"a", "b", "c", "a", "b", "", "x"}, []bool{true, true, true, true, true, false, true})
+	arr := b.NewArray()
+	defer arr.Release()
+
+	h, err := NewHyperLogLog(DefaultHLLPrecision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Update(arr); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := h.Count(), uint64(4); got != want {
+		t.Fatalf("got=%d, want=%d", got, want)
+	}
+}
+
+func TestHyperLogLogMerge(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	left := make([]int64, 20000)
+	right := make([]int64, 20000)
+	for i := range left {
+		left[i] = int64(i)
+		right[i] = int64(i + 10000)
+	}
+
+	leftCol := newInt64Column(pool, "left", left)
+	defer leftCol.Release()
+	rightCol := newInt64Column(pool, "right", right)
+	defer rightCol.Release()
+
+	h1, _ := NewHyperLogLog(12)
+	h2, _ := NewHyperLogLog(12)
+	if err := h1.UpdateColumn(leftCol); err != nil {
+		t.Fatal(err)
+	}
+	if err := h2.UpdateColumn(rightCol); err != nil {
+		t.Fatal(err)
+	}
+
+	// Round trip h2 through its binary form as if it was computed elsewhere.
+	data, err := h2.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var remote HyperLogLog
+	if err := remote.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h1.Merge(&remote); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := h1.Count(), uint64(30000); !withinRelErr(got, want, 0.05) {
+		t.Fatalf("got=%d, want=%d (+/- 5%%)", got, want)
+	}
+
+	h3, _ := NewHyperLogLog(10)
+	if err := h1.Merge(h3); err == nil {
+		t.Fatal("expected an error merging sketches with different precisions")
+	}
+}
+
+func TestNewHyperLogLogPrecision(t *testing.T) {
+	for _, p := range []uint8{0, 3, 19} {
+		t.Run(fmt.Sprintf("precision=%d", p), func(t *testing.T) {
+			if _, err := NewHyperLogLog(p); err == nil {
+				t.Fatalf("expected an error for precision %d", p)
+			}
+		})
+	}
+}
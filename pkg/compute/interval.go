@@ -0,0 +1,161 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// IntervalJoin matches every row of values with the right rows whose interval
+// [starts, ends] holds its value, bounds included, and returns the indices of
+// the matching rows of each side like HashJoin does: for every left row the
+// matches are listed in right row order, and a left row without a match is
+// paired with a null right index when how is LeftJoin. Neither side needs to
+// be sorted and intervals may overlap.
+//
+// The three columns hold integers, floats or temporal values of the same type.
+// Null values and intervals with a null bound never match, and neither do
+// intervals whose start is after their end.
+func IntervalJoin(mem memory.Allocator, values, starts, ends *array.Column, how JoinType) (*array.Int64, *array.Int64, error) {
+	if !how.pairs() {
+		return nil, nil, fmt.Errorf("compute: IntervalJoin does not support %s joins", how)
+	}
+	if starts.Len() != ends.Len() {
+		return nil, nil, fmt.Errorf("compute: IntervalJoin needs as many starts as ends (%d != %d)", starts.Len(), ends.Len())
+	}
+	cmp, err := intervalComparator(values, starts, ends)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := validRows(values)
+	sort.SliceStable(rows, func(i, j int) bool { return cmp(seriesValue, rows[i], seriesValue, rows[j]) < 0 })
+	startNulls, endNulls := nullMask(starts), nullMask(ends)
+	var intervals []int
+	for row := range startNulls {
+		if !startNulls[row] && !endNulls[row] {
+			intervals = append(intervals, row)
+		}
+	}
+	sort.SliceStable(intervals, func(i, j int) bool { return cmp(seriesStart, intervals[i], seriesStart, intervals[j]) < 0 })
+
+	// Sweep the values in order: open the intervals starting at or before the
+	// value and close those ending before it, the open ones hold the value.
+	open := &intervalHeap{less: func(a, b int) bool { return cmp(seriesEnd, a, seriesEnd, b) < 0 }}
+	matches := make([][]int, values.Len())
+	next := 0
+	for _, row := range rows {
+		for ; next < len(intervals) && cmp(seriesStart, intervals[next], seriesValue, row) <= 0; next++ {
+			heap.Push(open, intervals[next])
+		}
+		for open.Len() > 0 && cmp(seriesEnd, open.rows[0], seriesValue, row) < 0 {
+			heap.Pop(open)
+		}
+		if open.Len() > 0 {
+			matches[row] = append([]int(nil), open.rows...)
+			sort.Ints(matches[row])
+		}
+	}
+
+	lbldr := array.NewInt64Builder(mem)
+	defer lbldr.Release()
+	rbldr := array.NewInt64Builder(mem)
+	defer rbldr.Release()
+	for row, rights := range matches {
+		for _, r := range rights {
+			lbldr.Append(int64(row))
+			rbldr.Append(int64(r))
+		}
+		if len(rights) == 0 && how == LeftJoin {
+			lbldr.Append(int64(row))
+			rbldr.AppendNull()
+		}
+	}
+	return lbldr.NewInt64Array(), rbldr.NewInt64Array(), nil
+}
+
+// The series compared by the function returned by intervalComparator.
+const (
+	seriesValue = iota
+	seriesStart
+	seriesEnd
+)
+
+// intervalComparator materializes the columns of an interval join and returns
+// a function comparing the value of row i of series a with row j of series b.
+func intervalComparator(cols ...*array.Column) (func(a, i, b, j int) int, error) {
+	float, temporal := false, false
+	for _, col := range cols {
+		class := asofClass(col)
+		if class == otherClass {
+			return nil, fmt.Errorf("compute: cannot interval join on %s", col.DataType())
+		}
+		float = float || class == floatClass
+		temporal = temporal || classOf(col.DataType()) == otherClass
+	}
+	for _, col := range cols[1:] {
+		if (asofClass(col) == floatClass) != (asofClass(cols[0]) == floatClass) ||
+			temporal && !arrow.TypeEqual(col.DataType(), cols[0].DataType()) {
+			return nil, fmt.Errorf("compute: cannot interval join %s with %s", cols[0].DataType(), col.DataType())
+		}
+	}
+
+	if float {
+		values := make([][]float64, len(cols))
+		for i, col := range cols {
+			values[i] = floatValues(col)
+		}
+		return func(a, i, b, j int) int { return compareFloat64(values[a][i], values[b][j]) }, nil
+	}
+	values := make([][]int64, len(cols))
+	for i, col := range cols {
+		values[i] = intValues(col)
+	}
+	return func(a, i, b, j int) int { return compareInt64(values[a][i], values[b][j]) }, nil
+}
+
+// validRows returns the rows of col that are not null.
+func validRows(col *array.Column) []int {
+	var rows []int
+	for row, null := range nullMask(col) {
+		if !null {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// intervalHeap holds the open intervals of an interval join, the one ending first on top.
+type intervalHeap struct {
+	rows []int
+	less func(a, b int) bool
+}
+
+func (h *intervalHeap) Len() int           { return len(h.rows) }
+func (h *intervalHeap) Less(i, j int) bool { return h.less(h.rows[i], h.rows[j]) }
+func (h *intervalHeap) Swap(i, j int)      { h.rows[i], h.rows[j] = h.rows[j], h.rows[i] }
+func (h *intervalHeap) Push(x interface{}) { h.rows = append(h.rows, x.(int)) }
+func (h *intervalHeap) Pop() interface{} {
+	n := len(h.rows) - 1
+	x := h.rows[n]
+	h.rows = h.rows[:n]
+	return x
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestIntervalJoin(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	values := newNullableInt64Column(pool, "v", []int64{5, 1, 12, 0, 20, 7}, []bool{true, true, true, false, true, true})
+	defer values.Release()
+	// The intervals overlap, one has a null bound and the last one is empty.
+	starts := newNullableInt64Column(pool, "start", []int64{0, 4, 10, 0, 8}, []bool{true, true, true, false, true})
+	defer starts.Release()
+	ends := newInt64Column(pool, "end", []int64{5, 10, 15, 3, 6})
+	defer ends.Release()
+
+	for _, tc := range []struct {
+		how         JoinType
+		left, right string
+	}{
+		{InnerJoin, "[0 0 1 2 5]", "[0 1 0 2 1]"},
+		{LeftJoin, "[0 0 1 2 3 4 5]", "[0 1 0 2 (null) (null) 1]"},
+	} {
+		left, right, err := IntervalJoin(pool, values, starts, ends, tc.how)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprint(left); got != tc.left {
+			t.Errorf("join %d: got left=%s, want=%s", tc.how, got, tc.left)
+		}
+		if got := fmt.Sprint(right); got != tc.right {
+			t.Errorf("join %d: got right=%s, want=%s", tc.how, got, tc.right)
+		}
+		left.Release()
+		right.Release()
+	}
+
+	floats := newFloat64Column(pool, "f", []float64{1, 2}, nil)
+	defer floats.Release()
+	if _, _, err := IntervalJoin(pool, floats, starts, ends, InnerJoin); err == nil ||
+		err.Error() != "compute: cannot interval join float64 with int64" {
+		t.Fatalf("got error %v", err)
+	}
+}
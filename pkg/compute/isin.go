@@ -0,0 +1,118 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// IsIn reports whether the value of every row of col is one of values, null
+// for null rows. Numbers compare by value whatever their type, like they do
+// in ListContains. The values are looked up in a hash set, and the values of
+// a dictionary column are looked up once per dictionary entry rather than
+// once per row.
+func IsIn(mem memory.Allocator, col *array.Column, values ...interface{}) (*array.Column, error) {
+	dtype := col.DataType()
+	if dict, ok := dtype.(*arrow.DictionaryType); ok {
+		dtype = dict.ValueType
+	}
+	read, err := castReader(dtype)
+	if err != nil {
+		return nil, fmt.Errorf("compute: cannot search values of %s", col.DataType())
+	}
+	contains, err := valueSet(values...)
+	if err != nil {
+		return nil, fmt.Errorf("compute: IsIn of column %q: %w", col.Name(), err)
+	}
+
+	bldr := array.NewBooleanBuilder(mem)
+	defer bldr.Release()
+	bldr.Reserve(col.Len())
+	for _, chunk := range col.Data().Chunks() {
+		if dict, ok := chunk.(*array.Dictionary); ok {
+			entries := dict.Dictionary()
+			member := make([]bool, entries.Len())
+			for k := range member {
+				member[k] = entries.IsValid(k) && contains(read(entries, k))
+			}
+			for i := 0; i < dict.Len(); i++ {
+				if dict.IsNull(i) || entries.IsNull(dict.GetValueIndex(i)) {
+					bldr.AppendNull()
+					continue
+				}
+				bldr.Append(member[dict.GetValueIndex(i)])
+			}
+			continue
+		}
+		for i := 0; i < chunk.Len(); i++ {
+			if chunk.IsNull(i) {
+				bldr.AppendNull()
+				continue
+			}
+			bldr.Append(contains(read(chunk, i)))
+		}
+	}
+	return newResultColumn(col.Name(), bldr.NewArray()), nil
+}
+
+// Between reports whether the value of every row of col lies between low and
+// high, bounds included, null for null rows. low and high are columns or
+// values as the right operand of Compare, and compare with col as it does.
+func Between(mem memory.Allocator, col *array.Column, low, high interface{}) (*array.Column, error) {
+	above, err := Compare(mem, col, low, GreaterEqual)
+	if err != nil {
+		return nil, err
+	}
+	defer above.Release()
+	below, err := Compare(mem, col, high, LessEqual)
+	if err != nil {
+		return nil, err
+	}
+	defer below.Release()
+
+	in, err := And(mem, above.Data().Chunk(0).(*array.Boolean), below.Data().Chunk(0).(*array.Boolean), Kleene)
+	if err != nil {
+		return nil, err
+	}
+	return newResultColumn(col.Name(), in), nil
+}
+
+// valueSet returns a function reporting whether a value returned by a
+// castReader equals one of values. Numbers are compared as float64.
+func valueSet(values ...interface{}) (func(v interface{}) bool, error) {
+	set := make(map[interface{}]struct{}, len(values))
+	for _, value := range values {
+		v, err := fillValue(value)
+		if err != nil {
+			return nil, err
+		}
+		set[setKey(v)] = struct{}{}
+	}
+	return func(v interface{}) bool {
+		_, ok := set[setKey(v)]
+		return ok
+	}, nil
+}
+
+func setKey(v interface{}) interface{} {
+	if f, ok := toFloat(v); ok {
+		return f
+	}
+	return v
+}
@@ -0,0 +1,100 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestIsIn(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	ints := newNullableInt64Column(pool, "i", []int64{1, 0, 2, 3}, []bool{true, false, true, true})
+	defer ints.Release()
+	strs := newStringColumn(pool, "s", []string{"a", "b", ""}, []bool{true, true, false})
+	defer strs.Release()
+
+	got, err := IsIn(pool, ints, 3, 1.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, want := fmt.Sprint(got.Data().Chunk(0)), "[true (null) false true]"; s != want {
+		t.Errorf("got=%s, want=%s", s, want)
+	}
+	got.Release()
+
+	got, err = IsIn(pool, strs, "b", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, want := fmt.Sprint(got.Data().Chunk(0)), "[false true (null)]"; s != want {
+		t.Errorf("got=%s, want=%s", s, want)
+	}
+	got.Release()
+
+	dict, err := DictionaryEncode(pool, strs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dict.Release()
+	got, err = IsIn(pool, dict, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, want := fmt.Sprint(got.Data().Chunk(0)), "[true false (null)]"; s != want {
+		t.Errorf("dictionary: got=%s, want=%s", s, want)
+	}
+	got.Release()
+
+	if _, err := IsIn(pool, strs, []string{"a"}); err == nil || err.Error() != `compute: IsIn of column "s": unsupported value type []string` {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	ints := newNullableInt64Column(pool, "i", []int64{1, 0, 2, 3, 5}, []bool{true, false, true, true, true})
+	defer ints.Release()
+	highs := newInt64Column(pool, "h", []int64{0, 9, 9}, []int64{2, 9})
+	defer highs.Release()
+
+	for _, tc := range []struct {
+		low, high interface{}
+		want      string
+	}{
+		{2, 3, "[false (null) true true false]"},
+		{1.5, 5, "[false (null) true true true]"},
+		{1, highs, "[false (null) true false true]"},
+	} {
+		got, err := Between(pool, ints, tc.low, tc.high)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := fmt.Sprint(got.Data().Chunk(0)); s != tc.want {
+			t.Errorf("between %v and %T: got=%s, want=%s", tc.low, tc.high, s, tc.want)
+		}
+		got.Release()
+	}
+
+	if _, err := Between(pool, ints, "a", "b"); err == nil {
+		t.Fatal("expected an error for string bounds of an integer column")
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// JoinType selects which rows HashJoin keeps.
+type JoinType int
+
+const (
+	// InnerJoin keeps the pairs of rows whose keys match.
+	InnerJoin JoinType = iota
+	// LeftJoin keeps the matching pairs plus every left row without a match.
+	LeftJoin
+	// SemiJoin keeps the left rows having a match, once each, without pairing
+	// them with right rows.
+	SemiJoin
+	// AntiJoin keeps the left rows without a match.
+	AntiJoin
+	// CrossJoin pairs every left row with every right row, without keys, see
+	// CrossJoinRows.
+	CrossJoin
+)
+
+func (t JoinType) String() string {
+	switch t {
+	case InnerJoin:
+		return "inner"
+	case LeftJoin:
+		return "left"
+	case SemiJoin:
+		return "semi"
+	case AntiJoin:
+		return "anti"
+	case CrossJoin:
+		return "cross"
+	default:
+		return fmt.Sprintf("JoinType(%d)", int(t))
+	}
+}
+
+// pairs reports whether t pairs left rows with right rows, in which case it is
+// InnerJoin or LeftJoin, rather than filtering the left rows.
+func (t JoinType) pairs() bool { return t == InnerJoin || t == LeftJoin }
+
+// HashJoin matches the rows of the left key columns with the rows of the right
+// key columns and returns the indices of the matching rows of each side.
+// For every left row the matches are listed in right row order, and a left row
+// without a match is paired with a null right index when how is LeftJoin.
+// Null keys never match, like in SQL.
+//
+// The keys of both sides are encoded to integer codes by a shared KeyEncoder,
+// so dictionary encoded keys are joined on their indices instead of their values.
+// SemiJoin and AntiJoin do not pair rows, see SemiJoinRows, and CrossJoin has
+// no keys, see CrossJoinRows.
+func HashJoin(mem memory.Allocator, left, right []*array.Column, how JoinType) (*array.Int64, *array.Int64, error) {
+	if !how.pairs() {
+		return nil, nil, fmt.Errorf("compute: HashJoin does not support %s joins", how)
+	}
+	if len(left) != len(right) {
+		return nil, nil, fmt.Errorf("compute: HashJoin needs the same number of left and right keys (%d != %d)", len(left), len(right))
+	}
+	t, err := NewHashTable(right)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t.Join(mem, left, how)
+}
+
+// HashTable holds the rows of the right key columns of a HashJoin chained by
+// key. It is built once and probed with any number of batches of left rows,
+// like the small side of a broadcast join is by the batches of the large side.
+type HashTable struct {
+	enc   *KeyEncoder
+	nkeys int
+	// head[code] is the first right row of a key code and next[row] the following one.
+	head []int32
+	next []int32
+}
+
+// NewHashTable builds the HashTable of the right key columns of a join.
+func NewHashTable(right []*array.Column) (*HashTable, error) {
+	enc := NewKeyEncoder(false)
+	codes, err := enc.Encode(right...)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &HashTable{enc: enc, nkeys: len(right), head: make([]int32, enc.NumKeys()), next: make([]int32, len(codes))}
+	for i := range t.head {
+		t.head[i] = -1
+	}
+	for row := len(codes) - 1; row >= 0; row-- {
+		code := codes[row]
+		if code < 0 {
+			continue
+		}
+		t.next[row] = t.head[code]
+		t.head[code] = int32(row)
+	}
+	return t, nil
+}
+
+// Join matches the rows of the left key columns with the right rows of t and
+// returns the indices of the matching rows of each side like HashJoin does.
+// The keys of left are looked up without being added to t.
+func (t *HashTable) Join(mem memory.Allocator, left []*array.Column, how JoinType) (*array.Int64, *array.Int64, error) {
+	if !how.pairs() {
+		return nil, nil, fmt.Errorf("compute: HashTable does not support %s joins", how)
+	}
+	if len(left) != t.nkeys {
+		return nil, nil, fmt.Errorf("compute: HashTable needs the same number of left and right keys (%d != %d)", len(left), t.nkeys)
+	}
+	codes, err := t.enc.Lookup(left...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lbldr := array.NewInt64Builder(mem)
+	defer lbldr.Release()
+	rbldr := array.NewInt64Builder(mem)
+	defer rbldr.Release()
+
+	for row, code := range codes {
+		matched := false
+		if code >= 0 {
+			for r := t.head[code]; r >= 0; r = t.next[r] {
+				lbldr.Append(int64(row))
+				rbldr.Append(int64(r))
+				matched = true
+			}
+		}
+		if !matched && how == LeftJoin {
+			lbldr.Append(int64(row))
+			rbldr.AppendNull()
+		}
+	}
+
+	return lbldr.NewInt64Array(), rbldr.NewInt64Array(), nil
+}
+
+// SemiJoinRows returns the indices of the left rows kept by a SemiJoin, the
+// rows whose keys match a row of the right key columns, or by an AntiJoin, the
+// rows whose keys match none, in left row order. Null keys never match, so an
+// AntiJoin keeps the left rows with a null key.
+//
+// Unlike HashJoin, only the set of right keys is built and every left row is
+// looked up once, so the pairs of matching rows are never listed.
+func SemiJoinRows(mem memory.Allocator, left, right []*array.Column, how JoinType) (*array.Int64, error) {
+	if how != SemiJoin && how != AntiJoin {
+		return nil, fmt.Errorf("compute: SemiJoinRows does not support %s joins", how)
+	}
+	leftCodes, rightCodes, numKeys, err := encodeJoinKeys("SemiJoinRows", left, right)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]bool, numKeys)
+	for _, code := range rightCodes {
+		if code >= 0 {
+			found[code] = true
+		}
+	}
+
+	bldr := array.NewInt64Builder(mem)
+	defer bldr.Release()
+	for row, code := range leftCodes {
+		matched := code >= 0 && int(code) < len(found) && found[code]
+		if matched == (how == SemiJoin) {
+			bldr.Append(int64(row))
+		}
+	}
+	return bldr.NewInt64Array(), nil
+}
+
+// encodeJoinKeys encodes the left and right keys of a join with a shared
+// KeyEncoder, right first, returning their codes and the number of codes of
+// the right keys. Null keys get negative codes.
+func encodeJoinKeys(name string, left, right []*array.Column) (leftCodes, rightCodes []int32, numKeys int, err error) {
+	if len(left) != len(right) {
+		return nil, nil, 0, fmt.Errorf("compute: %s needs the same number of left and right keys (%d != %d)", name, len(left), len(right))
+	}
+
+	enc := NewKeyEncoder(false)
+	if rightCodes, err = enc.Encode(right...); err != nil {
+		return nil, nil, 0, err
+	}
+	numKeys = enc.NumKeys()
+	if leftCodes, err = enc.Encode(left...); err != nil {
+		return nil, nil, 0, err
+	}
+	return leftCodes, rightCodes, numKeys, nil
+}
+
+// CrossJoinSize returns the number of rows of the cross join of leftRows rows
+// with rightRows rows, or an error when it exceeds maxRows. Checking the size
+// first makes an accidental cartesian product fail before anything is
+// allocated.
+func CrossJoinSize(leftRows, rightRows, maxRows int64) (int64, error) {
+	if leftRows < 0 || rightRows < 0 {
+		return 0, fmt.Errorf("compute: CrossJoinSize of %d and %d rows", leftRows, rightRows)
+	}
+	if rightRows > 0 && leftRows > maxRows/rightRows || leftRows*rightRows > maxRows {
+		return 0, fmt.Errorf("compute: cross join of %d and %d rows exceeds the maximum of %d rows", leftRows, rightRows, maxRows)
+	}
+	return leftRows * rightRows, nil
+}
+
+// CrossJoinRows returns the indices of the left and right rows of the pairs
+// start to start+n-1 of the cross join of leftRows rows with rightRows rows,
+// in which every left row is paired with every right row in turn. Generating
+// the pairs a range at a time lets a cross join be built in batches.
+func CrossJoinRows(mem memory.Allocator, leftRows, rightRows, start, n int64) (*array.Int64, *array.Int64, error) {
+	size, err := CrossJoinSize(leftRows, rightRows, math.MaxInt64)
+	if err != nil {
+		return nil, nil, err
+	}
+	if start < 0 || n < 0 || start > size || n > size-start {
+		return nil, nil, fmt.Errorf("compute: pairs [%d, %d) out of the %d pairs of the cross join", start, start+n, size)
+	}
+
+	lbldr := array.NewInt64Builder(mem)
+	defer lbldr.Release()
+	rbldr := array.NewInt64Builder(mem)
+	defer rbldr.Release()
+	lbldr.Reserve(int(n))
+	rbldr.Reserve(int(n))
+	for k := start; k < start+n; k++ {
+		lbldr.UnsafeAppend(k / rightRows)
+		rbldr.UnsafeAppend(k % rightRows)
+	}
+	return lbldr.NewInt64Array(), rbldr.NewInt64Array(), nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestCrossJoinRows(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	li, ri, err := CrossJoinRows(pool, 3, 2, 1, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer li.Release()
+	defer ri.Release()
+	if got, want := li.String(), "[0 1 1 2]"; got != want {
+		t.Errorf("got left=%s, want=%s", got, want)
+	}
+	if got, want := ri.String(), "[1 0 1 0]"; got != want {
+		t.Errorf("got right=%s, want=%s", got, want)
+	}
+
+	if _, _, err := CrossJoinRows(pool, 3, 2, 4, 3); err == nil {
+		t.Error("expected an error for pairs past the end of the cross join")
+	}
+
+	for _, tc := range []struct {
+		left, right, max int64
+		ok               bool
+	}{
+		{3, 4, 12, true},
+		{3, 4, 11, false},
+		{0, 5, 0, true},
+		{math.MaxInt64, 2, math.MaxInt64, false},
+	} {
+		size, err := CrossJoinSize(tc.left, tc.right, tc.max)
+		if (err == nil) != tc.ok {
+			t.Errorf("CrossJoinSize(%d, %d, %d): got error %v", tc.left, tc.right, tc.max, err)
+		}
+		if err == nil && size != tc.left*tc.right {
+			t.Errorf("CrossJoinSize(%d, %d, %d): got=%d", tc.left, tc.right, tc.max, size)
+		}
+	}
+}
+
+func TestHashTable(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	right := newStringColumn(pool, "k", []string{"c", "a", "", "a"}, []bool{true, true, false, true})
+	defer right.Release()
+	table, err := NewHashTable([]*array.Column{right})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The table is probed by several batches, whose new keys are not added to it.
+	for _, tc := range []struct {
+		keys        []string
+		left, right string
+	}{
+		{[]string{"a", "b"}, "[0 0 1]", "[1 3 (null)]"},
+		{[]string{"d", "c", "e"}, "[0 1 2]", "[(null) 0 (null)]"},
+	} {
+		left := newStringColumn(pool, "k", tc.keys, nil)
+		li, ri, err := table.Join(pool, []*array.Column{left}, LeftJoin)
+		left.Release()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := li.String(); got != tc.left {
+			t.Errorf("got left=%s, want=%s", got, tc.left)
+		}
+		if got := ri.String(); got != tc.right {
+			t.Errorf("got right=%s, want=%s", got, tc.right)
+		}
+		li.Release()
+		ri.Release()
+	}
+	if got, want := table.enc.NumKeys(), 2; got != want {
+		t.Fatalf("got=%d keys, want=%d", got, want)
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+)
+
+// KeyEncoder assigns dense integer codes to the rows of one or more key columns.
+// Rows with equal keys get the same code and codes are handed out in order of
+// first appearance, starting at 0. Codes are shared between calls to Encode,
+// so the key columns of both sides of a join can be encoded by one KeyEncoder.
+//
+// Dictionary encoded key columns are encoded from their indices: each distinct
+// dictionary value is looked up once, after which rows are remapped through a
+// slice indexed by the dictionary index. Columns using different dictionaries,
+// or mixing dictionary and plain chunks, are remapped to the same codes.
+type KeyEncoder struct {
+	nullsEqual bool
+	columns    []*columnEncoder
+	// levels[k] combines the codes of key columns 0..k with the code of column k+1.
+	levels []map[[2]int32]int32
+	n      int32
+}
+
+// NewKeyEncoder creates a KeyEncoder. When nullsEqual is true null is treated
+// as a regular key value, which is what a group by needs. Otherwise a row with
+// a null in any of its key columns is given the code -1, which is what a join needs.
+func NewKeyEncoder(nullsEqual bool) *KeyEncoder {
+	return &KeyEncoder{nullsEqual: nullsEqual}
+}
+
+// NumKeys returns the number of distinct keys encoded so far.
+func (e *KeyEncoder) NumKeys() int { return int(e.n) }
+
+// Encode returns the code of every row of keys. All the key columns must have
+// the same length, and every call must pass the same number of key columns.
+func (e *KeyEncoder) Encode(keys ...*array.Column) ([]int32, error) {
+	return e.encode(keys, false)
+}
+
+// Lookup returns the code of every row of keys like Encode, except that keys
+// which were not encoded before get the code -1 instead of a new code, so the
+// KeyEncoder is left as it is. This probes the keys of the rows of a join
+// against the keys of the other side, encoded once, without the KeyEncoder
+// growing with every probe.
+func (e *KeyEncoder) Lookup(keys ...*array.Column) ([]int32, error) {
+	return e.encode(keys, true)
+}
+
+func (e *KeyEncoder) encode(keys []*array.Column, lookup bool) ([]int32, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("compute: at least one key column is required")
+	}
+	if e.columns == nil {
+		e.columns = make([]*columnEncoder, len(keys))
+		for i := range e.columns {
+			e.columns[i] = newColumnEncoder()
+		}
+		e.levels = make([]map[[2]int32]int32, len(keys)-1)
+		for i := range e.levels {
+			e.levels[i] = make(map[[2]int32]int32)
+		}
+	}
+	if len(keys) != len(e.columns) {
+		return nil, fmt.Errorf("compute: expected %d key columns, got %d", len(e.columns), len(keys))
+	}
+
+	n := keys[0].Len()
+	codes := make([]int32, n)
+	for k, key := range keys {
+		if key.Len() != n {
+			return nil, fmt.Errorf("compute: key column %q has %d rows, want %d", key.Name(), key.Len(), n)
+		}
+
+		row := 0
+		for _, chunk := range key.Data().Chunks() {
+			chunkCodes, err := e.columns[k].encode(chunk, lookup)
+			if err != nil {
+				return nil, fmt.Errorf("compute: key column %q: %w", key.Name(), err)
+			}
+			for _, c := range chunkCodes {
+				if c == nullCode && e.nullsEqual {
+					c = e.columns[k].code(nullKey{}, lookup)
+				}
+				switch {
+				case k > 0 && codes[row] < 0:
+					// A previous key column was null.
+				case c < 0:
+					codes[row] = -1
+				case k == 0:
+					codes[row] = c
+				default:
+					codes[row] = e.combine(k-1, codes[row], c, lookup)
+				}
+				row++
+			}
+		}
+	}
+
+	// With a single key column the column codes are the key codes.
+	if len(keys) == 1 && !lookup {
+		e.n = e.columns[0].n
+	}
+
+	return codes, nil
+}
+
+// combine returns the code of the key made of prefix and the code c of the next
+// column, or -1 when looking up a key that was not encoded before.
+func (e *KeyEncoder) combine(level int, prefix, c int32, lookup bool) int32 {
+	pair := [2]int32{prefix, c}
+	if code, ok := e.levels[level][pair]; ok {
+		return code
+	}
+	if lookup {
+		return nullCode
+	}
+	code := int32(len(e.levels[level]))
+	e.levels[level][pair] = code
+	if level == len(e.levels)-1 {
+		e.n = code + 1
+	}
+	return code
+}
+
+// columnEncoder assigns codes to the values of a single key column.
+type columnEncoder struct {
+	values map[interface{}]int32
+	n      int32
+	// remaps caches, for every dictionary seen so far, the code of each
+	// dictionary value or -1 when the value has not been referenced yet.
+	remaps map[*array.Data][]int32
+}
+
+func newColumnEncoder() *columnEncoder {
+	return &columnEncoder{
+		values: make(map[interface{}]int32),
+		remaps: make(map[*array.Data][]int32),
+	}
+}
+
+// Codes given by columnEncoder.encode to the rows without a code.
+const (
+	// nullCode is the code of null values, and of the rows of keys not seen
+	// before by KeyEncoder.Lookup.
+	nullCode int32 = -1
+	// unknownCode is the code of values not seen before when looking them up.
+	unknownCode int32 = -2
+)
+
+// code returns the code of key, assigning a new one if key was not seen before
+// unless lookup is true, in which case unknownCode is returned.
+func (c *columnEncoder) code(key interface{}, lookup bool) int32 {
+	if code, ok := c.values[key]; ok {
+		return code
+	}
+	if lookup {
+		return unknownCode
+	}
+	code := c.n
+	c.values[key] = code
+	c.n++
+	return code
+}
+
+// encode returns the codes of the values of arr. Null values are given
+// nullCode, and values not seen before unknownCode when lookup is true.
+func (c *columnEncoder) encode(arr array.Interface, lookup bool) ([]int32, error) {
+	codes := make([]int32, arr.Len())
+
+	if dict, ok := arr.(*array.Dictionary); ok {
+		values := dict.Dictionary()
+		key, err := keyGetter(values)
+		if err != nil {
+			return nil, err
+		}
+		remap, ok := c.remaps[values.Data()]
+		if !ok {
+			remap = make([]int32, values.Len())
+			for i := range remap {
+				remap[i] = nullCode
+			}
+			// Lookups do not cache the dictionaries they see.
+			if !lookup {
+				c.remaps[values.Data()] = remap
+			}
+		}
+		for i := range codes {
+			if dict.IsNull(i) {
+				codes[i] = nullCode
+				continue
+			}
+			j := dict.GetValueIndex(i)
+			if remap[j] < 0 && values.IsValid(j) {
+				code := c.code(key(j), lookup)
+				if code < 0 {
+					codes[i] = code
+					continue
+				}
+				remap[j] = code
+			}
+			codes[i] = remap[j]
+		}
+		return codes, nil
+	}
+
+	key, err := keyGetter(arr)
+	if err != nil {
+		return nil, err
+	}
+	for i := range codes {
+		if arr.IsNull(i) {
+			codes[i] = nullCode
+			continue
+		}
+		codes[i] = c.code(key(i), lookup)
+	}
+	return codes, nil
+}
+
+// nullKey is the key of null values when nulls are equal.
+type nullKey struct{}
+
+// nanKey is the key of every floating point NaN.
+type nanKey struct{}
+
+// keyGetter returns a function returning a comparable Go value for the i-th value of arr.
+// Integers are widened so that keys of different integer widths compare equal,
+// and floating point values are normalized so that 0 == -0 and NaN == NaN.
+func keyGetter(arr array.Interface) (func(i int) interface{}, error) {
+	switch a := arr.(type) {
+	case *array.Boolean:
+		return func(i int) interface{} { return a.Value(i) }, nil
+	case *array.Int8, *array.Int16, *array.Int32, *array.Int64,
+		*array.Date32, *array.Date64, *array.Time32, *array.Time64,
+		*array.Timestamp, *array.Duration, *array.MonthInterval:
+		get := int64Getter(arr)
+		return func(i int) interface{} { return get(i) }, nil
+	case *array.Uint8, *array.Uint16, *array.Uint32, *array.Uint64:
+		get := uint64Getter(arr)
+		return func(i int) interface{} { return get(i) }, nil
+	case *array.Float16, *array.Float32, *array.Float64:
+		get := float64Getter(arr)
+		return func(i int) interface{} {
+			v := get(i)
+			switch {
+			case v == 0:
+				return float64(0)
+			case math.IsNaN(v):
+				return nanKey{}
+			}
+			return v
+		}, nil
+	case *array.String:
+		return func(i int) interface{} { return a.Value(i) }, nil
+	case *array.Binary:
+		return func(i int) interface{} { return string(a.Value(i)) }, nil
+	case *array.FixedSizeBinary:
+		return func(i int) interface{} { return string(a.Value(i)) }, nil
+	case *array.Decimal128:
+		return func(i int) interface{} { return a.Value(i) }, nil
+	case *array.DayTimeInterval:
+		return func(i int) interface{} { return a.Value(i) }, nil
+	case *array.MonthDayNanoInterval:
+		return func(i int) interface{} { return a.Value(i) }, nil
+	case *array.RunEndEncoded:
+		get, err := keyGetter(a.Values())
+		if err != nil {
+			return nil, err
+		}
+		return func(i int) interface{} { return get(a.GetPhysicalIndex(i)) }, nil
+	default:
+		return nil, fmt.Errorf("%T cannot be used as a key", arr)
+	}
+}
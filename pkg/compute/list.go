@@ -0,0 +1,178 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// ListLengths returns the number of elements of every list of col, null for null lists.
+func ListLengths(mem memory.Allocator, col *array.Column) (*array.Column, error) {
+	if _, err := listElem(col); err != nil {
+		return nil, err
+	}
+
+	bldr := array.NewInt64Builder(mem)
+	defer bldr.Release()
+	bldr.Reserve(col.Len())
+	for _, chunk := range col.Data().Chunks() {
+		_, bounds := listChunk(chunk)
+		for i := 0; i < chunk.Len(); i++ {
+			if chunk.IsNull(i) {
+				bldr.AppendNull()
+				continue
+			}
+			beg, end := bounds(i)
+			bldr.Append(end - beg)
+		}
+	}
+	return newResultColumn(col.Name(), bldr.NewArray()), nil
+}
+
+// ListSum returns the sum of the non-null elements of every list of col, an
+// int64 for lists of integers and a float64 for lists of floating point numbers.
+// Null lists have a null sum and empty lists a sum of zero.
+func ListSum(mem memory.Allocator, col *array.Column) (*array.Column, error) {
+	elem, err := listElem(col)
+	if err != nil {
+		return nil, err
+	}
+
+	switch classOf(elem) {
+	case signedClass, unsignedClass:
+		bldr := array.NewInt64Builder(mem)
+		defer bldr.Release()
+		bldr.Reserve(col.Len())
+		for _, chunk := range col.Data().Chunks() {
+			values, bounds := listChunk(chunk)
+			get := int64Getter(values)
+			if classOf(elem) == unsignedClass {
+				getUint := uint64Getter(values)
+				get = func(k int) int64 { return int64(getUint(k)) }
+			}
+			for i := 0; i < chunk.Len(); i++ {
+				if chunk.IsNull(i) {
+					bldr.AppendNull()
+					continue
+				}
+				var sum int64
+				beg, end := bounds(i)
+				for k := int(beg); k < int(end); k++ {
+					if values.IsValid(k) {
+						sum += get(k)
+					}
+				}
+				bldr.Append(sum)
+			}
+		}
+		return newResultColumn(col.Name(), bldr.NewArray()), nil
+
+	case floatClass:
+		bldr := array.NewFloat64Builder(mem)
+		defer bldr.Release()
+		bldr.Reserve(col.Len())
+		for _, chunk := range col.Data().Chunks() {
+			values, bounds := listChunk(chunk)
+			get := float64Getter(values)
+			for i := 0; i < chunk.Len(); i++ {
+				if chunk.IsNull(i) {
+					bldr.AppendNull()
+					continue
+				}
+				var sum float64
+				beg, end := bounds(i)
+				for k := int(beg); k < int(end); k++ {
+					if values.IsValid(k) {
+						sum += get(k)
+					}
+				}
+				bldr.Append(sum)
+			}
+		}
+		return newResultColumn(col.Name(), bldr.NewArray()), nil
+
+	default:
+		return nil, fmt.Errorf("compute: cannot sum lists of %s", elem)
+	}
+}
+
+// ListContains reports whether every list of col holds an element equal to
+// value, null for null lists. Numbers compare by value whatever their type.
+func ListContains(mem memory.Allocator, col *array.Column, value interface{}) (*array.Column, error) {
+	elem, err := listElem(col)
+	if err != nil {
+		return nil, err
+	}
+	read, err := castReader(elem)
+	if err != nil {
+		return nil, fmt.Errorf("compute: cannot search lists of %s", elem)
+	}
+	equal, err := valueSet(value)
+	if err != nil {
+		return nil, fmt.Errorf("compute: ListContains of column %q: %w", col.Name(), err)
+	}
+
+	bldr := array.NewBooleanBuilder(mem)
+	defer bldr.Release()
+	bldr.Reserve(col.Len())
+	for _, chunk := range col.Data().Chunks() {
+		values, bounds := listChunk(chunk)
+		for i := 0; i < chunk.Len(); i++ {
+			if chunk.IsNull(i) {
+				bldr.AppendNull()
+				continue
+			}
+			found := false
+			beg, end := bounds(i)
+			for k := int(beg); k < int(end) && !found; k++ {
+				found = values.IsValid(k) && equal(read(values, k))
+			}
+			bldr.Append(found)
+		}
+	}
+	return newResultColumn(col.Name(), bldr.NewArray()), nil
+}
+
+// listElem returns the type of the elements of the list column col.
+func listElem(col *array.Column) (arrow.DataType, error) {
+	switch dtype := col.DataType().(type) {
+	case *arrow.ListType:
+		return dtype.Elem(), nil
+	case *arrow.FixedSizeListType:
+		return dtype.Elem(), nil
+	default:
+		return nil, fmt.Errorf("compute: column %q of type %s does not hold lists", col.Name(), col.DataType())
+	}
+}
+
+// listChunk returns the values of a chunk of a list column and a function
+// returning the range of the values of list i, read from the offsets.
+func listChunk(chunk array.Interface) (array.Interface, func(i int) (int64, int64)) {
+	switch chunk := chunk.(type) {
+	case *array.List:
+		offsets := chunk.Offsets()[chunk.Data().Offset():]
+		return chunk.ListValues(), func(i int) (int64, int64) { return int64(offsets[i]), int64(offsets[i+1]) }
+	case *array.FixedSizeList:
+		n := int64(chunk.DataType().(*arrow.FixedSizeListType).Len())
+		offset := int64(chunk.Data().Offset())
+		return chunk.ListValues(), func(i int) (int64, int64) { return (offset + int64(i)) * n, (offset + int64(i) + 1) * n }
+	default:
+		panic(fmt.Errorf("compute: %T is not a list", chunk))
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestListKernels(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	col := newInt64ListColumn(pool, "l", [][]int64{{1, 2}, nil, {}}, [][]int64{{3, 4, 5}})
+	defer col.Release()
+
+	for _, tc := range []struct {
+		name string
+		fn   func() (*array.Column, error)
+		want string
+	}{
+		{"lengths", func() (*array.Column, error) { return ListLengths(pool, col) }, "[2 (null) 0 3]"},
+		{"sum", func() (*array.Column, error) { return ListSum(pool, col) }, "[3 (null) 0 12]"},
+		{"contains 4", func() (*array.Column, error) { return ListContains(pool, col, 4) }, "[false (null) false true]"},
+		{"contains 2.0", func() (*array.Column, error) { return ListContains(pool, col, 2.0) }, "[true (null) false false]"},
+		{"contains string", func() (*array.Column, error) { return ListContains(pool, col, "1") }, "[false (null) false false]"},
+	} {
+		got, err := tc.fn()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := fmt.Sprint(got.Data().Chunk(0)); s != tc.want {
+			t.Errorf("%s: got=%s, want=%s", tc.name, s, tc.want)
+		}
+		got.Release()
+	}
+
+	ints := newInt64Column(pool, "i", []int64{1})
+	defer ints.Release()
+	if _, err := ListLengths(pool, ints); err == nil {
+		t.Fatal("expected an error for a column that does not hold lists")
+	}
+}
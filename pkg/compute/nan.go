@@ -0,0 +1,122 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/float16"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// NaNPolicy selects how aggregations treat floating point NaNs, which some
+// sources use to mark missing values where others use nulls. Nulls are always
+// skipped.
+type NaNPolicy int
+
+const (
+	// NaNPropagate treats NaNs as values: sums and means holding one are NaN,
+	// and NaNs are greater than every other number for AggMin and AggMax.
+	// This is the policy of Sum, Mean, MinMax and AggregateGroups.
+	NaNPropagate NaNPolicy = iota
+	// NaNSkip treats NaNs as missing values, skipping them like nulls.
+	NaNSkip
+)
+
+func (p NaNPolicy) String() string {
+	switch p {
+	case NaNPropagate:
+		return "propagate"
+	case NaNSkip:
+		return "skip"
+	default:
+		return fmt.Sprintf("NaNPolicy(%d)", int(p))
+	}
+}
+
+// missing returns a function reporting whether the value at index i of arr is
+// missing under p: null, or NaN with NaNSkip.
+func (p NaNPolicy) missing(arr array.Interface) func(i int) bool {
+	if p == NaNSkip && classOf(arr.DataType()) == floatClass {
+		get := float64Getter(arr)
+		return func(i int) bool { return arr.IsNull(i) || math.IsNaN(get(i)) }
+	}
+	return arr.IsNull
+}
+
+// NaNToNull returns a copy of the floating point column col whose NaNs are
+// replaced by nulls.
+func NaNToNull(mem memory.Allocator, col *array.Column) (*array.Column, error) {
+	return mapFloats(mem, col, "NaNToNull", func(v float64, valid bool) (float64, bool) {
+		return v, valid && !math.IsNaN(v)
+	})
+}
+
+// NullToNaN returns a copy of the floating point column col whose nulls are
+// replaced by NaNs, so that it has no nulls.
+func NullToNaN(mem memory.Allocator, col *array.Column) (*array.Column, error) {
+	return mapFloats(mem, col, "NullToNaN", func(v float64, valid bool) (float64, bool) {
+		if !valid {
+			return math.NaN(), true
+		}
+		return v, true
+	})
+}
+
+// mapFloats returns a copy of the floating point column col, chunk by chunk,
+// whose values and validity are mapped by fn.
+func mapFloats(mem memory.Allocator, col *array.Column, name string, fn func(v float64, valid bool) (float64, bool)) (*array.Column, error) {
+	if classOf(col.DataType()) != floatClass {
+		return nil, fmt.Errorf("compute: %s expects a floating point column, got %s", name, col.DataType())
+	}
+
+	chunks := make([]array.Interface, 0, len(col.Data().Chunks()))
+	defer func() {
+		for _, chunk := range chunks {
+			chunk.Release()
+		}
+	}()
+	for _, chunk := range col.Data().Chunks() {
+		bldr := array.NewBuilder(mem, col.DataType())
+		var appendValue func(float64)
+		switch b := bldr.(type) {
+		case *array.Float16Builder:
+			appendValue = func(v float64) { b.Append(float16.New(float32(v))) }
+		case *array.Float32Builder:
+			appendValue = func(v float64) { b.Append(float32(v)) }
+		case *array.Float64Builder:
+			appendValue = b.Append
+		}
+
+		get := float64Getter(chunk)
+		bldr.Reserve(chunk.Len())
+		for i := 0; i < chunk.Len(); i++ {
+			v, valid := fn(get(i), chunk.IsValid(i))
+			if !valid {
+				bldr.AppendNull()
+				continue
+			}
+			appendValue(v)
+		}
+		chunks = append(chunks, bldr.NewArray())
+		bldr.Release()
+	}
+
+	chunked := array.NewChunked(col.DataType(), chunks)
+	defer chunked.Release()
+	return array.NewColumn(col.Field(), chunked), nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/object"
+)
+
+func TestNaNNullConversions(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	b := array.NewFloat32Builder(pool)
+	defer b.Release()
+	b.AppendValues([]float32{1, float32(math.NaN()), 0, 4}, []bool{true, true, false, true})
+	arr := b.NewArray()
+	defer arr.Release()
+	col := newSingleChunkColumn("v", arr)
+	defer col.Release()
+
+	nulls, err := NaNToNull(pool, col)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nulls.Release()
+	if got, want := fmt.Sprint(nulls.Data().Chunk(0)), "[1 (null) (null) 4]"; got != want {
+		t.Errorf("NaNToNull: got=%s, want=%s", got, want)
+	}
+
+	nans, err := NullToNaN(pool, col)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nans.Release()
+	if got, want := fmt.Sprint(nans.Data().Chunk(0)), "[1 NaN NaN 4]"; got != want {
+		t.Errorf("NullToNaN: got=%s, want=%s", got, want)
+	}
+	if got := nans.NullN(); got != 0 {
+		t.Errorf("NullToNaN: got %d nulls, want none", got)
+	}
+
+	ints := newInt64Column(pool, "i", []int64{1, 2})
+	defer ints.Release()
+	if _, err := NaNToNull(pool, ints); err == nil {
+		t.Fatal("expected an error converting an integer column")
+	}
+}
+
+func TestNaNPolicy(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	b := array.NewFloat64Builder(pool)
+	defer b.Release()
+	b.AppendValues([]float64{1, math.NaN(), 3, 0, 8}, []bool{true, true, true, false, true})
+	arr := b.NewArray()
+	defer arr.Release()
+	col := newSingleChunkColumn("v", arr)
+	defer col.Release()
+
+	sum, err := Sum(col)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, ok := sum.(object.Float64); !ok || !math.IsNaN(float64(f)) {
+		t.Errorf("Sum: got=%v, want=NaN", sum)
+	}
+	if sum, err = NaNSkip.Sum(col); err != nil {
+		t.Fatal(err)
+	}
+	if sum != object.NewFloat64(12) {
+		t.Errorf("NaNSkip.Sum: got=%v, want=12", sum)
+	}
+	mean, err := NaNSkip.Mean(col)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mean != object.NewFloat64(4) {
+		t.Errorf("NaNSkip.Mean: got=%v, want=4", mean)
+	}
+	min, max, err := NaNSkip.MinMax(col)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if min != object.NewFloat64(1) || max != object.NewFloat64(8) {
+		t.Errorf("NaNSkip.MinMax: got=%v, %v, want=1, 8", min, max)
+	}
+
+	keys := newStringColumn(pool, "k", []string{"a", "a", "b", "b", "b"}, nil)
+	defer keys.Release()
+	g, err := GroupRows(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		policy NaNPolicy
+		kind   AggregateKind
+		want   string
+	}{
+		{NaNPropagate, AggCount, "[2 2]"},
+		{NaNSkip, AggCount, "[1 2]"},
+		{NaNPropagate, AggMean, "[NaN 5.5]"},
+		{NaNSkip, AggMean, "[1 5.5]"},
+		{NaNPropagate, AggMax, "[NaN 8]"},
+		{NaNSkip, AggMax, "[1 8]"},
+	} {
+		got, err := tc.policy.AggregateGroups(pool, col, g, tc.kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := fmt.Sprint(got.Data().Chunk(0)); s != tc.want {
+			t.Errorf("%v %v: got=%s, want=%s", tc.policy, tc.kind, s, tc.want)
+		}
+		got.Release()
+	}
+}
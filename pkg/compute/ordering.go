@@ -0,0 +1,319 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/pkg/metadata"
+)
+
+// SortOrder is the order in which values are ranked.
+type SortOrder int
+
+const (
+	// Ascending orders values from smallest to largest.
+	Ascending SortOrder = iota
+	// Descending orders values from largest to smallest.
+	Descending
+)
+
+func (o SortOrder) String() string {
+	switch o {
+	case Ascending:
+		return "ascending"
+	case Descending:
+		return "descending"
+	default:
+		return fmt.Sprintf("SortOrder(%d)", int(o))
+	}
+}
+
+// WithSortOrder returns field with metadata recording that the rows are sorted
+// by its values in order, so that operators such as merge joins can rely on it.
+func WithSortOrder(field arrow.Field, order SortOrder) arrow.Field {
+	field.Metadata = metadata.WithSortOrder(field.Metadata, order.String())
+	return field
+}
+
+// FieldSortOrder returns the sort order recorded in the metadata of field by
+// WithSortOrder, if any.
+func FieldSortOrder(field arrow.Field) (SortOrder, bool) {
+	switch order, _ := metadata.SortOrder(field.Metadata); order {
+	case Ascending.String():
+		return Ascending, true
+	case Descending.String():
+		return Descending, true
+	default:
+		return 0, false
+	}
+}
+
+// position addresses a single value of a chunked column.
+type position struct {
+	chunk int
+	index int
+}
+
+// valueComparator compares the non-null values at two positions of the
+// same column, returning -1, 0 or +1.
+type valueComparator func(a, b position) int
+
+// newValueComparator returns a comparator for the values of the given chunks.
+// Floating point NaNs sort after every other number.
+func newValueComparator(chunks []array.Interface) (valueComparator, error) {
+	if len(chunks) == 0 {
+		return func(a, b position) int { return 0 }, nil
+	}
+
+	switch chunks[0].(type) {
+	case *array.Int8, *array.Int16, *array.Int32, *array.Int64,
+		*array.Date32, *array.Date64, *array.Time32, *array.Time64,
+		*array.Timestamp, *array.Duration, *array.MonthInterval:
+		get := make([]func(int) int64, len(chunks))
+		for c, chunk := range chunks {
+			get[c] = int64Getter(chunk)
+		}
+		return func(a, b position) int {
+			return compareInt64(get[a.chunk](a.index), get[b.chunk](b.index))
+		}, nil
+
+	case *array.Uint8, *array.Uint16, *array.Uint32, *array.Uint64:
+		get := make([]func(int) uint64, len(chunks))
+		for c, chunk := range chunks {
+			get[c] = uint64Getter(chunk)
+		}
+		return func(a, b position) int {
+			return compareUint64(get[a.chunk](a.index), get[b.chunk](b.index))
+		}, nil
+
+	case *array.Float16, *array.Float32, *array.Float64:
+		get := make([]func(int) float64, len(chunks))
+		for c, chunk := range chunks {
+			get[c] = float64Getter(chunk)
+		}
+		return func(a, b position) int {
+			return compareFloat64(get[a.chunk](a.index), get[b.chunk](b.index))
+		}, nil
+
+	case *array.Boolean:
+		return func(a, b position) int {
+			l := chunks[a.chunk].(*array.Boolean).Value(a.index)
+			r := chunks[b.chunk].(*array.Boolean).Value(b.index)
+			switch {
+			case l == r:
+				return 0
+			case !l:
+				return -1
+			default:
+				return 1
+			}
+		}, nil
+
+	case *array.String:
+		return func(a, b position) int {
+			return strings.Compare(
+				chunks[a.chunk].(*array.String).Value(a.index),
+				chunks[b.chunk].(*array.String).Value(b.index),
+			)
+		}, nil
+
+	case *array.Binary:
+		return func(a, b position) int {
+			return bytes.Compare(
+				chunks[a.chunk].(*array.Binary).Value(a.index),
+				chunks[b.chunk].(*array.Binary).Value(b.index),
+			)
+		}, nil
+
+	case *array.FixedSizeBinary:
+		return func(a, b position) int {
+			return bytes.Compare(
+				chunks[a.chunk].(*array.FixedSizeBinary).Value(a.index),
+				chunks[b.chunk].(*array.FixedSizeBinary).Value(b.index),
+			)
+		}, nil
+
+	case *array.Decimal128:
+		return func(a, b position) int {
+			l := chunks[a.chunk].(*array.Decimal128).Value(a.index)
+			r := chunks[b.chunk].(*array.Decimal128).Value(b.index)
+			if c := compareInt64(l.HighBits(), r.HighBits()); c != 0 {
+				return c
+			}
+			return compareUint64(l.LowBits(), r.LowBits())
+		}, nil
+
+	case *array.DayTimeInterval:
+		return func(a, b position) int {
+			l := chunks[a.chunk].(*array.DayTimeInterval).Value(a.index)
+			r := chunks[b.chunk].(*array.DayTimeInterval).Value(b.index)
+			if c := compareInt64(int64(l.Days), int64(r.Days)); c != 0 {
+				return c
+			}
+			return compareInt64(int64(l.Milliseconds), int64(r.Milliseconds))
+		}, nil
+
+	case *array.MonthDayNanoInterval:
+		return func(a, b position) int {
+			l := chunks[a.chunk].(*array.MonthDayNanoInterval).Value(a.index)
+			r := chunks[b.chunk].(*array.MonthDayNanoInterval).Value(b.index)
+			if c := compareInt64(int64(l.Months), int64(r.Months)); c != 0 {
+				return c
+			}
+			if c := compareInt64(int64(l.Days), int64(r.Days)); c != 0 {
+				return c
+			}
+			return compareInt64(l.Nanoseconds, r.Nanoseconds)
+		}, nil
+
+	case *array.Dictionary:
+		// Dictionary values are compared through the values they index.
+		dicts := make([]*array.Dictionary, len(chunks))
+		values := make([]array.Interface, len(chunks))
+		for c, chunk := range chunks {
+			dicts[c] = chunk.(*array.Dictionary)
+			values[c] = dicts[c].Dictionary()
+		}
+		cmp, err := newValueComparator(values)
+		if err != nil {
+			return nil, err
+		}
+		return func(a, b position) int {
+			return cmp(
+				position{chunk: a.chunk, index: dicts[a.chunk].GetValueIndex(a.index)},
+				position{chunk: b.chunk, index: dicts[b.chunk].GetValueIndex(b.index)},
+			)
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("compute: ordering not defined for %T", chunks[0])
+	}
+}
+
+// newFieldComparator returns a comparator for the values of the given chunks
+// of a column of field. The values of a field declaring categories are
+// ordered as its categories.
+func newFieldComparator(field arrow.Field, chunks []array.Interface) (valueComparator, error) {
+	if categories, ok := FieldCategories(field); ok {
+		return newCategoryComparator(categories, chunks)
+	}
+	return newValueComparator(chunks)
+}
+
+// int64Getter returns an accessor that widens the signed values of arr to int64.
+func int64Getter(arr array.Interface) func(int) int64 {
+	switch a := arr.(type) {
+	case *array.Int8:
+		return func(i int) int64 { return int64(a.Value(i)) }
+	case *array.Int16:
+		return func(i int) int64 { return int64(a.Value(i)) }
+	case *array.Int32:
+		return func(i int) int64 { return int64(a.Value(i)) }
+	case *array.Int64:
+		return a.Value
+	case *array.Date32:
+		return func(i int) int64 { return int64(a.Value(i)) }
+	case *array.Date64:
+		return func(i int) int64 { return int64(a.Value(i)) }
+	case *array.Time32:
+		return func(i int) int64 { return int64(a.Value(i)) }
+	case *array.Time64:
+		return func(i int) int64 { return int64(a.Value(i)) }
+	case *array.Timestamp:
+		return func(i int) int64 { return int64(a.Value(i)) }
+	case *array.Duration:
+		return func(i int) int64 { return int64(a.Value(i)) }
+	case *array.MonthInterval:
+		return func(i int) int64 { return int64(a.Value(i)) }
+	default:
+		panic(fmt.Errorf("compute: %T is not a signed integer array", arr))
+	}
+}
+
+// uint64Getter returns an accessor that widens the unsigned values of arr to uint64.
+func uint64Getter(arr array.Interface) func(int) uint64 {
+	switch a := arr.(type) {
+	case *array.Uint8:
+		return func(i int) uint64 { return uint64(a.Value(i)) }
+	case *array.Uint16:
+		return func(i int) uint64 { return uint64(a.Value(i)) }
+	case *array.Uint32:
+		return func(i int) uint64 { return uint64(a.Value(i)) }
+	case *array.Uint64:
+		return a.Value
+	default:
+		panic(fmt.Errorf("compute: %T is not an unsigned integer array", arr))
+	}
+}
+
+// float64Getter returns an accessor that widens the floating point values of arr to float64.
+func float64Getter(arr array.Interface) func(int) float64 {
+	switch a := arr.(type) {
+	case *array.Float16:
+		return func(i int) float64 { return float64(a.Value(i).Float32()) }
+	case *array.Float32:
+		return func(i int) float64 { return float64(a.Value(i)) }
+	case *array.Float64:
+		return a.Value
+	default:
+		panic(fmt.Errorf("compute: %T is not a floating point array", arr))
+	}
+}
+
+func compareInt64(l, r int64) int {
+	switch {
+	case l < r:
+		return -1
+	case l > r:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareUint64(l, r uint64) int {
+	switch {
+	case l < r:
+		return -1
+	case l > r:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareFloat64(l, r float64) int {
+	lnan, rnan := math.IsNaN(l), math.IsNaN(r)
+	switch {
+	case lnan && rnan:
+		return 0
+	case lnan:
+		return 1
+	case rnan:
+		return -1
+	case l < r:
+		return -1
+	case l > r:
+		return 1
+	default:
+		return 0
+	}
+}
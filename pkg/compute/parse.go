@@ -0,0 +1,105 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// TimestampLayouts are the layouts tried by default when parsing timestamps:
+// RFC 3339, its variant with a space instead of the T and no time zone, and dates.
+var TimestampLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02"}
+
+// ParseErrorPolicy selects what a parsing kernel does with the strings it cannot parse.
+type ParseErrorPolicy int
+
+const (
+	// ParseNull turns the strings that cannot be parsed into nulls.
+	ParseNull ParseErrorPolicy = iota
+	// ParseFail fails on the first string that cannot be parsed.
+	ParseFail
+)
+
+// ParseTime parses s with the first of layouts that accepts it. Layouts
+// without a time zone read the time in loc. The error is the one of the last layout.
+func ParseTime(s string, layouts []string, loc *time.Location) (time.Time, error) {
+	err := fmt.Errorf("no layout to parse %q", s)
+	for _, layout := range layouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// ParseTimestamp parses the strings of col to a timestamp column of the given
+// unit and time zone, named like col, trying layouts in order. Layouts without
+// a time zone read the times in tz, an IANA name such as "Europe/Paris", or in
+// UTC when tz is empty. Times are truncated to the unit.
+//
+// It also returns whether every row failed to parse, which null strings never do.
+// With ParseNull these rows are null, with ParseFail the first one is an error.
+func ParseTimestamp(mem memory.Allocator, col *array.Column, layouts []string, unit arrow.TimeUnit, tz string, onError ParseErrorPolicy) (*array.Column, *array.Boolean, error) {
+	if col.DataType().ID() != arrow.STRING {
+		return nil, nil, fmt.Errorf("compute: cannot parse column %q of type %s", col.Name(), col.DataType())
+	}
+	if len(layouts) == 0 {
+		return nil, nil, fmt.Errorf("compute: ParseTimestamp of column %q needs a layout", col.Name())
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, nil, fmt.Errorf("compute: %w", err)
+	}
+
+	dtype := &arrow.TimestampType{Unit: unit, TimeZone: tz}
+	scale := unitNanoseconds(unit)
+	bldr := array.NewTimestampBuilder(mem, dtype)
+	defer bldr.Release()
+	bldr.Reserve(col.Len())
+	failed := array.NewBooleanBuilder(mem)
+	defer failed.Release()
+	failed.Reserve(col.Len())
+
+	row := 0
+	for _, chunk := range col.Data().Chunks() {
+		strs := chunk.(*array.String)
+		for i := 0; i < strs.Len(); i, row = i+1, row+1 {
+			if strs.IsNull(i) {
+				bldr.AppendNull()
+				failed.Append(false)
+				continue
+			}
+			t, err := ParseTime(strs.Value(i), layouts, loc)
+			if err != nil {
+				if onError == ParseFail {
+					return nil, nil, fmt.Errorf("compute: ParseTimestamp of column %q, row %d: %w", col.Name(), row, err)
+				}
+				bldr.AppendNull()
+				failed.Append(true)
+				continue
+			}
+			bldr.Append(arrow.Timestamp(t.UnixNano() / scale))
+			failed.Append(false)
+		}
+	}
+
+	return newResultColumn(col.Name(), bldr.NewArray()), failed.NewBooleanArray(), nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	col := newStringColumn(pool, "ts", []string{
+		"2020-01-02T03:04:05Z",
+		"2020-01-02 01:00:00",
+		"02/01/2020",
+		"",
+		"yesterday",
+	}, []bool{true, true, true, false, true})
+	defer col.Release()
+	layouts := append([]string{"02/01/2006"}, TimestampLayouts...)
+
+	got, failed, err := ParseTimestamp(pool, col, layouts, arrow.Second, "Europe/Paris", ParseNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Release()
+	defer failed.Release()
+
+	// Paris is an hour ahead of UTC in January.
+	if s, want := fmt.Sprint(got.Data().Chunk(0)), "[1577934245 1577923200 1577919600 (null) (null)]"; s != want {
+		t.Fatalf("got=%s, want=%s", s, want)
+	}
+	if s, want := fmt.Sprint(failed), "[false false false false true]"; s != want {
+		t.Fatalf("got failed=%s, want=%s", s, want)
+	}
+	if s, want := fmt.Sprint(got.DataType()), "timestamp[s, tz=Europe/Paris]"; s != want {
+		t.Fatalf("got type=%s, want=%s", s, want)
+	}
+
+	_, _, err = ParseTimestamp(pool, col, layouts, arrow.Second, "", ParseFail)
+	if err == nil || err.Error() != `compute: ParseTimestamp of column "ts", row 4: parsing time "yesterday" as "2006-01-02": cannot parse "yesterday" as "2006"` {
+		t.Fatalf("got error %v", err)
+	}
+	if _, _, err := ParseTimestamp(pool, col, layouts, arrow.Second, "Nowhere/Town", ParseNull); err == nil {
+		t.Fatal("expected an error for an unknown time zone")
+	}
+}
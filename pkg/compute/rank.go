@@ -0,0 +1,211 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// RankMethod selects the rank given to tied values.
+type RankMethod int
+
+const (
+	// RankMin gives tied values the lowest of their ranks, as in 1 2 2 4.
+	RankMin RankMethod = iota
+	// RankMax gives tied values the highest of their ranks, as in 1 3 3 4.
+	RankMax
+	// RankDense gives tied values the same rank without gaps, as in 1 2 2 3.
+	RankDense
+	// RankAverage gives tied values the mean of their ranks, as in 1 2.5 2.5 4.
+	RankAverage
+	// RankFirst breaks ties by row order, as in 1 2 3 4.
+	RankFirst
+)
+
+func (m RankMethod) String() string {
+	switch m {
+	case RankMin:
+		return "min"
+	case RankMax:
+		return "max"
+	case RankDense:
+		return "dense"
+	case RankAverage:
+		return "average"
+	case RankFirst:
+		return "first"
+	default:
+		return fmt.Sprintf("RankMethod(%d)", int(m))
+	}
+}
+
+// Rank returns the ranks, starting at 1, of the values of col in the given
+// order. Ties are broken according to method. Nulls are not ranked and their
+// rank is null. RankAverage gives float64 ranks, the other methods int64 ranks.
+func Rank(mem memory.Allocator, col *array.Column, method RankMethod, order SortOrder) (*array.Column, error) {
+	return RankGroups(mem, col, nil, method, order)
+}
+
+// RankGroups ranks the values of col within each of the groups g, as returned
+// by GroupRows.
+func RankGroups(mem memory.Allocator, col *array.Column, g *Groups, method RankMethod, order SortOrder) (*array.Column, error) {
+	runs, err := rankRuns(col, g, order)
+	if err != nil {
+		return nil, err
+	}
+
+	if method == RankAverage {
+		ranks := make([]float64, col.Len())
+		runs.each(func(rows []int64, start, _ int) {
+			avg := float64(start) + float64(len(rows)-1)/2
+			for _, row := range rows {
+				ranks[row] = avg
+			}
+		})
+		return newFloat64Result(mem, col.Name(), ranks, runs.valid), nil
+	}
+
+	ranks := make([]int64, col.Len())
+	switch method {
+	case RankMin, RankMax, RankFirst:
+		runs.each(func(rows []int64, start, _ int) {
+			for i, row := range rows {
+				switch method {
+				case RankMin:
+					ranks[row] = int64(start)
+				case RankMax:
+					ranks[row] = int64(start + len(rows) - 1)
+				default:
+					ranks[row] = int64(start + i)
+				}
+			}
+		})
+	case RankDense:
+		runs.each(func(rows []int64, _, dense int) {
+			for _, row := range rows {
+				ranks[row] = int64(dense)
+			}
+		})
+	default:
+		return nil, fmt.Errorf("compute: unknown rank method %v", method)
+	}
+	return newInt64Result(mem, col.Name(), ranks, runs.valid), nil
+}
+
+// PercentRank returns the relative ranks of the values of col, computed as
+// (rank - 1) / (rows - 1) with the RankMin rank and the number of non-null
+// rows, so that they range from 0 to 1. Nulls get a null percent rank.
+func PercentRank(mem memory.Allocator, col *array.Column, order SortOrder) (*array.Column, error) {
+	return PercentRankGroups(mem, col, nil, order)
+}
+
+// PercentRankGroups computes the percent ranks of the values of col within
+// each of the groups g.
+func PercentRankGroups(mem memory.Allocator, col *array.Column, g *Groups, order SortOrder) (*array.Column, error) {
+	runs, err := rankRuns(col, g, order)
+	if err != nil {
+		return nil, err
+	}
+
+	ranks := make([]float64, col.Len())
+	runs.each(func(rows []int64, start, _ int) {
+		n := runs.size[runs.group(rows[0])]
+		for _, row := range rows {
+			if n > 1 {
+				ranks[row] = float64(start-1) / float64(n-1)
+			}
+		}
+	})
+	return newFloat64Result(mem, col.Name(), ranks, runs.valid), nil
+}
+
+// rankedRuns holds the non-null rows of a column ordered by group then value.
+type rankedRuns struct {
+	rows  []int64
+	ties  []bool // ties[i] is true when rows[i] ties with rows[i-1]
+	valid []bool
+	group func(row int64) int32
+	size  map[int32]int
+}
+
+// each calls fn with every run of tied rows, its first rank and its dense rank
+// within its group.
+func (r *rankedRuns) each(fn func(rows []int64, start, dense int)) {
+	start, dense := 1, 0
+	for i := 0; i < len(r.rows); {
+		if i > 0 && r.group(r.rows[i]) != r.group(r.rows[i-1]) {
+			start, dense = 1, 0
+		}
+		j := i + 1
+		for j < len(r.rows) && r.ties[j] {
+			j++
+		}
+		dense++
+		fn(r.rows[i:j], start, dense)
+		start += j - i
+		i = j
+	}
+}
+
+func rankRuns(col *array.Column, g *Groups, order SortOrder) (*rankedRuns, error) {
+	if g != nil && len(g.IDs) != col.Len() {
+		return nil, fmt.Errorf("compute: groups of %d rows for column %q of %d rows", len(g.IDs), col.Name(), col.Len())
+	}
+	chunks := col.Data().Chunks()
+	cmp, err := newKeyComparator(col.Field(), chunks, order)
+	if err != nil {
+		return nil, fmt.Errorf("compute: rank of column %q: %w", col.Name(), err)
+	}
+
+	r := &rankedRuns{
+		valid: make([]bool, col.Len()),
+		group: func(int64) int32 { return 0 },
+		size:  make(map[int32]int),
+	}
+	if g != nil {
+		r.group = func(row int64) int32 { return g.IDs[row] }
+	}
+	positions := make([]position, 0, col.Len())
+	for c, chunk := range chunks {
+		for i := 0; i < chunk.Len(); i++ {
+			row := int64(len(positions))
+			positions = append(positions, position{chunk: c, index: i})
+			if chunk.IsNull(i) {
+				continue
+			}
+			r.valid[row] = true
+			r.rows = append(r.rows, row)
+			r.size[r.group(row)]++
+		}
+	}
+
+	sort.SliceStable(r.rows, func(i, j int) bool {
+		a, b := r.rows[i], r.rows[j]
+		if ga, gb := r.group(a), r.group(b); ga != gb {
+			return ga < gb
+		}
+		return cmp.compare(positions[a], positions[b]) < 0
+	})
+	r.ties = make([]bool, len(r.rows))
+	for i := 1; i < len(r.rows); i++ {
+		a, b := r.rows[i-1], r.rows[i]
+		r.ties[i] = r.group(a) == r.group(b) && cmp.compare(positions[a], positions[b]) == 0
+	}
+	return r, nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestRank(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	scores := newFloat64Column(pool, "score", []float64{10, 30, 0, 20, 30, 10}, []bool{true, true, false, true, true, true})
+	defer scores.Release()
+
+	for _, tc := range []struct {
+		method RankMethod
+		order  SortOrder
+		want   string
+	}{
+		{RankMin, Ascending, "[1 4 (null) 3 4 1]"},
+		{RankMax, Ascending, "[2 5 (null) 3 5 2]"},
+		{RankDense, Ascending, "[1 3 (null) 2 3 1]"},
+		{RankAverage, Ascending, "[1.5 4.5 (null) 3 4.5 1.5]"},
+		{RankFirst, Ascending, "[1 4 (null) 3 5 2]"},
+		{RankMin, Descending, "[4 1 (null) 3 1 4]"},
+		{RankDense, Descending, "[3 1 (null) 2 1 3]"},
+	} {
+		got, err := Rank(pool, scores, tc.method, tc.order)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := fmt.Sprint(got.Data().Chunk(0)); s != tc.want {
+			t.Errorf("%v %v: got %s, want %s", tc.method, tc.order, s, tc.want)
+		}
+		got.Release()
+	}
+
+	pct, err := PercentRank(pool, scores, Ascending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pct.Release()
+	if got, want := fmt.Sprint(pct.Data().Chunk(0)), "[0 0.75 (null) 0.5 0.75 0]"; got != want {
+		t.Errorf("percent rank: got %s, want %s", got, want)
+	}
+}
+
+func TestRankGroups(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	teams := newStringColumn(pool, "team", []string{"a", "b", "a", "b", "a", "c"}, nil)
+	defer teams.Release()
+	points := newInt64Column(pool, "points", []int64{5, 7}, []int64{9, 7, 1, 3})
+	defer points.Release()
+
+	g, err := GroupRows(teams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ranks, err := RankGroups(pool, points, g, RankMin, Descending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ranks.Release()
+	if got, want := fmt.Sprint(ranks.Data().Chunk(0)), "[2 1 1 1 3 1]"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	pct, err := PercentRankGroups(pool, points, g, Ascending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pct.Release()
+	if got, want := fmt.Sprint(pct.Data().Chunk(0)), "[0.5 0 1 0 0 0]"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	x := newInt64Column(pool, "x", []int64{1})
+	defer x.Release()
+	other, err := GroupRows(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RankGroups(pool, points, other, RankMin, Ascending); err == nil {
+		t.Error("expected an error for groups of another length")
+	}
+}
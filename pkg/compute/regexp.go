@@ -0,0 +1,150 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// RegexpExtract matches pattern against every string of col and returns a
+// string column per capture group holding the text the group matched. groups
+// selects the capture groups by number, 0 being the whole match, and defaults
+// to every capture group of pattern. A column is named after its group when
+// the group is named, and after col and the group number otherwise.
+//
+// Rows that are null or do not match are null in every column, and so are the
+// rows where an optional group did not take part in the match.
+func RegexpExtract(mem memory.Allocator, col *array.Column, pattern string, groups ...int) ([]*array.Column, error) {
+	re, groups, fields, err := regexpFields(col, pattern, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	bldrs := make([]*array.StringBuilder, len(groups))
+	for i := range bldrs {
+		bldrs[i] = array.NewStringBuilder(mem)
+		defer bldrs[i].Release()
+		bldrs[i].Reserve(col.Len())
+	}
+	extractGroups(col, re, func(s string, loc []int) {
+		for i, group := range groups {
+			appendGroup(bldrs[i], s, loc, group)
+		}
+	}, func() {
+		for _, bldr := range bldrs {
+			bldr.AppendNull()
+		}
+	})
+
+	cols := make([]*array.Column, len(groups))
+	for i, bldr := range bldrs {
+		cols[i] = newResultColumn(fields[i].Name, bldr.NewArray())
+	}
+	return cols, nil
+}
+
+// RegexpExtractStruct is like RegexpExtract but returns a single struct column
+// named like col, with a string field per capture group. Rows that are null or
+// do not match are null.
+func RegexpExtractStruct(mem memory.Allocator, col *array.Column, pattern string, groups ...int) (*array.Column, error) {
+	re, groups, fields, err := regexpFields(col, pattern, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	bldr := array.NewStructBuilder(mem, arrow.StructOf(fields...))
+	defer bldr.Release()
+	bldr.Reserve(col.Len())
+	extractGroups(col, re, func(s string, loc []int) {
+		bldr.Append(true)
+		for i, group := range groups {
+			appendGroup(bldr.FieldBuilder(i).(*array.StringBuilder), s, loc, group)
+		}
+	}, bldr.AppendNull)
+
+	return newResultColumn(col.Name(), bldr.NewArray()), nil
+}
+
+// regexpFields compiles pattern and returns the extracted groups and their fields.
+func regexpFields(col *array.Column, pattern string, groups []int) (*regexp.Regexp, []int, []arrow.Field, error) {
+	if col.DataType().ID() != arrow.STRING {
+		return nil, nil, nil, fmt.Errorf("compute: cannot match column %q of type %s", col.Name(), col.DataType())
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("compute: %w", err)
+	}
+	if len(groups) == 0 {
+		groups = allGroups(re)
+	}
+
+	names := re.SubexpNames()
+	fields := make([]arrow.Field, len(groups))
+	for i, group := range groups {
+		if group < 0 || group > re.NumSubexp() {
+			return nil, nil, nil, fmt.Errorf("compute: pattern %q has no group %d", pattern, group)
+		}
+		name := names[group]
+		if name == "" {
+			name = fmt.Sprintf("%s_%d", col.Name(), group)
+		}
+		fields[i] = arrow.Field{Name: name, Type: arrow.BinaryTypes.String, Nullable: true}
+	}
+	return re, groups, fields, nil
+}
+
+// allGroups returns the numbers of the capture groups of re.
+func allGroups(re *regexp.Regexp) []int {
+	groups := make([]int, re.NumSubexp())
+	for i := range groups {
+		groups[i] = i + 1
+	}
+	return groups
+}
+
+// extractGroups calls match with every string of col matching re and the
+// indices of its groups, and miss for the other rows.
+func extractGroups(col *array.Column, re *regexp.Regexp, match func(s string, loc []int), miss func()) {
+	for _, chunk := range col.Data().Chunks() {
+		strs := chunk.(*array.String)
+		for i := 0; i < strs.Len(); i++ {
+			if strs.IsNull(i) {
+				miss()
+				continue
+			}
+			// Value slices the data buffer of the chunk, the string is not copied.
+			s := strs.Value(i)
+			loc := re.FindStringSubmatchIndex(s)
+			if loc == nil {
+				miss()
+				continue
+			}
+			match(s, loc)
+		}
+	}
+}
+
+func appendGroup(bldr *array.StringBuilder, s string, loc []int, group int) {
+	if loc[2*group] < 0 {
+		bldr.AppendNull()
+		return
+	}
+	bldr.Append(s[loc[2*group]:loc[2*group+1]])
+}
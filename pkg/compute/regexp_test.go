@@ -0,0 +1,74 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestRegexpExtract(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	lines := newStringColumn(pool, "line", []string{
+		"GET /index.html 200",
+		"POST /login 401 slow",
+		"garbage",
+		"",
+	}, []bool{true, true, true, false})
+	defer lines.Release()
+	pattern := `^(?P<method>[A-Z]+) (\S+) (\d+)( slow)?`
+
+	cols, err := RegexpExtract(pool, lines, pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, col := range cols {
+		got = append(got, fmt.Sprint(col.Name(), col.Data().Chunk(0)))
+		col.Release()
+	}
+	want := []string{
+		`method["GET" "POST" (null) (null)]`,
+		`line_2["/index.html" "/login" (null) (null)]`,
+		`line_3["200" "401" (null) (null)]`,
+		`line_4[(null) " slow" (null) (null)]`,
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got=%v, want=%v", got, want)
+	}
+
+	st, err := RegexpExtractStruct(pool, lines, pattern, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Release()
+	if got, want := fmt.Sprint(st.DataType()), "struct<method: utf8, line_3: utf8>"; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+	if got, want := fmt.Sprint(st.Data().Chunk(0)), `{["GET" "POST" (null) (null)] ["200" "401" (null) (null)]}`; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+
+	if _, err := RegexpExtract(pool, lines, pattern, 5); err == nil || err.Error() != fmt.Sprintf("compute: pattern %q has no group 5", pattern) {
+		t.Fatalf("got error %v", err)
+	}
+	if _, err := RegexpExtract(pool, lines, "("); err == nil {
+		t.Fatal("expected an error for an invalid pattern")
+	}
+}
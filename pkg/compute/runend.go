@@ -0,0 +1,301 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// RunEndEncode encodes col as a run-end encoded column with int32 run ends.
+// Each chunk is encoded on its own, consecutive equal values form a run and
+// consecutive nulls form a null run.
+func RunEndEncode(mem memory.Allocator, col *array.Column) (*array.Column, error) {
+	if _, ok := col.DataType().(*arrow.RunEndEncodedType); ok {
+		return array.NewColumn(col.Field(), col.Data()), nil
+	}
+
+	arrs := make([]array.Interface, 0, len(col.Data().Chunks()))
+	defer func() {
+		for _, arr := range arrs {
+			arr.Release()
+		}
+	}()
+
+	for _, chunk := range col.Data().Chunks() {
+		key, err := keyGetter(chunk)
+		if err != nil {
+			return nil, fmt.Errorf("compute: cannot run-end encode %s: %w", col.DataType(), err)
+		}
+
+		bldr := array.NewRunEndEncodedBuilder(mem, col.DataType())
+		var last interface{}
+		for i := 0; i < chunk.Len(); i++ {
+			if chunk.IsNull(i) {
+				bldr.AppendNull()
+				last = nil
+				continue
+			}
+			k := key(i)
+			if last != nil && k == last {
+				bldr.ContinueRun(1)
+				continue
+			}
+			last = k
+			bldr.Append(1)
+			if err := AppendValue(bldr.ValueBuilder(), chunk, i); err != nil {
+				bldr.Release()
+				return nil, err
+			}
+		}
+		arrs = append(arrs, bldr.NewArray())
+		bldr.Release()
+	}
+
+	dtype := &arrow.RunEndEncodedType{RunEndType: arrow.PrimitiveTypes.Int32, ValueType: col.DataType()}
+	chunked := array.NewChunked(dtype, arrs)
+	defer chunked.Release()
+
+	field := col.Field()
+	field.Type = dtype
+	return array.NewColumn(field, chunked), nil
+}
+
+// RunEndDecode converts a run-end encoded column back to a column of its value type.
+func RunEndDecode(mem memory.Allocator, col *array.Column) (*array.Column, error) {
+	dtype, ok := col.DataType().(*arrow.RunEndEncodedType)
+	if !ok {
+		return nil, fmt.Errorf("compute: RunEndDecode expects a run-end encoded column, got %s", col.DataType())
+	}
+
+	arrs := make([]array.Interface, 0, len(col.Data().Chunks()))
+	defer func() {
+		for _, arr := range arrs {
+			arr.Release()
+		}
+	}()
+
+	for _, chunk := range col.Data().Chunks() {
+		ree := chunk.(*array.RunEndEncoded)
+		bldr := array.NewBuilder(mem, dtype.ValueType)
+		bldr.Reserve(ree.Len())
+		err := forEachRun(ree, func(j, n int) error {
+			for k := 0; k < n; k++ {
+				if err := AppendValue(bldr, ree.Values(), j); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			bldr.Release()
+			return nil, err
+		}
+		arrs = append(arrs, bldr.NewArray())
+		bldr.Release()
+	}
+
+	chunked := array.NewChunked(dtype.ValueType, arrs)
+	defer chunked.Release()
+
+	field := col.Field()
+	field.Type = dtype.ValueType
+	return array.NewColumn(field, chunked), nil
+}
+
+// takeRunEndEncoded is Take for run-end encoded columns.
+func takeRunEndEncoded(mem memory.Allocator, col *array.Column, indices *array.Int64) (*array.Column, error) {
+	dtype := col.DataType().(*arrow.RunEndEncodedType)
+	chunks := col.Data().Chunks()
+	locate := newChunkLocator(chunks)
+
+	runs := newRunAppender(mem, dtype.ValueType)
+	defer runs.release()
+
+	for i := 0; i < indices.Len(); i++ {
+		if indices.IsNull(i) {
+			runs.appendNull()
+			continue
+		}
+		c, j, err := locate(indices.Value(i))
+		if err != nil {
+			return nil, err
+		}
+		if err := runs.append(chunks[c].(*array.RunEndEncoded), j); err != nil {
+			return nil, err
+		}
+	}
+
+	return runs.newColumn(col.Field()), nil
+}
+
+// Filter builds a new Column holding the rows of col whose mask value is true.
+// mask must be a boolean column of the same length as col, a null mask value
+// drops the row. The result is a single chunk.
+//
+// Run-end encoded columns are filtered run by run and stay encoded.
+func Filter(mem memory.Allocator, col, mask *array.Column) (*array.Column, error) {
+	if mask.DataType().ID() != arrow.BOOL {
+		return nil, fmt.Errorf("compute: Filter expects a boolean mask, got %s", mask.DataType())
+	}
+	if col.Len() != mask.Len() {
+		return nil, fmt.Errorf("compute: Filter column and mask lengths differ (%d != %d)", col.Len(), mask.Len())
+	}
+
+	dtype, ok := col.DataType().(*arrow.RunEndEncodedType)
+	if !ok {
+		indices := selectedRows(mem, mask)
+		defer indices.Release()
+		return Take(mem, col, indices)
+	}
+
+	selected := newRowCursor(mask)
+	keep := func() bool {
+		arr, i := selected.next()
+		return arr.IsValid(i) && arr.(*array.Boolean).Value(i)
+	}
+
+	bldr := array.NewRunEndEncodedBuilder(mem, dtype.ValueType)
+	defer bldr.Release()
+
+	for _, chunk := range col.Data().Chunks() {
+		ree := chunk.(*array.RunEndEncoded)
+		err := forEachRun(ree, func(j, n int) error {
+			count := 0
+			for k := 0; k < n; k++ {
+				if keep() {
+					count++
+				}
+			}
+			switch {
+			case count == 0:
+				return nil
+			case ree.Values().IsNull(j):
+				for k := 0; k < count; k++ {
+					bldr.AppendNull()
+				}
+				return nil
+			default:
+				bldr.Append(count)
+				return AppendValue(bldr.ValueBuilder(), ree.Values(), j)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newColumnFromBuilder(col.Field(), bldr), nil
+}
+
+// forEachRun calls fn with the physical index and the length of each run of arr.
+func forEachRun(arr *array.RunEndEncoded, fn func(j, n int) error) error {
+	beg, first := 0, arr.PhysicalOffset()
+	for j := first; j < first+arr.PhysicalLength(); j++ {
+		end := arr.RunEnd(j)
+		if err := fn(j, end-beg); err != nil {
+			return err
+		}
+		beg = end
+	}
+	return nil
+}
+
+// runAppender appends rows of run-end encoded arrays to a builder, extending
+// the last run when a row belongs to the same run as the previous row.
+type runAppender struct {
+	bldr *array.RunEndEncodedBuilder
+	last *array.RunEndEncoded
+	run  int
+}
+
+func newRunAppender(mem memory.Allocator, dtype arrow.DataType) *runAppender {
+	return &runAppender{bldr: array.NewRunEndEncodedBuilder(mem, dtype)}
+}
+
+func (r *runAppender) append(arr *array.RunEndEncoded, i int) error {
+	j := arr.GetPhysicalIndex(i)
+	if arr == r.last && j == r.run {
+		r.bldr.ContinueRun(1)
+		return nil
+	}
+	r.last, r.run = arr, j
+	if arr.Values().IsNull(j) {
+		r.bldr.AppendNull()
+		return nil
+	}
+	r.bldr.Append(1)
+	return AppendValue(r.bldr.ValueBuilder(), arr.Values(), j)
+}
+
+func (r *runAppender) appendNull() {
+	r.last = nil
+	r.bldr.AppendNull()
+}
+
+func (r *runAppender) newColumn(field arrow.Field) *array.Column {
+	return newColumnFromBuilder(field, r.bldr)
+}
+
+func (r *runAppender) release() { r.bldr.Release() }
+
+// newColumnFromBuilder returns a single chunk Column holding the array built by bldr.
+// The type of field is replaced by the type of the array.
+func newColumnFromBuilder(field arrow.Field, bldr array.Builder) *array.Column {
+	arr := bldr.NewArray()
+	defer arr.Release()
+
+	chunked := array.NewChunked(arr.DataType(), []array.Interface{arr})
+	defer chunked.Release()
+
+	field.Type = arr.DataType()
+	return array.NewColumn(field, chunked)
+}
+
+// selectedRows returns the rows where mask is valid and true. The runs of
+// selected rows are read from the bitmaps of the mask a word at a time.
+func selectedRows(mem memory.Allocator, mask *array.Column) *array.Int64 {
+	bldr := array.NewInt64Builder(mem)
+	defer bldr.Release()
+
+	var row int64
+	for _, chunk := range mask.Data().Chunks() {
+		data := chunk.Data()
+		offset := data.Offset()
+		values := data.Buffers()[1].Bytes()
+		if chunk.NullN() > 0 {
+			// Keep only the valid values by and-ing the bitmaps over the bytes of the chunk.
+			beg, end := offset/8, int(bitutil.BytesForBits(int64(offset+chunk.Len())))
+			selected := make([]byte, end-beg)
+			bitutil.BitmapAnd(selected, values[beg:end], data.Buffers()[0].Bytes()[beg:end])
+			values, offset = selected, offset%8
+		}
+
+		rdr := bitutil.NewBitRunReader(values, offset, chunk.Len())
+		for run := rdr.NextRun(); run.Len > 0; run = rdr.NextRun() {
+			if run.Set {
+				for i := 0; i < run.Len; i++ {
+					bldr.Append(row + int64(i))
+				}
+			}
+			row += int64(run.Len)
+		}
+	}
+	return bldr.NewInt64Array()
+}
@@ -0,0 +1,266 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/object"
+)
+
+func newNullableInt64Column(mem memory.Allocator, name string, values []int64, valid []bool) *array.Column {
+	b := array.NewInt64Builder(mem)
+	defer b.Release()
+	b.AppendValues(values, valid)
+	arr := b.NewArray()
+	defer arr.Release()
+	return newSingleChunkColumn(name, arr)
+}
+
+func TestRunEndEncode(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	col := newNullableInt64Column(pool, "v", []int64{1, 1, 1, 0, 0, 2, 2, 1}, []bool{true, true, true, false, false, true, true, true})
+	defer col.Release()
+
+	enc, err := RunEndEncode(pool, col)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Release()
+
+	ree := enc.Data().Chunk(0).(*array.RunEndEncoded)
+	if got, want := ree.RunEnds().(*array.Int32).String(), "[3 5 7 8]"; got != want {
+		t.Fatalf("got run ends=%s, want=%s", got, want)
+	}
+	if got, want := ree.Values().(*array.Int64).String(), "[1 (null) 2 1]"; got != want {
+		t.Fatalf("got values=%s, want=%s", got, want)
+	}
+	if got, want := ree.String(), "[1 1 1 (null) (null) 2 2 1]"; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+	if got, want := enc.NullN(), 2; got != want {
+		t.Fatalf("got=%d nulls, want=%d", got, want)
+	}
+
+	// A slice starts and ends in the middle of a run.
+	slice := array.NewSlice(ree, 1, 6).(*array.RunEndEncoded)
+	defer slice.Release()
+	if got, want := slice.String(), "[1 1 (null) (null) 2]"; got != want {
+		t.Fatalf("got slice=%s, want=%s", got, want)
+	}
+	if got, want := slice.PhysicalLength(), 3; got != want {
+		t.Fatalf("got=%d runs, want=%d", got, want)
+	}
+
+	dec, err := RunEndDecode(pool, enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Release()
+	if !array.ArrayEqual(dec.Data().Chunk(0), col.Data().Chunk(0)) {
+		t.Fatalf("got=%v, want=%v", dec.Data().Chunk(0), col.Data().Chunk(0))
+	}
+}
+
+func TestRunEndEncodedKernels(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	col := newNullableInt64Column(pool, "v", []int64{4, 4, 4, 0, 5, 5}, []bool{true, true, true, false, true, true})
+	defer col.Release()
+	enc, err := RunEndEncode(pool, col)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Release()
+
+	ib := array.NewInt64Builder(pool)
+	defer ib.Release()
+	ib.AppendValues([]int64{0, 1, 3, 5, 4, 0}, []bool{true, true, true, true, true, false})
+	indices := ib.NewInt64Array()
+	defer indices.Release()
+
+	taken, err := Take(pool, enc, indices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Release()
+	if got, want := taken.Data().Chunk(0).(*array.RunEndEncoded).RunEnds().(*array.Int32).String(), "[2 3 5 6]"; got != want {
+		t.Fatalf("got run ends=%s, want=%s", got, want)
+	}
+	if got, want := taken.Data().Chunk(0).(*array.RunEndEncoded).String(), "[4 4 (null) 5 5 (null)]"; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+
+	bb := array.NewBooleanBuilder(pool)
+	defer bb.Release()
+	bb.AppendValues([]bool{true, false, true, true, true, false}, []bool{true, true, true, true, true, false})
+	maskArr := bb.NewArray()
+	defer maskArr.Release()
+	mask := newSingleChunkColumn("m", maskArr)
+	defer mask.Release()
+
+	filtered, err := Filter(pool, enc, mask)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer filtered.Release()
+	if got, want := filtered.Data().Chunk(0).(*array.RunEndEncoded).String(), "[4 4 (null) 5]"; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+
+	plain, err := Filter(pool, col, mask)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plain.Release()
+	if got, want := plain.Data().Chunk(0).(*array.Int64).String(), "[4 4 (null) 5]"; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+
+	for _, c := range []*array.Column{col, enc} {
+		sum, err := Sum(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := sum, object.NewInt64(22); got != want {
+			t.Fatalf("%s: got sum=%v, want=%v", c.DataType(), got, want)
+		}
+		mean, err := Mean(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := mean, object.NewFloat64(4.4); got != want {
+			t.Fatalf("%s: got mean=%v, want=%v", c.DataType(), got, want)
+		}
+	}
+}
+
+func TestFilterChunkedMask(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	// Three chunks sliced at odd offsets, the second one with nulls.
+	bb := array.NewBooleanBuilder(pool)
+	defer bb.Release()
+	var chunks []array.Interface
+	var want []int64
+	var row int64
+	for c := 0; c < 3; c++ {
+		for i := 0; i < 150; i++ {
+			if c == 1 && i%7 == 0 {
+				bb.AppendNull()
+				continue
+			}
+			bb.Append(i%3 != 0 || i > 100)
+		}
+		arr := bb.NewBooleanArray()
+		slice := array.NewSlice(arr, 3, 140).(*array.Boolean)
+		arr.Release()
+		for i := 0; i < slice.Len(); i++ {
+			if slice.IsValid(i) && slice.Value(i) {
+				want = append(want, row)
+			}
+			row++
+		}
+		chunks = append(chunks, slice)
+	}
+	chunked := array.NewChunked(arrow.FixedWidthTypes.Boolean, chunks)
+	for _, chunk := range chunks {
+		chunk.Release()
+	}
+	mask := array.NewColumn(arrow.Field{Name: "m", Type: arrow.FixedWidthTypes.Boolean, Nullable: true}, chunked)
+	chunked.Release()
+	defer mask.Release()
+
+	values := make([]int64, row)
+	for i := range values {
+		values[i] = int64(i)
+	}
+	col := newNullableInt64Column(pool, "v", values, nil)
+	defer col.Release()
+
+	filtered, err := Filter(pool, col, mask)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer filtered.Release()
+	got := filtered.Data().Chunk(0).(*array.Int64).Int64Values()
+	if len(got) != len(want) {
+		t.Fatalf("got=%d rows, want=%d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("row %d: got=%d, want=%d", i, got[i], want[i])
+		}
+	}
+}
+
+func TestConcat(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	a := newStringColumn(pool, "date", []string{"2020-01-01", "2020-01-01"}, nil)
+	defer a.Release()
+	b := newStringColumn(pool, "date", []string{"2020-01-01"}, nil)
+	defer b.Release()
+	c := newStringColumn(pool, "date", []string{"2020-01-02"}, nil)
+	defer c.Release()
+
+	constant, err := Concat(pool, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer constant.Release()
+
+	ree, ok := constant.Data().Chunk(0).(*array.RunEndEncoded)
+	if !ok {
+		t.Fatalf("got type=%s, want a run-end encoded column", constant.DataType())
+	}
+	if got, want := ree.PhysicalLength(), 1; got != want {
+		t.Fatalf("got=%d runs, want=%d", got, want)
+	}
+	if got, want := ree.String(), `["2020-01-01" "2020-01-01" "2020-01-01"]`; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+
+	mixed, err := Concat(pool, a, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mixed.Release()
+	if got, want := mixed.DataType(), arrow.DataType(arrow.BinaryTypes.String); got != want {
+		t.Fatalf("got type=%s, want=%s", got, want)
+	}
+
+	// Concatenating a run-end encoded column keeps the runs.
+	runs, err := Concat(pool, constant, constant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer runs.Release()
+	if got, want := runs.Data().Chunk(0).(*array.RunEndEncoded).PhysicalLength(), 1; got != want {
+		t.Fatalf("got=%d runs, want=%d", got, want)
+	}
+
+	if _, err := Concat(pool, a, constant); err == nil {
+		t.Fatal("expected an error concatenating columns of different types")
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// SampleIndices draws n distinct rows out of total uniformly at random and
+// returns their indices in increasing order. The same seed draws the same rows.
+func SampleIndices(mem memory.Allocator, total, n int64, seed int64) (*array.Int64, error) {
+	if n < 0 || n > total {
+		return nil, fmt.Errorf("compute: cannot sample %d rows out of %d", n, total)
+	}
+	rows := sampleRows(rand.New(rand.NewSource(seed)), total, n)
+	return newIndices(mem, rows), nil
+}
+
+// SampleFraction returns the number of rows sampled out of total for frac,
+// rounded to the nearest integer.
+func SampleFraction(total int64, frac float64) (int64, error) {
+	if frac < 0 || frac > 1 || math.IsNaN(frac) {
+		return 0, fmt.Errorf("compute: sample fraction %v is not in [0, 1]", frac)
+	}
+	return int64(math.Round(frac * float64(total))), nil
+}
+
+// StratifiedSampleIndices samples the fraction frac of the rows of every group
+// of equal keys, so that every group keeps its share of the sample, and
+// returns the indices of the sampled rows in increasing order.
+func StratifiedSampleIndices(mem memory.Allocator, keys []*array.Column, frac float64, seed int64) (*array.Int64, error) {
+	if _, err := SampleFraction(0, frac); err != nil {
+		return nil, err
+	}
+	g, err := GroupRows(keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	strata := make([][]int64, g.NumGroups())
+	for row, id := range g.IDs {
+		strata[id] = append(strata[id], int64(row))
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	var rows []int64
+	for _, stratum := range strata {
+		n, _ := SampleFraction(int64(len(stratum)), frac)
+		for _, i := range sampleRows(rng, int64(len(stratum)), n) {
+			rows = append(rows, stratum[i])
+		}
+	}
+	sortInt64s(rows)
+	return newIndices(mem, rows), nil
+}
+
+// Reservoir keeps a uniform random sample of at most n of the rows offered to
+// it, without knowing their number in advance.
+type Reservoir struct {
+	rng   *rand.Rand
+	n     int
+	seen  int64
+	slots []int64
+}
+
+// NewReservoir returns a Reservoir sampling n rows.
+func NewReservoir(n int, seed int64) *Reservoir {
+	return &Reservoir{rng: rand.New(rand.NewSource(seed)), n: n}
+}
+
+// Offer offers the next row to the reservoir, numbering rows in the order they
+// are offered. It returns the slot the row is stored in, and the row it
+// evicts if any, or -1 when the row is not sampled.
+func (r *Reservoir) Offer() (slot int, evicted int64) {
+	row := r.seen
+	r.seen++
+	if len(r.slots) < r.n {
+		r.slots = append(r.slots, row)
+		return len(r.slots) - 1, -1
+	}
+	j := r.rng.Int63n(r.seen)
+	if j >= int64(r.n) {
+		return -1, -1
+	}
+	evicted, r.slots[j] = r.slots[j], row
+	return int(j), evicted
+}
+
+// Rows returns the sampled rows in increasing order.
+func (r *Reservoir) Rows() []int64 {
+	rows := append([]int64(nil), r.slots...)
+	sortInt64s(rows)
+	return rows
+}
+
+// sampleRows draws n distinct rows out of total with Floyd's algorithm, which
+// only needs memory for the sample, and returns them in increasing order.
+func sampleRows(rng *rand.Rand, total, n int64) []int64 {
+	chosen := make(map[int64]struct{}, n)
+	rows := make([]int64, 0, n)
+	for j := total - n; j < total; j++ {
+		t := rng.Int63n(j + 1)
+		if _, ok := chosen[t]; ok {
+			t = j
+		}
+		chosen[t] = struct{}{}
+		rows = append(rows, t)
+	}
+	sortInt64s(rows)
+	return rows
+}
+
+func sortInt64s(v []int64) {
+	sort.Slice(v, func(i, j int) bool { return v[i] < v[j] })
+}
+
+func newIndices(mem memory.Allocator, rows []int64) *array.Int64 {
+	bldr := array.NewInt64Builder(mem)
+	defer bldr.Release()
+	bldr.AppendValues(rows, nil)
+	return bldr.NewInt64Array()
+}
@@ -0,0 +1,134 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestSampleIndices(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	indices, err := SampleIndices(pool, 100, 10, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer indices.Release()
+	if indices.Len() != 10 {
+		t.Fatalf("got %d rows, want 10", indices.Len())
+	}
+	values := indices.Int64Values()
+	for i, v := range values {
+		if v < 0 || v >= 100 || (i > 0 && v <= values[i-1]) {
+			t.Fatalf("rows are not distinct, increasing and in range: %v", values)
+		}
+	}
+
+	again, err := SampleIndices(pool, 100, 10, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer again.Release()
+	if !equalInt64s(again.Int64Values(), values) {
+		t.Fatalf("same seed drew %v then %v", values, again.Int64Values())
+	}
+
+	all, err := SampleIndices(pool, 5, 5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer all.Release()
+	if got := all.Int64Values(); !equalInt64s(got, []int64{0, 1, 2, 3, 4}) {
+		t.Fatalf("got %v, want every row", got)
+	}
+
+	if _, err := SampleIndices(pool, 5, 6, 1); err == nil {
+		t.Fatal("expected an error sampling more rows than available")
+	}
+	if _, err := SampleFraction(5, 1.5); err == nil {
+		t.Fatal("expected an error for a fraction above 1")
+	}
+}
+
+func TestStratifiedSampleIndices(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	keys := make([]string, 0, 40)
+	for i := 0; i < 40; i++ {
+		if i%4 == 0 {
+			keys = append(keys, "rare")
+		} else {
+			keys = append(keys, "common")
+		}
+	}
+	col := newStringColumn(pool, "k", keys, nil)
+	defer col.Release()
+
+	indices, err := StratifiedSampleIndices(pool, []*array.Column{col}, 0.2, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer indices.Release()
+
+	counts := map[string]int{}
+	for _, row := range indices.Int64Values() {
+		counts[keys[row]]++
+	}
+	if counts["rare"] != 2 || counts["common"] != 6 {
+		t.Fatalf("got strata %v, want 2 rare and 6 common rows", counts)
+	}
+}
+
+func TestReservoir(t *testing.T) {
+	const rows, n, runs = 20, 5, 4000
+	hits := make([]int, rows)
+	for seed := int64(0); seed < runs; seed++ {
+		r := NewReservoir(n, seed)
+		for i := 0; i < rows; i++ {
+			r.Offer()
+		}
+		sampled := r.Rows()
+		if len(sampled) != n {
+			t.Fatalf("got %d rows, want %d", len(sampled), n)
+		}
+		for _, row := range sampled {
+			hits[row]++
+		}
+	}
+	// Every row is sampled with probability n/rows.
+	want := runs * n / rows
+	for row, h := range hits {
+		if h < want*8/10 || h > want*12/10 {
+			t.Errorf("row %d sampled %d times, want about %d", row, h, want)
+		}
+	}
+}
+
+func equalInt64s(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/pkg/object"
+)
+
+// ScalarAt returns the value at index i of arr as an object of the type of
+// arr, or object.Null if the value is null. An index outside of arr is
+// reported with an *array.IndexError.
+func ScalarAt(arr array.Interface, i int) (object.Object, error) {
+	if err := array.CheckIndex(arr, i); err != nil {
+		return nil, err
+	}
+	if arr.IsNull(i) {
+		return object.NewNull(), nil
+	}
+	switch a := arr.(type) {
+	case *array.Boolean:
+		return object.NewBoolean(a.Value(i)), nil
+	case *array.Int8:
+		return object.NewInt8(a.Value(i)), nil
+	case *array.Int16:
+		return object.NewInt16(a.Value(i)), nil
+	case *array.Int32:
+		return object.NewInt32(a.Value(i)), nil
+	case *array.Int64:
+		return object.NewInt64(a.Value(i)), nil
+	case *array.Uint8:
+		return object.NewUint8(a.Value(i)), nil
+	case *array.Uint16:
+		return object.NewUint16(a.Value(i)), nil
+	case *array.Uint32:
+		return object.NewUint32(a.Value(i)), nil
+	case *array.Uint64:
+		return object.NewUint64(a.Value(i)), nil
+	case *array.Float16:
+		return object.NewFloat16(a.Value(i)), nil
+	case *array.Float32:
+		return object.NewFloat32(a.Value(i)), nil
+	case *array.Float64:
+		return object.NewFloat64(a.Value(i)), nil
+	case *array.Decimal128:
+		return object.NewDecimal128(a.Value(i)), nil
+	case *array.String:
+		return object.NewString(a.Value(i)), nil
+	case *array.Date32:
+		return object.NewDate32(a.Value(i)), nil
+	case *array.Date64:
+		return object.NewDate64(a.Value(i)), nil
+	case *array.Time32:
+		return object.NewTime32(a.Value(i)), nil
+	case *array.Time64:
+		return object.NewTime64(a.Value(i)), nil
+	case *array.Timestamp:
+		return object.NewTimestamp(a.Value(i)), nil
+	case *array.Duration:
+		return object.NewDuration(a.Value(i)), nil
+	case *array.MonthInterval:
+		return object.NewMonthInterval(a.Value(i)), nil
+	case *array.DayTimeInterval:
+		return object.NewDayTimeInterval(a.Value(i)), nil
+	case *array.MonthDayNanoInterval:
+		return object.NewMonthDayNanoInterval(a.Value(i)), nil
+	case *array.Dictionary:
+		return ScalarAt(a.Dictionary(), a.GetValueIndex(i))
+	case *array.RunEndEncoded:
+		return ScalarAt(a.Values(), a.GetPhysicalIndex(i))
+	default:
+		return nil, fmt.Errorf("compute: no scalar object for %s", arr.DataType())
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/object"
+)
+
+func TestScalarAtOutOfRange(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	b := array.NewInt64Builder(pool)
+	defer b.Release()
+	b.AppendValues([]int64{7, 8}, nil)
+	arr := b.NewInt64Array()
+	defer arr.Release()
+
+	if v, err := ScalarAt(arr, 1); err != nil || v != object.NewInt64(8) {
+		t.Fatalf("got %v (%v), want 8", v, err)
+	}
+	for _, i := range []int{-1, 2} {
+		if _, err := ScalarAt(arr, i); !errors.Is(err, array.ErrIndexOutOfRange) {
+			t.Errorf("index %d: got error %v, want an index out of range", i, err)
+		}
+		if _, err := arr.CheckedValue(i); !errors.Is(err, array.ErrIndexOutOfRange) {
+			t.Errorf("index %d: got error %v, want an index out of range", i, err)
+		}
+		if err := AppendValue(b, arr, i); !errors.Is(err, array.ErrIndexOutOfRange) {
+			t.Errorf("index %d: got error %v, want an index out of range", i, err)
+		}
+	}
+	var ie *array.IndexError
+	if _, err := arr.CheckedValue(5); !errors.As(err, &ie) || ie.Index != 5 || ie.Len != 2 {
+		t.Fatalf("got error %v, want index 5 of 2", err)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// Sessionize assigns a session to every row of the timestamp column ts. The
+// rows of a group of equal keys, see GroupRows, taken in time order, belong
+// to the same session as long as each follows the previous one by at most
+// gap; a larger gap starts a new session. Without keys all the rows form one
+// group.
+//
+// The result is an Int64 column named "session". Sessions are numbered from 0
+// by group, in order of first appearance of the groups, then in time order.
+// Rows whose timestamp is null have a null session.
+func Sessionize(mem memory.Allocator, keys []*array.Column, ts *array.Column, gap time.Duration) (*array.Column, error) {
+	dtype, ok := ts.DataType().(*arrow.TimestampType)
+	if !ok {
+		return nil, fmt.Errorf("compute: Sessionize expects a timestamp column, got %s", ts.DataType())
+	}
+	if gap < 0 {
+		return nil, fmt.Errorf("compute: Sessionize gap must not be negative, got %v", gap)
+	}
+	rows := ts.Len()
+
+	ids := make([]int32, rows)
+	if len(keys) > 0 {
+		for _, key := range keys {
+			if key.Len() != rows {
+				return nil, fmt.Errorf("compute: key column %q has %d rows, want %d", key.Name(), key.Len(), rows)
+			}
+		}
+		g, err := GroupRows(keys...)
+		if err != nil {
+			return nil, err
+		}
+		ids = g.IDs
+	}
+
+	stamps := make([]int64, rows)
+	valid := make([]bool, rows)
+	order := make([]int, 0, rows)
+	row := 0
+	for _, chunk := range ts.Data().Chunks() {
+		arr := chunk.(*array.Timestamp)
+		for i := 0; i < arr.Len(); i, row = i+1, row+1 {
+			if arr.IsNull(i) {
+				continue
+			}
+			stamps[row], valid[row] = int64(arr.Value(i)), true
+			order = append(order, row)
+		}
+	}
+	// Walk the rows of every group in time order, ties in row order.
+	sort.SliceStable(order, func(a, b int) bool {
+		ra, rb := order[a], order[b]
+		if ids[ra] != ids[rb] {
+			return ids[ra] < ids[rb]
+		}
+		return stamps[ra] < stamps[rb]
+	})
+
+	limit := int64(gap) / unitNanoseconds(dtype.Unit)
+	sessions := make([]int64, rows)
+	session := int64(-1)
+	for k, r := range order {
+		if k == 0 || ids[r] != ids[order[k-1]] || stamps[r]-stamps[order[k-1]] > limit {
+			session++
+		}
+		sessions[r] = session
+	}
+
+	return newInt64Result(mem, "session", sessions, valid), nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// SortIndices returns the row indices that order the rows of the key columns,
+// the first key taking precedence over the following ones. Each key is ordered
+// according to the matching order. Nulls sort after every other value whatever
+// the order, and rows with equal keys keep their relative order.
+func SortIndices(mem memory.Allocator, keys []*array.Column, orders []SortOrder) (*array.Int64, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("compute: at least one key column is required")
+	}
+	if len(keys) != len(orders) {
+		return nil, fmt.Errorf("compute: SortIndices needs an order per key (%d != %d)", len(keys), len(orders))
+	}
+
+	n := keys[0].Len()
+	cmps := make([]*keyComparator, len(keys))
+	positions := make([][]position, len(keys))
+	for k, key := range keys {
+		if key.Len() != n {
+			return nil, fmt.Errorf("compute: key column %q has %d rows, want %d", key.Name(), key.Len(), n)
+		}
+		cmp, err := newKeyComparator(key.Field(), key.Data().Chunks(), orders[k])
+		if err != nil {
+			return nil, fmt.Errorf("compute: key column %q: %w", key.Name(), err)
+		}
+		cmps[k] = cmp
+
+		// Key columns may be chunked differently, so each key locates its rows on its own.
+		pos := make([]position, 0, n)
+		for c, chunk := range key.Data().Chunks() {
+			for i := 0; i < chunk.Len(); i++ {
+				pos = append(pos, position{chunk: c, index: i})
+			}
+		}
+		positions[k] = pos
+	}
+
+	indices := make([]int64, n)
+	for i := range indices {
+		indices[i] = int64(i)
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		a, b := indices[i], indices[j]
+		for k, cmp := range cmps {
+			if c := cmp.compare(positions[k][a], positions[k][b]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	bldr := array.NewInt64Builder(mem)
+	defer bldr.Release()
+	bldr.AppendValues(indices, nil)
+
+	return bldr.NewInt64Array(), nil
+}
+
+// RowComparator compares rows of key columns split into aligned chunks, such
+// as the key columns of several records sharing a schema. The rows are ordered
+// like SortIndices orders them.
+type RowComparator struct {
+	keys []*keyComparator
+}
+
+// NewRowComparator creates a RowComparator where keys[k][c] is chunk c of key
+// column k. Chunk c of every key column must have the same length.
+func NewRowComparator(keys [][]array.Interface, orders []SortOrder) (*RowComparator, error) {
+	if len(keys) != len(orders) {
+		return nil, fmt.Errorf("compute: NewRowComparator needs an order per key (%d != %d)", len(keys), len(orders))
+	}
+
+	rc := &RowComparator{keys: make([]*keyComparator, len(keys))}
+	for k, chunks := range keys {
+		if len(chunks) != len(keys[0]) {
+			return nil, fmt.Errorf("compute: key %d has %d chunks, want %d", k, len(chunks), len(keys[0]))
+		}
+		for c, chunk := range chunks {
+			if chunk.Len() != keys[0][c].Len() {
+				return nil, fmt.Errorf("compute: chunk %d of key %d has %d rows, want %d", c, k, chunk.Len(), keys[0][c].Len())
+			}
+		}
+		cmp, err := newKeyComparator(arrow.Field{}, chunks, orders[k])
+		if err != nil {
+			return nil, err
+		}
+		rc.keys[k] = cmp
+	}
+	return rc, nil
+}
+
+// Compare compares row i of chunk a with row j of chunk b, returning -1, 0 or +1.
+func (rc *RowComparator) Compare(a, i, b, j int) int {
+	pa, pb := position{chunk: a, index: i}, position{chunk: b, index: j}
+	for _, cmp := range rc.keys {
+		if c := cmp.compare(pa, pb); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// keyComparator orders the values of a single key column, nulls last.
+type keyComparator struct {
+	chunks []array.Interface
+	cmp    valueComparator
+	order  SortOrder
+}
+
+func newKeyComparator(field arrow.Field, chunks []array.Interface, order SortOrder) (*keyComparator, error) {
+	cmp, err := newFieldComparator(field, chunks)
+	if err != nil {
+		return nil, err
+	}
+	return &keyComparator{chunks: chunks, cmp: cmp, order: order}, nil
+}
+
+func (k *keyComparator) compare(a, b position) int {
+	an := k.chunks[a.chunk].IsNull(a.index)
+	bn := k.chunks[b.chunk].IsNull(b.index)
+	switch {
+	case an && bn:
+		return 0
+	case an:
+		return 1
+	case bn:
+		return -1
+	}
+
+	c := k.cmp(a, b)
+	if k.order == Descending {
+		c = -c
+	}
+	return c
+}
@@ -0,0 +1,87 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestSortIndices(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	// The key columns are chunked differently.
+	names := newStringColumn(pool, "name", []string{"b", "a", "b", "", "a", "c"}, []bool{true, true, true, false, true, true})
+	defer names.Release()
+	values := newInt64Column(pool, "value", []int64{1, 2}, []int64{3, 4, 5, 6})
+	defer values.Release()
+
+	cases := []struct {
+		orders []SortOrder
+		want   string
+	}{
+		{orders: []SortOrder{Ascending, Ascending}, want: "[1 4 0 2 5 3]"},
+		{orders: []SortOrder{Ascending, Descending}, want: "[4 1 2 0 5 3]"},
+		{orders: []SortOrder{Descending, Ascending}, want: "[5 0 2 1 4 3]"},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%v", c.orders), func(t *testing.T) {
+			indices, err := SortIndices(pool, []*array.Column{names, values}, c.orders)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer indices.Release()
+
+			if got := fmt.Sprintf("%v", indices); got != c.want {
+				t.Fatalf("got=%s, want=%s", got, c.want)
+			}
+		})
+	}
+
+	if _, err := SortIndices(pool, []*array.Column{names}, nil); err == nil {
+		t.Fatal("expected an error for a missing order")
+	}
+}
+
+func TestRowComparator(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	first := newInt64Column(pool, "v", []int64{1, 5})
+	defer first.Release()
+	second := newInt64Column(pool, "v", []int64{3})
+	defer second.Release()
+
+	keys := [][]array.Interface{{first.Data().Chunk(0), second.Data().Chunk(0)}}
+	rc, err := NewRowComparator(keys, []SortOrder{Descending})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rc.Compare(0, 1, 1, 0); got != -1 {
+		t.Fatalf("got=%d, want=-1", got)
+	}
+	if got := rc.Compare(0, 0, 1, 0); got != 1 {
+		t.Fatalf("got=%d, want=1", got)
+	}
+	if got := rc.Compare(1, 0, 1, 0); got != 0 {
+		t.Fatalf("got=%d, want=0", got)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// Split splits every string of col around sep and returns a list column of
+// the parts, named like col. At most maxSplits splits are made, the last part
+// holding the rest of the string, and a negative maxSplits makes no limit.
+// Null strings produce null lists.
+func Split(mem memory.Allocator, col *array.Column, sep string, maxSplits int) (*array.Column, error) {
+	if sep == "" {
+		return nil, fmt.Errorf("compute: cannot split column %q around an empty separator", col.Name())
+	}
+	return splitStrings(mem, col, func(s string, emit func(beg, end int)) {
+		beg := 0
+		for n := 0; maxSplits < 0 || n < maxSplits; n++ {
+			i := strings.Index(s[beg:], sep)
+			if i < 0 {
+				break
+			}
+			emit(beg, beg+i)
+			beg += i + len(sep)
+		}
+		emit(beg, len(s))
+	})
+}
+
+// Tokenize splits every string of col around runs of white space, as defined
+// by unicode.IsSpace, and returns a list column of the tokens, named like col.
+// Strings holding only white space produce empty lists and null strings null lists.
+func Tokenize(mem memory.Allocator, col *array.Column) (*array.Column, error) {
+	return splitStrings(mem, col, func(s string, emit func(beg, end int)) {
+		beg := -1
+		for i, r := range s {
+			switch space := unicode.IsSpace(r); {
+			case space && beg >= 0:
+				emit(beg, i)
+				beg = -1
+			case !space && beg < 0:
+				beg = i
+			}
+		}
+		if beg >= 0 {
+			emit(beg, len(s))
+		}
+	})
+}
+
+// splitStrings builds a list column from the parts of the strings of col found by split.
+func splitStrings(mem memory.Allocator, col *array.Column, split func(s string, emit func(beg, end int))) (*array.Column, error) {
+	if col.DataType().ID() != arrow.STRING {
+		return nil, fmt.Errorf("compute: cannot split column %q of type %s", col.Name(), col.DataType())
+	}
+
+	dtype := arrow.ListOf(arrow.BinaryTypes.String)
+	chunks := make([]array.Interface, 0, len(col.Data().Chunks()))
+	defer func() {
+		for _, chunk := range chunks {
+			chunk.Release()
+		}
+	}()
+	for _, chunk := range col.Data().Chunks() {
+		lists, err := splitChunk(mem, chunk.(*array.String), split)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, lists)
+	}
+
+	chunked := array.NewChunked(dtype, chunks)
+	defer chunked.Release()
+	return array.NewColumn(arrow.Field{Name: col.Name(), Type: dtype, Nullable: true}, chunked), nil
+}
+
+// splitChunk splits the strings of a chunk in two passes: the first one
+// computes the offsets of the lists and of their parts and the second one
+// copies the parts once the size of their data is known.
+func splitChunk(mem memory.Allocator, strs *array.String, split func(s string, emit func(beg, end int))) (array.Interface, error) {
+	n := strs.Len()
+	listOffsets := make([]int32, 1, n+1)
+	partOffsets := []int32{0}
+	var spans []int // the start of every part in its string, then its row
+	for i := 0; i < n; i++ {
+		if strs.IsValid(i) {
+			split(strs.Value(i), func(beg, end int) {
+				partOffsets = append(partOffsets, partOffsets[len(partOffsets)-1]+int32(end-beg))
+				spans = append(spans, beg, i)
+			})
+		}
+		listOffsets = append(listOffsets, int32(len(partOffsets)-1))
+	}
+
+	data := newBuffer(mem, int(partOffsets[len(partOffsets)-1]))
+	defer data.Release()
+	bytes := data.Bytes()
+	for k := 0; k < len(spans); k += 2 {
+		beg, row := spans[k], spans[k+1]
+		part := k / 2
+		copy(bytes[partOffsets[part]:partOffsets[part+1]], strs.Value(row)[beg:])
+	}
+
+	offsets := newBuffer(mem, arrow.Int32Traits.BytesRequired(len(partOffsets)))
+	defer offsets.Release()
+	copy(arrow.Int32Traits.CastFromBytes(offsets.Bytes()), partOffsets)
+	parts := array.NewData(arrow.BinaryTypes.String, len(partOffsets)-1, []*memory.Buffer{nil, offsets, data}, nil, 0, 0)
+	defer parts.Release()
+
+	lists := newBuffer(mem, arrow.Int32Traits.BytesRequired(len(listOffsets)))
+	copy(arrow.Int32Traits.CastFromBytes(lists.Bytes()), listOffsets)
+	var valid *memory.Buffer
+	if strs.NullN() > 0 {
+		valid = newBuffer(mem, int(bitutil.BytesForBits(int64(n))))
+		for i := 0; i < n; i++ {
+			bitutil.SetBitTo(valid.Bytes(), i, strs.IsValid(i))
+		}
+	}
+	// The list takes the buffers over, so they are not copied.
+	return array.NewListFromOffsets(lists, array.MakeFromData(parts), valid)
+}
+
+// newBuffer allocates a buffer of n bytes from mem.
+func newBuffer(mem memory.Allocator, n int) *memory.Buffer {
+	buf := memory.NewResizableBuffer(mem)
+	buf.Resize(n)
+	return buf
+}
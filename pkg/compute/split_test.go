@@ -0,0 +1,71 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestSplit(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	col := newStringColumn(pool, "s", []string{"a,b,,c", "", "x", "a,b,c"}, []bool{true, false, true, true})
+	defer col.Release()
+
+	for _, tc := range []struct {
+		name string
+		fn   func() (*array.Column, error)
+		want string
+	}{
+		{"split", func() (*array.Column, error) { return Split(pool, col, ",", -1) }, `[["a" "b" "" "c"] (null) ["x"] ["a" "b" "c"]]`},
+		{"split once", func() (*array.Column, error) { return Split(pool, col, ",", 1) }, `[["a" "b,,c"] (null) ["x"] ["a" "b,c"]]`},
+		{"split none", func() (*array.Column, error) { return Split(pool, col, ",", 0) }, `[["a,b,,c"] (null) ["x"] ["a,b,c"]]`},
+	} {
+		got, err := tc.fn()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := fmt.Sprint(got.Data().Chunk(0)); s != tc.want {
+			t.Errorf("%s: got=%s, want=%s", tc.name, s, tc.want)
+		}
+		got.Release()
+	}
+
+	if _, err := Split(pool, col, "", -1); err == nil {
+		t.Fatal("expected an error for an empty separator")
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	col := newStringColumn(pool, "s", []string{"  the quick\tbrown\n", " \t", "fox", ""}, []bool{true, true, true, false})
+	defer col.Release()
+
+	got, err := Tokenize(pool, col)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Release()
+	if s, want := fmt.Sprint(got.Data().Chunk(0)), `[["the" "quick" "brown"] [] ["fox"] (null)]`; s != want {
+		t.Fatalf("got=%s, want=%s", s, want)
+	}
+}
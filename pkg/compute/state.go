@@ -0,0 +1,382 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/object"
+)
+
+// The aggregation states below accumulate an aggregate over the batches of a
+// column passed to Update, and fold in the state of another with Merge, so
+// that partial aggregates computed by several partitions, workers or
+// processes can be combined. Their binary encodings ship a state to another
+// process, where it is decoded into a state created for the same type.
+// HyperLogLog and TDigest follow the same pattern for distinct counts and
+// quantiles.
+
+const stateVersion = 1
+
+// CountState counts the values that are not missing under its NaNPolicy.
+type CountState struct {
+	policy NaNPolicy
+	n      int64
+}
+
+// NewCountState creates a new empty count of the values that are not missing
+// under p.
+func NewCountState(p NaNPolicy) *CountState {
+	return &CountState{policy: p}
+}
+
+// Update counts the values of arr. Run-end encoded arrays are counted run by
+// run.
+func (s *CountState) Update(arr array.Interface) error {
+	if ree, ok := arr.(*array.RunEndEncoded); ok {
+		missing := s.policy.missing(ree.Values())
+		return forEachRun(ree, func(j, n int) error {
+			if !missing(j) {
+				s.n += int64(n)
+			}
+			return nil
+		})
+	}
+	missing := s.policy.missing(arr)
+	for i := 0; i < arr.Len(); i++ {
+		if !missing(i) {
+			s.n++
+		}
+	}
+	return nil
+}
+
+// UpdateColumn counts the values of every chunk in col.
+func (s *CountState) UpdateColumn(col *array.Column) error {
+	for _, chunk := range col.Data().Chunks() {
+		if err := s.Update(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Merge folds the count of other into s.
+func (s *CountState) Merge(other *CountState) error {
+	if other == nil {
+		return nil
+	}
+	s.n += other.n
+	return nil
+}
+
+// Count returns the number of values counted.
+func (s *CountState) Count() int64 { return s.n }
+
+// Reset clears the count so it can be reused.
+func (s *CountState) Reset() { s.n = 0 }
+
+// MarshalBinary encodes the count.
+func (s *CountState) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 9)
+	buf[0] = stateVersion
+	binary.LittleEndian.PutUint64(buf[1:], uint64(s.n))
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a count encoded with MarshalBinary.
+func (s *CountState) UnmarshalBinary(data []byte) error {
+	if len(data) != 9 {
+		return errors.New("compute: count state data has the wrong size")
+	}
+	if data[0] != stateVersion {
+		return fmt.Errorf("compute: unsupported count state version %d", data[0])
+	}
+	s.n = int64(binary.LittleEndian.Uint64(data[1:]))
+	return nil
+}
+
+// SumState accumulates the sum and the number of the values of a type that
+// are not missing under its NaNPolicy, giving their sum and mean as Sum and
+// Mean do.
+type SumState struct {
+	policy NaNPolicy
+	s      *sum
+}
+
+// NewSumState creates a new empty sum of values of dtype that are not missing
+// under p.
+func NewSumState(dtype arrow.DataType, p NaNPolicy) (*SumState, error) {
+	s, err := newSum(dtype)
+	if err != nil {
+		return nil, err
+	}
+	return &SumState{policy: p, s: s}, nil
+}
+
+// Update adds the values of arr, which must be of the type of the state.
+func (s *SumState) Update(arr array.Interface) error {
+	dtype := arr.DataType()
+	if ree, ok := dtype.(*arrow.RunEndEncodedType); ok {
+		dtype = ree.ValueType
+	}
+	if !arrow.TypeEqual(dtype, s.s.dtype) {
+		return fmt.Errorf("compute: cannot add %s values to a sum of %s", dtype, s.s.dtype)
+	}
+	s.s.update(arr, s.policy)
+	return nil
+}
+
+// UpdateColumn adds the values of every chunk in col.
+func (s *SumState) UpdateColumn(col *array.Column) error {
+	for _, chunk := range col.Data().Chunks() {
+		if err := s.Update(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Merge folds the sum of other into s. Both must sum values of the same type.
+func (s *SumState) Merge(other *SumState) error {
+	if other == nil {
+		return nil
+	}
+	if !arrow.TypeEqual(s.s.dtype, other.s.dtype) {
+		return fmt.Errorf("compute: cannot merge a sum of %s into a sum of %s", other.s.dtype, s.s.dtype)
+	}
+	s.s.merge(other.s)
+	return nil
+}
+
+// Count returns the number of values added.
+func (s *SumState) Count() int64 { return s.s.n }
+
+// Sum returns the sum of the values added, as returned by Sum.
+func (s *SumState) Sum() object.Object { return s.s.value() }
+
+// Mean returns the mean of the values added, as returned by Mean.
+func (s *SumState) Mean() (object.Object, error) { return s.s.mean() }
+
+// Reset clears the sum so it can be reused.
+func (s *SumState) Reset() {
+	*s.s = sum{dtype: s.s.dtype, class: s.s.class}
+}
+
+// MarshalBinary encodes the sum.
+func (s *SumState) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 1+6*8)
+	buf[0] = stateVersion
+	for k, v := range []uint64{uint64(s.s.n), uint64(s.s.i), s.s.u, math.Float64bits(s.s.f), uint64(s.s.hi), s.s.lo} {
+		binary.LittleEndian.PutUint64(buf[1+8*k:], v)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a sum encoded with MarshalBinary into s, which must
+// have been created for the type of the sum encoded.
+func (s *SumState) UnmarshalBinary(data []byte) error {
+	if len(data) != 1+6*8 {
+		return errors.New("compute: sum state data has the wrong size")
+	}
+	if data[0] != stateVersion {
+		return fmt.Errorf("compute: unsupported sum state version %d", data[0])
+	}
+	word := func(k int) uint64 { return binary.LittleEndian.Uint64(data[1+8*k:]) }
+	s.s.n, s.s.i, s.s.u = int64(word(0)), int64(word(1)), word(2)
+	s.s.f, s.s.hi, s.s.lo = math.Float64frombits(word(3)), int64(word(4)), word(5)
+	return nil
+}
+
+// MinMaxState keeps the smallest and the largest values of a field that are
+// not missing under its NaNPolicy, ordered as by MinMax. It holds a copy of
+// both values, so the batches added are not retained.
+type MinMaxState struct {
+	policy NaNPolicy
+	field  arrow.Field
+	dtype  arrow.DataType // type of the values
+
+	// extremes holds the smallest and the largest values, nil before any is
+	// added.
+	extremes array.Interface
+}
+
+// NewMinMaxState creates a new empty state for the values of field, which must
+// be ordered.
+func NewMinMaxState(field arrow.Field, p NaNPolicy) (*MinMaxState, error) {
+	dtype := field.Type
+	if ree, ok := dtype.(*arrow.RunEndEncodedType); ok {
+		dtype = ree.ValueType
+	}
+	if err := checkOrdered(dtype); err != nil {
+		return nil, err
+	}
+	return &MinMaxState{policy: p, field: field, dtype: dtype}, nil
+}
+
+// Update adds the values of arr, which must be of the type of the field.
+func (s *MinMaxState) Update(arr array.Interface) error {
+	values := arr
+	if ree, ok := arr.(*array.RunEndEncoded); ok {
+		values = ree.Values()
+	}
+	if !arrow.TypeEqual(values.DataType(), s.dtype) {
+		return fmt.Errorf("compute: cannot add %s values to a MinMax of %s", values.DataType(), s.dtype)
+	}
+
+	chunks, comparing := []array.Interface{arr}, []array.Interface{values}
+	if s.extremes != nil {
+		chunks = append(chunks, s.extremes)
+		comparing = append(comparing, s.extremes)
+	}
+	cmp, err := newFieldComparator(s.field, comparing)
+	if err != nil {
+		return err
+	}
+	lo, hi, found := s.policy.extremes(chunks, cmp)
+	if !found || (lo.chunk == 1 && hi.chunk == 1) {
+		return nil
+	}
+
+	bldr := array.NewBuilder(memory.NewGoAllocator(), s.dtype)
+	defer bldr.Release()
+	if err := AppendValue(bldr, comparing[lo.chunk], lo.index); err != nil {
+		return err
+	}
+	if err := AppendValue(bldr, comparing[hi.chunk], hi.index); err != nil {
+		return err
+	}
+	s.Reset()
+	s.extremes = bldr.NewArray()
+	return nil
+}
+
+// UpdateColumn adds the values of every chunk in col.
+func (s *MinMaxState) UpdateColumn(col *array.Column) error {
+	for _, chunk := range col.Data().Chunks() {
+		if err := s.Update(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Merge folds the values kept by other into s. Both must keep values of the
+// same type.
+func (s *MinMaxState) Merge(other *MinMaxState) error {
+	if other == nil {
+		return nil
+	}
+	if !arrow.TypeEqual(s.dtype, other.dtype) {
+		return fmt.Errorf("compute: cannot merge a MinMax of %s into a MinMax of %s", other.dtype, s.dtype)
+	}
+	if other.extremes == nil {
+		return nil
+	}
+	return s.Update(other.extremes)
+}
+
+// MinMax returns the smallest and the largest values added, as returned by
+// MinMax.
+func (s *MinMaxState) MinMax() (min, max object.Object, err error) {
+	if s.extremes == nil {
+		return object.NewNull(), object.NewNull(), nil
+	}
+	if min, err = ScalarAt(s.extremes, 0); err != nil {
+		return nil, nil, err
+	}
+	if max, err = ScalarAt(s.extremes, 1); err != nil {
+		return nil, nil, err
+	}
+	return min, max, nil
+}
+
+// Reset clears the state so it can be reused.
+func (s *MinMaxState) Reset() {
+	if s.extremes != nil {
+		s.extremes.Release()
+		s.extremes = nil
+	}
+}
+
+// MarshalBinary encodes the values kept as an Arrow IPC stream.
+func (s *MinMaxState) MarshalBinary() ([]byte, error) {
+	extremes := s.extremes
+	if extremes == nil {
+		bldr := array.NewBuilder(memory.NewGoAllocator(), s.dtype)
+		defer bldr.Release()
+		extremes = bldr.NewArray()
+		defer extremes.Release()
+	}
+	schema := arrow.NewSchema([]arrow.Field{{Name: "extremes", Type: s.dtype, Nullable: true}}, nil)
+	rec := array.NewRecord(schema, []array.Interface{extremes}, int64(extremes.Len()))
+	defer rec.Release()
+
+	var buf bytes.Buffer
+	buf.WriteByte(stateVersion)
+	w := ipc.NewWriter(&buf, ipc.WithSchema(schema))
+	if err := w.Write(rec); err != nil {
+		return nil, fmt.Errorf("compute: could not encode MinMax state: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("compute: could not encode MinMax state: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a state encoded with MarshalBinary into s, which
+// must have been created for the type of the values encoded.
+func (s *MinMaxState) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return errors.New("compute: MinMax state data too short")
+	}
+	if data[0] != stateVersion {
+		return fmt.Errorf("compute: unsupported MinMax state version %d", data[0])
+	}
+	r, err := ipc.NewReader(bytes.NewReader(data[1:]))
+	if err != nil {
+		return fmt.Errorf("compute: could not decode MinMax state: %w", err)
+	}
+	defer r.Release()
+	if !r.Next() {
+		if err := r.Err(); err != nil {
+			return fmt.Errorf("compute: could not decode MinMax state: %w", err)
+		}
+		return errors.New("compute: MinMax state data holds no record")
+	}
+	rec := r.Record()
+	if rec.NumCols() != 1 || !arrow.TypeEqual(rec.Column(0).DataType(), s.dtype) {
+		return fmt.Errorf("compute: cannot decode MinMax state of %s into a MinMax of %s", rec.Schema(), s.dtype)
+	}
+	switch rec.NumRows() {
+	case 0:
+		s.Reset()
+	case 2:
+		s.Reset()
+		s.extremes = rec.Column(0)
+		s.extremes.Retain()
+	default:
+		return fmt.Errorf("compute: MinMax state holds %d values", rec.NumRows())
+	}
+	return nil
+}
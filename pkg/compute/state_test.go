@@ -0,0 +1,187 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestAggregationStates(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	newFloats := func(values []float64, valid []bool) array.Interface {
+		b := array.NewFloat64Builder(pool)
+		defer b.Release()
+		b.AppendValues(values, valid)
+		return b.NewArray()
+	}
+	// Two partitions, the second holding a null and a NaN.
+	parts := []array.Interface{
+		newFloats([]float64{3, 1, 4}, nil),
+		newFloats([]float64{1, 0, 5, math.NaN(), -2}, []bool{true, false, true, true, true}),
+	}
+	defer func() {
+		for _, part := range parts {
+			part.Release()
+		}
+	}()
+	field := arrow.Field{Name: "f", Type: arrow.PrimitiveTypes.Float64}
+
+	for _, p := range []NaNPolicy{NaNPropagate, NaNSkip} {
+		t.Run(p.String(), func(t *testing.T) {
+			counts := make([]*CountState, len(parts))
+			sums := make([]*SumState, len(parts))
+			minmaxes := make([]*MinMaxState, len(parts))
+			for k, part := range parts {
+				counts[k] = NewCountState(p)
+				var err error
+				if sums[k], err = NewSumState(part.DataType(), p); err != nil {
+					t.Fatal(err)
+				}
+				if minmaxes[k], err = NewMinMaxState(field, p); err != nil {
+					t.Fatal(err)
+				}
+				defer minmaxes[k].Reset()
+				if err := counts[k].Update(part); err != nil {
+					t.Fatal(err)
+				}
+				if err := sums[k].Update(part); err != nil {
+					t.Fatal(err)
+				}
+				if err := minmaxes[k].Update(part); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Ship the states of the second partition through their
+			// encodings before merging them.
+			count := NewCountState(p)
+			sum, err := NewSumState(arrow.PrimitiveTypes.Float64, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			minmax, err := NewMinMaxState(field, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer minmax.Reset()
+			for _, pair := range []struct {
+				from interface{ MarshalBinary() ([]byte, error) }
+				to   interface{ UnmarshalBinary([]byte) error }
+			}{{counts[1], count}, {sums[1], sum}, {minmaxes[1], minmax}} {
+				data, err := pair.from.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := pair.to.UnmarshalBinary(data); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := count.Merge(counts[0]); err != nil {
+				t.Fatal(err)
+			}
+			if err := sum.Merge(sums[0]); err != nil {
+				t.Fatal(err)
+			}
+			if err := minmax.Merge(minmaxes[0]); err != nil {
+				t.Fatal(err)
+			}
+
+			wantCount, wantSum, wantMean, wantMax := int64(7), "NaN", "NaN", "NaN"
+			if p == NaNSkip {
+				wantCount, wantSum, wantMean, wantMax = 6, "12", "2", "5"
+			}
+			if got := count.Count(); got != wantCount {
+				t.Fatalf("count: got=%d, want=%d", got, wantCount)
+			}
+			if got := sum.Count(); got != wantCount {
+				t.Fatalf("sum count: got=%d, want=%d", got, wantCount)
+			}
+			if got := fmt.Sprint(sum.Sum()); got != wantSum {
+				t.Fatalf("sum: got=%s, want=%s", got, wantSum)
+			}
+			mean, err := sum.Mean()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fmt.Sprint(mean); got != wantMean {
+				t.Fatalf("mean: got=%s, want=%s", got, wantMean)
+			}
+			min, max, err := minmax.MinMax()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := fmt.Sprint(min), "-2"; got != want {
+				t.Fatalf("min: got=%s, want=%s", got, want)
+			}
+			if got := fmt.Sprint(max); got != wantMax {
+				t.Fatalf("max: got=%s, want=%s", got, wantMax)
+			}
+		})
+	}
+}
+
+func TestAggregationStatesErrors(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	col := newInt64Column(pool, "ids", []int64{1, 2})
+	defer col.Release()
+
+	if _, err := NewSumState(arrow.BinaryTypes.String, NaNPropagate); err == nil {
+		t.Fatal("expected an error summing strings")
+	}
+	sum, err := NewSumState(arrow.PrimitiveTypes.Float64, NaNPropagate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sum.UpdateColumn(col); err == nil {
+		t.Fatal("expected an error adding int64 values to a float64 sum")
+	}
+	ints, err := NewSumState(arrow.PrimitiveTypes.Int64, NaNPropagate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sum.Merge(ints); err == nil {
+		t.Fatal("expected an error merging sums of different types")
+	}
+
+	minmax, err := NewMinMaxState(col.Field(), NaNPropagate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer minmax.Reset()
+	if err := minmax.UpdateColumn(col); err != nil {
+		t.Fatal(err)
+	}
+	data, err := minmax.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	floats, err := NewMinMaxState(arrow.Field{Name: "f", Type: arrow.PrimitiveTypes.Float64}, NaNPropagate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := floats.UnmarshalBinary(data); err == nil {
+		t.Fatal("expected an error decoding an int64 MinMax into a float64 one")
+	}
+}
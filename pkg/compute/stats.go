@@ -0,0 +1,172 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+)
+
+// NullPolicy selects the rows of a pair of columns used by Cov and Corr.
+type NullPolicy int
+
+const (
+	// PairwiseComplete uses, for every pair of columns, the rows where both
+	// columns are valid.
+	PairwiseComplete NullPolicy = iota
+	// CompleteRows uses the rows where all the columns are valid, so that
+	// every entry of the matrix is computed over the same rows.
+	CompleteRows
+)
+
+// Cov returns the matrix of the sample covariances of the numeric columns.
+// An entry computed over fewer than two rows is NaN.
+func Cov(cols []*array.Column, policy NullPolicy) ([][]float64, error) {
+	pairs, err := coMoments(cols, policy)
+	if err != nil {
+		return nil, err
+	}
+	return symmetric(pairs, func(p *coMoment) float64 {
+		if p.n < 2 {
+			return math.NaN()
+		}
+		return p.c / float64(p.n-1)
+	}), nil
+}
+
+// Corr returns the matrix of the Pearson correlation coefficients of the
+// numeric columns. An entry computed over fewer than two rows or involving a
+// constant column is NaN.
+func Corr(cols []*array.Column, policy NullPolicy) ([][]float64, error) {
+	pairs, err := coMoments(cols, policy)
+	if err != nil {
+		return nil, err
+	}
+	return symmetric(pairs, func(p *coMoment) float64 {
+		if p.n < 2 || p.m2x == 0 || p.m2y == 0 {
+			return math.NaN()
+		}
+		return p.c / math.Sqrt(p.m2x*p.m2y)
+	}), nil
+}
+
+// coMoment accumulates the co-moment of two series in a single pass with
+// Welford's algorithm, which does not lose precision to cancellation like the
+// difference of the sum of products and the product of the sums does.
+type coMoment struct {
+	n            int64
+	meanX, meanY float64
+	m2x, m2y, c  float64
+}
+
+func (p *coMoment) add(x, y float64) {
+	p.n++
+	dx := x - p.meanX
+	dy := y - p.meanY
+	p.meanX += dx / float64(p.n)
+	p.meanY += dy / float64(p.n)
+	p.c += dx * (y - p.meanY)
+	p.m2x += dx * (x - p.meanX)
+	p.m2y += dy * (y - p.meanY)
+}
+
+// coMoments returns the co-moments of every pair of columns i <= j in
+// pairs[i][j-i], reading the columns side by side in a single pass.
+func coMoments(cols []*array.Column, policy NullPolicy) ([][]coMoment, error) {
+	if len(cols) == 0 {
+		return nil, nil
+	}
+	rows := cols[0].Len()
+	for _, col := range cols[1:] {
+		if col.Len() != rows {
+			return nil, fmt.Errorf("compute: column %q has %d rows, want %d", col.Name(), col.Len(), rows)
+		}
+	}
+
+	readers := make([]*numberCursor, len(cols))
+	for i, col := range cols {
+		readers[i] = &numberCursor{cursor: newRowCursor(col)}
+	}
+	pairs := make([][]coMoment, len(cols))
+	for i := range pairs {
+		pairs[i] = make([]coMoment, len(cols)-i)
+	}
+
+	values := make([]float64, len(cols))
+	valid := make([]bool, len(cols))
+	for row := 0; row < rows; row++ {
+		complete := true
+		for i, r := range readers {
+			v, ok, err := r.next()
+			if err != nil {
+				return nil, fmt.Errorf("compute: column %q: %w", cols[i].Name(), err)
+			}
+			values[i], valid[i] = v, ok
+			complete = complete && ok
+		}
+		if policy == CompleteRows && !complete {
+			continue
+		}
+		for i := range pairs {
+			if !valid[i] {
+				continue
+			}
+			for j := i; j < len(cols); j++ {
+				if valid[j] {
+					pairs[i][j-i].add(values[i], values[j])
+				}
+			}
+		}
+	}
+	return pairs, nil
+}
+
+func symmetric(pairs [][]coMoment, fn func(p *coMoment) float64) [][]float64 {
+	out := make([][]float64, len(pairs))
+	for i := range out {
+		out[i] = make([]float64, len(pairs))
+	}
+	for i := range pairs {
+		for j := i; j < len(pairs); j++ {
+			out[i][j] = fn(&pairs[i][j-i])
+			out[j][i] = out[i][j]
+		}
+	}
+	return out
+}
+
+// numberCursor reads the rows of a numeric column in order as float64.
+type numberCursor struct {
+	cursor *rowCursor
+	chunk  array.Interface
+	get    func(int) float64
+}
+
+func (c *numberCursor) next() (float64, bool, error) {
+	chunk, i := c.cursor.next()
+	if chunk != c.chunk {
+		get, err := numberGetter(chunk)
+		if err != nil {
+			return 0, false, err
+		}
+		c.chunk, c.get = chunk, get
+	}
+	if chunk.IsNull(i) {
+		return 0, false, nil
+	}
+	return c.get(i), true, nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestCovCorr(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	// x is split in two chunks, y = 2x and z has a null.
+	x := newInt64Column(pool, "x", []int64{1, 2}, []int64{3, 4, 5})
+	defer x.Release()
+	y := newFloat64Column(pool, "y", []float64{2, 4, 6, 8, 10}, nil)
+	defer y.Release()
+	z := newFloat64Column(pool, "z", []float64{5, 0, 3, 1, 2}, []bool{true, false, true, true, true})
+	defer z.Release()
+	cols := []*array.Column{x, y, z}
+
+	for _, tc := range []struct {
+		name   string
+		fn     func([]*array.Column, NullPolicy) ([][]float64, error)
+		policy NullPolicy
+		want   [][]float64
+	}{
+		{"cov pairwise", Cov, PairwiseComplete, [][]float64{
+			{2.5, 5, -2.5833333333333335},
+			{5, 10, -5.166666666666667},
+			{-2.5833333333333335, -5.166666666666667, 2.9166666666666665},
+		}},
+		{"cov complete", Cov, CompleteRows, [][]float64{
+			{2.9166666666666665, 5.833333333333333, -2.5833333333333335},
+			{5.833333333333333, 11.666666666666666, -5.166666666666667},
+			{-2.5833333333333335, -5.166666666666667, 2.9166666666666665},
+		}},
+		{"corr pairwise", Corr, PairwiseComplete, [][]float64{
+			{1, 1, -0.8857142857142858},
+			{1, 1, -0.8857142857142858},
+			{-0.8857142857142858, -0.8857142857142858, 1},
+		}},
+	} {
+		got, err := tc.fn(cols, tc.policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range tc.want {
+			for j := range tc.want[i] {
+				if math.Abs(got[i][j]-tc.want[i][j]) > 1e-12 {
+					t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+				}
+			}
+		}
+	}
+
+	// Welford's algorithm keeps its precision with a large offset.
+	big := newFloat64Column(pool, "big", []float64{1e9 + 4, 1e9 + 7, 1e9 + 13, 1e9 + 16}, nil)
+	defer big.Release()
+	got, err := Cov([]*array.Column{big}, PairwiseComplete)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0][0] != 30 {
+		t.Errorf("got variance %v, want 30", got[0][0])
+	}
+
+	one := newFloat64Column(pool, "one", []float64{1}, nil)
+	defer one.Release()
+	got, err = Corr([]*array.Column{one}, PairwiseComplete)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsNaN(got[0][0]) {
+		t.Errorf("got %v, want NaN for a single row", got[0][0])
+	}
+
+	s := newStringColumn(pool, "s", []string{"a", "b", "c", "d", "e"}, nil)
+	defer s.Release()
+	if _, err := Cov([]*array.Column{x, s}, PairwiseComplete); err == nil || err.Error() != `compute: column "s": compute: utf8 is not a numeric type` {
+		t.Errorf("got error %v", err)
+	}
+	if _, err := Cov([]*array.Column{x, one}, PairwiseComplete); err == nil {
+		t.Error("expected an error for columns of different lengths")
+	}
+}
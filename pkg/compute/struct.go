@@ -0,0 +1,93 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/bitutil"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// StructField returns the child column of the struct column col with the given
+// name. The child shares the buffers of col, only the validity bitmap of the
+// chunks holding null structs is rebuilt so that their rows are null in the child.
+func StructField(mem memory.Allocator, col *array.Column, name string) (*array.Column, error) {
+	dtype, ok := col.DataType().(*arrow.StructType)
+	if !ok {
+		return nil, fmt.Errorf("compute: column %q of type %s is not a struct", col.Name(), col.DataType())
+	}
+	idx := -1
+	for i, f := range dtype.Fields() {
+		if f.Name == name {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, fmt.Errorf("compute: struct column %q has no field %q", col.Name(), name)
+	}
+	field := dtype.Field(idx)
+	field.Nullable = field.Nullable || col.Field().Nullable
+
+	chunks := make([]array.Interface, 0, len(col.Data().Chunks()))
+	defer func() {
+		for _, chunk := range chunks {
+			chunk.Release()
+		}
+	}()
+	for _, chunk := range col.Data().Chunks() {
+		chunks = append(chunks, structChild(mem, chunk.(*array.Struct), idx))
+	}
+
+	chunked := array.NewChunked(field.Type, chunks)
+	defer chunked.Release()
+	return array.NewColumn(field, chunked), nil
+}
+
+// structChild returns child i of s, null where s is null.
+func structChild(mem memory.Allocator, s *array.Struct, i int) array.Interface {
+	child := s.Field(i)
+	if s.NullN() == 0 {
+		child.Retain()
+		return child
+	}
+
+	// The bitmap keeps the offset of the child so that its other buffers are shared as they are.
+	data := child.Data()
+	offset := data.Offset()
+	bitmap := memory.NewResizableBuffer(mem)
+	defer bitmap.Release()
+	bitmap.Resize(int(bitutil.BytesForBits(int64(offset + child.Len()))))
+	bits := bitmap.Bytes()
+	for k := range bits {
+		bits[k] = 0
+	}
+	nulls := 0
+	for j := 0; j < child.Len(); j++ {
+		if s.IsValid(j) && child.IsValid(j) {
+			bitutil.SetBit(bits, offset+j)
+			continue
+		}
+		nulls++
+	}
+
+	buffers := append([]*memory.Buffer{bitmap}, data.Buffers()[1:]...)
+	masked := array.NewData(data.DataType(), data.Len(), buffers, data.Children(), nulls, offset)
+	defer masked.Release()
+	return array.MakeFromData(masked)
+}
@@ -0,0 +1,81 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestStructField(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	dtype := arrow.StructOf(
+		arrow.Field{Name: "a", Type: arrow.PrimitiveTypes.Int64},
+		arrow.Field{Name: "b", Type: arrow.BinaryTypes.String, Nullable: true},
+	)
+	bldr := array.NewStructBuilder(pool, dtype)
+	defer bldr.Release()
+	a := bldr.FieldBuilder(0).(*array.Int64Builder)
+	b := bldr.FieldBuilder(1).(*array.StringBuilder)
+	// The children of the null struct hold values, which must not leak through.
+	bldr.AppendValues([]bool{true, true, false, true})
+	for i := 0; i < 4; i++ {
+		a.Append(int64(i))
+		if i == 3 {
+			b.AppendNull()
+			continue
+		}
+		b.Append(fmt.Sprint("s", i))
+	}
+	arr := bldr.NewArray()
+	defer arr.Release()
+	// Slicing off the first row checks that offsets are kept.
+	sliced := array.NewSlice(arr, 1, 4)
+	defer sliced.Release()
+	chunked := array.NewChunked(dtype, []array.Interface{arr, sliced})
+	defer chunked.Release()
+	col := array.NewColumn(arrow.Field{Name: "s", Type: dtype, Nullable: true}, chunked)
+	defer col.Release()
+
+	for _, tc := range []struct {
+		name string
+		want string
+	}{
+		{"a", "[0 1 (null) 3] [1 (null) 3]"},
+		{"b", `["s0" "s1" (null) (null)] ["s1" (null) (null)]`},
+	} {
+		got, err := StructField(pool, col, tc.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := fmt.Sprint(got.Data().Chunk(0), " ", got.Data().Chunk(1)); s != tc.want {
+			t.Errorf("field %s: got=%s, want=%s", tc.name, s, tc.want)
+		}
+		if got.Name() != tc.name || !got.Field().Nullable {
+			t.Errorf("field %s: got field %v", tc.name, got.Field())
+		}
+		got.Release()
+	}
+
+	if _, err := StructField(pool, col, "c"); err == nil || err.Error() != `compute: struct column "s" has no field "c"` {
+		t.Fatalf("got error %v", err)
+	}
+}
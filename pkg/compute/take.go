@@ -0,0 +1,194 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// Take builds a new Column holding the rows of col at the given indices, in order.
+// A null index produces a null row. The result is a single chunk.
+// Dictionary columns keep their dictionary, only the indices are taken.
+// Run-end encoded columns stay encoded, consecutive rows of the same run form a single run.
+func Take(mem memory.Allocator, col *array.Column, indices *array.Int64) (*array.Column, error) {
+	switch col.DataType().(type) {
+	case *arrow.DictionaryType:
+		return takeDictionary(mem, col, indices)
+	case *arrow.RunEndEncodedType:
+		return takeRunEndEncoded(mem, col, indices)
+	}
+
+	chunks := col.Data().Chunks()
+	locate := newChunkLocator(chunks)
+
+	bldr := array.NewBuilder(mem, col.DataType())
+	defer bldr.Release()
+	bldr.Reserve(indices.Len())
+
+	for i := 0; i < indices.Len(); i++ {
+		if indices.IsNull(i) {
+			bldr.AppendNull()
+			continue
+		}
+		c, j, err := locate(indices.Value(i))
+		if err != nil {
+			return nil, err
+		}
+		if err := AppendValue(bldr, chunks[c], j); err != nil {
+			return nil, err
+		}
+	}
+
+	arr := bldr.NewArray()
+	defer arr.Release()
+
+	chunked := array.NewChunked(col.DataType(), []array.Interface{arr})
+	defer chunked.Release()
+
+	return array.NewColumn(col.Field(), chunked), nil
+}
+
+// newChunkLocator returns a function that maps a row index of the
+// chunked data to the chunk holding it and the index within that chunk.
+func newChunkLocator(chunks []array.Interface) func(idx int64) (int, int, error) {
+	// offsets[i] is the first row of chunk i.
+	offsets := make([]int64, len(chunks)+1)
+	for i, chunk := range chunks {
+		offsets[i+1] = offsets[i] + int64(chunk.Len())
+	}
+	total := offsets[len(chunks)]
+
+	return func(idx int64) (int, int, error) {
+		if idx < 0 || idx >= total {
+			return 0, 0, fmt.Errorf("compute: index %d out of range [0, %d)", idx, total)
+		}
+		c := sort.Search(len(chunks), func(i int) bool { return offsets[i+1] > idx })
+		return c, int(idx - offsets[c]), nil
+	}
+}
+
+// AppendValue appends the i-th element of arr to bldr.
+// bldr must have been created for the same DataType as arr. An index outside
+// of arr is reported with an *array.IndexError.
+func AppendValue(bldr array.Builder, arr array.Interface, i int) error {
+	if err := array.CheckIndex(arr, i); err != nil {
+		return err
+	}
+	if arr.IsNull(i) {
+		bldr.AppendNull()
+		return nil
+	}
+
+	switch a := arr.(type) {
+	case *array.Null:
+		bldr.AppendNull()
+	case *array.Boolean:
+		bldr.(*array.BooleanBuilder).Append(a.Value(i))
+	case *array.Int8:
+		bldr.(*array.Int8Builder).Append(a.Value(i))
+	case *array.Int16:
+		bldr.(*array.Int16Builder).Append(a.Value(i))
+	case *array.Int32:
+		bldr.(*array.Int32Builder).Append(a.Value(i))
+	case *array.Int64:
+		bldr.(*array.Int64Builder).Append(a.Value(i))
+	case *array.Uint8:
+		bldr.(*array.Uint8Builder).Append(a.Value(i))
+	case *array.Uint16:
+		bldr.(*array.Uint16Builder).Append(a.Value(i))
+	case *array.Uint32:
+		bldr.(*array.Uint32Builder).Append(a.Value(i))
+	case *array.Uint64:
+		bldr.(*array.Uint64Builder).Append(a.Value(i))
+	case *array.Float16:
+		bldr.(*array.Float16Builder).Append(a.Value(i))
+	case *array.Float32:
+		bldr.(*array.Float32Builder).Append(a.Value(i))
+	case *array.Float64:
+		bldr.(*array.Float64Builder).Append(a.Value(i))
+	case *array.Date32:
+		bldr.(*array.Date32Builder).Append(a.Value(i))
+	case *array.Date64:
+		bldr.(*array.Date64Builder).Append(a.Value(i))
+	case *array.Time32:
+		bldr.(*array.Time32Builder).Append(a.Value(i))
+	case *array.Time64:
+		bldr.(*array.Time64Builder).Append(a.Value(i))
+	case *array.Timestamp:
+		bldr.(*array.TimestampBuilder).Append(a.Value(i))
+	case *array.Duration:
+		bldr.(*array.DurationBuilder).Append(a.Value(i))
+	case *array.MonthInterval:
+		bldr.(*array.MonthIntervalBuilder).Append(a.Value(i))
+	case *array.DayTimeInterval:
+		bldr.(*array.DayTimeIntervalBuilder).Append(a.Value(i))
+	case *array.MonthDayNanoInterval:
+		bldr.(*array.MonthDayNanoIntervalBuilder).Append(a.Value(i))
+	case *array.Decimal128:
+		bldr.(*array.Decimal128Builder).Append(a.Value(i))
+	case *array.String:
+		bldr.(*array.StringBuilder).Append(a.Value(i))
+	case *array.Binary:
+		bldr.(*array.BinaryBuilder).Append(a.Value(i))
+	case *array.FixedSizeBinary:
+		bldr.(*array.FixedSizeBinaryBuilder).Append(a.Value(i))
+	case *array.List:
+		b := bldr.(*array.ListBuilder)
+		b.Append(true)
+		offsets := a.Offsets()
+		j := i + a.Data().Offset()
+		values := a.ListValues()
+		for k := int(offsets[j]); k < int(offsets[j+1]); k++ {
+			if err := AppendValue(b.ValueBuilder(), values, k); err != nil {
+				return err
+			}
+		}
+	case *array.FixedSizeList:
+		b := bldr.(*array.FixedSizeListBuilder)
+		b.Append(true)
+		values := a.ListValues()
+		n := int(a.DataType().(*arrow.FixedSizeListType).Len())
+		beg := (i + a.Data().Offset()) * n
+		for k := beg; k < beg+n; k++ {
+			if err := AppendValue(b.ValueBuilder(), values, k); err != nil {
+				return err
+			}
+		}
+	case *array.RunEndEncoded:
+		b := bldr.(*array.RunEndEncodedBuilder)
+		b.Append(1)
+		if err := AppendValue(b.ValueBuilder(), a.Values(), a.GetPhysicalIndex(i)); err != nil {
+			return err
+		}
+	case *array.Struct:
+		b := bldr.(*array.StructBuilder)
+		b.Append(true)
+		j := i + a.Data().Offset()
+		for f := 0; f < a.NumField(); f++ {
+			if err := AppendValue(b.FieldBuilder(f), a.Field(f), j); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("compute: unsupported array type %T", arr)
+	}
+
+	return nil
+}
@@ -0,0 +1,260 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+)
+
+const (
+	// MinTDigestCompression is the smallest compression accepted by NewTDigest.
+	MinTDigestCompression = 10
+	// DefaultTDigestCompression keeps about 100 centroids, estimating
+	// quantiles within a fraction of a percent of their rank.
+	DefaultTDigestCompression = 100
+
+	tdigestVersion = 1
+)
+
+// centroid is a cluster of values of a TDigest, summarized by their mean and
+// their number.
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// TDigest is a sketch for estimating the quantiles of numeric values. It
+// clusters the values into centroids, small near the extreme quantiles and
+// larger near the median, so that their number is bounded by the compression
+// regardless of the number of values added. Sketches can be merged, so
+// partitions can be sketched independently and combined afterwards.
+type TDigest struct {
+	compression float64
+	centroids   []centroid // merged, ordered by mean
+	pending     []centroid // added since the last merge
+	count       float64
+	min, max    float64
+}
+
+// NewTDigest creates a new empty sketch with the given compression.
+func NewTDigest(compression float64) (*TDigest, error) {
+	if !(compression >= MinTDigestCompression) {
+		return nil, fmt.Errorf("compute/tdigest: compression must be at least %d, got %v", MinTDigestCompression, compression)
+	}
+	return &TDigest{compression: compression, min: math.Inf(1), max: math.Inf(-1)}, nil
+}
+
+// Compression returns the compression the sketch was created with.
+func (t *TDigest) Compression() float64 { return t.compression }
+
+// Add adds a value to the sketch. NaNs are ignored.
+func (t *TDigest) Add(v float64) {
+	t.add(v, 1)
+}
+
+func (t *TDigest) add(mean, weight float64) {
+	if math.IsNaN(mean) || weight <= 0 {
+		return
+	}
+	t.pending = append(t.pending, centroid{mean: mean, weight: weight})
+	t.count += weight
+	t.min, t.max = math.Min(t.min, mean), math.Max(t.max, mean)
+	if len(t.pending) >= 8*int(t.compression) {
+		t.compress()
+	}
+}
+
+// Update adds all the non-null values of the numeric array arr to the sketch.
+// NaNs are ignored. Run-end encoded arrays are added run by run.
+func (t *TDigest) Update(arr array.Interface) error {
+	if ree, ok := arr.(*array.RunEndEncoded); ok {
+		values := ree.Values()
+		get, err := numberGetter(values)
+		if err != nil {
+			return err
+		}
+		return forEachRun(ree, func(j, n int) error {
+			if values.IsValid(j) {
+				t.add(get(j), float64(n))
+			}
+			return nil
+		})
+	}
+	get, err := numberGetter(arr)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < arr.Len(); i++ {
+		if arr.IsValid(i) {
+			t.add(get(i), 1)
+		}
+	}
+	return nil
+}
+
+// UpdateColumn adds all the non-null values of every chunk in col to the sketch.
+func (t *TDigest) UpdateColumn(col *array.Column) error {
+	for _, chunk := range col.Data().Chunks() {
+		if err := t.Update(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Merge folds the centroids of other into t. The merged sketch keeps the
+// compression of t.
+func (t *TDigest) Merge(other *TDigest) error {
+	if other == nil {
+		return nil
+	}
+	for _, cs := range [][]centroid{other.centroids, other.pending} {
+		for _, c := range cs {
+			t.add(c.mean, c.weight)
+		}
+	}
+	if other.count > 0 {
+		t.min, t.max = math.Min(t.min, other.min), math.Max(t.max, other.max)
+	}
+	return nil
+}
+
+// Count returns the number of values added to the sketch.
+func (t *TDigest) Count() uint64 { return uint64(t.count) }
+
+// Quantile returns the estimated q-quantile of the values added, the value
+// below which a fraction q of them fall. It returns NaN when the sketch is
+// empty or q is not in [0, 1].
+func (t *TDigest) Quantile(q float64) float64 {
+	if t.count == 0 || !(q >= 0 && q <= 1) {
+		return math.NaN()
+	}
+	t.compress()
+	cs := t.centroids
+	if len(cs) == 1 {
+		return cs[0].mean
+	}
+
+	// Interpolate between the centers of the centroids, and between the
+	// extreme values and the centers of the first and last centroids.
+	target := q * t.count
+	if center := cs[0].weight / 2; target < center {
+		return t.min + (cs[0].mean-t.min)*target/center
+	}
+	var cum float64
+	for k := 0; k < len(cs)-1; k++ {
+		left := cum + cs[k].weight/2
+		right := cum + cs[k].weight + cs[k+1].weight/2
+		if target <= right {
+			return cs[k].mean + (cs[k+1].mean-cs[k].mean)*(target-left)/(right-left)
+		}
+		cum += cs[k].weight
+	}
+	last := cs[len(cs)-1]
+	center := cum + last.weight/2
+	return last.mean + (t.max-last.mean)*(target-center)/(t.count-center)
+}
+
+// Reset clears the sketch so it can be reused.
+func (t *TDigest) Reset() {
+	t.centroids, t.pending = t.centroids[:0], t.pending[:0]
+	t.count, t.min, t.max = 0, math.Inf(1), math.Inf(-1)
+}
+
+// compress merges the pending centroids into the centroids, joining
+// neighbours as long as the quantiles they span stay within the limit set by
+// the compression.
+func (t *TDigest) compress() {
+	if len(t.pending) == 0 {
+		return
+	}
+	all := append(t.pending, t.centroids...)
+	sort.Slice(all, func(a, b int) bool { return all[a].mean < all[b].mean })
+
+	merged := make([]centroid, 0, len(t.centroids)+1)
+	cur, before := all[0], 0.0
+	limit := t.quantileLimit(0)
+	for _, c := range all[1:] {
+		if (before+cur.weight+c.weight)/t.count <= limit {
+			cur.weight += c.weight
+			cur.mean += (c.mean - cur.mean) * c.weight / cur.weight
+			continue
+		}
+		merged = append(merged, cur)
+		before += cur.weight
+		limit = t.quantileLimit(before / t.count)
+		cur = c
+	}
+	t.centroids = append(merged, cur)
+	t.pending = all[:0]
+}
+
+// quantileLimit returns the largest quantile a centroid starting at quantile
+// q may reach, one unit further along the scale k(q) = δ/2π·asin(2q-1).
+func (t *TDigest) quantileLimit(q float64) float64 {
+	k := t.compression/(2*math.Pi)*math.Asin(2*q-1) + 1
+	if k >= t.compression/4 {
+		return 1
+	}
+	return (math.Sin(2*math.Pi*k/t.compression) + 1) / 2
+}
+
+// MarshalBinary encodes the sketch so it can be shipped to another process and merged.
+func (t *TDigest) MarshalBinary() ([]byte, error) {
+	t.compress()
+	buf := make([]byte, 1+3*8+4+16*len(t.centroids))
+	buf[0] = tdigestVersion
+	binary.LittleEndian.PutUint64(buf[1:], math.Float64bits(t.compression))
+	binary.LittleEndian.PutUint64(buf[9:], math.Float64bits(t.min))
+	binary.LittleEndian.PutUint64(buf[17:], math.Float64bits(t.max))
+	binary.LittleEndian.PutUint32(buf[25:], uint32(len(t.centroids)))
+	for k, c := range t.centroids {
+		binary.LittleEndian.PutUint64(buf[29+16*k:], math.Float64bits(c.mean))
+		binary.LittleEndian.PutUint64(buf[37+16*k:], math.Float64bits(c.weight))
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a sketch encoded with MarshalBinary.
+func (t *TDigest) UnmarshalBinary(data []byte) error {
+	if len(data) < 29 {
+		return errors.New("compute/tdigest: sketch data too short")
+	}
+	if data[0] != tdigestVersion {
+		return fmt.Errorf("compute/tdigest: unsupported sketch version %d", data[0])
+	}
+	word := func(off int) float64 { return math.Float64frombits(binary.LittleEndian.Uint64(data[off:])) }
+	compression := word(1)
+	if !(compression >= MinTDigestCompression) {
+		return fmt.Errorf("compute/tdigest: invalid compression %v", compression)
+	}
+	n := int(binary.LittleEndian.Uint32(data[25:]))
+	if len(data)-29 != 16*n {
+		return fmt.Errorf("compute/tdigest: expected %d centroids, got %d bytes", n, len(data)-29)
+	}
+	t.compression, t.min, t.max = compression, word(9), word(17)
+	t.centroids, t.pending, t.count = make([]centroid, n), nil, 0
+	for k := range t.centroids {
+		t.centroids[k] = centroid{mean: word(29 + 16*k), weight: word(37 + 16*k)}
+		t.count += t.centroids[k].weight
+	}
+	return nil
+}
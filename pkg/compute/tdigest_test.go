@@ -0,0 +1,91 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestTDigest(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	// A shuffled permutation of [0, n) split over two partitions, one of
+	// which is shipped through its encoding before being merged.
+	const n = 100000
+	values := rand.New(rand.NewSource(1)).Perm(n)
+	chunks := [][]int64{make([]int64, 0, n/2), make([]int64, 0, n/2)}
+	for i, v := range values {
+		chunks[i%2] = append(chunks[i%2], int64(v))
+	}
+
+	var digests [2]*TDigest
+	for k, chunk := range chunks {
+		col := newInt64Column(pool, "x", chunk)
+		d, err := NewTDigest(DefaultTDigestCompression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := d.UpdateColumn(col); err != nil {
+			t.Fatal(err)
+		}
+		col.Release()
+		digests[k] = d
+	}
+	data, err := digests[1].MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var shipped TDigest
+	if err := shipped.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	d := digests[0]
+	if err := d.Merge(&shipped); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := d.Count(), uint64(n); got != want {
+		t.Fatalf("count: got=%d, want=%d", got, want)
+	}
+	if got := len(d.centroids); got > 2*DefaultTDigestCompression {
+		t.Fatalf("got %d centroids", got)
+	}
+	for _, tc := range []struct {
+		q, tolerance float64
+	}{
+		{0, 0}, {0.001, 0.001}, {0.01, 0.001}, {0.25, 0.01}, {0.5, 0.01}, {0.99, 0.001}, {1, 0},
+	} {
+		got, want := d.Quantile(tc.q), tc.q*(n-1)
+		if math.Abs(got-want) > tc.tolerance*n {
+			t.Errorf("quantile %v: got=%v, want=%v (+/- %v)", tc.q, got, want, tc.tolerance*n)
+		}
+	}
+	if got := d.Quantile(1.5); !math.IsNaN(got) {
+		t.Fatalf("quantile 1.5: got=%v, want=NaN", got)
+	}
+
+	d.Reset()
+	if got := d.Quantile(0.5); !math.IsNaN(got) {
+		t.Fatalf("quantile of an empty sketch: got=%v, want=NaN", got)
+	}
+	if _, err := NewTDigest(1); err == nil {
+		t.Fatal("expected an error for a compression of 1")
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+const (
+	nanosecondsPerDay  = int64(24 * time.Hour)
+	millisecondsPerDay = nanosecondsPerDay / int64(time.Millisecond)
+)
+
+// AddDuration adds the durations of dur to the timestamps of ts, row by row.
+// The result uses the finer of the two units and keeps the time zone of ts.
+// A row is null when either input is null.
+func AddDuration(mem memory.Allocator, ts, dur *array.Column) (*array.Column, error) {
+	tsType, ok := ts.DataType().(*arrow.TimestampType)
+	if !ok {
+		return nil, fmt.Errorf("compute: AddDuration expects a timestamp column, got %s", ts.DataType())
+	}
+	durType, ok := dur.DataType().(*arrow.DurationType)
+	if !ok {
+		return nil, fmt.Errorf("compute: AddDuration expects a duration column, got %s", dur.DataType())
+	}
+	if ts.Len() != dur.Len() {
+		return nil, fmt.Errorf("compute: AddDuration column lengths differ (%d != %d)", ts.Len(), dur.Len())
+	}
+
+	unit := finerUnit(tsType.Unit, durType.Unit)
+	tsScale := unitNanoseconds(tsType.Unit) / unitNanoseconds(unit)
+	durScale := unitNanoseconds(durType.Unit) / unitNanoseconds(unit)
+	dtype := &arrow.TimestampType{Unit: unit, TimeZone: tsType.TimeZone}
+
+	bldr := array.NewTimestampBuilder(mem, dtype)
+	defer bldr.Release()
+	bldr.Reserve(ts.Len())
+
+	left, right := newRowCursor(ts), newRowCursor(dur)
+	for i := 0; i < ts.Len(); i++ {
+		l, li := left.next()
+		r, ri := right.next()
+		if l.IsNull(li) || r.IsNull(ri) {
+			bldr.AppendNull()
+			continue
+		}
+		v := int64(l.(*array.Timestamp).Value(li))*tsScale + int64(r.(*array.Duration).Value(ri))*durScale
+		bldr.Append(arrow.Timestamp(v))
+	}
+
+	return newResultColumn(ts.Name(), bldr.NewArray()), nil
+}
+
+// SubtractTimestamps returns the durations between the timestamps of a and b (a - b), row by row.
+// The result uses the finer of the two units. A row is null when either input is null.
+func SubtractTimestamps(mem memory.Allocator, a, b *array.Column) (*array.Column, error) {
+	aType, ok := a.DataType().(*arrow.TimestampType)
+	if !ok {
+		return nil, fmt.Errorf("compute: SubtractTimestamps expects timestamp columns, got %s", a.DataType())
+	}
+	bType, ok := b.DataType().(*arrow.TimestampType)
+	if !ok {
+		return nil, fmt.Errorf("compute: SubtractTimestamps expects timestamp columns, got %s", b.DataType())
+	}
+	if a.Len() != b.Len() {
+		return nil, fmt.Errorf("compute: SubtractTimestamps column lengths differ (%d != %d)", a.Len(), b.Len())
+	}
+
+	unit := finerUnit(aType.Unit, bType.Unit)
+	aScale := unitNanoseconds(aType.Unit) / unitNanoseconds(unit)
+	bScale := unitNanoseconds(bType.Unit) / unitNanoseconds(unit)
+
+	bldr := array.NewDurationBuilder(mem, &arrow.DurationType{Unit: unit})
+	defer bldr.Release()
+	bldr.Reserve(a.Len())
+
+	left, right := newRowCursor(a), newRowCursor(b)
+	for i := 0; i < a.Len(); i++ {
+		l, li := left.next()
+		r, ri := right.next()
+		if l.IsNull(li) || r.IsNull(ri) {
+			bldr.AppendNull()
+			continue
+		}
+		v := int64(l.(*array.Timestamp).Value(li))*aScale - int64(r.(*array.Timestamp).Value(ri))*bScale
+		bldr.Append(arrow.Duration(v))
+	}
+
+	return newResultColumn(a.Name(), bldr.NewArray()), nil
+}
+
+// AddMonths adds a number of calendar months to each value of a date32, date64 or timestamp column.
+// See AddInterval for how month lengths are handled.
+func AddMonths(mem memory.Allocator, col *array.Column, months int32) (*array.Column, error) {
+	return AddInterval(mem, col, arrow.MonthDayNanoInterval{Months: months})
+}
+
+// AddInterval adds iv to each value of a date32, date64 or timestamp column.
+// The months are added first, then the days and then the nanoseconds.
+// Adding months keeps the day of the month, clamped to the length of the
+// resulting month, so 2020-01-31 plus one month is 2020-02-29.
+// Timestamps are shifted in the wall clock of their time zone.
+//
+// The nanoseconds of iv must be expressible in the unit of the column,
+// whole days for date32 and whole milliseconds for date64.
+func AddInterval(mem memory.Allocator, col *array.Column, iv arrow.MonthDayNanoInterval) (*array.Column, error) {
+	var (
+		bldr  array.Builder
+		shift func(arr array.Interface, i int)
+	)
+
+	switch dtype := col.DataType().(type) {
+	case *arrow.Date32Type:
+		if iv.Nanoseconds%nanosecondsPerDay != 0 {
+			return nil, fmt.Errorf("compute: interval %v is not a whole number of days", iv)
+		}
+		b := array.NewDate32Builder(mem)
+		bldr = b
+		shift = func(arr array.Interface, i int) {
+			t := time.Unix(int64(arr.(*array.Date32).Value(i))*86400, 0).UTC()
+			t = addInterval(t, iv)
+			b.Append(arrow.Date32(t.Unix() / 86400))
+		}
+
+	case *arrow.Date64Type:
+		if iv.Nanoseconds%int64(time.Millisecond) != 0 {
+			return nil, fmt.Errorf("compute: interval %v is not a whole number of milliseconds", iv)
+		}
+		b := array.NewDate64Builder(mem)
+		bldr = b
+		shift = func(arr array.Interface, i int) {
+			ms := int64(arr.(*array.Date64).Value(i))
+			t := time.Unix(0, ms*int64(time.Millisecond)).UTC()
+			t = addInterval(t, iv)
+			b.Append(arrow.Date64(t.UnixNano() / int64(time.Millisecond)))
+		}
+
+	case *arrow.TimestampType:
+		scale := unitNanoseconds(dtype.Unit)
+		if iv.Nanoseconds%scale != 0 {
+			return nil, fmt.Errorf("compute: interval %v is not a whole number of %s", iv, dtype.Unit)
+		}
+		loc := time.UTC
+		if dtype.TimeZone != "" {
+			var err error
+			if loc, err = time.LoadLocation(dtype.TimeZone); err != nil {
+				return nil, fmt.Errorf("compute: could not load time zone %q: %w", dtype.TimeZone, err)
+			}
+		}
+		b := array.NewTimestampBuilder(mem, dtype)
+		bldr = b
+		shift = func(arr array.Interface, i int) {
+			t := time.Unix(0, int64(arr.(*array.Timestamp).Value(i))*scale).In(loc)
+			t = addInterval(t, iv)
+			b.Append(arrow.Timestamp(t.UnixNano() / scale))
+		}
+
+	default:
+		return nil, fmt.Errorf("compute: cannot add an interval to %s", col.DataType())
+	}
+	defer bldr.Release()
+	bldr.Reserve(col.Len())
+
+	for _, chunk := range col.Data().Chunks() {
+		for i := 0; i < chunk.Len(); i++ {
+			if chunk.IsNull(i) {
+				bldr.AppendNull()
+				continue
+			}
+			shift(chunk, i)
+		}
+	}
+
+	return newResultColumn(col.Name(), bldr.NewArray()), nil
+}
+
+// addInterval adds the months, days and nanoseconds of iv to t, in that order.
+func addInterval(t time.Time, iv arrow.MonthDayNanoInterval) time.Time {
+	if iv.Months != 0 {
+		year, month, day := t.Date()
+		months := int(month) - 1 + int(iv.Months)
+		year += months / 12
+		months %= 12
+		if months < 0 {
+			months += 12
+			year--
+		}
+		month = time.Month(months + 1)
+		if last := daysIn(year, month); day > last {
+			day = last
+		}
+		t = time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	}
+	if iv.Days != 0 {
+		t = t.AddDate(0, 0, int(iv.Days))
+	}
+	return t.Add(time.Duration(iv.Nanoseconds))
+}
+
+// daysIn returns the number of days in the given month.
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// unitNanoseconds returns the number of nanoseconds in one unit.
+func unitNanoseconds(unit arrow.TimeUnit) int64 {
+	switch unit {
+	case arrow.Second:
+		return int64(time.Second)
+	case arrow.Millisecond:
+		return int64(time.Millisecond)
+	case arrow.Microsecond:
+		return int64(time.Microsecond)
+	default:
+		return 1
+	}
+}
+
+// finerUnit returns the unit with the best resolution.
+func finerUnit(a, b arrow.TimeUnit) arrow.TimeUnit {
+	if unitNanoseconds(a) < unitNanoseconds(b) {
+		return a
+	}
+	return b
+}
+
+// newResultColumn wraps arr in a single chunk Column named name and releases arr.
+func newResultColumn(name string, arr array.Interface) *array.Column {
+	defer arr.Release()
+	chunked := array.NewChunked(arr.DataType(), []array.Interface{arr})
+	defer chunked.Release()
+	return array.NewColumn(arrow.Field{Name: name, Type: arr.DataType(), Nullable: true}, chunked)
+}
+
+// rowCursor walks the rows of a chunked column in order, so that two
+// columns with different chunk layouts can be processed side by side.
+type rowCursor struct {
+	chunks []array.Interface
+	chunk  int
+	index  int
+}
+
+func newRowCursor(col *array.Column) *rowCursor {
+	return &rowCursor{chunks: col.Data().Chunks()}
+}
+
+// next returns the chunk and the index within that chunk of the next row.
+// It must not be called more than col.Len() times.
+func (c *rowCursor) next() (array.Interface, int) {
+	for c.index >= c.chunks[c.chunk].Len() {
+		c.chunk++
+		c.index = 0
+	}
+	i := c.index
+	c.index++
+	return c.chunks[c.chunk], i
+}
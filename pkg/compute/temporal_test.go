@@ -0,0 +1,128 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func newSingleChunkColumn(name string, arr array.Interface) *array.Column {
+	chunked := array.NewChunked(arr.DataType(), []array.Interface{arr})
+	defer chunked.Release()
+	return array.NewColumn(arrow.Field{Name: name, Type: arr.DataType(), Nullable: true}, chunked)
+}
+
+func TestAddMonths(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	day := func(s string) arrow.Date32 {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return arrow.Date32(d.Unix() / 86400)
+	}
+
+	b := array.NewDate32Builder(pool)
+	defer b.Release()
+	b.AppendValues([]arrow.Date32{day("2020-01-31"), day("2019-12-15"), 0, day("2020-03-31")}, []bool{true, true, false, true})
+	arr := b.NewArray()
+	defer arr.Release()
+	col := newSingleChunkColumn("d", arr)
+	defer col.Release()
+
+	cases := []struct {
+		months int32
+		want   []string
+	}{
+		{1, []string{"2020-02-29", "2020-01-15", "", "2020-04-30"}},
+		{-1, []string{"2019-12-31", "2019-11-15", "", "2020-02-29"}},
+		{13, []string{"2021-02-28", "2021-01-15", "", "2021-04-30"}},
+	}
+	for _, c := range cases {
+		res, err := AddMonths(pool, col, c.months)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := res.Data().Chunk(0).(*array.Date32)
+		for i, want := range c.want {
+			if want == "" {
+				if got.IsValid(i) {
+					t.Errorf("months=%d row %d: expected null", c.months, i)
+				}
+				continue
+			}
+			if got.Value(i) != day(want) {
+				v := time.Unix(int64(got.Value(i))*86400, 0).UTC().Format("2006-01-02")
+				t.Errorf("months=%d row %d: got=%s, want=%s", c.months, i, v, want)
+			}
+		}
+		res.Release()
+	}
+}
+
+func TestTimestampDurationArithmetic(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	tsb := array.NewTimestampBuilder(pool, &arrow.TimestampType{Unit: arrow.Second, TimeZone: "UTC"})
+	defer tsb.Release()
+	tsb.AppendValues([]arrow.Timestamp{10, 20, 30}, []bool{true, true, true})
+	tsArr := tsb.NewArray()
+	defer tsArr.Release()
+	ts := newSingleChunkColumn("ts", tsArr)
+	defer ts.Release()
+
+	db := array.NewDurationBuilder(pool, &arrow.DurationType{Unit: arrow.Millisecond})
+	defer db.Release()
+	db.AppendValues([]arrow.Duration{1500, 0, -2000}, []bool{true, false, true})
+	dArr := db.NewArray()
+	defer dArr.Release()
+	dur := newSingleChunkColumn("d", dArr)
+	defer dur.Release()
+
+	sum, err := AddDuration(pool, ts, dur)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sum.Release()
+
+	if got, want := sum.DataType().(*arrow.TimestampType).Unit, arrow.Millisecond; got != want {
+		t.Fatalf("got unit=%s, want=%s", got, want)
+	}
+	if got, want := sum.Data().Chunk(0).(*array.Timestamp).String(), "[11500 (null) 28000]"; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+
+	diff, err := SubtractTimestamps(pool, sum, ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer diff.Release()
+
+	if got, want := diff.Data().Chunk(0).(*array.Duration).String(), "[1500 (null) -2000]"; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+
+	if _, err := AddDuration(pool, dur, ts); err == nil {
+		t.Fatal("expected an error adding a timestamp to a duration")
+	}
+}
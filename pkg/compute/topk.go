@@ -0,0 +1,110 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// TopK returns the row indices of the k smallest (Ascending) or k largest (Descending)
+// values of col, ordered from first to last according to order.
+// Null values are never selected, so fewer than k indices are returned when
+// col has fewer than k non-null values. Ties are broken by row index.
+//
+// TopK keeps a heap of k entries instead of sorting the whole column.
+func TopK(mem memory.Allocator, col *array.Column, k int, order SortOrder) (*array.Int64, error) {
+	if k < 0 {
+		return nil, fmt.Errorf("compute: TopK k must be >= 0, got %d", k)
+	}
+
+	chunks := col.Data().Chunks()
+	cmp, err := newFieldComparator(col.Field(), chunks)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &topKHeap{cmp: cmp, order: order}
+	if k > 0 {
+		var offset int64
+		for c, chunk := range chunks {
+			for i := 0; i < chunk.Len(); i++ {
+				if chunk.IsNull(i) {
+					continue
+				}
+				e := topKEntry{pos: position{chunk: c, index: i}, row: offset + int64(i)}
+				if h.Len() < k {
+					heap.Push(h, e)
+					continue
+				}
+				// The root is the worst value kept so far.
+				if h.better(e, h.entries[0]) {
+					h.entries[0] = e
+					heap.Fix(h, 0)
+				}
+			}
+			offset += int64(chunk.Len())
+		}
+	}
+
+	sort.Slice(h.entries, func(i, j int) bool { return h.better(h.entries[i], h.entries[j]) })
+
+	bldr := array.NewInt64Builder(mem)
+	defer bldr.Release()
+	bldr.Reserve(len(h.entries))
+	for _, e := range h.entries {
+		bldr.Append(e.row)
+	}
+
+	return bldr.NewInt64Array(), nil
+}
+
+type topKEntry struct {
+	pos position
+	row int64
+}
+
+// topKHeap is a heap whose root is the worst of the kept entries.
+type topKHeap struct {
+	entries []topKEntry
+	cmp     valueComparator
+	order   SortOrder
+}
+
+// better returns true when a should come before b in the result.
+func (h *topKHeap) better(a, b topKEntry) bool {
+	c := h.cmp(a.pos, b.pos)
+	if h.order == Descending {
+		c = -c
+	}
+	if c != 0 {
+		return c < 0
+	}
+	return a.row < b.row
+}
+
+func (h *topKHeap) Len() int           { return len(h.entries) }
+func (h *topKHeap) Less(i, j int) bool { return h.better(h.entries[j], h.entries[i]) }
+func (h *topKHeap) Swap(i, j int)      { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+func (h *topKHeap) Push(x interface{}) { h.entries = append(h.entries, x.(topKEntry)) }
+func (h *topKHeap) Pop() (v interface{}) {
+	n := len(h.entries) - 1
+	v, h.entries = h.entries[n], h.entries[:n]
+	return v
+}
@@ -0,0 +1,132 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func newFloat64Column(mem memory.Allocator, name string, values []float64, valid []bool) *array.Column {
+	b := array.NewFloat64Builder(mem)
+	defer b.Release()
+	b.AppendValues(values, valid)
+	arr := b.NewArray()
+	defer arr.Release()
+
+	chunked := array.NewChunked(arrow.PrimitiveTypes.Float64, []array.Interface{arr})
+	defer chunked.Release()
+	return array.NewColumn(arrow.Field{Name: name, Type: arrow.PrimitiveTypes.Float64, Nullable: true}, chunked)
+}
+
+func TestTopK(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	col := newInt64Column(pool, "v", []int64{5, 1, 9, 3}, []int64{7, 9, 0, 2, 8})
+	defer col.Release()
+
+	cases := []struct {
+		k     int
+		order SortOrder
+		want  string
+	}{
+		{k: 3, order: Descending, want: "[2 5 8]"},
+		{k: 3, order: Ascending, want: "[6 1 7]"},
+		{k: 0, order: Descending, want: "[]"},
+		{k: 20, order: Ascending, want: "[6 1 7 3 0 4 8 2 5]"},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("k=%d/%v", c.k, c.order), func(t *testing.T) {
+			indices, err := TopK(pool, col, c.k, c.order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer indices.Release()
+
+			if got := fmt.Sprintf("%v", indices); got != c.want {
+				t.Fatalf("got=%v, want=%v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestTopKNulls(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	col := newFloat64Column(pool, "v",
+		[]float64{1, 100, 3, math.NaN(), 2},
+		[]bool{true, false, true, true, true},
+	)
+	defer col.Release()
+
+	indices, err := TopK(pool, col, 2, Ascending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer indices.Release()
+	if got, want := fmt.Sprintf("%v", indices), "[0 4]"; got != want {
+		t.Fatalf("got=%v, want=%v", got, want)
+	}
+
+	// NaN sorts after every number and the null is never selected.
+	indices2, err := TopK(pool, col, 10, Descending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer indices2.Release()
+	if got, want := fmt.Sprintf("%v", indices2), "[3 2 4 0]"; got != want {
+		t.Fatalf("got=%v, want=%v", got, want)
+	}
+}
+
+func TestTake(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	col := newInt64Column(pool, "v", []int64{10, 11, 12}, []int64{13, 14})
+	defer col.Release()
+
+	ib := array.NewInt64Builder(pool)
+	defer ib.Release()
+	ib.AppendValues([]int64{4, 0, 0, 3}, nil)
+	ib.AppendNull()
+	indices := ib.NewInt64Array()
+	defer indices.Release()
+
+	got, err := Take(pool, col, indices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Release()
+
+	if got, want := fmt.Sprintf("%v", got.Data().Chunk(0)), "[14 10 10 13 (null)]"; got != want {
+		t.Fatalf("got=%v, want=%v", got, want)
+	}
+
+	ib.Append(5)
+	outOfRange := ib.NewInt64Array()
+	defer outOfRange.Release()
+	if _, err := Take(pool, col, outOfRange); err == nil {
+		t.Fatal("expected an out of range error")
+	}
+}
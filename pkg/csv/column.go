@@ -0,0 +1,181 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/decimal128"
+	"github.com/gomem/gomem/arrow/go/arrow/float16"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+const dateLayout = "2006-01-02"
+
+var errInvalidUTF8 = errors.New("invalid UTF-8")
+
+// column parses the fields of one CSV column into an Arrow builder.
+// A row is parsed into the pending value of every column first, and only
+// appended once the whole row is known to be valid.
+type column struct {
+	index int    // position of the column in the CSV rows
+	name  string // name of the column, for error messages
+	bldr  array.Builder
+
+	parse  func(s string) error // parses s as the pending value
+	append func()               // appends the pending value
+	null   bool                 // the pending value is null
+}
+
+func (c *column) commit() {
+	if c.null {
+		c.bldr.AppendNull()
+		return
+	}
+	c.append()
+}
+
+// newColumn returns a column parsing the index-th field of the rows into bldr,
+// checking the strings of string columns with policy.
+func newColumn(index int, field arrow.Field, bldr array.Builder, policy array.UTF8Policy) (*column, error) {
+	c := &column{index: index, name: field.Name, bldr: bldr}
+
+	switch dtype := field.Type.(type) {
+	case *arrow.BooleanType:
+		var v bool
+		b := bldr.(*array.BooleanBuilder)
+		c.parse = func(s string) (err error) { v, err = strconv.ParseBool(s); return err }
+		c.append = func() { b.Append(v) }
+	case *arrow.Int8Type:
+		var v int64
+		b := bldr.(*array.Int8Builder)
+		c.parse = func(s string) (err error) { v, err = parseInt(s, 8); return err }
+		c.append = func() { b.Append(int8(v)) }
+	case *arrow.Int16Type:
+		var v int64
+		b := bldr.(*array.Int16Builder)
+		c.parse = func(s string) (err error) { v, err = parseInt(s, 16); return err }
+		c.append = func() { b.Append(int16(v)) }
+	case *arrow.Int32Type:
+		var v int64
+		b := bldr.(*array.Int32Builder)
+		c.parse = func(s string) (err error) { v, err = parseInt(s, 32); return err }
+		c.append = func() { b.Append(int32(v)) }
+	case *arrow.Int64Type:
+		var v int64
+		b := bldr.(*array.Int64Builder)
+		c.parse = func(s string) (err error) { v, err = parseInt(s, 64); return err }
+		c.append = func() { b.Append(v) }
+	case *arrow.Uint8Type:
+		var v uint64
+		b := bldr.(*array.Uint8Builder)
+		c.parse = func(s string) (err error) { v, err = parseUint(s, 8); return err }
+		c.append = func() { b.Append(uint8(v)) }
+	case *arrow.Uint16Type:
+		var v uint64
+		b := bldr.(*array.Uint16Builder)
+		c.parse = func(s string) (err error) { v, err = parseUint(s, 16); return err }
+		c.append = func() { b.Append(uint16(v)) }
+	case *arrow.Uint32Type:
+		var v uint64
+		b := bldr.(*array.Uint32Builder)
+		c.parse = func(s string) (err error) { v, err = parseUint(s, 32); return err }
+		c.append = func() { b.Append(uint32(v)) }
+	case *arrow.Uint64Type:
+		var v uint64
+		b := bldr.(*array.Uint64Builder)
+		c.parse = func(s string) (err error) { v, err = parseUint(s, 64); return err }
+		c.append = func() { b.Append(v) }
+	case *arrow.Float16Type:
+		var v float64
+		b := bldr.(*array.Float16Builder)
+		c.parse = func(s string) (err error) { v, err = parseFloat(s, 32); return err }
+		c.append = func() { b.Append(float16.New(float32(v))) }
+	case *arrow.Float32Type:
+		var v float64
+		b := bldr.(*array.Float32Builder)
+		c.parse = func(s string) (err error) { v, err = parseFloat(s, 32); return err }
+		c.append = func() { b.Append(float32(v)) }
+	case *arrow.Float64Type:
+		var v float64
+		b := bldr.(*array.Float64Builder)
+		c.parse = func(s string) (err error) { v, err = parseFloat(s, 64); return err }
+		c.append = func() { b.Append(v) }
+	case *arrow.Decimal128Type:
+		var v decimal128.Num
+		b := bldr.(*array.Decimal128Builder)
+		c.parse = func(s string) (err error) { v, err = parseDecimal128(s, dtype.Precision, dtype.Scale); return err }
+		c.append = func() { b.Append(v) }
+	case *arrow.StringType:
+		var v string
+		b := bldr.(*array.StringBuilder)
+		c.parse = func(s string) error {
+			v = s
+			if policy == array.UTF8Unchecked || utf8.ValidString(s) {
+				return nil
+			}
+			if policy == array.UTF8Replace {
+				v = strings.ToValidUTF8(s, string(utf8.RuneError))
+				return nil
+			}
+			return errInvalidUTF8
+		}
+		c.append = func() { b.Append(v) }
+	case *arrow.BinaryType:
+		var v string
+		b := bldr.(*array.BinaryBuilder)
+		c.parse = func(s string) error { v = s; return nil }
+		c.append = func() { b.AppendString(v) }
+	case *arrow.Date32Type:
+		var v time.Time
+		b := bldr.(*array.Date32Builder)
+		c.parse = func(s string) (err error) { v, err = time.Parse(dateLayout, s); return err }
+		c.append = func() { b.Append(arrow.Date32(v.Unix() / 86400)) }
+	case *arrow.TimestampType:
+		var v time.Time
+		b := bldr.(*array.TimestampBuilder)
+		scale := timestampScale(dtype.Unit)
+		c.parse = func(s string) (err error) {
+			v, err = compute.ParseTime(s, compute.TimestampLayouts, time.UTC)
+			return err
+		}
+		c.append = func() { b.Append(arrow.Timestamp(v.UnixNano() / scale)) }
+	default:
+		return nil, fmt.Errorf("csv: unsupported column type %s for column %q", field.Type, field.Name)
+	}
+
+	return c, nil
+}
+
+// timestampScale returns the number of nanoseconds in one unit.
+func timestampScale(unit arrow.TimeUnit) int64 {
+	switch unit {
+	case arrow.Second:
+		return int64(time.Second)
+	case arrow.Millisecond:
+		return int64(time.Millisecond)
+	case arrow.Microsecond:
+		return int64(time.Microsecond)
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package csv reads and writes CSV files as streams of Arrow records.
+
+The Reader parses the input chunk by chunk, so files larger than memory can be
+processed one record at a time. The schema is either provided or inferred from
+a sample of the first rows, only the projected columns are parsed, and rows
+that cannot be parsed are handled according to an ErrorPolicy.
+
+	r, err := csv.NewReader(f, csv.WithChunk(100000), csv.WithColumns("id", "price"), csv.OnError(csv.Skip))
+	if err != nil {
+		return err
+	}
+	defer r.Release()
+	for r.Next() {
+		rec := r.Record()
+		// ...
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+WithReadOptions pushes a pushdown.ReadOptions down to the Reader: only the
+columns read by its projection and its filter are parsed, and the records hold
+the selected rows only. A Source opens a file for readers taking any
+pushdown.Source.
+
+WithUTF8Validation checks the fields of string columns for invalid UTF-8,
+either replacing the invalid sequences or handling the rows holding them
+according to the ErrorPolicy. ipc.WithUTF8Validation does the same for the
+records read from Arrow streams and files.
+
+The Writer writes records back as CSV rows, formatting the values the way
+the Reader parses them, so that a file written with the same options reads
+back to the same records.
+
+*/
+package csv
@@ -0,0 +1,87 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+)
+
+// inference tracks the types the sampled values of a column can be parsed as.
+type inference struct {
+	values                         int
+	isBool, isInt, isFloat, isDate bool
+}
+
+func newInference() *inference {
+	return &inference{isBool: true, isInt: true, isFloat: true, isDate: true}
+}
+
+func (in *inference) observe(s string) {
+	in.values++
+	if in.isBool {
+		in.isBool = strings.EqualFold(s, "true") || strings.EqualFold(s, "false")
+	}
+	if in.isInt {
+		_, err := parseInt(s, 64)
+		in.isInt = err == nil
+	}
+	if in.isFloat {
+		_, err := parseFloat(s, 64)
+		in.isFloat = err == nil
+	}
+	if in.isDate {
+		_, err := time.Parse(dateLayout, s)
+		in.isDate = err == nil
+	}
+}
+
+// dataType returns the narrowest type holding every observed value.
+// Columns without values are read as strings.
+func (in *inference) dataType() arrow.DataType {
+	switch {
+	case in.values == 0:
+		return arrow.BinaryTypes.String
+	case in.isBool:
+		return arrow.FixedWidthTypes.Boolean
+	case in.isInt:
+		return arrow.PrimitiveTypes.Int64
+	case in.isFloat:
+		return arrow.PrimitiveTypes.Float64
+	case in.isDate:
+		return arrow.FixedWidthTypes.Date32
+	default:
+		return arrow.BinaryTypes.String
+	}
+}
+
+// inferTypes returns the type of each of the given columns of rows.
+// Null values and rows too short to hold a column are ignored.
+func inferTypes(rows [][]string, columns []int, isNull func(string) bool) []arrow.DataType {
+	types := make([]arrow.DataType, len(columns))
+	for i, index := range columns {
+		in := newInference()
+		for _, row := range rows {
+			if index >= len(row) || isNull(row[index]) {
+				continue
+			}
+			in.observe(row[index])
+		}
+		types[i] = in.dataType()
+	}
+	return types
+}
@@ -0,0 +1,226 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv
+
+import (
+	"math/big"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/gomem/gomem/arrow/go/arrow/decimal128"
+)
+
+// The parsers below read the common shapes of CSV numbers byte by byte,
+// without allocating. Anything they cannot handle exactly, from exponents to
+// malformed fields, goes through strconv, so the values and the errors are
+// the ones of strconv.
+
+// maxFastDigits is the number of decimal digits that always fit in an uint64.
+const maxFastDigits = 19
+
+// parseDigits returns the value of the decimal digits of s. ok is false if s
+// is empty, holds anything but digits or is too long to be read without
+// overflow checks.
+func parseDigits(s string) (v uint64, ok bool) {
+	if len(s) == 0 || len(s) > maxFastDigits {
+		return 0, false
+	}
+	for i := 0; i < len(s); i++ {
+		d := s[i] - '0'
+		if d > 9 {
+			return 0, false
+		}
+		v = v*10 + uint64(d)
+	}
+	return v, true
+}
+
+// parseInt is strconv.ParseInt(s, 10, bitSize).
+func parseInt(s string, bitSize int) (int64, error) {
+	digits, neg := s, false
+	if len(digits) > 0 && (digits[0] == '-' || digits[0] == '+') {
+		digits, neg = digits[1:], digits[0] == '-'
+	}
+	if u, ok := parseDigits(digits); ok {
+		limit := uint64(1) << uint(bitSize-1)
+		if u < limit || neg && u == limit {
+			if neg {
+				return -int64(u), nil
+			}
+			return int64(u), nil
+		}
+	}
+	return strconv.ParseInt(s, 10, bitSize)
+}
+
+// parseUint is strconv.ParseUint(s, 10, bitSize).
+func parseUint(s string, bitSize int) (uint64, error) {
+	if u, ok := parseDigits(s); ok && (bitSize == 64 || u < uint64(1)<<uint(bitSize)) {
+		return u, nil
+	}
+	return strconv.ParseUint(s, 10, bitSize)
+}
+
+// float64pow10 and float32pow10 hold the powers of ten that are exact in
+// their type.
+var (
+	float64pow10 = [...]float64{1e0, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10, 1e11, 1e12, 1e13, 1e14, 1e15, 1e16, 1e17, 1e18, 1e19, 1e20, 1e21, 1e22}
+	float32pow10 = [...]float32{1e0, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10}
+)
+
+// parseFloat is strconv.ParseFloat(s, bitSize).
+//
+// Numbers written as digits with an optional fraction are read as an integer
+// mantissa divided by a power of ten. When both are exact in the target type,
+// the division is correctly rounded and gives the same value as strconv.
+func parseFloat(s string, bitSize int) (float64, error) {
+	digits, neg := s, false
+	if len(digits) > 0 && (digits[0] == '-' || digits[0] == '+') {
+		digits, neg = digits[1:], digits[0] == '-'
+	}
+
+	var mant uint64
+	n, frac, dot := 0, 0, false
+	for i := 0; i < len(digits); i++ {
+		c := digits[i]
+		switch {
+		case c == '.' && !dot:
+			dot = true
+		case c >= '0' && c <= '9':
+			if n == maxFastDigits {
+				return strconv.ParseFloat(s, bitSize)
+			}
+			mant = mant*10 + uint64(c-'0')
+			n++
+			if dot {
+				frac++
+			}
+		default:
+			return strconv.ParseFloat(s, bitSize)
+		}
+	}
+	if n == 0 {
+		return strconv.ParseFloat(s, bitSize)
+	}
+
+	var f float64
+	if bitSize == 32 {
+		if mant > 1<<24 || frac >= len(float32pow10) {
+			return strconv.ParseFloat(s, bitSize)
+		}
+		f = float64(float32(mant) / float32pow10[frac])
+	} else {
+		if mant > 1<<53 || frac >= len(float64pow10) {
+			return strconv.ParseFloat(s, bitSize)
+		}
+		f = float64(mant) / float64pow10[frac]
+	}
+	if neg {
+		f = -f
+	}
+	return f, nil
+}
+
+// parseDecimal128 parses s as a decimal number and returns it scaled by
+// 10^scale. The number must have at most precision digits once scaled and no
+// more than scale non-zero fractional digits. Errors are *strconv.NumError.
+func parseDecimal128(s string, precision, scale int32) (decimal128.Num, error) {
+	digits, neg := s, false
+	if len(digits) > 0 && (digits[0] == '-' || digits[0] == '+') {
+		digits, neg = digits[1:], digits[0] == '-'
+	}
+
+	// hi:lo is the magnitude of the unscaled value.
+	var hi, lo uint64
+	n, frac, dot := int32(0), int32(0), false
+	for i := 0; i < len(digits); i++ {
+		c := digits[i]
+		switch {
+		case c == '.' && !dot:
+			dot = true
+			continue
+		case c < '0' || c > '9':
+			return decimal128.Num{}, decimalError(s, strconv.ErrSyntax)
+		}
+		if dot && frac == scale {
+			// Fractional digits past the scale can only be zeros.
+			if c != '0' {
+				return decimal128.Num{}, decimalError(s, strconv.ErrRange)
+			}
+			continue
+		}
+		if n > 0 || c != '0' {
+			if n++; n > precision {
+				return decimal128.Num{}, decimalError(s, strconv.ErrRange)
+			}
+		}
+		hi, lo = mulAdd10(hi, lo, uint64(c-'0'))
+		if dot {
+			frac++
+		}
+	}
+	if len(digits) == 0 || digits == "." {
+		return decimal128.Num{}, decimalError(s, strconv.ErrSyntax)
+	}
+	for ; frac < scale; frac++ {
+		if n > 0 {
+			if n++; n > precision {
+				return decimal128.Num{}, decimalError(s, strconv.ErrRange)
+			}
+		}
+		hi, lo = mulAdd10(hi, lo, 0)
+	}
+
+	if neg {
+		// Two's complement of the magnitude.
+		lo, hi = ^lo+1, ^hi
+		if lo == 0 {
+			hi++
+		}
+	}
+	return decimal128.New(int64(hi), lo), nil
+}
+
+// mulAdd10 returns hi:lo * 10 + d. Decimals have at most 38 digits, which
+// cannot overflow 128 bits.
+func mulAdd10(hi, lo, d uint64) (uint64, uint64) {
+	carry, lo := bits.Mul64(lo, 10)
+	lo, c := bits.Add64(lo, d, 0)
+	return hi*10 + carry + c, lo
+}
+
+// formatDecimal128 formats the value of n scaled by 10^-scale the way
+// parseDecimal128 reads it.
+func formatDecimal128(n decimal128.Num, scale int32) string {
+	v := new(big.Int).Lsh(big.NewInt(n.HighBits()), 64)
+	v.Or(v, new(big.Int).SetUint64(n.LowBits()))
+
+	digits := new(big.Int).Abs(v).String()
+	if scale > 0 {
+		if pad := int(scale) + 1 - len(digits); pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+		digits = digits[:len(digits)-int(scale)] + "." + digits[len(digits)-int(scale):]
+	}
+	if v.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+func decimalError(s string, err error) error {
+	return &strconv.NumError{Func: "ParseDecimal", Num: s, Err: err}
+}
@@ -0,0 +1,217 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+var numberInputs = []string{
+	"0", "-0", "+0", "7", "007", "-12", "+12", "127", "128", "-128", "-129", "255", "256",
+	"32767", "-32768", "65535", "2147483647", "-2147483648", "4294967295", "4294967296",
+	"9223372036854775807", "-9223372036854775808", "9223372036854775808", "18446744073709551615",
+	"18446744073709551616", "99999999999999999999",
+	"1.5", "-1.5", ".5", "5.", "0.1", "0.3", "3.14159", "-2.718281828459045", "123456.789",
+	"9007199254740993", "0.000000000000000000001", "16777217", "1.0000001", "1e3", "1E-3", "-2.5e+10",
+	"", "-", "+", ".", "1.2.3", "1_000", "0x10", "NaN", "inf", "-Inf", "abc", " 1", "1 ",
+}
+
+func TestParseIntMatchesStrconv(t *testing.T) {
+	for _, s := range numberInputs {
+		for _, bitSize := range []int{8, 16, 32, 64} {
+			got, gotErr := parseInt(s, bitSize)
+			want, wantErr := strconv.ParseInt(s, 10, bitSize)
+			if got != want || fmt.Sprint(gotErr) != fmt.Sprint(wantErr) {
+				t.Errorf("parseInt(%q, %d) = %d, %v, want %d, %v", s, bitSize, got, gotErr, want, wantErr)
+			}
+		}
+	}
+}
+
+func TestParseUintMatchesStrconv(t *testing.T) {
+	for _, s := range numberInputs {
+		for _, bitSize := range []int{8, 16, 32, 64} {
+			got, gotErr := parseUint(s, bitSize)
+			want, wantErr := strconv.ParseUint(s, 10, bitSize)
+			if got != want || fmt.Sprint(gotErr) != fmt.Sprint(wantErr) {
+				t.Errorf("parseUint(%q, %d) = %d, %v, want %d, %v", s, bitSize, got, gotErr, want, wantErr)
+			}
+		}
+	}
+}
+
+func TestParseFloatMatchesStrconv(t *testing.T) {
+	inputs := append([]string(nil), numberInputs...)
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		inputs = append(inputs, strconv.FormatFloat(rng.NormFloat64()*math.Pow10(rng.Intn(12)-4), 'f', rng.Intn(10), 64))
+	}
+	for _, s := range inputs {
+		for _, bitSize := range []int{32, 64} {
+			got, gotErr := parseFloat(s, bitSize)
+			want, wantErr := strconv.ParseFloat(s, bitSize)
+			same := got == want && math.Signbit(got) == math.Signbit(want) || math.IsNaN(got) && math.IsNaN(want)
+			if !same || fmt.Sprint(gotErr) != fmt.Sprint(wantErr) {
+				t.Errorf("parseFloat(%q, %d) = %v, %v, want %v, %v", s, bitSize, got, gotErr, want, wantErr)
+			}
+		}
+	}
+}
+
+func TestParseDecimal128(t *testing.T) {
+	for _, tc := range []struct {
+		s                string
+		precision, scale int32
+		want             string
+		err              string
+	}{
+		{s: "0", precision: 5, scale: 2, want: "0.00"},
+		{s: "-0", precision: 5, scale: 2, want: "0.00"},
+		{s: "12.5", precision: 5, scale: 2, want: "12.50"},
+		{s: "-12.5", precision: 5, scale: 2, want: "-12.50"},
+		{s: "+.05", precision: 5, scale: 2, want: "0.05"},
+		{s: "-.05", precision: 5, scale: 2, want: "-0.05"},
+		{s: "1.2300", precision: 5, scale: 2, want: "1.23"},
+		{s: "00042", precision: 2, scale: 0, want: "42"},
+		{s: "999.99", precision: 5, scale: 2, want: "999.99"},
+		{s: "12345678901234567890123456789012345678", precision: 38, scale: 0, want: "12345678901234567890123456789012345678"},
+		{s: "-1234567890123456789012345678901234567.8", precision: 38, scale: 1, want: "-1234567890123456789012345678901234567.8"},
+		{s: "1000", precision: 5, scale: 2, err: "value out of range"},
+		{s: "1.234", precision: 5, scale: 2, err: "value out of range"},
+		{s: "", precision: 5, scale: 2, err: "invalid syntax"},
+		{s: ".", precision: 5, scale: 2, err: "invalid syntax"},
+		{s: "1.2.3", precision: 5, scale: 2, err: "invalid syntax"},
+		{s: "1e3", precision: 5, scale: 2, err: "invalid syntax"},
+	} {
+		got, err := parseDecimal128(tc.s, tc.precision, tc.scale)
+		switch {
+		case tc.err != "":
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("parseDecimal128(%q) error = %v, want %q", tc.s, err, tc.err)
+			}
+		case err != nil:
+			t.Errorf("parseDecimal128(%q): %v", tc.s, err)
+		case formatDecimal128(got, tc.scale) != tc.want:
+			t.Errorf("parseDecimal128(%q) = %s, want %s", tc.s, formatDecimal128(got, tc.scale), tc.want)
+		}
+	}
+}
+
+func TestReaderDecimal(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "amount", Type: &arrow.Decimal128Type{Precision: 10, Scale: 2}, Nullable: true},
+	}, nil)
+	data := "amount\n1.5\n-0.25\nNA\n123.456\n"
+	r, err := NewReader(strings.NewReader(data), WithAllocator(pool), WithSchema(schema), WithNullValues("NA"), OnError(Null))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+
+	checkStrings(t, readAll(t, r), []string{"amount: [{150 0} {18446744073709551591 -1} (null) (null)]"})
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// numberColumn returns n fields looking like the numbers of a CSV export.
+func numberColumn(n int, float bool) []string {
+	rng := rand.New(rand.NewSource(1))
+	out := make([]string, n)
+	for i := range out {
+		if float {
+			out[i] = strconv.FormatFloat(rng.Float64()*1e6, 'f', 2, 64)
+		} else {
+			out[i] = strconv.FormatInt(rng.Int63n(1e9)-5e8, 10)
+		}
+	}
+	return out
+}
+
+func BenchmarkParseInt(b *testing.B) {
+	fields := numberColumn(1024, false)
+	b.Run("strconv", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = strconv.ParseInt(fields[i%len(fields)], 10, 64)
+		}
+	})
+	b.Run("fast", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = parseInt(fields[i%len(fields)], 64)
+		}
+	})
+}
+
+func BenchmarkParseFloat(b *testing.B) {
+	fields := numberColumn(1024, true)
+	b.Run("strconv", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = strconv.ParseFloat(fields[i%len(fields)], 64)
+		}
+	})
+	b.Run("fast", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = parseFloat(fields[i%len(fields)], 64)
+		}
+	})
+}
+
+func BenchmarkParseDecimal128(b *testing.B) {
+	fields := numberColumn(1024, true)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = parseDecimal128(fields[i%len(fields)], 18, 2)
+	}
+}
+
+func BenchmarkReaderNumbers(b *testing.B) {
+	ints, floats := numberColumn(4096, false), numberColumn(4096, true)
+	var sb strings.Builder
+	sb.WriteString("id,price\n")
+	for i := range ints {
+		sb.WriteString(ints[i] + "," + floats[i] + "\n")
+	}
+	data := sb.String()
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r, err := NewReader(strings.NewReader(data), WithChunk(1024))
+		if err != nil {
+			b.Fatal(err)
+		}
+		for r.Next() {
+		}
+		if err := r.Err(); err != nil {
+			b.Fatal(err)
+		}
+		r.Release()
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/pushdown"
+)
+
+// Option is an option that may be passed to NewReader and NewWriter.
+type Option func(interface{}) error
+
+// ErrorPolicy selects what the Reader does with a row that cannot be parsed.
+type ErrorPolicy int
+
+const (
+	// Fail stops reading and reports the error. It is the default.
+	Fail ErrorPolicy = iota
+	// Skip drops the row.
+	Skip
+	// Null replaces the fields that cannot be parsed with nulls.
+	// Missing fields are null and extra fields are ignored.
+	Null
+)
+
+func (p ErrorPolicy) String() string {
+	switch p {
+	case Fail:
+		return "fail"
+	case Skip:
+		return "skip"
+	case Null:
+		return "null"
+	default:
+		return fmt.Sprintf("ErrorPolicy(%d)", int(p))
+	}
+}
+
+type config struct {
+	mem        memory.Allocator
+	comma      rune
+	comment    rune
+	header     bool
+	schema     *arrow.Schema
+	chunk      int
+	sample     int
+	columns    []string
+	policy     ErrorPolicy
+	nullValues []string
+	pushdown   *pushdown.ReadOptions
+	utf8       array.UTF8Policy
+}
+
+func newConfig(opts ...Option) (*config, error) {
+	cfg := &config{
+		mem:        memory.NewGoAllocator(),
+		comma:      ',',
+		header:     true,
+		chunk:      1024,
+		sample:     1000,
+		policy:     Fail,
+		nullValues: []string{""},
+	}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// option returns an Option applying fn to the reader configuration.
+func option(name string, fn func(cfg *config) error) Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply %s to: %T", name, p)
+		}
+		return fn(cfg)
+	}
+}
+
+// WithAllocator specifies the allocator used to build the records.
+func WithAllocator(mem memory.Allocator) Option {
+	return option("WithAllocator", func(cfg *config) error {
+		cfg.mem = mem
+		return nil
+	})
+}
+
+// WithComma specifies the field delimiter, ',' by default.
+func WithComma(comma rune) Option {
+	return option("WithComma", func(cfg *config) error {
+		cfg.comma = comma
+		return nil
+	})
+}
+
+// WithComment specifies the character starting comment lines. There are none by default.
+func WithComment(comment rune) Option {
+	return option("WithComment", func(cfg *config) error {
+		cfg.comment = comment
+		return nil
+	})
+}
+
+// WithHeader specifies whether the first row holds the column names, true by default.
+// Without a header the columns are named f0, f1, ... unless a schema is provided.
+func WithHeader(header bool) Option {
+	return option("WithHeader", func(cfg *config) error {
+		cfg.header = header
+		return nil
+	})
+}
+
+// WithSchema specifies the schema of the rows, one field per CSV column,
+// instead of inferring it.
+func WithSchema(schema *arrow.Schema) Option {
+	return option("WithSchema", func(cfg *config) error {
+		cfg.schema = schema
+		return nil
+	})
+}
+
+// WithChunk specifies the maximum number of rows of each record, 1024 by default.
+func WithChunk(n int) Option {
+	return option("WithChunk", func(cfg *config) error {
+		if n <= 0 {
+			return fmt.Errorf("csv: chunk size must be > 0, got %d", n)
+		}
+		cfg.chunk = n
+		return nil
+	})
+}
+
+// WithSampleRows specifies the maximum number of rows read to infer the schema, 1000 by default.
+func WithSampleRows(n int) Option {
+	return option("WithSampleRows", func(cfg *config) error {
+		if n <= 0 {
+			return fmt.Errorf("csv: sample size must be > 0, got %d", n)
+		}
+		cfg.sample = n
+		return nil
+	})
+}
+
+// WithColumns restricts the records to the named columns, in the given order.
+// The other columns are not parsed.
+func WithColumns(names ...string) Option {
+	return option("WithColumns", func(cfg *config) error {
+		cfg.columns = names
+		return nil
+	})
+}
+
+// WithReadOptions restricts the records to the columns and the rows of ro.
+// Only the columns projected or read by the filter are parsed, and the
+// records hold the rows selected by the filter; the chunks without selected
+// rows are not returned. It replaces WithColumns.
+func WithReadOptions(ro pushdown.ReadOptions) Option {
+	return option("WithReadOptions", func(cfg *config) error {
+		cfg.columns = ro.ReadColumns()
+		cfg.pushdown = &ro
+		return nil
+	})
+}
+
+// OnError specifies the policy applied to rows that cannot be parsed.
+func OnError(policy ErrorPolicy) Option {
+	return option("OnError", func(cfg *config) error {
+		cfg.policy = policy
+		return nil
+	})
+}
+
+// WithNullValues specifies the field values read as null, the empty string by default.
+func WithNullValues(values ...string) Option {
+	return option("WithNullValues", func(cfg *config) error {
+		cfg.nullValues = values
+		return nil
+	})
+}
+
+// WithUTF8Validation checks that the fields of string columns are valid UTF-8,
+// applying policy to the fields that are not: array.UTF8Replace replaces their
+// invalid sequences with U+FFFD and array.UTF8Error makes them fail to parse,
+// so that their rows are handled according to the ErrorPolicy.
+func WithUTF8Validation(policy array.UTF8Policy) Option {
+	return option("WithUTF8Validation", func(cfg *config) error {
+		cfg.utf8 = policy
+		return nil
+	})
+}
@@ -0,0 +1,364 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/internal/debug"
+	"github.com/gomem/gomem/pkg/pushdown"
+)
+
+// Reader reads the rows of a CSV input as a stream of records of at most
+// WithChunk rows each. Only the sampled rows used to infer the schema and
+// the current record are held in memory.
+type Reader struct {
+	refs int64
+
+	cfg    *config
+	r      *csv.Reader
+	closer io.Closer     // closed on release, if any
+	schema *arrow.Schema // schema of the records returned
+	parsed *arrow.Schema // schema of the parsed columns
+	bldr   *array.RecordBuilder
+	cols   []*column
+
+	nfields int        // number of fields of a well-formed row
+	pending [][]string // rows read while sampling and not returned yet
+	row     int        // number of data rows read so far
+	done    bool
+
+	rec array.Record
+	err error
+}
+
+// NewReader returns a Reader of the CSV rows of r.
+func NewReader(r io.Reader, opts ...Option) (*Reader, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	cr := csv.NewReader(r)
+	cr.Comma = cfg.comma
+	cr.Comment = cfg.comment
+	cr.FieldsPerRecord = -1 // the field count is checked against the error policy
+	cr.ReuseRecord = true
+
+	rr := &Reader{refs: 1, cfg: cfg, r: cr}
+	if err := rr.init(); err != nil {
+		return nil, err
+	}
+	return rr, nil
+}
+
+// init reads the header and the sample, and prepares the builders.
+func (r *Reader) init() error {
+	var names []string
+	if r.cfg.header {
+		header, err := r.r.Read()
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("csv: could not read header: %w", err)
+		}
+		names = append(names, header...)
+	}
+
+	var fields []arrow.Field
+	switch {
+	case r.cfg.schema != nil:
+		fields = r.cfg.schema.Fields()
+		if names != nil && len(names) != len(fields) {
+			return fmt.Errorf("csv: header has %d columns, schema has %d fields", len(names), len(fields))
+		}
+	default:
+		if err := r.sample(); err != nil {
+			return err
+		}
+		if names == nil && len(r.pending) > 0 {
+			for i := range r.pending[0] {
+				names = append(names, fmt.Sprintf("f%d", i))
+			}
+		}
+		fields = make([]arrow.Field, len(names))
+		for i, name := range names {
+			fields[i] = arrow.Field{Name: name, Nullable: true}
+		}
+	}
+	r.nfields = len(fields)
+
+	// Project the columns.
+	indices := make([]int, 0, len(fields))
+	if r.cfg.columns == nil {
+		for i := range fields {
+			indices = append(indices, i)
+		}
+	}
+	for _, name := range r.cfg.columns {
+		found := false
+		for i, f := range fields {
+			if f.Name == name {
+				indices = append(indices, i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("csv: unknown column %q", name)
+		}
+	}
+
+	projected := make([]arrow.Field, len(indices))
+	for i, index := range indices {
+		projected[i] = fields[index]
+	}
+	if r.cfg.schema == nil {
+		for i, dtype := range inferTypes(r.pending, indices, r.isNull) {
+			projected[i].Type = dtype
+		}
+	}
+
+	r.parsed = arrow.NewSchema(projected, nil)
+	r.schema = r.parsed
+	if ro := r.cfg.pushdown; ro != nil {
+		// The columns only read by the filter are dropped after filtering.
+		schema, err := ro.Schema(r.parsed)
+		if err != nil {
+			return fmt.Errorf("csv: %w", err)
+		}
+		r.schema = schema
+	}
+	r.bldr = array.NewRecordBuilder(r.cfg.mem, r.parsed)
+	r.cols = make([]*column, len(indices))
+	for i, index := range indices {
+		col, err := newColumn(index, projected[i], r.bldr.Field(i), r.cfg.utf8)
+		if err != nil {
+			r.bldr.Release()
+			return err
+		}
+		r.cols[i] = col
+	}
+	return nil
+}
+
+// sample reads the rows used to infer the schema.
+func (r *Reader) sample() error {
+	for len(r.pending) < r.cfg.sample {
+		row, err := r.r.Read()
+		switch {
+		case err == io.EOF:
+			return nil
+		case err != nil:
+			if r.cfg.policy == Fail {
+				return fmt.Errorf("csv: could not read row %d: %w", len(r.pending)+1, err)
+			}
+			continue
+		}
+		r.pending = append(r.pending, append([]string(nil), row...))
+	}
+	return nil
+}
+
+// Retain increases the reference count by 1.
+// Retain may be called simultaneously from multiple goroutines.
+func (r *Reader) Retain() {
+	atomic.AddInt64(&r.refs, 1)
+}
+
+// Release decreases the reference count by 1.
+// When the reference count goes to zero, the memory is freed.
+// Release may be called simultaneously from multiple goroutines.
+func (r *Reader) Release() {
+	debug.Assert(atomic.LoadInt64(&r.refs) > 0, "too many releases")
+
+	if atomic.AddInt64(&r.refs, -1) == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+		r.bldr.Release()
+		if r.closer != nil {
+			r.closer.Close()
+		}
+	}
+}
+
+// Schema returns the schema of the records.
+func (r *Reader) Schema() *arrow.Schema { return r.schema }
+
+// Record returns the current record. It is valid until the next call to Next.
+func (r *Reader) Record() array.Record { return r.rec }
+
+// Err returns the error that stopped the Reader, if any.
+func (r *Reader) Err() error { return r.err }
+
+// Next reads the next record. It returns false at the end of the input or
+// when an error occurs, see Err.
+func (r *Reader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+	for r.err == nil && !r.done {
+		rec := r.readChunk()
+		if rec == nil {
+			return false
+		}
+		if r.cfg.pushdown == nil {
+			r.rec = rec
+			return true
+		}
+		filtered, err := pushdown.Apply(r.cfg.mem, rec, *r.cfg.pushdown)
+		rec.Release()
+		if err != nil {
+			r.err = fmt.Errorf("csv: %w", err)
+			return false
+		}
+		if filtered.NumRows() > 0 {
+			r.rec = filtered
+			return true
+		}
+		filtered.Release()
+	}
+	return false
+}
+
+// readChunk parses the next chunk of rows. It returns nil when there are no
+// rows left or when an error occurs.
+func (r *Reader) readChunk() array.Record {
+	n := 0
+	for n < r.cfg.chunk {
+		row, err := r.next()
+		if err == io.EOF {
+			r.done = true
+			break
+		}
+		r.row++
+		if err != nil {
+			// The decoder could not split the row in fields, it cannot be
+			// read with nulls either.
+			if r.cfg.policy == Fail {
+				r.err = fmt.Errorf("csv: could not read row %d: %w", r.row, err)
+				return nil
+			}
+			continue
+		}
+		ok, err := r.parse(row)
+		if err != nil {
+			r.err = err
+			return nil
+		}
+		if ok {
+			n++
+		}
+	}
+
+	if n == 0 {
+		return nil
+	}
+	return r.bldr.NewRecord()
+}
+
+// next returns the next row, replaying the sampled rows first.
+func (r *Reader) next() ([]string, error) {
+	if len(r.pending) > 0 {
+		row := r.pending[0]
+		r.pending[0] = nil
+		r.pending = r.pending[1:]
+		return row, nil
+	}
+	return r.r.Read()
+}
+
+// parse appends row to the builders. It returns false when the row is skipped.
+func (r *Reader) parse(row []string) (bool, error) {
+	if len(row) != r.nfields {
+		switch r.cfg.policy {
+		case Fail:
+			return false, fmt.Errorf("csv: row %d has %d fields, want %d", r.row, len(row), r.nfields)
+		case Skip:
+			return false, nil
+		}
+	}
+
+	for _, c := range r.cols {
+		if c.index >= len(row) || r.isNull(row[c.index]) {
+			c.null = true
+			continue
+		}
+		c.null = false
+		if err := c.parse(row[c.index]); err != nil {
+			switch r.cfg.policy {
+			case Fail:
+				return false, fmt.Errorf("csv: row %d, column %q: %w", r.row, c.name, err)
+			case Skip:
+				return false, nil
+			default:
+				c.null = true
+			}
+		}
+	}
+
+	for _, c := range r.cols {
+		c.commit()
+	}
+	return true, nil
+}
+
+func (r *Reader) isNull(s string) bool {
+	for _, v := range r.cfg.nullValues {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Source is a pushdown.Source reading a CSV file.
+type Source struct {
+	path string
+	opts []Option
+}
+
+// NewSource returns the Source of the CSV file at path, read with opts.
+func NewSource(path string, opts ...Option) *Source {
+	return &Source{path: path, opts: opts}
+}
+
+// Read returns a Reader of the file with ro applied.
+func (s *Source) Read(ro pushdown.ReadOptions) (array.RecordReader, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("csv: %w", err)
+	}
+	opts := append(append([]Option(nil), s.opts...), WithReadOptions(ro))
+	r, err := NewReader(f, opts...)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r.closer = f
+	return r, nil
+}
+
+var (
+	_ array.RecordReader = (*Reader)(nil)
+	_ pushdown.Source    = (*Source)(nil)
+)
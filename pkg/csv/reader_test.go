@@ -0,0 +1,229 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/pushdown"
+)
+
+// readAll returns the String of every column of every record read by r.
+func readAll(t *testing.T, r *Reader) []string {
+	t.Helper()
+	var got []string
+	for r.Next() {
+		rec := r.Record()
+		for i, col := range rec.Columns() {
+			got = append(got, fmt.Sprintf("%s: %v", rec.ColumnName(i), col))
+		}
+	}
+	return got
+}
+
+func checkStrings(t *testing.T, got, want []string) {
+	t.Helper()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("\ngot=\n%s\nwant=\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestReaderInferSchema(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	data := `id,price,ok,day,name
+1,1.5,true,2020-01-02,a
+2,,false,2020-01-03,b
+3,2,TRUE,,c
+`
+	r, err := NewReader(strings.NewReader(data), WithAllocator(pool), WithChunk(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+
+	want := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "price", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "ok", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+		{Name: "day", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	if !r.Schema().Equal(want) {
+		t.Fatalf("got schema=%v, want=%v", r.Schema(), want)
+	}
+
+	checkStrings(t, readAll(t, r), []string{
+		"id: [1 2]",
+		"price: [1.5 (null)]",
+		"ok: [true false]",
+		"day: [18263 18264]",
+		`name: ["a" "b"]`,
+		"id: [3]",
+		"price: [2]",
+		"ok: [true]",
+		"day: [(null)]",
+		`name: ["c"]`,
+	})
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReaderProjectionAndSampling(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	// The bad value of column a is outside of the sample and a is not projected.
+	data := "a;b\n1;x\n2;y\nnot-a-number;z\n"
+	r, err := NewReader(strings.NewReader(data), WithAllocator(pool), WithComma(';'), WithSampleRows(1), WithColumns("b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+
+	checkStrings(t, readAll(t, r), []string{`b: ["x" "y" "z"]`})
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewReader(strings.NewReader(data), WithColumns("c")); err == nil {
+		t.Fatal("expected an error projecting an unknown column")
+	}
+}
+
+func TestReaderErrorPolicy(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "a", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		{Name: "b", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+	}, nil)
+	data := "1,1.5\nx,2.5\n3\n4,4.5,extra\n5,5.5\n"
+
+	cases := []struct {
+		policy ErrorPolicy
+		want   []string
+		err    string
+	}{
+		{Fail, nil, `csv: row 2, column "a"`},
+		{Skip, []string{"a: [1 5]", "b: [1.5 5.5]"}, ""},
+		{Null, []string{"a: [1 (null) 3 4 5]", "b: [1.5 2.5 (null) 4.5 5.5]"}, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.policy.String(), func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(data), WithAllocator(pool), WithHeader(false), WithSchema(schema), OnError(c.policy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Release()
+
+			checkStrings(t, readAll(t, r), c.want)
+			switch {
+			case c.err == "" && r.Err() != nil:
+				t.Fatalf("unexpected error: %v", r.Err())
+			case c.err != "" && (r.Err() == nil || !strings.HasPrefix(r.Err().Error(), c.err)):
+				t.Fatalf("got error=%v, want=%s...", r.Err(), c.err)
+			}
+		})
+	}
+}
+
+func TestReaderUTF8Validation(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{{Name: "s", Type: arrow.BinaryTypes.String, Nullable: true}}, nil)
+	data := "ok\nb\xffd\n"
+
+	cases := []struct {
+		name string
+		opts []Option
+		want []string
+		err  string
+	}{
+		{"unchecked", nil, []string{`s: ["ok" "b\xffd"]`}, ""},
+		{"replace", []Option{WithUTF8Validation(array.UTF8Replace)}, []string{`s: ["ok" "b�d"]`}, ""},
+		{"error", []Option{WithUTF8Validation(array.UTF8Error)}, nil, `csv: row 2, column "s": invalid UTF-8`},
+		{"error/null", []Option{WithUTF8Validation(array.UTF8Error), OnError(Null)}, []string{`s: ["ok" (null)]`}, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := append([]Option{WithAllocator(pool), WithHeader(false), WithSchema(schema)}, c.opts...)
+			r, err := NewReader(strings.NewReader(data), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Release()
+
+			checkStrings(t, readAll(t, r), c.want)
+			switch {
+			case c.err == "" && r.Err() != nil:
+				t.Fatalf("unexpected error: %v", r.Err())
+			case c.err != "" && (r.Err() == nil || r.Err().Error() != c.err):
+				t.Fatalf("got error=%v, want=%s", r.Err(), c.err)
+			}
+		})
+	}
+}
+
+func TestReaderReadOptions(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	dir, err := ioutil.TempDir("", "gomem-csv-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "data.csv")
+	if err := ioutil.WriteFile(path, []byte("id,price,name\n1,1.5,a\n2,20,b\n3,2.5,c\n4,3,d\n5,40,e\n6,50,f\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := NewSource(path, WithAllocator(pool), WithChunk(2))
+	rr, err := src.Read(pushdown.ReadOptions{
+		Columns: []string{"name"},
+		Filter:  pushdown.Compare("price", pushdown.Greater, 10),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rr.Release()
+	r := rr.(*Reader)
+	if got, want := r.Schema().String(), "schema:\n  fields: 1\n    - name: type=utf8, nullable"; got != want {
+		t.Fatalf("got schema=%v, want=%v", got, want)
+	}
+
+	// The second chunk holds no selected row and is not returned.
+	checkStrings(t, readAll(t, r), []string{`name: ["b"]`, `name: ["e" "f"]`})
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := src.Read(pushdown.ReadOptions{Filter: pushdown.Compare("qty", pushdown.Equal, 1)}); err == nil {
+		t.Fatal("expected an error filtering an unknown column")
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+)
+
+// Writer writes records as CSV rows, in the format read back by Reader.
+// The header is written with the first record, or on Flush if there are none.
+type Writer struct {
+	cfg    *config
+	w      *csv.Writer
+	schema *arrow.Schema
+	header bool // whether the header is still to be written
+	row    []string
+}
+
+// NewWriter returns a Writer of the records of schema to w. It accepts the
+// WithComma, WithHeader and WithNullValues options; nulls are written as
+// the first null value.
+func NewWriter(w io.Writer, schema *arrow.Schema, opts ...Option) (*Writer, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range schema.Fields() {
+		if _, err := formatter(field.Type); err != nil {
+			return nil, fmt.Errorf("csv: unsupported column type %s for column %q", field.Type, field.Name)
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = cfg.comma
+	return &Writer{
+		cfg:    cfg,
+		w:      cw,
+		schema: schema,
+		header: cfg.header,
+		row:    make([]string, len(schema.Fields())),
+	}, nil
+}
+
+// Write writes the rows of rec.
+func (w *Writer) Write(rec array.Record) error {
+	if !rec.Schema().Equal(w.schema) {
+		return fmt.Errorf("csv: record schema %v does not match writer schema %v", rec.Schema(), w.schema)
+	}
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	null := ""
+	if len(w.cfg.nullValues) > 0 {
+		null = w.cfg.nullValues[0]
+	}
+	cols := rec.Columns()
+	formats := make([]func(arr array.Interface, i int) string, len(cols))
+	for j, col := range cols {
+		formats[j], _ = formatter(col.DataType())
+	}
+
+	for i := 0; i < int(rec.NumRows()); i++ {
+		for j, col := range cols {
+			if col.IsNull(i) {
+				w.row[j] = null
+			} else {
+				w.row[j] = formats[j](col, i)
+			}
+		}
+		if err := w.w.Write(w.row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes the buffered rows to the underlying writer.
+func (w *Writer) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *Writer) writeHeader() error {
+	if !w.header {
+		return nil
+	}
+	w.header = false
+	for j, field := range w.schema.Fields() {
+		w.row[j] = field.Name
+	}
+	return w.w.Write(w.row)
+}
+
+// formatter returns the function formatting the valid values of arrays of dtype.
+func formatter(dtype arrow.DataType) (func(arr array.Interface, i int) string, error) {
+	switch dtype := dtype.(type) {
+	case *arrow.BooleanType:
+		return func(arr array.Interface, i int) string { return strconv.FormatBool(arr.(*array.Boolean).Value(i)) }, nil
+	case *arrow.Int8Type:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatInt(int64(arr.(*array.Int8).Value(i)), 10)
+		}, nil
+	case *arrow.Int16Type:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatInt(int64(arr.(*array.Int16).Value(i)), 10)
+		}, nil
+	case *arrow.Int32Type:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatInt(int64(arr.(*array.Int32).Value(i)), 10)
+		}, nil
+	case *arrow.Int64Type:
+		return func(arr array.Interface, i int) string { return strconv.FormatInt(arr.(*array.Int64).Value(i), 10) }, nil
+	case *arrow.Uint8Type:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatUint(uint64(arr.(*array.Uint8).Value(i)), 10)
+		}, nil
+	case *arrow.Uint16Type:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatUint(uint64(arr.(*array.Uint16).Value(i)), 10)
+		}, nil
+	case *arrow.Uint32Type:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatUint(uint64(arr.(*array.Uint32).Value(i)), 10)
+		}, nil
+	case *arrow.Uint64Type:
+		return func(arr array.Interface, i int) string { return strconv.FormatUint(arr.(*array.Uint64).Value(i), 10) }, nil
+	case *arrow.Float16Type:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatFloat(float64(arr.(*array.Float16).Value(i).Float32()), 'g', -1, 32)
+		}, nil
+	case *arrow.Float32Type:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatFloat(float64(arr.(*array.Float32).Value(i)), 'g', -1, 32)
+		}, nil
+	case *arrow.Float64Type:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatFloat(arr.(*array.Float64).Value(i), 'g', -1, 64)
+		}, nil
+	case *arrow.Decimal128Type:
+		return func(arr array.Interface, i int) string {
+			return formatDecimal128(arr.(*array.Decimal128).Value(i), dtype.Scale)
+		}, nil
+	case *arrow.StringType:
+		return func(arr array.Interface, i int) string { return arr.(*array.String).Value(i) }, nil
+	case *arrow.BinaryType:
+		return func(arr array.Interface, i int) string { return string(arr.(*array.Binary).Value(i)) }, nil
+	case *arrow.Date32Type:
+		return func(arr array.Interface, i int) string {
+			return time.Unix(int64(arr.(*array.Date32).Value(i))*86400, 0).UTC().Format(dateLayout)
+		}, nil
+	case *arrow.TimestampType:
+		scale := timestampScale(dtype.Unit)
+		return func(arr array.Interface, i int) string {
+			return time.Unix(0, int64(arr.(*array.Timestamp).Value(i))*scale).UTC().Format(time.RFC3339Nano)
+		}, nil
+	default:
+		return nil, fmt.Errorf("csv: unsupported type %s", dtype)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestWriterRoundTrip(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	data := `id;price;ok;day;name
+1;1.5;true;2020-01-02;a
+2;NA;false;2020-01-03;"b;c"
+3;2;true;NA;NA
+`
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "price", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "ok", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+		{Name: "day", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	opts := []Option{WithAllocator(pool), WithComma(';'), WithNullValues("NA"), WithSchema(schema), WithChunk(2)}
+
+	r, err := NewReader(strings.NewReader(data), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, r.Schema(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for r.Next() {
+		if err := w.Write(r.Record()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != data {
+		t.Fatalf("\ngot=\n%s\nwant=\n%s", got, data)
+	}
+
+	buf.Reset()
+	w, err = NewWriter(&buf, schema, WithHeader(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("unexpected output without header and records: %q", buf.String())
+	}
+
+	nested := arrow.NewSchema([]arrow.Field{{Name: "l", Type: arrow.ListOf(arrow.PrimitiveTypes.Int64)}}, nil)
+	if _, err := NewWriter(&buf, nested); err == nil {
+		t.Fatal("expected an error for an unsupported column type")
+	}
+}
@@ -0,0 +1,476 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// ArithOp is an arithmetic operator applied by Arithmetic.
+type ArithOp int
+
+const (
+	// Add adds the values.
+	Add ArithOp = iota
+	// Sub subtracts the right values from the left values.
+	Sub
+	// Mul multiplies the values.
+	Mul
+	// Div divides the left values by the right values.
+	Div
+)
+
+func (op ArithOp) String() string {
+	switch op {
+	case Add:
+		return "+"
+	case Sub:
+		return "-"
+	case Mul:
+		return "*"
+	case Div:
+		return "/"
+	default:
+		return fmt.Sprintf("ArithOp(%d)", int(op))
+	}
+}
+
+// AlignJoin selects the keys kept when aligning two DataFrames on their index.
+type AlignJoin int
+
+const (
+	// AlignOuter keeps the keys found on either side.
+	AlignOuter AlignJoin = iota
+	// AlignInner keeps the keys found on both sides.
+	AlignInner
+	// AlignLeft keeps the keys of the left side.
+	AlignLeft
+)
+
+// alignConfig are the config params for Arithmetic.
+type alignConfig struct {
+	how AlignJoin
+}
+
+// WithAlignJoin configures the keys Arithmetic computes values for, the keys
+// found on either side by default.
+func WithAlignJoin(how AlignJoin) Option {
+	return func(p interface{}) error {
+		o, ok := p.(*alignConfig)
+		if !ok {
+			return fmt.Errorf("cannot apply WithAlignJoin to: %T", p)
+		}
+		o.how = how
+		return nil
+	}
+}
+
+// Align returns the left and the right DataFrames with the same rows, aligned
+// on their index keys and selected by how. Both DataFrames must have an index
+// of the same kind on columns of the same types, and hold each key at most
+// once.
+//
+// The rows are in the order of the keys for an OrderedIndex. Otherwise they
+// hold the keys of the left rows in their order followed by the keys found on
+// the right only. A DataFrame holds nulls in the columns other than the index
+// for the keys it does not have. Both DataFrames are indexed like the
+// DataFrames they come from.
+func (df *DataFrame) Align(right *DataFrame, how AlignJoin) (*DataFrame, *DataFrame, error) {
+	m := df.mutator
+	leftRows, rightRows, err := alignRows(df, right, how)
+	if err != nil {
+		return nil, nil, err
+	}
+	l, err := m.alignSide(df, right, leftRows, rightRows)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := m.alignSide(right, df, rightRows, leftRows)
+	if err != nil {
+		l.Release()
+		return nil, nil, err
+	}
+	return l, r, nil
+}
+
+// Arithmetic applies op to the values of the left and the right DataFrames,
+// aligned on their index keys like Align does with the keys selected by
+// WithAlignJoin.
+//
+// The DataFrame holds the index columns followed by the columns other than
+// the index found on both sides, in the left order, whose values are computed
+// as float64 numbers. A value is null when its key is missing on a side,
+// either operand is null or it divides by zero. The DataFrame is indexed like
+// the left DataFrame.
+func (m *Mutator) Arithmetic(op ArithOp, rightDf *DataFrame, opts ...Option) MutationFunc {
+	cfg := &alignConfig{how: AlignOuter}
+	var err error
+	for _, opt := range opts {
+		if err = opt(cfg); err != nil {
+			break
+		}
+	}
+	return func(leftDf *DataFrame) (*DataFrame, error) {
+		if err != nil {
+			return nil, err
+		}
+		leftRows, rightRows, err := alignRows(leftDf, rightDf, cfg.how)
+		if err != nil {
+			return nil, err
+		}
+		left, right := leftDf.Index(), rightDf.Index()
+
+		cols, err := m.gatherKeys(leftDf, rightDf, leftRows, rightRows)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			for i := range cols {
+				cols[i].Release()
+			}
+		}()
+
+		isKey := make(map[string]bool)
+		for _, name := range append(left.Columns(), right.Columns()...) {
+			isKey[name] = true
+		}
+		for _, lcol := range leftDf.Columns() {
+			rcol := rightDf.Column(lcol.Name())
+			if isKey[lcol.Name()] || rcol == nil {
+				continue
+			}
+			col, err := arithmetic(m.mem, op, &lcol, rcol, leftRows, rightRows)
+			if err != nil {
+				return nil, err
+			}
+			cols = append(cols, *col)
+		}
+
+		out, err := NewDataFrameFromShape(m.mem, cols, int64(len(leftRows)))
+		if err != nil {
+			return nil, err
+		}
+		return reindex(out, left)
+	}
+}
+
+// alignRows returns the rows of each side holding the key of every row of the
+// alignment of two DataFrames, -1 when a side does not have the key. Null keys
+// match no other key.
+func alignRows(leftDf, rightDf *DataFrame, how AlignJoin) (leftRows, rightRows []int64, err error) {
+	left, right := leftDf.Index(), rightDf.Index()
+	if left == nil || right == nil {
+		return nil, nil, fmt.Errorf("mutation: cannot align DataFrames without index")
+	}
+	if fmt.Sprintf("%T", left) != fmt.Sprintf("%T", right) || len(left.Columns()) != len(right.Columns()) {
+		return nil, nil, fmt.Errorf("mutation: cannot align a %T with a %T", left, right)
+	}
+	for i, name := range right.Columns() {
+		l, r := leftDf.Column(left.Columns()[i]), rightDf.Column(name)
+		if !arrow.TypeEqual(l.DataType(), r.DataType()) {
+			return nil, nil, fmt.Errorf("mutation: cannot align index column %q of type %s with %q of type %s", l.Name(), l.DataType(), name, r.DataType())
+		}
+	}
+
+	seen := make(map[string]int, left.Len())
+	for i := int64(0); i < left.Len(); i++ {
+		if left.key(i) == nil {
+			leftRows = append(leftRows, i)
+			rightRows = append(rightRows, -1)
+			continue
+		}
+		k := alignKey(left.key(i))
+		if _, dup := seen[k]; dup {
+			return nil, nil, fmt.Errorf("mutation: duplicate index key %v", left.key(i))
+		}
+		seen[k] = len(leftRows)
+		leftRows = append(leftRows, i)
+		rightRows = append(rightRows, -1)
+	}
+	matched := make(map[string]struct{}, right.Len())
+	for i := int64(0); i < right.Len(); i++ {
+		if right.key(i) != nil {
+			k := alignKey(right.key(i))
+			if _, dup := matched[k]; dup {
+				return nil, nil, fmt.Errorf("mutation: duplicate index key %v", right.key(i))
+			}
+			matched[k] = struct{}{}
+			if row, ok := seen[k]; ok {
+				rightRows[row] = i
+				continue
+			}
+		}
+		leftRows = append(leftRows, -1)
+		rightRows = append(rightRows, i)
+	}
+
+	n := 0
+	for i := range leftRows {
+		switch {
+		case how == AlignInner && (leftRows[i] < 0 || rightRows[i] < 0):
+		case how == AlignLeft && leftRows[i] < 0:
+		default:
+			leftRows[n], rightRows[n] = leftRows[i], rightRows[i]
+			n++
+		}
+	}
+	leftRows, rightRows = leftRows[:n], rightRows[:n]
+
+	if ordered, ok := left.(OrderedIndex); ok {
+		key := func(row int) int64 {
+			if leftRows[row] >= 0 {
+				return orderedKey(ordered, leftRows[row])
+			}
+			return orderedKey(right.(OrderedIndex), rightRows[row])
+		}
+		perm := make([]int, len(leftRows))
+		for i := range perm {
+			perm[i] = i
+		}
+		sort.SliceStable(perm, func(i, j int) bool { return key(perm[i]) < key(perm[j]) })
+		l, r := make([]int64, len(perm)), make([]int64, len(perm))
+		for i, row := range perm {
+			l[i], r[i] = leftRows[row], rightRows[row]
+		}
+		leftRows, rightRows = l, r
+	}
+	return leftRows, rightRows, nil
+}
+
+// gatherKeys returns the index columns of the alignment of leftDf and rightDf,
+// named like the index columns of leftDf, gathered from both sides at once.
+func (m *Mutator) gatherKeys(leftDf, rightDf *DataFrame, leftRows, rightRows []int64) ([]array.Column, error) {
+	left, right := leftDf.Index(), rightDf.Index()
+	keyCols := left.Columns()
+
+	gather := make([]int64, len(leftRows))
+	for i := range gather {
+		if leftRows[i] >= 0 {
+			gather[i] = leftRows[i]
+		} else {
+			gather[i] = columnLen(*leftDf.Column(keyCols[0])) + rightRows[i]
+		}
+	}
+	indices := newIndices(m.mem, gather)
+	defer indices.Release()
+
+	cols := make([]array.Column, 0, len(keyCols))
+	for i, name := range keyCols {
+		l, r := leftDf.Column(name), rightDf.Column(right.Columns()[i])
+		chunks := append(append([]array.Interface(nil), l.Data().Chunks()...), r.Data().Chunks()...)
+		chunked := array.NewChunked(l.DataType(), chunks)
+		both := array.NewColumn(l.Field(), chunked)
+		chunked.Release()
+		taken, err := compute.Take(m.mem, both, indices)
+		both.Release()
+		if err != nil {
+			for j := range cols {
+				cols[j].Release()
+			}
+			return nil, err
+		}
+		cols = append(cols, *taken)
+	}
+	return cols, nil
+}
+
+// alignSide returns df with the rows of its alignment with other, rows and
+// otherRows being the rows of each side, in the order of the columns of df.
+func (m *Mutator) alignSide(df, other *DataFrame, rows, otherRows []int64) (*DataFrame, error) {
+	keys, err := m.gatherKeys(df, other, rows, otherRows)
+	if err != nil {
+		return nil, err
+	}
+	cols := make([]array.Column, 0, df.NumCols())
+	defer func() {
+		for i := range keys {
+			keys[i].Release()
+		}
+		for i := range cols {
+			cols[i].Release()
+		}
+	}()
+
+	indices := newNullableIndices(m.mem, rows)
+	defer indices.Release()
+	level := make(map[string]int, len(keys))
+	for i, name := range df.Index().Columns() {
+		level[name] = i
+	}
+	for i, col := range df.Columns() {
+		if l, ok := level[col.Name()]; ok {
+			keys[l].Retain()
+			cols = append(cols, keys[l])
+			continue
+		}
+		taken, err := compute.Take(m.mem, &df.Columns()[i], indices)
+		if err != nil {
+			return nil, err
+		}
+		field := col.Field()
+		field.Nullable = field.Nullable || indices.NullN() > 0
+		cols = append(cols, *array.NewColumn(field, taken.Data()))
+		taken.Release()
+	}
+
+	out, err := NewDataFrameFromShape(m.mem, cols, int64(len(rows)))
+	if err != nil {
+		return nil, err
+	}
+	return reindex(out, df.Index())
+}
+
+// arithmetic computes op on the rows of l and r, a negative row standing for
+// a null value.
+func arithmetic(mem memory.Allocator, op ArithOp, l, r *array.Column, leftRows, rightRows []int64) (*array.Column, error) {
+	lv, lvalid, err := floatValues(l)
+	if err != nil {
+		return nil, err
+	}
+	rv, rvalid, err := floatValues(r)
+	if err != nil {
+		return nil, err
+	}
+
+	bldr := array.NewFloat64Builder(mem)
+	defer bldr.Release()
+	for i := range leftRows {
+		li, ri := leftRows[i], rightRows[i]
+		if li < 0 || ri < 0 || !lvalid[li] || !rvalid[ri] {
+			bldr.AppendNull()
+			continue
+		}
+		a, b := lv[li], rv[ri]
+		switch op {
+		case Add:
+			bldr.Append(a + b)
+		case Sub:
+			bldr.Append(a - b)
+		case Mul:
+			bldr.Append(a * b)
+		case Div:
+			if b == 0 {
+				bldr.AppendNull()
+				continue
+			}
+			bldr.Append(a / b)
+		default:
+			return nil, fmt.Errorf("mutation: unknown operator %v", op)
+		}
+	}
+	arr := bldr.NewArray()
+	defer arr.Release()
+	chunked := array.NewChunked(arr.DataType(), []array.Interface{arr})
+	defer chunked.Release()
+	field := arrow.Field{Name: l.Name(), Type: arr.DataType(), Nullable: true}
+	return array.NewColumn(field, chunked), nil
+}
+
+// floatValues returns the values of a numeric column as float64 numbers, along
+// with their validity.
+func floatValues(col *array.Column) ([]float64, []bool, error) {
+	values := make([]float64, 0, columnLen(*col))
+	valid := make([]bool, 0, columnLen(*col))
+	for _, chunk := range col.Data().Chunks() {
+		var get func(i int) float64
+		switch a := chunk.(type) {
+		case *array.Float32:
+			get = func(i int) float64 { return float64(a.Value(i)) }
+		case *array.Float64:
+			get = a.Value
+		case *array.Int8, *array.Int16, *array.Int32, *array.Int64,
+			*array.Uint8, *array.Uint16, *array.Uint32, *array.Uint64:
+			get = func(i int) float64 {
+				if v, ok := intAt(a, i).(uint64); ok {
+					return float64(v)
+				}
+				k, _ := intKey(intAt(a, i))
+				return float64(k)
+			}
+		default:
+			return nil, nil, fmt.Errorf("mutation: column %q of type %s is not numeric", col.Name(), col.DataType())
+		}
+		for i := 0; i < chunk.Len(); i++ {
+			valid = append(valid, chunk.IsValid(i))
+			if chunk.IsValid(i) {
+				values = append(values, get(i))
+			} else {
+				values = append(values, 0)
+			}
+		}
+	}
+	return values, valid, nil
+}
+
+// alignKey encodes a non-null key returned by Index.key.
+func alignKey(key interface{}) string {
+	switch k := key.(type) {
+	case []interface{}:
+		norm := make([]interface{}, len(k))
+		for i, v := range k {
+			if t, ok := v.(time.Time); ok {
+				v = t.UnixNano()
+			}
+			norm[i] = v
+		}
+		return encodeKey(norm)
+	case time.Time:
+		return encodeKey([]interface{}{k.UnixNano()})
+	default:
+		return encodeKey([]interface{}{k})
+	}
+}
+
+// orderedKey returns the key of row i of an ordered index as an int64.
+func orderedKey(idx OrderedIndex, i int64) int64 {
+	switch idx := idx.(type) {
+	case *IntIndex:
+		return idx.keys[i]
+	case *TimestampIndex:
+		return idx.keys[i]
+	default:
+		return 0
+	}
+}
+
+// newIndices returns the indices as an Int64 array.
+func newIndices(mem memory.Allocator, indices []int64) *array.Int64 {
+	bldr := array.NewInt64Builder(mem)
+	defer bldr.Release()
+	bldr.AppendValues(indices, nil)
+	return bldr.NewInt64Array()
+}
+
+// newNullableIndices returns the indices as an Int64 array, the negative
+// indices being null.
+func newNullableIndices(mem memory.Allocator, indices []int64) *array.Int64 {
+	bldr := array.NewInt64Builder(mem)
+	defer bldr.Release()
+	for _, i := range indices {
+		if i < 0 {
+			bldr.AppendNull()
+		} else {
+			bldr.Append(i)
+		}
+	}
+	return bldr.NewInt64Array()
+}
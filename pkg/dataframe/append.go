@@ -0,0 +1,117 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/pkg/smartbuilder"
+)
+
+// Appender buffers rows to append to a DataFrame.
+// The rows are validated against the schema of the DataFrame as they are
+// appended, see smartbuilder.RecordBuilder.
+type Appender struct {
+	df        *DataFrame
+	bldr      *smartbuilder.RecordBuilder
+	rows      int64
+	committed bool
+}
+
+// BeginAppend returns an Appender of rows to df. df is not modified, Commit
+// returns a new DataFrame holding the rows of df followed by the appended rows.
+// The Appender must be released.
+func (df *DataFrame) BeginAppend() (*Appender, error) {
+	for _, f := range df.schema.Fields() {
+		if f.Type.ID() == arrow.DICTIONARY {
+			return nil, fmt.Errorf("dataframe: cannot append to dictionary column %q", f.Name)
+		}
+	}
+	bldr, err := smartbuilder.NewRecordBuilder(df.mem, df.schema)
+	if err != nil {
+		return nil, err
+	}
+	df.Retain()
+	return &Appender{df: df, bldr: bldr}, nil
+}
+
+// AppendRow appends a row holding one value per column, nil being null.
+func (a *Appender) AppendRow(values ...interface{}) error {
+	if a.committed {
+		return errors.New("dataframe: appender already committed")
+	}
+	if err := a.bldr.AppendRow(values...); err != nil {
+		return err
+	}
+	a.rows++
+	return nil
+}
+
+// NumRows returns the number of rows appended.
+func (a *Appender) NumRows() int64 { return a.rows }
+
+// Commit returns a new DataFrame with the existing chunks of each column
+// followed by the chunks of the appended rows. No data is copied.
+// The Appender cannot be used to append rows afterwards.
+func (a *Appender) Commit() (*DataFrame, error) {
+	if a.committed {
+		return nil, errors.New("dataframe: appender already committed")
+	}
+	a.committed = true
+
+	recs, err := a.bldr.NewRecords()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, rec := range recs {
+			rec.Release()
+		}
+	}()
+
+	df := a.df
+	dfCols := df.Columns()
+	cols := make([]array.Column, len(dfCols))
+	for i := range dfCols {
+		col := &dfCols[i]
+		if columnLen(*col) > df.NumRows() {
+			// only the rows of df come before the appended rows.
+			col = col.NewSlice(0, df.NumRows())
+			defer col.Release()
+		}
+		chunks := append([]array.Interface{}, col.Data().Chunks()...)
+		for _, rec := range recs {
+			chunks = append(chunks, rec.Column(i))
+		}
+		chunked := array.NewChunked(col.DataType(), chunks)
+		cols[i] = *array.NewColumn(col.Field(), chunked)
+		chunked.Release()
+	}
+	defer func() {
+		for i := range cols {
+			cols[i].Release()
+		}
+	}()
+	return NewDataFrameFromShape(df.mem, cols, df.NumRows()+a.rows)
+}
+
+// Release releases the buffered rows and the DataFrame appended to.
+func (a *Appender) Release() {
+	a.bldr.Release()
+	a.df.Release()
+}
@@ -0,0 +1,138 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// asofJoinConfig are the config params for AsofJoin.
+type asofJoinConfig struct {
+	leftJoinConfig
+	by        []string
+	direction compute.AsofDirection
+	tolerance float64
+}
+
+// newAsofJoinConfig creates a new config using options and validates it.
+func newAsofJoinConfig(opts ...Option) (*asofJoinConfig, error) {
+	cfg := &asofJoinConfig{
+		leftJoinConfig: *defaultLeftJoinConfig(),
+		direction:      compute.AsofBackward,
+		tolerance:      math.Inf(1),
+	}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return cfg, err
+		}
+	}
+	err := cfg.validate()
+	return cfg, err
+}
+
+// WithBy configures an as-of join to only match rows with equal values of the given columns.
+func WithBy(columnNames ...string) Option {
+	return func(p interface{}) error {
+		o, ok := p.(*asofJoinConfig)
+		if !ok {
+			return fmt.Errorf("cannot apply WithBy to: %T", p)
+		}
+		o.by = columnNames
+		return nil
+	}
+}
+
+// WithDirection configures the right rows an as-of join matches, backward by default.
+func WithDirection(direction compute.AsofDirection) Option {
+	return func(p interface{}) error {
+		o, ok := p.(*asofJoinConfig)
+		if !ok {
+			return fmt.Errorf("cannot apply WithDirection to: %T", p)
+		}
+		o.direction = direction
+		return nil
+	}
+}
+
+// WithTolerance configures the largest distance between the values an as-of join
+// matches, in the unit of the on columns such as the unit of a timestamp. There is
+// no limit by default.
+func WithTolerance(tolerance float64) Option {
+	return func(p interface{}) error {
+		o, ok := p.(*asofJoinConfig)
+		if !ok {
+			return fmt.Errorf("cannot apply WithTolerance to: %T", p)
+		}
+		if tolerance < 0 || math.IsNaN(tolerance) {
+			return fmt.Errorf("mutation: invalid as-of tolerance %v", tolerance)
+		}
+		o.tolerance = tolerance
+		return nil
+	}
+}
+
+// AsofJoin returns a DataFrame matching every row of the left DataFrame with the
+// right row holding the closest value of the on column, the latest one at or before
+// it by default. See compute.AsofJoin for the options. The DataFrame holds the left
+// columns followed by the right columns other than the on and by columns, which are
+// null for the left rows without a match. Names found on both sides are suffixed
+// like LeftJoin does.
+func (m *Mutator) AsofJoin(rightDf *DataFrame, on string, opts ...Option) MutationFunc {
+	cfg, err := newAsofJoinConfig(opts...)
+	return func(leftDf *DataFrame) (*DataFrame, error) {
+		if err != nil {
+			return nil, err
+		}
+
+		column := func(df *DataFrame, side, name string) (*array.Column, error) {
+			col := df.Column(name)
+			if col == nil {
+				return nil, fmt.Errorf("mutation: column %q is not in %s DataFrame: (%v)", name, side, df.ColumnNames())
+			}
+			return col, nil
+		}
+		leftOn, err := column(leftDf, "left", on)
+		if err != nil {
+			return nil, err
+		}
+		rightOn, err := column(rightDf, "right", on)
+		if err != nil {
+			return nil, err
+		}
+		leftBy := make([]*array.Column, len(cfg.by))
+		rightBy := make([]*array.Column, len(cfg.by))
+		for i, name := range cfg.by {
+			if leftBy[i], err = column(leftDf, "left", name); err != nil {
+				return nil, err
+			}
+			if rightBy[i], err = column(rightDf, "right", name); err != nil {
+				return nil, err
+			}
+		}
+
+		indices, err := compute.AsofJoin(m.mem, leftOn, rightOn, leftBy, rightBy, cfg.direction, cfg.tolerance)
+		if err != nil {
+			return nil, err
+		}
+		defer indices.Release()
+
+		drop := append([]string{on}, cfg.by...)
+		return m.gatherJoin(leftDf, rightDf, nil, indices, drop, &cfg.leftJoinConfig)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Catalog is a registry of named DataFrames, safe for concurrent use. It
+// holds a reference to every DataFrame registered, and Get hands out
+// references of their own to its callers, so a DataFrame replaced or dropped
+// while queries are using it is only freed once they release it.
+type Catalog struct {
+	mu  sync.RWMutex
+	dfs map[string]*DataFrame
+}
+
+// NewCatalog returns an empty Catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{dfs: make(map[string]*DataFrame)}
+}
+
+// Register registers df as name, retaining it. Registering a name twice is
+// an error, see Replace to swap the DataFrame of a name.
+func (c *Catalog) Register(name string, df *DataFrame) error {
+	if df == nil {
+		return errors.New("dataframe: cannot register a nil DataFrame")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.dfs[name]; ok {
+		return fmt.Errorf("dataframe: %q is already registered", name)
+	}
+	df.Retain()
+	c.dfs[name] = df
+	return nil
+}
+
+// Get returns the DataFrame registered as name, retained for the caller, who
+// must release it.
+func (c *Catalog) Get(name string) (*DataFrame, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	df, ok := c.dfs[name]
+	if ok {
+		df.Retain()
+	}
+	return df, ok
+}
+
+// Replace atomically makes df the DataFrame registered as name, retaining
+// it, and releases the reference the Catalog held to the DataFrame it
+// replaces, if any. Callers of Get keep theirs until they release them.
+func (c *Catalog) Replace(name string, df *DataFrame) error {
+	if df == nil {
+		return errors.New("dataframe: cannot register a nil DataFrame")
+	}
+	df.Retain()
+	c.mu.Lock()
+	old := c.dfs[name]
+	c.dfs[name] = df
+	c.mu.Unlock()
+	if old != nil {
+		old.Release()
+	}
+	return nil
+}
+
+// CompareAndSwap replaces the DataFrame registered as name by df, as Replace
+// does, only if it is still old. It reports whether it was replaced, which
+// lets concurrent writers refresh a DataFrame derived from the one they got.
+func (c *Catalog) CompareAndSwap(name string, old, df *DataFrame) (bool, error) {
+	if df == nil {
+		return false, errors.New("dataframe: cannot register a nil DataFrame")
+	}
+	c.mu.Lock()
+	if cur, ok := c.dfs[name]; !ok || cur != old {
+		c.mu.Unlock()
+		return false, nil
+	}
+	df.Retain()
+	c.dfs[name] = df
+	c.mu.Unlock()
+	old.Release()
+	return true, nil
+}
+
+// Drop removes the DataFrame registered as name, releasing the reference the
+// Catalog held to it. It reports whether name was registered.
+func (c *Catalog) Drop(name string) bool {
+	c.mu.Lock()
+	df, ok := c.dfs[name]
+	delete(c.dfs, name)
+	c.mu.Unlock()
+	if ok {
+		df.Release()
+	}
+	return ok
+}
+
+// Names returns the names registered, in sorted order.
+func (c *Catalog) Names() []string {
+	c.mu.RLock()
+	names := make([]string, 0, len(c.dfs))
+	for name := range c.dfs {
+		names = append(names, name)
+	}
+	c.mu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// Snapshot returns the DataFrames registered at a single point in time, each
+// retained for the caller, who must release them.
+func (c *Catalog) Snapshot() map[string]*DataFrame {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	dfs := make(map[string]*DataFrame, len(c.dfs))
+	for name, df := range c.dfs {
+		df.Retain()
+		dfs[name] = df
+	}
+	return dfs
+}
+
+// Close drops every DataFrame registered.
+func (c *Catalog) Close() {
+	c.mu.Lock()
+	dfs := c.dfs
+	c.dfs = make(map[string]*DataFrame)
+	c.mu.Unlock()
+	for _, df := range dfs {
+		df.Release()
+	}
+}
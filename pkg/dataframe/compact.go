@@ -0,0 +1,164 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// Compact creates a new DataFrame holding the rows of df in fewer, larger
+// chunks, which restores the speed of scans after many small appends. In
+// every column, runs of consecutive chunks of fewer than targetChunkSize rows
+// are merged into chunks of at most targetChunkSize rows, allocated from the
+// allocator of the Mutator, where a memory.PoolAllocator reuses the buffers
+// of the chunks released. Larger chunks are shared with df, and empty chunks
+// are dropped.
+func (m *Mutator) Compact(targetChunkSize int64) MutationFunc {
+	return func(df *DataFrame) (*DataFrame, error) {
+		if targetChunkSize <= 0 {
+			return nil, fmt.Errorf("mutation: invalid target chunk size %d", targetChunkSize)
+		}
+		cols := make([]array.Column, 0, df.NumCols())
+		defer func() {
+			for i := range cols {
+				cols[i].Release()
+			}
+		}()
+		for _, col := range df.Columns() {
+			compacted, err := compactColumn(m.mem, &col, targetChunkSize)
+			if err != nil {
+				return nil, err
+			}
+			cols = append(cols, *compacted)
+		}
+		return NewDataFrameFromShape(m.mem, cols, df.NumRows())
+	}
+}
+
+// compactColumn returns col with its runs of small chunks merged.
+func compactColumn(mem memory.Allocator, col *array.Column, target int64) (*array.Column, error) {
+	var chunks, run []array.Interface
+	defer func() {
+		for _, chunk := range chunks {
+			chunk.Release()
+		}
+	}()
+	var rows int64
+	flush := func() error {
+		switch len(run) {
+		case 0:
+		case 1:
+			run[0].Retain()
+			chunks = append(chunks, run[0])
+		default:
+			merged, err := mergeChunks(mem, col, run)
+			if err != nil {
+				return err
+			}
+			chunks = append(chunks, merged)
+		}
+		run, rows = run[:0], 0
+		return nil
+	}
+
+	for _, chunk := range col.Data().Chunks() {
+		n := int64(chunk.Len())
+		switch {
+		case n == 0:
+			continue
+		case n >= target:
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			chunk.Retain()
+			chunks = append(chunks, chunk)
+			continue
+		case rows+n > target:
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+		run = append(run, chunk)
+		rows += n
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	chunked := array.NewChunked(col.DataType(), chunks)
+	defer chunked.Release()
+	return array.NewColumn(col.Field(), chunked), nil
+}
+
+// mergeChunks copies the rows of chunks, chunks of col, into a single chunk.
+func mergeChunks(mem memory.Allocator, col *array.Column, chunks []array.Interface) (array.Interface, error) {
+	var n int64
+	for _, chunk := range chunks {
+		n += int64(chunk.Len())
+	}
+	rows := make([]int64, n)
+	for i := range rows {
+		rows[i] = int64(i)
+	}
+	indices := newIndices(mem, rows)
+	defer indices.Release()
+
+	chunked := array.NewChunked(col.DataType(), chunks)
+	defer chunked.Release()
+	run := array.NewColumn(col.Field(), chunked)
+	defer run.Release()
+
+	merged, err := compute.Take(mem, run, indices)
+	if err != nil {
+		return nil, err
+	}
+	defer merged.Release()
+	chunk := merged.Data().Chunk(0)
+	chunk.Retain()
+	return chunk, nil
+}
+
+// Compact compacts the DataFrame registered as name in a background
+// goroutine, see Mutator.Compact, and registers the compacted DataFrame in
+// its place once done, unless it was replaced in the meantime, in which case
+// the compacted DataFrame is discarded. Queries keep using the DataFrame they
+// got until they release it. The channel returned receives the error of the
+// compaction, nil on success, and is then closed.
+func (c *Catalog) Compact(name string, targetChunkSize int64) <-chan error {
+	errc := make(chan error, 1)
+	df, ok := c.Get(name)
+	if !ok {
+		errc <- fmt.Errorf("dataframe: %q is not registered", name)
+		close(errc)
+		return errc
+	}
+	go func() {
+		defer close(errc)
+		defer df.Release()
+		compacted, err := df.Compact(targetChunkSize)
+		if err != nil {
+			errc <- err
+			return
+		}
+		defer compacted.Release()
+		_, err = c.CompareAndSwap(name, df, compacted)
+		errc <- err
+	}()
+	return errc
+}
@@ -15,8 +15,8 @@
 package dataframe
 
 import (
-	"github.com/apache/arrow/go/arrow/array"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 	"github.com/gomem/gomem/internal/cast"
 	"github.com/gomem/gomem/internal/constructors"
 )
@@ -20,12 +20,15 @@ import (
 	"sort"
 	"strings"
 	"sync/atomic"
+	"time"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/array"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
 	"github.com/gomem/gomem/internal/constructors"
 	"github.com/gomem/gomem/internal/debug"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/expr"
 	"github.com/gomem/gomem/pkg/iterator"
 	"github.com/gomem/gomem/pkg/smartbuilder"
 )
@@ -84,6 +87,7 @@ func NewDataFrame(mem memory.Allocator, schema *arrow.Schema, arrs []array.Inter
 			df.cols[i] = *col
 		}(i)
 	}
+	df.trackRefs()
 
 	return df, nil
 }
@@ -168,6 +172,7 @@ func NewDataFrameFromShape(mem memory.Allocator, cols []array.Column, rows int64
 	for i := range df.cols {
 		df.cols[i].Retain()
 	}
+	df.trackRefs()
 
 	return df, nil
 }
@@ -196,6 +201,10 @@ type DataFrame struct {
 	cols []array.Column
 	rows int64
 
+	// index locates the rows by the values of a column, nil when the
+	// DataFrame has none. See SetIndex.
+	index Index
+
 	// Mutations that can be performed on this DataFrame
 	// require a the Mutator to be set up.
 	mutator *Mutator
@@ -437,7 +446,12 @@ func (df *DataFrame) Copy() (*DataFrame, error) {
 	nCols := len(df.cols)
 	cols := make([]array.Column, nCols)
 	copy(cols, df.cols)
-	return NewDataFrameFromShape(df.mem, cols, df.rows)
+	out, err := NewDataFrameFromShape(df.mem, cols, df.rows)
+	if err != nil {
+		return nil, err
+	}
+	out.index = df.index
+	return out, nil
 }
 
 // CrossJoin returns a DataFrame containing the cross join of two DataFrames.
@@ -458,12 +472,60 @@ func (df *DataFrame) Drop(names ...string) (*DataFrame, error) {
 	return fn(df)
 }
 
+// Rename the given DataFrame columns, mapping old names to new names.
+func (df *DataFrame) Rename(names map[string]string) (*DataFrame, error) {
+	fn := df.mutator.Rename(names)
+	return fn(df)
+}
+
+// Reorder the DataFrame columns, moving the given columns first.
+func (df *DataFrame) Reorder(names ...string) (*DataFrame, error) {
+	fn := df.mutator.Reorder(names...)
+	return fn(df)
+}
+
 // InnerJoin returns a DataFrame containing the inner join of two DataFrames.
 func (df *DataFrame) InnerJoin(right *DataFrame, columns []string, opts ...Option) (*DataFrame, error) {
 	fn := df.mutator.InnerJoin(right, columns, opts...)
 	return fn(df)
 }
 
+// SemiJoin returns a DataFrame containing the rows of df matching a row of right.
+func (df *DataFrame) SemiJoin(right *DataFrame, columns []string) (*DataFrame, error) {
+	fn := df.mutator.SemiJoin(right, columns)
+	return fn(df)
+}
+
+// AntiJoin returns a DataFrame containing the rows of df matching no row of right.
+func (df *DataFrame) AntiJoin(right *DataFrame, columns []string) (*DataFrame, error) {
+	fn := df.mutator.AntiJoin(right, columns)
+	return fn(df)
+}
+
+// AsofJoin returns a DataFrame matching every row of df with the closest row of right
+// on the named column, see Mutator.AsofJoin.
+func (df *DataFrame) AsofJoin(right *DataFrame, on string, opts ...Option) (*DataFrame, error) {
+	return df.mutator.AsofJoin(right, on, opts...)(df)
+}
+
+// IntervalJoin returns a DataFrame matching every row of df with the rows of right
+// whose interval holds the value of the named column, see Mutator.IntervalJoin.
+func (df *DataFrame) IntervalJoin(right *DataFrame, column, start, end string, how compute.JoinType, opts ...Option) (*DataFrame, error) {
+	return df.mutator.IntervalJoin(right, column, start, end, how, opts...)(df)
+}
+
+// IndexJoin returns a DataFrame matching every row of df with the rows of right
+// holding the same index key, see Mutator.IndexJoin.
+func (df *DataFrame) IndexJoin(right *DataFrame, how compute.JoinType, opts ...Option) (*DataFrame, error) {
+	return df.mutator.IndexJoin(right, how, opts...)(df)
+}
+
+// Arithmetic returns a DataFrame applying op to the values of df and right
+// aligned on their index keys, see Mutator.Arithmetic.
+func (df *DataFrame) Arithmetic(op ArithOp, right *DataFrame, opts ...Option) (*DataFrame, error) {
+	return df.mutator.Arithmetic(op, right, opts...)(df)
+}
+
 // LeftJoin returns a DataFrame containing the left join of two DataFrames.
 func (df *DataFrame) LeftJoin(right *DataFrame, columns []string, opts ...Option) (*DataFrame, error) {
 	fn := df.mutator.LeftJoin(right, columns, opts...)
@@ -488,6 +550,101 @@ func (df *DataFrame) Slice(beg, end int64) (*DataFrame, error) {
 	return df.mutator.Slice(beg, end)(df)
 }
 
+// Take creates a new DataFrame consisting of the rows at the given indices, in order.
+func (df *DataFrame) Take(indices *array.Int64) (*DataFrame, error) {
+	return df.mutator.Take(indices)(df)
+}
+
+// TopK creates a new DataFrame with the k rows holding the largest values of the named column,
+// ordered from largest to smallest.
+func (df *DataFrame) TopK(columnName string, k int) (*DataFrame, error) {
+	return df.mutator.TopK(columnName, k, compute.Descending)(df)
+}
+
+// Reindex creates a new DataFrame conformed to the keys of target, see Mutator.Reindex.
+func (df *DataFrame) Reindex(target Index, fill FillPolicy) (*DataFrame, error) {
+	return df.mutator.Reindex(target, fill)(df)
+}
+
+// Resample creates a new DataFrame aggregating the named columns over windows of
+// the given duration of the timestamp index, see Mutator.Resample.
+func (df *DataFrame) Resample(every time.Duration, kind compute.AggregateKind, columnNames ...string) (*DataFrame, error) {
+	return df.mutator.Resample(every, kind, columnNames...)(df)
+}
+
+// Sessionize creates a new DataFrame with a column of the session ids of the
+// rows, see Mutator.Sessionize.
+func (df *DataFrame) Sessionize(byCols []string, tsCol string, gap time.Duration) (*DataFrame, error) {
+	return df.mutator.Sessionize(byCols, tsCol, gap)(df)
+}
+
+// Page creates a new DataFrame of at most limit rows of df starting at row
+// offset, see Mutator.Page.
+func (df *DataFrame) Page(offset, limit int64) (*DataFrame, error) {
+	return df.mutator.Page(offset, limit)(df)
+}
+
+// Compact creates a new DataFrame holding the rows of df in fewer, larger
+// chunks, see Mutator.Compact.
+func (df *DataFrame) Compact(targetChunkSize int64) (*DataFrame, error) {
+	return df.mutator.Compact(targetChunkSize)(df)
+}
+
+// GroupBy groups the rows of df by the named key columns, to be aggregated by
+// Grouped.Agg.
+func (df *DataFrame) GroupBy(keys ...string) *Grouped {
+	return &Grouped{df: df, keys: keys}
+}
+
+// Sample creates a new DataFrame with n rows drawn at random, see Mutator.Sample.
+func (df *DataFrame) Sample(n int64, seed int64) (*DataFrame, error) {
+	return df.mutator.Sample(n, seed)(df)
+}
+
+// SampleFraction creates a new DataFrame with the fraction frac of the rows drawn at random.
+func (df *DataFrame) SampleFraction(frac float64, seed int64) (*DataFrame, error) {
+	return df.mutator.SampleFraction(frac, seed)(df)
+}
+
+// StratifiedSample creates a new DataFrame with the fraction frac of the rows of every group
+// of equal values of the named columns, see Mutator.StratifiedSample.
+func (df *DataFrame) StratifiedSample(frac float64, seed int64, columnNames ...string) (*DataFrame, error) {
+	return df.mutator.StratifiedSample(frac, seed, columnNames...)(df)
+}
+
+// Explode returns a DataFrame with a row for every element of the lists of the named
+// column, see Mutator.Explode.
+func (df *DataFrame) Explode(columnName string, policy compute.ExplodePolicy) (*DataFrame, error) {
+	return df.mutator.Explode(columnName, policy)(df)
+}
+
+// Unnest returns a DataFrame replacing the named struct column with a column for
+// every field of the struct, see Mutator.Unnest.
+func (df *DataFrame) Unnest(columnName, prefix string) (*DataFrame, error) {
+	return df.mutator.Unnest(columnName, prefix)(df)
+}
+
+// Cov creates a DataFrame holding the covariance matrix of the named columns, see Mutator.Cov.
+func (df *DataFrame) Cov(policy compute.NullPolicy, columnNames ...string) (*DataFrame, error) {
+	return df.mutator.Cov(policy, columnNames...)(df)
+}
+
+// Corr creates a DataFrame holding the correlation matrix of the named columns, see Mutator.Corr.
+func (df *DataFrame) Corr(policy compute.NullPolicy, columnNames ...string) (*DataFrame, error) {
+	return df.mutator.Corr(policy, columnNames...)(df)
+}
+
+// WithColumn creates a new DataFrame with the result of evaluating e stored in the named column.
+func (df *DataFrame) WithColumn(name string, e expr.Expr) (*DataFrame, error) {
+	return df.mutator.WithColumn(name, e)(df)
+}
+
+// Filter creates a new DataFrame with the rows for which the boolean expression e
+// is true. Rows where e is null are dropped.
+func (df *DataFrame) Filter(e expr.Expr) (*DataFrame, error) {
+	return df.mutator.Filter(e)(df)
+}
+
 // Schema returns the schema of this Frame.
 func (df *DataFrame) Schema() *arrow.Schema {
 	return df.schema
@@ -496,6 +653,9 @@ func (df *DataFrame) Schema() *arrow.Schema {
 // Retain increases the reference count by 1.
 // Retain may be called simultaneously from multiple goroutines.
 func (df *DataFrame) Retain() {
+	if debug.RefAudit {
+		debug.TrackRetain(df, "DataFrame")
+	}
 	atomic.AddInt64(&df.refs, 1)
 }
 
@@ -503,17 +663,42 @@ func (df *DataFrame) Retain() {
 // When the reference count goes to zero, the memory is freed.
 // Release may be called simultaneously from multiple goroutines.
 func (df *DataFrame) Release() {
+	if debug.RefAudit {
+		debug.TrackRelease(df)
+	}
 	refs := atomic.AddInt64(&df.refs, -1)
 	debug.Assert(refs >= 0, "too many releases")
 
 	if refs == 0 {
 		for i := range df.cols {
+			if debug.RefAudit {
+				debug.TrackRelease(df.cols[i].Data())
+			}
 			df.cols[i].Release()
 		}
 		df.cols = nil
 	}
 }
 
+// trackRefs records the reference held on the new DataFrame and on the data
+// of its columns when built with the refaudit tag.
+func (df *DataFrame) trackRefs() {
+	if !debug.RefAudit {
+		return
+	}
+	debug.TrackRetain(df, "DataFrame")
+	for i := range df.cols {
+		debug.TrackRetain(df.cols[i].Data(), "column "+df.cols[i].Name())
+	}
+}
+
+// LeakReport returns the DataFrames and column data still referenced, with
+// the stack traces of the calls that took each reference, when built with the
+// refaudit tag. It returns an empty string otherwise, or when nothing leaked.
+func LeakReport() string {
+	return debug.Leaks()
+}
+
 func (df *DataFrame) validate() error {
 	// 字段数匹配
 	if len(df.Columns()) != len(df.schema.Fields()) {
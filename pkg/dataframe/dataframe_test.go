@@ -15,15 +15,23 @@
 package dataframe
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/array"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/expr"
 	"github.com/gomem/gomem/pkg/iterator"
+	"github.com/gomem/gomem/pkg/object"
+	"github.com/gomem/gomem/pkg/pushdown"
 	"github.com/gomem/gomem/pkg/smartbuilder"
 )
 
@@ -495,6 +503,150 @@ rec[0]["col5-i32"]: [1 2 3 4 5 6 7 8 9 10]
 	}
 }
 
+func TestRename(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := NewDataFrameFromMem(pool, Dict{
+		"col1-i32": []int32{1, 2, 3},
+		"col2-f64": []float64{1, 2, 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	df2, err := df.Rename(map[string]string{"col1-i32": "id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df2.Release()
+
+	got := df2.Display(-1)
+	want := `rec[0]["id"]: [1 2 3]
+rec[0]["col2-f64"]: [1 2 3]
+`
+	if got != want {
+		t.Fatalf("\ngot=\n%v\nwant=\n%v", got, want)
+	}
+	if df2.Column("id").Data().Chunk(0) != df.Column("col1-i32").Data().Chunk(0) {
+		t.Fatal("renamed column does not share its data")
+	}
+
+	if _, err := df.Rename(map[string]string{"missing": "x"}); err == nil {
+		t.Fatal("expected an error for an unknown column")
+	}
+	if _, err := df.Rename(map[string]string{"col1-i32": "col2-f64"}); err == nil {
+		t.Fatal("expected an error for a duplicate column")
+	}
+}
+
+func TestReorder(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := NewDataFrameFromMem(pool, Dict{
+		"col1-i32": []int32{1, 2, 3},
+		"col2-f64": []float64{1, 2, 3},
+		"col3-i32": []int32{4, 5, 6},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	df2, err := df.Reorder("col3-i32", "col2-f64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df2.Release()
+
+	got := df2.Display(-1)
+	want := `rec[0]["col3-i32"]: [4 5 6]
+rec[0]["col2-f64"]: [1 2 3]
+rec[0]["col1-i32"]: [1 2 3]
+`
+	if got != want {
+		t.Fatalf("\ngot=\n%v\nwant=\n%v", got, want)
+	}
+
+	if _, err := df.Reorder("col1-i32", "col1-i32"); err == nil {
+		t.Fatal("expected an error for a repeated column")
+	}
+	if _, err := df.Reorder("missing"); err == nil {
+		t.Fatal("expected an error for an unknown column")
+	}
+}
+
+func TestAppender(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := NewDataFrameFromMem(pool, Dict{
+		"col1-i32": []int32{1, 2},
+		"col2-str": []string{"a", "b"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	app, err := df.BeginAppend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Release()
+
+	if err := app.AppendRow(int32(3), "c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.AppendRow(int32(4)); err == nil {
+		t.Fatal("expected an error for a short row")
+	}
+	if err := app.AppendRow(int32(5), nil); err == nil {
+		t.Fatal("expected an error for a null in a non-nullable column")
+	}
+	if err := app.AppendRow(int32(5), "e"); err != nil {
+		t.Fatal(err)
+	}
+	if got := app.NumRows(); got != 2 {
+		t.Fatalf("got %d appended rows, want 2", got)
+	}
+
+	df2, err := app.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df2.Release()
+
+	if got := df2.NumRows(); got != 4 {
+		t.Fatalf("got %d rows, want 4", got)
+	}
+	if got := len(df2.Column("col1-i32").Data().Chunks()); got != 2 {
+		t.Fatalf("got %d chunks, want 2", got)
+	}
+	if df2.Column("col1-i32").Data().Chunk(0) != df.Column("col1-i32").Data().Chunk(0) {
+		t.Fatal("committed frame does not share the existing chunks")
+	}
+	if got := df.NumRows(); got != 2 {
+		t.Fatalf("original frame has %d rows, want 2", got)
+	}
+
+	got := df2.Display(-1)
+	want := `rec[0]["col1-i32"]: [1 2]
+rec[0]["col2-str"]: ["a" "b"]
+rec[1]["col1-i32"]: [3 5]
+rec[1]["col2-str"]: ["c" "e"]
+`
+	if got != want {
+		t.Fatalf("\ngot=\n%v\nwant=\n%v", got, want)
+	}
+
+	if err := app.AppendRow(int32(6), "f"); err == nil {
+		t.Fatal("expected an error after commit")
+	}
+}
+
 func TestNewDataFrameFromMem(t *testing.T) {
 	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
 	defer pool.AssertSize(t, 0)
@@ -877,6 +1029,51 @@ rec[0]["D_0"]: [5 (null) 1 5 0 0]
 	}
 }
 
+func TestSemiJoin(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	leftDf, err := NewDataFrameFromMem(pool, Dict{
+		"A": []float64{1, 7, 6, 1},
+		"B": []float64{2.1, 2.2, 2.3, 2.4},
+		"D": []float64{5, 3, 2, 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer leftDf.Release()
+
+	rightDf, err := NewDataFrameFromMem(pool, Dict{
+		"A": []float64{2, 0, 6, 1, 6},
+		"D": []float64{2, 7, 2, 2, 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rightDf.Release()
+
+	for _, tc := range []struct {
+		join func(*DataFrame, []string) (*DataFrame, error)
+		want string
+	}{
+		{leftDf.SemiJoin, "rec[0][\"A\"]: [6 1]\nrec[0][\"B\"]: [2.3 2.4]\nrec[0][\"D\"]: [2 2]\n"},
+		{leftDf.AntiJoin, "rec[0][\"A\"]: [1 7]\nrec[0][\"B\"]: [2.1 2.2]\nrec[0][\"D\"]: [5 3]\n"},
+	} {
+		joinedDf, err := tc.join(rightDf, []string{"A", "D"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := joinedDf.Display(-1); got != tc.want {
+			t.Errorf("\ngot=\n%v\nwant=\n%v", got, tc.want)
+		}
+		joinedDf.Release()
+	}
+
+	if _, err := leftDf.SemiJoin(rightDf, []string{"B"}); err == nil {
+		t.Fatal("expected an error joining on a column missing from the right DataFrame")
+	}
+}
+
 func TestInnerJoinCase1(t *testing.T) {
 	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
 	defer pool.AssertSize(t, 0)
@@ -920,6 +1117,84 @@ rec[0]["F"]: [2 9]
 	}
 }
 
+func TestJoinDictionaryKeys(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	// encode dictionary encodes column name of df.
+	encode := func(df *DataFrame, name string) *DataFrame {
+		enc, err := compute.DictionaryEncode(pool, df.Column(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer enc.Release()
+		cols := append([]array.Column(nil), df.Columns()...)
+		for i := range cols {
+			if cols[i].Name() == name {
+				cols[i] = *enc
+			}
+		}
+		res, err := NewDataFrameFromColumns(pool, cols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	left, err := NewDataFrameFromMem(pool, Dict{
+		"A": []string{"x", "y", "z", "x"},
+		"B": []int64{1, 2, 3, 4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer left.Release()
+	leftDf := encode(left, "A")
+	defer leftDf.Release()
+
+	// The right dictionary holds the values in another order.
+	right, err := NewDataFrameFromMem(pool, Dict{
+		"A": []string{"z", "x", "w"},
+		"C": []float64{7, 8, 9},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer right.Release()
+	rightDf := encode(right, "A")
+	defer rightDf.Release()
+
+	joinedDf, err := leftDf.LeftJoin(rightDf, []string{"A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer joinedDf.Release()
+
+	got := joinedDf.Display(-1)
+	want := `rec[0]["A"]: ["x" "y" "z" "x"]
+rec[0]["B"]: [1 2 3 4]
+rec[0]["C"]: [8 (null) 7 8]
+`
+	if got != want {
+		t.Fatalf("\ngot=\n%v\nwant=\n%v", got, want)
+	}
+
+	innerDf, err := leftDf.InnerJoin(rightDf, []string{"A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer innerDf.Release()
+
+	got = innerDf.Display(-1)
+	want = `rec[0]["A"]: ["x" "z" "x"]
+rec[0]["B"]: [1 3 4]
+rec[0]["C"]: [8 7 8]
+`
+	if got != want {
+		t.Fatalf("\ngot=\n%v\nwant=\n%v", got, want)
+	}
+}
+
 func TestOuterJoin(t *testing.T) {
 	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
 	defer pool.AssertSize(t, 0)
@@ -1095,6 +1370,10 @@ rec[0]["F"]: [7 3 5 8 11 7 3 5 8 11 7 3 5 8 11 7 3 5 8 11]
 	if got != want {
 		t.Fatalf("\ngot=\n%v\nwant=\n%v", got, want)
 	}
+
+	if _, err := leftDf.CrossJoin(rightDf, WithMaxRows(19)); err == nil {
+		t.Fatal("expected an error for a cross join over the maximum number of rows")
+	}
 }
 
 func TestJoinSuffix(t *testing.T) {
@@ -1312,3 +1591,2072 @@ rec[2]["f2-f64"]: [31 32 33 34 35 36 37 38 39 40]
 		t.Fatalf("\ngot=\n%v\nwant=\n%v", got, want)
 	}
 }
+
+func TestTopK(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := NewDataFrameFromMem(pool, Dict{
+		"id":    []int32{1, 2, 3, 4, 5, 6},
+		"score": []interface{}{3.5, 9.0, nil, 7.25, 1.0, 9.0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	df2, err := df.TopK("score", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df2.Release()
+
+	got := df2.Display(-1)
+	want := `rec[0]["id"]: [2 6 4]
+rec[0]["score"]: [9 9 7.25]
+`
+	if got != want {
+		t.Fatalf("\ngot=\n%v\nwant=\n%v", got, want)
+	}
+
+	if _, err := df.TopK("missing", 3); err == nil {
+		t.Fatal("expected an error for a missing column")
+	}
+}
+
+func TestSample(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	ids := make([]int64, 20)
+	kinds := make([]string, 20)
+	for i := range ids {
+		ids[i] = int64(i)
+		kinds[i] = []string{"a", "b", "b", "b"}[i%4]
+	}
+	df, err := NewDataFrameFromMem(pool, Dict{"id": ids, "kind": kinds})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	sample, err := df.Sample(5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sample.Release()
+	again, err := df.Sample(5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer again.Release()
+	if sample.NumRows() != 5 || sample.Display(-1) != again.Display(-1) {
+		t.Fatalf("expected the same 5 rows for the same seed, got:\n%s\n%s", sample.Display(-1), again.Display(-1))
+	}
+
+	frac, err := df.SampleFraction(0.5, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer frac.Release()
+	if frac.NumRows() != 10 {
+		t.Fatalf("got=%d, want=10 rows", frac.NumRows())
+	}
+
+	strat, err := df.StratifiedSample(0.4, 3, "kind")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer strat.Release()
+	counts := map[string]int{}
+	for _, row := range strat.Column("id").Data().Chunk(0).(*array.Int64).Int64Values() {
+		counts[kinds[row]]++
+	}
+	if counts["a"] != 2 || counts["b"] != 6 {
+		t.Fatalf("got strata %v, want 2 a and 6 b rows", counts)
+	}
+
+	if _, err := df.StratifiedSample(0.4, 3, "missing"); err == nil {
+		t.Fatal("expected an error for a missing column")
+	}
+}
+
+func TestReservoirSample(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{{Name: "id", Type: arrow.PrimitiveTypes.Int64}}, nil)
+	bldr := array.NewRecordBuilder(pool, schema)
+	defer bldr.Release()
+	var recs []array.Record
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 10; j++ {
+			bldr.Field(0).(*array.Int64Builder).Append(int64(i*10 + j))
+		}
+		rec := bldr.NewRecord()
+		defer rec.Release()
+		recs = append(recs, rec)
+	}
+	rdr, err := array.NewRecordReader(schema, recs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rdr.Release()
+
+	df, err := ReservoirSample(pool, rdr, 7, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	if df.NumRows() != 7 {
+		t.Fatalf("got=%d, want=7 rows", df.NumRows())
+	}
+	var ids []int64
+	for _, chunk := range df.Column("id").Data().Chunks() {
+		ids = append(ids, chunk.(*array.Int64).Int64Values()...)
+	}
+	for i, id := range ids {
+		if id < 0 || id >= 100 || (i > 0 && id <= ids[i-1]) {
+			t.Fatalf("rows are not distinct, ordered and in range: %v", ids)
+		}
+	}
+
+	empty, err := array.NewRecordReader(schema, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer empty.Release()
+	none, err := ReservoirSample(pool, empty, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer none.Release()
+	if none.NumRows() != 0 || none.NumCols() != 1 {
+		t.Fatalf("got %d rows and %d columns, want 0 rows and 1 column", none.NumRows(), none.NumCols())
+	}
+}
+
+func TestCorr(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := NewDataFrameFromMem(pool, Dict{
+		"x": []int32{1, 2, 3, 4},
+		"y": []float64{8, 6, 4, 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	corr, err := df.Corr(compute.PairwiseComplete)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer corr.Release()
+
+	got := corr.Display(-1)
+	want := `rec[0]["column"]: ["x" "y"]
+rec[0]["x"]: [1 -1]
+rec[0]["y"]: [-1 1]
+`
+	if got != want {
+		t.Fatalf("\ngot=\n%v\nwant=\n%v", got, want)
+	}
+
+	cov, err := df.Cov(compute.CompleteRows, "y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cov.Release()
+	if got, want := cov.Display(-1), "rec[0][\"column\"]: [\"y\"]\nrec[0][\"y\"]: [6.666666666666667]\n"; got != want {
+		t.Fatalf("\ngot=\n%v\nwant=\n%v", got, want)
+	}
+
+	if _, err := df.Corr(compute.PairwiseComplete, "x", "missing"); err == nil {
+		t.Fatal("expected an error for a missing column")
+	}
+}
+
+func TestAsofJoin(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	trades, err := NewDataFrameFromMem(pool, Dict{
+		"ts":    []int64{100, 100, 250, 260},
+		"sym":   []string{"a", "b", "a", "b"},
+		"price": []float64{10, 20, 11, 21},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trades.Release()
+	quotes, err := NewDataFrameFromMem(pool, Dict{
+		"ts":    []int64{90, 95, 200, 300},
+		"sym":   []string{"a", "b", "a", "a"},
+		"price": []float64{9.5, 19.5, 10.5, 11.5},
+		"size":  []int32{1, 2, 3, 4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer quotes.Release()
+
+	df, err := trades.AsofJoin(quotes, "ts", WithBy("sym"), WithTolerance(100), WithRsuffix("_quote"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	got := df.Display(-1)
+	want := `rec[0]["price_0"]: [10 20 11 21]
+rec[0]["sym"]: ["a" "b" "a" "b"]
+rec[0]["ts"]: [100 100 250 260]
+rec[0]["price_quote"]: [9.5 19.5 10.5 (null)]
+rec[0]["size"]: [1 2 3 (null)]
+`
+	if got != want {
+		t.Fatalf("\ngot=\n%v\nwant=\n%v", got, want)
+	}
+
+	if _, err := trades.AsofJoin(quotes, "missing"); err == nil {
+		t.Fatal("expected an error for a missing column")
+	}
+	if _, err := trades.AsofJoin(quotes, "ts", WithTolerance(-1)); err == nil {
+		t.Fatal("expected an error for a negative tolerance")
+	}
+}
+
+func TestIntervalJoin(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	events, err := NewDataFrameFromMem(pool, Dict{
+		"ts":   []int64{3, 12, 40, 25},
+		"user": []string{"a", "b", "c", "d"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer events.Release()
+	sessions, err := NewDataFrameFromMem(pool, Dict{
+		"start": []int64{0, 10, 11},
+		"end":   []int64{5, 20, 30},
+		"user":  []string{"x", "y", "z"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sessions.Release()
+
+	df, err := events.IntervalJoin(sessions, "ts", "start", "end", compute.LeftJoin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	got := df.Display(-1)
+	want := `rec[0]["ts"]: [3 12 12 40 25]
+rec[0]["user_0"]: ["a" "b" "b" "c" "d"]
+rec[0]["end"]: [5 20 30 (null) 30]
+rec[0]["start"]: [0 10 11 (null) 11]
+rec[0]["user_1"]: ["x" "y" "z" (null) "z"]
+`
+	if got != want {
+		t.Fatalf("\ngot=\n%v\nwant=\n%v", got, want)
+	}
+
+	inner, err := events.IntervalJoin(sessions, "ts", "start", "end", compute.InnerJoin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inner.Release()
+	if got, want := inner.NumRows(), int64(4); got != want {
+		t.Fatalf("got=%d, want=%d rows", got, want)
+	}
+
+	if _, err := events.IntervalJoin(sessions, "ts", "start", "missing", compute.InnerJoin); err == nil {
+		t.Fatal("expected an error for a missing column")
+	}
+}
+
+func TestExplode(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int32},
+		{Name: "tags", Type: arrow.ListOf(arrow.BinaryTypes.String), Nullable: true},
+	}, nil)
+	b := array.NewRecordBuilder(pool, schema)
+	defer b.Release()
+	b.Field(0).(*array.Int32Builder).AppendValues([]int32{1, 2, 3, 4}, nil)
+	tags := b.Field(1).(*array.ListBuilder)
+	tags.Append(true)
+	tags.ValueBuilder().(*array.StringBuilder).AppendValues([]string{"a", "b"}, nil)
+	tags.AppendNull()
+	tags.Append(true)
+	tags.Append(true)
+	tags.ValueBuilder().(*array.StringBuilder).Append("c")
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	df, err := NewDataFrameFromRecord(pool, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	for _, tc := range []struct {
+		policy compute.ExplodePolicy
+		want   string
+	}{
+		{0, `rec[0]["id"]: [1 1 4]
+rec[0]["tags"]: ["a" "b" "c"]
+`},
+		{compute.ExplodeKeepNull | compute.ExplodeKeepEmpty, `rec[0]["id"]: [1 1 2 3 4]
+rec[0]["tags"]: ["a" "b" (null) (null) "c"]
+`},
+	} {
+		exploded, err := df.Explode("tags", tc.policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := exploded.Display(-1)
+		exploded.Release()
+		if got != tc.want {
+			t.Fatalf("\ngot=\n%v\nwant=\n%v", got, tc.want)
+		}
+	}
+
+	if _, err := df.Explode("id", 0); err == nil {
+		t.Fatal("expected an error for a column that does not hold lists")
+	}
+}
+
+func TestUnnest(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	dtype := arrow.StructOf(
+		arrow.Field{Name: "city", Type: arrow.BinaryTypes.String},
+		arrow.Field{Name: "zip", Type: arrow.PrimitiveTypes.Int32},
+	)
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "addr", Type: dtype, Nullable: true},
+		{Name: "id", Type: arrow.PrimitiveTypes.Int32},
+	}, nil)
+	b := array.NewRecordBuilder(pool, schema)
+	defer b.Release()
+	addr := b.Field(0).(*array.StructBuilder)
+	addr.Append(true)
+	addr.FieldBuilder(0).(*array.StringBuilder).Append("paris")
+	addr.FieldBuilder(1).(*array.Int32Builder).Append(75001)
+	addr.AppendNull()
+	b.Field(1).(*array.Int32Builder).AppendValues([]int32{1, 2}, nil)
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	df, err := NewDataFrameFromRecord(pool, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	unnested, err := df.Unnest("addr", "addr_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unnested.Release()
+
+	got := unnested.Display(-1)
+	want := `rec[0]["addr_city"]: ["paris" (null)]
+rec[0]["addr_zip"]: [75001 (null)]
+rec[0]["id"]: [1 2]
+`
+	if got != want {
+		t.Fatalf("\ngot=\n%v\nwant=\n%v", got, want)
+	}
+
+	zips, err := df.WithColumn("zip", expr.Col("addr").Field("zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zips.Release()
+	if got, want := fmt.Sprint(zips.Column("zip").Data().Chunk(0)), "[75001 (null)]"; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+
+	if _, err := df.Unnest("id", ""); err == nil {
+		t.Fatal("expected an error for a column that is not a struct")
+	}
+}
+
+func TestWithColumn(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "day", Type: arrow.FixedWidthTypes.Date32},
+		{Name: "id", Type: arrow.PrimitiveTypes.Int32},
+	}, nil)
+	b := array.NewRecordBuilder(pool, schema)
+	defer b.Release()
+	// 2020-01-31, 2020-03-15 and 2020-12-31 as days since the epoch.
+	b.Field(0).(*array.Date32Builder).AppendValues([]arrow.Date32{18292, 18336, 18627}, nil)
+	b.Field(1).(*array.Int32Builder).AppendValues([]int32{1, 2, 3}, nil)
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	df, err := NewDataFrameFromRecord(pool, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	df2, err := df.WithColumn("next", expr.AddMonths(expr.Col("day"), 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df2.Release()
+
+	// 2020-02-29, 2020-04-15 and 2021-01-31.
+	got := df2.Display(-1)
+	want := `rec[0]["day"]: [18292 18336 18627]
+rec[0]["id"]: [1 2 3]
+rec[0]["next"]: [18321 18367 18658]
+`
+	if got != want {
+		t.Fatalf("\ngot=\n%v\nwant=\n%v", got, want)
+	}
+
+	df3, err := df2.WithColumn("day", expr.Col("next"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df3.Release()
+
+	if got, want := df3.ColumnNames(), []string{"day", "id", "next"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got=%v, want=%v", got, want)
+	}
+
+	if _, err := df.WithColumn("x", expr.Col("missing")); err == nil {
+		t.Fatal("expected an error for a missing column")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "tenant", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "id", Type: arrow.PrimitiveTypes.Int32},
+	}, nil)
+	b := array.NewRecordBuilder(pool, schema)
+	defer b.Release()
+	b.Field(0).(*array.StringBuilder).AppendValues([]string{"a", "b", "", "a"}, []bool{true, true, false, true})
+	b.Field(1).(*array.Int32Builder).AppendValues([]int32{1, 2, 3, 4}, nil)
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	df, err := NewDataFrameFromRecord(pool, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	df2, err := df.Filter(expr.IsIn(expr.Col("tenant"), "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df2.Release()
+
+	got := df2.Display(-1)
+	want := `rec[0]["tenant"]: ["a" "a"]
+rec[0]["id"]: [1 4]
+`
+	if got != want {
+		t.Fatalf("\ngot=\n%v\nwant=\n%v", got, want)
+	}
+
+	if _, err := df.Filter(expr.Col("id")); err == nil || err.Error() != "mutation: expression id is of type int32, want bool" {
+		t.Fatalf("invalid error: %v", err)
+	}
+}
+
+func TestMemoryUsage(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	lb := array.NewListBuilder(pool, arrow.PrimitiveTypes.Int32)
+	defer lb.Release()
+	vb := lb.ValueBuilder().(*array.Int32Builder)
+	for i := 0; i < 3; i++ {
+		lb.Append(true)
+		vb.AppendValues([]int32{1, 2}, nil)
+	}
+	lists := lb.NewArray()
+	defer lists.Release()
+
+	df, err := NewDataFrameFromMem(pool, Dict{
+		"ints":    []int64{1, 2, 3},
+		"strings": []string{"a", "bb", "ccc"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	field := arrow.Field{Name: "lists", Type: lists.DataType()}
+	chunked := array.NewChunked(field.Type, []array.Interface{lists})
+	defer chunked.Release()
+	col := array.NewColumn(field, chunked)
+	defer col.Release()
+	df2, err := NewDataFrameFromColumns(pool, append(df.Columns()[:2:2], *col))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df2.Release()
+
+	usage := df2.MemoryUsage()
+	byName := make(map[string]ColumnUsage)
+	for _, c := range usage.Columns {
+		byName[c.Name] = c
+	}
+
+	ints := byName["ints"]
+	if got, want := ints.Chunks, 1; got != want {
+		t.Fatalf("got=%d, want=%d chunks", got, want)
+	}
+	if got, want := ints.Values, int64(3*8); got != want {
+		t.Fatalf("got=%d, want=%d value bytes", got, want)
+	}
+	strs := byName["strings"]
+	if got, want := strs.Offsets, int64(4*4); got != want {
+		t.Fatalf("got=%d, want=%d offset bytes", got, want)
+	}
+	if got, want := strs.Values, int64(6); got != want {
+		t.Fatalf("got=%d, want=%d value bytes", got, want)
+	}
+	ls := byName["lists"]
+	if ls.Offsets == 0 || ls.Values != 0 {
+		t.Fatalf("expected offsets and no values for the list column, got %+v", ls)
+	}
+	if ls.Children < int64(6*4) {
+		t.Fatalf("got=%d, want at least %d child bytes", ls.Children, 6*4)
+	}
+
+	var total int64
+	for _, c := range usage.Columns {
+		total += c.Validity + c.Offsets + c.Values + c.Children
+	}
+	if got, want := usage.Total(), total; got != want {
+		t.Fatalf("got=%d, want=%d total bytes", got, want)
+	}
+
+	top := usage.Top(1)
+	if len(top) != 1 {
+		t.Fatalf("got=%d, want=1 columns", len(top))
+	}
+	for _, c := range usage.Columns {
+		if c.Total() > top[0].Total() {
+			t.Fatalf("column %q holds more than the top column %q", c.Name, top[0].Name)
+		}
+	}
+
+	report := usage.Report(1)
+	if !strings.Contains(report, top[0].Name) {
+		t.Fatalf("expected %q in the report, got:\n%s", top[0].Name, report)
+	}
+	if want := fmt.Sprintf("total: %d bytes in 3 columns", usage.Total()); !strings.Contains(report, want) {
+		t.Fatalf("expected %q in the report, got:\n%s", want, report)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := NewDataFrameFromMem(pool, Dict{
+		"id":   []int64{1, 3, 3, 7},
+		"name": []string{"b", "a", "b", "c"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	if _, err := df.Loc(3); err == nil {
+		t.Fatal("expected an error looking up a DataFrame without index")
+	}
+	if _, err := df.SetIndex("missing"); err == nil {
+		t.Fatal("expected an error indexing a missing column")
+	}
+
+	byID, err := df.SetIndex("id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer byID.Release()
+	if _, ok := byID.Index().(*IntIndex); !ok {
+		t.Fatalf("got index %T, want *IntIndex", byID.Index())
+	}
+	byName, err := df.SetIndex("name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer byName.Release()
+
+	for _, tc := range []struct {
+		get  func() (*DataFrame, error)
+		want string
+	}{
+		{func() (*DataFrame, error) { return byID.Loc(3) }, "rec[0][\"id\"]: [3 3]\nrec[0][\"name\"]: [\"a\" \"b\"]\n"},
+		{func() (*DataFrame, error) { return byID.Loc(int32(4)) }, ""},
+		{func() (*DataFrame, error) { return byID.LocRange(2, 8) }, "rec[0][\"id\"]: [3 3 7]\nrec[0][\"name\"]: [\"a\" \"b\" \"c\"]\n"},
+		{func() (*DataFrame, error) { return byName.Loc("b") }, "rec[0][\"id\"]: [1 3]\nrec[0][\"name\"]: [\"b\" \"b\"]\n"},
+	} {
+		got, err := tc.get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Index() == nil {
+			t.Errorf("lost the index")
+		}
+		if s := got.Display(-1); s != tc.want {
+			t.Errorf("\ngot=\n%v\nwant=\n%v", s, tc.want)
+		}
+		got.Release()
+	}
+
+	if _, err := byID.Loc("3"); err == nil {
+		t.Fatal("expected an error looking up a string in an integer index")
+	}
+	if _, err := byName.LocRange("a", "b"); err == nil {
+		t.Fatal("expected an error for a range of a string index")
+	}
+	if _, err := byID.At(time.Unix(0, 0)); err == nil {
+		t.Fatal("expected an error for At without timestamp index")
+	}
+
+	unsorted, err := NewDataFrameFromMem(pool, Dict{"id": []int64{2, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unsorted.Release()
+	if _, err := unsorted.SetIndex("id"); err == nil {
+		t.Fatal("expected an error indexing an unsorted column")
+	}
+}
+
+func newSeries(t *testing.T, mem memory.Allocator, ts []arrow.Timestamp, values []float64) *DataFrame {
+	t.Helper()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "ts", Type: &arrow.TimestampType{Unit: arrow.Second}},
+		{Name: "value", Type: arrow.PrimitiveTypes.Float64},
+	}, nil)
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+	b.Field(0).(*array.TimestampBuilder).AppendValues(ts, nil)
+	b.Field(1).(*array.Float64Builder).AppendValues(values, nil)
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	df, err := NewDataFrameFromRecord(mem, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+	indexed, err := df.SetIndex("ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return indexed
+}
+
+func TestTimestampIndex(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df := newSeries(t, pool, []arrow.Timestamp{0, 30, 60, 90, 150}, []float64{1, 2, 3, 4, 5})
+	defer df.Release()
+
+	for _, tc := range []struct {
+		get  func() (*DataFrame, error)
+		want string
+	}{
+		{func() (*DataFrame, error) { return df.At(time.Unix(75, 0)) }, "rec[0][\"ts\"]: [60]\nrec[0][\"value\"]: [3]\n"},
+		{func() (*DataFrame, error) { return df.At(time.Unix(90, 0)) }, "rec[0][\"ts\"]: [90]\nrec[0][\"value\"]: [4]\n"},
+		{func() (*DataFrame, error) { return df.At(time.Unix(-1, 0)) }, ""},
+		{func() (*DataFrame, error) { return df.Loc(time.Unix(30, 0)) }, "rec[0][\"ts\"]: [30]\nrec[0][\"value\"]: [2]\n"},
+		{func() (*DataFrame, error) { return df.LocRange(time.Unix(30, 0), time.Unix(90, 0)) }, "rec[0][\"ts\"]: [30 60]\nrec[0][\"value\"]: [2 3]\n"},
+		{func() (*DataFrame, error) { return df.Resample(time.Minute, compute.AggSum) }, "rec[0][\"ts\"]: [0 60 120]\nrec[0][\"value\"]: [3 7 5]\n"},
+	} {
+		got, err := tc.get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := got.Display(-1); s != tc.want {
+			t.Errorf("\ngot=\n%v\nwant=\n%v", s, tc.want)
+		}
+		got.Release()
+	}
+
+	if _, err := df.Resample(time.Millisecond, compute.AggSum); err == nil {
+		t.Fatal("expected an error resampling below the unit of the index")
+	}
+}
+
+func TestIndexJoin(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	left := newSeries(t, pool, []arrow.Timestamp{0, 30, 60}, []float64{1, 2, 3})
+	defer left.Release()
+	right := newSeries(t, pool, []arrow.Timestamp{30, 60, 60}, []float64{20, 30, 31})
+	defer right.Release()
+
+	for _, tc := range []struct {
+		how  compute.JoinType
+		want string
+	}{
+		{compute.InnerJoin, "rec[0][\"ts\"]: [30 60 60]\nrec[0][\"value_0\"]: [2 3 3]\nrec[0][\"value_1\"]: [20 30 31]\n"},
+		{compute.LeftJoin, "rec[0][\"ts\"]: [0 30 60 60]\nrec[0][\"value_0\"]: [1 2 3 3]\nrec[0][\"value_1\"]: [(null) 20 30 31]\n"},
+		{compute.SemiJoin, "rec[0][\"ts\"]: [30 60]\nrec[0][\"value\"]: [2 3]\n"},
+		{compute.AntiJoin, "rec[0][\"ts\"]: [0]\nrec[0][\"value\"]: [1]\n"},
+	} {
+		got, err := left.IndexJoin(right, tc.how)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := got.Index().(*TimestampIndex); !ok {
+			t.Errorf("got index %T, want *TimestampIndex", got.Index())
+		}
+		if s := got.Display(-1); s != tc.want {
+			t.Errorf("\ngot=\n%v\nwant=\n%v", s, tc.want)
+		}
+		got.Release()
+	}
+
+	ids, err := NewDataFrameFromMem(pool, Dict{"ts": []int64{0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ids.Release()
+	byID, err := ids.SetIndex("ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer byID.Release()
+	if _, err := left.IndexJoin(byID, compute.InnerJoin); err == nil {
+		t.Fatal("expected an error joining indexes of different kinds")
+	}
+}
+
+func TestMultiIndex(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := NewDataFrameFromMem(pool, Dict{
+		"day":    []int64{2, 1, 2, 1},
+		"price":  []float64{11, 10, 21, 20},
+		"symbol": []string{"a", "a", "b", "b"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	panel, err := df.SetIndex("day", "symbol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer panel.Release()
+	if got := panel.Index().Columns(); !reflect.DeepEqual(got, []string{"day", "symbol"}) {
+		t.Fatalf("got levels %v", got)
+	}
+
+	for _, tc := range []struct {
+		get  func() (*DataFrame, error)
+		want string
+	}{
+		{func() (*DataFrame, error) { return panel.Loc([]interface{}{1, "b"}) }, "rec[0][\"day\"]: [1]\nrec[0][\"price\"]: [20]\nrec[0][\"symbol\"]: [\"b\"]\n"},
+		{func() (*DataFrame, error) { return panel.Loc(2) }, "rec[0][\"day\"]: [2 2]\nrec[0][\"price\"]: [11 21]\nrec[0][\"symbol\"]: [\"a\" \"b\"]\n"},
+		{func() (*DataFrame, error) { return panel.Xs("symbol", "a") }, "rec[0][\"day\"]: [2 1]\nrec[0][\"price\"]: [11 10]\nrec[0][\"symbol\"]: [\"a\" \"a\"]\n"},
+	} {
+		got, err := tc.get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := got.Index().(*MultiIndex); !ok {
+			t.Errorf("got index %T, want *MultiIndex", got.Index())
+		}
+		if s := got.Display(-1); s != tc.want {
+			t.Errorf("\ngot=\n%v\nwant=\n%v", s, tc.want)
+		}
+		got.Release()
+	}
+
+	xs, err := panel.Xs("symbol", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer xs.Release()
+	if got := xs.Index().Columns(); !reflect.DeepEqual(got, []string{"day"}) {
+		t.Fatalf("got levels %v after Xs", got)
+	}
+
+	if _, err := panel.Loc([]interface{}{1, "b", 3}); err == nil {
+		t.Fatal("expected an error for a key with too many values")
+	}
+	if _, err := panel.Xs("price", 10.0); err == nil {
+		t.Fatal("expected an error for a column that is not a level")
+	}
+	if _, err := df.SetIndex("day", "day"); err == nil {
+		t.Fatal("expected an error indexing a column twice")
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	index := func(dict Dict, names ...string) *DataFrame {
+		df, err := NewDataFrameFromMem(pool, dict)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer df.Release()
+		indexed, err := df.SetIndex(names...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return indexed
+	}
+
+	qty := index(Dict{"day": []int64{1, 1, 2}, "symbol": []string{"a", "b", "a"}, "value": []int64{10, 20, 30}}, "day", "symbol")
+	defer qty.Release()
+	price := index(Dict{"day": []int64{2, 1, 3}, "symbol": []string{"a", "a", "a"}, "value": []float64{1.5, 2, 4}}, "day", "symbol")
+	defer price.Release()
+
+	got, err := qty.Arithmetic(Mul, price)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `rec[0]["day"]: [1 1 2 3]
+rec[0]["symbol"]: ["a" "b" "a" "a"]
+rec[0]["value"]: [20 (null) 45 (null)]
+`
+	if s := got.Display(-1); s != want {
+		t.Errorf("\ngot=\n%v\nwant=\n%v", s, want)
+	}
+	got.Release()
+
+	// An ordered index keeps the keys in order.
+	a := index(Dict{"day": []int64{1, 3}, "value": []float64{1, 3}}, "day")
+	defer a.Release()
+	b := index(Dict{"day": []int64{2, 3}, "value": []float64{20, 0}}, "day")
+	defer b.Release()
+	got, err = a.Arithmetic(Div, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `rec[0]["day"]: [1 2 3]
+rec[0]["value"]: [(null) (null) (null)]
+`
+	if s := got.Display(-1); s != want {
+		t.Errorf("\ngot=\n%v\nwant=\n%v", s, want)
+	}
+	got.Release()
+	got, err = a.Arithmetic(Sub, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `rec[0]["day"]: [1 2 3]
+rec[0]["value"]: [(null) (null) 3]
+`
+	if s := got.Display(-1); s != want {
+		t.Errorf("\ngot=\n%v\nwant=\n%v", s, want)
+	}
+	if _, ok := got.Index().(*IntIndex); !ok {
+		t.Errorf("got index %T, want *IntIndex", got.Index())
+	}
+	got.Release()
+
+	if _, err := a.Arithmetic(Add, qty); err == nil {
+		t.Fatal("expected an error aligning indexes of different kinds")
+	}
+	dup := index(Dict{"day": []int64{1, 1}, "value": []float64{1, 2}}, "day")
+	defer dup.Release()
+	if _, err := dup.Arithmetic(Add, a); err == nil {
+		t.Fatal("expected an error for duplicate keys")
+	}
+}
+
+func TestReindexAndAlign(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	index := func(dict Dict, names ...string) *DataFrame {
+		df, err := NewDataFrameFromMem(pool, dict)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer df.Release()
+		indexed, err := df.SetIndex(names...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return indexed
+	}
+
+	df := index(Dict{"day": []int32{2, 4, 6}, "value": []float64{20, 40, 60}}, "day")
+	defer df.Release()
+	target := index(Dict{"day": []int64{1, 2, 3, 7}}, "day")
+	defer target.Release()
+
+	for _, tc := range []struct {
+		fill FillPolicy
+		want string
+	}{
+		{FillNull, "rec[0][\"day\"]: [1 2 3 7]\nrec[0][\"value\"]: [(null) 20 (null) (null)]\n"},
+		{FillForward, "rec[0][\"day\"]: [1 2 3 7]\nrec[0][\"value\"]: [(null) 20 20 60]\n"},
+		{FillBackward, "rec[0][\"day\"]: [1 2 3 7]\nrec[0][\"value\"]: [20 20 40 (null)]\n"},
+	} {
+		got, err := df.Reindex(target.Index(), tc.fill)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Column("day").DataType().ID() != arrow.INT32 {
+			t.Errorf("got day of type %s, want int32", got.Column("day").DataType())
+		}
+		if s := got.Display(-1); s != tc.want {
+			t.Errorf("%v:\ngot=\n%v\nwant=\n%v", tc.fill, s, tc.want)
+		}
+		got.Release()
+	}
+
+	byName := index(Dict{"name": []string{"b", "a"}, "value": []int64{2, 1}}, "name")
+	defer byName.Release()
+	names := index(Dict{"name": []string{"a", "c"}}, "name")
+	defer names.Release()
+	got, err := byName.Reindex(names.Index(), FillNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, want := got.Display(-1), "rec[0][\"name\"]: [\"a\" \"c\"]\nrec[0][\"value\"]: [1 (null)]\n"; s != want {
+		t.Errorf("\ngot=\n%v\nwant=\n%v", s, want)
+	}
+	got.Release()
+	if _, err := byName.Reindex(names.Index(), FillForward); err == nil {
+		t.Fatal("expected an error filling forward with a string index")
+	}
+	if _, err := df.Reindex(names.Index(), FillNull); err == nil {
+		t.Fatal("expected an error reindexing with an index of another kind")
+	}
+
+	other := index(Dict{"day": []int32{3, 4}, "qty": []int64{3, 4}}, "day")
+	defer other.Release()
+	for _, tc := range []struct {
+		how         AlignJoin
+		left, right string
+	}{
+		{
+			AlignOuter,
+			"rec[0][\"day\"]: [2 3 4 6]\nrec[0][\"value\"]: [20 (null) 40 60]\n",
+			"rec[0][\"day\"]: [2 3 4 6]\nrec[0][\"qty\"]: [(null) 3 4 (null)]\n",
+		},
+		{
+			AlignInner,
+			"rec[0][\"day\"]: [4]\nrec[0][\"value\"]: [40]\n",
+			"rec[0][\"day\"]: [4]\nrec[0][\"qty\"]: [4]\n",
+		},
+		{
+			AlignLeft,
+			"rec[0][\"day\"]: [2 4 6]\nrec[0][\"value\"]: [20 40 60]\n",
+			"rec[0][\"day\"]: [2 4 6]\nrec[0][\"qty\"]: [(null) 4 (null)]\n",
+		},
+	} {
+		l, r, err := df.Align(other, tc.how)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := l.Display(-1); s != tc.left {
+			t.Errorf("left %v:\ngot=\n%v\nwant=\n%v", tc.how, s, tc.left)
+		}
+		if s := r.Display(-1); s != tc.right {
+			t.Errorf("right %v:\ngot=\n%v\nwant=\n%v", tc.how, s, tc.right)
+		}
+		if l.Index() == nil || r.Index() == nil {
+			t.Errorf("%v: lost the index", tc.how)
+		}
+		l.Release()
+		r.Release()
+	}
+
+	sum, err := df.Arithmetic(Add, df, WithAlignJoin(AlignInner))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, want := sum.Display(-1), "rec[0][\"day\"]: [2 4 6]\nrec[0][\"value\"]: [40 80 120]\n"; s != want {
+		t.Errorf("\ngot=\n%v\nwant=\n%v", s, want)
+	}
+	sum.Release()
+}
+
+func TestValidate(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	// newStrings builds a String array from raw offsets and data, as a zero-copy
+	// reader would.
+	newStrings := func(offsets []int32, data string) array.Interface {
+		offs := memory.NewResizableBuffer(pool)
+		offs.Resize(arrow.Int32Traits.BytesRequired(len(offsets)))
+		copy(arrow.Int32Traits.CastFromBytes(offs.Bytes()), offsets)
+		defer offs.Release()
+		values := memory.NewResizableBuffer(pool)
+		values.Resize(len(data))
+		copy(values.Bytes(), data)
+		defer values.Release()
+		d := array.NewData(arrow.BinaryTypes.String, len(offsets)-1, []*memory.Buffer{nil, offs, values}, nil, 0, 0)
+		defer d.Release()
+		return array.MakeFromData(d)
+	}
+	schema := arrow.NewSchema([]arrow.Field{{Name: "s", Type: arrow.BinaryTypes.String}}, nil)
+
+	for _, tc := range []struct {
+		offsets []int32
+		data    string
+		err     string
+		utf8Err string
+	}{
+		{offsets: []int32{0, 1, 3}, data: "abc"},
+		{offsets: []int32{0, 2, 1}, data: "abc", err: "smaller than the previous one"},
+		{offsets: []int32{0, 1, 4}, data: "abc", err: "out of the 3 values"},
+		{offsets: []int32{0, 1, 3}, data: "a\xff\xfe", utf8Err: "not valid UTF-8"},
+	} {
+		arr := newStrings(tc.offsets, tc.data)
+		df, err := NewDataFrame(pool, schema, []array.Interface{arr})
+		arr.Release()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, check := range []struct {
+			opts []Option
+			want string
+		}{
+			{want: tc.err},
+			{opts: []Option{WithUTF8Validation()}, want: tc.err + tc.utf8Err},
+		} {
+			err := Validate(df, check.opts...)
+			switch {
+			case check.want == "" && err != nil:
+				t.Errorf("offsets %v: %v", tc.offsets, err)
+			case check.want != "" && (err == nil || !strings.Contains(err.Error(), check.want)):
+				t.Errorf("offsets %v: got error %v, want %q", tc.offsets, err, check.want)
+			}
+		}
+		df.Release()
+	}
+}
+
+func TestDiff(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "price", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+	}, nil)
+	newDf := func(ids []int64, names []string, prices []float64, valid []bool) *DataFrame {
+		b := array.NewRecordBuilder(pool, schema)
+		defer b.Release()
+		b.Field(0).(*array.Int64Builder).AppendValues(ids, valid)
+		b.Field(1).(*array.StringBuilder).AppendValues(names, nil)
+		b.Field(2).(*array.Float64Builder).AppendValues(prices, nil)
+		rec := b.NewRecord()
+		defer rec.Release()
+		df, err := NewDataFrameFromRecord(pool, rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return df
+	}
+
+	old := newDf([]int64{1, 2, 3, 0}, []string{"a", "b", "c", "n"}, []float64{1, 2, 3, 9}, []bool{true, true, true, false})
+	defer old.Release()
+	new := newDf([]int64{3, 1, 4, 0}, []string{"c", "a", "d", "m"}, []float64{3.5, 1, 4, 9}, []bool{true, true, true, false})
+	defer new.Release()
+
+	diff, err := Diff(old, new, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer diff.Release()
+
+	for _, tc := range []struct {
+		name string
+		df   *DataFrame
+		want string
+	}{
+		{"added", diff.Added, `rec[0]["id"]: [4]
+rec[0]["name"]: ["d"]
+rec[0]["price"]: [4]
+`},
+		{"removed", diff.Removed, `rec[0]["id"]: [2]
+rec[0]["name"]: ["b"]
+rec[0]["price"]: [2]
+`},
+		{"changed", diff.Changed, `rec[0]["id"]: [3 (null)]
+rec[0]["name_before"]: ["c" "n"]
+rec[0]["name_after"]: ["c" "m"]
+rec[0]["price_before"]: [3 9]
+rec[0]["price_after"]: [3.5 9]
+`},
+	} {
+		if got := tc.df.Display(-1); got != tc.want {
+			t.Errorf("%s:\ngot=\n%v\nwant=\n%v", tc.name, got, tc.want)
+		}
+	}
+
+	if _, err := Diff(old, new); err == nil {
+		t.Fatal("expected an error without keys")
+	}
+	if _, err := Diff(old, new, "sku"); err == nil {
+		t.Fatal("expected an error for a missing key column")
+	}
+	dup := newDf([]int64{1, 1}, []string{"a", "b"}, []float64{1, 2}, nil)
+	defer dup.Release()
+	if _, err := Diff(old, dup, "id"); err == nil {
+		t.Fatal("expected an error for duplicate keys")
+	}
+	if _, err := Diff(dup, old, "id"); err == nil {
+		t.Fatal("expected an error for duplicate old keys")
+	}
+	composite, err := Diff(dup, old, "id", "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer composite.Release()
+	if got, want := composite.Changed.NumRows(), int64(0); got != want {
+		t.Fatalf("got %d changed rows, want %d", got, want)
+	}
+}
+
+func TestRing(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	dtype := &arrow.TimestampType{Unit: arrow.Second}
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "ts", Type: dtype},
+		{Name: "value", Type: arrow.PrimitiveTypes.Float64},
+	}, nil)
+	newRecord := func(ts []arrow.Timestamp, values []float64) array.Record {
+		b := array.NewRecordBuilder(pool, schema)
+		defer b.Release()
+		b.Field(0).(*array.TimestampBuilder).AppendValues(ts, nil)
+		b.Field(1).(*array.Float64Builder).AppendValues(values, nil)
+		return b.NewRecord()
+	}
+	appendRecord := func(r *Ring, ts []arrow.Timestamp, values []float64) error {
+		rec := newRecord(ts, values)
+		defer rec.Release()
+		return r.Append(rec)
+	}
+	display := func(r *Ring) string {
+		df, err := r.Frame()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer df.Release()
+		var s string
+		for _, col := range df.Columns() {
+			s += fmt.Sprintf("%s: %v\n", col.Name(), col.Data().Chunks())
+		}
+		return s
+	}
+
+	r, err := NewRing(pool, schema, WithMaxRows(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		base := arrow.Timestamp(3 * i)
+		if err := appendRecord(r, []arrow.Timestamp{base, base + 1, base + 2}, []float64{float64(base), float64(base + 1), float64(base + 2)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := r.NumRows(), int64(5); got != want {
+		t.Fatalf("got %d rows, want %d", got, want)
+	}
+	if got, want := len(r.chunks), 2; got != want {
+		t.Fatalf("got %d chunks, want %d", got, want)
+	}
+	if got, want := display(r), "ts: [[4 5] [6 7 8]]\nvalue: [[4 5] [6 7 8]]\n"; got != want {
+		t.Fatalf("got=\n%s\nwant=\n%s", got, want)
+	}
+	// The buffers of the dropped chunk are reused by the next one.
+	pooled := r.pool.Pooled()
+	if pooled == 0 {
+		t.Fatal("expected the buffers of the dropped chunk to be pooled")
+	}
+	if err := appendRecord(r, []arrow.Timestamp{9, 10, 11}, []float64{9, 10, 11}); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.pool.Pooled(); got != pooled {
+		t.Fatalf("got %d bytes pooled, want %d", got, pooled)
+	}
+	r.Release()
+
+	r, err = NewRing(pool, schema, WithRetention("ts", 10*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+	if err := appendRecord(r, []arrow.Timestamp{0, 5}, []float64{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := appendRecord(r, []arrow.Timestamp{8, 12}, []float64{3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := display(r), "ts: [[5] [8 12]]\nvalue: [[2] [3 4]]\n"; got != want {
+		t.Fatalf("got=\n%s\nwant=\n%s", got, want)
+	}
+	if err := appendRecord(r, []arrow.Timestamp{30}, []float64{5}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := display(r), "ts: [[30]]\nvalue: [[5]]\n"; got != want {
+		t.Fatalf("got=\n%s\nwant=\n%s", got, want)
+	}
+	if err := appendRecord(r, []arrow.Timestamp{29}, []float64{6}); err == nil {
+		t.Fatal("expected an error for a timestamp going back in time")
+	}
+
+	if _, err := NewRing(pool, schema); err == nil {
+		t.Fatal("expected an error without retention")
+	}
+	if _, err := NewRing(pool, schema, WithRetention("value", time.Second)); err == nil {
+		t.Fatal("expected an error for a retention column that is not a timestamp")
+	}
+}
+
+func TestSessionize(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "user", Type: arrow.BinaryTypes.String},
+		{Name: "ts", Type: &arrow.TimestampType{Unit: arrow.Second}, Nullable: true},
+	}, nil)
+	b := array.NewRecordBuilder(pool, schema)
+	defer b.Release()
+	b.Field(0).(*array.StringBuilder).AppendValues([]string{"a", "b", "a", "a", "b", "a", "b"}, nil)
+	b.Field(1).(*array.TimestampBuilder).AppendValues(
+		[]arrow.Timestamp{0, 10, 50, 20, 200, 0, 100},
+		[]bool{true, true, true, true, true, false, true},
+	)
+	rec := b.NewRecord()
+	defer rec.Release()
+	df, err := NewDataFrameFromRecord(pool, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	for _, tc := range []struct {
+		by   []string
+		gap  time.Duration
+		want string
+	}{
+		{[]string{"user"}, 25 * time.Second, "[0 2 1 0 4 (null) 3]"},
+		{[]string{"user"}, 100 * time.Second, "[0 1 0 0 1 (null) 1]"},
+		{nil, 40 * time.Second, "[0 0 0 0 2 (null) 1]"},
+	} {
+		got, err := df.Sessionize(tc.by, "ts", tc.gap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		col := got.Column(SessionColumn)
+		if col == nil {
+			t.Fatalf("missing column %q in %v", SessionColumn, got.ColumnNames())
+		}
+		if s := fmt.Sprint(col.Data().Chunk(0)); s != tc.want {
+			t.Errorf("Sessionize(%v, %v): got=%s, want=%s", tc.by, tc.gap, s, tc.want)
+		}
+		got.Release()
+	}
+
+	if _, err := df.Sessionize([]string{"user"}, "user", time.Second); err == nil {
+		t.Fatal("expected an error for a column that is not a timestamp")
+	}
+	if _, err := df.Sessionize([]string{"missing"}, "ts", time.Second); err == nil {
+		t.Fatal("expected an error for a missing key column")
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "k", Type: arrow.BinaryTypes.String},
+		{Name: "x", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "s", Type: arrow.BinaryTypes.String},
+		{Name: "y", Type: arrow.PrimitiveTypes.Int64},
+	}, nil)
+	b := array.NewRecordBuilder(pool, schema)
+	defer b.Release()
+	b.Field(0).(*array.StringBuilder).AppendValues([]string{"a", "b", "a", "b", "c"}, nil)
+	b.Field(1).(*array.Float64Builder).AppendValues([]float64{1, 2, 3, 4, 5}, nil)
+	b.Field(2).(*array.StringBuilder).AppendValues([]string{"p", "q", "r", "s", "t"}, nil)
+	b.Field(3).(*array.Int64Builder).AppendValues([]int64{1, 1, 2, 1, 7}, nil)
+	rec := b.NewRecord()
+	defer rec.Release()
+	df, err := NewDataFrameFromRecord(pool, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	for _, tc := range []struct {
+		aggs []Aggregation
+		want string
+	}{
+		{
+			[]Aggregation{Sum("x").As("x_sum"), Mean("x").As("x_avg"), CountDistinct("y")},
+			`rec[0]["k"]: ["a" "b" "c"]
+rec[0]["x_sum"]: [4 6 5]
+rec[0]["x_avg"]: [2 3 5]
+rec[0]["y_count_distinct"]: [2 1 1]
+`,
+		},
+		{
+			[]Aggregation{Max(AllNumeric), Count("s")},
+			`rec[0]["k"]: ["a" "b" "c"]
+rec[0]["x_max"]: [3 4 5]
+rec[0]["y_max"]: [2 1 7]
+rec[0]["s_count"]: [2 2 1]
+`,
+		},
+	} {
+		got, err := df.GroupBy("k").Agg(tc.aggs...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := got.Display(-1); s != tc.want {
+			t.Errorf("\ngot=\n%v\nwant=\n%v", s, tc.want)
+		}
+		got.Release()
+	}
+
+	for _, aggs := range [][]Aggregation{
+		{Sum("x"), Sum("x")},
+		{Sum("x"), Min("y").As("x_sum")},
+		{Sum(AllNumeric).As("total")},
+		{Sum("missing")},
+	} {
+		if _, err := df.GroupBy("k").Agg(aggs...); err == nil {
+			t.Errorf("expected an error aggregating %v", aggs)
+		}
+	}
+}
+
+// lockedAllocator makes an allocator safe for concurrent use.
+type lockedAllocator struct {
+	mu  sync.Mutex
+	mem memory.Allocator
+}
+
+func (a *lockedAllocator) Allocate(size int) []byte {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.mem.Allocate(size)
+}
+
+func (a *lockedAllocator) Reallocate(size int, b []byte) []byte {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.mem.Reallocate(size, b)
+}
+
+func (a *lockedAllocator) Free(b []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.mem.Free(b)
+}
+
+func TestGroupByApply(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+	mem := &lockedAllocator{mem: pool}
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "k", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "x", Type: arrow.PrimitiveTypes.Int64},
+	}, nil)
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+	b.Field(0).(*array.StringBuilder).AppendValues([]string{"a", "b", "a", "", "b", "c"}, []bool{true, true, true, false, true, true})
+	b.Field(1).(*array.Int64Builder).AppendValues([]int64{1, 2, 3, 4, 5, 6}, nil)
+	rec := b.NewRecord()
+	defer rec.Release()
+	df, err := NewDataFrameFromRecord(mem, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	// Keep the last row of every group but c, checking the key of the group.
+	last := func(key Row, group *DataFrame) (*DataFrame, error) {
+		want, err := compute.ScalarAt(group.Column("k").Data().Chunk(0), 0)
+		if err != nil {
+			return nil, err
+		}
+		if fmt.Sprint(key["k"]) != fmt.Sprint(want) {
+			return nil, fmt.Errorf("got key %v for group of %v", key["k"], want)
+		}
+		if s, ok := key["k"].(object.String); ok && s.Value() == "c" {
+			return nil, nil
+		}
+		return group.Slice(group.NumRows()-1, group.NumRows())
+	}
+	for _, parallelism := range []int{1, 3} {
+		got, err := df.GroupBy("k").Apply(last, WithParallelism(parallelism))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The chunks of the results are chained.
+		want := `[["a"] ["b"] [(null)]] [[3] [5] [4]]`
+		if s := fmt.Sprint(got.Column("k").Data().Chunks(), got.Column("x").Data().Chunks()); s != want {
+			t.Errorf("parallelism=%d: got=%s, want=%s", parallelism, s, want)
+		}
+		got.Release()
+	}
+
+	_, err = df.GroupBy("k").Apply(func(key Row, group *DataFrame) (*DataFrame, error) {
+		if s, ok := key["k"].(object.String); ok && s.Value() == "b" {
+			return nil, fmt.Errorf("failed on b")
+		}
+		group.Retain()
+		return group, nil
+	}, WithParallelism(2))
+	if err == nil || err.Error() != "failed on b" {
+		t.Fatalf("got error %v", err)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+	mem := &lockedAllocator{mem: pool}
+
+	// Every version v of the DataFrame holds n rows of value v.
+	const n = 100
+	schema := arrow.NewSchema([]arrow.Field{{Name: "v", Type: arrow.PrimitiveTypes.Int64}}, nil)
+	newVersion := func(v int64) *DataFrame {
+		b := array.NewRecordBuilder(mem, schema)
+		defer b.Release()
+		for i := 0; i < n; i++ {
+			b.Field(0).(*array.Int64Builder).Append(v)
+		}
+		rec := b.NewRecord()
+		defer rec.Release()
+		df, err := NewDataFrameFromRecord(mem, rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return df
+	}
+
+	c := NewCatalog()
+	defer c.Close()
+	df := newVersion(0)
+	if err := c.Register("t", df); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Register("t", df); err == nil {
+		t.Fatal("expected an error registering t twice")
+	}
+	df.Release()
+
+	// Readers check that the versions they get are whole while a writer
+	// replaces them.
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				df, ok := c.Get("t")
+				if !ok {
+					errs <- errors.New("t is not registered")
+					return
+				}
+				arr := df.Column("v").Data().Chunk(0).(*array.Int64)
+				for j := 1; j < arr.Len(); j++ {
+					if arr.Value(j) != arr.Value(0) {
+						errs <- fmt.Errorf("version %d holds %d", arr.Value(0), arr.Value(j))
+						break
+					}
+				}
+				df.Release()
+			}
+		}()
+	}
+	for v := int64(1); v <= 20; v++ {
+		df := newVersion(v)
+		if err := c.Replace("t", df); err != nil {
+			t.Fatal(err)
+		}
+		df.Release()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	cur, ok := c.Get("t")
+	if !ok {
+		t.Fatal("t is not registered")
+	}
+	next := newVersion(21)
+	stale := newVersion(22)
+	if swapped, err := c.CompareAndSwap("t", cur, next); err != nil || !swapped {
+		t.Fatalf("got swapped=%v (%v), want=true", swapped, err)
+	}
+	if swapped, err := c.CompareAndSwap("t", cur, stale); err != nil || swapped {
+		t.Fatalf("got swapped=%v (%v), want=false", swapped, err)
+	}
+	cur.Release()
+	next.Release()
+	stale.Release()
+
+	u := newVersion(0)
+	if err := c.Register("u", u); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := c.Names(), []string{"t", "u"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got=%v, want=%v", got, want)
+	}
+	c.Drop("u")
+	// The reference of the Catalog is gone, not that of the caller.
+	if got := u.NumRows(); got != n {
+		t.Fatalf("got %d rows, want=%d", got, n)
+	}
+	u.Release()
+	if got, want := c.Names(), []string{"t"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got=%v, want=%v", got, want)
+	}
+	snapshot := c.Snapshot()
+	if got := snapshot["t"].Column("v").Data().Chunk(0).(*array.Int64).Value(0); got != 21 {
+		t.Fatalf("got version %d, want=21", got)
+	}
+	for _, df := range snapshot {
+		df.Release()
+	}
+	if c.Drop("u") {
+		t.Fatal("u was dropped twice")
+	}
+}
+
+func TestCompact(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+	mem := &lockedAllocator{mem: pool}
+
+	// Chunks of 2, 2, 2, 2, 2, 0, 7 and 1 rows, as streaming appends leave them.
+	sizes := []int{2, 2, 2, 2, 2, 0, 7, 1}
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	var recs []array.Record
+	var want []string
+	row := 0
+	for _, size := range sizes {
+		b := array.NewRecordBuilder(mem, schema)
+		for i := 0; i < size; i++ {
+			b.Field(0).(*array.Int64Builder).Append(int64(row))
+			if row%3 == 0 {
+				b.Field(1).(*array.StringBuilder).AppendNull()
+				want = append(want, fmt.Sprintf("%d:(null)", row))
+			} else {
+				b.Field(1).(*array.StringBuilder).Append(fmt.Sprint("n", row))
+				want = append(want, fmt.Sprintf("%d:n%d", row, row))
+			}
+			row++
+		}
+		recs = append(recs, b.NewRecord())
+		b.Release()
+	}
+	tbl := array.NewTableFromRecords(schema, recs)
+	for _, rec := range recs {
+		rec.Release()
+	}
+	df, err := NewDataFrameFromTable(mem, tbl)
+	tbl.Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	check := func(df *DataFrame, wantSizes []int) {
+		t.Helper()
+		for _, col := range df.Columns() {
+			var got []int
+			for _, chunk := range col.Data().Chunks() {
+				got = append(got, chunk.Len())
+			}
+			if !reflect.DeepEqual(got, wantSizes) {
+				t.Fatalf("%s: got chunks of %v rows, want=%v", col.Name(), got, wantSizes)
+			}
+		}
+		var ids []int64
+		for _, chunk := range df.Column("id").Data().Chunks() {
+			ids = append(ids, chunk.(*array.Int64).Int64Values()...)
+		}
+		var got []string
+		for _, chunk := range df.Column("name").Data().Chunks() {
+			arr := chunk.(*array.String)
+			for j := 0; j < arr.Len(); j++ {
+				name := "(null)"
+				if arr.IsValid(j) {
+					name = arr.Value(j)
+				}
+				got = append(got, fmt.Sprintf("%d:%s", ids[len(got)], name))
+			}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got=%v, want=%v", got, want)
+		}
+	}
+
+	compacted, err := df.Compact(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer compacted.Release()
+	check(compacted, []int{4, 4, 2, 7, 1})
+
+	if _, err := df.Compact(0); err == nil {
+		t.Fatal("expected an error compacting to chunks of 0 rows")
+	}
+
+	// Compacting in the background swaps the DataFrame of a Catalog.
+	c := NewCatalog()
+	defer c.Close()
+	if err := c.Register("t", df); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-c.Compact("t", 100); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := c.Get("t")
+	if !ok {
+		t.Fatal("t is not registered")
+	}
+	defer got.Release()
+	check(got, []int{18})
+	check(df, []int{2, 2, 2, 2, 2, 0, 7, 1})
+	if err := <-c.Compact("u", 100); err == nil {
+		t.Fatal("expected an error compacting an unknown DataFrame")
+	}
+}
+
+// countingSource is a pushdown.Source of records in memory counting the
+// reads of every column.
+type countingSource struct {
+	mem   memory.Allocator
+	recs  []array.Record
+	reads map[string]int
+}
+
+func (s *countingSource) Read(ro pushdown.ReadOptions) (array.RecordReader, error) {
+	for _, name := range ro.Columns {
+		s.reads[name]++
+	}
+	rdr, err := array.NewRecordReader(s.recs[0].Schema(), s.recs)
+	if err != nil {
+		return nil, err
+	}
+	defer rdr.Release()
+	return pushdown.NewReader(s.mem, rdr, ro)
+}
+
+func TestLazyFrame(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "a", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "b", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "c", Type: arrow.BinaryTypes.String},
+	}, nil)
+	src := &countingSource{mem: pool, reads: make(map[string]int)}
+	for r := 0; r < 2; r++ {
+		b := array.NewRecordBuilder(pool, schema)
+		for i := 0; i < 1000; i++ {
+			b.Field(0).(*array.Int64Builder).Append(int64(i))
+			b.Field(1).(*array.Float64Builder).Append(float64(i) / 2)
+			b.Field(2).(*array.StringBuilder).Append(fmt.Sprint(i))
+		}
+		src.recs = append(src.recs, b.NewRecord())
+		b.Release()
+	}
+	defer func() {
+		for _, rec := range src.recs {
+			rec.Release()
+		}
+	}()
+
+	lazy, err := NewLazyFrameFromSource(pool, src, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lazy.Release()
+	if got := lazy.Loaded(); len(got) != 0 {
+		t.Fatalf("got %v loaded before any access", got)
+	}
+
+	df, err := lazy.Select("c", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := df.ColumnNames(), []string{"c", "a"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got=%v, want=%v", got, want)
+	}
+	if got, want := df.NumRows(), int64(2000); got != want {
+		t.Fatalf("got %d rows, want=%d", got, want)
+	}
+	df.Release()
+	col, err := lazy.Column("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	col.Release()
+	if got, want := src.reads, map[string]int{"a": 1, "c": 1}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got reads %v, want=%v", got, want)
+	}
+	if got, want := lazy.Loaded(), []string{"a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got=%v, want=%v", got, want)
+	}
+	usage := lazy.MemoryUsage()
+	if len(usage.Columns) != 2 || usage.Total() == 0 {
+		t.Fatalf("got usage %v", usage.Report(0))
+	}
+
+	if !lazy.Evict("a") || lazy.Evict("a") {
+		t.Fatal("a was not evicted once")
+	}
+	if _, err := lazy.Select("d"); err == nil {
+		t.Fatal("expected an error selecting an unknown column")
+	}
+
+	// Under a memory limit holding one column, loading another evicts the
+	// column used least recently.
+	limited, err := NewLazyFrameFromSource(pool, src, schema, WithMemoryLimit(20000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer limited.Release()
+	for _, name := range []string{"a", "b", "a"} {
+		col, err := limited.Column(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		col.Release()
+		if got, want := limited.Loaded(), []string{name}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got=%v, want=%v", got, want)
+		}
+	}
+	if got, want := src.reads["a"], 3; got != want {
+		t.Fatalf("got %d reads of a, want=%d", got, want)
+	}
+}
+
+func TestPage(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "group", Type: arrow.BinaryTypes.String},
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+	}, nil)
+	newFrame := func(rows map[int64]string) *DataFrame {
+		b := array.NewRecordBuilder(pool, schema)
+		defer b.Release()
+		for id := int64(0); id < 20; id++ {
+			if group, ok := rows[id]; ok {
+				b.Field(0).(*array.StringBuilder).Append(group)
+				b.Field(1).(*array.Int64Builder).Append(id)
+			}
+		}
+		rec := b.NewRecord()
+		defer rec.Release()
+		df, err := NewDataFrameFromRecord(pool, rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return df
+	}
+	ids := func(df *DataFrame) []int64 {
+		var ids []int64
+		for _, chunk := range df.Column("id").Data().Chunks() {
+			ids = append(ids, chunk.(*array.Int64).Int64Values()...)
+		}
+		return ids
+	}
+
+	rows := map[int64]string{0: "b", 1: "a", 2: "c", 3: "a", 4: "b", 5: "c", 6: "a", 7: "b"}
+	df := newFrame(rows)
+	defer df.Release()
+
+	for _, tc := range []struct {
+		offset, limit int64
+		want          []int64
+	}{
+		{0, 3, []int64{0, 1, 2}},
+		{6, 3, []int64{6, 7}},
+		{8, 3, nil},
+		{20, 3, nil},
+		{2, 0, nil},
+	} {
+		page, err := df.Page(tc.offset, tc.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(page); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Page(%d, %d) got %v, want=%v", tc.offset, tc.limit, got, tc.want)
+		}
+		page.Release()
+	}
+	if _, err := df.Page(-1, 3); err == nil {
+		t.Fatal("expected an error for a negative offset")
+	}
+
+	keys := []string{"group", "id"}
+	orders := []compute.SortOrder{compute.Ascending, compute.Descending}
+	p, err := NewPaginator(df, keys, orders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Release()
+
+	var got []int64
+	var cursors []string
+	cursor := ""
+	for {
+		page, next, err := p.Page(cursor, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, ids(page)...)
+		page.Release()
+		if next == "" {
+			break
+		}
+		cursors = append(cursors, next)
+		cursor = next
+	}
+	if want := []int64{6, 3, 1, 7, 4, 0, 5, 2}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want=%v", got, want)
+	}
+	if len(cursors) != 2 {
+		t.Fatalf("got %d cursors, want=2", len(cursors))
+	}
+
+	// Rows added and removed before the cursor do not shift the next page.
+	rows[9], rows[10] = "a", "b"
+	delete(rows, 3)
+	refreshed := newFrame(rows)
+	defer refreshed.Release()
+	rp, err := NewPaginator(refreshed, keys, orders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rp.Release()
+	page, _, err := rp.Page(cursors[0], 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ids(page), []int64{10, 7, 4}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v after refresh, want=%v", got, want)
+	}
+	page.Release()
+
+	if _, _, err := p.Page("not a cursor", 3); err == nil {
+		t.Fatal("expected an error for an invalid cursor")
+	}
+	other, err := NewPaginator(df, []string{"id"}, []compute.SortOrder{compute.Ascending})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Release()
+	if _, _, err := other.Page(cursors[0], 3); err == nil {
+		t.Fatal("expected an error for a cursor of other keys")
+	}
+}
+
+// newCursorPaginator returns a Paginator of rows keyed by columns of every kind
+// of cursor value, nulls and a dictionary included, and its number of rows.
+func newCursorPaginator(t testing.TB, pool memory.Allocator) (*Paginator, int) {
+	t.Helper()
+	dictType := &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int8, ValueType: arrow.BinaryTypes.String}
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "s", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "b", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+		{Name: "f", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "ts", Type: arrow.FixedWidthTypes.Timestamp_us},
+		{Name: "cat", Type: dictType, Nullable: true},
+	}, nil)
+
+	s := array.NewStringBuilder(pool)
+	defer s.Release()
+	b := array.NewBooleanBuilder(pool)
+	defer b.Release()
+	f := array.NewFloat64Builder(pool)
+	defer f.Release()
+	ts := array.NewTimestampBuilder(pool, arrow.FixedWidthTypes.Timestamp_us.(*arrow.TimestampType))
+	defer ts.Release()
+	idx := array.NewInt8Builder(pool)
+	defer idx.Release()
+	n := 24
+	for i := 0; i < n; i++ {
+		if i%5 == 0 {
+			s.AppendNull()
+		} else {
+			s.Append(strings.Repeat("x", i%3))
+		}
+		if i%7 == 0 {
+			b.AppendNull()
+		} else {
+			b.Append(i%2 == 0)
+		}
+		if i%4 == 0 {
+			f.AppendNull()
+		} else {
+			f.Append(float64(i%3) - 0.5)
+		}
+		ts.Append(arrow.Timestamp(i))
+		if i%6 == 0 {
+			idx.AppendNull()
+		} else {
+			idx.Append(int8(i % 2))
+		}
+	}
+	dictValues := array.NewStringBuilder(pool)
+	defer dictValues.Release()
+	dictValues.AppendValues([]string{"y", "z"}, nil)
+
+	values := dictValues.NewArray()
+	defer values.Release()
+	indices := idx.NewArray()
+	defer indices.Release()
+	arrs := []array.Interface{s.NewArray(), b.NewArray(), f.NewArray(), ts.NewArray(), array.NewDictionaryArray(dictType, indices, values)}
+	defer func() {
+		for _, arr := range arrs {
+			arr.Release()
+		}
+	}()
+	df, err := NewDataFrame(pool, schema, arrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	p, err := NewPaginator(df, []string{"s", "cat", "b", "f", "ts"},
+		[]compute.SortOrder{compute.Ascending, compute.Descending, compute.Ascending, compute.Descending, compute.Ascending})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, n
+}
+
+func TestPaginatorCursorKeys(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	p, n := newCursorPaginator(t, pool)
+	defer p.Release()
+
+	// Pages of a single row resume after every kind of key.
+	var got []int64
+	cursor := ""
+	for {
+		page, next, err := p.Page(cursor, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, chunk := range page.Column("ts").Data().Chunks() {
+			for _, v := range chunk.(*array.Timestamp).TimestampValues() {
+				got = append(got, int64(v))
+			}
+		}
+		page.Release()
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	want := p.indices.Int64Values()
+	if !reflect.DeepEqual(got, want) || len(got) != n {
+		t.Fatalf("got %v, want=%v", got, want)
+	}
+}
+
+func TestPaginatorMalformedCursors(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	p, _ := newCursorPaginator(t, pool)
+	defer p.Release()
+	page, cursor, err := p.Page("", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page.Release()
+	valid, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := base64.RawURLEncoding.EncodeToString
+
+	for name, cursor := range map[string]string{
+		"not base64":     "not a cursor!",
+		"version only":   encode(valid[:1]),
+		"version":        encode(append([]byte{2}, valid[1:]...)),
+		"fingerprint":    encode(append([]byte{1, 0, 0, 0, 0}, valid[5:]...)),
+		"trailing bytes": encode(append(append([]byte(nil), valid...), 0)),
+		"null flag":      encode(append(append([]byte(nil), valid[:5]...), 7)),
+		"huge length":    encode(append(append([]byte(nil), valid[:5]...), 1, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f)),
+		"too long":       strings.Repeat("A", maxCursorLen+1),
+	} {
+		if _, _, err := p.Page(cursor, 5); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	// The empty cursor is the one of the first page.
+	for i := 1; i < len(valid); i++ {
+		if _, _, err := p.Page(encode(valid[:i]), 5); err == nil {
+			t.Errorf("expected an error for a cursor truncated to %d bytes", i)
+		}
+	}
+
+	// Altered cursors fail or resume at some row, they never panic.
+	for i := range valid {
+		for _, v := range []byte{0, 1, 2, 0x7f, 0x80, 0xff} {
+			buf := append([]byte(nil), valid...)
+			buf[i] = v
+			if page, _, err := p.Page(encode(buf), 5); err == nil {
+				page.Release()
+			}
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/internal/hashing"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+const (
+	// DiffBeforeSuffix and DiffAfterSuffix are appended to the names of the
+	// columns of FrameDiff.Changed holding the old and the new values.
+	DiffBeforeSuffix = "_before"
+	DiffAfterSuffix  = "_after"
+)
+
+// FrameDiff holds the differences between two versions of a DataFrame, as
+// found by Diff.
+type FrameDiff struct {
+	// Added holds the rows of the new DataFrame whose keys are not in the
+	// old one, in their order.
+	Added *DataFrame
+	// Removed holds the rows of the old DataFrame whose keys are not in the
+	// new one, in their order.
+	Removed *DataFrame
+	// Changed holds the rows whose keys are in both DataFrames but whose
+	// other values differ, in the order of the old DataFrame: the key
+	// columns followed, for each other column, by its old and its new values
+	// in columns suffixed with DiffBeforeSuffix and DiffAfterSuffix.
+	Changed *DataFrame
+}
+
+// Release releases the DataFrames of d.
+func (d *FrameDiff) Release() {
+	d.Added.Release()
+	d.Removed.Release()
+	d.Changed.Release()
+}
+
+// Diff compares the rows of two versions of a DataFrame identified by the
+// values of the key columns, which must be unique on each side, and returns
+// the rows that were added, removed or changed from old to new. old and new
+// must have the same schema.
+//
+// The keys of both sides are encoded by a shared compute.KeyEncoder, as in
+// compute.HashJoin except that null keys match each other, and the other
+// values of the matching rows are compared through the hashes of the rows.
+func Diff(old, new *DataFrame, keys ...string) (*FrameDiff, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("dataframe: Diff needs at least one key column")
+	}
+	if !old.schema.Equal(new.schema) {
+		return nil, fmt.Errorf("dataframe: cannot diff DataFrames of schemas %s and %s", old.schema, new.schema)
+	}
+	mem := old.mem
+
+	isKey := make(map[string]bool, len(keys))
+	oldKeys := make([]*array.Column, len(keys))
+	newKeys := make([]*array.Column, len(keys))
+	for i, name := range keys {
+		if oldKeys[i] = old.Column(name); oldKeys[i] == nil {
+			return nil, fmt.Errorf("dataframe: key column %q not found", name)
+		}
+		newKeys[i] = new.Column(name)
+		isKey[name] = true
+	}
+
+	enc := compute.NewKeyEncoder(true)
+	oldCodes, err := enc.Encode(oldKeys...)
+	if err != nil {
+		return nil, err
+	}
+	if enc.NumKeys() != len(oldCodes) {
+		return nil, fmt.Errorf("dataframe: keys %v of the old DataFrame are not unique", keys)
+	}
+	newCodes, err := enc.Encode(newKeys...)
+	if err != nil {
+		return nil, err
+	}
+	// newRowOf[code] is the row of new with the key of the given code, -1
+	// when there is none.
+	newRowOf := make([]int64, enc.NumKeys())
+	for i := range newRowOf {
+		newRowOf[i] = -1
+	}
+	for n, code := range newCodes {
+		if newRowOf[code] >= 0 {
+			return nil, fmt.Errorf("dataframe: keys %v of the new DataFrame are not unique", keys)
+		}
+		newRowOf[code] = int64(n)
+	}
+
+	oldHashes, err := rowHashes(old, isKey)
+	if err != nil {
+		return nil, err
+	}
+	newHashes, err := rowHashes(new, isKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed, changedOld, changedNew []int64
+	for o, code := range oldCodes {
+		n := newRowOf[code]
+		switch {
+		case n < 0:
+			removed = append(removed, int64(o))
+		case oldHashes[o] != newHashes[n]:
+			changedOld = append(changedOld, int64(o))
+			changedNew = append(changedNew, n)
+		}
+	}
+	var added []int64
+	for n, code := range newCodes {
+		if int(code) >= len(oldCodes) {
+			added = append(added, int64(n))
+		}
+	}
+
+	diff := &FrameDiff{}
+	if diff.Added, err = takeRows(mem, new, added); err != nil {
+		return nil, err
+	}
+	if diff.Removed, err = takeRows(mem, old, removed); err != nil {
+		diff.Added.Release()
+		return nil, err
+	}
+	if diff.Changed, err = changedRows(mem, old, new, keys, isKey, changedOld, changedNew); err != nil {
+		diff.Added.Release()
+		diff.Removed.Release()
+		return nil, err
+	}
+	return diff, nil
+}
+
+// rowHashes returns the hash of the values of the columns of df other than
+// the keys, for every row.
+func rowHashes(df *DataFrame, isKey map[string]bool) ([]uint64, error) {
+	hashes := make([]uint64, df.NumRows())
+	for i := range df.cols {
+		col := &df.cols[i]
+		if isKey[col.Name()] {
+			continue
+		}
+		row := 0
+		for _, chunk := range col.Data().Chunks() {
+			err := hashing.Array(chunk, func(i int, h uint64, _ bool) {
+				hashes[row+i] = hashing.Combine(hashes[row+i], h)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("dataframe: cannot diff column %q: %w", col.Name(), err)
+			}
+			row += chunk.Len()
+		}
+	}
+	return hashes, nil
+}
+
+// takeRows returns the given rows of df.
+func takeRows(mem memory.Allocator, df *DataFrame, rows []int64) (*DataFrame, error) {
+	indices := newIndices(mem, rows)
+	defer indices.Release()
+	cols := make([]array.Column, 0, len(df.cols))
+	defer func() {
+		for i := range cols {
+			cols[i].Release()
+		}
+	}()
+	for i := range df.cols {
+		taken, err := compute.Take(mem, &df.cols[i], indices)
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, *taken)
+	}
+	return NewDataFrameFromShape(mem, cols, int64(len(rows)))
+}
+
+// changedRows returns the keys of the changed rows of new, followed by the
+// old and the new values of the other columns.
+func changedRows(mem memory.Allocator, old, new *DataFrame, keys []string, isKey map[string]bool, oldRows, newRows []int64) (*DataFrame, error) {
+	oldTaken := newIndices(mem, oldRows)
+	defer oldTaken.Release()
+	newTaken := newIndices(mem, newRows)
+	defer newTaken.Release()
+
+	cols := make([]array.Column, 0, 2*len(new.cols)-len(keys))
+	defer func() {
+		for i := range cols {
+			cols[i].Release()
+		}
+	}()
+	take := func(col *array.Column, indices *array.Int64, suffix string) error {
+		taken, err := compute.Take(mem, col, indices)
+		if err != nil {
+			return err
+		}
+		defer taken.Release()
+		field := col.Field()
+		field.Name += suffix
+		cols = append(cols, *array.NewColumn(field, taken.Data()))
+		return nil
+	}
+
+	for _, name := range keys {
+		if err := take(new.Column(name), newTaken, ""); err != nil {
+			return nil, err
+		}
+	}
+	for i := range new.cols {
+		name := new.cols[i].Name()
+		if isKey[name] {
+			continue
+		}
+		if err := take(old.Column(name), oldTaken, DiffBeforeSuffix); err != nil {
+			return nil, err
+		}
+		if err := take(&new.cols[i], newTaken, DiffAfterSuffix); err != nil {
+			return nil, err
+		}
+	}
+	return NewDataFrameFromShape(mem, cols, int64(len(oldRows)))
+}
@@ -26,6 +26,150 @@ the corresponding entry in the array is valid (not null).
 Any DataFrames created should be released using Release() to decrement the reference
 and free up the memory managed by the Arrow implementation.
 
+Operations share the columns they do not change with the DataFrame they were
+called on, taking a reference on them, and build new columns for the others.
+Columns are never modified in place, so a shared column stays valid until the
+last DataFrame holding it is released.
+
+Indexes
+
+SetIndex attaches an Index to a DataFrame, locating its rows by the values of
+a column instead of their positions. Sorted integer and timestamp columns make
+an OrderedIndex searched in O(log n), string columns a hash table:
+
+	series, err := df.SetIndex("ts")
+	if err != nil {
+		return err
+	}
+	defer series.Release()
+	row, err := series.At(time.Now())                          // the latest row
+	hourly, err := series.Resample(time.Hour, compute.AggMean) // a row per hour
+
+Several columns make a MultiIndex, as for panel data keyed by date and symbol.
+Loc looks up a full key or a prefix of the levels, and Xs selects the rows
+holding a key at any level:
+
+	panel, err := df.SetIndex("date", "symbol")
+	...
+	aapl, err := panel.Xs("symbol", "AAPL")   // indexed by date
+	pnl, err := positions.Arithmetic(dataframe.Mul, prices) // aligned on date and symbol
+
+DataFrames holding different keys are combined through their indexes rather
+than their positions: Reindex conforms a DataFrame to the keys of another
+index, filling the missing keys with nulls or the previous or next values, and
+Align returns two DataFrames holding the union, the intersection or the left
+keys of both.
+
+Loc, LocRange, At, Xs, IndexJoin, Arithmetic, Reindex, Align and Resample
+return indexed DataFrames, the other operations drop the index.
+
+Sessionize splits event streams into sessions without an index: the rows of
+each key, in time order, start a new session once they follow the previous
+row by more than a gap, and the session ids land in a SessionColumn column:
+
+	sessions, err := events.Sessionize([]string{"user"}, "ts", 30*time.Minute)
+
+GroupBy aggregates the rows of each distinct key with any number of
+aggregations per column, named by As or after their column and aggregate,
+AllNumeric standing for every numeric column:
+
+	stats, err := sales.GroupBy("store").Agg(
+		dataframe.Sum("amount").As("total"),
+		dataframe.Mean("amount"),            // amount_mean
+		dataframe.CountDistinct("customer"), // customer_count_distinct
+	)
+
+Apply runs a function on the rows of each group instead, such as scoring a
+model per entity, and concatenates the DataFrames it returns.
+
+Validation
+
+Validate checks that the columns of a DataFrame match its schema and that the
+buffers of their chunks are consistent, with array.ValidateFull. Run it after
+building a DataFrame from buffers without a builder, such as zero-copy reads.
+
+	if err := dataframe.Validate(df, dataframe.WithUTF8Validation()); err != nil {
+		return err
+	}
+
+Diffing
+
+Diff compares two versions of a DataFrame whose rows are identified by key
+columns, and returns the rows added, removed and changed, the changed ones
+with their values before and after. It suits auditing pipelines that
+republish corrected datasets.
+
+	diff, err := dataframe.Diff(published, corrected, "id")
+	if err != nil {
+		return err
+	}
+	defer diff.Release()
+
+Rings
+
+A Ring is an append-only table keeping only its last rows, by count or by
+age along a timestamp column, for in-process telemetry. The chunks it drops
+give their buffers back to the memory.PoolAllocator of the Ring, where the
+chunks appended next find them.
+
+	ring, err := dataframe.NewRing(mem, schema, dataframe.WithRetention("ts", time.Hour))
+	if err != nil {
+		return err
+	}
+	defer ring.Release()
+	err = ring.Append(rec)
+	df, err := ring.Frame()
+
+A Catalog registers DataFrames by name for servers answering queries on
+them. Get hands out a reference of its own, so replacing a DataFrame while
+queries use the previous one is safe: it is freed once they release it.
+
+	df, ok := catalog.Get("sales")
+	if !ok {
+		return errNotFound
+	}
+	defer df.Release()
+
+	err = catalog.Replace("sales", reloaded)
+
+The small chunks left by streaming appends slow scans down. Compact merges
+them into larger chunks, and Catalog.Compact does so in the background,
+registering the compacted DataFrame once done:
+
+	err := <-catalog.Compact("sales", 64<<10)
+
+A LazyFrame reads its columns when they are first accessed, from a
+ColumnLoader per column or from a pushdown.Source such as an IPC file, so that
+queries on very wide tables only load the columns they touch:
+
+	lazy, err := dataframe.NewLazyFrameFromSource(mem, pushdown.NewIPCFile(mem, f), schema, dataframe.WithMemoryLimit(1<<30))
+	if err != nil {
+		return err
+	}
+	defer lazy.Release()
+	df, err := lazy.Select("region", "units")
+
+Page returns a page of rows by offset. A Paginator instead serves pages in the
+order of key columns, resuming from a cursor that encodes the keys of the last
+row served, so pages stay stable as the DataFrame is refreshed between them:
+
+	p, err := dataframe.NewPaginator(df, []string{"region", "id"}, []compute.SortOrder{compute.Ascending, compute.Ascending})
+	if err != nil {
+		return err
+	}
+	defer p.Release()
+	page, next, err := p.Page(cursor, 50)
+
+Reference Auditing
+
+Building with the refaudit tag records the stack trace of every reference
+taken on a DataFrame and on the data of its columns. LeakReport lists the
+references still held along with the code that took them, and releasing a
+DataFrame too many times panics with the stack trace of the release that
+freed it.
+
+	go test -tags refaudit ./...
+
 Getting Started
 
 Look in dataframe_tests.go for examples to get started.
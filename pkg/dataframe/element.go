@@ -17,7 +17,7 @@ package dataframe
 import (
 	"fmt"
 
-	"github.com/apache/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow"
 )
 
 // Element is an interface for Elements within a Column.
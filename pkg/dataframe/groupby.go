@@ -0,0 +1,389 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/object"
+)
+
+// AllNumeric is the column name of an Aggregation applied to every numeric
+// column of the DataFrame other than the group keys, such as Mean(AllNumeric).
+const AllNumeric = "*"
+
+// Aggregation is an aggregate computed over the groups of a GroupBy, built by
+// Count, Sum, Mean, Min, Max, List or CountDistinct. Its output column is
+// named after the column and the aggregate, such as "x_sum", unless As names it.
+type Aggregation struct {
+	column string
+	kind   compute.AggregateKind
+	name   string
+}
+
+// Count counts the non-null values of the named column.
+func Count(column string) Aggregation { return Aggregation{column: column, kind: compute.AggCount} }
+
+// Sum sums the non-null values of the named column.
+func Sum(column string) Aggregation { return Aggregation{column: column, kind: compute.AggSum} }
+
+// Mean averages the non-null values of the named column.
+func Mean(column string) Aggregation { return Aggregation{column: column, kind: compute.AggMean} }
+
+// Min keeps the smallest non-null value of the named column.
+func Min(column string) Aggregation { return Aggregation{column: column, kind: compute.AggMin} }
+
+// Max keeps the largest non-null value of the named column.
+func Max(column string) Aggregation { return Aggregation{column: column, kind: compute.AggMax} }
+
+// List collects the values of the named column to a list.
+func List(column string) Aggregation { return Aggregation{column: column, kind: compute.AggList} }
+
+// CountDistinct counts the distinct non-null values of the named column.
+func CountDistinct(column string) Aggregation {
+	return Aggregation{column: column, kind: compute.AggCountDistinct}
+}
+
+// As returns the Aggregation with its output column named name.
+func (a Aggregation) As(name string) Aggregation {
+	a.name = name
+	return a
+}
+
+// outputName returns the name of the output column of a for the named column.
+func (a Aggregation) outputName(column string) string {
+	if a.name != "" {
+		return a.name
+	}
+	return fmt.Sprintf("%s_%s", column, a.kind)
+}
+
+// Grouped is a DataFrame grouped by key columns, aggregated by Agg.
+type Grouped struct {
+	df   *DataFrame
+	keys []string
+}
+
+// Agg creates a new DataFrame holding a row per group with the aggregations,
+// see Mutator.GroupBy.
+func (g *Grouped) Agg(aggs ...Aggregation) (*DataFrame, error) {
+	return g.df.mutator.GroupBy(g.keys, aggs...)(g.df)
+}
+
+// GroupBy creates a new DataFrame with a row per distinct value of the keys
+// columns, in the order of their first row, holding the keys followed by a
+// column per aggregation, in the order of aggs. Any number of aggregations may
+// be computed over the same column. An Aggregation of AllNumeric expands to one
+// per numeric column other than the keys, in the order of the DataFrame. Null
+// keys are grouped together. See compute.AggregateGroups for the aggregates.
+func (m *Mutator) GroupBy(keys []string, aggs ...Aggregation) MutationFunc {
+	return func(df *DataFrame) (*DataFrame, error) {
+		keyCols, err := groupKeys(df, keys)
+		if err != nil {
+			return nil, err
+		}
+		isKey := make(map[string]bool, len(keys))
+		for _, name := range keys {
+			isKey[name] = true
+		}
+
+		type output struct {
+			col  *array.Column
+			kind compute.AggregateKind
+			name string
+		}
+		var outputs []output
+		names := make(map[string]bool, len(keys)+len(aggs))
+		for _, name := range keys {
+			names[name] = true
+		}
+		add := func(a Aggregation, col *array.Column) error {
+			name := a.outputName(col.Name())
+			if names[name] {
+				return fmt.Errorf("mutation: GroupBy output column %q is not unique", name)
+			}
+			names[name] = true
+			outputs = append(outputs, output{col: col, kind: a.kind, name: name})
+			return nil
+		}
+		for _, a := range aggs {
+			if a.column != AllNumeric {
+				col := df.Column(a.column)
+				if col == nil {
+					return nil, fmt.Errorf("mutation: column %q is not in DataFrame: (%v)", a.column, df.ColumnNames())
+				}
+				if err := add(a, col); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if a.name != "" {
+				return nil, fmt.Errorf("mutation: an aggregation of every numeric column cannot be named %q", a.name)
+			}
+			cols := df.Columns()
+			for i := range cols {
+				if isKey[cols[i].Name()] || !isNumeric(cols[i].DataType()) {
+					continue
+				}
+				if err := add(a, &cols[i]); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		g, err := compute.GroupRows(keyCols...)
+		if err != nil {
+			return nil, err
+		}
+		first := newIndices(m.mem, g.First)
+		defer first.Release()
+
+		cols := make([]array.Column, 0, len(keyCols)+len(outputs))
+		defer func() {
+			for i := range cols {
+				cols[i].Release()
+			}
+		}()
+		for _, key := range keyCols {
+			col, err := compute.Take(m.mem, key, first)
+			if err != nil {
+				return nil, err
+			}
+			cols = append(cols, *col)
+		}
+		for _, out := range outputs {
+			agg, err := compute.AggregateGroups(m.mem, out.col, g, out.kind)
+			if err != nil {
+				return nil, fmt.Errorf("mutation: %s of column %q: %w", out.kind, out.col.Name(), err)
+			}
+			field := arrow.Field{Name: out.name, Type: agg.DataType(), Nullable: true}
+			cols = append(cols, *array.NewColumn(field, agg.Data()))
+			agg.Release()
+		}
+		return NewDataFrameFromShape(m.mem, cols, int64(g.NumGroups()))
+	}
+}
+
+// isNumeric reports whether dtype is an integer or floating point type.
+func isNumeric(dtype arrow.DataType) bool {
+	switch dtype.ID() {
+	case arrow.INT8, arrow.INT16, arrow.INT32, arrow.INT64,
+		arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64,
+		arrow.FLOAT16, arrow.FLOAT32, arrow.FLOAT64:
+		return true
+	default:
+		return false
+	}
+}
+
+// groupKeys returns the named key columns of df.
+func groupKeys(df *DataFrame, keys []string) ([]*array.Column, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("mutation: GroupBy needs at least one key column")
+	}
+	cols := make([]*array.Column, len(keys))
+	for i, name := range keys {
+		if cols[i] = df.Column(name); cols[i] == nil {
+			return nil, fmt.Errorf("mutation: column %q is not in DataFrame: (%v)", name, df.ColumnNames())
+		}
+	}
+	return cols, nil
+}
+
+// Row holds the values of the key columns of a group by column name, null
+// keys being object.Null.
+type Row map[string]object.Object
+
+// ApplyFunc computes the rows of a group from its key and its rows. The group
+// DataFrame is released when the function returns, so it must be retained to
+// be returned as it is. A nil DataFrame adds no rows.
+type ApplyFunc func(key Row, group *DataFrame) (*DataFrame, error)
+
+// applyConfig are the config params for Grouped.Apply.
+type applyConfig struct {
+	parallelism int
+}
+
+// WithParallelism configures Grouped.Apply to run its function on up to n
+// groups at a time, one by default.
+func WithParallelism(n int) Option {
+	return func(p interface{}) error {
+		o, ok := p.(*applyConfig)
+		if !ok {
+			return fmt.Errorf("cannot apply WithParallelism to: %T", p)
+		}
+		if n <= 0 {
+			return fmt.Errorf("dataframe: parallelism must be positive, got %d", n)
+		}
+		o.parallelism = n
+		return nil
+	}
+}
+
+// Apply calls fn with the key and the rows of every group, for logic the
+// aggregations of Agg cannot express, and returns a DataFrame holding the rows
+// returned by fn for each group in the order of the groups of Agg. The
+// DataFrames returned must have the same schema, and their chunks are used as
+// they are, without copying.
+//
+// With WithParallelism, fn runs on several groups at a time and must be safe
+// for concurrent use, as must the allocator of the DataFrame. The first error
+// returned by fn is returned and the groups not started yet are skipped.
+func (g *Grouped) Apply(fn ApplyFunc, opts ...Option) (*DataFrame, error) {
+	cfg := &applyConfig{parallelism: 1}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	df, mem := g.df, g.df.Allocator()
+	keyCols, err := groupKeys(df, g.keys)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := compute.GroupRows(keyCols...)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([][]int64, groups.NumGroups())
+	for row, id := range groups.IDs {
+		rows[id] = append(rows[id], int64(row))
+	}
+
+	// The keys of the groups are read from their first rows.
+	first := newIndices(mem, groups.First)
+	defer first.Release()
+	keys := make([]Row, groups.NumGroups())
+	for i := range keys {
+		keys[i] = make(Row, len(keyCols))
+	}
+	for _, col := range keyCols {
+		taken, err := compute.Take(mem, col, first)
+		if err != nil {
+			return nil, err
+		}
+		arr := taken.Data().Chunk(0)
+		for i := range keys {
+			if keys[i][col.Name()], err = compute.ScalarAt(arr, i); err != nil {
+				taken.Release()
+				return nil, err
+			}
+		}
+		taken.Release()
+	}
+
+	results := make([]*DataFrame, groups.NumGroups())
+	defer func() {
+		for _, res := range results {
+			if res != nil {
+				res.Release()
+			}
+		}
+	}()
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+	run := func(id int) {
+		if failed() {
+			return
+		}
+		res, err := func() (*DataFrame, error) {
+			indices := newIndices(mem, rows[id])
+			defer indices.Release()
+			group, err := df.Take(indices)
+			if err != nil {
+				return nil, err
+			}
+			defer group.Release()
+			return fn(keys[id], group)
+		}()
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		results[id] = res
+	}
+	sem := make(chan struct{}, cfg.parallelism)
+	for id := range rows {
+		if cfg.parallelism == 1 {
+			run(id)
+			continue
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			run(id)
+		}(id)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return concatFrames(mem, results)
+}
+
+// concatFrames returns a DataFrame holding the rows of the non-nil frames, in
+// order, whose columns chain the chunks of their columns.
+func concatFrames(mem memory.Allocator, frames []*DataFrame) (*DataFrame, error) {
+	var schema *arrow.Schema
+	var rows int64
+	var chunks [][]array.Interface
+	for _, frame := range frames {
+		if frame == nil {
+			continue
+		}
+		if schema == nil {
+			schema = frame.Schema()
+			chunks = make([][]array.Interface, len(schema.Fields()))
+		} else if !schema.Equal(frame.Schema()) {
+			return nil, fmt.Errorf("dataframe: cannot concatenate DataFrames of different schemas: (%v) and (%v)", schema, frame.Schema())
+		}
+		for i, col := range frame.Columns() {
+			chunks[i] = append(chunks[i], col.Data().Chunks()...)
+		}
+		rows += frame.NumRows()
+	}
+	if schema == nil {
+		return NewDataFrameFromShape(mem, nil, 0)
+	}
+
+	cols := make([]array.Column, 0, len(chunks))
+	defer func() {
+		for i := range cols {
+			cols[i].Release()
+		}
+	}()
+	for i, field := range schema.Fields() {
+		chunked := array.NewChunked(field.Type, chunks[i])
+		cols = append(cols, *array.NewColumn(field, chunked))
+		chunked.Release()
+	}
+	return NewDataFrameFromShape(mem, cols, rows)
+}
@@ -0,0 +1,557 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// Index locates the rows of a DataFrame by the values of one of its columns,
+// the keys of the rows. See DataFrame.SetIndex.
+type Index interface {
+	// Columns returns the names of the indexed columns, the levels of the index.
+	Columns() []string
+	// Len returns the number of rows indexed.
+	Len() int64
+	// Lookup returns the positions of the rows holding key, in row order.
+	Lookup(key interface{}) ([]int64, error)
+
+	// key returns the key of row i as accepted by Lookup, or nil if it is null.
+	key(i int64) interface{}
+}
+
+// OrderedIndex is an Index whose keys do not decrease from one row to the next,
+// so the rows holding a range of keys are contiguous.
+type OrderedIndex interface {
+	Index
+	// Range returns the positions beg:end of the rows holding a key k with
+	// lo <= k < hi.
+	Range(lo, hi interface{}) (beg, end int64, err error)
+}
+
+// IntIndex is an OrderedIndex of a column of integers.
+type IntIndex struct {
+	column string
+	keys   []int64
+}
+
+// Columns returns the name of the indexed column.
+func (idx *IntIndex) Columns() []string { return []string{idx.column} }
+
+// Len returns the number of rows indexed.
+func (idx *IntIndex) Len() int64 { return int64(len(idx.keys)) }
+
+// Lookup returns the positions of the rows holding key, which may be any
+// integer type.
+func (idx *IntIndex) Lookup(key interface{}) ([]int64, error) {
+	k, err := intKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return positions(idx.search(k), idx.search(k+1)), nil
+}
+
+// Range returns the positions beg:end of the rows holding a key k with lo <= k < hi.
+func (idx *IntIndex) Range(lo, hi interface{}) (beg, end int64, err error) {
+	l, err := intKey(lo)
+	if err != nil {
+		return 0, 0, err
+	}
+	h, err := intKey(hi)
+	if err != nil {
+		return 0, 0, err
+	}
+	beg, end = idx.search(l), idx.search(h)
+	if end < beg {
+		end = beg
+	}
+	return beg, end, nil
+}
+
+// search returns the position of the first row whose key is not less than k.
+func (idx *IntIndex) search(k int64) int64 {
+	return int64(sort.Search(len(idx.keys), func(i int) bool { return idx.keys[i] >= k }))
+}
+
+func (idx *IntIndex) key(i int64) interface{} { return idx.keys[i] }
+
+// TimestampIndex is an OrderedIndex of a column of timestamps. Its keys are
+// time.Time values.
+type TimestampIndex struct {
+	column string
+	dtype  *arrow.TimestampType
+	keys   []int64 // nanoseconds since the epoch
+}
+
+// Columns returns the name of the indexed column.
+func (idx *TimestampIndex) Columns() []string { return []string{idx.column} }
+
+// Len returns the number of rows indexed.
+func (idx *TimestampIndex) Len() int64 { return int64(len(idx.keys)) }
+
+// Lookup returns the positions of the rows holding the time.Time key.
+func (idx *TimestampIndex) Lookup(key interface{}) ([]int64, error) {
+	k, err := timeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return positions(idx.search(k), idx.search(k+1)), nil
+}
+
+// Range returns the positions beg:end of the rows holding a time k with lo <= k < hi.
+func (idx *TimestampIndex) Range(lo, hi interface{}) (beg, end int64, err error) {
+	l, err := timeKey(lo)
+	if err != nil {
+		return 0, 0, err
+	}
+	h, err := timeKey(hi)
+	if err != nil {
+		return 0, 0, err
+	}
+	beg, end = idx.search(l), idx.search(h)
+	if end < beg {
+		end = beg
+	}
+	return beg, end, nil
+}
+
+// Asof returns the position of the last row holding a time at or before t,
+// or -1 when every row is after t.
+func (idx *TimestampIndex) Asof(t time.Time) int64 {
+	return idx.search(t.UnixNano()+1) - 1
+}
+
+func (idx *TimestampIndex) search(k int64) int64 {
+	return int64(sort.Search(len(idx.keys), func(i int) bool { return idx.keys[i] >= k }))
+}
+
+func (idx *TimestampIndex) key(i int64) interface{} { return time.Unix(0, idx.keys[i]).UTC() }
+
+// StringIndex is an Index of a column of strings, looking keys up in a hash
+// table. The keys may come in any order, and rows with a null key are not
+// indexed.
+type StringIndex struct {
+	column string
+	keys   []string
+	valid  []bool
+	rows   map[string][]int64
+}
+
+// Columns returns the name of the indexed column.
+func (idx *StringIndex) Columns() []string { return []string{idx.column} }
+
+// Len returns the number of rows indexed.
+func (idx *StringIndex) Len() int64 { return int64(len(idx.keys)) }
+
+// Lookup returns the positions of the rows holding the string key.
+func (idx *StringIndex) Lookup(key interface{}) ([]int64, error) {
+	k, ok := key.(string)
+	if !ok {
+		return nil, fmt.Errorf("dataframe: invalid string index key %v (%T)", key, key)
+	}
+	return idx.rows[k], nil
+}
+
+func (idx *StringIndex) key(i int64) interface{} {
+	if !idx.valid[i] {
+		return nil
+	}
+	return idx.keys[i]
+}
+
+// newIndex builds the index of col, whose kind depends on the type of col.
+func newIndex(col *array.Column) (Index, error) {
+	name := col.Name()
+	n := columnLen(*col)
+	chunks := col.Data().Chunks()
+	if col.NullN() > 0 && col.DataType().ID() != arrow.STRING {
+		return nil, fmt.Errorf("dataframe: cannot index column %q holding nulls", name)
+	}
+
+	switch dtype := col.DataType().(type) {
+	case *arrow.Int8Type, *arrow.Int16Type, *arrow.Int32Type, *arrow.Int64Type,
+		*arrow.Uint8Type, *arrow.Uint16Type, *arrow.Uint32Type, *arrow.Uint64Type:
+		idx := &IntIndex{column: name, keys: make([]int64, 0, n)}
+		for _, chunk := range chunks {
+			for i := 0; i < chunk.Len(); i++ {
+				k, err := intKey(intAt(chunk, i))
+				if err != nil {
+					return nil, fmt.Errorf("dataframe: cannot index column %q: %w", name, err)
+				}
+				idx.keys = append(idx.keys, k)
+			}
+		}
+		if err := checkOrdered(name, idx.keys); err != nil {
+			return nil, err
+		}
+		return idx, nil
+
+	case *arrow.TimestampType:
+		scale := unitNanoseconds(dtype.Unit)
+		idx := &TimestampIndex{column: name, dtype: dtype, keys: make([]int64, 0, n)}
+		for _, chunk := range chunks {
+			for _, v := range chunk.(*array.Timestamp).TimestampValues() {
+				idx.keys = append(idx.keys, int64(v)*scale)
+			}
+		}
+		if err := checkOrdered(name, idx.keys); err != nil {
+			return nil, err
+		}
+		return idx, nil
+
+	case *arrow.StringType:
+		idx := &StringIndex{
+			column: name,
+			keys:   make([]string, 0, n),
+			valid:  make([]bool, 0, n),
+			rows:   make(map[string][]int64),
+		}
+		for _, chunk := range chunks {
+			arr := chunk.(*array.String)
+			for i := 0; i < arr.Len(); i++ {
+				row := int64(len(idx.keys))
+				if arr.IsNull(i) {
+					idx.keys = append(idx.keys, "")
+					idx.valid = append(idx.valid, false)
+					continue
+				}
+				k := arr.Value(i)
+				idx.keys = append(idx.keys, k)
+				idx.valid = append(idx.valid, true)
+				idx.rows[k] = append(idx.rows[k], row)
+			}
+		}
+		return idx, nil
+
+	default:
+		return nil, fmt.Errorf("dataframe: cannot index column %q of type %s", name, dtype)
+	}
+}
+
+// checkOrdered returns an error if keys decrease somewhere.
+func checkOrdered(name string, keys []int64) error {
+	for i := 1; i < len(keys); i++ {
+		if keys[i] < keys[i-1] {
+			return fmt.Errorf("dataframe: column %q is not sorted at row %d, cannot index it", name, i)
+		}
+	}
+	return nil
+}
+
+// positions returns the positions beg to end-1.
+func positions(beg, end int64) []int64 {
+	out := make([]int64, 0, end-beg)
+	for i := beg; i < end; i++ {
+		out = append(out, i)
+	}
+	return out
+}
+
+// intAt returns the value at index i of an integer array.
+func intAt(arr array.Interface, i int) interface{} {
+	switch a := arr.(type) {
+	case *array.Int8:
+		return a.Value(i)
+	case *array.Int16:
+		return a.Value(i)
+	case *array.Int32:
+		return a.Value(i)
+	case *array.Int64:
+		return a.Value(i)
+	case *array.Uint8:
+		return a.Value(i)
+	case *array.Uint16:
+		return a.Value(i)
+	case *array.Uint32:
+		return a.Value(i)
+	case *array.Uint64:
+		return a.Value(i)
+	default:
+		return nil
+	}
+}
+
+// intKey converts an integer key to int64.
+func intKey(key interface{}) (int64, error) {
+	switch k := key.(type) {
+	case int:
+		return int64(k), nil
+	case int8:
+		return int64(k), nil
+	case int16:
+		return int64(k), nil
+	case int32:
+		return int64(k), nil
+	case int64:
+		return k, nil
+	case uint8:
+		return int64(k), nil
+	case uint16:
+		return int64(k), nil
+	case uint32:
+		return int64(k), nil
+	case uint:
+		if uint64(k) > math.MaxInt64 {
+			return 0, fmt.Errorf("dataframe: index key %d out of range", k)
+		}
+		return int64(k), nil
+	case uint64:
+		if k > math.MaxInt64 {
+			return 0, fmt.Errorf("dataframe: index key %d out of range", k)
+		}
+		return int64(k), nil
+	default:
+		return 0, fmt.Errorf("dataframe: invalid integer index key %v (%T)", key, key)
+	}
+}
+
+// timeKey converts a time.Time key to nanoseconds since the epoch.
+func timeKey(key interface{}) (int64, error) {
+	t, ok := key.(time.Time)
+	if !ok {
+		return 0, fmt.Errorf("dataframe: invalid timestamp index key %v (%T)", key, key)
+	}
+	return t.UnixNano(), nil
+}
+
+// unitNanoseconds returns the number of nanoseconds in a unit of time.
+func unitNanoseconds(unit arrow.TimeUnit) int64 {
+	switch unit {
+	case arrow.Second:
+		return int64(time.Second)
+	case arrow.Millisecond:
+		return int64(time.Millisecond)
+	case arrow.Microsecond:
+		return int64(time.Microsecond)
+	default:
+		return 1
+	}
+}
+
+// SetIndex returns a DataFrame sharing the columns of df and indexed by the
+// named columns. A single integer or timestamp column makes an OrderedIndex
+// and must be sorted and free of nulls, a single string column makes a
+// StringIndex, and several columns make a MultiIndex.
+//
+// The DataFrames returned by Copy, Loc, LocRange, At, Xs, IndexJoin,
+// Arithmetic, Reindex, Align and Resample keep an index, the other operations
+// return DataFrames without index.
+func (df *DataFrame) SetIndex(columnNames ...string) (*DataFrame, error) {
+	idx, err := buildIndex(df, columnNames)
+	if err != nil {
+		return nil, err
+	}
+	out, err := df.Copy()
+	if err != nil {
+		return nil, err
+	}
+	out.index = idx
+	return out, nil
+}
+
+// buildIndex returns the index of the named columns of df.
+func buildIndex(df *DataFrame, columnNames []string) (Index, error) {
+	if len(columnNames) == 0 {
+		return nil, fmt.Errorf("dataframe: no column to index")
+	}
+	cols := make([]*array.Column, len(columnNames))
+	for i, name := range columnNames {
+		if cols[i] = df.Column(name); cols[i] == nil {
+			return nil, fmt.Errorf("dataframe: column %q is not in DataFrame: (%v)", name, df.ColumnNames())
+		}
+		for _, prev := range columnNames[:i] {
+			if prev == name {
+				return nil, fmt.Errorf("dataframe: column %q indexed twice", name)
+			}
+		}
+	}
+	if len(cols) == 1 {
+		return newIndex(cols[0])
+	}
+	return newMultiIndex(cols)
+}
+
+// Index returns the index of df, or nil if df has none.
+func (df *DataFrame) Index() Index {
+	return df.index
+}
+
+// Loc returns the rows of df whose index key is key, see Index.Lookup. The
+// DataFrame is empty when no row holds key.
+func (df *DataFrame) Loc(key interface{}) (*DataFrame, error) {
+	if df.index == nil {
+		return nil, fmt.Errorf("dataframe: DataFrame has no index")
+	}
+	rows, err := df.index.Lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := df.index.(OrderedIndex); ok {
+		beg := int64(0)
+		if len(rows) > 0 {
+			beg = rows[0]
+		}
+		return df.sliceIndexed(beg, beg+int64(len(rows)))
+	}
+
+	indices := newIndices(df.mem, rows)
+	defer indices.Release()
+	out, err := df.Take(indices)
+	if err != nil {
+		return nil, err
+	}
+	return reindex(out, df.index)
+}
+
+// LocRange returns the rows of df whose index key k is such that lo <= k < hi.
+// df must have an OrderedIndex.
+func (df *DataFrame) LocRange(lo, hi interface{}) (*DataFrame, error) {
+	idx, ok := df.index.(OrderedIndex)
+	if !ok {
+		return nil, fmt.Errorf("dataframe: DataFrame has no ordered index")
+	}
+	beg, end, err := idx.Range(lo, hi)
+	if err != nil {
+		return nil, err
+	}
+	return df.sliceIndexed(beg, end)
+}
+
+// At returns the row of df in effect at t: the last row whose timestamp is at
+// or before t. df must have a TimestampIndex. The DataFrame is empty when
+// every row is after t.
+func (df *DataFrame) At(t time.Time) (*DataFrame, error) {
+	idx, ok := df.index.(*TimestampIndex)
+	if !ok {
+		return nil, fmt.Errorf("dataframe: DataFrame has no timestamp index")
+	}
+	i := idx.Asof(t)
+	if i < 0 {
+		return df.sliceIndexed(0, 0)
+	}
+	return df.sliceIndexed(i, i+1)
+}
+
+// sliceIndexed returns rows beg:end of df, indexed like df.
+func (df *DataFrame) sliceIndexed(beg, end int64) (*DataFrame, error) {
+	out, err := df.Slice(beg, end)
+	if err != nil {
+		return nil, err
+	}
+	return reindex(out, df.index)
+}
+
+// reindex indexes df like it was indexed by like, releasing df. It returns df
+// without index when it no longer has the indexed columns.
+func reindex(df *DataFrame, like Index) (*DataFrame, error) {
+	names := like.Columns()
+	cols := make([]*array.Column, len(names))
+	for i, name := range names {
+		if cols[i] = df.Column(name); cols[i] == nil {
+			return df, nil
+		}
+	}
+	var err error
+	if _, ok := like.(*MultiIndex); ok {
+		df.index, err = newMultiIndex(cols)
+	} else {
+		df.index, err = newIndex(cols[0])
+	}
+	if err != nil {
+		df.Release()
+		return nil, err
+	}
+	return df, nil
+}
+
+// IndexJoin returns a DataFrame matching every row of the left DataFrame with
+// the rows of the right DataFrame holding the same index key, looked up in the
+// index of the right DataFrame. Both DataFrames must have an index of the same
+// kind. The DataFrame holds the left columns followed by the right columns
+// other than its index column, and is indexed like the left DataFrame. With
+// compute.LeftJoin, the left rows without a match are kept with nulls for the
+// right columns. Names found on both sides are suffixed like LeftJoin does.
+// With compute.SemiJoin or compute.AntiJoin, the DataFrame holds the left rows
+// with, respectively without, a match, and only the left columns.
+func (m *Mutator) IndexJoin(rightDf *DataFrame, how compute.JoinType, opts ...Option) MutationFunc {
+	cfg, err := newLeftJoinConfig(opts...)
+	return func(leftDf *DataFrame) (*DataFrame, error) {
+		if err != nil {
+			return nil, err
+		}
+		if how == compute.CrossJoin {
+			return nil, fmt.Errorf("mutation: index join does not support %s joins", how)
+		}
+		left, right := leftDf.Index(), rightDf.Index()
+		if left == nil || right == nil {
+			return nil, fmt.Errorf("mutation: index join of DataFrames without index")
+		}
+		if fmt.Sprintf("%T", left) != fmt.Sprintf("%T", right) {
+			return nil, fmt.Errorf("mutation: cannot join a %T with a %T", left, right)
+		}
+
+		leftBldr := array.NewInt64Builder(m.mem)
+		defer leftBldr.Release()
+		rightBldr := array.NewInt64Builder(m.mem)
+		defer rightBldr.Release()
+		for i := int64(0); i < left.Len(); i++ {
+			var matches []int64
+			if k := left.key(i); k != nil {
+				if matches, err = right.Lookup(k); err != nil {
+					return nil, err
+				}
+			}
+			switch how {
+			case compute.SemiJoin, compute.AntiJoin:
+				if (len(matches) > 0) == (how == compute.SemiJoin) {
+					leftBldr.Append(i)
+				}
+				continue
+			}
+			for _, j := range matches {
+				leftBldr.Append(i)
+				rightBldr.Append(j)
+			}
+			if len(matches) == 0 && how == compute.LeftJoin {
+				leftBldr.Append(i)
+				rightBldr.AppendNull()
+			}
+		}
+		leftIndices := leftBldr.NewInt64Array()
+		defer leftIndices.Release()
+		if how == compute.SemiJoin || how == compute.AntiJoin {
+			out, err := m.Take(leftIndices)(leftDf)
+			if err != nil {
+				return nil, err
+			}
+			return reindex(out, left)
+		}
+		rightIndices := rightBldr.NewInt64Array()
+		defer rightIndices.Release()
+
+		out, err := m.gatherJoin(leftDf, rightDf, leftIndices, rightIndices, right.Columns(), cfg)
+		if err != nil {
+			return nil, err
+		}
+		return reindex(out, left)
+	}
+}
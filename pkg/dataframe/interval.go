@@ -0,0 +1,56 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// IntervalJoin returns a DataFrame matching every row of the left DataFrame with the
+// right rows whose interval, from the start column to the end column with both bounds
+// included, holds the value of the named left column. This joins events to sessions
+// or addresses to ranges. A left row matching several intervals is repeated, and how
+// selects whether the left rows without a match are kept. See compute.IntervalJoin.
+// The DataFrame holds the left columns followed by the right columns, which are null
+// for the left rows without a match. Names found on both sides are suffixed like
+// LeftJoin does.
+func (m *Mutator) IntervalJoin(rightDf *DataFrame, column, start, end string, how compute.JoinType, opts ...Option) MutationFunc {
+	cfg, err := newLeftJoinConfig(opts...)
+	return func(leftDf *DataFrame) (*DataFrame, error) {
+		if err != nil {
+			return nil, err
+		}
+
+		values := leftDf.Column(column)
+		if values == nil {
+			return nil, fmt.Errorf("mutation: column %q is not in left DataFrame: (%v)", column, leftDf.ColumnNames())
+		}
+		starts, ends := rightDf.Column(start), rightDf.Column(end)
+		if starts == nil || ends == nil {
+			return nil, fmt.Errorf("mutation: columns %q and %q are not in right DataFrame: (%v)", start, end, rightDf.ColumnNames())
+		}
+
+		leftIndices, rightIndices, err := compute.IntervalJoin(m.mem, values, starts, ends, how)
+		if err != nil {
+			return nil, err
+		}
+		defer leftIndices.Release()
+		defer rightIndices.Release()
+
+		return m.gatherJoin(leftDf, rightDf, leftIndices, rightIndices, nil, cfg)
+	}
+}
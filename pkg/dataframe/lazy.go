@@ -0,0 +1,327 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/pushdown"
+)
+
+// ColumnLoader reads the data of a column of a LazyFrame, which owns the
+// column returned.
+type ColumnLoader func(mem memory.Allocator) (*array.Column, error)
+
+// lazyConfig are the config params for NewLazyFrame.
+type lazyConfig struct {
+	limit int64
+}
+
+func newLazyConfig(opts ...Option) (*lazyConfig, error) {
+	cfg := &lazyConfig{}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return cfg, err
+		}
+	}
+	return cfg, nil
+}
+
+// WithMemoryLimit bounds the bytes held by the columns a LazyFrame loaded.
+// Loading a column beyond the limit evicts the columns used least recently.
+func WithMemoryLimit(bytes int64) Option {
+	return func(p interface{}) error {
+		if bytes <= 0 {
+			return fmt.Errorf("dataframe: memory limit must be positive, got %d", bytes)
+		}
+		cfg, ok := p.(*lazyConfig)
+		if !ok {
+			return fmt.Errorf("cannot apply WithMemoryLimit to: %T", p)
+		}
+		cfg.limit = bytes
+		return nil
+	}
+}
+
+// LazyFrame is a table whose columns are only read when they are first
+// accessed, so that a query on a very wide table only loads the columns it
+// touches. The columns loaded are kept for the next accesses, and their size
+// is accounted for by MemoryUsage. It is safe for concurrent use.
+type LazyFrame struct {
+	mem     memory.Allocator
+	schema  *arrow.Schema
+	loaders []ColumnLoader
+	cfg     *lazyConfig
+
+	mu     sync.Mutex
+	rows   int64           // number of rows, -1 before a column is loaded
+	cols   []*array.Column // columns loaded, nil for the others
+	sizes  []int64         // bytes held by the columns loaded
+	used   []int64         // last access of the columns loaded
+	clock  int64
+	closed bool
+}
+
+// NewLazyFrame creates a new LazyFrame of the given schema, the data of the
+// i-th field being read by loaders[i] when first accessed.
+func NewLazyFrame(mem memory.Allocator, schema *arrow.Schema, loaders []ColumnLoader, opts ...Option) (*LazyFrame, error) {
+	if len(loaders) != len(schema.Fields()) {
+		return nil, fmt.Errorf("dataframe: %d loaders for %d fields", len(loaders), len(schema.Fields()))
+	}
+	cfg, err := newLazyConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	n := len(loaders)
+	return &LazyFrame{
+		mem:     mem,
+		schema:  schema,
+		loaders: loaders,
+		cfg:     cfg,
+		rows:    -1,
+		cols:    make([]*array.Column, n),
+		sizes:   make([]int64, n),
+		used:    make([]int64, n),
+	}, nil
+}
+
+// NewLazyFrameFromSource creates a new LazyFrame of the records of src, of
+// the given schema, reading every column on its own, as the projection of a
+// pushdown.ReadOptions, when it is first accessed.
+func NewLazyFrameFromSource(mem memory.Allocator, src pushdown.Source, schema *arrow.Schema, opts ...Option) (*LazyFrame, error) {
+	loaders := make([]ColumnLoader, len(schema.Fields()))
+	for i, field := range schema.Fields() {
+		loaders[i] = sourceLoader(src, field)
+	}
+	return NewLazyFrame(mem, schema, loaders, opts...)
+}
+
+// sourceLoader returns a loader reading the column of field from src.
+func sourceLoader(src pushdown.Source, field arrow.Field) ColumnLoader {
+	return func(memory.Allocator) (*array.Column, error) {
+		rdr, err := src.Read(pushdown.ReadOptions{Columns: []string{field.Name}})
+		if err != nil {
+			return nil, err
+		}
+		defer rdr.Release()
+
+		var chunks []array.Interface
+		defer func() {
+			for _, chunk := range chunks {
+				chunk.Release()
+			}
+		}()
+		for rdr.Next() {
+			chunk := rdr.Record().Column(0)
+			chunk.Retain()
+			chunks = append(chunks, chunk)
+		}
+		if r, ok := rdr.(interface{ Err() error }); ok && r.Err() != nil {
+			return nil, r.Err()
+		}
+		chunked := array.NewChunked(field.Type, chunks)
+		defer chunked.Release()
+		return array.NewColumn(field, chunked), nil
+	}
+}
+
+// Schema returns the schema of the LazyFrame.
+func (f *LazyFrame) Schema() *arrow.Schema { return f.schema }
+
+// Column returns the named column, loading it if it is not loaded yet. The
+// column must be released by the caller.
+func (f *LazyFrame) Column(name string) (*array.Column, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	col, err := f.load(name)
+	if err != nil {
+		return nil, err
+	}
+	col.Retain()
+	return col, nil
+}
+
+// Select creates a new DataFrame of the named columns, loading those that
+// are not loaded yet.
+func (f *LazyFrame) Select(names ...string) (*DataFrame, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cols := make([]array.Column, 0, len(names))
+	for _, name := range names {
+		col, err := f.load(name)
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, *col)
+	}
+	rows := f.rows
+	if rows < 0 {
+		rows = 0
+	}
+	return NewDataFrameFromShape(f.mem, cols, rows)
+}
+
+// load returns the named column, loading it if it is not loaded yet.
+func (f *LazyFrame) load(name string) (*array.Column, error) {
+	if f.closed {
+		return nil, fmt.Errorf("dataframe: LazyFrame is released")
+	}
+	idx := f.schema.FieldIndices(name)
+	if len(idx) == 0 {
+		return nil, fmt.Errorf("dataframe: column %q is not in LazyFrame: (%v)", name, f.Names())
+	}
+	i := idx[0]
+	f.clock++
+	f.used[i] = f.clock
+	if f.cols[i] != nil {
+		return f.cols[i], nil
+	}
+
+	col, err := f.loaders[i](f.mem)
+	if err != nil {
+		return nil, fmt.Errorf("dataframe: could not load column %q: %w", name, err)
+	}
+	field := f.schema.Field(i)
+	switch {
+	case !arrow.TypeEqual(col.DataType(), field.Type):
+		col.Release()
+		return nil, fmt.Errorf("dataframe: column %q loaded as %s, want %s", name, col.DataType(), field.Type)
+	case f.rows >= 0 && int64(col.Len()) != f.rows:
+		col.Release()
+		return nil, fmt.Errorf("dataframe: column %q loaded with %d rows, want %d", name, col.Len(), f.rows)
+	}
+	f.rows = int64(col.Len())
+	f.cols[i] = col
+	f.sizes[i] = columnSize(col)
+	f.evict(i)
+	return col, nil
+}
+
+// evict releases the columns used least recently, but the column keep, until
+// the columns loaded fit in the memory limit.
+func (f *LazyFrame) evict(keep int) {
+	if f.cfg.limit <= 0 {
+		return
+	}
+	var total int64
+	for _, size := range f.sizes {
+		total += size
+	}
+	for total > f.cfg.limit {
+		lru := -1
+		for i, col := range f.cols {
+			if col != nil && i != keep && (lru < 0 || f.used[i] < f.used[lru]) {
+				lru = i
+			}
+		}
+		if lru < 0 {
+			return
+		}
+		total -= f.sizes[lru]
+		f.drop(lru)
+	}
+}
+
+// drop releases the i-th column, which is loaded.
+func (f *LazyFrame) drop(i int) {
+	f.cols[i].Release()
+	f.cols[i], f.sizes[i] = nil, 0
+}
+
+// columnSize returns the number of bytes held by the buffers of col.
+func columnSize(col *array.Column) int64 {
+	seen := make(map[*memory.Buffer]struct{})
+	var u ColumnUsage
+	for _, chunk := range col.Data().Chunks() {
+		addDataUsage(&u, chunk.Data(), seen)
+	}
+	return u.Total()
+}
+
+// Names returns the names of the columns of the LazyFrame.
+func (f *LazyFrame) Names() []string {
+	names := make([]string, len(f.schema.Fields()))
+	for i, field := range f.schema.Fields() {
+		names[i] = field.Name
+	}
+	return names
+}
+
+// Loaded returns the names of the columns loaded, in the order of the schema.
+func (f *LazyFrame) Loaded() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var names []string
+	for i, col := range f.cols {
+		if col != nil {
+			names = append(names, f.schema.Field(i).Name)
+		}
+	}
+	return names
+}
+
+// MemoryUsage returns the number of bytes held by each column loaded.
+func (f *LazyFrame) MemoryUsage() MemoryUsage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	seen := make(map[*memory.Buffer]struct{})
+	var usage MemoryUsage
+	for _, col := range f.cols {
+		if col == nil {
+			continue
+		}
+		u := ColumnUsage{
+			Name:   col.Name(),
+			Type:   col.DataType(),
+			Chunks: len(col.Data().Chunks()),
+		}
+		for _, chunk := range col.Data().Chunks() {
+			addDataUsage(&u, chunk.Data(), seen)
+		}
+		usage.Columns = append(usage.Columns, u)
+	}
+	return usage
+}
+
+// Evict releases the named column if it is loaded, so that it is read again
+// on its next access. The DataFrames and columns handed out keep their data.
+// It reports whether the column was loaded.
+func (f *LazyFrame) Evict(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, i := range f.schema.FieldIndices(name) {
+		if f.cols[i] != nil {
+			f.drop(i)
+			return true
+		}
+	}
+	return false
+}
+
+// Release releases the columns loaded. The LazyFrame cannot be used after.
+func (f *LazyFrame) Release() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, col := range f.cols {
+		if col != nil {
+			f.drop(i)
+		}
+	}
+	f.closed = true
+}
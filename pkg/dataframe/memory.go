@@ -0,0 +1,156 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// ColumnUsage is the number of bytes held by the buffers of a column,
+// split by the kind of buffer.
+type ColumnUsage struct {
+	Name   string
+	Type   arrow.DataType
+	Chunks int
+
+	// Validity is the size of the validity bitmaps.
+	Validity int64
+	// Offsets is the size of the offsets of binary, string and list arrays.
+	Offsets int64
+	// Values is the size of the value buffers, including dictionary indices.
+	Values int64
+	// Children is the size of all the buffers of the child arrays of nested,
+	// dictionary and run-end encoded arrays.
+	Children int64
+}
+
+// Total returns the number of bytes held by the column.
+func (u ColumnUsage) Total() int64 {
+	return u.Validity + u.Offsets + u.Values + u.Children
+}
+
+// MemoryUsage is the number of bytes held by the columns of a DataFrame.
+type MemoryUsage struct {
+	Columns []ColumnUsage
+}
+
+// Total returns the number of bytes held by the DataFrame.
+func (u MemoryUsage) Total() int64 {
+	var total int64
+	for _, c := range u.Columns {
+		total += c.Total()
+	}
+	return total
+}
+
+// Top returns the n columns holding the most bytes, largest first.
+// If n <= 0 or exceeds the number of columns, all columns are returned.
+func (u MemoryUsage) Top(n int) []ColumnUsage {
+	cols := make([]ColumnUsage, len(u.Columns))
+	copy(cols, u.Columns)
+	sort.SliceStable(cols, func(i, j int) bool {
+		return cols[i].Total() > cols[j].Total()
+	})
+	if n > 0 && n < len(cols) {
+		cols = cols[:n]
+	}
+	return cols
+}
+
+// Report builds out a table of the n columns holding the most bytes,
+// followed by the total for the DataFrame.
+// If n <= 0, all columns are listed.
+func (u MemoryUsage) Report(n int) string {
+	var output strings.Builder
+	w := tabwriter.NewWriter(&output, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "column\ttype\tchunks\tvalidity\toffsets\tvalues\tchildren\ttotal\t")
+	for _, c := range u.Top(n) {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t\n",
+			c.Name, c.Type, c.Chunks, c.Validity, c.Offsets, c.Values, c.Children, c.Total())
+	}
+	w.Flush()
+	fmt.Fprintf(&output, "total: %d bytes in %d columns\n", u.Total(), len(u.Columns))
+	return output.String()
+}
+
+// MemoryUsage returns the number of bytes held by each column of the DataFrame.
+// Sizes are those of the underlying buffers, so a sliced column reports the
+// buffers it shares with the column it was sliced from. A buffer referenced
+// more than once in the DataFrame is only counted the first time.
+func (df *DataFrame) MemoryUsage() MemoryUsage {
+	seen := make(map[*memory.Buffer]struct{})
+	usage := MemoryUsage{Columns: make([]ColumnUsage, len(df.cols))}
+	for i := range df.cols {
+		col := &df.cols[i]
+		u := ColumnUsage{
+			Name:   col.Name(),
+			Type:   col.DataType(),
+			Chunks: len(col.Data().Chunks()),
+		}
+		for _, chunk := range col.Data().Chunks() {
+			addDataUsage(&u, chunk.Data(), seen)
+		}
+		usage.Columns[i] = u
+	}
+	return usage
+}
+
+// addDataUsage adds the sizes of the buffers of data to u.
+func addDataUsage(u *ColumnUsage, data *array.Data, seen map[*memory.Buffer]struct{}) {
+	size := func(buf *memory.Buffer) int64 {
+		if buf == nil {
+			return 0
+		}
+		if _, ok := seen[buf]; ok {
+			return 0
+		}
+		seen[buf] = struct{}{}
+		return int64(buf.Len())
+	}
+
+	for i, buf := range data.Buffers() {
+		switch {
+		case i == 0:
+			u.Validity += size(buf)
+		case i == 1 && hasOffsets(data.DataType()):
+			u.Offsets += size(buf)
+		default:
+			u.Values += size(buf)
+		}
+	}
+
+	for _, child := range data.Children() {
+		var c ColumnUsage
+		addDataUsage(&c, child, seen)
+		u.Children += c.Total()
+	}
+}
+
+// hasOffsets reports whether the second buffer of arrays of type dtype holds offsets.
+func hasOffsets(dtype arrow.DataType) bool {
+	switch dtype.ID() {
+	case arrow.BINARY, arrow.STRING, arrow.LIST, arrow.MAP:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+)
+
+// MultiIndex is an Index of several columns, the levels of the index, looking
+// keys up in hash tables. A key is a []interface{} holding a value per level,
+// in the order of the levels, with the types accepted by the single column
+// indexes: integers, time.Time values for timestamps and strings. The keys may
+// come in any order, and rows with a null value are not indexed at its level.
+type MultiIndex struct {
+	levels []*indexLevel
+	rows   map[string][]int64 // by encoded key, for the rows without nulls
+	n      int64
+}
+
+// indexLevel is a column of a MultiIndex.
+type indexLevel struct {
+	column string
+	dtype  arrow.DataType
+	keys   []interface{} // int64 or string by row, nil when null
+	rows   map[interface{}][]int64
+}
+
+// Columns returns the names of the levels.
+func (idx *MultiIndex) Columns() []string {
+	names := make([]string, len(idx.levels))
+	for i, l := range idx.levels {
+		names[i] = l.column
+	}
+	return names
+}
+
+// Len returns the number of rows indexed.
+func (idx *MultiIndex) Len() int64 { return idx.n }
+
+// Lookup returns the positions of the rows holding key. A key holding values
+// for the first levels only returns the rows matching these levels, and a key
+// that is not a []interface{} is the value of the first level.
+func (idx *MultiIndex) Lookup(key interface{}) ([]int64, error) {
+	values, ok := key.([]interface{})
+	if !ok {
+		values = []interface{}{key}
+	}
+	if len(values) == 0 || len(values) > len(idx.levels) {
+		return nil, fmt.Errorf("dataframe: index key %v has %d values, want 1 to %d", key, len(values), len(idx.levels))
+	}
+
+	norm := make([]interface{}, len(values))
+	for i, v := range values {
+		k, err := idx.levels[i].normalize(v)
+		if err != nil {
+			return nil, err
+		}
+		norm[i] = k
+	}
+	if len(norm) == len(idx.levels) {
+		return idx.rows[encodeKey(norm)], nil
+	}
+
+	rows := idx.levels[0].rows[norm[0]]
+	for i := 1; i < len(norm) && len(rows) > 0; i++ {
+		rows = intersect(rows, idx.levels[i].rows[norm[i]])
+	}
+	return rows, nil
+}
+
+// LevelLookup returns the positions of the rows holding key at the named level.
+func (idx *MultiIndex) LevelLookup(level string, key interface{}) ([]int64, error) {
+	for _, l := range idx.levels {
+		if l.column != level {
+			continue
+		}
+		k, err := l.normalize(key)
+		if err != nil {
+			return nil, err
+		}
+		return l.rows[k], nil
+	}
+	return nil, fmt.Errorf("dataframe: no index level %q in (%v)", level, idx.Columns())
+}
+
+func (idx *MultiIndex) key(i int64) interface{} {
+	key := make([]interface{}, len(idx.levels))
+	for j, l := range idx.levels {
+		k := l.keys[i]
+		if k == nil {
+			return nil
+		}
+		if _, ok := l.dtype.(*arrow.TimestampType); ok {
+			k = time.Unix(0, k.(int64)).UTC()
+		}
+		key[j] = k
+	}
+	return key
+}
+
+// normalize converts a key of the level to the value stored in its keys.
+func (l *indexLevel) normalize(key interface{}) (interface{}, error) {
+	switch l.dtype.(type) {
+	case *arrow.TimestampType:
+		return timeKey(key)
+	case *arrow.StringType:
+		k, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("dataframe: invalid string index key %v (%T)", key, key)
+		}
+		return k, nil
+	default:
+		return intKey(key)
+	}
+}
+
+// newMultiIndex builds the MultiIndex of cols.
+func newMultiIndex(cols []*array.Column) (*MultiIndex, error) {
+	idx := &MultiIndex{rows: make(map[string][]int64)}
+	for _, col := range cols {
+		l, err := newIndexLevel(col)
+		if err != nil {
+			return nil, err
+		}
+		idx.levels = append(idx.levels, l)
+	}
+	idx.n = int64(len(idx.levels[0].keys))
+
+	key := make([]interface{}, len(idx.levels))
+	for i := int64(0); i < idx.n; i++ {
+		valid := true
+		for j, l := range idx.levels {
+			key[j] = l.keys[i]
+			valid = valid && key[j] != nil
+		}
+		if valid {
+			k := encodeKey(key)
+			idx.rows[k] = append(idx.rows[k], i)
+		}
+	}
+	return idx, nil
+}
+
+func newIndexLevel(col *array.Column) (*indexLevel, error) {
+	l := &indexLevel{
+		column: col.Name(),
+		dtype:  col.DataType(),
+		keys:   make([]interface{}, 0, columnLen(*col)),
+		rows:   make(map[interface{}][]int64),
+	}
+	var value func(arr array.Interface, i int) interface{}
+	switch dtype := l.dtype.(type) {
+	case *arrow.Int8Type, *arrow.Int16Type, *arrow.Int32Type, *arrow.Int64Type,
+		*arrow.Uint8Type, *arrow.Uint16Type, *arrow.Uint32Type, *arrow.Uint64Type:
+		value = func(arr array.Interface, i int) interface{} {
+			k, err := intKey(intAt(arr, i))
+			if err != nil {
+				return err
+			}
+			return k
+		}
+	case *arrow.TimestampType:
+		scale := unitNanoseconds(dtype.Unit)
+		value = func(arr array.Interface, i int) interface{} {
+			return int64(arr.(*array.Timestamp).Value(i)) * scale
+		}
+	case *arrow.StringType:
+		value = func(arr array.Interface, i int) interface{} {
+			return arr.(*array.String).Value(i)
+		}
+	default:
+		return nil, fmt.Errorf("dataframe: cannot index column %q of type %s", l.column, dtype)
+	}
+
+	for _, chunk := range col.Data().Chunks() {
+		for i := 0; i < chunk.Len(); i++ {
+			if chunk.IsNull(i) {
+				l.keys = append(l.keys, nil)
+				continue
+			}
+			k := value(chunk, i)
+			if err, ok := k.(error); ok {
+				return nil, fmt.Errorf("dataframe: cannot index column %q: %w", l.column, err)
+			}
+			l.rows[k] = append(l.rows[k], int64(len(l.keys)))
+			l.keys = append(l.keys, k)
+		}
+	}
+	return l, nil
+}
+
+// encodeKey encodes the normalized values of a key to a string, telling the
+// values apart whatever the strings they hold.
+func encodeKey(values []interface{}) string {
+	var sb strings.Builder
+	for _, v := range values {
+		switch v := v.(type) {
+		case int64:
+			sb.WriteByte('i')
+			sb.WriteString(strconv.FormatInt(v, 10))
+			sb.WriteByte(';')
+		case string:
+			sb.WriteByte('s')
+			sb.WriteString(strconv.Itoa(len(v)))
+			sb.WriteByte(':')
+			sb.WriteString(v)
+		}
+	}
+	return sb.String()
+}
+
+// intersect returns the positions found in both of the sorted a and b.
+func intersect(a, b []int64) []int64 {
+	var out []int64
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// Xs returns the cross section of df at key on the named level of its
+// MultiIndex: the rows holding key at this level, indexed by the other
+// levels.
+func (df *DataFrame) Xs(level string, key interface{}) (*DataFrame, error) {
+	idx, ok := df.index.(*MultiIndex)
+	if !ok {
+		return nil, fmt.Errorf("dataframe: DataFrame has no multi-index")
+	}
+	rows, err := idx.LevelLookup(level, key)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := newIndices(df.mem, rows)
+	defer indices.Release()
+	out, err := df.Take(indices)
+	if err != nil {
+		return nil, err
+	}
+
+	var cols []*array.Column
+	for _, l := range idx.levels {
+		if l.column != level {
+			cols = append(cols, out.Column(l.column))
+		}
+	}
+	if len(cols) == 0 {
+		return out, nil
+	}
+	if out.index, err = newMultiIndex(cols); err != nil {
+		out.Release()
+		return nil, err
+	}
+	return out, nil
+}
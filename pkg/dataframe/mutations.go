@@ -15,11 +15,14 @@
 package dataframe
 
 import (
+	"errors"
 	"fmt"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/array"
-	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/expr"
 	"github.com/gomem/gomem/pkg/iterator"
 	"github.com/gomem/gomem/pkg/smartbuilder"
 )
@@ -43,6 +46,7 @@ func NewMutator(mem memory.Allocator) *Mutator {
 type MutationFunc func(*DataFrame) (*DataFrame, error)
 
 // Select the given DataFrame columns by name.
+// The columns share their data with df.
 // 提取指定的 columns
 func (m *Mutator) Select(names ...string) MutationFunc {
 	return func(df *DataFrame) (*DataFrame, error) {
@@ -54,6 +58,7 @@ func (m *Mutator) Select(names ...string) MutationFunc {
 }
 
 // Drop the given DataFrame columns by name.
+// The remaining columns share their data with df.
 // 忽略指定的 columns
 func (m *Mutator) Drop(names ...string) MutationFunc {
 	return func(df *DataFrame) (*DataFrame, error) {
@@ -64,6 +69,73 @@ func (m *Mutator) Drop(names ...string) MutationFunc {
 	}
 }
 
+// Rename the given DataFrame columns, mapping old names to new names.
+// The columns share their data with df, only their fields change.
+func (m *Mutator) Rename(names map[string]string) MutationFunc {
+	return func(df *DataFrame) (*DataFrame, error) {
+		for old := range names {
+			if df.Column(old) == nil {
+				return nil, fmt.Errorf("mutation: unknown column %q", old)
+			}
+		}
+
+		dfCols := df.Columns()
+		fields := make([]arrow.Field, len(dfCols))
+		seen := make(map[string]struct{}, len(dfCols))
+		for i := range dfCols {
+			fields[i] = dfCols[i].Field()
+			if name, ok := names[fields[i].Name]; ok {
+				fields[i].Name = name
+			}
+			if _, dup := seen[fields[i].Name]; dup {
+				return nil, fmt.Errorf("mutation: duplicate column %q", fields[i].Name)
+			}
+			seen[fields[i].Name] = struct{}{}
+		}
+
+		cols := make([]array.Column, len(dfCols))
+		for i := range dfCols {
+			cols[i] = *array.NewColumn(fields[i], dfCols[i].Data())
+		}
+		defer func() {
+			for i := range cols {
+				cols[i].Release()
+			}
+		}()
+
+		return NewDataFrameFromShape(m.mem, cols, df.NumRows())
+	}
+}
+
+// Reorder the DataFrame columns: the given columns come first, in the given
+// order, followed by the other columns in their current order.
+// The columns share their data with df.
+func (m *Mutator) Reorder(names ...string) MutationFunc {
+	return func(df *DataFrame) (*DataFrame, error) {
+		cols := make([]array.Column, 0, df.NumCols())
+		moved := make(map[string]struct{}, len(names))
+		for _, name := range names {
+			if _, dup := moved[name]; dup {
+				return nil, fmt.Errorf("mutation: column %q given twice", name)
+			}
+			col := df.Column(name)
+			if col == nil {
+				return nil, fmt.Errorf("mutation: unknown column %q", name)
+			}
+			moved[name] = struct{}{}
+			cols = append(cols, *col)
+		}
+		dfCols := df.Columns()
+		for i := range dfCols {
+			if _, ok := moved[dfCols[i].Name()]; !ok {
+				cols = append(cols, dfCols[i])
+			}
+		}
+
+		return NewDataFrameFromShape(m.mem, cols, df.NumRows())
+	}
+}
+
 // Slice creates a new DataFrame consisting of rows[beg:end].
 func (m *Mutator) Slice(beg, end int64) MutationFunc {
 	return func(df *DataFrame) (*DataFrame, error) {
@@ -92,10 +164,315 @@ func (m *Mutator) Slice(beg, end int64) MutationFunc {
 	}
 }
 
+// Take creates a new DataFrame consisting of the rows at the given indices, in order.
+// A null index produces a row of nulls.
+func (m *Mutator) Take(indices *array.Int64) MutationFunc {
+	return func(df *DataFrame) (*DataFrame, error) {
+		dfCols := df.Columns()
+		cols := make([]array.Column, 0, len(dfCols))
+		defer func() {
+			for i := range cols {
+				cols[i].Release()
+			}
+		}()
+
+		for i := range dfCols {
+			col, err := compute.Take(m.mem, &dfCols[i], indices)
+			if err != nil {
+				return nil, err
+			}
+			cols = append(cols, *col)
+		}
+
+		return NewDataFrameFromShape(m.mem, cols, int64(indices.Len()))
+	}
+}
+
+// TopK creates a new DataFrame with the k rows holding the largest (Descending) or
+// smallest (Ascending) values of the named column. Rows where the column is null are skipped.
+func (m *Mutator) TopK(columnName string, k int, order compute.SortOrder) MutationFunc {
+	return func(df *DataFrame) (*DataFrame, error) {
+		col := df.Column(columnName)
+		if col == nil {
+			return nil, fmt.Errorf("mutation: column %q is not in DataFrame: (%v)", columnName, df.ColumnNames())
+		}
+
+		indices, err := compute.TopK(m.mem, col, k, order)
+		if err != nil {
+			return nil, err
+		}
+		defer indices.Release()
+
+		return m.Take(indices)(df)
+	}
+}
+
+// Sample creates a new DataFrame with n rows drawn uniformly at random without
+// replacement, kept in their original order. The same seed draws the same rows.
+func (m *Mutator) Sample(n int64, seed int64) MutationFunc {
+	return func(df *DataFrame) (*DataFrame, error) {
+		indices, err := compute.SampleIndices(m.mem, df.NumRows(), n, seed)
+		if err != nil {
+			return nil, err
+		}
+		defer indices.Release()
+
+		return m.Take(indices)(df)
+	}
+}
+
+// SampleFraction creates a new DataFrame with the fraction frac of the rows drawn
+// uniformly at random without replacement, kept in their original order.
+func (m *Mutator) SampleFraction(frac float64, seed int64) MutationFunc {
+	return func(df *DataFrame) (*DataFrame, error) {
+		n, err := compute.SampleFraction(df.NumRows(), frac)
+		if err != nil {
+			return nil, err
+		}
+		return m.Sample(n, seed)(df)
+	}
+}
+
+// StratifiedSample creates a new DataFrame with the fraction frac of the rows of
+// every group of equal values of the named columns, kept in their original order.
+func (m *Mutator) StratifiedSample(frac float64, seed int64, columnNames ...string) MutationFunc {
+	return func(df *DataFrame) (*DataFrame, error) {
+		keys := make([]*array.Column, len(columnNames))
+		for i, name := range columnNames {
+			if keys[i] = df.Column(name); keys[i] == nil {
+				return nil, fmt.Errorf("mutation: column %q is not in DataFrame: (%v)", name, df.ColumnNames())
+			}
+		}
+
+		indices, err := compute.StratifiedSampleIndices(m.mem, keys, frac, seed)
+		if err != nil {
+			return nil, err
+		}
+		defer indices.Release()
+
+		return m.Take(indices)(df)
+	}
+}
+
+// Explode creates a new DataFrame with a row for every element of the lists of the
+// named column, which then holds the elements, repeating the values of the other
+// columns. Null and empty lists produce no rows unless policy keeps them.
+func (m *Mutator) Explode(columnName string, policy compute.ExplodePolicy) MutationFunc {
+	return func(df *DataFrame) (*DataFrame, error) {
+		list := df.Column(columnName)
+		if list == nil {
+			return nil, fmt.Errorf("mutation: column %q is not in DataFrame: (%v)", columnName, df.ColumnNames())
+		}
+		elems, rows, err := compute.Explode(m.mem, list, policy)
+		if err != nil {
+			return nil, err
+		}
+		defer elems.Release()
+		defer rows.Release()
+
+		dfCols := df.Columns()
+		cols := make([]array.Column, 0, len(dfCols))
+		defer func() {
+			for i := range cols {
+				cols[i].Release()
+			}
+		}()
+		for i := range dfCols {
+			if dfCols[i].Name() == columnName {
+				cols = append(cols, *array.NewColumn(elems.Field(), elems.Data()))
+				continue
+			}
+			taken, err := compute.Take(m.mem, &dfCols[i], rows)
+			if err != nil {
+				return nil, err
+			}
+			cols = append(cols, *taken)
+		}
+
+		return NewDataFrameFromShape(m.mem, cols, int64(rows.Len()))
+	}
+}
+
+// Unnest creates a new DataFrame replacing the named struct column with a column
+// for every field of the struct, named after the field with prefix prepended.
+// The columns share the memory of the struct column, rows where the struct is
+// null are null.
+func (m *Mutator) Unnest(columnName, prefix string) MutationFunc {
+	return func(df *DataFrame) (*DataFrame, error) {
+		col := df.Column(columnName)
+		if col == nil {
+			return nil, fmt.Errorf("mutation: column %q is not in DataFrame: (%v)", columnName, df.ColumnNames())
+		}
+		dtype, ok := col.DataType().(*arrow.StructType)
+		if !ok {
+			return nil, fmt.Errorf("mutation: column %q of type %s is not a struct", columnName, col.DataType())
+		}
+
+		dfCols := df.Columns()
+		cols := make([]array.Column, 0, len(dfCols)+len(dtype.Fields()))
+		defer func() {
+			for i := range cols {
+				cols[i].Release()
+			}
+		}()
+		for i := range dfCols {
+			if dfCols[i].Name() != columnName {
+				cols = append(cols, *array.NewColumn(dfCols[i].Field(), dfCols[i].Data()))
+				continue
+			}
+			for _, f := range dtype.Fields() {
+				child, err := compute.StructField(m.mem, col, f.Name)
+				if err != nil {
+					return nil, err
+				}
+				field := child.Field()
+				field.Name = prefix + f.Name
+				if field.Name != columnName && df.Column(field.Name) != nil {
+					child.Release()
+					return nil, fmt.Errorf("mutation: column %q already exists in DataFrame", field.Name)
+				}
+				cols = append(cols, *array.NewColumn(field, child.Data()))
+				child.Release()
+			}
+		}
+
+		return NewDataFrameFromShape(m.mem, cols, df.NumRows())
+	}
+}
+
+// Cov creates a small DataFrame holding the covariance matrix of the named numeric columns,
+// or of all the columns when none are named. Its first column, "column", names the rows.
+func (m *Mutator) Cov(policy compute.NullPolicy, columnNames ...string) MutationFunc {
+	return m.statsMatrix(compute.Cov, policy, columnNames)
+}
+
+// Corr creates a small DataFrame holding the correlation matrix of the named numeric columns,
+// or of all the columns when none are named. Its first column, "column", names the rows.
+func (m *Mutator) Corr(policy compute.NullPolicy, columnNames ...string) MutationFunc {
+	return m.statsMatrix(compute.Corr, policy, columnNames)
+}
+
+func (m *Mutator) statsMatrix(fn func([]*array.Column, compute.NullPolicy) ([][]float64, error), policy compute.NullPolicy, columnNames []string) MutationFunc {
+	return func(df *DataFrame) (*DataFrame, error) {
+		if len(columnNames) == 0 {
+			columnNames = df.ColumnNames()
+		}
+		cols := make([]*array.Column, len(columnNames))
+		for i, name := range columnNames {
+			if name == "column" {
+				return nil, errors.New(`mutation: column "column" names the rows of the matrix`)
+			}
+			if cols[i] = df.Column(name); cols[i] == nil {
+				return nil, fmt.Errorf("mutation: column %q is not in DataFrame: (%v)", name, df.ColumnNames())
+			}
+		}
+
+		matrix, err := fn(cols, policy)
+		if err != nil {
+			return nil, err
+		}
+
+		dict := Dict{"column": columnNames}
+		for j, name := range columnNames {
+			values := make([]float64, len(matrix))
+			for i := range matrix {
+				values[i] = matrix[i][j]
+			}
+			dict[name] = values
+		}
+		res, err := NewDataFrameFromMem(m.mem, dict)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Release()
+
+		return res.Reorder(append([]string{"column"}, columnNames...)...)
+	}
+}
+
+// WithColumn creates a new DataFrame with the result of evaluating e stored in the named column.
+// An existing column with the same name is replaced in place, otherwise the column is appended.
+func (m *Mutator) WithColumn(name string, e expr.Expr) MutationFunc {
+	return func(df *DataFrame) (*DataFrame, error) {
+		res, err := e.Eval(m.mem, df)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Release()
+
+		if int64(res.Len()) != df.NumRows() {
+			return nil, fmt.Errorf("mutation: expression %s produced %d rows, want %d", e, res.Len(), df.NumRows())
+		}
+
+		field := res.Field()
+		field.Name = name
+		col := array.NewColumn(field, res.Data())
+		defer col.Release()
+
+		cols := make([]array.Column, 0, df.NumCols()+1)
+		replaced := false
+		for _, c := range df.Columns() {
+			if c.Name() == name {
+				c = *col
+				replaced = true
+			}
+			cols = append(cols, c)
+		}
+		if !replaced {
+			cols = append(cols, *col)
+		}
+
+		return NewDataFrameFromShape(m.mem, cols, df.NumRows())
+	}
+}
+
+// Filter creates a new DataFrame with the rows for which the boolean expression e
+// is true. Rows where e is null are dropped.
+func (m *Mutator) Filter(e expr.Expr) MutationFunc {
+	return func(df *DataFrame) (*DataFrame, error) {
+		mask, err := e.Eval(m.mem, df)
+		if err != nil {
+			return nil, err
+		}
+		defer mask.Release()
+
+		if mask.DataType().ID() != arrow.BOOL {
+			return nil, fmt.Errorf("mutation: expression %s is of type %s, want bool", e, mask.DataType())
+		}
+		if int64(mask.Len()) != df.NumRows() {
+			return nil, fmt.Errorf("mutation: expression %s produced %d rows, want %d", e, mask.Len(), df.NumRows())
+		}
+
+		bldr := array.NewInt64Builder(m.mem)
+		defer bldr.Release()
+		var row int64
+		for _, chunk := range mask.Data().Chunks() {
+			values := chunk.(*array.Boolean)
+			for i := 0; i < values.Len(); i, row = i+1, row+1 {
+				if values.IsValid(i) && values.Value(i) {
+					bldr.Append(row)
+				}
+			}
+		}
+		indices := bldr.NewInt64Array()
+		defer indices.Release()
+
+		return m.Take(indices)(df)
+	}
+}
+
+// DefaultCrossJoinMaxRows is the maximum number of rows of a CrossJoin unless
+// WithMaxRows sets another one.
+const DefaultCrossJoinMaxRows = 100000000
+
+// crossJoinBatchSize is the number of rows a CrossJoin generates at a time.
+const crossJoinBatchSize = 64 << 10
+
 // leftJoinConfig are the config params for LeftJoin.
 type leftJoinConfig struct {
 	lsuffix string
 	rsuffix string
+	maxRows int64
 }
 
 // newLeftJoinConfig creates a new config using options and validates it.
@@ -122,14 +499,20 @@ func defaultLeftJoinConfig() *leftJoinConfig {
 	return &leftJoinConfig{
 		lsuffix: "_0",
 		rsuffix: "_1",
+		maxRows: DefaultCrossJoinMaxRows,
 	}
 }
 
 // WithLsuffix configures a right or left join to use the provided left suffix.
 func WithLsuffix(lsuffix string) Option {
 	return func(p interface{}) error {
-		o, ok := p.(*leftJoinConfig)
-		if !ok {
+		var o *leftJoinConfig
+		switch p := p.(type) {
+		case *leftJoinConfig:
+			o = p
+		case *asofJoinConfig:
+			o = &p.leftJoinConfig
+		default:
 			return fmt.Errorf("cannot apply WithLsuffix to: %T", p)
 		}
 		o.lsuffix = lsuffix
@@ -140,8 +523,13 @@ func WithLsuffix(lsuffix string) Option {
 // WithRsuffix configures a right or left join to use the provided left suffix.
 func WithRsuffix(rsuffix string) Option {
 	return func(p interface{}) error {
-		o, ok := p.(*leftJoinConfig)
-		if !ok {
+		var o *leftJoinConfig
+		switch p := p.(type) {
+		case *leftJoinConfig:
+			o = p
+		case *asofJoinConfig:
+			o = &p.leftJoinConfig
+		default:
 			return fmt.Errorf("cannot apply WithRsuffix to: %T", p)
 		}
 		o.rsuffix = rsuffix
@@ -209,6 +597,13 @@ type joinFuncConfig struct {
 	schema                 *arrow.Schema
 	recordBuilder          *array.RecordBuilder
 	smartBuilder           *smartbuilder.SmartBuilder
+
+	// hashJoin is set when a key column is dictionary encoded or holds fixed size
+	// binaries, such as UUIDs, which the value iterators do not support. The join
+	// is then computed by compute.HashJoin and stored in result, and no builders
+	// are created.
+	hashJoin bool
+	result   *DataFrame
 }
 
 // newJoinFuncConfig builds up all the data needed to do a join.
@@ -234,6 +629,10 @@ func (m *Mutator) newJoinFuncConfig(cfg *leftJoinConfig, leftDf *DataFrame, righ
 
 		jc.leftColumns = append(jc.leftColumns, *leftColumn)
 		jc.rightColumns = append(jc.rightColumns, *rightColumn)
+
+		if needsHashJoin(leftColumn) || needsHashJoin(rightColumn) {
+			jc.hashJoin = true
+		}
 	}
 	// Keep track of the number of matching left and right columns. (They should be the same number)
 	jc.matchingLeftColsLen = len(jc.leftColumns)
@@ -273,6 +672,9 @@ func (m *Mutator) newJoinFuncConfig(cfg *leftJoinConfig, leftDf *DataFrame, righ
 	}
 
 	jc.schema = arrow.NewSchema(fields, nil)
+	if jc.hashJoin {
+		return jc, nil
+	}
 	jc.recordBuilder = array.NewRecordBuilder(m.mem, jc.schema)
 	jc.smartBuilder = smartbuilder.NewSmartBuilder(jc.recordBuilder)
 
@@ -280,10 +682,19 @@ func (m *Mutator) newJoinFuncConfig(cfg *leftJoinConfig, leftDf *DataFrame, righ
 }
 
 func (jc *joinFuncConfig) Release() {
-	jc.recordBuilder.Release()
+	if jc.recordBuilder != nil {
+		jc.recordBuilder.Release()
+	}
+	if jc.result != nil {
+		jc.result.Release()
+	}
 }
 
 func (jc *joinFuncConfig) buildDataFrame() (*DataFrame, error) {
+	if jc.hashJoin {
+		jc.result.Retain()
+		return jc.result, nil
+	}
 	rec := jc.recordBuilder.NewRecord()
 	defer rec.Release()
 	return NewDataFrame(jc.mutator.mem, jc.schema, rec.Columns())
@@ -297,6 +708,14 @@ func (m *Mutator) leftJoin(cfg *leftJoinConfig, leftDf *DataFrame, rightDf *Data
 		return nil, err
 	}
 
+	if data.hashJoin {
+		if err := data.runHashJoin(compute.LeftJoin); err != nil {
+			data.Release()
+			return nil, err
+		}
+		return data, nil
+	}
+
 	sharedLeftJoinLogic(data, func(appendEmptyRow bool, leftStepValues *iterator.StepValue) {
 		if appendEmptyRow {
 			// If nothing matched then we append the row once with nil for additional right columns.
@@ -318,6 +737,117 @@ func (m *Mutator) leftJoin(cfg *leftJoinConfig, leftDf *DataFrame, rightDf *Data
 	return data, nil
 }
 
+// runHashJoin joins the key columns with compute.HashJoin and gathers the rows
+// of every output column with compute.Take, storing the DataFrame in jc.result.
+// Dictionary columns keep their dictionaries so no values are decoded.
+func (jc *joinFuncConfig) runHashJoin(how compute.JoinType) error {
+	mem := jc.mutator.mem
+
+	leftKeys := make([]*array.Column, jc.matchingLeftColsLen)
+	rightKeys := make([]*array.Column, jc.matchingRightColsLen)
+	for i := range leftKeys {
+		leftKeys[i] = &jc.leftColumns[i]
+		rightKeys[i] = &jc.rightColumns[i]
+	}
+
+	leftIndices, rightIndices, err := compute.HashJoin(mem, leftKeys, rightKeys, how)
+	if err != nil {
+		return err
+	}
+	defer leftIndices.Release()
+	defer rightIndices.Release()
+
+	cols := make([]array.Column, 0, len(jc.schema.Fields()))
+	defer func() {
+		for i := range cols {
+			cols[i].Release()
+		}
+	}()
+
+	take := func(col *array.Column, indices *array.Int64) error {
+		taken, err := compute.Take(mem, col, indices)
+		if err != nil {
+			return err
+		}
+		defer taken.Release()
+		// Use the output field, it carries the suffixed name and nullability.
+		cols = append(cols, *array.NewColumn(jc.schema.Field(len(cols)), taken.Data()))
+		return nil
+	}
+
+	for i := range jc.leftColumns {
+		if err := take(&jc.leftColumns[i], leftIndices); err != nil {
+			return err
+		}
+	}
+	for i := jc.matchingRightColsLen; i < len(jc.rightColumns); i++ {
+		if err := take(&jc.rightColumns[i], rightIndices); err != nil {
+			return err
+		}
+	}
+
+	jc.result, err = NewDataFrameFromShape(mem, cols, int64(leftIndices.Len()))
+	return err
+}
+
+// gatherJoin builds the DataFrame of a join from the indices of the matching rows of
+// each side: the left columns followed by the right columns other than drop, which are
+// nullable. A nil leftIndices keeps the rows of leftDf as they are. Names found on both
+// sides are suffixed according to cfg.
+func (m *Mutator) gatherJoin(leftDf, rightDf *DataFrame, leftIndices, rightIndices *array.Int64, drop []string, cfg *leftJoinConfig) (*DataFrame, error) {
+	leftCols := leftDf.Columns()
+	cols := make([]array.Column, 0, len(leftCols)+rightDf.NumCols())
+	defer func() {
+		for i := range cols {
+			cols[i].Release()
+		}
+	}()
+
+	names := make(map[string]int, len(leftCols))
+	for i := range leftCols {
+		names[leftCols[i].Name()] = i
+		if leftIndices == nil {
+			cols = append(cols, *array.NewColumn(leftCols[i].Field(), leftCols[i].Data()))
+			continue
+		}
+		taken, err := compute.Take(m.mem, &leftCols[i], leftIndices)
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, *taken)
+	}
+	for _, col := range rightDf.RejectColumns(drop...) {
+		taken, err := compute.Take(m.mem, &col, rightIndices)
+		if err != nil {
+			return nil, err
+		}
+		field := col.Field()
+		field.Nullable = true
+		if i, ok := names[field.Name]; ok {
+			lfield := cols[i].Field()
+			lfield.Name += cfg.lsuffix
+			renamed := array.NewColumn(lfield, cols[i].Data())
+			cols[i].Release()
+			cols[i] = *renamed
+			field.Name += cfg.rsuffix
+		}
+		cols = append(cols, *array.NewColumn(field, taken.Data()))
+		taken.Release()
+	}
+
+	return NewDataFrameFromShape(m.mem, cols, int64(rightIndices.Len()))
+}
+
+// needsHashJoin returns true if col is a key joined by compute.HashJoin.
+func needsHashJoin(col *array.Column) bool {
+	switch col.DataType().(type) {
+	case *arrow.DictionaryType, *arrow.FixedSizeBinaryType:
+		return true
+	default:
+		return false
+	}
+}
+
 // Acts like SQL in that nil elements are treated as unknown so nil != nil.
 func sharedLeftJoinLogic(data *joinFuncConfig, iterationEndFunc func(bool, *iterator.StepValue)) {
 	// What I want here is a step iterator for the matchingLeftCols.
@@ -385,6 +915,13 @@ func (m *Mutator) InnerJoin(rightDf *DataFrame, columnNames []string, opts ...Op
 		}
 		defer data.Release()
 
+		if data.hashJoin {
+			if err := data.runHashJoin(compute.InnerJoin); err != nil {
+				return nil, err
+			}
+			return data.buildDataFrame()
+		}
+
 		// InnerJoin is basically LeftJoin without appending nulls in iterationEndFunc so we stub that callback.
 		sharedLeftJoinLogic(data, func(bool, *iterator.StepValue) {})
 
@@ -392,6 +929,44 @@ func (m *Mutator) InnerJoin(rightDf *DataFrame, columnNames []string, opts ...Op
 	}
 }
 
+// SemiJoin returns a DataFrame containing the rows of the left DataFrame that match
+// a row of the right DataFrame on the named columns, each kept once, with only the
+// left columns. Acts like SQL in that nil elements are treated as unknown so nil != nil.
+func (m *Mutator) SemiJoin(rightDf *DataFrame, columnNames []string) MutationFunc {
+	return m.filterJoin(rightDf, columnNames, compute.SemiJoin)
+}
+
+// AntiJoin returns a DataFrame containing the rows of the left DataFrame that match
+// no row of the right DataFrame on the named columns, with only the left columns.
+// Rows with a nil element in the named columns never match so they are kept.
+func (m *Mutator) AntiJoin(rightDf *DataFrame, columnNames []string) MutationFunc {
+	return m.filterJoin(rightDf, columnNames, compute.AntiJoin)
+}
+
+// filterJoin takes the left rows selected by compute.SemiJoinRows, which never
+// pairs the left rows with the right ones.
+func (m *Mutator) filterJoin(rightDf *DataFrame, columnNames []string, how compute.JoinType) MutationFunc {
+	return func(leftDf *DataFrame) (*DataFrame, error) {
+		leftKeys := make([]*array.Column, len(columnNames))
+		rightKeys := make([]*array.Column, len(columnNames))
+		for i, name := range columnNames {
+			if leftKeys[i] = leftDf.Column(name); leftKeys[i] == nil {
+				return nil, fmt.Errorf("bullseye/mutations: column %s is not in left DataFrame: (%v)", name, leftDf.ColumnNames())
+			}
+			if rightKeys[i] = rightDf.Column(name); rightKeys[i] == nil {
+				return nil, fmt.Errorf("bullseye/mutations: column %s is not in right DataFrame: (%v)", name, rightDf.ColumnNames())
+			}
+		}
+
+		indices, err := compute.SemiJoinRows(m.mem, leftKeys, rightKeys, how)
+		if err != nil {
+			return nil, err
+		}
+		defer indices.Release()
+		return m.Take(indices)(leftDf)
+	}
+}
+
 // OuterJoin returns a DataFrame containing the outer join of two DataFrames.
 // Use union of keys from both frames, similar to a SQL full outer join.
 // Acts like SQL in that nil elements are treated as unknown so nil != nil.
@@ -408,6 +983,10 @@ func (m *Mutator) OuterJoin(rightDf *DataFrame, columnNames []string, opts ...Op
 		}
 		defer data.Release()
 
+		if data.hashJoin {
+			return nil, fmt.Errorf("mutation: OuterJoin does not support dictionary encoded keys")
+		}
+
 		// Now we iterate over the right first.
 		rightIterator := iterator.NewStepIteratorForColumns(data.rightColumns)
 		defer rightIterator.Release()
@@ -468,6 +1047,9 @@ func outerJoinAnyRowsMatch(rightStepValues *iterator.StepValue, data *joinFuncCo
 }
 
 // CrossJoin returns a DataFrame containing the cross join of two DataFrames.
+// The rows are generated in batches, each becoming a chunk of the columns, and an
+// error is returned before any row is built when there would be more rows than the
+// maximum set by WithMaxRows.
 func (m *Mutator) CrossJoin(rightDf *DataFrame, opts ...Option) MutationFunc {
 	cfg, err := newLeftJoinConfig(opts...)
 	return func(leftDf *DataFrame) (*DataFrame, error) {
@@ -475,39 +1057,72 @@ func (m *Mutator) CrossJoin(rightDf *DataFrame, opts ...Option) MutationFunc {
 			return nil, err
 		}
 
+		leftRows, rightRows := leftDf.NumRows(), rightDf.NumRows()
+		size, err := compute.CrossJoinSize(leftRows, rightRows, cfg.maxRows)
+		if err != nil {
+			return nil, err
+		}
+
 		data, err := m.newJoinFuncConfig(cfg, leftDf, rightDf, nil, false)
 		if err != nil {
 			return nil, err
 		}
 		defer data.Release()
 
-		leftMatchingIterator := iterator.NewStepIteratorForColumns(data.leftColumns)
-		defer leftMatchingIterator.Release()
-		for leftMatchingIterator.Next() { // Iterate through every row in the left df.
-			leftStepValues := leftMatchingIterator.Values()
-
-			func() {
-				rightMatchingIterator := iterator.NewStepIteratorForColumns(data.rightColumns)
-				defer rightMatchingIterator.Release()
-				for rightMatchingIterator.Next() { // Iterate through every row in the right df.
-					rightStepValues := rightMatchingIterator.Values()
-
-					cIdx := 0
+		sources := append(append([]array.Column(nil), data.leftColumns...), data.rightColumns...)
+		chunks := make([][]array.Interface, len(sources))
+		defer func() {
+			for _, arrs := range chunks {
+				for _, arr := range arrs {
+					arr.Release()
+				}
+			}
+		}()
+		for start := int64(0); start < size; start += crossJoinBatchSize {
+			n := size - start
+			if n > crossJoinBatchSize {
+				n = crossJoinBatchSize
+			}
+			if err := func() error {
+				leftIndices, rightIndices, err := compute.CrossJoinRows(m.mem, leftRows, rightRows, start, n)
+				if err != nil {
+					return err
+				}
+				defer leftIndices.Release()
+				defer rightIndices.Release()
 
-					// Add all columns from both frames.
-					for i := range leftStepValues.Values {
-						data.smartBuilder.Append(cIdx, leftStepValues.Values[i])
-						cIdx++
+				for i := range sources {
+					indices := leftIndices
+					if i >= len(data.leftColumns) {
+						indices = rightIndices
 					}
-					for i := range rightStepValues.Values {
-						data.smartBuilder.Append(cIdx, rightStepValues.Values[i])
-						cIdx++
+					taken, err := compute.Take(m.mem, &sources[i], indices)
+					if err != nil {
+						return err
 					}
+					chunk := taken.Data().Chunk(0)
+					chunk.Retain()
+					taken.Release()
+					chunks[i] = append(chunks[i], chunk)
 				}
-			}()
+				return nil
+			}(); err != nil {
+				return nil, err
+			}
 		}
 
-		return data.buildDataFrame()
+		cols := make([]array.Column, 0, len(sources))
+		defer func() {
+			for i := range cols {
+				cols[i].Release()
+			}
+		}()
+		for i, field := range data.schema.Fields() {
+			chunked := array.NewChunked(field.Type, chunks[i])
+			cols = append(cols, *array.NewColumn(field, chunked))
+			chunked.Release()
+		}
+		return NewDataFrameFromShape(m.mem, cols, size)
 	}
 }
 
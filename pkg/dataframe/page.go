@@ -0,0 +1,401 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// Page creates a new DataFrame of at most limit rows of df starting at row
+// offset, as served to a UI a page at a time. An offset past the last row
+// gives an empty DataFrame.
+func (m *Mutator) Page(offset, limit int64) MutationFunc {
+	return func(df *DataFrame) (*DataFrame, error) {
+		if offset < 0 || limit < 0 {
+			return nil, fmt.Errorf("mutation: invalid page at offset %d of %d rows", offset, limit)
+		}
+		rows := df.NumRows()
+		if offset > rows {
+			offset = rows
+		}
+		end := rows
+		if limit < rows-offset {
+			end = offset + limit
+		}
+		return m.Slice(offset, end)(df)
+	}
+}
+
+// Paginator serves the rows of a DataFrame a page at a time, in the order of
+// key columns. Pages are resumed from a cursor encoding the key of the last
+// row served rather than its position, so they stay stable when the
+// DataFrame is refreshed between two pages: a new Paginator on the refreshed
+// DataFrame resumes after the same key, neither repeating nor skipping rows
+// because rows were added or removed before it. The keys must identify the
+// rows, adding a unique column as the last key if needed, since rows whose
+// keys equal the key of the cursor are skipped.
+type Paginator struct {
+	df     *DataFrame
+	fields []arrow.Field
+	orders []compute.SortOrder
+
+	indices *array.Int64      // rows of df in the order of the keys
+	keys    []array.Interface // key columns in the order of the keys
+}
+
+// NewPaginator creates a Paginator of the rows of df ordered by the named key
+// columns, each key in the matching order. The Paginator must be released.
+func NewPaginator(df *DataFrame, keys []string, orders []compute.SortOrder) (*Paginator, error) {
+	if len(keys) == 0 || len(keys) != len(orders) {
+		return nil, fmt.Errorf("dataframe: a Paginator needs an order per key (%d != %d)", len(keys), len(orders))
+	}
+	mem := df.Allocator()
+	cols := make([]*array.Column, len(keys))
+	for i, name := range keys {
+		if cols[i] = df.Column(name); cols[i] == nil {
+			return nil, fmt.Errorf("dataframe: column %q is not in DataFrame: (%v)", name, df.ColumnNames())
+		}
+	}
+	indices, err := compute.SortIndices(mem, cols, orders)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Paginator{df: df, orders: orders, indices: indices}
+	df.Retain()
+	for _, col := range cols {
+		sorted, err := compute.Take(mem, col, indices)
+		if err != nil {
+			p.Release()
+			return nil, err
+		}
+		key := sorted.Data().Chunk(0)
+		key.Retain()
+		sorted.Release()
+		p.fields = append(p.fields, col.Field())
+		p.keys = append(p.keys, key)
+	}
+	return p, nil
+}
+
+// Page returns the page of at most limit rows following the row of cursor,
+// in the order of the keys, and the cursor of the next page. The first page
+// follows the empty cursor, and the cursor of the last page is empty.
+func (p *Paginator) Page(cursor string, limit int64) (page *DataFrame, next string, err error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("dataframe: invalid page of %d rows", limit)
+	}
+	n := int64(p.indices.Len())
+	start := int64(0)
+	if cursor != "" {
+		if start, err = p.after(cursor); err != nil {
+			return nil, "", err
+		}
+	}
+	end := n
+	if limit < n-start {
+		end = start + limit
+	}
+
+	rows := array.NewSlice(p.indices, start, end).(*array.Int64)
+	defer rows.Release()
+	if page, err = p.df.Take(rows); err != nil {
+		return nil, "", err
+	}
+	if end < n {
+		if next, err = p.encode(end - 1); err != nil {
+			page.Release()
+			return nil, "", err
+		}
+	}
+	return page, next, nil
+}
+
+// Cursors encode the keys of a row in a format of their own rather than in the
+// Arrow IPC format: they come from clients, and decoding one must neither
+// panic nor allocate more than its size. A cursor is, base64 encoded:
+//
+//	version      byte
+//	fingerprint  uint32, little endian, of the names and types of the keys
+//	keys         a value per key
+//
+// where a value is a byte, 0 for a null and 1 otherwise, followed for
+// non-null values by the byte of a boolean, the uvarint length and the bytes
+// of a string or a binary, or the little endian bytes of the other fixed
+// width types. Dictionary keys are encoded as their values.
+const (
+	cursorVersion = 1
+	// maxCursorLen bounds the length of the cursors decoded.
+	maxCursorLen = 1 << 16
+)
+
+// encode returns the cursor of the i-th row in the order of the keys.
+func (p *Paginator) encode(i int64) (string, error) {
+	buf := make([]byte, 5, 64)
+	buf[0] = cursorVersion
+	binary.LittleEndian.PutUint32(buf[1:], p.fingerprint())
+	for k, key := range p.keys {
+		var err error
+		if buf, err = appendCursorKey(buf, key, int(i)); err != nil {
+			return "", fmt.Errorf("dataframe: could not encode cursor key %q: %w", p.fields[k].Name, err)
+		}
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// fingerprint returns the hash of the names and types of the keys, so that
+// the cursors of other keys are rejected.
+func (p *Paginator) fingerprint() uint32 {
+	h := fnv.New32a()
+	for _, f := range p.fields {
+		fmt.Fprintf(h, "%q:%v;", f.Name, f.Type)
+	}
+	return h.Sum32()
+}
+
+// after returns the position of the first row, in the order of the keys,
+// whose keys follow those of cursor.
+func (p *Paginator) after(cursor string) (int64, error) {
+	if len(cursor) > maxCursorLen {
+		return 0, fmt.Errorf("dataframe: invalid cursor of %d bytes", len(cursor))
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("dataframe: invalid cursor: %w", err)
+	}
+	r := &cursorReader{data: data}
+	if v := r.byte(); v != cursorVersion {
+		return 0, fmt.Errorf("dataframe: invalid cursor version %d", v)
+	}
+	if fp := r.uint32(); r.err == nil && fp != p.fingerprint() {
+		return 0, fmt.Errorf("dataframe: cursor of other keys, want %v", p.fields)
+	}
+
+	// Chunk 0 of the keys is the row of the cursor, chunk 1 the sorted rows.
+	chunks := make([][]array.Interface, 0, len(p.keys))
+	defer func() {
+		for _, c := range chunks {
+			c[0].Release()
+		}
+	}()
+	for k, key := range p.keys {
+		row, err := r.key(p.fields[k].Type)
+		if err != nil {
+			return 0, fmt.Errorf("dataframe: invalid cursor key %q: %w", p.fields[k].Name, err)
+		}
+		chunks = append(chunks, []array.Interface{row, key})
+	}
+	if r.err == nil && len(r.data) != 0 {
+		r.err = fmt.Errorf("%d trailing bytes", len(r.data))
+	}
+	if r.err != nil {
+		return 0, fmt.Errorf("dataframe: invalid cursor: %w", r.err)
+	}
+
+	cmp, err := compute.NewRowComparator(chunks, p.orders)
+	if err != nil {
+		return 0, err
+	}
+	n := p.indices.Len()
+	return int64(sort.Search(n, func(i int) bool { return cmp.Compare(1, i, 0, 0) > 0 })), nil
+}
+
+// appendCursorKey appends the value at index i of arr to the cursor buf.
+func appendCursorKey(buf []byte, arr array.Interface, i int) ([]byte, error) {
+	if dict, ok := arr.(*array.Dictionary); ok && dict.IsValid(i) {
+		return appendCursorKey(buf, dict.Dictionary(), dict.GetValueIndex(i))
+	}
+	if arr.IsNull(i) {
+		return append(buf, 0), nil
+	}
+	buf = append(buf, 1)
+
+	switch a := arr.(type) {
+	case *array.Boolean:
+		if a.Value(i) {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case *array.String:
+		return appendCursorBytes(buf, []byte(a.Value(i))), nil
+	case *array.Binary:
+		return appendCursorBytes(buf, a.Value(i)), nil
+	}
+	w := cursorByteWidth(arr.DataType())
+	if w == 0 {
+		return nil, fmt.Errorf("unsupported type %v", arr.DataType())
+	}
+	data := arr.Data()
+	beg := (data.Offset() + i) * w
+	return append(buf, data.Buffers()[1].Bytes()[beg:beg+w]...), nil
+}
+
+func appendCursorBytes(buf, v []byte) []byte {
+	var n [binary.MaxVarintLen64]byte
+	buf = append(buf, n[:binary.PutUvarint(n[:], uint64(len(v)))]...)
+	return append(buf, v...)
+}
+
+// cursorByteWidth returns the number of bytes of the values of dtype, or 0 for
+// the types not encoded as their bytes.
+func cursorByteWidth(dtype arrow.DataType) int {
+	switch dt := dtype.(type) {
+	case *arrow.BooleanType:
+		return 0
+	case *arrow.Decimal128Type:
+		// BitWidth of Decimal128Type returns its width in bytes.
+		return arrow.Decimal128SizeBytes
+	case arrow.FixedWidthDataType:
+		if dt.BitWidth()%8 == 0 {
+			return dt.BitWidth() / 8
+		}
+	}
+	return 0
+}
+
+// cursorReader decodes the values of a cursor, keeping the first error.
+type cursorReader struct {
+	data []byte
+	err  error
+}
+
+func (r *cursorReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = fmt.Errorf("truncated cursor")
+		return nil
+	}
+	v := r.data[:n:n]
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *cursorReader) byte() byte {
+	if v := r.next(1); v != nil {
+		return v[0]
+	}
+	return 0
+}
+
+func (r *cursorReader) uint32() uint32 {
+	if v := r.next(4); v != nil {
+		return binary.LittleEndian.Uint32(v)
+	}
+	return 0
+}
+
+// key decodes a value of dtype to an array of a single row. The array must
+// be released.
+func (r *cursorReader) key(dtype arrow.DataType) (array.Interface, error) {
+	if dict, ok := dtype.(*arrow.DictionaryType); ok {
+		values, err := r.key(dict.ValueType)
+		if err != nil {
+			return nil, err
+		}
+		defer values.Release()
+		w := cursorByteWidth(dict.IndexType)
+		if w == 0 {
+			return nil, fmt.Errorf("unsupported dictionary index type %v", dict.IndexType)
+		}
+		// The row is null when its value is, and indexes the value otherwise.
+		indices := newCursorRow(dict.IndexType, values.IsNull(0), memory.NewBufferBytes(make([]byte, w)))
+		defer indices.Release()
+		return array.NewDictionaryArray(dict, indices, values), nil
+	}
+
+	null := false
+	switch flag := r.byte(); {
+	case r.err != nil:
+		return nil, r.err
+	case flag > 1:
+		return nil, fmt.Errorf("invalid null flag %d", flag)
+	default:
+		null = flag == 0
+	}
+
+	switch dtype.(type) {
+	case *arrow.BooleanType:
+		v := byte(0)
+		if !null {
+			if v = r.byte(); v > 1 {
+				return nil, fmt.Errorf("invalid boolean %d", v)
+			}
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		return newCursorRow(dtype, null, memory.NewBufferBytes([]byte{v})), nil
+	case *arrow.StringType, *arrow.BinaryType:
+		var v []byte
+		if !null {
+			n, size := binary.Uvarint(r.data)
+			if size <= 0 || n > uint64(len(r.data)-size) {
+				return nil, fmt.Errorf("invalid length of %d bytes", n)
+			}
+			r.next(size)
+			v = append([]byte(nil), r.next(int(n))...)
+		}
+		offsets := make([]byte, 2*arrow.Int32SizeBytes)
+		binary.LittleEndian.PutUint32(offsets[arrow.Int32SizeBytes:], uint32(len(v)))
+		return newCursorRow(dtype, null, memory.NewBufferBytes(offsets), memory.NewBufferBytes(v)), nil
+	}
+
+	w := cursorByteWidth(dtype)
+	if w == 0 {
+		return nil, fmt.Errorf("unsupported type %v", dtype)
+	}
+	v := make([]byte, w)
+	if !null {
+		copy(v, r.next(w))
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return newCursorRow(dtype, null, memory.NewBufferBytes(v)), nil
+}
+
+// newCursorRow returns an array of dtype of a single row, null or not, made of
+// buffers following its validity bitmap.
+func newCursorRow(dtype arrow.DataType, null bool, buffers ...*memory.Buffer) array.Interface {
+	valid, nulls := []byte{1}, 0
+	if null {
+		valid, nulls = []byte{0}, 1
+	}
+	buffers = append([]*memory.Buffer{memory.NewBufferBytes(valid)}, buffers...)
+	data := array.NewData(dtype, 1, buffers, nil, nulls, 0)
+	defer data.Release()
+	return array.MakeFromData(data)
+}
+
+// Release releases the Paginator and its reference to the DataFrame.
+func (p *Paginator) Release() {
+	for _, key := range p.keys {
+		key.Release()
+	}
+	p.keys = nil
+	p.indices.Release()
+	p.df.Release()
+}
@@ -0,0 +1,49 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package dataframe
+
+import (
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func FuzzPaginatorCursor(f *testing.F) {
+	p, _ := newCursorPaginator(f, memory.NewGoAllocator())
+	defer p.Release()
+	for _, limit := range []int64{1, 5, 11} {
+		page, cursor, err := p.Page("", limit)
+		if err != nil {
+			f.Fatal(err)
+		}
+		page.Release()
+		f.Add(cursor)
+	}
+	f.Add("AQAAAAA")
+
+	f.Fuzz(func(t *testing.T, cursor string) {
+		page, _, err := p.Page(cursor, 5)
+		if err != nil {
+			return
+		}
+		if page.NumRows() > 5 {
+			t.Fatalf("page of %d rows", page.NumRows())
+		}
+		page.Release()
+	})
+}
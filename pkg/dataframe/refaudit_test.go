@@ -0,0 +1,69 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build refaudit
+
+package dataframe
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestRefAuditLeakReport(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := NewDataFrameFromMem(pool, Dict{
+		"col1": []int32{1, 2, 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := LeakReport()
+	if !strings.Contains(report, "DataFrame") || !strings.Contains(report, "column col1") {
+		t.Fatalf("expected the DataFrame and its column in the report, got:\n%s", report)
+	}
+	if !strings.Contains(report, "TestRefAuditLeakReport") {
+		t.Fatalf("expected the test to be named as the holder, got:\n%s", report)
+	}
+
+	df.Release()
+	if report := LeakReport(); report != "" {
+		t.Fatalf("expected an empty report, got:\n%s", report)
+	}
+}
+
+func TestRefAuditDoubleRelease(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := NewDataFrameFromMem(pool, Dict{
+		"col1": []int32{1, 2, 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	df.Release()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic on the second release")
+		}
+	}()
+	df.Release()
+}
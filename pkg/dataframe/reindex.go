@@ -0,0 +1,243 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// FillPolicy selects the values Reindex gives to the keys a DataFrame does
+// not have.
+type FillPolicy int
+
+const (
+	// FillNull gives them nulls.
+	FillNull FillPolicy = iota
+	// FillForward gives them the values of the row holding the largest
+	// smaller key, or nulls when there is none.
+	FillForward
+	// FillBackward gives them the values of the row holding the smallest
+	// larger key, or nulls when there is none.
+	FillBackward
+)
+
+// Reindex conforms a DataFrame to the keys of target: the DataFrame has a row
+// per row of target, holding its key, and the values of the row of the
+// DataFrame holding the same key or, when there is none, the values selected
+// by fill. FillForward and FillBackward need an OrderedIndex.
+//
+// The DataFrame must have an index of the kind of target and hold each key at
+// most once. The columns keep their names and types, and the DataFrame is
+// indexed like the DataFrame it comes from.
+func (m *Mutator) Reindex(target Index, fill FillPolicy) MutationFunc {
+	return func(df *DataFrame) (*DataFrame, error) {
+		idx := df.Index()
+		if idx == nil {
+			return nil, fmt.Errorf("mutation: cannot reindex a DataFrame without index")
+		}
+		if fmt.Sprintf("%T", idx) != fmt.Sprintf("%T", target) || len(idx.Columns()) != len(target.Columns()) {
+			return nil, fmt.Errorf("mutation: cannot reindex a %T with a %T", idx, target)
+		}
+		ordered, isOrdered := idx.(OrderedIndex)
+		if fill != FillNull && !isOrdered {
+			return nil, fmt.Errorf("mutation: filling needs an ordered index, not a %T", idx)
+		}
+
+		rows := make([]int64, target.Len())
+		for i := range rows {
+			k := target.key(int64(i))
+			rows[i] = -1
+			if k == nil {
+				continue
+			}
+			matches, err := idx.Lookup(k)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case len(matches) > 1:
+				return nil, fmt.Errorf("mutation: duplicate index key %v", k)
+			case len(matches) == 1:
+				rows[i] = matches[0]
+			case fill != FillNull:
+				pos, err := searchKey(ordered, k)
+				if err != nil {
+					return nil, err
+				}
+				if fill == FillForward {
+					rows[i] = pos - 1
+				} else if pos < idx.Len() {
+					rows[i] = pos
+				}
+			}
+		}
+
+		cols := make([]array.Column, 0, df.NumCols())
+		defer func() {
+			for i := range cols {
+				cols[i].Release()
+			}
+		}()
+		indices := newNullableIndices(m.mem, rows)
+		defer indices.Release()
+
+		level := make(map[string]int)
+		for i, name := range idx.Columns() {
+			level[name] = i
+		}
+		dfCols := df.Columns()
+		for i := range dfCols {
+			l, ok := level[dfCols[i].Name()]
+			if !ok {
+				taken, err := compute.Take(m.mem, &dfCols[i], indices)
+				if err != nil {
+					return nil, err
+				}
+				field := dfCols[i].Field()
+				field.Nullable = field.Nullable || indices.NullN() > 0
+				cols = append(cols, *array.NewColumn(field, taken.Data()))
+				taken.Release()
+				continue
+			}
+			col, err := m.keyColumn(dfCols[i].Field(), target, l)
+			if err != nil {
+				return nil, err
+			}
+			cols = append(cols, *col)
+		}
+
+		out, err := NewDataFrameFromShape(m.mem, cols, target.Len())
+		if err != nil {
+			return nil, err
+		}
+		return reindex(out, idx)
+	}
+}
+
+// keyColumn returns the column of field holding the keys of target at the
+// given level.
+func (m *Mutator) keyColumn(field arrow.Field, target Index, level int) (*array.Column, error) {
+	bldr := array.NewBuilder(m.mem, field.Type)
+	defer bldr.Release()
+	for i := int64(0); i < target.Len(); i++ {
+		k := target.key(i)
+		if values, ok := k.([]interface{}); ok {
+			k = values[level]
+		}
+		if k == nil {
+			field.Nullable = true
+			bldr.AppendNull()
+			continue
+		}
+		if err := appendKey(bldr, field.Type, k); err != nil {
+			return nil, fmt.Errorf("mutation: column %q: %w", field.Name, err)
+		}
+	}
+	arr := bldr.NewArray()
+	defer arr.Release()
+	chunked := array.NewChunked(field.Type, []array.Interface{arr})
+	defer chunked.Release()
+	return array.NewColumn(field, chunked), nil
+}
+
+// appendKey appends a key returned by Index.key to a builder of dtype.
+func appendKey(bldr array.Builder, dtype arrow.DataType, key interface{}) error {
+	switch b := bldr.(type) {
+	case *array.StringBuilder:
+		b.Append(key.(string))
+		return nil
+	case *array.TimestampBuilder:
+		ns := key.(time.Time).UnixNano()
+		b.Append(arrow.Timestamp(ns / unitNanoseconds(dtype.(*arrow.TimestampType).Unit)))
+		return nil
+	}
+
+	k := key.(int64)
+	inRange := func(min, max float64) error {
+		if float64(k) < min || float64(k) > max {
+			return fmt.Errorf("index key %d out of range for %s", k, dtype)
+		}
+		return nil
+	}
+	switch b := bldr.(type) {
+	case *array.Int8Builder:
+		if err := inRange(math.MinInt8, math.MaxInt8); err != nil {
+			return err
+		}
+		b.Append(int8(k))
+	case *array.Int16Builder:
+		if err := inRange(math.MinInt16, math.MaxInt16); err != nil {
+			return err
+		}
+		b.Append(int16(k))
+	case *array.Int32Builder:
+		if err := inRange(math.MinInt32, math.MaxInt32); err != nil {
+			return err
+		}
+		b.Append(int32(k))
+	case *array.Int64Builder:
+		b.Append(k)
+	case *array.Uint8Builder:
+		if err := inRange(0, math.MaxUint8); err != nil {
+			return err
+		}
+		b.Append(uint8(k))
+	case *array.Uint16Builder:
+		if err := inRange(0, math.MaxUint16); err != nil {
+			return err
+		}
+		b.Append(uint16(k))
+	case *array.Uint32Builder:
+		if err := inRange(0, math.MaxUint32); err != nil {
+			return err
+		}
+		b.Append(uint32(k))
+	case *array.Uint64Builder:
+		if err := inRange(0, math.MaxInt64); err != nil {
+			return err
+		}
+		b.Append(uint64(k))
+	default:
+		return fmt.Errorf("cannot build index keys of type %s", dtype)
+	}
+	return nil
+}
+
+// searchKey returns the position of the first row of idx whose key is not
+// less than the key k.
+func searchKey(idx OrderedIndex, k interface{}) (int64, error) {
+	switch idx := idx.(type) {
+	case *IntIndex:
+		n, err := intKey(k)
+		if err != nil {
+			return 0, err
+		}
+		return idx.search(n), nil
+	case *TimestampIndex:
+		n, err := timeKey(k)
+		if err != nil {
+			return 0, err
+		}
+		return idx.search(n), nil
+	default:
+		return 0, fmt.Errorf("mutation: cannot search a %T", idx)
+	}
+}
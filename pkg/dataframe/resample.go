@@ -0,0 +1,104 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// Resample groups the rows of a DataFrame with a TimestampIndex into windows
+// of the given duration, aligned on the epoch, and aggregates the named
+// columns, or every column but the index, over each window with
+// compute.AggregateGroups. The DataFrame holds a row per window holding rows,
+// in time order: the start of the window in the index column followed by the
+// aggregates, and is indexed by the start of the windows.
+func (m *Mutator) Resample(every time.Duration, kind compute.AggregateKind, columnNames ...string) MutationFunc {
+	return func(df *DataFrame) (*DataFrame, error) {
+		idx, ok := df.Index().(*TimestampIndex)
+		if !ok {
+			return nil, fmt.Errorf("mutation: resampling a DataFrame without timestamp index")
+		}
+		scale := unitNanoseconds(idx.dtype.Unit)
+		if every <= 0 || int64(every)%scale != 0 {
+			return nil, fmt.Errorf("mutation: invalid resampling period %v for unit %s", every, idx.dtype.Unit)
+		}
+
+		if len(columnNames) == 0 {
+			for _, name := range df.ColumnNames() {
+				if name != idx.column {
+					columnNames = append(columnNames, name)
+				}
+			}
+		}
+		aggregated := make([]*array.Column, len(columnNames))
+		for i, name := range columnNames {
+			if aggregated[i] = df.Column(name); aggregated[i] == nil {
+				return nil, fmt.Errorf("mutation: column %q is not in DataFrame: (%v)", name, df.ColumnNames())
+			}
+		}
+
+		// The keys are sorted, so the rows of a window are contiguous.
+		g := &compute.Groups{IDs: make([]int32, len(idx.keys))}
+		var starts []arrow.Timestamp
+		for i, k := range idx.keys {
+			start := k - k%int64(every)
+			if k%int64(every) < 0 {
+				start -= int64(every)
+			}
+			ts := arrow.Timestamp(start / scale)
+			if len(starts) == 0 || starts[len(starts)-1] != ts {
+				starts = append(starts, ts)
+				g.First = append(g.First, int64(i))
+			}
+			g.IDs[i] = int32(len(starts) - 1)
+		}
+
+		cols := make([]array.Column, 0, len(columnNames)+1)
+		defer func() {
+			for i := range cols {
+				cols[i].Release()
+			}
+		}()
+		bldr := array.NewTimestampBuilder(m.mem, idx.dtype)
+		defer bldr.Release()
+		bldr.AppendValues(starts, nil)
+		arr := bldr.NewArray()
+		chunked := array.NewChunked(idx.dtype, []array.Interface{arr})
+		arr.Release()
+		cols = append(cols, *array.NewColumn(df.Column(idx.column).Field(), chunked))
+		chunked.Release()
+
+		for _, col := range aggregated {
+			agg, err := compute.AggregateGroups(m.mem, col, g, kind)
+			if err != nil {
+				return nil, err
+			}
+			field := arrow.Field{Name: col.Name(), Type: agg.DataType(), Nullable: true}
+			cols = append(cols, *array.NewColumn(field, agg.Data()))
+			agg.Release()
+		}
+
+		out, err := NewDataFrameFromShape(m.mem, cols, int64(len(starts)))
+		if err != nil {
+			return nil, err
+		}
+		return reindex(out, idx)
+	}
+}
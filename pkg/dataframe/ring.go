@@ -0,0 +1,291 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// ringConfig are the config params for NewRing.
+type ringConfig struct {
+	maxRows   int64
+	column    string
+	retention time.Duration
+}
+
+func newRingConfig(opts ...Option) (*ringConfig, error) {
+	cfg := &ringConfig{}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return cfg, err
+		}
+	}
+	if cfg.maxRows == 0 && cfg.column == "" {
+		return cfg, fmt.Errorf("dataframe: a Ring needs WithMaxRows or WithRetention")
+	}
+	return cfg, nil
+}
+
+// WithMaxRows configures a Ring to keep its last n rows, or a CrossJoin to fail
+// when it would have more than n rows.
+func WithMaxRows(n int64) Option {
+	return func(p interface{}) error {
+		if n <= 0 {
+			return fmt.Errorf("dataframe: max rows must be positive, got %d", n)
+		}
+		switch o := p.(type) {
+		case *ringConfig:
+			o.maxRows = n
+		case *leftJoinConfig:
+			o.maxRows = n
+		default:
+			return fmt.Errorf("cannot apply WithMaxRows to: %T", p)
+		}
+		return nil
+	}
+}
+
+// WithRetention configures a Ring to keep the rows whose value of the named
+// timestamp column is within d of the latest one.
+func WithRetention(column string, d time.Duration) Option {
+	return func(p interface{}) error {
+		o, ok := p.(*ringConfig)
+		if !ok {
+			return fmt.Errorf("cannot apply WithRetention to: %T", p)
+		}
+		if d <= 0 {
+			return fmt.Errorf("dataframe: retention must be positive, got %v", d)
+		}
+		o.column, o.retention = column, d
+		return nil
+	}
+}
+
+// Ring is an append-only table keeping only its latest rows, by count with
+// WithMaxRows and by age with WithRetention, for in-process telemetry. Every
+// appended record is copied into a chunk of the Ring, and the oldest chunks
+// are dropped once they hold no retained row. The chunks are allocated from
+// a memory.PoolAllocator, so the buffers of the chunks dropped are reused by
+// the chunks appended next.
+//
+// Appending small records makes small chunks, appenders should batch rows.
+// A Ring is safe to use from multiple goroutines.
+type Ring struct {
+	mu     sync.Mutex
+	pool   *memory.PoolAllocator
+	schema *arrow.Schema
+	cfg    *ringConfig
+
+	// chunks are the records held, oldest first, and rows their rows.
+	chunks []array.Record
+	rows   int64
+
+	// ts is the index of the retention column, -1 without retention, span
+	// the retention in its unit and last its latest value.
+	ts   int
+	span int64
+	last int64
+}
+
+// NewRing returns an empty Ring of the given schema allocating from mem.
+// The retention column of WithRetention must be a timestamp column whose
+// values are not null and do not decrease.
+func NewRing(mem memory.Allocator, schema *arrow.Schema, opts ...Option) (*Ring, error) {
+	cfg, err := newRingConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	r := &Ring{pool: memory.NewPoolAllocator(mem), schema: schema, cfg: cfg, ts: -1}
+	if cfg.column != "" {
+		indices := schema.FieldIndices(cfg.column)
+		if len(indices) == 0 {
+			return nil, fmt.Errorf("dataframe: retention column %q not found", cfg.column)
+		}
+		dtype, ok := schema.Field(indices[0]).Type.(*arrow.TimestampType)
+		if !ok {
+			return nil, fmt.Errorf("dataframe: retention column %q of type %s is not a timestamp", cfg.column, schema.Field(indices[0]).Type)
+		}
+		r.ts = indices[0]
+		r.span = int64(cfg.retention) / unitNanoseconds(dtype.Unit)
+	}
+	return r, nil
+}
+
+// Schema returns the schema of the Ring.
+func (r *Ring) Schema() *arrow.Schema { return r.schema }
+
+// NumRows returns the number of rows retained.
+func (r *Ring) NumRows() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rows - r.skipped()
+}
+
+// Append copies the rows of rec at the end of the Ring, and drops the chunks
+// holding no retained row.
+func (r *Ring) Append(rec array.Record) error {
+	if !rec.Schema().Equal(r.schema) {
+		return fmt.Errorf("dataframe: cannot append a record of schema %s to a Ring of schema %s", rec.Schema(), r.schema)
+	}
+	if rec.NumRows() == 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	last := r.last
+	if r.ts >= 0 {
+		ts := rec.Column(r.ts).(*array.Timestamp)
+		if ts.NullN() > 0 {
+			return fmt.Errorf("dataframe: retention column %q holds nulls", r.cfg.column)
+		}
+		check := len(r.chunks) > 0
+		for i := 0; i < ts.Len(); i++ {
+			v := int64(ts.Value(i))
+			if check && v < last {
+				return fmt.Errorf("dataframe: retention column %q goes back in time at row %d", r.cfg.column, i)
+			}
+			last, check = v, true
+		}
+	}
+
+	chunk, err := r.copyRecord(rec)
+	if err != nil {
+		return err
+	}
+	r.chunks = append(r.chunks, chunk)
+	r.rows += chunk.NumRows()
+	r.last = last
+	r.evict()
+	return nil
+}
+
+// copyRecord copies rec into buffers allocated from the pool.
+func (r *Ring) copyRecord(rec array.Record) (array.Record, error) {
+	bldr := array.NewInt64Builder(r.pool)
+	defer bldr.Release()
+	for i := int64(0); i < rec.NumRows(); i++ {
+		bldr.Append(i)
+	}
+	indices := bldr.NewInt64Array()
+	defer indices.Release()
+
+	cols := make([]array.Interface, 0, rec.NumCols())
+	defer func() {
+		for _, col := range cols {
+			col.Release()
+		}
+	}()
+	for i, arr := range rec.Columns() {
+		chunked := array.NewChunked(arr.DataType(), []array.Interface{arr})
+		col := array.NewColumn(r.schema.Field(i), chunked)
+		chunked.Release()
+		taken, err := compute.Take(r.pool, col, indices)
+		col.Release()
+		if err != nil {
+			return nil, err
+		}
+		copied := taken.Data().Chunk(0)
+		copied.Retain()
+		taken.Release()
+		cols = append(cols, copied)
+	}
+	return array.NewRecord(r.schema, cols, rec.NumRows()), nil
+}
+
+// evict drops the oldest chunks while they hold no retained row.
+func (r *Ring) evict() {
+	n := 0
+	for n < len(r.chunks)-1 {
+		rows := r.chunks[n].NumRows()
+		expired := r.cfg.maxRows > 0 && r.rows-rows >= r.cfg.maxRows
+		if r.ts >= 0 {
+			ts := r.chunks[n].Column(r.ts).(*array.Timestamp)
+			expired = expired || int64(ts.Value(ts.Len()-1)) < r.last-r.span
+		}
+		if !expired {
+			break
+		}
+		r.chunks[n].Release()
+		r.rows -= rows
+		n++
+	}
+	if n > 0 {
+		copy(r.chunks, r.chunks[n:])
+		for i := len(r.chunks) - n; i < len(r.chunks); i++ {
+			r.chunks[i] = nil
+		}
+		r.chunks = r.chunks[:len(r.chunks)-n]
+	}
+}
+
+// skipped returns the number of rows of the oldest chunk that are not
+// retained.
+func (r *Ring) skipped() int64 {
+	if len(r.chunks) == 0 {
+		return 0
+	}
+	var skip int64
+	if r.cfg.maxRows > 0 && r.rows > r.cfg.maxRows {
+		skip = r.rows - r.cfg.maxRows
+	}
+	if r.ts >= 0 {
+		ts := r.chunks[0].Column(r.ts).(*array.Timestamp)
+		cutoff := r.last - r.span
+		expired := int64(sort.Search(ts.Len(), func(i int) bool { return int64(ts.Value(i)) >= cutoff }))
+		if expired > skip {
+			skip = expired
+		}
+	}
+	return skip
+}
+
+// Frame returns a DataFrame holding the rows retained, sharing the chunks of
+// the Ring. The chunks go back to the pool once the Ring dropped them and the
+// DataFrame is released.
+func (r *Ring) Frame() (*DataFrame, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	recs := make([]array.Record, len(r.chunks))
+	copy(recs, r.chunks)
+	if skip := r.skipped(); skip > 0 {
+		recs[0] = recs[0].NewSlice(skip, recs[0].NumRows())
+		defer recs[0].Release()
+	}
+	table := array.NewTableFromRecords(r.schema, recs)
+	defer table.Release()
+	return NewDataFrameFromTable(r.pool, table)
+}
+
+// Release releases the chunks of the Ring and returns the buffers kept by its
+// pool to the allocator of the Ring.
+func (r *Ring) Release() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, chunk := range r.chunks {
+		chunk.Release()
+	}
+	r.chunks, r.rows = nil, 0
+	r.pool.SetLimit(0)
+}
@@ -0,0 +1,118 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// ReservoirSample creates a new DataFrame with n rows drawn uniformly at random
+// from the records of rdr, in a single pass and without knowing their number in
+// advance. Only the records holding sampled rows are kept in memory. The rows
+// are kept in the order they were read.
+func ReservoirSample(mem memory.Allocator, rdr array.RecordReader, n int, seed int64) (*DataFrame, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("dataframe: cannot sample %d rows", n)
+	}
+
+	type slot struct {
+		rec int
+		row int64
+	}
+
+	var (
+		res   = compute.NewReservoir(n, seed)
+		recs  []array.Record
+		refs  []int
+		slots = make([]slot, 0, n)
+	)
+	defer func() {
+		for _, rec := range recs {
+			if rec != nil {
+				rec.Release()
+			}
+		}
+	}()
+
+	for rdr.Next() {
+		rec := rdr.Record()
+		rec.Retain()
+		ri := len(recs)
+		recs = append(recs, rec)
+		refs = append(refs, 0)
+
+		for row := int64(0); row < rec.NumRows(); row++ {
+			i, _ := res.Offer()
+			switch {
+			case i < 0:
+				continue
+			case i == len(slots):
+				slots = append(slots, slot{})
+			default:
+				old := slots[i].rec
+				if refs[old]--; refs[old] == 0 && old != ri {
+					recs[old].Release()
+					recs[old] = nil
+				}
+			}
+			slots[i] = slot{rec: ri, row: row}
+			refs[ri]++
+		}
+		if refs[ri] == 0 {
+			rec.Release()
+			recs[ri] = nil
+		}
+	}
+
+	// Number the rows of the records still holding sampled rows.
+	var (
+		live    []array.Record
+		offsets = make([]int64, len(recs))
+		offset  int64
+	)
+	for i, rec := range recs {
+		if rec != nil {
+			live = append(live, rec)
+			offsets[i] = offset
+			offset += rec.NumRows()
+		}
+	}
+	rows := make([]int64, len(slots))
+	for i, s := range slots {
+		rows[i] = offsets[s.rec] + s.row
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i] < rows[j] })
+
+	bldr := array.NewInt64Builder(mem)
+	defer bldr.Release()
+	bldr.AppendValues(rows, nil)
+	indices := bldr.NewInt64Array()
+	defer indices.Release()
+
+	tbl := array.NewTableFromRecords(rdr.Schema(), live)
+	defer tbl.Release()
+	df, err := NewDataFrameFromTable(mem, tbl)
+	if err != nil {
+		return nil, err
+	}
+	defer df.Release()
+
+	return df.Take(indices)
+}
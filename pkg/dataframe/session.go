@@ -0,0 +1,70 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// SessionColumn is the name of the column of session ids added by Sessionize.
+const SessionColumn = "session_id"
+
+// Sessionize creates a new DataFrame with a column named SessionColumn holding
+// the session of every row, as computed by compute.Sessionize: the rows of
+// equal values of byCols, in the order of the timestamp column tsCol, are in
+// the same session until the time between two of them exceeds gap. A column
+// named SessionColumn is replaced.
+func (m *Mutator) Sessionize(byCols []string, tsCol string, gap time.Duration) MutationFunc {
+	return func(df *DataFrame) (*DataFrame, error) {
+		keys := make([]*array.Column, len(byCols))
+		for i, name := range byCols {
+			if keys[i] = df.Column(name); keys[i] == nil {
+				return nil, fmt.Errorf("mutation: column %q is not in DataFrame: (%v)", name, df.ColumnNames())
+			}
+		}
+		ts := df.Column(tsCol)
+		if ts == nil {
+			return nil, fmt.Errorf("mutation: column %q is not in DataFrame: (%v)", tsCol, df.ColumnNames())
+		}
+
+		sessions, err := compute.Sessionize(m.mem, keys, ts, gap)
+		if err != nil {
+			return nil, err
+		}
+		defer sessions.Release()
+		field := sessions.Field()
+		field.Name = SessionColumn
+		col := array.NewColumn(field, sessions.Data())
+		defer col.Release()
+
+		cols := make([]array.Column, 0, df.NumCols()+1)
+		replaced := false
+		for _, c := range df.Columns() {
+			if c.Name() == SessionColumn {
+				c = *col
+				replaced = true
+			}
+			cols = append(cols, c)
+		}
+		if !replaced {
+			cols = append(cols, *col)
+		}
+		return NewDataFrameFromShape(m.mem, cols, df.NumRows())
+	}
+}
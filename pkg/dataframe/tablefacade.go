@@ -15,8 +15,8 @@
 package dataframe
 
 import (
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
 )
 
 // I don't want to force the DataFrame API to conform to the TableReader API.
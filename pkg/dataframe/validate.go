@@ -0,0 +1,84 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataframe
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+)
+
+// validateConfig are the config params for Validate.
+type validateConfig struct {
+	utf8 bool
+}
+
+// WithUTF8Validation makes Validate also check that the strings of the
+// DataFrame are valid UTF-8.
+func WithUTF8Validation() Option {
+	return func(p interface{}) error {
+		o, ok := p.(*validateConfig)
+		if !ok {
+			return fmt.Errorf("cannot apply WithUTF8Validation to: %T", p)
+		}
+		o.utf8 = true
+		return nil
+	}
+}
+
+// Validate checks that df is consistent: its columns match its schema and
+// hold one value per row, their chunks have the type of the column, the
+// buffers of every chunk pass array.ValidateFull and its index, if any,
+// indexes every row. It is meant as a guardrail after building a DataFrame
+// from buffers without a builder.
+func Validate(df *DataFrame, opts ...Option) error {
+	cfg := &validateConfig{}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return err
+		}
+	}
+	validateChunk := array.ValidateFull
+	if cfg.utf8 {
+		validateChunk = array.ValidateFullUTF8
+	}
+
+	if n := len(df.schema.Fields()); n != len(df.cols) {
+		return fmt.Errorf("dataframe: schema has %d fields for %d columns", n, len(df.cols))
+	}
+	for i := range df.cols {
+		col := &df.cols[i]
+		field := df.schema.Field(i)
+		if field.Name != col.Name() || !arrow.TypeEqual(field.Type, col.DataType()) {
+			return fmt.Errorf("dataframe: column %q of type %s does not match field %q of type %s", col.Name(), col.DataType(), field.Name, field.Type)
+		}
+		if n := columnLen(*col); n != df.rows {
+			return fmt.Errorf("dataframe: column %q has %d values for %d rows", col.Name(), n, df.rows)
+		}
+		for c, chunk := range col.Data().Chunks() {
+			if !arrow.TypeEqual(chunk.DataType(), col.DataType()) {
+				return fmt.Errorf("dataframe: chunk %d of column %q has type %s, want %s", c, col.Name(), chunk.DataType(), col.DataType())
+			}
+			if err := validateChunk(chunk); err != nil {
+				return fmt.Errorf("dataframe: chunk %d of column %q: %w", c, col.Name(), err)
+			}
+		}
+	}
+	if df.index != nil && df.index.Len() != df.rows {
+		return fmt.Errorf("dataframe: index of %d rows for %d rows", df.index.Len(), df.rows)
+	}
+	return nil
+}
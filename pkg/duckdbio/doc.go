@@ -0,0 +1,45 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package duckdbio exchanges DataFrames with DuckDB over Arrow.
+
+DuckDB reads and returns Arrow record batches through the Arrow C data
+interface. Its Go binding exposes this on a connection as a view
+registration taking a RecordReader and a query method returning one; Conn
+describes that pair of methods in terms of the Arrow package used by gomem.
+
+This package does not implement the C data interface itself, and has no
+cgo or DuckDB dependency. Plugging a DuckDB connection in takes an adapter
+implementing Conn, which exports and imports the records through the C data
+interface with the Arrow package of the DuckDB binding. The lifetimes below
+are those of the records exchanged with that adapter.
+
+	release, err := duckdbio.RegisterDataFrame(conn, "sales", df)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	out, err := duckdbio.Query(ctx, mem, conn, "SELECT region, sum(units) FROM sales GROUP BY region")
+
+The DataFrame of a view is retained until the view is released, so the
+buffers DuckDB reads stay valid for the lifetime of the view, even if the
+caller releases its own reference first.
+
+The DataFrame returned by Query shares the buffers of the records returned
+by the connection rather than copying them, and holds them until it is
+released.
+*/
+package duckdbio
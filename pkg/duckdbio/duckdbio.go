@@ -0,0 +1,96 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package duckdbio
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+// Conn is a DuckDB connection exchanging Arrow records.
+type Conn interface {
+	// RegisterView makes the records of rdr queryable as the view name
+	// until release is called. The connection retains rdr as long as it
+	// needs it.
+	RegisterView(rdr array.RecordReader, name string) (release func(), err error)
+
+	// QueryContext runs query with the values of its placeholders and
+	// returns the result as a stream of records, released by the caller.
+	QueryContext(ctx context.Context, query string, args ...interface{}) (array.RecordReader, error)
+}
+
+// RegisterDataFrame registers df as the view name of conn. The returned
+// function drops the view and releases the reference held on df; calling it
+// more than once is a no-op.
+func RegisterDataFrame(conn Conn, name string, df *dataframe.DataFrame, opts ...Option) (release func(), err error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	rdr := array.NewTableReader(dataframe.NewTableFacade(df), cfg.chunkSize)
+	drop, err := conn.RegisterView(rdr, name)
+	if err != nil {
+		rdr.Release()
+		return nil, fmt.Errorf("duckdbio: could not register view %q: %w", name, err)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			drop()
+			rdr.Release()
+		})
+	}, nil
+}
+
+// Query runs query on conn and returns its result as a new DataFrame using
+// mem. The records returned by conn are not copied: the DataFrame retains
+// them as the chunks of its columns, so their buffers stay alive until the
+// DataFrame is released.
+func Query(ctx context.Context, mem memory.Allocator, conn Conn, query string, args ...interface{}) (*dataframe.DataFrame, error) {
+	rdr, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rdr.Release()
+
+	var recs []array.Record
+	defer func() {
+		for _, rec := range recs {
+			rec.Release()
+		}
+	}()
+	for rdr.Next() {
+		rec := rdr.Record()
+		rec.Retain()
+		recs = append(recs, rec)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if r, ok := rdr.(interface{ Err() error }); ok && r.Err() != nil {
+		return nil, fmt.Errorf("duckdbio: could not read result: %w", r.Err())
+	}
+
+	tbl := array.NewTableFromRecords(rdr.Schema(), recs)
+	defer tbl.Release()
+	return dataframe.NewDataFrameFromTable(mem, tbl)
+}
@@ -0,0 +1,166 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package duckdbio
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/dataframe"
+	"github.com/gomem/gomem/pkg/gomemsql"
+)
+
+// fakeConn stands in for DuckDB, running the queries with gomemsql over the
+// records of the registered views.
+type fakeConn struct {
+	mem   memory.Allocator
+	views map[string]*dataframe.DataFrame
+}
+
+func (c *fakeConn) RegisterView(rdr array.RecordReader, name string) (func(), error) {
+	if _, ok := c.views[name]; ok {
+		return nil, fmt.Errorf("view %q already exists", name)
+	}
+	rdr.Retain()
+
+	var recs []array.Record
+	for rdr.Next() {
+		rec := rdr.Record()
+		rec.Retain()
+		recs = append(recs, rec)
+	}
+	tbl := array.NewTableFromRecords(rdr.Schema(), recs)
+	for _, rec := range recs {
+		rec.Release()
+	}
+	df, err := dataframe.NewDataFrameFromTable(c.mem, tbl)
+	tbl.Release()
+	if err != nil {
+		rdr.Release()
+		return nil, err
+	}
+	c.views[name] = df
+
+	return func() {
+		df.Release()
+		delete(c.views, name)
+		rdr.Release()
+	}, nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args ...interface{}) (array.RecordReader, error) {
+	df, err := gomemsql.Query(ctx, query, c.views, gomemsql.WithArgs(args...))
+	if err != nil {
+		return nil, err
+	}
+	defer df.Release()
+	return array.NewTableReader(dataframe.NewTableFacade(df), 2), nil
+}
+
+func TestRoundTrip(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := dataframe.NewDataFrameFromMem(pool, dataframe.Dict{
+		"region": []string{"east", "west", "east", "north", "west"},
+		"units":  []int64{3, 5, 2, 7, 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn := &fakeConn{mem: pool, views: make(map[string]*dataframe.DataFrame)}
+	release, err := RegisterDataFrame(conn, "sales", df, WithChunkSize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	if _, err := RegisterDataFrame(conn, "sales", df); err == nil {
+		t.Fatal("expected an error registering a view twice")
+	}
+	// The view keeps the data alive.
+	df.Release()
+
+	out, err := Query(context.Background(), pool, conn, "select region, sum(units) as units from sales where units > ? group by region order by region", int64(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Release()
+
+	if got, want := out.NumRows(), int64(3); got != want {
+		t.Fatalf("invalid number of rows: got=%d, want=%d", got, want)
+	}
+	if got, want := fmt.Sprint(out.Column("units").Data().Chunks()), "[[5 7] [5]]"; got != want {
+		t.Fatalf("invalid units: got=%s, want=%s", got, want)
+	}
+
+	release()
+	release()
+	if _, ok := conn.views["sales"]; ok {
+		t.Fatal("view was not dropped")
+	}
+}
+
+// recordConn returns rec as the result of every query.
+type recordConn struct {
+	fakeConn
+	rec array.Record
+}
+
+func (c *recordConn) QueryContext(ctx context.Context, query string, args ...interface{}) (array.RecordReader, error) {
+	return array.NewRecordReader(c.rec.Schema(), []array.Record{c.rec})
+}
+
+func TestQuerySharesRecords(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := dataframe.NewDataFrameFromMem(pool, dataframe.Dict{
+		"units": []int64{3, 5, 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := array.NewRecord(df.Schema(), []array.Interface{df.Column("units").Data().Chunk(0)}, df.NumRows())
+	df.Release()
+
+	out, err := Query(context.Background(), pool, &recordConn{rec: rec}, "select units from result")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Release()
+
+	want := rec.Column(0).Data().Buffers()[1]
+	// The DataFrame keeps the data alive.
+	rec.Release()
+
+	got := out.Column("units").Data().Chunk(0).Data().Buffers()[1]
+	if got != want {
+		t.Fatal("the values of the result were copied")
+	}
+	if got, want := fmt.Sprint(out.Column("units").Data().Chunks()), "[[3 5 2]]"; got != want {
+		t.Fatalf("invalid units: got=%s, want=%s", got, want)
+	}
+}
+
+func TestWithChunkSize(t *testing.T) {
+	if _, err := newConfig(WithChunkSize(0)); err == nil {
+		t.Fatal("expected an error for an invalid chunk size")
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package duckdbio
+
+import (
+	"fmt"
+)
+
+// DefaultChunkSize is the number of rows of the records of a registered view.
+const DefaultChunkSize = 64 * 1024
+
+// Option is an option that may be passed to RegisterDataFrame.
+type Option func(interface{}) error
+
+type config struct {
+	chunkSize int64
+}
+
+func newConfig(opts ...Option) (*config, error) {
+	cfg := &config{chunkSize: DefaultChunkSize}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// WithChunkSize specifies the number of rows of the records DuckDB reads
+// from a registered DataFrame.
+func WithChunkSize(n int64) Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply WithChunkSize to: %T", p)
+		}
+		if n <= 0 {
+			return fmt.Errorf("duckdbio: invalid chunk size %d", n)
+		}
+		cfg.chunkSize = n
+		return nil
+	}
+}
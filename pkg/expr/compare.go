@@ -0,0 +1,102 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/pushdown"
+	"github.com/gomem/gomem/pkg/scalar"
+)
+
+// Eq returns an expression reporting whether the values of the column are
+// equal to value, see Compare.
+func (c ColumnRef) Eq(value interface{}) Expr { return Compare(c, pushdown.Equal, value) }
+
+// NotEq returns an expression reporting whether the values of the column
+// differ from value, see Compare.
+func (c ColumnRef) NotEq(value interface{}) Expr { return Compare(c, pushdown.NotEqual, value) }
+
+// Lt returns an expression reporting whether the values of the column are
+// smaller than value, see Compare.
+func (c ColumnRef) Lt(value interface{}) Expr { return Compare(c, pushdown.Less, value) }
+
+// LtEq returns an expression reporting whether the values of the column are
+// smaller than or equal to value, see Compare.
+func (c ColumnRef) LtEq(value interface{}) Expr { return Compare(c, pushdown.LessEqual, value) }
+
+// Gt returns an expression reporting whether the values of the column are
+// greater than value, see Compare.
+func (c ColumnRef) Gt(value interface{}) Expr { return Compare(c, pushdown.Greater, value) }
+
+// GtEq returns an expression reporting whether the values of the column are
+// greater than or equal to value, see Compare.
+func (c ColumnRef) GtEq(value interface{}) Expr { return Compare(c, pushdown.GreaterEqual, value) }
+
+// Compare returns an expression comparing the values of the column c refers
+// to with value, as pushdown.Compare does. Null values compare as false.
+//
+// The comparison is evaluated chunk by chunk and keeps the chunks of the
+// column. A chunk holding statistics, see array.Statistics, for which they
+// rule out any match gives a chunk of false values without reading its rows.
+func Compare(c ColumnRef, op pushdown.Op, value interface{}) Expr {
+	return &comparison{name: c.name, pred: pushdown.Compare(c.name, op, value)}
+}
+
+type comparison struct {
+	name string
+	pred pushdown.Predicate
+}
+
+func (c *comparison) Eval(mem memory.Allocator, src Source) (*array.Column, error) {
+	col := src.Column(c.name)
+	if col == nil {
+		return nil, fmt.Errorf("expr: column %q not found", c.name)
+	}
+	schema := arrow.NewSchema([]arrow.Field{{Name: c.name, Type: col.DataType(), Nullable: true}}, nil)
+
+	chunks := make([]array.Interface, 0, len(col.Data().Chunks()))
+	defer func() {
+		for _, chunk := range chunks {
+			chunk.Release()
+		}
+	}()
+	for _, chunk := range col.Data().Chunks() {
+		if stats, ok := pushdown.CachedStats(chunk); ok && !c.pred.MayMatch(pushdown.Stats{c.name: stats}) {
+			skipped, err := scalar.MakeArray(mem, scalar.NewBoolean(false), chunk.Len())
+			if err != nil {
+				return nil, err
+			}
+			chunks = append(chunks, skipped)
+			continue
+		}
+		rec := array.NewRecord(schema, []array.Interface{chunk}, int64(chunk.Len()))
+		mask, err := c.pred.Eval(mem, rec)
+		rec.Release()
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, mask)
+	}
+
+	chunked := array.NewChunked(arrow.FixedWidthTypes.Boolean, chunks)
+	defer chunked.Release()
+	return array.NewColumn(arrow.Field{Name: c.String(), Type: arrow.FixedWidthTypes.Boolean}, chunked), nil
+}
+
+func (c *comparison) String() string { return c.pred.String() }
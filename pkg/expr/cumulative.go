@@ -0,0 +1,76 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// CumSum returns an expression computing the running sums of e.
+func CumSum(e Expr, nulls compute.NullHandling) Expr {
+	return cumulative("cumsum", compute.CumSum, e, nulls)
+}
+
+// CumProd returns an expression computing the running products of e.
+func CumProd(e Expr, nulls compute.NullHandling) Expr {
+	return cumulative("cumprod", compute.CumProd, e, nulls)
+}
+
+// CumMin returns an expression computing the running minimums of e.
+func CumMin(e Expr, nulls compute.NullHandling) Expr {
+	return cumulative("cummin", compute.CumMin, e, nulls)
+}
+
+// CumMax returns an expression computing the running maximums of e.
+func CumMax(e Expr, nulls compute.NullHandling) Expr {
+	return cumulative("cummax", compute.CumMax, e, nulls)
+}
+
+type cumulativeFunc func(mem memory.Allocator, col *array.Column, nulls compute.NullHandling) (*array.Column, error)
+
+func cumulative(name string, fn cumulativeFunc, e Expr, nulls compute.NullHandling) Expr {
+	if nulls == compute.PropagateNulls {
+		name += "[propagate]"
+	}
+	return Call(name, func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		return fn(mem, args[0], nulls)
+	}, e)
+}
+
+// Diff returns an expression computing the differences between the rows of e
+// and the rows periods rows before them.
+func Diff(e Expr, periods int) Expr {
+	return Call(fmt.Sprintf("diff[%d]", periods), func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		return compute.Diff(mem, args[0], periods)
+	}, e)
+}
+
+// Lag returns an expression holding the value of e n rows before, or fill.
+func Lag(e Expr, n int, fill interface{}) Expr {
+	return Call(fmt.Sprintf("lag[%d]", n), func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		return compute.Shift(mem, args[0], n, fill)
+	}, e)
+}
+
+// Lead returns an expression holding the value of e n rows after, or fill.
+func Lead(e Expr, n int, fill interface{}) Expr {
+	return Call(fmt.Sprintf("lead[%d]", n), func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		return compute.Shift(mem, args[0], -n, fill)
+	}, e)
+}
@@ -0,0 +1,41 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package expr provides expressions that are evaluated against the columns of a DataFrame.
+
+An expression is a tree of column references and function calls. Evaluating
+it runs the matching compute kernels and produces a new Column, which can
+be added to a DataFrame with WithColumn.
+
+	e := expr.AddMonths(expr.Col("signup"), 1)
+	df2, err := df.WithColumn("renewal", e)
+
+Comparisons of a column with a constant give boolean columns, which
+DataFrame.Filter keeps the rows of. They are evaluated chunk by chunk, and
+the chunks whose statistics, kept by builders with statistics enabled, rule
+out any match are skipped without reading their rows.
+
+	df2, err := df.Filter(expr.Col("ts").Gt(cutoff))
+
+User-defined functions are registered by name in a Registry, and called with
+Func. Elementwise functions receive whole columns, or the values of a row as
+scalars; aggregate functions reduce the rows of a group to a scalar. The
+functions of DefaultRegistry can also be called from SQL queries.
+
+	err := expr.Register(expr.Function{Name: "half", Args: []arrow.DataType{arrow.PrimitiveTypes.Float64}, Result: arrow.PrimitiveTypes.Float64, Row: half})
+	e := expr.Func("half", expr.Col("price"))
+
+*/
+package expr
@@ -0,0 +1,107 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// Source is the set of named columns an expression is evaluated against.
+// A *dataframe.DataFrame is a Source.
+type Source interface {
+	Column(name string) *array.Column
+	NumRows() int64
+}
+
+// Expr is an expression that produces a Column when evaluated.
+type Expr interface {
+	// Eval evaluates the expression against src.
+	// The caller is responsible for releasing the returned Column.
+	Eval(mem memory.Allocator, src Source) (*array.Column, error)
+	// String returns a readable form of the expression.
+	String() string
+}
+
+// Col returns an expression referencing the column of the Source with the given name.
+func Col(name string) ColumnRef {
+	return ColumnRef{name: name}
+}
+
+// ColumnRef is an expression referencing a column of the Source by name.
+type ColumnRef struct {
+	name string
+}
+
+func (c ColumnRef) Eval(mem memory.Allocator, src Source) (*array.Column, error) {
+	col := src.Column(c.name)
+	if col == nil {
+		return nil, fmt.Errorf("expr: column %q not found", c.name)
+	}
+	return array.NewColumn(col.Field(), col.Data()), nil
+}
+
+func (c ColumnRef) String() string { return c.name }
+
+// Field returns an expression referencing the named field of the struct column.
+func (c ColumnRef) Field(name string) Expr {
+	return Field(c, name)
+}
+
+// KernelFunc computes a new Column from the evaluated arguments of a call.
+// It must not release its arguments.
+type KernelFunc func(mem memory.Allocator, args []*array.Column) (*array.Column, error)
+
+// Call returns an expression that evaluates args and passes the results to fn.
+// The name is only used to describe the expression.
+func Call(name string, fn KernelFunc, args ...Expr) Expr {
+	return &call{name: name, fn: fn, args: args}
+}
+
+type call struct {
+	name string
+	fn   KernelFunc
+	args []Expr
+}
+
+func (c *call) Eval(mem memory.Allocator, src Source) (*array.Column, error) {
+	args := make([]*array.Column, 0, len(c.args))
+	defer func() {
+		for _, arg := range args {
+			arg.Release()
+		}
+	}()
+
+	for _, e := range c.args {
+		arg, err := e.Eval(mem, src)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+
+	return c.fn(mem, args)
+}
+
+func (c *call) String() string {
+	args := make([]string, len(c.args))
+	for i, e := range c.args {
+		args[i] = e.String()
+	}
+	return c.name + "(" + strings.Join(args, ", ") + ")"
+}
@@ -0,0 +1,206 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// source is a minimal Source backed by a map of columns.
+type source map[string]*array.Column
+
+func (s source) Column(name string) *array.Column { return s[name] }
+func (s source) NumRows() int64 {
+	for _, col := range s {
+		return int64(col.Len())
+	}
+	return 0
+}
+
+func TestSubtractTimestampsExpr(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	dtype := &arrow.TimestampType{Unit: arrow.Second}
+	newColumn := func(name string, values []arrow.Timestamp) *array.Column {
+		b := array.NewTimestampBuilder(pool, dtype)
+		defer b.Release()
+		b.AppendValues(values, nil)
+		arr := b.NewArray()
+		defer arr.Release()
+		chunked := array.NewChunked(dtype, []array.Interface{arr})
+		defer chunked.Release()
+		return array.NewColumn(arrow.Field{Name: name, Type: dtype}, chunked)
+	}
+
+	start := newColumn("start", []arrow.Timestamp{0, 60, 120})
+	defer start.Release()
+	end := newColumn("end", []arrow.Timestamp{30, 90, 300})
+	defer end.Release()
+
+	e := SubtractTimestamps(Col("end"), Col("start"))
+	if got, want := e.String(), "subtract_timestamps(end, start)"; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+
+	res, err := e.Eval(pool, source{"start": start, "end": end})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Release()
+
+	if got, want := res.Data().Chunk(0).(*array.Duration).String(), "[30 30 180]"; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+
+	if _, err := e.Eval(pool, source{"start": start}); err == nil {
+		t.Fatal("expected an error for a missing column")
+	}
+}
+
+func TestCumulativeExpr(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	b := array.NewInt64Builder(pool)
+	defer b.Release()
+	b.AppendValues([]int64{1, 2, 0, 4}, []bool{true, true, false, true})
+	arr := b.NewArray()
+	defer arr.Release()
+	chunked := array.NewChunked(arr.DataType(), []array.Interface{arr})
+	defer chunked.Release()
+	x := array.NewColumn(arrow.Field{Name: "x", Type: arr.DataType(), Nullable: true}, chunked)
+	defer x.Release()
+
+	for _, tc := range []struct {
+		e    Expr
+		name string
+		want string
+	}{
+		{CumSum(Col("x"), compute.SkipNulls), "cumsum(x)", "[1 3 (null) 7]"},
+		{CumMax(Col("x"), compute.PropagateNulls), "cummax[propagate](x)", "[1 2 (null) (null)]"},
+		{Diff(Col("x"), 1), "diff[1](x)", "[(null) 1 (null) (null)]"},
+		{Lag(Col("x"), 1, 0), "lag[1](x)", "[0 1 2 (null)]"},
+		{Lead(Col("x"), 2, nil), "lead[2](x)", "[(null) 4 (null) (null)]"},
+	} {
+		if got := tc.e.String(); got != tc.name {
+			t.Errorf("got=%s, want=%s", got, tc.name)
+		}
+		res, err := tc.e.Eval(pool, source{"x": x})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprint(res.Data().Chunk(0)); got != tc.want {
+			t.Errorf("%s: got=%s, want=%s", tc.name, got, tc.want)
+		}
+		res.Release()
+	}
+}
+
+func TestFieldExpr(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	dtype := arrow.StructOf(arrow.Field{Name: "x", Type: arrow.PrimitiveTypes.Int64})
+	b := array.NewStructBuilder(pool, dtype)
+	defer b.Release()
+	b.AppendValues([]bool{true, false, true})
+	b.FieldBuilder(0).(*array.Int64Builder).AppendValues([]int64{1, 2, 3}, nil)
+	arr := b.NewArray()
+	defer arr.Release()
+	chunked := array.NewChunked(dtype, []array.Interface{arr})
+	defer chunked.Release()
+	s := array.NewColumn(arrow.Field{Name: "s", Type: dtype, Nullable: true}, chunked)
+	defer s.Release()
+
+	e := Col("s").Field("x")
+	if got, want := e.String(), "s.x"; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+	res, err := e.Eval(pool, source{"s": s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Release()
+	if got, want := fmt.Sprint(res.Data().Chunk(0)), "[1 (null) 3]"; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+
+	if _, err := Field(Col("s"), "y").Eval(pool, source{"s": s}); err == nil {
+		t.Fatal("expected an error for a missing field")
+	}
+}
+
+func TestCompareExpr(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	dtype := &arrow.TimestampType{Unit: arrow.Second}
+	b := array.NewTimestampBuilder(pool, dtype)
+	defer b.Release()
+	var arrs []array.Interface
+	for _, chunk := range []struct {
+		values []arrow.Timestamp
+		valid  []bool
+		stats  bool
+	}{
+		{[]arrow.Timestamp{1, 2, 3}, nil, true},
+		{[]arrow.Timestamp{10, 0, 12}, []bool{true, false, true}, true},
+		// Without statistics the chunk is always scanned.
+		{[]arrow.Timestamp{4, 20}, nil, false},
+	} {
+		b.SetStatistics(chunk.stats)
+		b.AppendValues(chunk.values, chunk.valid)
+		arrs = append(arrs, b.NewArray())
+	}
+	chunked := array.NewChunked(dtype, arrs)
+	for _, arr := range arrs {
+		arr.Release()
+	}
+	ts := array.NewColumn(arrow.Field{Name: "ts", Type: dtype, Nullable: true}, chunked)
+	chunked.Release()
+	defer ts.Release()
+
+	e := Col("ts").Gt(5)
+	if got, want := e.String(), "ts > 5"; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+	res, err := e.Eval(pool, source{"ts": ts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Release()
+
+	var got []string
+	for _, chunk := range res.Data().Chunks() {
+		got = append(got, chunk.(*array.Boolean).String())
+	}
+	if want := []string{"[false false false]", "[true false true]", "[false true]"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got=%v, want=%v", got, want)
+	}
+
+	if _, err := Col("ts").Eq("soon").Eval(pool, source{"ts": ts}); err == nil {
+		t.Fatal("expected an error for a value of another type")
+	}
+	if _, err := Col("at").Lt(1).Eval(pool, source{"ts": ts}); err == nil {
+		t.Fatal("expected an error for a missing column")
+	}
+}
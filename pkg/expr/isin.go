@@ -0,0 +1,30 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// IsIn returns an expression reporting whether the values of e are one of values.
+func IsIn(e Expr, values ...interface{}) Expr {
+	return Call(fmt.Sprintf("isin%v", values), func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		return compute.IsIn(mem, args[0], values...)
+	}, e)
+}
@@ -0,0 +1,43 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// Field returns an expression referencing the named field of the struct e
+// evaluates to. Rows where the struct is null are null.
+func Field(e Expr, name string) Expr {
+	return &field{e: e, name: name}
+}
+
+type field struct {
+	e    Expr
+	name string
+}
+
+func (f *field) Eval(mem memory.Allocator, src Source) (*array.Column, error) {
+	col, err := f.e.Eval(mem, src)
+	if err != nil {
+		return nil, err
+	}
+	defer col.Release()
+	return compute.StructField(mem, col, f.name)
+}
+
+func (f *field) String() string { return f.e.String() + "." + f.name }
@@ -0,0 +1,53 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// AddDuration returns an expression adding the durations of d to the timestamps of ts.
+func AddDuration(ts, d Expr) Expr {
+	return Call("add_duration", func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		return compute.AddDuration(mem, args[0], args[1])
+	}, ts, d)
+}
+
+// SubtractTimestamps returns an expression computing the durations a - b.
+func SubtractTimestamps(a, b Expr) Expr {
+	return Call("subtract_timestamps", func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		return compute.SubtractTimestamps(mem, args[0], args[1])
+	}, a, b)
+}
+
+// AddMonths returns an expression adding a number of calendar months to a
+// date or timestamp expression, respecting month lengths.
+func AddMonths(e Expr, months int32) Expr {
+	return Call(fmt.Sprintf("add_months[%d]", months), func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		return compute.AddMonths(mem, args[0], months)
+	}, e)
+}
+
+// AddInterval returns an expression adding iv to a date or timestamp expression.
+func AddInterval(e Expr, iv arrow.MonthDayNanoInterval) Expr {
+	return Call(fmt.Sprintf("add_interval[%dM%dd%dns]", iv.Months, iv.Days, iv.Nanoseconds), func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		return compute.AddInterval(mem, args[0], iv)
+	}, e)
+}
@@ -0,0 +1,382 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/scalar"
+)
+
+// FuncKind tells whether a function computes a value per row or per group of rows.
+type FuncKind int
+
+const (
+	// Elementwise functions compute a value per row.
+	Elementwise FuncKind = iota
+	// Aggregate functions compute a value per group of rows.
+	Aggregate
+)
+
+func (k FuncKind) String() string {
+	switch k {
+	case Elementwise:
+		return "elementwise"
+	case Aggregate:
+		return "aggregate"
+	default:
+		return fmt.Sprintf("FuncKind(%d)", int(k))
+	}
+}
+
+// Function is a user-defined function.
+//
+// An elementwise function sets Vector, which receives whole columns, or Row,
+// which receives the values of one row at a time. An aggregate function sets
+// Reduce, which receives the rows of one group.
+type Function struct {
+	// Name is the name the function is called by. Names are case insensitive.
+	Name string
+	Kind FuncKind
+	// Args holds the types of the arguments. A nil type accepts any type.
+	Args []arrow.DataType
+	// Result is the type of the result. It is required by Row and Reduce, and
+	// checked against the result of Vector when set.
+	Result arrow.DataType
+
+	// Vector computes the result column from the argument columns, which all
+	// have the same length. It must not release its arguments.
+	Vector KernelFunc
+	// Row computes the value of one row. It is only called for rows where no
+	// argument is null, the result of the others is null.
+	Row func(args []scalar.Scalar) (scalar.Scalar, error)
+	// Reduce computes the value of a group from the rows of the group.
+	Reduce func(mem memory.Allocator, args []*array.Column) (scalar.Scalar, error)
+}
+
+func (f *Function) validate() error {
+	switch {
+	case f.Name == "":
+		return fmt.Errorf("expr: function without a name")
+	case f.Kind == Elementwise && (f.Vector == nil) == (f.Row == nil):
+		return fmt.Errorf("expr: elementwise function %s needs one of Vector or Row", f.Name)
+	case f.Kind == Elementwise && f.Reduce != nil:
+		return fmt.Errorf("expr: elementwise function %s cannot have Reduce", f.Name)
+	case f.Kind == Aggregate && (f.Reduce == nil || f.Vector != nil || f.Row != nil):
+		return fmt.Errorf("expr: aggregate function %s needs Reduce only", f.Name)
+	case f.Kind != Elementwise && f.Kind != Aggregate:
+		return fmt.Errorf("expr: function %s has invalid kind %v", f.Name, f.Kind)
+	case f.Result == nil && f.Vector == nil:
+		return fmt.Errorf("expr: function %s needs a Result type", f.Name)
+	}
+	return nil
+}
+
+// checkArgs checks the argument columns against the signature of f.
+func (f *Function) checkArgs(args []*array.Column) error {
+	if len(args) != len(f.Args) {
+		return fmt.Errorf("expr: %s expects %d arguments, got %d", f.Name, len(f.Args), len(args))
+	}
+	for i, arg := range args {
+		if f.Args[i] != nil && !arrow.TypeEqual(arg.DataType(), f.Args[i]) {
+			return fmt.Errorf("expr: argument %d of %s must be %s, got %s", i+1, f.Name, f.Args[i], arg.DataType())
+		}
+	}
+	return nil
+}
+
+// Registry holds user-defined functions by name. It is safe for concurrent use.
+type Registry struct {
+	mu    sync.RWMutex
+	funcs map[string]*Function
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{funcs: make(map[string]*Function)}
+}
+
+// DefaultRegistry is the registry used by Register, Lookup and Func, and
+// searched by the SQL layer.
+var DefaultRegistry = NewRegistry()
+
+// Register adds f to the registry, replacing the function registered under
+// the same name.
+func (r *Registry) Register(f Function) error {
+	if err := f.validate(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.funcs[strings.ToLower(f.Name)] = &f
+	return nil
+}
+
+// Unregister removes the function registered under name.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.funcs, strings.ToLower(name))
+}
+
+// Lookup returns the function registered under name.
+func (r *Registry) Lookup(name string) (*Function, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	f, ok := r.funcs[strings.ToLower(name)]
+	return f, ok
+}
+
+// Register adds f to DefaultRegistry.
+func Register(f Function) error { return DefaultRegistry.Register(f) }
+
+// Lookup returns the function registered under name in DefaultRegistry.
+func Lookup(name string) (*Function, bool) { return DefaultRegistry.Lookup(name) }
+
+// Func returns an expression calling the function of DefaultRegistry
+// registered under name. The function is resolved when the expression is
+// evaluated. The result of an aggregate function is the value of all the rows
+// of the Source, repeated on every row.
+func Func(name string, args ...Expr) Expr {
+	return &funcCall{reg: DefaultRegistry, name: name, args: args}
+}
+
+// Func is like the package function Func, with the functions of r.
+func (r *Registry) Func(name string, args ...Expr) Expr {
+	return &funcCall{reg: r, name: name, args: args}
+}
+
+type funcCall struct {
+	reg  *Registry
+	name string
+	args []Expr
+}
+
+func (c *funcCall) Eval(mem memory.Allocator, src Source) (*array.Column, error) {
+	f, ok := c.reg.Lookup(c.name)
+	if !ok {
+		return nil, fmt.Errorf("expr: unknown function %s", c.name)
+	}
+	return Call(c.name, func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		if f.Kind == Aggregate {
+			s, err := f.reduce(mem, args)
+			if err != nil {
+				return nil, err
+			}
+			return broadcast(mem, f.Name, s, int(src.NumRows()))
+		}
+		return f.Apply(mem, args)
+	}, c.args...).Eval(mem, src)
+}
+
+func (c *funcCall) String() string {
+	return Call(c.name, nil, c.args...).String()
+}
+
+// Apply computes the elementwise function f over args.
+// The caller is responsible for releasing the returned Column.
+func (f *Function) Apply(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+	if f.Kind != Elementwise {
+		return nil, fmt.Errorf("expr: %s is not an elementwise function", f.Name)
+	}
+	if err := f.checkArgs(args); err != nil {
+		return nil, err
+	}
+	for _, arg := range args {
+		if arg.Len() != args[0].Len() {
+			return nil, fmt.Errorf("expr: arguments of %s have different lengths", f.Name)
+		}
+	}
+
+	if f.Vector != nil {
+		col, err := f.Vector(mem, args)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case len(args) > 0 && col.Len() != args[0].Len():
+			col.Release()
+			return nil, fmt.Errorf("expr: %s returned %d rows, want %d", f.Name, col.Len(), args[0].Len())
+		case f.Result != nil && !arrow.TypeEqual(col.DataType(), f.Result):
+			col.Release()
+			return nil, fmt.Errorf("expr: %s returned %s, want %s", f.Name, col.DataType(), f.Result)
+		}
+		return col, nil
+	}
+
+	n := 0
+	if len(args) > 0 {
+		n = args[0].Len()
+	}
+	cursors := make([]*rowCursor, len(args))
+	for i, arg := range args {
+		cursors[i] = &rowCursor{chunks: arg.Data().Chunks()}
+	}
+
+	bldr := array.NewBuilder(mem, f.Result)
+	defer bldr.Release()
+	bldr.Reserve(n)
+	values := make([]scalar.Scalar, len(args))
+	for row := 0; row < n; row++ {
+		null := false
+		for i, c := range cursors {
+			v, err := c.next()
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+			null = null || !v.IsValid()
+		}
+		if null {
+			bldr.AppendNull()
+			continue
+		}
+		v, err := f.Row(values)
+		if err != nil {
+			return nil, fmt.Errorf("expr: %s: %w", f.Name, err)
+		}
+		if err := f.append(bldr, v); err != nil {
+			return nil, err
+		}
+	}
+	return newColumn(f.Name, bldr.NewArray()), nil
+}
+
+// ReduceGroups computes the aggregate function f over the rows of every
+// group of g, in order of the groups.
+// The caller is responsible for releasing the returned Column.
+func (f *Function) ReduceGroups(mem memory.Allocator, args []*array.Column, g *compute.Groups) (*array.Column, error) {
+	if f.Kind != Aggregate {
+		return nil, fmt.Errorf("expr: %s is not an aggregate function", f.Name)
+	}
+	if err := f.checkArgs(args); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]int64, g.NumGroups())
+	for row, id := range g.IDs {
+		rows[id] = append(rows[id], int64(row))
+	}
+
+	bldr := array.NewBuilder(mem, f.Result)
+	defer bldr.Release()
+	bldr.Reserve(g.NumGroups())
+	for _, group := range rows {
+		v, err := f.reduceRows(mem, args, group)
+		if err != nil {
+			return nil, err
+		}
+		if err := f.append(bldr, v); err != nil {
+			return nil, err
+		}
+	}
+	return newColumn(f.Name, bldr.NewArray()), nil
+}
+
+// reduceRows reduces the rows of args at the given indices.
+func (f *Function) reduceRows(mem memory.Allocator, args []*array.Column, rows []int64) (scalar.Scalar, error) {
+	ib := array.NewInt64Builder(mem)
+	defer ib.Release()
+	ib.AppendValues(rows, nil)
+	indices := ib.NewInt64Array()
+	defer indices.Release()
+
+	taken := make([]*array.Column, 0, len(args))
+	defer func() {
+		for _, col := range taken {
+			col.Release()
+		}
+	}()
+	for _, arg := range args {
+		col, err := compute.Take(mem, arg, indices)
+		if err != nil {
+			return nil, err
+		}
+		taken = append(taken, col)
+	}
+	return f.reduce(mem, taken)
+}
+
+func (f *Function) reduce(mem memory.Allocator, args []*array.Column) (scalar.Scalar, error) {
+	if f.Kind != Aggregate {
+		return nil, fmt.Errorf("expr: %s is not an aggregate function", f.Name)
+	}
+	if err := f.checkArgs(args); err != nil {
+		return nil, err
+	}
+	v, err := f.Reduce(mem, args)
+	if err != nil {
+		return nil, fmt.Errorf("expr: %s: %w", f.Name, err)
+	}
+	if !v.IsValid() {
+		return scalar.MakeNull(f.Result)
+	}
+	if !arrow.TypeEqual(v.DataType(), f.Result) {
+		return nil, fmt.Errorf("expr: %s returned %s, want %s", f.Name, v.DataType(), f.Result)
+	}
+	return v, nil
+}
+
+// append appends v, the result of f, to bldr.
+func (f *Function) append(bldr array.Builder, v scalar.Scalar) error {
+	if !v.IsValid() {
+		bldr.AppendNull()
+		return nil
+	}
+	if !arrow.TypeEqual(v.DataType(), f.Result) {
+		return fmt.Errorf("expr: %s returned %s, want %s", f.Name, v.DataType(), f.Result)
+	}
+	scalar.Append(bldr, v)
+	return nil
+}
+
+// broadcast returns a column of n rows holding s.
+func broadcast(mem memory.Allocator, name string, s scalar.Scalar, n int) (*array.Column, error) {
+	arr, err := scalar.MakeArray(mem, s, n)
+	if err != nil {
+		return nil, err
+	}
+	return newColumn(name, arr), nil
+}
+
+// newColumn returns a single chunk column holding arr, and releases arr.
+func newColumn(name string, arr array.Interface) *array.Column {
+	defer arr.Release()
+	chunked := array.NewChunked(arr.DataType(), []array.Interface{arr})
+	defer chunked.Release()
+	return array.NewColumn(arrow.Field{Name: name, Type: arr.DataType(), Nullable: true}, chunked)
+}
+
+// rowCursor walks the rows of a chunked column in order, as scalars.
+type rowCursor struct {
+	chunks []array.Interface
+	chunk  int
+	index  int
+}
+
+func (c *rowCursor) next() (scalar.Scalar, error) {
+	for c.index >= c.chunks[c.chunk].Len() {
+		c.chunk++
+		c.index = 0
+	}
+	c.index++
+	return scalar.Get(c.chunks[c.chunk], c.index-1)
+}
@@ -0,0 +1,132 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/scalar"
+)
+
+func TestUserFunctions(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	b := array.NewFloat64Builder(pool)
+	defer b.Release()
+	b.AppendValues([]float64{1, 2, 0, 4}, []bool{true, true, false, true})
+	arr := b.NewArray()
+	defer arr.Release()
+	chunked := array.NewChunked(arr.DataType(), []array.Interface{arr})
+	defer chunked.Release()
+	x := array.NewColumn(arrow.Field{Name: "x", Type: arr.DataType(), Nullable: true}, chunked)
+	defer x.Release()
+
+	reg := NewRegistry()
+	for _, f := range []Function{
+		{
+			Name: "Running",
+			Args: []arrow.DataType{arrow.PrimitiveTypes.Float64},
+			Vector: func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+				return compute.CumProd(mem, args[0], compute.SkipNulls)
+			},
+		},
+		{
+			Name:   "half",
+			Args:   []arrow.DataType{arrow.PrimitiveTypes.Float64},
+			Result: arrow.PrimitiveTypes.Float64,
+			Row: func(args []scalar.Scalar) (scalar.Scalar, error) {
+				return scalar.NewFloat64(args[0].(scalar.Float64).Value / 2), nil
+			},
+		},
+		{
+			Name:   "total",
+			Kind:   Aggregate,
+			Args:   []arrow.DataType{nil},
+			Result: arrow.PrimitiveTypes.Float64,
+			Reduce: func(mem memory.Allocator, args []*array.Column) (scalar.Scalar, error) {
+				mean, err := compute.Mean(args[0])
+				if err != nil {
+					return nil, err
+				}
+				return scalar.FromObject(arrow.PrimitiveTypes.Float64, mean)
+			},
+		},
+	} {
+		if err := reg.Register(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		e    Expr
+		name string
+		want string
+	}{
+		{reg.Func("running", Col("x")), "running(x)", "[1 2 (null) 8]"},
+		{reg.Func("HALF", Col("x")), "HALF(x)", "[0.5 1 (null) 2]"},
+		{reg.Func("total", reg.Func("half", Col("x"))), "total(half(x))", "[1.1666666666666667 1.1666666666666667 1.1666666666666667 1.1666666666666667]"},
+	} {
+		if got := tc.e.String(); got != tc.name {
+			t.Errorf("got=%s, want=%s", got, tc.name)
+		}
+		res, err := tc.e.Eval(pool, source{"x": x})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprint(res.Data().Chunk(0)); got != tc.want {
+			t.Errorf("%s: got=%s, want=%s", tc.name, got, tc.want)
+		}
+		res.Release()
+	}
+
+	total, _ := reg.Lookup("total")
+	groups := &compute.Groups{IDs: []int32{0, 1, 0, 1}, First: []int64{0, 1}}
+	res, err := total.ReduceGroups(pool, []*array.Column{x}, groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(res.Data().Chunk(0)), "[1 3]"; got != want {
+		t.Errorf("got=%s, want=%s", got, want)
+	}
+	res.Release()
+
+	for _, e := range []Expr{
+		reg.Func("missing", Col("x")),
+		reg.Func("half", Col("x"), Col("x")),
+		Func("half", Col("x")),
+	} {
+		if res, err := e.Eval(pool, source{"x": x}); err == nil {
+			res.Release()
+			t.Errorf("%s: expected an error", e)
+		}
+	}
+
+	for _, f := range []Function{
+		{Name: "", Vector: func(memory.Allocator, []*array.Column) (*array.Column, error) { return nil, nil }},
+		{Name: "norow"},
+		{Name: "noresult", Row: func([]scalar.Scalar) (scalar.Scalar, error) { return nil, nil }},
+		{Name: "noreduce", Kind: Aggregate, Result: arrow.PrimitiveTypes.Int64},
+	} {
+		if err := reg.Register(f); err == nil {
+			t.Errorf("%q: expected an error", f.Name)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package extension provides extension types: logical types such as UUIDs or IP
+addresses whose values are stored in columns of a plain Arrow storage type.
+
+The Arrow version gomem is built on has no extension arrays, so an extension
+column is a column of its storage type whose field carries the name of the type
+in the ARROW:extension:name metadata key, as the Arrow columnar format
+specifies. The metadata travels through IPC untouched, and kernels working on
+the storage type, such as sorting, grouping and joining, work on extension
+columns as they are.
+
+Types are registered by name, and TypeOf finds the registered type of a field:
+
+	ids, failed, err := extension.ParseUUID(mem, col, compute.ParseNull)
+	if err != nil {
+		return err
+	}
+	defer failed.Release()
+	if t, ok := extension.TypeOf(ids.Field()); ok {
+		fmt.Println(t.Name()) // arrow.uuid
+	}
+
+The UUID, IPv4, IPv6 and GeoPoint types are registered by default. GeoPoint
+columns come with distance and bounding box kernels, also available as
+expressions:
+
+	near := extension.Within(expr.Col("location"), extension.BoundingBox{
+		MinLat: 48.8, MinLon: 2.2, MaxLat: 48.9, MaxLon: 2.5,
+	})
+*/
+package extension
@@ -0,0 +1,181 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extension
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/metadata"
+)
+
+// Type is an extension type.
+type Type interface {
+	// Name is the name the type is registered and stored under, such as "arrow.uuid".
+	Name() string
+	// StorageType is the type of the columns holding the values.
+	StorageType() arrow.DataType
+}
+
+var (
+	mu    sync.RWMutex
+	types = make(map[string]Type)
+)
+
+func init() {
+	for _, t := range []Type{UUID, IPv4, IPv6, GeoPoint} {
+		if err := Register(t); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Register makes t known to TypeOf. Registering a name twice is an error.
+func Register(t Type) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := types[t.Name()]; ok {
+		return fmt.Errorf("extension: type %q is already registered", t.Name())
+	}
+	types[t.Name()] = t
+	return nil
+}
+
+// Unregister forgets the type registered under name.
+func Unregister(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(types, name)
+}
+
+// Lookup returns the type registered under name.
+func Lookup(name string) (Type, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	t, ok := types[name]
+	return t, ok
+}
+
+// NewField returns a field named name holding values of the extension type t.
+func NewField(name string, t Type, nullable bool) arrow.Field {
+	return arrow.Field{
+		Name:     name,
+		Type:     t.StorageType(),
+		Nullable: nullable,
+		Metadata: metadata.WithExtension(arrow.Metadata{}, t.Name(), ""),
+	}
+}
+
+// TypeOf returns the registered extension type of field. Fields whose type is
+// not registered or whose storage type does not match the registered one have none.
+func TypeOf(field arrow.Field) (Type, bool) {
+	name, _, ok := metadata.Extension(field.Metadata)
+	if !ok {
+		return nil, false
+	}
+	t, ok := Lookup(name)
+	if !ok || !arrow.TypeEqual(t.StorageType(), field.Type) {
+		return nil, false
+	}
+	return t, true
+}
+
+// fixedType is an extension type stored as fixed size binaries.
+type fixedType struct {
+	name  string
+	width int
+}
+
+func (t fixedType) Name() string { return t.name }
+func (t fixedType) StorageType() arrow.DataType {
+	return &arrow.FixedSizeBinaryType{ByteWidth: t.width}
+}
+
+// parseFixed parses the strings of col to a column of the fixed size binary
+// type t, named like col. parse writes the value of s to dst, which is as long
+// as the values of t, and reports whether s is valid. It also returns whether
+// every row failed to parse, like compute.ParseTimestamp does.
+func parseFixed(mem memory.Allocator, col *array.Column, t fixedType, onError compute.ParseErrorPolicy, parse func(s string, dst []byte) bool) (*array.Column, *array.Boolean, error) {
+	if col.DataType().ID() != arrow.STRING {
+		return nil, nil, fmt.Errorf("extension: cannot parse column %q of type %s", col.Name(), col.DataType())
+	}
+
+	bldr := array.NewFixedSizeBinaryBuilder(mem, t.StorageType().(*arrow.FixedSizeBinaryType))
+	defer bldr.Release()
+	bldr.Reserve(col.Len())
+	failed := array.NewBooleanBuilder(mem)
+	defer failed.Release()
+	failed.Reserve(col.Len())
+
+	value := make([]byte, t.width)
+	row := 0
+	for _, chunk := range col.Data().Chunks() {
+		strs := chunk.(*array.String)
+		for i := 0; i < strs.Len(); i, row = i+1, row+1 {
+			switch {
+			case strs.IsNull(i):
+				bldr.AppendNull()
+				failed.Append(false)
+			case parse(strs.Value(i), value):
+				bldr.Append(value)
+				failed.Append(false)
+			case onError == compute.ParseFail:
+				return nil, nil, fmt.Errorf("extension: cannot parse %q as %s in column %q, row %d", strs.Value(i), t.name, col.Name(), row)
+			default:
+				bldr.AppendNull()
+				failed.Append(true)
+			}
+		}
+	}
+
+	return newColumn(NewField(col.Name(), t, true), bldr.NewArray()), failed.NewBooleanArray(), nil
+}
+
+// formatFixed formats the values of col, a column of the fixed size binary
+// type t, to a string column named like col.
+func formatFixed(mem memory.Allocator, col *array.Column, t fixedType, format func(v []byte) string) (*array.Column, error) {
+	if !arrow.TypeEqual(col.DataType(), t.StorageType()) {
+		return nil, fmt.Errorf("extension: cannot format column %q of type %s as %s", col.Name(), col.DataType(), t.name)
+	}
+
+	bldr := array.NewStringBuilder(mem)
+	defer bldr.Release()
+	bldr.Reserve(col.Len())
+	for _, chunk := range col.Data().Chunks() {
+		values := chunk.(*array.FixedSizeBinary)
+		for i := 0; i < values.Len(); i++ {
+			if values.IsNull(i) {
+				bldr.AppendNull()
+				continue
+			}
+			bldr.Append(format(values.Value(i)))
+		}
+	}
+
+	field := arrow.Field{Name: col.Name(), Type: arrow.BinaryTypes.String, Nullable: true}
+	return newColumn(field, bldr.NewArray()), nil
+}
+
+// newColumn returns a single chunk Column of field holding arr, releasing arr.
+func newColumn(field arrow.Field, arr array.Interface) *array.Column {
+	defer arr.Release()
+	chunked := array.NewChunked(arr.DataType(), []array.Interface{arr})
+	defer chunked.Release()
+	return array.NewColumn(field, chunked)
+}
@@ -0,0 +1,191 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extension
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+func newStringColumn(mem memory.Allocator, name string, values []string, valid []bool) *array.Column {
+	bldr := array.NewStringBuilder(mem)
+	defer bldr.Release()
+	bldr.AppendValues(values, valid)
+	return newColumn(arrow.Field{Name: name, Type: arrow.BinaryTypes.String, Nullable: true}, bldr.NewArray())
+}
+
+func TestRegistry(t *testing.T) {
+	if err := Register(fixedType{name: "arrow.uuid", width: 16}); err == nil {
+		t.Fatal("expected an error for a type registered twice")
+	}
+
+	field := NewField("id", UUID, false)
+	if got, ok := TypeOf(field); !ok || got.Name() != "arrow.uuid" {
+		t.Fatalf("got=%v, %v", got, ok)
+	}
+	field.Type = arrow.BinaryTypes.String
+	if _, ok := TypeOf(field); ok {
+		t.Fatal("expected no type for a field of another storage type")
+	}
+
+	custom := fixedType{name: "test.custom", width: 2}
+	if err := Register(custom); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := TypeOf(NewField("c", custom, true)); !ok {
+		t.Fatal("expected the custom type to be found")
+	}
+	Unregister(custom.Name())
+	if _, ok := Lookup(custom.Name()); ok {
+		t.Fatal("expected the custom type to be unregistered")
+	}
+}
+
+func TestUUID(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	col := newStringColumn(pool, "id", []string{
+		"123E4567-E89B-12D3-A456-426614174000",
+		"123e4567e89b12d3a456426614174000",
+		"",
+		"not-a-uuid",
+	}, []bool{true, true, false, true})
+	defer col.Release()
+
+	ids, failed, err := ParseUUID(pool, col, compute.ParseNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ids.Release()
+	defer failed.Release()
+	if got, want := fmt.Sprint(failed), "[false false false true]"; got != want {
+		t.Fatalf("got failed=%s, want=%s", got, want)
+	}
+	if typ, ok := TypeOf(ids.Field()); !ok || typ != UUID {
+		t.Fatalf("got type %v", typ)
+	}
+
+	strs, err := FormatUUID(pool, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer strs.Release()
+	want := `["123e4567-e89b-12d3-a456-426614174000" "123e4567-e89b-12d3-a456-426614174000" (null) (null)]`
+	if got := fmt.Sprint(strs.Data().Chunk(0)); got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+
+	// Equal UUIDs fall in the same group.
+	g, err := compute.GroupRows(ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(g.IDs), "[0 0 1 1]"; got != want {
+		t.Fatalf("got groups=%s, want=%s", got, want)
+	}
+
+	_, _, err = ParseUUID(pool, col, compute.ParseFail)
+	if err == nil || err.Error() != `extension: cannot parse "not-a-uuid" as arrow.uuid in column "id", row 3` {
+		t.Fatalf("got error %v", err)
+	}
+}
+
+func TestIP(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	col := newStringColumn(pool, "ip", []string{"10.0.0.1", "::1", "192.168.1.300"}, nil)
+	defer col.Release()
+
+	for _, tc := range []struct {
+		parse  func(memory.Allocator, *array.Column, compute.ParseErrorPolicy) (*array.Column, *array.Boolean, error)
+		typ    Type
+		want   string
+		failed string
+	}{
+		{ParseIPv4, IPv4, `["10.0.0.1" (null) (null)]`, "[false true true]"},
+		{ParseIPv6, IPv6, `["10.0.0.1" "::1" (null)]`, "[false false true]"},
+	} {
+		ips, failed, err := tc.parse(pool, col, compute.ParseNull)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ, ok := TypeOf(ips.Field()); !ok || typ != tc.typ {
+			t.Errorf("got type %v, want %v", typ, tc.typ)
+		}
+		if got := fmt.Sprint(failed); got != tc.failed {
+			t.Errorf("%s: got failed=%s, want=%s", tc.typ.Name(), got, tc.failed)
+		}
+		strs, err := FormatIP(pool, ips)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprint(strs.Data().Chunk(0)); got != tc.want {
+			t.Errorf("%s: got=%s, want=%s", tc.typ.Name(), got, tc.want)
+		}
+		strs.Release()
+		ips.Release()
+		failed.Release()
+	}
+}
+
+func TestJoinOnUUID(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	newFrame := func(ids []string, name string, values []int64) *dataframe.DataFrame {
+		col := newStringColumn(pool, "id", ids, nil)
+		defer col.Release()
+		uuids, failed, err := ParseUUID(pool, col, compute.ParseFail)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer uuids.Release()
+		defer failed.Release()
+
+		bldr := array.NewInt64Builder(pool)
+		defer bldr.Release()
+		bldr.AppendValues(values, nil)
+		other := newColumn(arrow.Field{Name: name, Type: arrow.PrimitiveTypes.Int64}, bldr.NewArray())
+		defer other.Release()
+
+		df, err := dataframe.NewDataFrameFromColumns(pool, []array.Column{*uuids, *other})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return df
+	}
+	a, b := "00000000-0000-0000-0000-00000000000a", "00000000-0000-0000-0000-00000000000b"
+	left := newFrame([]string{a, b, a}, "x", []int64{1, 2, 3})
+	defer left.Release()
+	right := newFrame([]string{b, a}, "y", []int64{20, 10})
+	defer right.Release()
+
+	joined, err := left.InnerJoin(right, []string{"id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer joined.Release()
+	if got, want := fmt.Sprint(joined.Column("x").Data().Chunk(0), joined.Column("y").Data().Chunk(0)), "[1 2 3] [10 20 10]"; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extension
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/expr"
+)
+
+// GeoPoint holds points on Earth as fixed size lists of two float64, the
+// latitude and the longitude in degrees.
+var GeoPoint Type = geoPointType{}
+
+type geoPointType struct{}
+
+func (geoPointType) Name() string { return "gomem.geo_point" }
+func (geoPointType) StorageType() arrow.DataType {
+	return arrow.FixedSizeListOf(2, arrow.PrimitiveTypes.Float64)
+}
+
+// EarthRadius is the mean radius of the Earth in meters, used by Haversine.
+const EarthRadius = 6371008.8
+
+// Haversine returns the great-circle distance in meters between two points
+// given by their latitude and longitude in degrees.
+func Haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	const rad = math.Pi / 180
+	dlat := (lat2 - lat1) * rad
+	dlon := (lon2 - lon1) * rad
+	a := math.Sin(dlat/2)*math.Sin(dlat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dlon/2)*math.Sin(dlon/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// BoundingBox is a range of latitudes and longitudes in degrees. A box whose
+// MinLon is greater than its MaxLon crosses the antimeridian.
+type BoundingBox struct {
+	MinLat, MinLon float64
+	MaxLat, MaxLon float64
+}
+
+// Contains reports whether the point is in the box, borders included.
+func (b BoundingBox) Contains(lat, lon float64) bool {
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+	if b.MinLon > b.MaxLon {
+		return lon >= b.MinLon || lon <= b.MaxLon
+	}
+	return lon >= b.MinLon && lon <= b.MaxLon
+}
+
+// NewGeoPoints returns a GeoPoint column named name from columns of latitudes
+// and longitudes in degrees. Rows where either is null are null.
+func NewGeoPoints(mem memory.Allocator, name string, lat, lon *array.Column) (*array.Column, error) {
+	if lat.Len() != lon.Len() {
+		return nil, fmt.Errorf("extension: latitudes and longitudes have different lengths (%d != %d)", lat.Len(), lon.Len())
+	}
+	lats, err := floatCursor(lat)
+	if err != nil {
+		return nil, err
+	}
+	lons, err := floatCursor(lon)
+	if err != nil {
+		return nil, err
+	}
+
+	bldr := array.NewFixedSizeListBuilder(mem, 2, arrow.PrimitiveTypes.Float64)
+	defer bldr.Release()
+	values := bldr.ValueBuilder().(*array.Float64Builder)
+	for i := 0; i < lat.Len(); i++ {
+		la, laOk := lats()
+		lo, loOk := lons()
+		// Null points hold values too, so that the points stay two values apart.
+		bldr.Append(laOk && loOk)
+		values.AppendValues([]float64{la, lo}, nil)
+	}
+	return newColumn(NewField(name, GeoPoint, true), bldr.NewArray()), nil
+}
+
+// Distances returns the distances in meters between the points of the GeoPoint
+// columns a and b, row by row, null where either point is null.
+func Distances(mem memory.Allocator, a, b *array.Column) (*array.Column, error) {
+	if a.Len() != b.Len() {
+		return nil, fmt.Errorf("extension: point columns have different lengths (%d != %d)", a.Len(), b.Len())
+	}
+	as, err := pointCursor(a)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := pointCursor(b)
+	if err != nil {
+		return nil, err
+	}
+
+	bldr := array.NewFloat64Builder(mem)
+	defer bldr.Release()
+	bldr.Reserve(a.Len())
+	for i := 0; i < a.Len(); i++ {
+		lat1, lon1, ok1 := as()
+		lat2, lon2, ok2 := bs()
+		if !ok1 || !ok2 {
+			bldr.AppendNull()
+			continue
+		}
+		bldr.Append(Haversine(lat1, lon1, lat2, lon2))
+	}
+	return newColumn(arrow.Field{Name: a.Name(), Type: arrow.PrimitiveTypes.Float64, Nullable: true}, bldr.NewArray()), nil
+}
+
+// DistancesTo returns the distances in meters between the points of the
+// GeoPoint column col and the point (lat, lon), null for null points.
+func DistancesTo(mem memory.Allocator, col *array.Column, lat, lon float64) (*array.Column, error) {
+	points, err := pointCursor(col)
+	if err != nil {
+		return nil, err
+	}
+
+	bldr := array.NewFloat64Builder(mem)
+	defer bldr.Release()
+	bldr.Reserve(col.Len())
+	for i := 0; i < col.Len(); i++ {
+		plat, plon, ok := points()
+		if !ok {
+			bldr.AppendNull()
+			continue
+		}
+		bldr.Append(Haversine(plat, plon, lat, lon))
+	}
+	return newColumn(arrow.Field{Name: col.Name(), Type: arrow.PrimitiveTypes.Float64, Nullable: true}, bldr.NewArray()), nil
+}
+
+// InBoundingBox returns a boolean column telling whether the points of the
+// GeoPoint column col are in box, null for null points. It is a mask usable
+// with compute.Filter.
+func InBoundingBox(mem memory.Allocator, col *array.Column, box BoundingBox) (*array.Column, error) {
+	points, err := pointCursor(col)
+	if err != nil {
+		return nil, err
+	}
+
+	bldr := array.NewBooleanBuilder(mem)
+	defer bldr.Release()
+	bldr.Reserve(col.Len())
+	for i := 0; i < col.Len(); i++ {
+		lat, lon, ok := points()
+		if !ok {
+			bldr.AppendNull()
+			continue
+		}
+		bldr.Append(box.Contains(lat, lon))
+	}
+	return newColumn(arrow.Field{Name: col.Name(), Type: arrow.FixedWidthTypes.Boolean, Nullable: true}, bldr.NewArray()), nil
+}
+
+// Distance returns an expression computing the distances in meters between
+// the points e evaluates to and the point (lat, lon).
+func Distance(e expr.Expr, lat, lon float64) expr.Expr {
+	return expr.Call(fmt.Sprintf("distance[%g,%g]", lat, lon), func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		return DistancesTo(mem, args[0], lat, lon)
+	}, e)
+}
+
+// DistanceBetween returns an expression computing the distances in meters
+// between the points a and b evaluate to.
+func DistanceBetween(a, b expr.Expr) expr.Expr {
+	return expr.Call("distance", func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		return Distances(mem, args[0], args[1])
+	}, a, b)
+}
+
+// Within returns an expression telling whether the points e evaluates to are in box.
+func Within(e expr.Expr, box BoundingBox) expr.Expr {
+	name := fmt.Sprintf("within[%g,%g,%g,%g]", box.MinLat, box.MinLon, box.MaxLat, box.MaxLon)
+	return expr.Call(name, func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		return InBoundingBox(mem, args[0], box)
+	}, e)
+}
+
+// pointCursor returns a function returning the points of the GeoPoint column
+// col in order, and whether they are valid.
+func pointCursor(col *array.Column) (func() (lat, lon float64, ok bool), error) {
+	if !arrow.TypeEqual(col.DataType(), GeoPoint.StorageType()) {
+		return nil, fmt.Errorf("extension: column %q of type %s does not hold points", col.Name(), col.DataType())
+	}
+	chunks := col.Data().Chunks()
+	c, i := 0, 0
+	return func() (float64, float64, bool) {
+		for i == chunks[c].Len() {
+			c, i = c+1, 0
+		}
+		points := chunks[c].(*array.FixedSizeList)
+		values := points.ListValues().(*array.Float64)
+		k := 2 * (points.Data().Offset() + i)
+		valid := points.IsValid(i) && values.IsValid(k) && values.IsValid(k+1)
+		i++
+		return values.Value(k), values.Value(k + 1), valid
+	}, nil
+}
+
+// floatCursor returns a function returning the values of the numeric column
+// col in order as float64, and whether they are valid.
+func floatCursor(col *array.Column) (func() (float64, bool), error) {
+	chunks := col.Data().Chunks()
+	getters := make([]func(int) float64, len(chunks))
+	for c, chunk := range chunks {
+		switch chunk := chunk.(type) {
+		case *array.Float64:
+			getters[c] = chunk.Value
+		case *array.Float32:
+			getters[c] = func(i int) float64 { return float64(chunk.Value(i)) }
+		default:
+			return nil, fmt.Errorf("extension: column %q of type %s does not hold coordinates", col.Name(), col.DataType())
+		}
+	}
+	c, i := 0, 0
+	return func() (float64, bool) {
+		for i == chunks[c].Len() {
+			c, i = c+1, 0
+		}
+		v, ok := getters[c](i), chunks[c].IsValid(i)
+		i++
+		return v, ok
+	}, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extension
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/dataframe"
+	"github.com/gomem/gomem/pkg/expr"
+)
+
+func TestGeoPoint(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	newFloats := func(name string, values []float64, valid []bool) *array.Column {
+		bldr := array.NewFloat64Builder(pool)
+		defer bldr.Release()
+		bldr.AppendValues(values, valid)
+		return newColumn(arrow.Field{Name: name, Type: arrow.PrimitiveTypes.Float64, Nullable: true}, bldr.NewArray())
+	}
+	// Paris, London, a null point and Fiji, east of the antimeridian.
+	lat := newFloats("lat", []float64{48.8566, 51.5074, 0, -17.7}, nil)
+	defer lat.Release()
+	lon := newFloats("lon", []float64{2.3522, -0.1278, 0, 178.1}, []bool{true, true, false, true})
+	defer lon.Release()
+
+	points, err := NewGeoPoints(pool, "city", lat, lon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer points.Release()
+	if typ, ok := TypeOf(points.Field()); !ok || typ != GeoPoint {
+		t.Fatalf("got type %v", typ)
+	}
+
+	df, err := dataframe.NewDataFrameFromColumns(pool, []array.Column{*points})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	df2, err := df.WithColumn("to_paris", Distance(expr.Col("city"), 48.8566, 2.3522))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df2.Release()
+	dists := df2.Column("to_paris").Data().Chunk(0).(*array.Float64)
+	if dists.Value(0) != 0 || math.Abs(dists.Value(1)-343556.53) > 0.01 || dists.IsValid(2) {
+		t.Fatalf("got distances %v", dists)
+	}
+
+	for _, tc := range []struct {
+		box  BoundingBox
+		want string
+	}{
+		{BoundingBox{MinLat: 40, MinLon: -5, MaxLat: 50, MaxLon: 10}, "[true false (null) false]"},
+		{BoundingBox{MinLat: -20, MinLon: 170, MaxLat: 0, MaxLon: -170}, "[false false (null) true]"},
+	} {
+		e := Within(expr.Col("city"), tc.box)
+		mask, err := e.Eval(pool, df)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprint(mask.Data().Chunk(0)); got != tc.want {
+			t.Errorf("%s: got=%s, want=%s", e, got, tc.want)
+		}
+		mask.Release()
+	}
+
+	self, err := DistanceBetween(expr.Col("city"), expr.Col("city")).Eval(pool, df)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer self.Release()
+	if got, want := fmt.Sprint(self.Data().Chunk(0)), "[0 0 (null) 0]"; got != want {
+		t.Fatalf("got=%s, want=%s", got, want)
+	}
+
+	if _, err := DistancesTo(pool, lat, 0, 0); err == nil {
+		t.Fatal("expected an error for a column that does not hold points")
+	}
+}
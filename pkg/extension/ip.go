@@ -0,0 +1,71 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extension
+
+import (
+	"net"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+var (
+	// IPv4 holds IPv4 addresses as 4 byte fixed size binaries in network order,
+	// so that they sort like the addresses.
+	IPv4 Type = ipv4Type
+	// IPv6 holds IPv6 addresses as 16 byte fixed size binaries in network order.
+	// IPv4 addresses are stored as IPv4-mapped IPv6 addresses.
+	IPv6 Type = ipv6Type
+)
+
+var (
+	ipv4Type = fixedType{name: "gomem.ipv4", width: net.IPv4len}
+	ipv6Type = fixedType{name: "gomem.ipv6", width: net.IPv6len}
+)
+
+// ParseIPv4 parses the strings of col, in dotted decimal notation, to an IPv4
+// column named like col. It also returns whether every row failed to parse,
+// like compute.ParseTimestamp does, and onError selects whether these rows are
+// null or an error.
+func ParseIPv4(mem memory.Allocator, col *array.Column, onError compute.ParseErrorPolicy) (*array.Column, *array.Boolean, error) {
+	return parseFixed(mem, col, ipv4Type, onError, func(s string, dst []byte) bool {
+		ip := net.ParseIP(s).To4()
+		copy(dst, ip)
+		return ip != nil
+	})
+}
+
+// ParseIPv6 parses the strings of col, IPv6 or IPv4 addresses, to an IPv6
+// column named like col, see ParseIPv4.
+func ParseIPv6(mem memory.Allocator, col *array.Column, onError compute.ParseErrorPolicy) (*array.Column, *array.Boolean, error) {
+	return parseFixed(mem, col, ipv6Type, onError, func(s string, dst []byte) bool {
+		ip := net.ParseIP(s)
+		copy(dst, ip.To16())
+		return ip != nil
+	})
+}
+
+// FormatIP formats the values of the IPv4 or IPv6 column col to strings, in a
+// string column named like col. IPv4-mapped IPv6 addresses are written as IPv4
+// addresses.
+func FormatIP(mem memory.Allocator, col *array.Column) (*array.Column, error) {
+	t := ipv6Type
+	if arrow.TypeEqual(col.DataType(), ipv4Type.StorageType()) {
+		t = ipv4Type
+	}
+	return formatFixed(mem, col, t, func(v []byte) string { return net.IP(v).String() })
+}
@@ -0,0 +1,66 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extension
+
+import (
+	"encoding/hex"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// UUID is the canonical Arrow UUID type, stored as 16 byte fixed size binaries.
+var UUID Type = uuidType
+
+var uuidType = fixedType{name: "arrow.uuid", width: 16}
+
+// ParseUUID parses the strings of col to a UUID column named like col. UUIDs
+// are written as 32 hexadecimal digits, optionally grouped 8-4-4-4-12 by hyphens.
+// It also returns whether every row failed to parse, like compute.ParseTimestamp
+// does, and onError selects whether these rows are null or an error.
+func ParseUUID(mem memory.Allocator, col *array.Column, onError compute.ParseErrorPolicy) (*array.Column, *array.Boolean, error) {
+	return parseFixed(mem, col, uuidType, onError, func(s string, dst []byte) bool {
+		if len(s) == 36 {
+			if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+				return false
+			}
+			s = s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+		}
+		if len(s) != 32 {
+			return false
+		}
+		_, err := hex.Decode(dst, []byte(s))
+		return err == nil
+	})
+}
+
+// FormatUUID formats the values of the UUID column col to lower case strings
+// grouped 8-4-4-4-12 by hyphens, in a string column named like col.
+func FormatUUID(mem memory.Allocator, col *array.Column) (*array.Column, error) {
+	return formatFixed(mem, col, uuidType, func(v []byte) string {
+		var buf [36]byte
+		hex.Encode(buf[0:8], v[0:4])
+		buf[8] = '-'
+		hex.Encode(buf[9:13], v[4:6])
+		buf[13] = '-'
+		hex.Encode(buf[14:18], v[6:8])
+		buf[18] = '-'
+		hex.Encode(buf[19:23], v[8:10])
+		buf[23] = '-'
+		hex.Encode(buf[24:], v[10:])
+		return string(buf[:])
+	})
+}
@@ -0,0 +1,42 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package fbio embeds Arrow records in flatbuffers messages.
+
+The records are encoded with the Arrow IPC stream format into a ubyte vector
+of the message, aligned so that the buffers of the records are aligned as in
+an IPC stream. The field holding them is declared in the schema of the
+message like any other:
+
+	table Envelope {
+		id: string;
+		payload: [ubyte];
+	}
+
+CreateRecord writes the vector before the table is started, as for any other
+vector, and NewRecordReader and ReadRecord decode the bytes returned by the
+generated accessor, or by ByteVector, without copying them: the buffers of the
+records point into the message, which must be kept unmodified while they are
+in use.
+
+	payload, err := fbio.CreateRecord(b, rec)
+	EnvelopeStart(b)
+	EnvelopeAddPayload(b, payload)
+	b.Finish(EnvelopeEnd(b))
+
+	rec, err := fbio.ReadRecord(GetRootAsEnvelope(buf, 0).PayloadBytes())
+	defer rec.Release()
+*/
+package fbio
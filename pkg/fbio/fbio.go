@@ -0,0 +1,134 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fbio
+
+import (
+	"bytes"
+	"fmt"
+	"unsafe"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// Alignment is the alignment of the vectors written by CreateRecord, which
+// is the alignment of the buffers in an Arrow IPC stream.
+const Alignment = 8
+
+// CreateRecord writes rec, encoded with the Arrow IPC stream format, as a
+// ubyte vector of b, and returns its offset to be added to the table being
+// built. Like any vector, it must be created before the table is started.
+func CreateRecord(b *flatbuffers.Builder, rec array.Record, opts ...Option) (flatbuffers.UOffsetT, error) {
+	rdr, err := array.NewRecordReader(rec.Schema(), []array.Record{rec})
+	if err != nil {
+		return 0, err
+	}
+	defer rdr.Release()
+	return CreateRecords(b, rdr, opts...)
+}
+
+// CreateRecords writes the records of rdr as a single Arrow IPC stream in a
+// ubyte vector of b, as CreateRecord does.
+func CreateRecords(b *flatbuffers.Builder, rdr array.RecordReader, opts ...Option) (flatbuffers.UOffsetT, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return 0, err
+	}
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, append(cfg.ipcOptions(), ipc.WithSchema(rdr.Schema()))...)
+	for rdr.Next() {
+		if err := w.Write(rdr.Record()); err != nil {
+			return 0, fmt.Errorf("fbio: could not encode record: %w", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return 0, fmt.Errorf("fbio: could not encode records: %w", err)
+	}
+	return CreateAlignedByteVector(b, buf.Bytes()), nil
+}
+
+// CreateAlignedByteVector writes data as a ubyte vector of b whose first
+// byte is aligned to Alignment within the finished buffer.
+func CreateAlignedByteVector(b *flatbuffers.Builder, data []byte) flatbuffers.UOffsetT {
+	b.StartVector(1, len(data), Alignment)
+	b.Pad(len(data))
+	copy(b.Bytes[b.Head():], data)
+	return b.EndVector(len(data))
+}
+
+// ByteVector returns the ubyte vector of the field of tab at the vtable
+// offset field, as the accessor generated for the field does, or nil when
+// the field is not set. The vector points into the buffer of tab.
+func ByteVector(tab *flatbuffers.Table, field flatbuffers.VOffsetT) []byte {
+	o := flatbuffers.UOffsetT(tab.Offset(field))
+	if o == 0 {
+		return nil
+	}
+	return tab.ByteVector(o + tab.Pos)
+}
+
+// NewRecordReader returns a reader of the records encoded in data by
+// CreateRecord or CreateRecords. The buffers of the records point into data,
+// which must not be modified while they are in use. data is only copied when
+// it is not aligned to Alignment in memory, as when the flatbuffer itself
+// was read into a misaligned slice.
+func NewRecordReader(data []byte, opts ...Option) (*ipc.Reader, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 && uintptr(unsafe.Pointer(&data[0]))%Alignment != 0 {
+		data = append(make([]byte, 0, len(data)), data...)
+	}
+	r, err := ipc.NewReaderFromBytes(data, cfg.ipcOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("fbio: could not decode records: %w", err)
+	}
+	return r, nil
+}
+
+// ReadRecord decodes the record encoded in data by CreateRecord, without
+// copying it as NewRecordReader does. The record must be released by the
+// caller.
+func ReadRecord(data []byte, opts ...Option) (array.Record, error) {
+	r, err := NewRecordReader(data, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Release()
+
+	var recs []array.Record
+	for r.Next() {
+		rec := r.Record()
+		rec.Retain()
+		recs = append(recs, rec)
+	}
+	if err := r.Err(); err != nil {
+		releaseRecords(recs)
+		return nil, fmt.Errorf("fbio: could not decode record: %w", err)
+	}
+	if len(recs) != 1 {
+		releaseRecords(recs)
+		return nil, fmt.Errorf("fbio: expected 1 record, got %d", len(recs))
+	}
+	return recs[0], nil
+}
+
+func releaseRecords(recs []array.Record) {
+	for _, rec := range recs {
+		rec.Release()
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fbio
+
+import (
+	"errors"
+	"testing"
+	"unsafe"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// The envelope of the tests is the table
+//
+//	table Envelope {
+//		id: string;
+//		payload: [ubyte];
+//	}
+const (
+	envelopeID      = 4
+	envelopePayload = 6
+)
+
+func newEnvelope(t *testing.T, id string, create func(b *flatbuffers.Builder) (flatbuffers.UOffsetT, error)) []byte {
+	t.Helper()
+	b := flatbuffers.NewBuilder(0)
+	// A short string first, so that the payload does not happen to be
+	// aligned.
+	sid := b.CreateString(id)
+	payload, err := create(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.StartObject(2)
+	b.PrependUOffsetTSlot(0, sid, 0)
+	b.PrependUOffsetTSlot(1, payload, 0)
+	b.Finish(b.EndObject())
+	return b.FinishedBytes()
+}
+
+func envelopeTable(buf []byte) *flatbuffers.Table {
+	return &flatbuffers.Table{Bytes: buf, Pos: flatbuffers.GetUOffsetT(buf)}
+}
+
+func newRecord(mem memory.Allocator, ids []int64, names []string) array.Record {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+	b.Field(0).(*array.Int64Builder).AppendValues(ids, nil)
+	b.Field(1).(*array.StringBuilder).AppendValues(names, nil)
+	return b.NewRecord()
+}
+
+func TestRecord(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	rec := newRecord(pool, []int64{1, 2, 3}, []string{"a", "b", "c"})
+	defer rec.Release()
+	buf := newEnvelope(t, "x", func(b *flatbuffers.Builder) (flatbuffers.UOffsetT, error) {
+		return CreateRecord(b, rec, WithAllocator(pool))
+	})
+
+	tab := envelopeTable(buf)
+	if got, want := string(ByteVector(tab, envelopeID)), "x"; got != want {
+		t.Fatalf("id: got=%q, want=%q", got, want)
+	}
+	payload := ByteVector(tab, envelopePayload)
+	if off := uintptr(unsafe.Pointer(&payload[0])) - uintptr(unsafe.Pointer(&buf[0])); off%Alignment != 0 {
+		t.Fatalf("payload at offset %d is not aligned", off)
+	}
+	if got := ByteVector(tab, 8); got != nil {
+		t.Fatalf("missing field: got=%v, want=nil", got)
+	}
+
+	got, err := ReadRecord(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Release()
+	if !array.RecordEqual(got, rec) {
+		t.Fatalf("got=%v, want=%v", got, rec)
+	}
+
+	// The values of the record point into the message.
+	values := got.Column(0).Data().Buffers()[1].Bytes()
+	start := uintptr(unsafe.Pointer(&buf[0]))
+	if p := uintptr(unsafe.Pointer(&values[0])); p < start || p >= start+uintptr(len(buf)) {
+		t.Fatal("the record was copied out of the message")
+	}
+}
+
+func TestRecords(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	recs := []array.Record{
+		newRecord(pool, []int64{1, 2}, []string{"a", "b"}),
+		newRecord(pool, []int64{3}, []string{"c"}),
+	}
+	defer func() {
+		for _, rec := range recs {
+			rec.Release()
+		}
+	}()
+	rdr, err := array.NewRecordReader(recs[0].Schema(), recs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rdr.Release()
+	buf := newEnvelope(t, "x", func(b *flatbuffers.Builder) (flatbuffers.UOffsetT, error) {
+		return CreateRecords(b, rdr, WithAllocator(pool), WithChecksum())
+	})
+	payload := ByteVector(envelopeTable(buf), envelopePayload)
+
+	// A message read into a misaligned slice is decoded from a copy.
+	misaligned := make([]byte, len(payload)+1)[1:]
+	copy(misaligned, payload)
+
+	r, err := NewRecordReader(misaligned, WithChecksum())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+	n := 0
+	for ; r.Next(); n++ {
+		if !array.RecordEqual(r.Record(), recs[n]) {
+			t.Fatalf("record %d: got=%v, want=%v", n, r.Record(), recs[n])
+		}
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n != len(recs) {
+		t.Fatalf("got %d records, want=%d", n, len(recs))
+	}
+
+	if _, err := ReadRecord(payload, WithChecksum()); err == nil {
+		t.Fatal("expected an error reading 2 records as one")
+	}
+	corrupted := append([]byte(nil), payload...)
+	corrupted[len(corrupted)-16] ^= 0xff
+	if _, err := ReadRecord(corrupted, WithChecksum()); !errors.Is(err, ipc.ErrChecksum) {
+		t.Fatalf("got err=%v, want=%v", err, ipc.ErrChecksum)
+	}
+}
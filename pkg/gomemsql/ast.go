@@ -0,0 +1,170 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomemsql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// node is an expression of a query.
+type node interface {
+	// String returns the canonical form of the expression, which names the
+	// columns computed from it.
+	String() string
+}
+
+type identNode struct {
+	qualifier string
+	name      string
+}
+
+func (n *identNode) String() string {
+	if n.qualifier != "" {
+		return n.qualifier + "." + n.name
+	}
+	return n.name
+}
+
+// literalNode holds nil, a bool, an int64, a float64 or a string.
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) String() string {
+	switch v := n.value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case string:
+		return "'" + strings.Replace(v, "'", "''", -1) + "'"
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return strconv.FormatInt(v.(int64), 10)
+	}
+}
+
+type unaryNode struct {
+	op string
+	x  node
+}
+
+func (n *unaryNode) String() string {
+	if n.op == "NOT" {
+		return "NOT " + operand(n.x)
+	}
+	return n.op + operand(n.x)
+}
+
+type binaryNode struct {
+	op   string
+	l, r node
+}
+
+func (n *binaryNode) String() string {
+	return operand(n.l) + " " + n.op + " " + operand(n.r)
+}
+
+type isNullNode struct {
+	x   node
+	not bool
+}
+
+func (n *isNullNode) String() string {
+	if n.not {
+		return operand(n.x) + " IS NOT NULL"
+	}
+	return operand(n.x) + " IS NULL"
+}
+
+// callNode is a function call. The name is lower case.
+type callNode struct {
+	name string
+	args []node
+	// star is set for count(*).
+	star bool
+}
+
+func (n *callNode) String() string {
+	if n.star {
+		return n.name + "(*)"
+	}
+	args := make([]string, len(n.args))
+	for i, arg := range n.args {
+		args[i] = arg.String()
+	}
+	return n.name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// operand returns the form of n as the operand of an operator.
+func operand(n node) string {
+	switch n.(type) {
+	case *binaryNode, *isNullNode, *unaryNode:
+		return "(" + n.String() + ")"
+	default:
+		return n.String()
+	}
+}
+
+type selectStmt struct {
+	distinct bool
+	items    []selectItem
+	from     tableRef
+	joins    []joinClause
+	where    node
+	groupBy  []node
+	having   node
+	orderBy  []orderItem
+	limit    int64
+	offset   int64
+}
+
+// selectItem is an expression of the select list, or all the columns of
+// a table, or of every table, when star is set.
+type selectItem struct {
+	expr      node
+	alias     string
+	star      bool
+	qualifier string
+}
+
+type tableRef struct {
+	name  string
+	alias string
+}
+
+// qualifier returns the name the columns of the table are qualified with.
+func (t tableRef) qualifier() string {
+	if t.alias != "" {
+		return t.alias
+	}
+	return t.name
+}
+
+type joinClause struct {
+	left  bool
+	table tableRef
+	on    node
+}
+
+type orderItem struct {
+	expr node
+	desc bool
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package gomemsql runs SQL queries over DataFrames.
+
+The tables of a query are the DataFrames of a map, by name. The result is a
+new DataFrame built with the allocator of the table of the FROM clause,
+unless WithAllocator is passed.
+
+	tables := map[string]*dataframe.DataFrame{"sales": sales}
+	df, err := gomemsql.Query(ctx, "SELECT region, sum(units) FROM sales GROUP BY region", tables)
+
+A subset of SELECT is supported:
+
+	SELECT [DISTINCT] expr [[AS] alias], ... | * | t.*
+	FROM table [[AS] alias]
+	[[INNER | LEFT [OUTER]] JOIN table [[AS] alias] ON a = b [AND c = d ...]]
+	[WHERE cond]
+	[GROUP BY expr, ...]
+	[HAVING cond]
+	[ORDER BY expr | alias | position [ASC | DESC], ...]
+	[LIMIT n] [OFFSET n]
+
+Expressions are made of columns, literals, arithmetic (+ - * / %),
+comparisons (= <> != < <= > >=), AND, OR, NOT, IS [NOT] NULL and the
+functions abs, lower, upper and length. The aggregate functions are count,
+sum, avg, min and max. The functions registered with expr.Register, of either
+kind, are called the same way. Join conditions only compare a column of each side.
+A ? placeholder takes the value of the next argument passed with WithArgs.
+
+Explain runs a query like Query and also returns its plan: the tree of the
+operators that ran, with the rows each one produced, the time it took and
+the memory it allocated. A Plan prints as an indented tree and marshals to
+JSON.
+
+	df, plan, err := gomemsql.Explain(ctx, "SELECT region, sum(units) FROM sales GROUP BY region", tables)
+	fmt.Print(plan)
+
+The package also registers a read-only database/sql driver named "gomem",
+which runs queries over the DataFrames made available with Register.
+
+	gomemsql.Register("sales", sales)
+	db, err := sql.Open("gomem", "")
+	rows, err := db.Query("SELECT region FROM sales WHERE units > ?", 2)
+*/
+package gomemsql
@@ -0,0 +1,239 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomemsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+// DriverName is the name of the database/sql driver serving the registered DataFrames.
+const DriverName = "gomem"
+
+func init() {
+	sql.Register(DriverName, Driver{})
+}
+
+var errReadOnly = errors.New("gomemsql: the database is read-only")
+
+var registry = struct {
+	sync.RWMutex
+	tables map[string]*dataframe.DataFrame
+}{tables: make(map[string]*dataframe.DataFrame)}
+
+// Register makes df queryable as the table name by the connections of the
+// driver, replacing the DataFrame previously registered under that name.
+// The DataFrame is retained until it is unregistered.
+func Register(name string, df *dataframe.DataFrame) {
+	df.Retain()
+	registry.Lock()
+	defer registry.Unlock()
+	if old, ok := registry.tables[name]; ok {
+		old.Release()
+	}
+	registry.tables[name] = df
+}
+
+// Unregister removes the table name, releasing its DataFrame.
+func Unregister(name string) {
+	registry.Lock()
+	defer registry.Unlock()
+	if df, ok := registry.tables[name]; ok {
+		df.Release()
+		delete(registry.tables, name)
+	}
+}
+
+// snapshot returns the registered tables, retained so that they outlive a
+// concurrent Unregister.
+func snapshot() map[string]*dataframe.DataFrame {
+	registry.RLock()
+	defer registry.RUnlock()
+	tables := make(map[string]*dataframe.DataFrame, len(registry.tables))
+	for name, df := range registry.tables {
+		df.Retain()
+		tables[name] = df
+	}
+	return tables
+}
+
+// Driver is a read-only database/sql driver querying the registered
+// DataFrames with Query. The data source name is ignored.
+//
+//	gomemsql.Register("sales", df)
+//	db, err := sql.Open(gomemsql.DriverName, "")
+//	rows, err := db.Query("SELECT region, sum(units) FROM sales WHERE units > ? GROUP BY region", 2)
+type Driver struct{}
+
+// Open returns a new connection to the registered DataFrames.
+func (Driver) Open(name string) (driver.Conn, error) {
+	return &conn{}, nil
+}
+
+type conn struct{}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error { return nil }
+
+func (c *conn) Begin() (driver.Tx, error) { return nil, errReadOnly }
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, fmt.Errorf("gomemsql: named argument %q is not supported", arg.Name)
+		}
+		values[i] = arg.Value
+	}
+
+	tables := snapshot()
+	defer func() {
+		for _, df := range tables {
+			df.Release()
+		}
+	}()
+
+	df, err := Query(ctx, query, tables, WithArgs(values...))
+	if err != nil {
+		return nil, err
+	}
+	return newRows(df), nil
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error { return nil }
+
+// NumInput returns -1, the number of placeholders is checked by Query.
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) { return nil, errReadOnly }
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return s.QueryContext(context.Background(), named)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+// rows iterates over the rows of the result of a query.
+type rows struct {
+	df      *dataframe.DataFrame
+	cols    []array.Column
+	cursors []*cursor
+	row     int64
+}
+
+func newRows(df *dataframe.DataFrame) *rows {
+	cols := df.Columns()
+	cursors := make([]*cursor, len(cols))
+	for i := range cols {
+		cursors[i] = newCursor(&cols[i])
+	}
+	return &rows{df: df, cols: cols, cursors: cursors}
+}
+
+func (r *rows) Columns() []string { return r.df.ColumnNames() }
+
+func (r *rows) Close() error {
+	if r.df != nil {
+		r.df.Release()
+		r.df = nil
+	}
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.df == nil || r.row >= r.df.NumRows() {
+		return io.EOF
+	}
+	r.row++
+	for i, c := range r.cursors {
+		arr, j := c.next()
+		if arr.IsNull(j) {
+			dest[i] = nil
+			continue
+		}
+		switch k := kindOf(arr.DataType()); k {
+		case kindInt:
+			dest[i] = intValue(arr, j)
+		case kindFloat:
+			dest[i] = floatValue(arr, j)
+		case kindString:
+			if b, ok := arr.(*array.Binary); ok {
+				dest[i] = append([]byte(nil), b.Value(j)...)
+			} else {
+				dest[i] = stringValue(arr, j)
+			}
+		case kindBool:
+			dest[i] = arr.(*array.Boolean).Value(j)
+		default:
+			return fmt.Errorf("gomemsql: column %q: unsupported type %v", r.cols[i].Name(), arr.DataType())
+		}
+	}
+	return nil
+}
+
+// ColumnTypeDatabaseTypeName returns the name of the Arrow type of a column.
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.cols[index].DataType().Name()
+}
+
+func (r *rows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	return r.cols[index].Field().Nullable, true
+}
+
+func (r *rows) ColumnTypeScanType(index int) reflect.Type {
+	switch kindOf(r.cols[index].DataType()) {
+	case kindInt:
+		return reflect.TypeOf(int64(0))
+	case kindFloat:
+		return reflect.TypeOf(float64(0))
+	case kindString:
+		dtype := r.cols[index].DataType()
+		if dict, ok := dtype.(*arrow.DictionaryType); ok {
+			dtype = dict.ValueType
+		}
+		if dtype.ID() == arrow.BINARY {
+			return reflect.TypeOf([]byte(nil))
+		}
+		return reflect.TypeOf("")
+	case kindBool:
+		return reflect.TypeOf(false)
+	default:
+		return reflect.TypeOf(new(interface{})).Elem()
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomemsql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+// Plan is an operator of an executed query, with what it did. The inputs of
+// an operator are its children. A Plan marshals to JSON with encoding/json.
+type Plan struct {
+	// Operator is the kind of the operator: scan, join, filter, aggregate,
+	// project, distinct, sort or limit.
+	Operator string `json:"operator"`
+	// Detail describes what the operator computes.
+	Detail string `json:"detail,omitempty"`
+	// Rows is the number of rows the operator produced.
+	Rows int64 `json:"rows"`
+	// Duration is the time spent in the operator, without its inputs.
+	Duration time.Duration `json:"duration_ns"`
+	// Bytes is the memory the operator allocated, without its inputs.
+	Bytes int64 `json:"bytes"`
+
+	Children []*Plan `json:"children,omitempty"`
+}
+
+// String returns the plan as an indented tree, one operator per line.
+func (p *Plan) String() string {
+	var sb strings.Builder
+	p.format(&sb, 0)
+	return sb.String()
+}
+
+func (p *Plan) format(sb *strings.Builder, depth int) {
+	sb.WriteString(strings.Repeat("  ", depth))
+	sb.WriteString(p.Operator)
+	if p.Detail != "" {
+		sb.WriteString(" " + p.Detail)
+	}
+	fmt.Fprintf(sb, " (rows=%d time=%s bytes=%d)\n", p.Rows, p.Duration, p.Bytes)
+	for _, child := range p.Children {
+		child.format(sb, depth+1)
+	}
+}
+
+// Explain runs a query like Query and returns, along with the result, the
+// plan the query was executed with and the metrics of its operators.
+func Explain(ctx context.Context, query string, tables map[string]*dataframe.DataFrame, opts ...Option) (*dataframe.DataFrame, *Plan, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	stmt, err := parse(query, cfg.args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e, err := newExecutor(ctx, cfg, tables, stmt)
+	if err != nil {
+		return nil, nil, err
+	}
+	e.mem = &meter{mem: e.mem}
+	e.plan = &explainer{}
+	df, err := e.run(stmt)
+	if err != nil {
+		return nil, nil, err
+	}
+	return df, e.plan.last, nil
+}
+
+// explainer collects the operators run by an executor. The operators run one
+// after the other, each one taking the result of the previous as input.
+type explainer struct {
+	last *Plan
+}
+
+// step is an operator being run.
+type step struct {
+	node  *Plan
+	start time.Time
+	bytes int64
+}
+
+// begin starts timing an operator. It returns nil when the executor does not
+// explain its query.
+func (e *executor) begin(op, detail string) *step {
+	if e.plan == nil {
+		return nil
+	}
+	return &step{
+		node:  &Plan{Operator: op, Detail: detail},
+		start: time.Now(),
+		bytes: e.mem.(*meter).allocated(),
+	}
+}
+
+// end records the operator of s, which produced rows rows from the previous
+// operator and the other inputs.
+func (e *executor) end(s *step, rows int64, inputs ...*Plan) {
+	if s == nil {
+		return
+	}
+	n := s.node
+	n.Rows = rows
+	n.Duration = time.Since(s.start)
+	n.Bytes = e.mem.(*meter).allocated() - s.bytes
+	if e.plan.last != nil {
+		n.Children = append(n.Children, e.plan.last)
+	}
+	n.Children = append(n.Children, inputs...)
+	e.plan.last = n
+}
+
+// describeAggregate describes the group keys and the aggregates collected by b.
+func describeAggregate(groupBy []node, b *binder) string {
+	parts := make([]string, 0, len(groupBy)+len(b.aggOrder))
+	for _, n := range groupBy {
+		parts = append(parts, n.String())
+	}
+	keys := strings.Join(parts, ", ")
+	aggs := strings.Join(b.aggOrder, ", ")
+	switch {
+	case keys == "":
+		return aggs
+	case aggs == "":
+		return "by " + keys
+	default:
+		return "by " + keys + ": " + aggs
+	}
+}
+
+func describeOutputs(outputs []output) string {
+	names := make([]string, len(outputs))
+	for i, out := range outputs {
+		names[i] = out.name
+	}
+	return strings.Join(names, ", ")
+}
+
+func describeSort(stmt *selectStmt) string {
+	parts := make([]string, 0, len(stmt.orderBy)+2)
+	for _, item := range stmt.orderBy {
+		if item.desc {
+			parts = append(parts, item.expr.String()+" DESC")
+		} else {
+			parts = append(parts, item.expr.String())
+		}
+	}
+	if stmt.limit >= 0 {
+		parts = append(parts, fmt.Sprintf("LIMIT %d", stmt.limit))
+	}
+	if stmt.offset > 0 {
+		parts = append(parts, fmt.Sprintf("OFFSET %d", stmt.offset))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// meter is an allocator counting the bytes allocated through it.
+type meter struct {
+	mem   memory.Allocator
+	bytes int64
+}
+
+func (m *meter) Allocate(size int) []byte {
+	atomic.AddInt64(&m.bytes, int64(size))
+	return m.mem.Allocate(size)
+}
+
+func (m *meter) Reallocate(size int, b []byte) []byte {
+	if grow := size - len(b); grow > 0 {
+		atomic.AddInt64(&m.bytes, int64(grow))
+	}
+	return m.mem.Reallocate(size, b)
+}
+
+func (m *meter) Free(b []byte) { m.mem.Free(b) }
+
+func (m *meter) allocated() int64 { return atomic.LoadInt64(&m.bytes) }
@@ -0,0 +1,382 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomemsql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/dataframe"
+	"github.com/gomem/gomem/pkg/expr"
+	"github.com/gomem/gomem/pkg/object"
+	"github.com/gomem/gomem/pkg/scalar"
+)
+
+// format returns the names and values of the columns of df, one column per line.
+func format(df *dataframe.DataFrame) string {
+	var sb strings.Builder
+	for _, col := range df.Columns() {
+		fmt.Fprintf(&sb, "%s:", col.Name())
+		for _, chunk := range col.Data().Chunks() {
+			fmt.Fprintf(&sb, " %v", chunk)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func TestQuery(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	sales, err := dataframe.NewDataFrameFromMem(pool, dataframe.Dict{
+		"region": []string{"east", "west", "east", "north", "west", "east"},
+		"units":  []int64{3, 5, 2, 7, 1, 4},
+		"price":  []float64{1.5, 2, 2.5, 1, 4, 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sales.Release()
+
+	regions, err := dataframe.NewDataFrameFromMem(pool, dataframe.Dict{
+		"region":  []string{"east", "west"},
+		"manager": []string{"ann", "bob"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer regions.Release()
+
+	tables := map[string]*dataframe.DataFrame{"sales": sales, "regions": regions}
+
+	for _, tc := range []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "where",
+			query: "select region, units * 2 as double from sales where units >= 3 and region <> 'north'",
+			want:  "region: [\"east\" \"west\" \"east\"]\ndouble: [6 10 8]\n",
+		},
+		{
+			name:  "group by",
+			query: "select region, sum(units) as units, count(*) as n from sales group by region order by units desc",
+			want:  "region: [\"east\" \"north\" \"west\"]\nunits: [9 7 6]\nn: [3 1 2]\n",
+		},
+		{
+			name:  "having",
+			query: "SELECT region, avg(price) FROM sales GROUP BY region HAVING count(*) > 1 ORDER BY 1",
+			want:  "region: [\"east\" \"west\"]\navg(price): [2.3333333333333335 3]\n",
+		},
+		{
+			name:  "aggregate without group by",
+			query: "select min(units), max(price), count(units) from sales",
+			want:  "min(units): [1]\nmax(price): [4]\ncount(units): [6]\n",
+		},
+		{
+			name:  "join",
+			query: "select s.region, r.manager, s.units from sales s join regions r on s.region = r.region order by units limit 3",
+			want:  "region: [\"west\" \"east\" \"east\"]\nmanager: [\"bob\" \"ann\" \"ann\"]\nunits: [1 2 3]\n",
+		},
+		{
+			name:  "left join",
+			query: "select distinct s.region, manager from sales s left join regions r on r.region = s.region order by region",
+			want:  "region: [\"east\" \"north\" \"west\"]\nmanager: [\"ann\" (null) \"bob\"]\n",
+		},
+		{
+			name:  "star",
+			query: "select * from sales s join regions r on s.region = r.region where manager = 'bob'",
+			want:  "price: [2 4]\ns.region: [\"west\" \"west\"]\nunits: [5 1]\nmanager: [\"bob\" \"bob\"]\nr.region: [\"west\" \"west\"]\n",
+		},
+		{
+			name:  "limit offset",
+			query: "select units from sales order by price desc, units limit 2 offset 1",
+			want:  "units: [4 2]\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			df, err := Query(context.Background(), tc.query, tables)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer df.Release()
+
+			if got := format(df); got != tc.want {
+				t.Fatalf("invalid result:\ngot:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestQueryUserFunctions(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	sales, err := dataframe.NewDataFrameFromMem(pool, dataframe.Dict{
+		"region": []string{"east", "west", "east", "west"},
+		"units":  []int64{3, 5, 2, 7},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sales.Release()
+
+	if err := expr.Register(expr.Function{
+		Name:   "square",
+		Args:   []arrow.DataType{arrow.PrimitiveTypes.Int64},
+		Result: arrow.PrimitiveTypes.Int64,
+		Row: func(args []scalar.Scalar) (scalar.Scalar, error) {
+			v := args[0].(scalar.Int64).Value
+			return scalar.NewInt64(v * v), nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	defer expr.DefaultRegistry.Unregister("square")
+	if err := expr.Register(expr.Function{
+		Name:   "spread",
+		Kind:   expr.Aggregate,
+		Args:   []arrow.DataType{arrow.PrimitiveTypes.Int64},
+		Result: arrow.PrimitiveTypes.Int64,
+		Reduce: func(mem memory.Allocator, args []*array.Column) (scalar.Scalar, error) {
+			min, max, err := compute.MinMax(args[0])
+			if err != nil {
+				return nil, err
+			}
+			lo, _ := object.CastToInt64(min)
+			hi, _ := object.CastToInt64(max)
+			return scalar.NewInt64(hi.Value() - lo.Value()), nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	defer expr.DefaultRegistry.Unregister("spread")
+
+	tables := map[string]*dataframe.DataFrame{"sales": sales}
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{"select SQUARE(units) as sq from sales", "sq: [9 25 4 49]\n"},
+		{"select region, spread(units) as s from sales group by region order by region", "region: [\"east\" \"west\"]\ns: [1 2]\n"},
+		{"select spread(square(units)) as s from sales", "s: [45]\n"},
+	} {
+		df, err := Query(context.Background(), tc.query, tables)
+		if err != nil {
+			t.Errorf("%s: %v", tc.query, err)
+			continue
+		}
+		if got := format(df); got != tc.want {
+			t.Errorf("%s:\ngot:\n%s\nwant:\n%s", tc.query, got, tc.want)
+		}
+		df.Release()
+	}
+
+	for _, query := range []string{
+		"select square(units, units) from sales",
+		"select square(region) from sales",
+		"select spread(spread(units)) from sales",
+	} {
+		if df, err := Query(context.Background(), query, tables); err == nil {
+			df.Release()
+			t.Errorf("%s: expected an error", query)
+		}
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := dataframe.NewDataFrameFromMem(pool, dataframe.Dict{
+		"a": []int64{1, 2, 3},
+		"b": []string{"x", "y", "x"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	tables := map[string]*dataframe.DataFrame{"t": df}
+
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{"select a from missing", `gomemsql: table "missing" does not exist`},
+		{"select c from t", `gomemsql: column "c" does not exist`},
+		{"select a, count(*) from t group by b", `gomemsql: column "a" must appear in the GROUP BY clause or be used in an aggregate function`},
+		{"select a from t where sum(a) > 1", "gomemsql: aggregate function sum is not allowed here"},
+		{"select a from t where a", "gomemsql: condition must be boolean"},
+		{"select a, a from t", `gomemsql: column "a" is returned more than once, use an alias`},
+		{"select a from t order by 2", "gomemsql: ORDER BY position 2 is not in the select list"},
+		{"select a from", "gomemsql:"},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			_, err := Query(context.Background(), tc.query, tables)
+			if err == nil || !strings.HasPrefix(err.Error(), tc.want) {
+				t.Fatalf("invalid error: got=%v, want=%q", err, tc.want)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Query(ctx, "select a from t", tables); err != context.Canceled {
+		t.Fatalf("invalid error: got=%v, want=%v", err, context.Canceled)
+	}
+}
+
+func TestDriver(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := dataframe.NewDataFrameFromMem(pool, dataframe.Dict{
+		"name":  []string{"a", "b", "c", "d"},
+		"score": []float64{1.5, 3, 2, 4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	Register("scores", df)
+	df.Release()
+	defer Unregister("scores")
+
+	db, err := sql.Open(DriverName, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("select name, score from scores where score > ? order by score desc", 1.8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var got []string
+	for rows.Next() {
+		var name string
+		var score float64
+		if err := rows.Scan(&name, &score); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, fmt.Sprintf("%s=%v", name, score))
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"d=4", "b=3", "c=2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid rows: got=%v, want=%v", got, want)
+	}
+
+	var n int64
+	if err := db.QueryRow("select count(*) from scores where name <> ?", "a").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Fatalf("invalid count: got=%d, want=3", n)
+	}
+
+	if _, err := db.Exec("select 1 from scores"); err == nil {
+		t.Fatal("expected an error executing a statement on a read-only database")
+	}
+	if _, err := db.Query("select name from scores where score > ?"); err == nil {
+		t.Fatal("expected an error for a missing argument")
+	}
+}
+
+func TestExplain(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	sales, err := dataframe.NewDataFrameFromMem(pool, dataframe.Dict{
+		"region": []string{"east", "west", "east", "north", "west", "east"},
+		"units":  []int64{3, 5, 2, 7, 1, 4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sales.Release()
+	regions, err := dataframe.NewDataFrameFromMem(pool, dataframe.Dict{
+		"region":  []string{"east", "west"},
+		"manager": []string{"ann", "bob"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer regions.Release()
+
+	df, plan, err := Explain(context.Background(),
+		"select manager, sum(units) as units from sales s join regions r on s.region = r.region where units > 1 group by manager order by units desc limit 1",
+		map[string]*dataframe.DataFrame{"sales": sales, "regions": regions})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+	if got, want := format(df), "manager: [\"ann\"]\nunits: [9]\n"; got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The tree without the metrics that vary from run to run.
+	var shape func(p *Plan, depth int) string
+	shape = func(p *Plan, depth int) string {
+		s := fmt.Sprintf("%s%s %s rows=%d\n", strings.Repeat("  ", depth), p.Operator, p.Detail, p.Rows)
+		for _, child := range p.Children {
+			s += shape(child, depth+1)
+		}
+		return s
+	}
+	want := `sort units DESC, LIMIT 1 rows=1
+  project manager, units rows=2
+    aggregate by manager: sum(units) rows=2
+      filter units > 1 rows=4
+        join s.region = r.region rows=5
+          scan sales rows=6
+          scan regions rows=2
+`
+	if got := shape(plan, 0); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if agg := plan.Children[0].Children[0]; agg.Bytes <= 0 {
+		t.Errorf("aggregate allocated %d bytes", agg.Bytes)
+	}
+	if !strings.Contains(plan.String(), "scan regions (rows=2 time=") {
+		t.Errorf("unexpected plan:\n%s", plan)
+	}
+
+	buf, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Plan
+	if err := json.Unmarshal(buf, &back); err != nil {
+		t.Fatal(err)
+	}
+	if got := shape(&back, 0); got != want {
+		t.Fatalf("JSON round trip:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
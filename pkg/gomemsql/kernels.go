@@ -0,0 +1,602 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomemsql
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/expr"
+)
+
+// kind is the class of values an expression operates on.
+type kind int
+
+const (
+	kindNull kind = iota
+	kindInt
+	kindFloat
+	kindString
+	kindBool
+	kindOther
+)
+
+func (k kind) String() string {
+	switch k {
+	case kindNull:
+		return "null"
+	case kindInt:
+		return "integer"
+	case kindFloat:
+		return "floating point"
+	case kindString:
+		return "string"
+	case kindBool:
+		return "boolean"
+	default:
+		return "unsupported"
+	}
+}
+
+// kindOf returns the kind of the values of dtype. Dictionary encoded values
+// have the kind of their dictionary, and temporal values are integers.
+func kindOf(dtype arrow.DataType) kind {
+	switch dtype.ID() {
+	case arrow.NULL:
+		return kindNull
+	case arrow.INT8, arrow.INT16, arrow.INT32, arrow.INT64,
+		arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64,
+		arrow.DATE32, arrow.DATE64, arrow.TIMESTAMP, arrow.TIME32, arrow.TIME64, arrow.DURATION:
+		return kindInt
+	case arrow.FLOAT16, arrow.FLOAT32, arrow.FLOAT64:
+		return kindFloat
+	case arrow.STRING, arrow.BINARY:
+		return kindString
+	case arrow.BOOL:
+		return kindBool
+	case arrow.DICTIONARY:
+		return kindOf(dtype.(*arrow.DictionaryType).ValueType)
+	default:
+		return kindOther
+	}
+}
+
+// cursor walks the rows of a chunked column in order.
+type cursor struct {
+	chunks []array.Interface
+	chunk  int
+	index  int
+}
+
+func newCursor(col *array.Column) *cursor {
+	return &cursor{chunks: col.Data().Chunks()}
+}
+
+// next returns the array holding the next row and the index of the row in it.
+// Dictionary encoded rows are returned as the dictionary value they reference.
+func (c *cursor) next() (array.Interface, int) {
+	for c.index >= c.chunks[c.chunk].Len() {
+		c.chunk++
+		c.index = 0
+	}
+	arr, i := c.chunks[c.chunk], c.index
+	c.index++
+	if d, ok := arr.(*array.Dictionary); ok && d.IsValid(i) {
+		return d.Dictionary(), d.GetValueIndex(i)
+	}
+	return arr, i
+}
+
+func intValue(arr array.Interface, i int) int64 {
+	switch a := arr.(type) {
+	case *array.Int8:
+		return int64(a.Value(i))
+	case *array.Int16:
+		return int64(a.Value(i))
+	case *array.Int32:
+		return int64(a.Value(i))
+	case *array.Int64:
+		return a.Value(i)
+	case *array.Uint8:
+		return int64(a.Value(i))
+	case *array.Uint16:
+		return int64(a.Value(i))
+	case *array.Uint32:
+		return int64(a.Value(i))
+	case *array.Uint64:
+		return int64(a.Value(i))
+	case *array.Date32:
+		return int64(a.Value(i))
+	case *array.Date64:
+		return int64(a.Value(i))
+	case *array.Timestamp:
+		return int64(a.Value(i))
+	case *array.Time32:
+		return int64(a.Value(i))
+	case *array.Time64:
+		return int64(a.Value(i))
+	case *array.Duration:
+		return int64(a.Value(i))
+	default:
+		panic(fmt.Errorf("gomemsql: %T is not an integer array", arr))
+	}
+}
+
+func floatValue(arr array.Interface, i int) float64 {
+	switch a := arr.(type) {
+	case *array.Float16:
+		return float64(a.Value(i).Float32())
+	case *array.Float32:
+		return float64(a.Value(i))
+	case *array.Float64:
+		return a.Value(i)
+	default:
+		return float64(intValue(arr, i))
+	}
+}
+
+func stringValue(arr array.Interface, i int) string {
+	switch a := arr.(type) {
+	case *array.String:
+		return a.Value(i)
+	case *array.Binary:
+		return string(a.Value(i))
+	default:
+		panic(fmt.Errorf("gomemsql: %T is not a string array", arr))
+	}
+}
+
+// newResult wraps arr in a single chunk nullable Column named name and releases arr.
+func newResult(name string, arr array.Interface) *array.Column {
+	defer arr.Release()
+	chunked := array.NewChunked(arr.DataType(), []array.Interface{arr})
+	defer chunked.Release()
+	return array.NewColumn(arrow.Field{Name: name, Type: arr.DataType(), Nullable: true}, chunked)
+}
+
+// literal is an expression evaluating to a constant column.
+type literal struct {
+	node *literalNode
+}
+
+func (l literal) Eval(mem memory.Allocator, src expr.Source) (*array.Column, error) {
+	n := int(src.NumRows())
+	var arr array.Interface
+	switch v := l.node.value.(type) {
+	case nil:
+		arr = array.NewNull(n)
+	case bool:
+		bldr := array.NewBooleanBuilder(mem)
+		defer bldr.Release()
+		for i := 0; i < n; i++ {
+			bldr.Append(v)
+		}
+		arr = bldr.NewArray()
+	case int64:
+		bldr := array.NewInt64Builder(mem)
+		defer bldr.Release()
+		for i := 0; i < n; i++ {
+			bldr.Append(v)
+		}
+		arr = bldr.NewArray()
+	case float64:
+		bldr := array.NewFloat64Builder(mem)
+		defer bldr.Release()
+		for i := 0; i < n; i++ {
+			bldr.Append(v)
+		}
+		arr = bldr.NewArray()
+	case string:
+		bldr := array.NewStringBuilder(mem)
+		defer bldr.Release()
+		for i := 0; i < n; i++ {
+			bldr.Append(v)
+		}
+		arr = bldr.NewArray()
+	}
+	return newResult(l.String(), arr), nil
+}
+
+func (l literal) String() string { return l.node.String() }
+
+// arithmetic returns the kernel of a binary arithmetic operator. Integers
+// give integers, truncating divisions, and any floating point operand gives
+// floating point numbers. Dividing by zero gives null.
+func arithmetic(op string) expr.KernelFunc {
+	return func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		l, r := args[0], args[1]
+		lk, rk := kindOf(l.DataType()), kindOf(r.DataType())
+		for _, k := range []kind{lk, rk} {
+			if k != kindInt && k != kindFloat && k != kindNull {
+				return nil, fmt.Errorf("gomemsql: operator %s does not apply to %v values", op, k)
+			}
+		}
+
+		lc, rc := newCursor(l), newCursor(r)
+		n := l.Len()
+		if lk == kindFloat || rk == kindFloat {
+			bldr := array.NewFloat64Builder(mem)
+			defer bldr.Release()
+			for i := 0; i < n; i++ {
+				la, li := lc.next()
+				ra, ri := rc.next()
+				if la.IsNull(li) || ra.IsNull(ri) {
+					bldr.AppendNull()
+					continue
+				}
+				a, b := floatValue(la, li), floatValue(ra, ri)
+				switch op {
+				case "+":
+					bldr.Append(a + b)
+				case "-":
+					bldr.Append(a - b)
+				case "*":
+					bldr.Append(a * b)
+				case "/":
+					if b == 0 {
+						bldr.AppendNull()
+						continue
+					}
+					bldr.Append(a / b)
+				case "%":
+					if b == 0 {
+						bldr.AppendNull()
+						continue
+					}
+					bldr.Append(math.Mod(a, b))
+				}
+			}
+			return newResult(op, bldr.NewArray()), nil
+		}
+
+		bldr := array.NewInt64Builder(mem)
+		defer bldr.Release()
+		for i := 0; i < n; i++ {
+			la, li := lc.next()
+			ra, ri := rc.next()
+			if la.IsNull(li) || ra.IsNull(ri) {
+				bldr.AppendNull()
+				continue
+			}
+			a, b := intValue(la, li), intValue(ra, ri)
+			switch op {
+			case "+":
+				bldr.Append(a + b)
+			case "-":
+				bldr.Append(a - b)
+			case "*":
+				bldr.Append(a * b)
+			case "/", "%":
+				if b == 0 {
+					bldr.AppendNull()
+					continue
+				}
+				if op == "/" {
+					bldr.Append(a / b)
+				} else {
+					bldr.Append(a % b)
+				}
+			}
+		}
+		return newResult(op, bldr.NewArray()), nil
+	}
+}
+
+// negate is the kernel of the unary minus.
+func negate(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+	col := args[0]
+	switch k := kindOf(col.DataType()); k {
+	case kindInt, kindFloat, kindNull:
+	default:
+		return nil, fmt.Errorf("gomemsql: operator - does not apply to %v values", k)
+	}
+
+	c := newCursor(col)
+	if kindOf(col.DataType()) == kindFloat {
+		bldr := array.NewFloat64Builder(mem)
+		defer bldr.Release()
+		for i := 0; i < col.Len(); i++ {
+			arr, j := c.next()
+			if arr.IsNull(j) {
+				bldr.AppendNull()
+				continue
+			}
+			bldr.Append(-floatValue(arr, j))
+		}
+		return newResult("-", bldr.NewArray()), nil
+	}
+
+	bldr := array.NewInt64Builder(mem)
+	defer bldr.Release()
+	for i := 0; i < col.Len(); i++ {
+		arr, j := c.next()
+		if arr.IsNull(j) {
+			bldr.AppendNull()
+			continue
+		}
+		bldr.Append(-intValue(arr, j))
+	}
+	return newResult("-", bldr.NewArray()), nil
+}
+
+// comparison returns the kernel of a comparison operator. Numbers compare to
+// numbers, strings to strings and booleans to booleans. Comparing with null
+// gives null.
+func comparison(op string) expr.KernelFunc {
+	return func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		l, r := args[0], args[1]
+		lk, rk := kindOf(l.DataType()), kindOf(r.DataType())
+
+		var cmp func(la array.Interface, li int, ra array.Interface, ri int) int
+		switch {
+		case lk == kindNull || rk == kindNull:
+			cmp = func(array.Interface, int, array.Interface, int) int { return 0 }
+		case lk == kindInt && rk == kindInt:
+			cmp = func(la array.Interface, li int, ra array.Interface, ri int) int {
+				return compareInts(intValue(la, li), intValue(ra, ri))
+			}
+		case (lk == kindInt || lk == kindFloat) && (rk == kindInt || rk == kindFloat):
+			cmp = func(la array.Interface, li int, ra array.Interface, ri int) int {
+				return compareFloats(floatValue(la, li), floatValue(ra, ri))
+			}
+		case lk == kindString && rk == kindString:
+			cmp = func(la array.Interface, li int, ra array.Interface, ri int) int {
+				return strings.Compare(stringValue(la, li), stringValue(ra, ri))
+			}
+		case lk == kindBool && rk == kindBool:
+			cmp = func(la array.Interface, li int, ra array.Interface, ri int) int {
+				a, b := la.(*array.Boolean).Value(li), ra.(*array.Boolean).Value(ri)
+				switch {
+				case a == b:
+					return 0
+				case !a:
+					return -1
+				default:
+					return 1
+				}
+			}
+		default:
+			return nil, fmt.Errorf("gomemsql: cannot compare %v and %v values", lk, rk)
+		}
+
+		lc, rc := newCursor(l), newCursor(r)
+		bldr := array.NewBooleanBuilder(mem)
+		defer bldr.Release()
+		for i := 0; i < l.Len(); i++ {
+			la, li := lc.next()
+			ra, ri := rc.next()
+			if la.IsNull(li) || ra.IsNull(ri) {
+				bldr.AppendNull()
+				continue
+			}
+			c := cmp(la, li, ra, ri)
+			switch op {
+			case "=":
+				bldr.Append(c == 0)
+			case "<>":
+				bldr.Append(c != 0)
+			case "<":
+				bldr.Append(c < 0)
+			case "<=":
+				bldr.Append(c <= 0)
+			case ">":
+				bldr.Append(c > 0)
+			case ">=":
+				bldr.Append(c >= 0)
+			}
+		}
+		return newResult(op, bldr.NewArray()), nil
+	}
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// truth returns the boolean value of row i of arr and whether it is known.
+func truth(arr array.Interface, i int) (value, known bool) {
+	if arr.IsNull(i) {
+		return false, false
+	}
+	return arr.(*array.Boolean).Value(i), true
+}
+
+func checkBooleans(op string, args []*array.Column) error {
+	for _, arg := range args {
+		if k := kindOf(arg.DataType()); k != kindBool && k != kindNull {
+			return fmt.Errorf("gomemsql: operator %s does not apply to %v values", op, k)
+		}
+	}
+	return nil
+}
+
+// logical returns the kernel of AND or OR, following three-valued logic.
+func logical(op string) expr.KernelFunc {
+	return func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		if err := checkBooleans(op, args); err != nil {
+			return nil, err
+		}
+
+		lc, rc := newCursor(args[0]), newCursor(args[1])
+		bldr := array.NewBooleanBuilder(mem)
+		defer bldr.Release()
+		for i := 0; i < args[0].Len(); i++ {
+			a, aKnown := truth(lc.next())
+			b, bKnown := truth(rc.next())
+			switch {
+			case op == "AND" && ((aKnown && !a) || (bKnown && !b)):
+				bldr.Append(false)
+			case op == "OR" && ((aKnown && a) || (bKnown && b)):
+				bldr.Append(true)
+			case !aKnown || !bKnown:
+				bldr.AppendNull()
+			case op == "AND":
+				bldr.Append(a && b)
+			default:
+				bldr.Append(a || b)
+			}
+		}
+		return newResult(op, bldr.NewArray()), nil
+	}
+}
+
+// not is the kernel of NOT.
+func not(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+	if err := checkBooleans("NOT", args); err != nil {
+		return nil, err
+	}
+
+	c := newCursor(args[0])
+	bldr := array.NewBooleanBuilder(mem)
+	defer bldr.Release()
+	for i := 0; i < args[0].Len(); i++ {
+		v, known := truth(c.next())
+		if !known {
+			bldr.AppendNull()
+			continue
+		}
+		bldr.Append(!v)
+	}
+	return newResult("NOT", bldr.NewArray()), nil
+}
+
+// isNull returns the kernel of IS NULL, or IS NOT NULL when negated.
+func isNull(negated bool) expr.KernelFunc {
+	return func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		c := newCursor(args[0])
+		bldr := array.NewBooleanBuilder(mem)
+		defer bldr.Release()
+		for i := 0; i < args[0].Len(); i++ {
+			arr, j := c.next()
+			bldr.Append(arr.IsNull(j) != negated)
+		}
+		return newResult("IS NULL", bldr.NewArray()), nil
+	}
+}
+
+// scalarFuncs are the functions computing a value per row.
+var scalarFuncs = map[string]struct {
+	args int
+	fn   expr.KernelFunc
+}{
+	"abs":    {args: 1, fn: abs},
+	"lower":  {args: 1, fn: mapStrings("lower", strings.ToLower)},
+	"upper":  {args: 1, fn: mapStrings("upper", strings.ToUpper)},
+	"length": {args: 1, fn: length},
+}
+
+func abs(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+	col := args[0]
+	switch k := kindOf(col.DataType()); k {
+	case kindInt, kindFloat, kindNull:
+	default:
+		return nil, fmt.Errorf("gomemsql: abs does not apply to %v values", k)
+	}
+
+	c := newCursor(col)
+	if kindOf(col.DataType()) == kindFloat {
+		bldr := array.NewFloat64Builder(mem)
+		defer bldr.Release()
+		for i := 0; i < col.Len(); i++ {
+			arr, j := c.next()
+			if arr.IsNull(j) {
+				bldr.AppendNull()
+				continue
+			}
+			bldr.Append(math.Abs(floatValue(arr, j)))
+		}
+		return newResult("abs", bldr.NewArray()), nil
+	}
+
+	bldr := array.NewInt64Builder(mem)
+	defer bldr.Release()
+	for i := 0; i < col.Len(); i++ {
+		arr, j := c.next()
+		if arr.IsNull(j) {
+			bldr.AppendNull()
+			continue
+		}
+		v := intValue(arr, j)
+		if v < 0 {
+			v = -v
+		}
+		bldr.Append(v)
+	}
+	return newResult("abs", bldr.NewArray()), nil
+}
+
+func mapStrings(name string, fn func(string) string) expr.KernelFunc {
+	return func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		col := args[0]
+		if k := kindOf(col.DataType()); k != kindString && k != kindNull {
+			return nil, fmt.Errorf("gomemsql: %s does not apply to %v values", name, k)
+		}
+
+		c := newCursor(col)
+		bldr := array.NewStringBuilder(mem)
+		defer bldr.Release()
+		for i := 0; i < col.Len(); i++ {
+			arr, j := c.next()
+			if arr.IsNull(j) {
+				bldr.AppendNull()
+				continue
+			}
+			bldr.Append(fn(stringValue(arr, j)))
+		}
+		return newResult(name, bldr.NewArray()), nil
+	}
+}
+
+func length(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+	col := args[0]
+	if k := kindOf(col.DataType()); k != kindString && k != kindNull {
+		return nil, fmt.Errorf("gomemsql: length does not apply to %v values", k)
+	}
+
+	c := newCursor(col)
+	bldr := array.NewInt64Builder(mem)
+	defer bldr.Release()
+	for i := 0; i < col.Len(); i++ {
+		arr, j := c.next()
+		if arr.IsNull(j) {
+			bldr.AppendNull()
+			continue
+		}
+		bldr.Append(int64(len([]rune(stringValue(arr, j)))))
+	}
+	return newResult("length", bldr.NewArray()), nil
+}
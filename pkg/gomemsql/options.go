@@ -0,0 +1,64 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomemsql
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// Option is an option that may be passed to Query.
+type Option func(interface{}) error
+
+type config struct {
+	mem  memory.Allocator
+	args []interface{}
+}
+
+func newConfig(opts ...Option) (*config, error) {
+	cfg := &config{}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// WithAllocator specifies the allocator used to build the result, the
+// allocator of the table of the FROM clause by default.
+func WithAllocator(mem memory.Allocator) Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply WithAllocator to: %T", p)
+		}
+		cfg.mem = mem
+		return nil
+	}
+}
+
+// WithArgs specifies the values of the ? placeholders of the query, in order.
+func WithArgs(args ...interface{}) Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply WithArgs to: %T", p)
+		}
+		cfg.args = args
+		return nil
+	}
+}
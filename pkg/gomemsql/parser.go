@@ -0,0 +1,526 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomemsql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parser is a recursive descent parser of SELECT statements.
+type parser struct {
+	tokens []token
+	pos    int
+	// args are the values of the ? placeholders, used is how many were parsed.
+	args []interface{}
+	used int
+}
+
+// parse parses a single SELECT statement, replacing its placeholders with args.
+func parse(query string, args ...interface{}) (*selectStmt, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, args: args}
+	stmt, err := p.parseSelect()
+	if err != nil {
+		return nil, err
+	}
+	p.acceptSymbol(";")
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected %v", tok)
+	}
+	if p.used != len(args) {
+		return nil, fmt.Errorf("gomemsql: query has %d placeholders, got %d arguments", p.used, len(args))
+	}
+	return stmt, nil
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return fmt.Errorf("gomemsql: offset %d: %s", tok.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) isKeyword(kw string) bool {
+	tok := p.peek()
+	return tok.kind == tokKeyword && tok.text == kw
+}
+
+func (p *parser) acceptKeyword(kw string) bool {
+	if p.isKeyword(kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(kw string) error {
+	if !p.acceptKeyword(kw) {
+		return p.errorf(p.peek(), "expected %s, got %v", kw, p.peek())
+	}
+	return nil
+}
+
+func (p *parser) isSymbol(sym string) bool {
+	tok := p.peek()
+	return tok.kind == tokSymbol && tok.text == sym
+}
+
+func (p *parser) acceptSymbol(sym string) bool {
+	if p.isSymbol(sym) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectSymbol(sym string) error {
+	if !p.acceptSymbol(sym) {
+		return p.errorf(p.peek(), "expected %q, got %v", sym, p.peek())
+	}
+	return nil
+}
+
+func (p *parser) expectIdent() (string, error) {
+	tok := p.next()
+	if tok.kind != tokIdent {
+		return "", p.errorf(tok, "expected an identifier, got %v", tok)
+	}
+	return tok.text, nil
+}
+
+func (p *parser) parseSelect() (*selectStmt, error) {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	stmt := &selectStmt{limit: -1}
+	stmt.distinct = p.acceptKeyword("DISTINCT")
+
+	for {
+		item, err := p.parseSelectItem()
+		if err != nil {
+			return nil, err
+		}
+		stmt.items = append(stmt.items, item)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	from, err := p.parseTableRef()
+	if err != nil {
+		return nil, err
+	}
+	stmt.from = from
+
+	for {
+		left, ok, err := p.parseJoinKind()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		join := joinClause{left: left}
+		if join.table, err = p.parseTableRef(); err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("ON"); err != nil {
+			return nil, err
+		}
+		if join.on, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+		stmt.joins = append(stmt.joins, join)
+	}
+
+	if p.acceptKeyword("WHERE") {
+		if stmt.where, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("GROUP") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		if stmt.groupBy, err = p.parseExprList(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("HAVING") {
+		if stmt.having, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			item := orderItem{expr: e}
+			if p.acceptKeyword("DESC") {
+				item.desc = true
+			} else {
+				p.acceptKeyword("ASC")
+			}
+			stmt.orderBy = append(stmt.orderBy, item)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+	if p.acceptKeyword("LIMIT") {
+		if stmt.limit, err = p.parseCount("LIMIT"); err != nil {
+			return nil, err
+		}
+		if p.acceptKeyword("OFFSET") {
+			if stmt.offset, err = p.parseCount("OFFSET"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return stmt, nil
+}
+
+// parseJoinKind parses the keywords starting a join, reporting whether there
+// is one and whether it is a left join.
+func (p *parser) parseJoinKind() (left, ok bool, err error) {
+	switch {
+	case p.acceptKeyword("JOIN"):
+		return false, true, nil
+	case p.acceptKeyword("INNER"):
+		return false, true, p.expectKeyword("JOIN")
+	case p.acceptKeyword("LEFT"):
+		p.acceptKeyword("OUTER")
+		return true, true, p.expectKeyword("JOIN")
+	default:
+		return false, false, nil
+	}
+}
+
+func (p *parser) parseSelectItem() (selectItem, error) {
+	if p.acceptSymbol("*") {
+		return selectItem{star: true}, nil
+	}
+	// table.*
+	if p.peek().kind == tokIdent && p.pos+2 < len(p.tokens) &&
+		p.tokens[p.pos+1].kind == tokSymbol && p.tokens[p.pos+1].text == "." &&
+		p.tokens[p.pos+2].kind == tokSymbol && p.tokens[p.pos+2].text == "*" {
+		qualifier := p.next().text
+		p.pos += 2
+		return selectItem{star: true, qualifier: qualifier}, nil
+	}
+
+	e, err := p.parseExpr()
+	if err != nil {
+		return selectItem{}, err
+	}
+	item := selectItem{expr: e}
+	if p.acceptKeyword("AS") {
+		if item.alias, err = p.expectIdent(); err != nil {
+			return selectItem{}, err
+		}
+	} else if p.peek().kind == tokIdent {
+		item.alias = p.next().text
+	}
+	return item, nil
+}
+
+func (p *parser) parseTableRef() (tableRef, error) {
+	name, err := p.expectIdent()
+	if err != nil {
+		return tableRef{}, err
+	}
+	ref := tableRef{name: name}
+	if p.acceptKeyword("AS") {
+		if ref.alias, err = p.expectIdent(); err != nil {
+			return tableRef{}, err
+		}
+	} else if p.peek().kind == tokIdent {
+		ref.alias = p.next().text
+	}
+	return ref, nil
+}
+
+func (p *parser) parseCount(clause string) (int64, error) {
+	tok := p.next()
+	n, err := strconv.ParseInt(tok.text, 10, 64)
+	if tok.kind != tokNumber || err != nil || n < 0 {
+		return 0, p.errorf(tok, "%s expects a non-negative integer, got %v", clause, tok)
+	}
+	return n, nil
+}
+
+func (p *parser) parseExprList() ([]node, error) {
+	var list []node
+	for {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+		if !p.acceptSymbol(",") {
+			return list, nil
+		}
+	}
+}
+
+func (p *parser) parseExpr() (node, error) { return p.parseOr() }
+
+func (p *parser) parseOr() (node, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryNode{op: "OR", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	l, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		r, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryNode{op: "AND", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.acceptKeyword("NOT") {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: "NOT", x: x}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	l, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.acceptKeyword("IS") {
+		not := p.acceptKeyword("NOT")
+		if err := p.expectKeyword("NULL"); err != nil {
+			return nil, err
+		}
+		return &isNullNode{x: l, not: not}, nil
+	}
+
+	tok := p.peek()
+	if tok.kind != tokSymbol {
+		return l, nil
+	}
+	switch tok.text {
+	case "=", "<>", "!=", "<", "<=", ">", ">=":
+		p.pos++
+		r, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		op := tok.text
+		if op == "!=" {
+			op = "<>"
+		}
+		return &binaryNode{op: op, l: l, r: r}, nil
+	}
+	return l, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	l, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.isSymbol("+") || p.isSymbol("-") {
+		op := p.next().text
+		r, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryNode{op: op, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isSymbol("*") || p.isSymbol("/") || p.isSymbol("%") {
+		op := p.next().text
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryNode{op: op, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.acceptSymbol("-") {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		// Fold negative numbers so they stay literals.
+		if lit, ok := x.(*literalNode); ok {
+			switch v := lit.value.(type) {
+			case int64:
+				return &literalNode{value: -v}, nil
+			case float64:
+				return &literalNode{value: -v}, nil
+			}
+		}
+		return &unaryNode{op: "-", x: x}, nil
+	}
+	p.acceptSymbol("+")
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		if !strings.ContainsAny(tok.text, ".eE") {
+			if v, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+				return &literalNode{value: v}, nil
+			}
+		}
+		v, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf(tok, "invalid number %v", tok)
+		}
+		return &literalNode{value: v}, nil
+
+	case tokString:
+		return &literalNode{value: tok.text}, nil
+
+	case tokKeyword:
+		switch tok.text {
+		case "NULL":
+			return &literalNode{}, nil
+		case "TRUE":
+			return &literalNode{value: true}, nil
+		case "FALSE":
+			return &literalNode{value: false}, nil
+		}
+
+	case tokSymbol:
+		if tok.text == "?" {
+			return p.parsePlaceholder(tok)
+		}
+		if tok.text == "(" {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectSymbol(")"); err != nil {
+				return nil, err
+			}
+			return e, nil
+		}
+
+	case tokIdent:
+		if p.acceptSymbol("(") {
+			return p.parseCall(tok.text)
+		}
+		if p.acceptSymbol(".") {
+			name, err := p.expectIdent()
+			if err != nil {
+				return nil, err
+			}
+			return &identNode{qualifier: tok.text, name: name}, nil
+		}
+		return &identNode{name: tok.text}, nil
+	}
+	return nil, p.errorf(tok, "unexpected %v", tok)
+}
+
+func (p *parser) parseCall(name string) (node, error) {
+	call := &callNode{name: strings.ToLower(name)}
+	if p.acceptSymbol("*") {
+		call.star = true
+	} else if !p.isSymbol(")") {
+		args, err := p.parseExprList()
+		if err != nil {
+			return nil, err
+		}
+		call.args = args
+	}
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
+// parsePlaceholder returns the literal of the argument of a ? placeholder.
+func (p *parser) parsePlaceholder(tok token) (node, error) {
+	if p.used >= len(p.args) {
+		return nil, p.errorf(tok, "missing argument for placeholder %d", p.used+1)
+	}
+	arg := p.args[p.used]
+	p.used++
+
+	switch v := arg.(type) {
+	case nil, bool, int64, float64, string:
+		return &literalNode{value: v}, nil
+	case int:
+		return &literalNode{value: int64(v)}, nil
+	case int32:
+		return &literalNode{value: int64(v)}, nil
+	case float32:
+		return &literalNode{value: float64(v)}, nil
+	case []byte:
+		return &literalNode{value: string(v)}, nil
+	default:
+		return nil, p.errorf(tok, "unsupported argument type %T for placeholder %d", arg, p.used)
+	}
+}
@@ -0,0 +1,673 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomemsql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/dataframe"
+	"github.com/gomem/gomem/pkg/expr"
+)
+
+// Query runs a SELECT statement over the DataFrames of tables, which the
+// statement references by their key in the map, and returns the result as a
+// new DataFrame. The tables are not modified.
+func Query(ctx context.Context, query string, tables map[string]*dataframe.DataFrame, opts ...Option) (*dataframe.DataFrame, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := parse(query, cfg.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := newExecutor(ctx, cfg, tables, stmt)
+	if err != nil {
+		return nil, err
+	}
+	return e.run(stmt)
+}
+
+type executor struct {
+	ctx    context.Context
+	mem    memory.Allocator
+	tables map[string]*dataframe.DataFrame
+	// plan collects the operators run, when the query is explained.
+	plan *explainer
+}
+
+func newExecutor(ctx context.Context, cfg *config, tables map[string]*dataframe.DataFrame, stmt *selectStmt) (*executor, error) {
+	e := &executor{ctx: ctx, mem: cfg.mem, tables: tables}
+	if e.mem == nil {
+		df, err := e.table(stmt.from.name)
+		if err != nil {
+			return nil, err
+		}
+		e.mem = df.Allocator()
+	}
+	return e, nil
+}
+
+// output is a column of the result.
+type output struct {
+	name string
+	expr expr.Expr
+	// star is set for the columns of a *, qualified is their qualified name.
+	star      bool
+	qualified string
+}
+
+// sortKey is a key of the ORDER BY clause, either an output column or an expression.
+type sortKey struct {
+	output int
+	expr   expr.Expr
+	order  compute.SortOrder
+}
+
+func (e *executor) table(name string) (*dataframe.DataFrame, error) {
+	df, ok := e.tables[name]
+	if !ok {
+		return nil, fmt.Errorf("gomemsql: table %q does not exist", name)
+	}
+	return df, nil
+}
+
+func (e *executor) run(stmt *selectStmt) (*dataframe.DataFrame, error) {
+	rel, err := e.from(stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { rel.release() }()
+
+	if stmt.where != nil {
+		st := e.begin("filter", stmt.where.String())
+		filtered, err := e.filter(rel, &binder{rel: rel}, stmt.where)
+		if err != nil {
+			return nil, err
+		}
+		rel.release()
+		rel = filtered
+		e.end(st, rel.NumRows())
+	}
+	if err := e.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	grouped := len(stmt.groupBy) > 0 || stmt.having != nil
+	for _, item := range stmt.items {
+		grouped = grouped || (!item.star && hasAggregate(item.expr))
+	}
+	b := &binder{rel: rel, grouped: grouped, keys: make(map[string]string), aggs: make(map[string]*callNode)}
+
+	// Group keys are computed first, so the expressions bound after them can match them.
+	var agg *step
+	var keys []*array.Column
+	defer func() { releaseColumns(keys) }()
+	if grouped {
+		agg = e.begin("aggregate", "")
+		if keys, err = e.groupKeys(rel, stmt.groupBy, b); err != nil {
+			return nil, err
+		}
+	}
+
+	outputs, err := e.bindOutputs(stmt, rel, b)
+	if err != nil {
+		return nil, err
+	}
+	sortKeys, err := e.bindSortKeys(stmt, outputs, b)
+	if err != nil {
+		return nil, err
+	}
+	var having expr.Expr
+	if stmt.having != nil {
+		if having, err = b.bind(stmt.having); err != nil {
+			return nil, err
+		}
+	}
+
+	src := rel
+	if grouped {
+		grel, err := e.group(rel, stmt.groupBy, keys, b)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { grel.release() }()
+		if agg != nil {
+			agg.node.Detail = describeAggregate(stmt.groupBy, b)
+		}
+		e.end(agg, grel.NumRows())
+		if having != nil {
+			st := e.begin("filter", stmt.having.String())
+			filtered, err := e.filterWith(grel, having)
+			if err != nil {
+				return nil, err
+			}
+			grel.release()
+			grel = filtered
+			e.end(st, grel.NumRows())
+		}
+		src = grel
+	}
+	if err := e.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	st := e.begin("project", describeOutputs(outputs))
+	cols := make([]*array.Column, 0, len(outputs))
+	defer func() { releaseColumns(cols) }()
+	for _, out := range outputs {
+		col, err := out.expr.Eval(e.mem, src)
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, col)
+	}
+	rows := src.NumRows()
+	e.end(st, rows)
+
+	if stmt.distinct {
+		if len(cols) > 0 {
+			st := e.begin("distinct", "")
+			distinct, n, err := e.distinct(cols)
+			if err != nil {
+				return nil, err
+			}
+			releaseColumns(cols)
+			cols, rows = distinct, n
+			e.end(st, rows)
+		}
+	}
+
+	if len(sortKeys) > 0 || stmt.limit >= 0 || stmt.offset > 0 {
+		op := "limit"
+		if len(sortKeys) > 0 {
+			op = "sort"
+		}
+		st := e.begin(op, describeSort(stmt))
+		taken, n, err := e.sortAndLimit(stmt, src, cols, rows, sortKeys)
+		if err != nil {
+			return nil, err
+		}
+		releaseColumns(cols)
+		cols, rows = taken, n
+		e.end(st, rows)
+	}
+
+	return e.newDataFrame(outputs, cols, rows)
+}
+
+// from returns the relation of the FROM clause and its joins.
+func (e *executor) from(stmt *selectStmt) (*relation, error) {
+	df, err := e.table(stmt.from.name)
+	if err != nil {
+		return nil, err
+	}
+	st := e.begin("scan", stmt.from.name)
+	rel := tableRelation(df, stmt.from.qualifier())
+	e.end(st, rel.NumRows())
+
+	for _, join := range stmt.joins {
+		if err := e.ctx.Err(); err != nil {
+			rel.release()
+			return nil, err
+		}
+		joined, err := e.join(rel, join)
+		rel.release()
+		if err != nil {
+			return nil, err
+		}
+		rel = joined
+	}
+	return rel, nil
+}
+
+// join joins the relation with the table of a join clause. The ON condition
+// must be a conjunction of equalities between a column of each side.
+func (e *executor) join(left *relation, join joinClause) (*relation, error) {
+	df, err := e.table(join.table.name)
+	if err != nil {
+		return nil, err
+	}
+	qualifier := join.table.qualifier()
+	for _, c := range left.cols {
+		if c.qualifier == qualifier {
+			return nil, fmt.Errorf("gomemsql: table name %q specified more than once", qualifier)
+		}
+	}
+	right := tableRelation(df, qualifier)
+	defer right.release()
+
+	var lkeys, rkeys []*array.Column
+	var collect func(n node) error
+	collect = func(n node) error {
+		bin, ok := n.(*binaryNode)
+		if ok && bin.op == "AND" {
+			if err := collect(bin.l); err != nil {
+				return err
+			}
+			return collect(bin.r)
+		}
+		if ok && bin.op == "=" {
+			l, lok := bin.l.(*identNode)
+			r, rok := bin.r.(*identNode)
+			if lok && rok {
+				lkey, lerr := left.resolve(l)
+				rkey, rerr := right.resolve(r)
+				if lerr != nil || rerr != nil {
+					// The operands may be written the other way around.
+					lkey, lerr = left.resolve(r)
+					rkey, rerr = right.resolve(l)
+				}
+				if lerr == nil && rerr == nil {
+					lkeys = append(lkeys, left.Column(lkey))
+					rkeys = append(rkeys, right.Column(rkey))
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("gomemsql: join condition %s must compare a column of each side with =", n.String())
+	}
+	if err := collect(join.on); err != nil {
+		return nil, err
+	}
+
+	how, op := compute.InnerJoin, "join"
+	if join.left {
+		how, op = compute.LeftJoin, "left join"
+	}
+	st := e.begin(op, join.on.String())
+	li, ri, err := compute.HashJoin(e.mem, lkeys, rkeys, how)
+	if err != nil {
+		return nil, err
+	}
+	defer li.Release()
+	defer ri.Release()
+
+	out, err := left.take(e.mem, li)
+	if err != nil {
+		return nil, err
+	}
+	rtaken, err := right.take(e.mem, ri)
+	if err != nil {
+		out.release()
+		return nil, err
+	}
+	for _, c := range rtaken.cols {
+		col := c.col
+		if join.left && !col.Field().Nullable {
+			field := col.Field()
+			field.Nullable = true
+			col = array.NewColumn(field, c.col.Data())
+			c.col.Release()
+		}
+		out.add(c.qualifier, c.name, c.key, col)
+	}
+	e.end(st, out.NumRows(), &Plan{Operator: "scan", Detail: join.table.name, Rows: right.NumRows()})
+	return out, nil
+}
+
+// filter returns the rows of rel where the condition n, bound by b, is true.
+func (e *executor) filter(rel *relation, b *binder, n node) (*relation, error) {
+	cond, err := b.bind(n)
+	if err != nil {
+		return nil, err
+	}
+	return e.filterWith(rel, cond)
+}
+
+func (e *executor) filterWith(rel *relation, cond expr.Expr) (*relation, error) {
+	mask, err := cond.Eval(e.mem, rel)
+	if err != nil {
+		return nil, err
+	}
+	defer mask.Release()
+	return rel.filter(e.mem, mask)
+}
+
+// groupKeys evaluates the expressions of the GROUP BY clause and registers
+// them as the group keys of b.
+func (e *executor) groupKeys(rel *relation, groupBy []node, b *binder) ([]*array.Column, error) {
+	pre := &binder{rel: rel}
+	keys := make([]*array.Column, 0, len(groupBy))
+	for _, n := range groupBy {
+		x, err := pre.bind(n)
+		if err != nil {
+			releaseColumns(keys)
+			return nil, err
+		}
+		col, err := x.Eval(e.mem, rel)
+		if err != nil {
+			releaseColumns(keys)
+			return nil, err
+		}
+		keys = append(keys, col)
+
+		if id, ok := n.(*identNode); ok {
+			key, _ := rel.resolve(id)
+			b.keys[key] = key
+		} else {
+			b.keys[n.String()] = aggregateKey(n.String())
+		}
+	}
+	return keys, nil
+}
+
+// group returns the relation holding a row per group, with the group keys and
+// the aggregates collected by b. Without GROUP BY all the rows form one group.
+func (e *executor) group(rel *relation, groupBy []node, keys []*array.Column, b *binder) (*relation, error) {
+	var groups *compute.Groups
+	if len(keys) > 0 {
+		var err error
+		if groups, err = compute.GroupRows(keys...); err != nil {
+			return nil, err
+		}
+	} else {
+		groups = &compute.Groups{IDs: make([]int32, rel.NumRows()), First: []int64{0}}
+	}
+
+	grel := &relation{rows: int64(groups.NumGroups())}
+	if len(keys) > 0 {
+		bldr := array.NewInt64Builder(e.mem)
+		defer bldr.Release()
+		bldr.AppendValues(groups.First, nil)
+		first := bldr.NewInt64Array()
+		defer first.Release()
+
+		for i, n := range groupBy {
+			col, err := compute.Take(e.mem, keys[i], first)
+			if err != nil {
+				grel.release()
+				return nil, err
+			}
+			if id, ok := n.(*identNode); ok {
+				key, _ := rel.resolve(id)
+				for _, c := range rel.cols {
+					if c.key == key {
+						grel.add(c.qualifier, c.name, key, col)
+					}
+				}
+			} else {
+				grel.add("", n.String(), aggregateKey(n.String()), col)
+			}
+		}
+	}
+
+	pre := &binder{rel: rel}
+	for _, canonical := range b.aggOrder {
+		call := b.aggs[canonical]
+		if _, ok := aggregates[call.name]; !ok {
+			col, err := e.reduceGroups(rel, pre, call, groups)
+			if err != nil {
+				grel.release()
+				return nil, fmt.Errorf("gomemsql: %s: %w", canonical, err)
+			}
+			grel.add("", canonical, aggregateKey(canonical), col)
+			continue
+		}
+
+		var arg *array.Column
+		if !call.star {
+			x, err := pre.bind(call.args[0])
+			if err != nil {
+				grel.release()
+				return nil, err
+			}
+			if arg, err = x.Eval(e.mem, rel); err != nil {
+				grel.release()
+				return nil, err
+			}
+		}
+		col, err := compute.AggregateGroups(e.mem, arg, groups, aggregates[call.name])
+		if arg != nil {
+			arg.Release()
+		}
+		if err != nil {
+			grel.release()
+			return nil, fmt.Errorf("gomemsql: %s: %w", canonical, err)
+		}
+		grel.add("", canonical, aggregateKey(canonical), col)
+	}
+	return grel, nil
+}
+
+// reduceGroups computes the user-defined aggregate function of call for
+// every group.
+func (e *executor) reduceGroups(rel *relation, pre *binder, call *callNode, groups *compute.Groups) (*array.Column, error) {
+	udf, ok := expr.Lookup(call.name)
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s", call.name)
+	}
+	var args []*array.Column
+	defer func() { releaseColumns(args) }()
+	for _, n := range call.args {
+		x, err := pre.bind(n)
+		if err != nil {
+			return nil, err
+		}
+		arg, err := x.Eval(e.mem, rel)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return udf.ReduceGroups(e.mem, args, groups)
+}
+
+// bindOutputs binds the select list, expanding the stars.
+func (e *executor) bindOutputs(stmt *selectStmt, rel *relation, b *binder) ([]output, error) {
+	var outputs []output
+	for _, item := range stmt.items {
+		if item.star {
+			if b.grouped {
+				return nil, fmt.Errorf("gomemsql: * is not allowed with GROUP BY or aggregate functions")
+			}
+			found := false
+			for _, c := range rel.cols {
+				if item.qualifier != "" && c.qualifier != item.qualifier {
+					continue
+				}
+				found = true
+				outputs = append(outputs, output{name: c.name, expr: expr.Col(c.key), star: true, qualified: c.key})
+			}
+			if !found {
+				return nil, fmt.Errorf("gomemsql: table %q does not exist", item.qualifier)
+			}
+			continue
+		}
+
+		x, err := b.bind(item.expr)
+		if err != nil {
+			return nil, err
+		}
+		name := item.alias
+		if name == "" {
+			if id, ok := item.expr.(*identNode); ok {
+				name = id.name
+			} else {
+				name = item.expr.String()
+			}
+		}
+		outputs = append(outputs, output{name: name, expr: x})
+	}
+
+	// Columns of a * sharing their name with another output are qualified.
+	count := make(map[string]int, len(outputs))
+	for _, out := range outputs {
+		count[out.name]++
+	}
+	for i, out := range outputs {
+		if out.star && count[out.name] > 1 {
+			outputs[i].name = out.qualified
+		}
+	}
+	seen := make(map[string]bool, len(outputs))
+	for _, out := range outputs {
+		if seen[out.name] {
+			return nil, fmt.Errorf("gomemsql: column %q is returned more than once, use an alias", out.name)
+		}
+		seen[out.name] = true
+	}
+	return outputs, nil
+}
+
+// bindSortKeys binds the ORDER BY clause. A key is an output column when it
+// is the position of the column, starting at 1, or its name.
+func (e *executor) bindSortKeys(stmt *selectStmt, outputs []output, b *binder) ([]sortKey, error) {
+	keys := make([]sortKey, 0, len(stmt.orderBy))
+	for _, item := range stmt.orderBy {
+		key := sortKey{output: -1}
+		if item.desc {
+			key.order = compute.Descending
+		}
+
+		switch n := item.expr.(type) {
+		case *literalNode:
+			pos, ok := n.value.(int64)
+			if !ok || pos < 1 || pos > int64(len(outputs)) {
+				return nil, fmt.Errorf("gomemsql: ORDER BY position %s is not in the select list", n.String())
+			}
+			key.output = int(pos - 1)
+		case *identNode:
+			if n.qualifier == "" {
+				for i, out := range outputs {
+					if out.name == n.name {
+						key.output = i
+						break
+					}
+				}
+			}
+		}
+
+		if key.output < 0 {
+			if stmt.distinct {
+				return nil, fmt.Errorf("gomemsql: ORDER BY %s must appear in the select list of a SELECT DISTINCT", item.expr.String())
+			}
+			x, err := b.bind(item.expr)
+			if err != nil {
+				return nil, err
+			}
+			key.expr = x
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// distinct returns the first row of every distinct combination of values of cols.
+func (e *executor) distinct(cols []*array.Column) ([]*array.Column, int64, error) {
+	groups, err := compute.GroupRows(cols...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	bldr := array.NewInt64Builder(e.mem)
+	defer bldr.Release()
+	bldr.AppendValues(groups.First, nil)
+	first := bldr.NewInt64Array()
+	defer first.Release()
+
+	taken, err := takeColumns(e.mem, cols, first)
+	return taken, int64(first.Len()), err
+}
+
+// sortAndLimit orders the rows of cols by the sort keys and keeps the rows
+// selected by LIMIT and OFFSET.
+func (e *executor) sortAndLimit(stmt *selectStmt, src *relation, cols []*array.Column, rows int64, keys []sortKey) ([]*array.Column, int64, error) {
+	var indices *array.Int64
+	if len(keys) > 0 {
+		keyCols := make([]*array.Column, 0, len(keys))
+		defer func() { releaseColumns(keyCols) }()
+		orders := make([]compute.SortOrder, len(keys))
+		for i, key := range keys {
+			orders[i] = key.order
+			if key.output >= 0 {
+				cols[key.output].Retain()
+				keyCols = append(keyCols, cols[key.output])
+				continue
+			}
+			col, err := key.expr.Eval(e.mem, src)
+			if err != nil {
+				return nil, 0, err
+			}
+			keyCols = append(keyCols, col)
+		}
+
+		var err error
+		if indices, err = compute.SortIndices(e.mem, keyCols, orders); err != nil {
+			return nil, 0, err
+		}
+	} else {
+		bldr := array.NewInt64Builder(e.mem)
+		defer bldr.Release()
+		for i := int64(0); i < rows; i++ {
+			bldr.Append(i)
+		}
+		indices = bldr.NewInt64Array()
+	}
+	defer indices.Release()
+
+	beg, end := stmt.offset, rows
+	if beg > rows {
+		beg = rows
+	}
+	if stmt.limit >= 0 && beg+stmt.limit < end {
+		end = beg + stmt.limit
+	}
+	window := array.NewSlice(indices, beg, end).(*array.Int64)
+	defer window.Release()
+
+	taken, err := takeColumns(e.mem, cols, window)
+	return taken, end - beg, err
+}
+
+// newDataFrame builds the result from the columns of the outputs.
+func (e *executor) newDataFrame(outputs []output, cols []*array.Column, rows int64) (*dataframe.DataFrame, error) {
+	result := make([]array.Column, 0, len(cols))
+	defer func() {
+		for i := range result {
+			result[i].Release()
+		}
+	}()
+	for i, col := range cols {
+		field := arrow.Field{Name: outputs[i].name, Type: col.DataType(), Nullable: col.Field().Nullable}
+		result = append(result, *array.NewColumn(field, col.Data()))
+	}
+	return dataframe.NewDataFrameFromShape(e.mem, result, rows)
+}
+
+func takeColumns(mem memory.Allocator, cols []*array.Column, indices *array.Int64) ([]*array.Column, error) {
+	taken := make([]*array.Column, 0, len(cols))
+	for _, col := range cols {
+		t, err := compute.Take(mem, col, indices)
+		if err != nil {
+			releaseColumns(taken)
+			return nil, err
+		}
+		taken = append(taken, t)
+	}
+	return taken, nil
+}
+
+func releaseColumns(cols []*array.Column) {
+	for _, col := range cols {
+		col.Release()
+	}
+}
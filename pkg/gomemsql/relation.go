@@ -0,0 +1,337 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomemsql
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/dataframe"
+	"github.com/gomem/gomem/pkg/expr"
+)
+
+// relation is the set of columns a query operates on between its stages.
+// It is the expr.Source the expressions of the query are evaluated against.
+type relation struct {
+	cols []relColumn
+	rows int64
+}
+
+// relColumn is a column of a relation. The key uniquely identifies the column
+// in expressions: it is the qualified name of table columns, and the
+// canonical form of the expressions computed while grouping.
+type relColumn struct {
+	qualifier string
+	name      string
+	key       string
+	col       *array.Column
+}
+
+// tableRelation returns the relation holding the columns of df, qualified by qualifier.
+func tableRelation(df *dataframe.DataFrame, qualifier string) *relation {
+	rel := &relation{rows: df.NumRows()}
+	for _, col := range df.Columns() {
+		rel.add(qualifier, col.Name(), qualifier+"."+col.Name(), array.NewColumn(col.Field(), col.Data()))
+	}
+	return rel
+}
+
+// add adds col to the relation, which takes ownership of it.
+func (r *relation) add(qualifier, name, key string, col *array.Column) {
+	r.cols = append(r.cols, relColumn{qualifier: qualifier, name: name, key: key, col: col})
+}
+
+// Column returns the column identified by key.
+func (r *relation) Column(key string) *array.Column {
+	for _, c := range r.cols {
+		if c.key == key {
+			return c.col
+		}
+	}
+	return nil
+}
+
+// NumRows returns the number of rows.
+func (r *relation) NumRows() int64 { return r.rows }
+
+func (r *relation) release() {
+	for _, c := range r.cols {
+		c.col.Release()
+	}
+	r.cols = nil
+}
+
+// resolve returns the key of the column an identifier references.
+func (r *relation) resolve(id *identNode) (string, error) {
+	key := ""
+	for _, c := range r.cols {
+		if c.name != id.name || (id.qualifier != "" && c.qualifier != id.qualifier) || c.qualifier == "" {
+			continue
+		}
+		if key != "" {
+			return "", fmt.Errorf("gomemsql: column reference %q is ambiguous", id.String())
+		}
+		key = c.key
+	}
+	if key == "" {
+		return "", fmt.Errorf("gomemsql: column %q does not exist", id.String())
+	}
+	return key, nil
+}
+
+// take returns a relation holding the rows of r at indices.
+func (r *relation) take(mem memory.Allocator, indices *array.Int64) (*relation, error) {
+	out := &relation{rows: int64(indices.Len())}
+	for _, c := range r.cols {
+		col, err := compute.Take(mem, c.col, indices)
+		if err != nil {
+			out.release()
+			return nil, fmt.Errorf("gomemsql: column %q: %w", c.key, err)
+		}
+		out.add(c.qualifier, c.name, c.key, col)
+	}
+	return out, nil
+}
+
+// filter returns a relation holding the rows of r where mask is true.
+func (r *relation) filter(mem memory.Allocator, mask *array.Column) (*relation, error) {
+	if k := kindOf(mask.DataType()); k != kindBool {
+		return nil, fmt.Errorf("gomemsql: condition must be boolean, got %v values", k)
+	}
+	out := &relation{}
+	for _, c := range r.cols {
+		col, err := compute.Filter(mem, c.col, mask)
+		if err != nil {
+			out.release()
+			return nil, fmt.Errorf("gomemsql: column %q: %w", c.key, err)
+		}
+		out.add(c.qualifier, c.name, c.key, col)
+	}
+	out.rows = countTrue(mask)
+	return out, nil
+}
+
+// countTrue returns the number of true values of a boolean column.
+func countTrue(mask *array.Column) int64 {
+	var n int64
+	for _, chunk := range mask.Data().Chunks() {
+		b := chunk.(*array.Boolean)
+		for i := 0; i < b.Len(); i++ {
+			if b.IsValid(i) && b.Value(i) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// aggregates maps the aggregate functions to the aggregate they compute.
+var aggregates = map[string]compute.AggregateKind{
+	"count": compute.AggCount,
+	"sum":   compute.AggSum,
+	"avg":   compute.AggMean,
+	"min":   compute.AggMin,
+	"max":   compute.AggMax,
+}
+
+// isAggregate reports whether n is a call to an aggregate function, built in
+// or user-defined.
+func isAggregate(n node) bool {
+	call, ok := n.(*callNode)
+	if !ok {
+		return false
+	}
+	if _, ok = aggregates[call.name]; ok {
+		return true
+	}
+	f, ok := expr.Lookup(call.name)
+	return ok && f.Kind == expr.Aggregate
+}
+
+// hasAggregate reports whether n calls an aggregate function.
+func hasAggregate(n node) bool {
+	switch n := n.(type) {
+	case *callNode:
+		if isAggregate(n) {
+			return true
+		}
+		for _, arg := range n.args {
+			if hasAggregate(arg) {
+				return true
+			}
+		}
+	case *unaryNode:
+		return hasAggregate(n.x)
+	case *binaryNode:
+		return hasAggregate(n.l) || hasAggregate(n.r)
+	case *isNullNode:
+		return hasAggregate(n.x)
+	}
+	return false
+}
+
+// binder turns the nodes of a query into expressions over a relation.
+//
+// Before grouping the identifiers reference the columns of the relation. After
+// grouping, when grouped is set, expressions may only reference the group keys
+// and aggregates, which the binder collects while binding so they can be
+// computed before the expressions are evaluated.
+type binder struct {
+	rel     *relation
+	grouped bool
+	// keys holds the key of the column of every group key, by the canonical
+	// form of the expression, or the column key of identifiers.
+	keys map[string]string
+	// aggs holds the aggregates to compute, by canonical form, in order.
+	aggs     map[string]*callNode
+	aggOrder []string
+}
+
+// groupKey returns the key of the group column n matches, if any.
+func (b *binder) groupKey(n node) (string, bool) {
+	if id, ok := n.(*identNode); ok {
+		key, err := b.rel.resolve(id)
+		if err != nil {
+			return "", false
+		}
+		k, ok := b.keys[key]
+		return k, ok
+	}
+	k, ok := b.keys[n.String()]
+	return k, ok
+}
+
+func (b *binder) bind(n node) (expr.Expr, error) {
+	if b.grouped {
+		if key, ok := b.groupKey(n); ok {
+			return expr.Col(key), nil
+		}
+	}
+
+	switch n := n.(type) {
+	case *identNode:
+		key, err := b.rel.resolve(n)
+		if err != nil {
+			return nil, err
+		}
+		if b.grouped {
+			return nil, fmt.Errorf("gomemsql: column %q must appear in the GROUP BY clause or be used in an aggregate function", n.String())
+		}
+		return expr.Col(key), nil
+
+	case *literalNode:
+		return literal{node: n}, nil
+
+	case *unaryNode:
+		x, err := b.bind(n.x)
+		if err != nil {
+			return nil, err
+		}
+		if n.op == "NOT" {
+			return expr.Call("NOT", not, x), nil
+		}
+		return expr.Call("-", negate, x), nil
+
+	case *binaryNode:
+		l, err := b.bind(n.l)
+		if err != nil {
+			return nil, err
+		}
+		r, err := b.bind(n.r)
+		if err != nil {
+			return nil, err
+		}
+		switch n.op {
+		case "AND", "OR":
+			return expr.Call(n.op, logical(n.op), l, r), nil
+		case "+", "-", "*", "/", "%":
+			return expr.Call(n.op, arithmetic(n.op), l, r), nil
+		default:
+			return expr.Call(n.op, comparison(n.op), l, r), nil
+		}
+
+	case *isNullNode:
+		x, err := b.bind(n.x)
+		if err != nil {
+			return nil, err
+		}
+		return expr.Call(n.String(), isNull(n.not), x), nil
+
+	case *callNode:
+		if isAggregate(n) {
+			return b.bindAggregate(n)
+		}
+		var (
+			nargs int
+			fn    expr.KernelFunc
+		)
+		if sf, ok := scalarFuncs[n.name]; ok {
+			nargs, fn = sf.args, sf.fn
+		} else if udf, ok := expr.Lookup(n.name); ok {
+			nargs, fn = len(udf.Args), udf.Apply
+		} else {
+			return nil, fmt.Errorf("gomemsql: unknown function %s", n.name)
+		}
+		if n.star || len(n.args) != nargs {
+			return nil, fmt.Errorf("gomemsql: %s expects %d arguments", n.name, nargs)
+		}
+		args := make([]expr.Expr, len(n.args))
+		for i, arg := range n.args {
+			var err error
+			if args[i], err = b.bind(arg); err != nil {
+				return nil, err
+			}
+		}
+		return expr.Call(n.name, fn, args...), nil
+
+	default:
+		return nil, fmt.Errorf("gomemsql: unsupported expression %s", n.String())
+	}
+}
+
+func (b *binder) bindAggregate(n *callNode) (expr.Expr, error) {
+	if !b.grouped {
+		return nil, fmt.Errorf("gomemsql: aggregate function %s is not allowed here", n.name)
+	}
+	nargs := 1
+	if _, ok := aggregates[n.name]; !ok {
+		udf, _ := expr.Lookup(n.name)
+		nargs = len(udf.Args)
+	}
+	switch {
+	case n.star && n.name != "count":
+		return nil, fmt.Errorf("gomemsql: %s(*) is not supported", n.name)
+	case !n.star && len(n.args) != nargs:
+		return nil, fmt.Errorf("gomemsql: %s expects %d arguments", n.name, nargs)
+	}
+	for _, arg := range n.args {
+		if hasAggregate(arg) {
+			return nil, fmt.Errorf("gomemsql: aggregate function calls cannot be nested")
+		}
+	}
+
+	canonical := n.String()
+	if _, ok := b.aggs[canonical]; !ok {
+		b.aggs[canonical] = n
+		b.aggOrder = append(b.aggOrder, canonical)
+	}
+	return expr.Col(aggregateKey(canonical)), nil
+}
+
+// aggregateKey returns the key of the column of an aggregate, or of a group key
+// that is not a column, in the grouped relation.
+func aggregateKey(canonical string) string { return "#" + canonical }
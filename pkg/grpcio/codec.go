@@ -0,0 +1,233 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcio
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+// CodecName is the name of the Codec, used as the gRPC content subtype.
+const CodecName = "arrow"
+
+// MessageCodec is the interface of a gRPC codec, encoding.Codec.
+type MessageCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	Name() string
+}
+
+// Codec is a gRPC codec encoding array.Record and *dataframe.DataFrame
+// messages with the Arrow IPC stream format. Messages of type []byte are
+// sent as is, which is what the stream helpers rely on.
+type Codec struct {
+	cfg *config
+}
+
+var _ MessageCodec = (*Codec)(nil)
+
+// NewCodec returns a new Codec.
+func NewCodec(opts ...Option) (*Codec, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Codec{cfg: cfg}, nil
+}
+
+// Name returns CodecName.
+func (c *Codec) Name() string { return CodecName }
+
+// Marshal encodes v, an array.Record, a *dataframe.DataFrame or a []byte.
+// Other messages are encoded by the fallback codec.
+func (c *Codec) Marshal(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case array.Record:
+		return marshalRecord(c.cfg, v)
+	case *dataframe.DataFrame:
+		return marshalDataFrame(c.cfg, v)
+	case []byte:
+		return v, nil
+	case *[]byte:
+		return *v, nil
+	}
+	if c.cfg.fallback == nil {
+		return nil, fmt.Errorf("grpcio: cannot marshal message of type %T", v)
+	}
+	return c.cfg.fallback.Marshal(v)
+}
+
+// Unmarshal decodes data into v, a *array.Record, a **dataframe.DataFrame
+// or a *[]byte. The decoded record or DataFrame must be released by the
+// caller. Other messages are decoded by the fallback codec.
+func (c *Codec) Unmarshal(data []byte, v interface{}) error {
+	switch v := v.(type) {
+	case *array.Record:
+		rec, err := unmarshalRecord(c.cfg, data)
+		if err != nil {
+			return err
+		}
+		*v = rec
+		return nil
+	case **dataframe.DataFrame:
+		df, err := unmarshalDataFrame(c.cfg, data)
+		if err != nil {
+			return err
+		}
+		*v = df
+		return nil
+	case *[]byte:
+		// The buffer of data may be reused by gRPC.
+		*v = append([]byte(nil), data...)
+		return nil
+	}
+	if c.cfg.fallback == nil {
+		return fmt.Errorf("grpcio: cannot unmarshal message of type %T", v)
+	}
+	return c.cfg.fallback.Unmarshal(data, v)
+}
+
+// MarshalRecord encodes rec with the Arrow IPC stream format.
+func MarshalRecord(rec array.Record, opts ...Option) ([]byte, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return marshalRecord(cfg, rec)
+}
+
+// UnmarshalRecord decodes a record encoded by MarshalRecord. The record
+// must be released by the caller.
+func UnmarshalRecord(data []byte, opts ...Option) (array.Record, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalRecord(cfg, data)
+}
+
+// MarshalDataFrame encodes df with the Arrow IPC stream format, a record per chunk.
+func MarshalDataFrame(df *dataframe.DataFrame, opts ...Option) ([]byte, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return marshalDataFrame(cfg, df)
+}
+
+// UnmarshalDataFrame decodes a DataFrame encoded by MarshalDataFrame. The
+// DataFrame must be released by the caller.
+func UnmarshalDataFrame(data []byte, opts ...Option) (*dataframe.DataFrame, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalDataFrame(cfg, data)
+}
+
+func marshalRecord(cfg *config, rec array.Record) ([]byte, error) {
+	rdr, err := array.NewRecordReader(rec.Schema(), []array.Record{rec})
+	if err != nil {
+		return nil, err
+	}
+	defer rdr.Release()
+	return marshalReader(cfg, rdr)
+}
+
+func marshalDataFrame(cfg *config, df *dataframe.DataFrame) ([]byte, error) {
+	rdr := array.NewTableReader(dataframe.NewTableFacade(df), -1)
+	defer rdr.Release()
+	return marshalReader(cfg, rdr)
+}
+
+// marshalReader encodes the records of rdr as a single IPC stream.
+func marshalReader(cfg *config, rdr array.RecordReader) ([]byte, error) {
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, append(cfg.writerOptions(), ipc.WithSchema(rdr.Schema()))...)
+	for rdr.Next() {
+		if err := w.Write(rdr.Record()); err != nil {
+			return nil, fmt.Errorf("grpcio: could not encode record: %w", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("grpcio: could not encode records: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func unmarshalRecord(cfg *config, data []byte) (array.Record, error) {
+	r, err := ipc.NewReader(bytes.NewReader(data), cfg.readerOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("grpcio: could not decode records: %w", err)
+	}
+	defer r.Release()
+
+	recs, err := readRecords(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) != 1 {
+		releaseRecords(recs)
+		return nil, fmt.Errorf("grpcio: expected 1 record, got %d", len(recs))
+	}
+	return recs[0], nil
+}
+
+func unmarshalDataFrame(cfg *config, data []byte) (*dataframe.DataFrame, error) {
+	r, err := ipc.NewReader(bytes.NewReader(data), cfg.readerOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("grpcio: could not decode records: %w", err)
+	}
+	defer r.Release()
+	return readDataFrame(cfg, r)
+}
+
+// readRecords returns the records of r, retained.
+func readRecords(r *ipc.Reader) ([]array.Record, error) {
+	var recs []array.Record
+	for r.Next() {
+		rec := r.Record()
+		rec.Retain()
+		recs = append(recs, rec)
+	}
+	if err := r.Err(); err != nil {
+		releaseRecords(recs)
+		return nil, fmt.Errorf("grpcio: could not decode record: %w", err)
+	}
+	return recs, nil
+}
+
+// readDataFrame returns a DataFrame holding the records of r.
+func readDataFrame(cfg *config, r *ipc.Reader) (*dataframe.DataFrame, error) {
+	recs, err := readRecords(r)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseRecords(recs)
+
+	tbl := array.NewTableFromRecords(r.Schema(), recs)
+	defer tbl.Release()
+	return dataframe.NewDataFrameFromTable(cfg.mem, tbl)
+}
+
+func releaseRecords(recs []array.Record) {
+	for _, rec := range recs {
+		rec.Release()
+	}
+}
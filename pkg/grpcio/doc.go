@@ -0,0 +1,35 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package grpcio sends Arrow records and DataFrames as gRPC messages.
+
+Records are encoded with the Arrow IPC stream format. The encoded bytes can
+be carried in a bytes field of a protobuf message, with MarshalRecord and
+UnmarshalRecord, or the messages themselves can be records and DataFrames
+when the Codec is used by the client and the server. The Codec does not
+depend on the grpc package, it implements the encoding.Codec interface:
+
+	codec := grpcio.NewCodec(grpcio.WithFallback(protoCodec))
+	srv := grpc.NewServer(grpc.ForceServerCodec(codec))
+
+Large results are best streamed a record at a time. SendRecords writes the
+schema once, with the first record, and NewRecordReader reassembles the
+stream on the receiving end:
+
+	err := grpcio.SendRecords(grpcio.SendMsg(stream), rdr)
+
+	rdr, err := grpcio.NewRecordReader(grpcio.RecvMsg(stream))
+*/
+package grpcio
@@ -0,0 +1,265 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/dataframe"
+	"github.com/gomem/gomem/pkg/transform"
+)
+
+// pipe is an in-memory gRPC stream encoding its messages with a codec.
+type pipe struct {
+	codec MessageCodec
+	msgs  chan []byte
+}
+
+func (p *pipe) SendMsg(m interface{}) error {
+	data, err := p.codec.Marshal(m)
+	if err != nil {
+		return err
+	}
+	p.msgs <- data
+	return nil
+}
+
+func (p *pipe) RecvMsg(m interface{}) error {
+	data, ok := <-p.msgs
+	if !ok {
+		return io.EOF
+	}
+	return p.codec.Unmarshal(data, m)
+}
+
+func newRecords(t *testing.T, mem memory.Allocator, n int) (*arrow.Schema, []array.Record) {
+	t.Helper()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+
+	bldr := array.NewRecordBuilder(mem, schema)
+	defer bldr.Release()
+
+	recs := make([]array.Record, n)
+	for i := range recs {
+		bldr.Field(0).(*array.Int64Builder).AppendValues([]int64{int64(2 * i), int64(2*i + 1)}, nil)
+		bldr.Field(1).(*array.StringBuilder).AppendValues([]string{fmt.Sprint("n", i), ""}, []bool{true, false})
+		recs[i] = bldr.NewRecord()
+	}
+	return schema, recs
+}
+
+func TestCodec(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	codec, err := NewCodec(WithAllocator(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := codec.Name(); got != CodecName {
+		t.Fatalf("invalid name: got=%q, want=%q", got, CodecName)
+	}
+
+	_, recs := newRecords(t, pool, 1)
+	defer recs[0].Release()
+
+	data, err := codec.Marshal(recs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rec array.Record
+	if err := codec.Unmarshal(data, &rec); err != nil {
+		t.Fatal(err)
+	}
+	defer rec.Release()
+	if !array.RecordEqual(rec, recs[0]) {
+		t.Fatalf("invalid record: got=%v, want=%v", rec, recs[0])
+	}
+
+	df, err := dataframe.NewDataFrameFromRecord(pool, recs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	data, err = codec.Marshal(df)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got *dataframe.DataFrame
+	if err := codec.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	defer got.Release()
+	if !got.Schema().Equal(df.Schema()) {
+		t.Fatalf("invalid schema: got=%v, want=%v", got.Schema(), df.Schema())
+	}
+	for i := 0; i < df.NumCols(); i++ {
+		want := fmt.Sprint(df.ColumnAt(i).Data().Chunks())
+		if got := fmt.Sprint(got.ColumnAt(i).Data().Chunks()); got != want {
+			t.Fatalf("invalid column %d: got=%s, want=%s", i, got, want)
+		}
+	}
+
+	if _, err := codec.Marshal(struct{}{}); err == nil {
+		t.Fatal("expected an error marshaling an unknown message without fallback")
+	}
+}
+
+func TestMarshalRecord(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	_, recs := newRecords(t, pool, 1)
+	defer recs[0].Release()
+
+	data, err := MarshalRecord(recs[0], WithAllocator(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := UnmarshalRecord(data, WithAllocator(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rec.Release()
+	if !array.RecordEqual(rec, recs[0]) {
+		t.Fatalf("invalid record: got=%v, want=%v", rec, recs[0])
+	}
+
+	if _, err := UnmarshalRecord(data[:len(data)/2]); err == nil {
+		t.Fatal("expected an error decoding a truncated record")
+	}
+}
+
+func TestMarshalRecordChecksum(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	_, recs := newRecords(t, pool, 1)
+	defer recs[0].Release()
+
+	data, err := MarshalRecord(recs[0], WithAllocator(pool), WithChecksum())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := UnmarshalRecord(data, WithAllocator(pool), WithChecksum())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rec.Release()
+	if !array.RecordEqual(rec, recs[0]) {
+		t.Fatalf("invalid record: got=%v, want=%v", rec, recs[0])
+	}
+
+	// Flip a bit of the body of the record, after the schema message.
+	data[len(data)-20] ^= 1
+	if _, err := UnmarshalRecord(data, WithAllocator(pool), WithChecksum()); !errors.Is(err, ipc.ErrChecksum) {
+		t.Fatalf("got error %v, want %v", err, ipc.ErrChecksum)
+	}
+}
+
+func TestMarshalProtectedRecord(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema, recs := newRecords(t, pool, 1)
+	defer recs[0].Release()
+	fields := schema.Fields()
+	fields[1] = transform.Protect(fields[1], "pii")
+	want := array.NewRecord(arrow.NewSchema(fields, nil), recs[0].Columns(), recs[0].NumRows())
+	defer want.Release()
+
+	reg := transform.NewRegistry()
+	if err := reg.Register("pii", transform.Tokenize([]byte("secret"))); err != nil {
+		t.Fatal(err)
+	}
+	data, err := MarshalRecord(want, WithAllocator(pool), WithTransforms(reg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("n0")) {
+		t.Fatal("protected value is sent in clear")
+	}
+	rec, err := UnmarshalRecord(data, WithAllocator(pool), WithTransforms(reg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rec.Release()
+	if got := rec.Column(1).(*array.String).Value(0); len(got) != 64 {
+		t.Fatalf("invalid token %q", got)
+	}
+}
+
+func TestSendRecords(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	codec, err := NewCodec(WithAllocator(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema, recs := newRecords(t, pool, 3)
+	defer func() {
+		for _, rec := range recs {
+			rec.Release()
+		}
+	}()
+	rdr, err := array.NewRecordReader(schema, recs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rdr.Release()
+
+	stream := &pipe{codec: codec, msgs: make(chan []byte, 8)}
+	if err := SendRecords(SendMsg(stream), rdr, WithAllocator(pool)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(stream.msgs), len(recs)+1; got != want {
+		t.Fatalf("invalid number of messages: got=%d, want=%d", got, want)
+	}
+	close(stream.msgs)
+
+	r, err := NewRecordReader(RecvMsg(stream), WithAllocator(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+
+	n := 0
+	for r.Next() {
+		if !array.RecordEqual(r.Record(), recs[n]) {
+			t.Fatalf("invalid record %d: got=%v, want=%v", n, r.Record(), recs[n])
+		}
+		n++
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n != len(recs) {
+		t.Fatalf("invalid number of records: got=%d, want=%d", n, len(recs))
+	}
+}
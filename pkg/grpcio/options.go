@@ -0,0 +1,138 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcio
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/transform"
+)
+
+// Option is an option that may be passed to the functions of the package.
+type Option func(interface{}) error
+
+type config struct {
+	mem      memory.Allocator
+	codec    ipc.Codec
+	fallback MessageCodec
+	xforms   *transform.Registry
+	checksum bool
+}
+
+func newConfig(opts ...Option) (*config, error) {
+	cfg := &config{mem: memory.NewGoAllocator()}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+func (cfg *config) ipcOptions() []ipc.Option {
+	opts := []ipc.Option{ipc.WithAllocator(cfg.mem)}
+	if cfg.codec != nil {
+		opts = append(opts, ipc.WithCompression(cfg.codec))
+	}
+	if cfg.checksum {
+		opts = append(opts, ipc.WithChecksum())
+	}
+	return opts
+}
+
+// writerOptions returns the options of the IPC writers encoding records.
+func (cfg *config) writerOptions() []ipc.Option {
+	opts := cfg.ipcOptions()
+	if cfg.xforms != nil {
+		opts = append(opts, cfg.xforms.WriteOption(cfg.mem))
+	}
+	return opts
+}
+
+// readerOptions returns the options of the IPC readers decoding records.
+func (cfg *config) readerOptions() []ipc.Option {
+	opts := cfg.ipcOptions()
+	if cfg.xforms != nil {
+		opts = append(opts, cfg.xforms.ReadOption(cfg.mem))
+	}
+	return opts
+}
+
+// WithAllocator specifies the allocator used to encode and decode the
+// records, a Go allocator by default.
+func WithAllocator(mem memory.Allocator) Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply WithAllocator to: %T", p)
+		}
+		cfg.mem = mem
+		return nil
+	}
+}
+
+// WithCompression compresses the buffers of the encoded records with codec.
+func WithCompression(codec ipc.Codec) Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply WithCompression to: %T", p)
+		}
+		cfg.codec = codec
+		return nil
+	}
+}
+
+// WithChecksum follows every encoded message with its checksum, which is
+// verified when decoding, so corrupted messages are reported as ipc.ErrChecksum.
+// The sender and the receiver must both use the option.
+func WithChecksum() Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply WithChecksum to: %T", p)
+		}
+		cfg.checksum = true
+		return nil
+	}
+}
+
+// WithFallback specifies the codec a Codec uses for the messages that are
+// neither records, DataFrames nor bytes, typically the protobuf codec.
+func WithFallback(codec MessageCodec) Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply WithFallback to: %T", p)
+		}
+		cfg.fallback = codec
+		return nil
+	}
+}
+
+// WithTransforms encodes the protected columns of the records with the
+// transforms of reg before sending them, and decodes them when receiving them.
+func WithTransforms(reg *transform.Registry) Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply WithTransforms to: %T", p)
+		}
+		cfg.xforms = reg
+		return nil
+	}
+}
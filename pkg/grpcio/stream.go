@@ -0,0 +1,112 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcio
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+)
+
+// SendRecords sends the records of rdr as an IPC stream split in messages,
+// a message per record. The first message also holds the schema and the
+// last one the end of the stream, so that the receiver can tell a complete
+// stream from an interrupted one.
+func SendRecords(send func([]byte) error, rdr array.RecordReader, opts ...Option) error {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	flush := func() error {
+		if buf.Len() == 0 {
+			return nil
+		}
+		// The message may be kept by send, so it is not reused.
+		msg := append([]byte(nil), buf.Bytes()...)
+		buf.Reset()
+		return send(msg)
+	}
+
+	w := ipc.NewWriter(&buf, append(cfg.writerOptions(), ipc.WithSchema(rdr.Schema()))...)
+	for rdr.Next() {
+		if err := w.Write(rdr.Record()); err != nil {
+			return fmt.Errorf("grpcio: could not encode record: %w", err)
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("grpcio: could not encode records: %w", err)
+	}
+	return flush()
+}
+
+// NewRecordReader returns a reader of the records sent by SendRecords,
+// receiving the messages with recv until it returns io.EOF.
+func NewRecordReader(recv func() ([]byte, error), opts ...Option) (*ipc.Reader, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	r, err := ipc.NewReader(&messageReader{recv: recv}, cfg.readerOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("grpcio: could not read schema: %w", err)
+	}
+	return r, nil
+}
+
+// messageReader reads the concatenation of the received messages.
+type messageReader struct {
+	recv func() ([]byte, error)
+	msg  []byte
+}
+
+func (r *messageReader) Read(p []byte) (int, error) {
+	for len(r.msg) == 0 {
+		msg, err := r.recv()
+		if err != nil {
+			return 0, err
+		}
+		r.msg = msg
+	}
+	n := copy(p, r.msg)
+	r.msg = r.msg[n:]
+	return n, nil
+}
+
+// SendMsg returns the function sending messages on a gRPC stream using the
+// Codec, such as a grpc.ServerStream or grpc.ClientStream.
+func SendMsg(stream interface{ SendMsg(m interface{}) error }) func([]byte) error {
+	return func(msg []byte) error {
+		return stream.SendMsg(msg)
+	}
+}
+
+// RecvMsg returns the function receiving messages from a gRPC stream using
+// the Codec, such as a grpc.ServerStream or grpc.ClientStream.
+func RecvMsg(stream interface{ RecvMsg(m interface{}) error }) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		var msg []byte
+		if err := stream.RecvMsg(&msg); err != nil {
+			return nil, err
+		}
+		return msg, nil
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package httpio serves DataFrames over HTTP and reads them from requests.
+
+A DataFrame is written as an Arrow IPC stream, CSV or a JSON array of row
+objects, optionally gzip compressed. ServeDataFrame picks the format from
+the Accept header of the request and compresses the response when the
+client accepts gzip:
+
+	http.HandleFunc("/sales", func(w http.ResponseWriter, r *http.Request) {
+		if err := httpio.ServeDataFrame(w, r, sales); err != nil {
+			log.Print(err)
+		}
+	})
+
+ReadDataFrame reads a DataFrame uploaded in any of these formats, using the
+Content-Type and Content-Encoding headers of the request. The types of the
+CSV and JSON columns are inferred from their values.
+*/
+package httpio
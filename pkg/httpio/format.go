@@ -0,0 +1,142 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpio
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format is the encoding of a DataFrame in an HTTP body.
+type Format int
+
+const (
+	// JSON is a JSON array with an object per row. It is the default.
+	JSON Format = iota
+	// Arrow is the Arrow IPC stream format.
+	Arrow
+	// CSV is CSV with a header row.
+	CSV
+)
+
+// Media types of the formats.
+const (
+	ArrowMediaType  = "application/vnd.apache.arrow.stream"
+	CSVMediaType    = "text/csv"
+	JSONMediaType   = "application/json"
+	NDJSONMediaType = "application/x-ndjson"
+)
+
+func (f Format) String() string {
+	switch f {
+	case JSON:
+		return "json"
+	case Arrow:
+		return "arrow"
+	case CSV:
+		return "csv"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// ContentType returns the value of the Content-Type header of f.
+func (f Format) ContentType() string {
+	switch f {
+	case Arrow:
+		return ArrowMediaType
+	case CSV:
+		return CSVMediaType + "; charset=utf-8"
+	default:
+		return JSONMediaType
+	}
+}
+
+// FormatOf returns the format of a Content-Type header value. JSON also
+// accepts newline delimited JSON.
+func FormatOf(contentType string) (Format, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return 0, fmt.Errorf("httpio: invalid content type %q: %w", contentType, err)
+	}
+	switch mediaType {
+	case ArrowMediaType:
+		return Arrow, nil
+	case CSVMediaType:
+		return CSV, nil
+	case JSONMediaType, NDJSONMediaType:
+		return JSON, nil
+	default:
+		return 0, fmt.Errorf("httpio: unsupported content type %q", contentType)
+	}
+}
+
+// acceptRange is a media range of an Accept header.
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept returns the media ranges of an Accept header by decreasing quality.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+	return ranges
+}
+
+// NegotiateFormat returns the format preferred by the Accept header of r,
+// JSON when the header is missing or accepts none of the formats.
+func NegotiateFormat(r *http.Request) Format {
+	for _, ar := range parseAccept(r.Header.Get("Accept")) {
+		switch ar.mediaType {
+		case ArrowMediaType:
+			return Arrow
+		case CSVMediaType, "text/*":
+			return CSV
+		case JSONMediaType, NDJSONMediaType, "*/*", "application/*":
+			return JSON
+		}
+	}
+	return JSON
+}
+
+// acceptsGzip reports whether the Accept-Encoding header of r accepts gzip.
+func acceptsGzip(r *http.Request) bool {
+	for _, ar := range parseAccept(r.Header.Get("Accept-Encoding")) {
+		if ar.mediaType == "gzip" || ar.mediaType == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpio
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+// columns returns the name, type and values of the columns of df.
+func columns(df *dataframe.DataFrame) string {
+	var sb strings.Builder
+	for _, col := range df.Columns() {
+		fmt.Fprintf(&sb, "%s %v:", col.Name(), col.DataType())
+		for _, chunk := range col.Data().Chunks() {
+			fmt.Fprintf(&sb, " %v", chunk)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func TestRoundTrip(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := dataframe.NewDataFrameFromMem(pool, dataframe.Dict{
+		"id":    []int64{1, 2, 3},
+		"name":  []string{"a", "b,c", "d\"e"},
+		"price": []float64{1.5, 2, 0.25},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+	want := columns(df)
+
+	for _, format := range []Format{Arrow, CSV, JSON} {
+		for _, gz := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%v gzip=%v", format, gz), func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				if err := WriteDataFrame(rec, df, format, WithGzip(gz)); err != nil {
+					t.Fatal(err)
+				}
+
+				req := httptest.NewRequest(http.MethodPost, "/", rec.Body)
+				req.Header.Set("Content-Type", rec.Header().Get("Content-Type"))
+				req.Header.Set("Content-Encoding", rec.Header().Get("Content-Encoding"))
+				got, err := ReadDataFrame(req, WithAllocator(pool))
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer got.Release()
+
+				if got := columns(got); got != want {
+					t.Fatalf("invalid DataFrame:\ngot:\n%s\nwant:\n%s", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestServeDataFrame(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := dataframe.NewDataFrameFromMem(pool, dataframe.Dict{
+		"id": []int64{1, 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	for _, tc := range []struct {
+		accept, encoding string
+		contentType      string
+		gzip             bool
+	}{
+		{"", "", JSONMediaType, false},
+		{"text/html, */*;q=0.8", "gzip, deflate", JSONMediaType, true},
+		{"text/csv;q=0.5, application/vnd.apache.arrow.stream", "", ArrowMediaType, false},
+		{"application/vnd.apache.arrow.stream;q=0, text/*", "gzip;q=0", "text/csv; charset=utf-8", false},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", tc.accept)
+		req.Header.Set("Accept-Encoding", tc.encoding)
+		rec := httptest.NewRecorder()
+		if err := ServeDataFrame(rec, req, df); err != nil {
+			t.Fatal(err)
+		}
+		if got := rec.Header().Get("Content-Type"); got != tc.contentType {
+			t.Errorf("Accept %q: invalid content type: got=%q, want=%q", tc.accept, got, tc.contentType)
+		}
+		if got := rec.Header().Get("Content-Encoding") == "gzip"; got != tc.gzip {
+			t.Errorf("Accept-Encoding %q: invalid gzip: got=%v, want=%v", tc.encoding, got, tc.gzip)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	if err := WriteDataFrame(rec, df, JSON); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rec.Body.String(), "[{\"id\":1},\n{\"id\":2}]\n"; got != want {
+		t.Fatalf("invalid JSON: got=%q, want=%q", got, want)
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	for _, tc := range []struct {
+		body, want string
+	}{
+		{
+			body: "{\"a\": 1, \"b\": \"x\"}\n{\"a\": 2.5, \"c\": true}\n{\"b\": null}\n",
+			want: "a float64: [1 2.5 (null)]\nb utf8: [\"x\" (null) (null)]\nc bool: [(null) true (null)]\n",
+		},
+		{body: "[]", want: ""},
+		{body: "[{\"a\": 1}, {\"a\": \"x\"}]", want: "httpio: could not read json: column \"a\" mixes int64 and utf8 values"},
+		{body: "[{\"a\": [1]}]", want: "httpio: could not read json: column \"a\": nested value [1] is not supported"},
+		{body: "[{\"a\": 1, \"a\": 2}]", want: "httpio: could not read json: duplicate key \"a\" in row 0"},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", NDJSONMediaType)
+		df, err := ReadDataFrame(req, WithAllocator(pool))
+		if err != nil {
+			if err.Error() != tc.want {
+				t.Errorf("invalid error: got=%q, want=%q", err, tc.want)
+			}
+			continue
+		}
+		if got := columns(df); got != tc.want {
+			t.Errorf("invalid DataFrame:\ngot:\n%s\nwant:\n%s", got, tc.want)
+		}
+		df.Release()
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("a,b"))
+	req.Header.Set("Content-Type", "text/plain")
+	if _, err := ReadDataFrame(req); err == nil {
+		t.Fatal("expected an error for an unsupported content type")
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpio
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/dataframe"
+	"github.com/gomem/gomem/pkg/iterator"
+)
+
+// writeJSON writes df as a JSON array of row objects, keeping the order of the columns.
+func writeJSON(w io.Writer, df *dataframe.DataFrame) error {
+	keys := make([][]byte, df.NumCols())
+	for i := range keys {
+		key, err := json.Marshal(df.Name(i))
+		if err != nil {
+			return err
+		}
+		keys[i] = append(key, ':')
+	}
+
+	it := iterator.NewStepIteratorForColumns(df.Columns())
+	defer it.Release()
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for row := 0; it.Next(); row++ {
+		step, err := it.ValuesJSON()
+		if err != nil {
+			return err
+		}
+
+		obj := []byte("{")
+		if row > 0 {
+			obj = []byte(",\n{")
+		}
+		for i, v := range step.ValuesJSON {
+			if i > 0 {
+				obj = append(obj, ',')
+			}
+			value, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("column %q: %w", df.Name(i), err)
+			}
+			obj = append(append(obj, keys[i]...), value...)
+		}
+		if _, err := w.Write(append(obj, '}')); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]\n")
+	return err
+}
+
+// jsonKind is the type inferred for the values of a JSON column.
+type jsonKind int
+
+const (
+	jsonNull jsonKind = iota
+	jsonBool
+	jsonInt
+	jsonFloat
+	jsonString
+)
+
+func (k jsonKind) dataType() arrow.DataType {
+	switch k {
+	case jsonBool:
+		return arrow.FixedWidthTypes.Boolean
+	case jsonInt:
+		return arrow.PrimitiveTypes.Int64
+	case jsonFloat:
+		return arrow.PrimitiveTypes.Float64
+	default:
+		return arrow.BinaryTypes.String
+	}
+}
+
+func kindOfJSON(v interface{}) (jsonKind, error) {
+	switch v := v.(type) {
+	case nil:
+		return jsonNull, nil
+	case bool:
+		return jsonBool, nil
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return jsonInt, nil
+		}
+		return jsonFloat, nil
+	case string:
+		return jsonString, nil
+	default:
+		return 0, fmt.Errorf("nested value %v is not supported", v)
+	}
+}
+
+// jsonColumn collects the values of a column of JSON objects.
+type jsonColumn struct {
+	name   string
+	kind   jsonKind
+	values []interface{}
+}
+
+func (c *jsonColumn) add(row int, v interface{}) error {
+	k, err := kindOfJSON(v)
+	if err != nil {
+		return fmt.Errorf("column %q: %w", c.name, err)
+	}
+	switch {
+	case k == jsonNull || k == c.kind:
+	case c.kind == jsonNull:
+		c.kind = k
+	case (k == jsonInt && c.kind == jsonFloat) || (k == jsonFloat && c.kind == jsonInt):
+		c.kind = jsonFloat
+	default:
+		return fmt.Errorf("column %q mixes %v and %v values", c.name, c.kind.dataType(), k.dataType())
+	}
+	for len(c.values) < row {
+		c.values = append(c.values, nil)
+	}
+	c.values = append(c.values, v)
+	return nil
+}
+
+func (c *jsonColumn) build(mem memory.Allocator, rows int) array.Interface {
+	bldr := array.NewBuilder(mem, c.kind.dataType())
+	defer bldr.Release()
+	bldr.Reserve(rows)
+
+	for i := 0; i < rows; i++ {
+		var v interface{}
+		if i < len(c.values) {
+			v = c.values[i]
+		}
+		if v == nil {
+			bldr.AppendNull()
+			continue
+		}
+		switch b := bldr.(type) {
+		case *array.BooleanBuilder:
+			b.Append(v.(bool))
+		case *array.Int64Builder:
+			n, _ := v.(json.Number).Int64()
+			b.Append(n)
+		case *array.Float64Builder:
+			f, _ := v.(json.Number).Float64()
+			b.Append(f)
+		case *array.StringBuilder:
+			b.Append(v.(string))
+		}
+	}
+	return bldr.NewArray()
+}
+
+// readJSON reads a JSON array of row objects, or newline delimited objects,
+// inferring the type of every column from its values.
+func readJSON(mem memory.Allocator, r io.Reader) (*dataframe.DataFrame, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var (
+		cols  []*jsonColumn
+		index = make(map[string]int)
+		rows  int
+	)
+	readObject := func() error {
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key := tok.(string)
+			var v interface{}
+			if err := dec.Decode(&v); err != nil {
+				return err
+			}
+			i, ok := index[key]
+			if !ok {
+				i = len(cols)
+				index[key] = i
+				cols = append(cols, &jsonColumn{name: key})
+			}
+			if len(cols[i].values) > rows {
+				return fmt.Errorf("duplicate key %q in row %d", key, rows)
+			}
+			if err := cols[i].add(rows, v); err != nil {
+				return err
+			}
+		}
+		rows++
+		return expectDelim(dec, '}')
+	}
+
+	tok, err := dec.Token()
+	switch {
+	case err == io.EOF:
+	case err != nil:
+		return nil, err
+	case tok == json.Delim('['):
+		for dec.More() {
+			if err := expectDelim(dec, '{'); err != nil {
+				return nil, err
+			}
+			if err := readObject(); err != nil {
+				return nil, err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return nil, err
+		}
+	case tok == json.Delim('{'):
+		for {
+			if err := readObject(); err != nil {
+				return nil, err
+			}
+			if err := expectDelim(dec, '{'); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("expected an array or objects, got %v", tok)
+	}
+
+	fields := make([]arrow.Field, len(cols))
+	arrs := make([]array.Interface, len(cols))
+	defer func() {
+		for _, arr := range arrs {
+			arr.Release()
+		}
+	}()
+	for i, col := range cols {
+		fields[i] = arrow.Field{Name: col.name, Type: col.kind.dataType(), Nullable: true}
+		arrs[i] = col.build(mem, rows)
+	}
+	return dataframe.NewDataFrame(mem, arrow.NewSchema(fields, nil), arrs)
+}
+
+// expectDelim reads the delimiter d, returning io.EOF at the end of the input.
+func expectDelim(dec *json.Decoder, d json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != d {
+		return fmt.Errorf("expected %v, got %v", d, tok)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpio
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+	"github.com/gomem/gomem/pkg/csv"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+// ReadDataFrame reads the DataFrame in the body of r, in the format of its
+// Content-Type header, decompressing it if its Content-Encoding is gzip.
+func ReadDataFrame(r *http.Request, opts ...Option) (*dataframe.DataFrame, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	format, err := FormatOf(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader = r.Body
+	switch enc := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); enc {
+	case "", "identity":
+	case "gzip":
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("httpio: could not read gzip body: %w", err)
+		}
+		defer zr.Close()
+		body = zr
+	default:
+		return nil, fmt.Errorf("httpio: unsupported content encoding %q", enc)
+	}
+
+	df, err := decode(cfg, body, format)
+	if err != nil {
+		return nil, fmt.Errorf("httpio: could not read %v: %w", format, err)
+	}
+	return df, nil
+}
+
+func decode(cfg *config, r io.Reader, format Format) (*dataframe.DataFrame, error) {
+	switch format {
+	case Arrow:
+		opts := []ipc.Option{ipc.WithAllocator(cfg.mem)}
+		if cfg.xforms != nil {
+			opts = append(opts, cfg.xforms.ReadOption(cfg.mem))
+		}
+		rdr, err := ipc.NewReader(r, opts...)
+		if err != nil {
+			return nil, err
+		}
+		defer rdr.Release()
+		return readRecords(cfg, rdr, rdr.Err)
+
+	case CSV:
+		rdr, err := csv.NewReader(r, csv.WithAllocator(cfg.mem))
+		if err != nil {
+			return nil, err
+		}
+		defer rdr.Release()
+		return readRecords(cfg, rdr, rdr.Err)
+
+	case JSON:
+		return readJSON(cfg.mem, r)
+
+	default:
+		return nil, fmt.Errorf("unknown format %v", format)
+	}
+}
+
+// readRecords returns a DataFrame holding the records of rdr.
+func readRecords(cfg *config, rdr array.RecordReader, errFn func() error) (*dataframe.DataFrame, error) {
+	var recs []array.Record
+	defer func() {
+		for _, rec := range recs {
+			rec.Release()
+		}
+	}()
+	for rdr.Next() {
+		rec := rdr.Record()
+		rec.Retain()
+		recs = append(recs, rec)
+	}
+	if err := errFn(); err != nil {
+		return nil, err
+	}
+
+	tbl := array.NewTableFromRecords(rdr.Schema(), recs)
+	defer tbl.Release()
+	return dataframe.NewDataFrameFromTable(cfg.mem, tbl)
+}
@@ -0,0 +1,100 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpio
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+	"github.com/gomem/gomem/pkg/csv"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+// WriteDataFrame writes df to w in format, setting the Content-Type header
+// and, with WithGzip, the Content-Encoding header.
+func WriteDataFrame(w http.ResponseWriter, df *dataframe.DataFrame, format Format, opts ...Option) error {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Add("Vary", "Accept, Accept-Encoding")
+	var body io.Writer = w
+	if cfg.gzip {
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		defer zw.Close()
+		body = zw
+	}
+	bw := bufio.NewWriter(body)
+
+	if err := encode(cfg, bw, df, format); err != nil {
+		return fmt.Errorf("httpio: could not write %v: %w", format, err)
+	}
+	return bw.Flush()
+}
+
+// ServeDataFrame writes df to w in the format negotiated with r, compressed
+// with gzip when r accepts it.
+func ServeDataFrame(w http.ResponseWriter, r *http.Request, df *dataframe.DataFrame) error {
+	return WriteDataFrame(w, df, NegotiateFormat(r), WithGzip(acceptsGzip(r)))
+}
+
+func encode(cfg *config, w io.Writer, df *dataframe.DataFrame, format Format) error {
+	switch format {
+	case Arrow:
+		rdr := array.NewTableReader(dataframe.NewTableFacade(df), -1)
+		defer rdr.Release()
+
+		opts := []ipc.Option{ipc.WithSchema(df.Schema()), ipc.WithAllocator(df.Allocator())}
+		if cfg.xforms != nil {
+			opts = append(opts, cfg.xforms.WriteOption(df.Allocator()))
+		}
+		iw := ipc.NewWriter(w, opts...)
+		for rdr.Next() {
+			if err := iw.Write(rdr.Record()); err != nil {
+				return err
+			}
+		}
+		return iw.Close()
+
+	case CSV:
+		rdr := array.NewTableReader(dataframe.NewTableFacade(df), -1)
+		defer rdr.Release()
+
+		cw, err := csv.NewWriter(w, df.Schema())
+		if err != nil {
+			return err
+		}
+		for rdr.Next() {
+			if err := cw.Write(rdr.Record()); err != nil {
+				return err
+			}
+		}
+		return cw.Flush()
+
+	case JSON:
+		return writeJSON(w, df)
+
+	default:
+		return fmt.Errorf("unknown format %v", format)
+	}
+}
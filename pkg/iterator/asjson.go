@@ -15,8 +15,8 @@
 package iterator
 
 import (
-	"github.com/apache/arrow/go/arrow/decimal128"
-	"github.com/apache/arrow/go/arrow/float16"
+	"github.com/gomem/gomem/arrow/go/arrow/decimal128"
+	"github.com/gomem/gomem/arrow/go/arrow/float16"
 	"github.com/gomem/gomem/pkg/object"
 )
 
@@ -111,6 +111,10 @@ func dayTimeIntervalAsJSON(v interface{}) (interface{}, error) {
 	return v, nil
 }
 
+func monthDayNanoIntervalAsJSON(v interface{}) (interface{}, error) {
+	return v, nil
+}
+
 func nullAsJSON(v interface{}) (interface{}, error) {
 	return nil, nil
 }
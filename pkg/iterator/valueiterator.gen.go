@@ -20,10 +20,10 @@ import (
 	"fmt"
 	"sync/atomic"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/array"
-	"github.com/apache/arrow/go/arrow/decimal128"
-	"github.com/apache/arrow/go/arrow/float16"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/decimal128"
+	"github.com/gomem/gomem/arrow/go/arrow/float16"
 	"github.com/gomem/gomem/internal/debug"
 )
 
@@ -92,6 +92,9 @@ func NewValueIterator(column *array.Column) ValueIterator {
 	case *arrow.Int8Type:
 		return NewInt8ValueIterator(column)
 
+	case *arrow.MonthDayNanoIntervalType:
+		return NewMonthDayNanoIntervalValueIterator(column)
+
 	case *arrow.MonthIntervalType:
 		return NewMonthIntervalValueIterator(column)
 
@@ -1700,6 +1703,136 @@ func (vr *Int8ValueIterator) Release() {
 	}
 }
 
+// MonthDayNanoIntervalValueIterator is an iterator for reading an Arrow Column value by value.
+type MonthDayNanoIntervalValueIterator struct {
+	refCount      int64
+	chunkIterator *MonthDayNanoIntervalChunkIterator
+
+	// Things we need to maintain for the iterator
+	index  int                          // current value index
+	values []arrow.MonthDayNanoInterval // current chunk values
+	ref    *array.MonthDayNanoInterval  // the chunk reference
+	done   bool                         // there are no more elements for this iterator
+
+	dataType arrow.DataType
+}
+
+// NewMonthDayNanoIntervalValueIterator creates a new MonthDayNanoIntervalValueIterator for reading an Arrow Column.
+func NewMonthDayNanoIntervalValueIterator(col *array.Column) *MonthDayNanoIntervalValueIterator {
+	// We need a ChunkIterator to read the chunks
+	chunkIterator := NewMonthDayNanoIntervalChunkIterator(col)
+
+	return &MonthDayNanoIntervalValueIterator{
+		refCount:      1,
+		chunkIterator: chunkIterator,
+
+		index:  0,
+		values: nil,
+
+		dataType: col.DataType(),
+	}
+}
+
+// Value will return the current value that the iterator is on and boolean value indicating if the value is actually null.
+func (vr *MonthDayNanoIntervalValueIterator) Value() (arrow.MonthDayNanoInterval, bool) {
+	return vr.values[vr.index], vr.ref.IsNull(vr.index)
+}
+
+// ValuePointer will return a pointer to the current value that the iterator is on. It will return nil if the value is actually null.
+func (vr *MonthDayNanoIntervalValueIterator) ValuePointer() *arrow.MonthDayNanoInterval {
+	if vr.ref.IsNull(vr.index) {
+		return nil
+	}
+	return &vr.values[vr.index]
+}
+
+// ValueInterface returns the current value as an interface{}.
+func (vr *MonthDayNanoIntervalValueIterator) ValueInterface() interface{} {
+	if vr.ref.IsNull(vr.index) {
+		return nil
+	}
+	return vr.values[vr.index]
+}
+
+// ValueAsJSON returns the current value as an interface{} in it's JSON representation.
+func (vr *MonthDayNanoIntervalValueIterator) ValueAsJSON() (interface{}, error) {
+	if vr.ref.IsNull(vr.index) {
+		return nil, nil
+	}
+	return monthDayNanoIntervalAsJSON(vr.values[vr.index])
+}
+
+func (vr *MonthDayNanoIntervalValueIterator) DataType() arrow.DataType {
+	return vr.dataType
+}
+
+// Next moves the iterator to the next value. This will return false
+// when there are no more values.
+func (vr *MonthDayNanoIntervalValueIterator) Next() bool {
+	if vr.done {
+		return false
+	}
+
+	// Move the index up
+	vr.index++
+
+	// Keep moving the chunk up until we get one with data
+	for vr.values == nil || vr.index >= len(vr.values) {
+		if !vr.nextChunk() {
+			// There were no more chunks with data in them
+			vr.done = true
+			return false
+		}
+	}
+
+	return true
+}
+
+func (vr *MonthDayNanoIntervalValueIterator) nextChunk() bool {
+	// Advance the chunk until we get one with data in it or we are done
+	if !vr.chunkIterator.Next() {
+		// No more chunks
+		return false
+	}
+
+	// There was another chunk.
+	// We maintain the ref and the values because the ref is going to allow us to retain the memory.
+	ref := vr.chunkIterator.Chunk()
+	ref.Retain()
+
+	if vr.ref != nil {
+		vr.ref.Release()
+	}
+
+	vr.ref = ref
+	vr.values = vr.chunkIterator.ChunkValues()
+	vr.index = 0
+	return true
+}
+
+// Retain keeps a reference to the MonthDayNanoIntervalValueIterator.
+func (vr *MonthDayNanoIntervalValueIterator) Retain() {
+	atomic.AddInt64(&vr.refCount, 1)
+}
+
+// Release removes a reference to the MonthDayNanoIntervalValueIterator.
+func (vr *MonthDayNanoIntervalValueIterator) Release() {
+	refs := atomic.AddInt64(&vr.refCount, -1)
+	debug.Assert(refs >= 0, "too many releases")
+	if refs == 0 {
+		if vr.chunkIterator != nil {
+			vr.chunkIterator.Release()
+			vr.chunkIterator = nil
+		}
+
+		if vr.ref != nil {
+			vr.ref.Release()
+			vr.ref = nil
+		}
+		vr.values = nil
+	}
+}
+
 // MonthIntervalValueIterator is an iterator for reading an Arrow Column value by value.
 type MonthIntervalValueIterator struct {
 	refCount      int64
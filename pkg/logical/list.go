@@ -1,7 +1,7 @@
 package logical
 
 import (
-	"github.com/apache/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
 	"github.com/gomem/gomem/pkg/collection"
 	"github.com/gomem/gomem/pkg/object"
 )
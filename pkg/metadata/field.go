@@ -14,12 +14,23 @@
 
 package metadata
 
-import "github.com/apache/arrow/go/arrow"
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+)
 
 const (
-	originalTypeKey = "GOMEM_DATAFRAME_ORIGINAL_TYPE"
-	mapConstant     = "MAP"
-	logicalTypeKey  = "LogicalType"
+	originalTypeKey  = "GOMEM_DATAFRAME_ORIGINAL_TYPE"
+	schemaVersionKey = "GOMEM_SCHEMA_VERSION"
+	sortOrderKey     = "GOMEM_SORT_ORDER"
+	extensionNameKey = "ARROW:extension:name"
+	extensionDataKey = "ARROW:extension:metadata"
+	transformKey     = "GOMEM_TRANSFORM"
+	categoriesKey    = "GOMEM_CATEGORIES"
+	mapConstant      = "MAP"
+	logicalTypeKey   = "LogicalType"
 )
 
 func AppendOriginalTypeMetadata(metadata arrow.Metadata, value string) arrow.Metadata {
@@ -55,3 +66,89 @@ func metadataValue(metadata arrow.Metadata, key string) (string, bool) {
 	// 根据 idx 取出 value
 	return metadata.Values()[idx], true
 }
+
+// WithSchemaVersion returns metadata with its schema version set to version.
+func WithSchemaVersion(metadata arrow.Metadata, version int) arrow.Metadata {
+	return withValue(metadata, schemaVersionKey, strconv.Itoa(version))
+}
+
+// SchemaVersion returns the schema version stored in metadata, if any.
+func SchemaVersion(metadata arrow.Metadata) (int, bool) {
+	value, ok := metadataValue(metadata, schemaVersionKey)
+	if !ok {
+		return 0, false
+	}
+	version, err := strconv.Atoi(value)
+	return version, err == nil
+}
+
+// WithSortOrder returns metadata recording that the rows are sorted by the
+// values of the field in order, such as "ascending" or "descending".
+func WithSortOrder(metadata arrow.Metadata, order string) arrow.Metadata {
+	return withValue(metadata, sortOrderKey, order)
+}
+
+// SortOrder returns the sort order stored in metadata, if any.
+func SortOrder(metadata arrow.Metadata) (string, bool) {
+	return metadataValue(metadata, sortOrderKey)
+}
+
+// WithExtension returns metadata marking the field as holding values of the
+// extension type name, with its serialized parameters in data. The keys are
+// the ones of the Arrow columnar format, so other implementations see the type.
+func WithExtension(metadata arrow.Metadata, name, data string) arrow.Metadata {
+	return withValue(withValue(metadata, extensionNameKey, name), extensionDataKey, data)
+}
+
+// Extension returns the name and the parameters of the extension type stored in metadata, if any.
+func Extension(metadata arrow.Metadata) (string, string, bool) {
+	name, ok := metadataValue(metadata, extensionNameKey)
+	if !ok {
+		return "", "", false
+	}
+	data, _ := metadataValue(metadata, extensionDataKey)
+	return name, data, true
+}
+
+// WithTransform returns metadata declaring that the values of the field are
+// encoded with the transform registered as name when they are written.
+func WithTransform(metadata arrow.Metadata, name string) arrow.Metadata {
+	return withValue(metadata, transformKey, name)
+}
+
+// Transform returns the name of the transform declared in metadata, if any.
+func Transform(metadata arrow.Metadata) (string, bool) {
+	return metadataValue(metadata, transformKey)
+}
+
+// withValue returns metadata with the value of key set to value.
+func WithCategories(metadata arrow.Metadata, categories []string) arrow.Metadata {
+	data, _ := json.Marshal(categories)
+	return withValue(metadata, categoriesKey, string(data))
+}
+
+func Categories(metadata arrow.Metadata) ([]string, bool) {
+	value, ok := metadataValue(metadata, categoriesKey)
+	if !ok {
+		return nil, false
+	}
+	var categories []string
+	if err := json.Unmarshal([]byte(value), &categories); err != nil {
+		return nil, false
+	}
+	return categories, true
+}
+
+func withValue(metadata arrow.Metadata, key, value string) arrow.Metadata {
+	keys := make([]string, 0, metadata.Len()+1)
+	values := make([]string, 0, metadata.Len()+1)
+	for i, k := range metadata.Keys() {
+		if k != key {
+			keys = append(keys, k)
+			values = append(values, metadata.Values()[i])
+		}
+	}
+	keys = append(keys, key)
+	values = append(values, value)
+	return arrow.NewMetadata(keys, values)
+}
@@ -0,0 +1,40 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package migrate upgrades DataFrames between versions of their schema.
+
+Users declare ordered migrations, each made of operations adding a column with
+a default value, renaming, retyping or dropping a column. Apply runs the
+migrations between two versions and stamps the resulting version into the
+metadata of every field, so a DataFrame records the version of its schema.
+
+	err := migrate.Register(
+		migrate.Migration{Version: 2, Ops: []migrate.Op{
+			migrate.AddColumn("country", arrow.BinaryTypes.String, "unknown"),
+		}},
+		migrate.Migration{Version: 3, Ops: []migrate.Op{
+			migrate.RenameColumn("ts", "timestamp"),
+			migrate.RetypeColumn("amount", arrow.PrimitiveTypes.Float64),
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	df, err = migrate.Apply(df, 1, 3)
+
+Retyping uses compute.Cast, which fails rather than truncate a value that does
+not fit the new type.
+*/
+package migrate
@@ -0,0 +1,264 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/dataframe"
+	"github.com/gomem/gomem/pkg/metadata"
+)
+
+// Op is an operation changing the schema of a DataFrame.
+type Op interface {
+	// Apply returns df with the operation applied.
+	// The caller is responsible for releasing df and the returned DataFrame.
+	Apply(df *dataframe.DataFrame) (*dataframe.DataFrame, error)
+	String() string
+}
+
+// Migration is the list of operations upgrading a schema to Version.
+type Migration struct {
+	Version     int
+	Description string
+	Ops         []Op
+}
+
+// Set is an ordered set of migrations.
+type Set struct {
+	mu         sync.RWMutex
+	migrations []Migration
+}
+
+// NewSet returns a Set holding the given migrations.
+func NewSet(migrations ...Migration) (*Set, error) {
+	s := &Set{}
+	if err := s.Register(migrations...); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Register adds migrations to the set. Versions must be positive and unique.
+func (s *Set) Register(migrations ...Migration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[int]struct{}, len(s.migrations)+len(migrations))
+	for _, m := range s.migrations {
+		seen[m.Version] = struct{}{}
+	}
+	for _, m := range migrations {
+		if m.Version <= 0 {
+			return fmt.Errorf("migrate: invalid version %d", m.Version)
+		}
+		if _, dup := seen[m.Version]; dup {
+			return fmt.Errorf("migrate: version %d registered twice", m.Version)
+		}
+		seen[m.Version] = struct{}{}
+	}
+
+	s.migrations = append(s.migrations, migrations...)
+	sort.SliceStable(s.migrations, func(i, j int) bool {
+		return s.migrations[i].Version < s.migrations[j].Version
+	})
+	return nil
+}
+
+// Apply runs the migrations with a version in (from, to], in order, and
+// stamps to as the schema version of the result.
+// If df already records a schema version, it must be from.
+// The caller is responsible for releasing the returned DataFrame.
+func (s *Set) Apply(df *dataframe.DataFrame, from, to int) (*dataframe.DataFrame, error) {
+	if to < from {
+		return nil, fmt.Errorf("migrate: cannot migrate down from version %d to %d", from, to)
+	}
+	if version, ok := Version(df); ok && version != from {
+		return nil, fmt.Errorf("migrate: DataFrame is at version %d, not %d", version, from)
+	}
+
+	s.mu.RLock()
+	var todo []Migration
+	for _, m := range s.migrations {
+		if m.Version > from && m.Version <= to {
+			todo = append(todo, m)
+		}
+	}
+	s.mu.RUnlock()
+
+	df.Retain()
+	for _, m := range todo {
+		for _, op := range m.Ops {
+			next, err := op.Apply(df)
+			df.Release()
+			if err != nil {
+				return nil, fmt.Errorf("migrate: version %d: %s: %w", m.Version, op, err)
+			}
+			df = next
+		}
+	}
+	defer df.Release()
+
+	return stamp(df, to)
+}
+
+var defaultSet = &Set{}
+
+// Register adds migrations to the default set.
+func Register(migrations ...Migration) error {
+	return defaultSet.Register(migrations...)
+}
+
+// Apply runs the migrations of the default set from version from to version to.
+func Apply(df *dataframe.DataFrame, from, to int) (*dataframe.DataFrame, error) {
+	return defaultSet.Apply(df, from, to)
+}
+
+// Version returns the schema version stamped into the fields of df, if any.
+func Version(df *dataframe.DataFrame) (int, bool) {
+	for _, field := range df.Schema().Fields() {
+		if version, ok := metadata.SchemaVersion(field.Metadata); ok {
+			return version, true
+		}
+	}
+	return 0, false
+}
+
+// stamp returns df with version in the metadata of all its fields.
+func stamp(df *dataframe.DataFrame, version int) (*dataframe.DataFrame, error) {
+	dfCols := df.Columns()
+	cols := make([]array.Column, len(dfCols))
+	for i := range dfCols {
+		field := dfCols[i].Field()
+		field.Metadata = metadata.WithSchemaVersion(field.Metadata, version)
+		cols[i] = *array.NewColumn(field, dfCols[i].Data())
+	}
+	defer releaseColumns(cols)
+
+	return dataframe.NewDataFrameFromShape(df.Allocator(), cols, df.NumRows())
+}
+
+// AddColumn returns an Op appending a column holding value on every row.
+// A nil value adds a column of nulls.
+func AddColumn(name string, dtype arrow.DataType, value interface{}) Op {
+	return addColumn{name: name, dtype: dtype, value: value}
+}
+
+type addColumn struct {
+	name  string
+	dtype arrow.DataType
+	value interface{}
+}
+
+func (op addColumn) Apply(df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+	if df.Column(op.name) != nil {
+		return nil, fmt.Errorf("column %q already exists", op.name)
+	}
+	field := arrow.Field{Name: op.name, Type: op.dtype, Nullable: op.value == nil}
+	col, err := compute.Fill(df.Allocator(), field, op.value, int(df.NumRows()))
+	if err != nil {
+		return nil, err
+	}
+	defer col.Release()
+
+	cols := append(append([]array.Column(nil), df.Columns()...), *col)
+	return dataframe.NewDataFrameFromShape(df.Allocator(), cols, df.NumRows())
+}
+
+func (op addColumn) String() string {
+	return fmt.Sprintf("add column %q %s", op.name, op.dtype)
+}
+
+// RenameColumn returns an Op renaming the column from to to.
+func RenameColumn(from, to string) Op {
+	return renameColumn{from: from, to: to}
+}
+
+type renameColumn struct {
+	from, to string
+}
+
+func (op renameColumn) Apply(df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+	return df.Rename(map[string]string{op.from: op.to})
+}
+
+func (op renameColumn) String() string {
+	return fmt.Sprintf("rename column %q to %q", op.from, op.to)
+}
+
+// RetypeColumn returns an Op casting the column name to dtype.
+func RetypeColumn(name string, dtype arrow.DataType) Op {
+	return retypeColumn{name: name, dtype: dtype}
+}
+
+type retypeColumn struct {
+	name  string
+	dtype arrow.DataType
+}
+
+func (op retypeColumn) Apply(df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+	if df.Column(op.name) == nil {
+		return nil, fmt.Errorf("unknown column %q", op.name)
+	}
+	cols := append([]array.Column(nil), df.Columns()...)
+	for i := range cols {
+		if cols[i].Name() != op.name {
+			continue
+		}
+		col, err := compute.Cast(df.Allocator(), &cols[i], op.dtype)
+		if err != nil {
+			return nil, err
+		}
+		defer col.Release()
+		cols[i] = *col
+		break
+	}
+	return dataframe.NewDataFrameFromShape(df.Allocator(), cols, df.NumRows())
+}
+
+func (op retypeColumn) String() string {
+	return fmt.Sprintf("retype column %q to %s", op.name, op.dtype)
+}
+
+// DropColumn returns an Op removing the column name.
+func DropColumn(name string) Op {
+	return dropColumn{name: name}
+}
+
+type dropColumn struct {
+	name string
+}
+
+func (op dropColumn) Apply(df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+	if df.Column(op.name) == nil {
+		return nil, fmt.Errorf("unknown column %q", op.name)
+	}
+	return df.Drop(op.name)
+}
+
+func (op dropColumn) String() string {
+	return fmt.Sprintf("drop column %q", op.name)
+}
+
+func releaseColumns(cols []array.Column) {
+	for i := range cols {
+		cols[i].Release()
+	}
+}
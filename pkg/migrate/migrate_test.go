@@ -0,0 +1,135 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+func TestApply(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := dataframe.NewDataFrameFromMem(pool, dataframe.Dict{
+		"amount": []int64{10, 20, 30},
+		"legacy": []string{"a", "b", "c"},
+		"ts":     []int64{100, 200, 300},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	set, err := NewSet(
+		Migration{Version: 3, Ops: []Op{
+			RenameColumn("ts", "timestamp"),
+			RetypeColumn("amount", arrow.PrimitiveTypes.Float64),
+		}},
+		Migration{Version: 2, Ops: []Op{
+			AddColumn("country", arrow.BinaryTypes.String, "unknown"),
+			AddColumn("note", arrow.BinaryTypes.String, nil),
+		}},
+		Migration{Version: 4, Ops: []Op{DropColumn("legacy")}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Register(Migration{Version: 2}); err == nil {
+		t.Fatal("expected an error registering version 2 twice")
+	}
+
+	got, err := set.Apply(df, 1, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Release()
+
+	want := []string{
+		"amount: float64 [10 20 30]",
+		"timestamp: int64 [100 200 300]",
+		`country: utf8 ["unknown" "unknown" "unknown"]`,
+		"note: utf8 [(null) (null) (null)]",
+	}
+	cols := got.Columns()
+	if len(cols) != len(want) {
+		t.Fatalf("got columns %v, want %d columns", got.ColumnNames(), len(want))
+	}
+	for i := range cols {
+		s := fmt.Sprintf("%s: %s %v", cols[i].Name(), cols[i].DataType(), cols[i].Data().Chunk(0))
+		if s != want[i] {
+			t.Errorf("column %d: got %s, want %s", i, s, want[i])
+		}
+	}
+	if version, ok := Version(got); !ok || version != 4 {
+		t.Fatalf("got version %d (%v), want 4", version, ok)
+	}
+
+	// Migrating a stamped DataFrame checks its version.
+	if _, err := set.Apply(got, 2, 4); err == nil {
+		t.Fatal("expected an error migrating from the wrong version")
+	}
+	same, err := set.Apply(got, 4, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer same.Release()
+	if same.NumCols() != got.NumCols() {
+		t.Fatalf("got %d columns, want %d", same.NumCols(), got.NumCols())
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := dataframe.NewDataFrameFromMem(pool, dataframe.Dict{
+		"s": []string{"1", "x"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	for _, tc := range []struct {
+		op   Op
+		want string
+	}{
+		{RetypeColumn("s", arrow.PrimitiveTypes.Int64), `migrate: version 1: retype column "s" to int64: compute: Cast of column "s" to int64: strconv.ParseInt: parsing "x": invalid syntax`},
+		{AddColumn("s", arrow.PrimitiveTypes.Int64, 0), `migrate: version 1: add column "s" int64: column "s" already exists`},
+		{DropColumn("t"), `migrate: version 1: drop column "t": unknown column "t"`},
+	} {
+		set, err := NewSet(Migration{Version: 1, Ops: []Op{tc.op}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = set.Apply(df, 0, 1)
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("got error %v, want %s", err, tc.want)
+		}
+	}
+
+	set, err := NewSet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := set.Apply(df, 2, 1); err == nil {
+		t.Fatal("expected an error migrating down")
+	}
+}
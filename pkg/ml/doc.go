@@ -0,0 +1,47 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package ml splits DataFrames into the sets used to train and evaluate models.
+
+Split holds out a random fraction of the rows as a test set, optionally
+stratified on a label column so that both sets keep the share of every label:
+
+	train, test, err := ml.Split(df, 0.2, 42, "label")
+	if err != nil {
+		return err
+	}
+	defer train.Release()
+	defer test.Release()
+
+KFold partitions the rows into k folds for cross-validation, every fold
+serving once as the test set while the others make the training set:
+
+	folds, err := ml.KFold(df, 5, 42)
+	if err != nil {
+		return err
+	}
+	for _, fold := range folds {
+		score := evaluate(fold.Train, fold.Test)
+		...
+		fold.Release()
+	}
+
+The same seed always makes the same sets, and the rows of every set keep their
+order. SplitIndices and KFoldIndices return the row indices of the sets
+instead, to be taken from several aligned DataFrames with DataFrame.Take.
+Scalers of the scale package are fitted on the training set only, then
+applied to both.
+*/
+package ml
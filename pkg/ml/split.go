@@ -0,0 +1,167 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ml
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+// SplitIndices draws the fraction frac of the rows of df as a test set and
+// returns the indices of the other rows, the training set, and of the test
+// rows, both in increasing order. With a stratifyCol the fraction is drawn
+// from every group of equal values of that column, see
+// compute.StratifiedSampleIndices; an empty stratifyCol draws from all the
+// rows. The same seed draws the same rows.
+func SplitIndices(df *dataframe.DataFrame, frac float64, seed int64, stratifyCol string) (train, test *array.Int64, err error) {
+	mem := df.Allocator()
+	if stratifyCol == "" {
+		n, err := compute.SampleFraction(df.NumRows(), frac)
+		if err != nil {
+			return nil, nil, err
+		}
+		test, err = compute.SampleIndices(mem, df.NumRows(), n, seed)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		col := df.Column(stratifyCol)
+		if col == nil {
+			return nil, nil, fmt.Errorf("ml: column %q not found: (%v)", stratifyCol, df.ColumnNames())
+		}
+		test, err = compute.StratifiedSampleIndices(mem, []*array.Column{col}, frac, seed)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return complement(mem, df.NumRows(), test.Int64Values()), test, nil
+}
+
+// Split is SplitIndices returning the rows of the training and test sets.
+func Split(df *dataframe.DataFrame, frac float64, seed int64, stratifyCol string) (train, test *dataframe.DataFrame, err error) {
+	trainRows, testRows, err := SplitIndices(df, frac, seed, stratifyCol)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer trainRows.Release()
+	defer testRows.Release()
+	return takeSets(df, trainRows, testRows)
+}
+
+// Fold is a training set and a test set of KFold.
+type Fold struct {
+	Train *dataframe.DataFrame
+	Test  *dataframe.DataFrame
+}
+
+// Release releases the DataFrames of f.
+func (f Fold) Release() {
+	f.Train.Release()
+	f.Test.Release()
+}
+
+// KFoldIndices partitions rows rows at random into k folds of sizes differing
+// by at most one row and returns the indices of the rows of every fold, in
+// increasing order. The training set of a fold is made of the rows of the
+// other folds. The same seed makes the same folds.
+func KFoldIndices(mem memory.Allocator, rows int64, k int, seed int64) ([]*array.Int64, error) {
+	if k < 2 || int64(k) > rows {
+		return nil, fmt.Errorf("ml: cannot make %d folds out of %d rows", k, rows)
+	}
+	perm := rand.New(rand.NewSource(seed)).Perm(int(rows))
+	folds := make([]*array.Int64, k)
+	for i := range folds {
+		beg, end := int64(i)*rows/int64(k), int64(i+1)*rows/int64(k)
+		fold := make([]int64, 0, end-beg)
+		for _, row := range perm[beg:end] {
+			fold = append(fold, int64(row))
+		}
+		sort.Slice(fold, func(a, b int) bool { return fold[a] < fold[b] })
+		folds[i] = newIndices(mem, fold)
+	}
+	return folds, nil
+}
+
+// KFold is KFoldIndices returning the training and test sets of every fold of
+// the rows of df.
+func KFold(df *dataframe.DataFrame, k int, seed int64) ([]Fold, error) {
+	mem := df.Allocator()
+	tests, err := KFoldIndices(mem, df.NumRows(), k, seed)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, test := range tests {
+			test.Release()
+		}
+	}()
+
+	folds := make([]Fold, 0, k)
+	for _, testRows := range tests {
+		trainRows := complement(mem, df.NumRows(), testRows.Int64Values())
+		train, test, err := takeSets(df, trainRows, testRows)
+		trainRows.Release()
+		if err != nil {
+			for _, fold := range folds {
+				fold.Release()
+			}
+			return nil, err
+		}
+		folds = append(folds, Fold{Train: train, Test: test})
+	}
+	return folds, nil
+}
+
+// takeSets returns the rows of df of the training and test sets.
+func takeSets(df *dataframe.DataFrame, trainRows, testRows *array.Int64) (train, test *dataframe.DataFrame, err error) {
+	train, err = df.Take(trainRows)
+	if err != nil {
+		return nil, nil, err
+	}
+	test, err = df.Take(testRows)
+	if err != nil {
+		train.Release()
+		return nil, nil, err
+	}
+	return train, test, nil
+}
+
+// complement returns the indices of the rows out of total that are not in
+// the increasing rows.
+func complement(mem memory.Allocator, total int64, rows []int64) *array.Int64 {
+	others := make([]int64, 0, total-int64(len(rows)))
+	j := 0
+	for row := int64(0); row < total; row++ {
+		if j < len(rows) && rows[j] == row {
+			j++
+			continue
+		}
+		others = append(others, row)
+	}
+	return newIndices(mem, others)
+}
+
+func newIndices(mem memory.Allocator, rows []int64) *array.Int64 {
+	bldr := array.NewInt64Builder(mem)
+	defer bldr.Release()
+	bldr.AppendValues(rows, nil)
+	return bldr.NewInt64Array()
+}
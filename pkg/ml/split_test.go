@@ -0,0 +1,173 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ml
+
+import (
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+func newFrame(t *testing.T, mem memory.Allocator, n int) *dataframe.DataFrame {
+	t.Helper()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "label", Type: arrow.BinaryTypes.String},
+	}, nil)
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+	for i := 0; i < n; i++ {
+		b.Field(0).(*array.Int64Builder).Append(int64(i))
+		// One row in five is labeled "b".
+		label := "a"
+		if i%5 == 0 {
+			label = "b"
+		}
+		b.Field(1).(*array.StringBuilder).Append(label)
+	}
+	rec := b.NewRecord()
+	defer rec.Release()
+	df, err := dataframe.NewDataFrameFromRecord(mem, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return df
+}
+
+// ids returns the ids of the rows of df.
+func ids(df *dataframe.DataFrame) []int64 {
+	var ids []int64
+	for _, chunk := range df.Column("id").Data().Chunks() {
+		ids = append(ids, chunk.(*array.Int64).Int64Values()...)
+	}
+	return ids
+}
+
+func countLabel(df *dataframe.DataFrame, label string) int {
+	n := 0
+	for _, chunk := range df.Column("label").Data().Chunks() {
+		arr := chunk.(*array.String)
+		for i := 0; i < arr.Len(); i++ {
+			if arr.Value(i) == label {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// checkPartition checks that the ids of the sets are increasing and together
+// hold every row of a frame of n rows exactly once.
+func checkPartition(t *testing.T, n int, sets ...*dataframe.DataFrame) {
+	t.Helper()
+	seen := make([]bool, n)
+	for _, set := range sets {
+		prev := int64(-1)
+		for _, id := range ids(set) {
+			if id <= prev {
+				t.Fatalf("ids are not increasing: %v", ids(set))
+			}
+			if seen[id] {
+				t.Fatalf("row %d is in several sets", id)
+			}
+			seen[id], prev = true, id
+		}
+	}
+	for id, ok := range seen {
+		if !ok {
+			t.Fatalf("row %d is in no set", id)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df := newFrame(t, pool, 40)
+	defer df.Release()
+
+	for _, stratifyCol := range []string{"", "label"} {
+		train, test, err := Split(df, 0.25, 7, stratifyCol)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := test.NumRows(), int64(10); got != want {
+			t.Errorf("stratify=%q: got %d test rows, want %d", stratifyCol, got, want)
+		}
+		checkPartition(t, 40, train, test)
+		if stratifyCol != "" {
+			if got, want := countLabel(test, "b"), 2; got != want {
+				t.Errorf("got %d test rows labeled b, want %d", got, want)
+			}
+		}
+
+		again, againTest, err := Split(df, 0.25, 7, stratifyCol)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := ids(againTest), ids(test); len(got) != len(want) || got[0] != want[0] || got[len(got)-1] != want[len(want)-1] {
+			t.Errorf("stratify=%q: the same seed drew %v then %v", stratifyCol, want, got)
+		}
+		for _, df := range []*dataframe.DataFrame{train, test, again, againTest} {
+			df.Release()
+		}
+	}
+
+	if _, _, err := Split(df, 1.5, 7, ""); err == nil {
+		t.Fatal("expected an error for a fraction above 1")
+	}
+	if _, _, err := Split(df, 0.2, 7, "missing"); err == nil {
+		t.Fatal("expected an error for a missing stratify column")
+	}
+}
+
+func TestKFold(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df := newFrame(t, pool, 11)
+	defer df.Release()
+
+	folds, err := KFold(df, 3, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(folds), 3; got != want {
+		t.Fatalf("got %d folds, want %d", got, want)
+	}
+	tests := make([]*dataframe.DataFrame, len(folds))
+	for i, fold := range folds {
+		if n := fold.Test.NumRows(); n < 3 || n > 4 {
+			t.Errorf("fold %d: got %d test rows, want 3 or 4", i, n)
+		}
+		checkPartition(t, 11, fold.Train, fold.Test)
+		tests[i] = fold.Test
+	}
+	checkPartition(t, 11, tests...)
+	for _, fold := range folds {
+		fold.Release()
+	}
+
+	if _, err := KFold(df, 1, 7); err == nil {
+		t.Fatal("expected an error for a single fold")
+	}
+	if _, err := KFold(df, 12, 7); err == nil {
+		t.Fatal("expected an error for more folds than rows")
+	}
+}
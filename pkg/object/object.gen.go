@@ -20,33 +20,34 @@ import (
 	"fmt"
 	"math"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/decimal128"
-	"github.com/apache/arrow/go/arrow/float16"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/decimal128"
+	"github.com/gomem/gomem/arrow/go/arrow/float16"
 )
 
 var (
-	MaxBoolean         = Boolean(true)
-	MaxDate32          = Date32(math.MaxInt32)
-	MaxDate64          = Date64(math.MaxInt64)
-	MaxDayTimeInterval = DayTimeInterval(arrow.DayTimeInterval{Days: math.MaxInt32, Milliseconds: math.MaxInt32})
-	MaxDecimal128      = Decimal128(decimal128.MaxDecimal128)
-	MaxDuration        = Duration(math.MaxInt64)
-	MaxFloat16         = Float16(float16.New(65504))
-	MaxFloat32         = Float32(math.MaxFloat32)
-	MaxFloat64         = Float64(math.MaxFloat64)
-	MaxInt16           = Int16(math.MaxInt16)
-	MaxInt32           = Int32(math.MaxInt32)
-	MaxInt64           = Int64(math.MaxInt64)
-	MaxInt8            = Int8(math.MaxInt8)
-	MaxMonthInterval   = MonthInterval(math.MaxInt32)
-	MaxTime32          = Time32(math.MaxInt32)
-	MaxTime64          = Time64(math.MaxInt64)
-	MaxTimestamp       = Timestamp(math.MaxInt64)
-	MaxUint16          = Uint16(math.MaxUint16)
-	MaxUint32          = Uint32(math.MaxUint32)
-	MaxUint64          = Uint64(math.MaxUint64)
-	MaxUint8           = Uint8(math.MaxUint8)
+	MaxBoolean              = Boolean(true)
+	MaxDate32               = Date32(math.MaxInt32)
+	MaxDate64               = Date64(math.MaxInt64)
+	MaxDayTimeInterval      = DayTimeInterval(arrow.DayTimeInterval{Days: math.MaxInt32, Milliseconds: math.MaxInt32})
+	MaxDecimal128           = Decimal128(decimal128.MaxDecimal128)
+	MaxDuration             = Duration(math.MaxInt64)
+	MaxFloat16              = Float16(float16.New(65504))
+	MaxFloat32              = Float32(math.MaxFloat32)
+	MaxFloat64              = Float64(math.MaxFloat64)
+	MaxInt16                = Int16(math.MaxInt16)
+	MaxInt32                = Int32(math.MaxInt32)
+	MaxInt64                = Int64(math.MaxInt64)
+	MaxInt8                 = Int8(math.MaxInt8)
+	MaxMonthDayNanoInterval = MonthDayNanoInterval(arrow.MonthDayNanoInterval{Months: math.MaxInt32, Days: math.MaxInt32, Nanoseconds: math.MaxInt64})
+	MaxMonthInterval        = MonthInterval(math.MaxInt32)
+	MaxTime32               = Time32(math.MaxInt32)
+	MaxTime64               = Time64(math.MaxInt64)
+	MaxTimestamp            = Timestamp(math.MaxInt64)
+	MaxUint16               = Uint16(math.MaxUint16)
+	MaxUint32               = Uint32(math.MaxUint32)
+	MaxUint64               = Uint64(math.MaxUint64)
+	MaxUint8                = Uint8(math.MaxUint8)
 )
 
 type CastableToBoolean interface {
@@ -140,6 +141,12 @@ func CastToBoolean(v interface{}) (Boolean, bool) {
 	case Int8:
 		t := pt
 		return Boolean(t != 0), true
+	case *MonthDayNanoInterval:
+		t := *pt
+		return Boolean(t.Months != 0 || t.Days != 0 || t.Nanoseconds != 0), true
+	case MonthDayNanoInterval:
+		t := pt
+		return Boolean(t.Months != 0 || t.Days != 0 || t.Nanoseconds != 0), true
 	case *MonthInterval:
 		t := *pt
 		return Boolean(t != 0), true
@@ -486,6 +493,24 @@ func (t Boolean) ToInt8() Int8 {
 	return Int8(0)
 }
 
+// ToMonthDayNanoIntervalChecked attempts to cast the Boolean to a MonthDayNanoInterval.
+// The second return value will be false if there was an overflow or any other loss of precision.
+// For example, going from Float64 to Int64 will result in anything after the decimal being truncated.
+func (t Boolean) ToMonthDayNanoIntervalChecked() (MonthDayNanoInterval, Boolean) {
+	return t.ToMonthDayNanoInterval(), true
+}
+
+// ToMonthDayNanoInterval attempts to cast the Boolean to a MonthDayNanoInterval.
+// The result is not checked for an overflow.
+// Use the ToMonthDayNanoIntervalChecked version of this method if you need to check for
+// an overflow.
+func (t Boolean) ToMonthDayNanoInterval() MonthDayNanoInterval {
+	if t {
+		return MonthDayNanoInterval(arrow.MonthDayNanoInterval{Months: 0, Days: 0, Nanoseconds: 1})
+	}
+	return MonthDayNanoInterval(arrow.MonthDayNanoInterval{Months: 0, Days: 0, Nanoseconds: 0})
+}
+
 // ToMonthIntervalChecked attempts to cast the Boolean to a MonthInterval.
 // The second return value will be false if there was an overflow or any other loss of precision.
 // For example, going from Float64 to Int64 will result in anything after the decimal being truncated.
@@ -5916,6 +5941,173 @@ func (t Int8) ToUint8() Uint8 {
 	return Uint8(t)
 }
 
+type CastableToMonthDayNanoInterval interface {
+	ToMonthDayNanoIntervalChecked() (MonthDayNanoInterval, Boolean)
+	ToMonthDayNanoInterval() MonthDayNanoInterval
+}
+
+// CastToMonthDayNanoInterval takes an interface{} type or any Object type and
+// attempts to convert it to the MonthDayNanoInterval Object type.
+func CastToMonthDayNanoInterval(v interface{}) (MonthDayNanoInterval, bool) {
+	switch pt := v.(type) {
+	case *MonthDayNanoInterval:
+		return *pt, true
+	case MonthDayNanoInterval:
+		return pt, true
+	case *arrow.MonthDayNanoInterval:
+		t := *pt
+		return MonthDayNanoInterval(t), true
+	case arrow.MonthDayNanoInterval:
+		t := pt
+		return MonthDayNanoInterval(t), true
+	case *Boolean:
+		t := *pt
+		return func(t Boolean) MonthDayNanoInterval {
+			if t {
+				return MonthDayNanoInterval(arrow.MonthDayNanoInterval{Months: 0, Days: 0, Nanoseconds: 1})
+			}
+			return MonthDayNanoInterval(arrow.MonthDayNanoInterval{Months: 0, Days: 0, Nanoseconds: 0})
+		}(t), true
+	case Boolean:
+		t := pt
+		return func(t Boolean) MonthDayNanoInterval {
+			if t {
+				return MonthDayNanoInterval(arrow.MonthDayNanoInterval{Months: 0, Days: 0, Nanoseconds: 1})
+			}
+			return MonthDayNanoInterval(arrow.MonthDayNanoInterval{Months: 0, Days: 0, Nanoseconds: 0})
+		}(t), true
+	default:
+		// Also handles when v is nil
+		return MonthDayNanoInterval(arrow.MonthDayNanoInterval{Months: 0, Days: 0, Nanoseconds: 0}), false
+	}
+}
+
+// NewMonthDayNanoInterval creates a new MonthDayNanoInterval object
+// from the given value provided as v.
+func NewMonthDayNanoInterval(v arrow.MonthDayNanoInterval) MonthDayNanoInterval {
+	return MonthDayNanoInterval(v)
+}
+
+// MonthDayNanoInterval has logic to apply to this type.
+type MonthDayNanoInterval arrow.MonthDayNanoInterval
+
+// Value returns the underlying value in it's native type.
+func (e MonthDayNanoInterval) Value() arrow.MonthDayNanoInterval {
+	return arrow.MonthDayNanoInterval(e)
+}
+
+// compare takes the left and right objects and applies the comparator function to them.
+func (e MonthDayNanoInterval) compareTypes(r Object, f func(arrow.MonthDayNanoInterval, arrow.MonthDayNanoInterval) Boolean) (Boolean, error) {
+	if r == nil {
+		return Boolean(false), nil
+	}
+
+	switch right := r.(type) {
+	case MonthDayNanoInterval:
+		return f(e.Value(), right.Value()), nil
+	case *MonthDayNanoInterval:
+		return f(e.Value(), right.Value()), nil
+	default:
+		return false, fmt.Errorf("cannot cast %T (%#v) to object.MonthDayNanoInterval", r, r)
+	}
+}
+
+// Comparation methods
+
+// Eq returns true if the left MonthDayNanoInterval is equal to the right MonthDayNanoInterval.
+func (e MonthDayNanoInterval) Eq(r Object) (Boolean, error) {
+	return e.compareTypes(r, func(left, right arrow.MonthDayNanoInterval) Boolean {
+		return Boolean(left.Months == right.Months && left.Days == right.Days && left.Nanoseconds == right.Nanoseconds)
+	})
+}
+
+// Neq returns true if the left MonthDayNanoInterval
+// is not equal to the right MonthDayNanoInterval.
+func (e MonthDayNanoInterval) Neq(r Object) (Boolean, error) {
+	v, err := e.Eq(r)
+	if err != nil {
+		return Boolean(false), err
+	}
+	return !v, err
+}
+
+// Less returns true if the left MonthDayNanoInterval
+// is less than the right MonthDayNanoInterval.
+func (e MonthDayNanoInterval) Less(r Object) (Boolean, error) {
+	return e.compareTypes(r, func(left, right arrow.MonthDayNanoInterval) Boolean {
+		return Boolean(left.Months < right.Months && left.Days < right.Days && left.Nanoseconds < right.Nanoseconds)
+	})
+}
+
+// LessEq returns true if the left MonthDayNanoInterval
+// is less than or equal to the right MonthDayNanoInterval.
+func (e MonthDayNanoInterval) LessEq(r Object) (Boolean, error) {
+	return e.compareTypes(r, func(left, right arrow.MonthDayNanoInterval) Boolean {
+		return Boolean(left.Months <= right.Months && left.Days <= right.Days && left.Nanoseconds <= right.Nanoseconds)
+	})
+}
+
+// Greater returns true if the left MonthDayNanoInterval
+// is greter than the right MonthDayNanoInterval.
+func (e MonthDayNanoInterval) Greater(r Object) (Boolean, error) {
+	return e.compareTypes(r, func(left, right arrow.MonthDayNanoInterval) Boolean {
+		return Boolean(left.Months > right.Months && left.Days > right.Days && left.Nanoseconds > right.Nanoseconds)
+	})
+}
+
+// GreaterEq returns true if the left MonthDayNanoInterval
+// is greter than or equal to the right MonthDayNanoInterval.
+func (e MonthDayNanoInterval) GreaterEq(r Object) (Boolean, error) {
+	return e.compareTypes(r, func(left, right arrow.MonthDayNanoInterval) Boolean {
+		return Boolean(left.Months >= right.Months && left.Days >= right.Days && left.Nanoseconds >= right.Nanoseconds)
+	})
+}
+
+// ToBooleanChecked attempts to cast the MonthDayNanoInterval to a Boolean.
+// The second return value will be false if there was an overflow or any other loss of precision.
+// For example, going from Float64 to Int64 will result in anything after the decimal being truncated.
+func (t MonthDayNanoInterval) ToBooleanChecked() (Boolean, Boolean) {
+	return t.ToBoolean(), true
+}
+
+// ToBoolean attempts to cast the MonthDayNanoInterval to a Boolean.
+// The result is not checked for an overflow.
+// Use the ToBooleanChecked version of this method if you need to check for
+// an overflow.
+func (t MonthDayNanoInterval) ToBoolean() Boolean {
+	return Boolean(t.Months != 0 || t.Days != 0 || t.Nanoseconds != 0)
+}
+
+// ToMonthDayNanoIntervalChecked attempts to cast the MonthDayNanoInterval to a MonthDayNanoInterval.
+// The second return value will be false if there was an overflow or any other loss of precision.
+// For example, going from Float64 to Int64 will result in anything after the decimal being truncated.
+func (t MonthDayNanoInterval) ToMonthDayNanoIntervalChecked() (MonthDayNanoInterval, Boolean) {
+	return t.ToMonthDayNanoInterval(), true
+}
+
+// ToMonthDayNanoInterval attempts to cast the MonthDayNanoInterval to a MonthDayNanoInterval.
+// The result is not checked for an overflow.
+// Use the ToMonthDayNanoIntervalChecked version of this method if you need to check for
+// an overflow.
+func (t MonthDayNanoInterval) ToMonthDayNanoInterval() MonthDayNanoInterval {
+	return t
+}
+
+// ToStringChecked attempts to cast the MonthDayNanoInterval to a String.
+// The second return value will be false if there was an overflow or any other loss of precision.
+// For example, going from Float64 to Int64 will result in anything after the decimal being truncated.
+func (t MonthDayNanoInterval) ToStringChecked() (String, Boolean) {
+	return t.ToString(), true
+}
+
+// ToString attempts to cast the MonthDayNanoInterval to a String.
+// The result is not checked for an overflow.
+// Use the ToStringChecked version of this method if you need to check for
+// an overflow.
+func (t MonthDayNanoInterval) ToString() String {
+	return String(fmt.Sprintf("%#v", t))
+}
+
 type CastableToMonthInterval interface {
 	ToMonthIntervalChecked() (MonthInterval, Boolean)
 	ToMonthInterval() MonthInterval
@@ -6201,6 +6393,12 @@ func CastToString(v interface{}) (String, bool) {
 	case Int8:
 		t := pt
 		return String(fmt.Sprintf("%d", t)), true
+	case *MonthDayNanoInterval:
+		t := *pt
+		return String(fmt.Sprintf("%#v", t)), true
+	case MonthDayNanoInterval:
+		t := pt
+		return String(fmt.Sprintf("%#v", t)), true
 	case *MonthInterval:
 		t := *pt
 		return String(fmt.Sprintf("%d", t)), true
@@ -9784,6 +9982,7 @@ var (
 	_ Object = (*Int32)(nil)
 	_ Object = (*Int64)(nil)
 	_ Object = (*Int8)(nil)
+	_ Object = (*MonthDayNanoInterval)(nil)
 	_ Object = (*MonthInterval)(nil)
 	_ Object = (*String)(nil)
 	_ Object = (*Time32)(nil)
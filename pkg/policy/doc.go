@@ -0,0 +1,52 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package policy restricts the rows and columns of the DataFrames a service
+serves to the identity of each caller, so a multi-tenant service can hold a
+single frame per dataset and serve it safely.
+
+A Policy holds a row filter, a boolean expression selecting the rows the caller
+may see, and column masks, expressions replacing the values of the columns the
+caller may not see. Both are built from the Identity of the caller, and a
+Registry holds the policy of every dataset:
+
+	reg := policy.NewRegistry()
+	err := reg.Register("orders", policy.Policy{
+		Rows: func(id policy.Identity) (expr.Expr, error) {
+			return expr.IsIn(expr.Col("tenant"), id.Attributes["tenant"]), nil
+		},
+		Masks: map[string]policy.ColumnMask{
+			"email": func(id policy.Identity) (expr.Expr, error) {
+				if id.HasRole("support") {
+					return nil, nil
+				}
+				return policy.Redact("email", "***"), nil
+			},
+		},
+	})
+
+Services call Apply with the identity they authenticated before sending a
+frame, for instance with grpcio or httpio:
+
+	view, err := reg.Apply(id, "orders", orders)
+	if err != nil {
+		return err
+	}
+	defer view.Release()
+	return httpio.ServeDataFrame(w, r, view)
+
+Datasets without a registered policy are not served.
+*/
+package policy
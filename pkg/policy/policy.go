@@ -0,0 +1,167 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/dataframe"
+	"github.com/gomem/gomem/pkg/expr"
+)
+
+// Identity is the caller a DataFrame is served to.
+type Identity struct {
+	// Subject identifies the caller, such as a user or a service account.
+	Subject string
+	// Roles are the roles granted to the caller.
+	Roles []string
+	// Attributes are the other claims of the caller, such as its tenant.
+	Attributes map[string]string
+}
+
+// HasRole reports whether role is granted to id.
+func (id Identity) HasRole(role string) bool {
+	for _, r := range id.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// RowFilter returns the boolean expression selecting the rows id may see, or
+// nil when id may see every row. Rows where the expression is null are hidden.
+type RowFilter func(id Identity) (expr.Expr, error)
+
+// ColumnMask returns the expression replacing the values of a column for id,
+// or nil when id may see the values.
+type ColumnMask func(id Identity) (expr.Expr, error)
+
+// Policy restricts the rows and columns of a dataset.
+type Policy struct {
+	// Rows filters the rows, every row is visible when nil.
+	Rows RowFilter
+	// Masks maps the names of the masked columns to their mask.
+	Masks map[string]ColumnMask
+}
+
+// Registry holds the policies of the datasets. It is safe for concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	policies map[string]Policy
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{policies: make(map[string]Policy)}
+}
+
+// Register makes p the policy of dataset. Registering a dataset twice is an error.
+func (r *Registry) Register(dataset string, p Policy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.policies[dataset]; ok {
+		return fmt.Errorf("policy: dataset %q is already registered", dataset)
+	}
+	r.policies[dataset] = p
+	return nil
+}
+
+// Lookup returns the policy of dataset.
+func (r *Registry) Lookup(dataset string) (Policy, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.policies[dataset]
+	return p, ok
+}
+
+// Apply returns the rows and columns of df, the frame of dataset, that id may
+// see. Masks are evaluated against the filtered rows, in the order of the names
+// of the columns. The caller releases the returned DataFrame.
+func (r *Registry) Apply(id Identity, dataset string, df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+	p, ok := r.Lookup(dataset)
+	if !ok {
+		return nil, fmt.Errorf("policy: no policy for dataset %q", dataset)
+	}
+	return p.Apply(id, df)
+}
+
+// Apply returns the rows and columns of df that id may see.
+// The caller releases the returned DataFrame.
+func (p Policy) Apply(id Identity, df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+	var filter expr.Expr
+	if p.Rows != nil {
+		e, err := p.Rows(id)
+		if err != nil {
+			return nil, fmt.Errorf("policy: row filter of %q: %w", id.Subject, err)
+		}
+		filter = e
+	}
+
+	names := make([]string, 0, len(p.Masks))
+	for name := range p.Masks {
+		if df.Column(name) == nil {
+			return nil, fmt.Errorf("policy: masked column %q is not in DataFrame: (%v)", name, df.ColumnNames())
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := df
+	out.Retain()
+	if filter != nil {
+		filtered, err := out.Filter(filter)
+		out.Release()
+		if err != nil {
+			return nil, fmt.Errorf("policy: %w", err)
+		}
+		out = filtered
+	}
+
+	for _, name := range names {
+		mask, err := p.Masks[name](id)
+		if err != nil {
+			out.Release()
+			return nil, fmt.Errorf("policy: mask of column %q: %w", name, err)
+		}
+		if mask == nil {
+			continue
+		}
+		masked, err := out.WithColumn(name, mask)
+		out.Release()
+		if err != nil {
+			return nil, fmt.Errorf("policy: %w", err)
+		}
+		out = masked
+	}
+	return out, nil
+}
+
+// Redact returns a mask replacing every value of the named column by value,
+// keeping the type of the column, or by null when value is nil.
+func Redact(column string, value interface{}) expr.Expr {
+	return expr.Call(fmt.Sprintf("redact[%v]", value), func(mem memory.Allocator, args []*array.Column) (*array.Column, error) {
+		field := args[0].Field()
+		if value == nil {
+			field.Nullable = true
+		}
+		return compute.Fill(mem, field, value, args[0].Len())
+	}, expr.Col(column))
+}
@@ -0,0 +1,159 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/dataframe"
+	"github.com/gomem/gomem/pkg/expr"
+)
+
+func newOrders(t *testing.T, mem memory.Allocator) *dataframe.DataFrame {
+	t.Helper()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "tenant", Type: arrow.BinaryTypes.String},
+		{Name: "email", Type: arrow.BinaryTypes.String},
+		{Name: "total", Type: arrow.PrimitiveTypes.Int64},
+	}, nil)
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+	b.Field(0).(*array.StringBuilder).AppendValues([]string{"acme", "globex", "acme"}, nil)
+	b.Field(1).(*array.StringBuilder).AppendValues([]string{"a@acme.com", "g@globex.com", "b@acme.com"}, nil)
+	b.Field(2).(*array.Int64Builder).AppendValues([]int64{10, 20, 30}, nil)
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	df, err := dataframe.NewDataFrameFromRecord(mem, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return df
+}
+
+func TestApply(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df := newOrders(t, pool)
+	defer df.Release()
+
+	reg := NewRegistry()
+	err := reg.Register("orders", Policy{
+		Rows: func(id Identity) (expr.Expr, error) {
+			if id.HasRole("admin") {
+				return nil, nil
+			}
+			return expr.IsIn(expr.Col("tenant"), id.Attributes["tenant"]), nil
+		},
+		Masks: map[string]ColumnMask{
+			"email": func(id Identity) (expr.Expr, error) {
+				if id.HasRole("support") || id.HasRole("admin") {
+					return nil, nil
+				}
+				return Redact("email", "***"), nil
+			},
+			"total": func(id Identity) (expr.Expr, error) {
+				if id.HasRole("admin") {
+					return nil, nil
+				}
+				return Redact("total", nil), nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Register("orders", Policy{}); err == nil {
+		t.Fatal("registered a dataset twice")
+	}
+
+	for _, tc := range []struct {
+		name string
+		id   Identity
+		want string
+	}{
+		{
+			name: "user",
+			id:   Identity{Subject: "u1", Attributes: map[string]string{"tenant": "acme"}},
+			want: `rec[0]["tenant"]: ["acme" "acme"]
+rec[0]["email"]: ["***" "***"]
+rec[0]["total"]: [(null) (null)]
+`,
+		},
+		{
+			name: "support",
+			id:   Identity{Subject: "s1", Roles: []string{"support"}, Attributes: map[string]string{"tenant": "globex"}},
+			want: `rec[0]["tenant"]: ["globex"]
+rec[0]["email"]: ["g@globex.com"]
+rec[0]["total"]: [(null)]
+`,
+		},
+		{
+			name: "admin",
+			id:   Identity{Subject: "root", Roles: []string{"admin"}},
+			want: `rec[0]["tenant"]: ["acme" "globex" "acme"]
+rec[0]["email"]: ["a@acme.com" "g@globex.com" "b@acme.com"]
+rec[0]["total"]: [10 20 30]
+`,
+		},
+		{
+			name: "no tenant",
+			id:   Identity{Subject: "u2"},
+			want: "",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			view, err := reg.Apply(tc.id, "orders", df)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer view.Release()
+			if got := view.Display(-1); got != tc.want {
+				t.Fatalf("\ngot=\n%v\nwant=\n%v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df := newOrders(t, pool)
+	defer df.Release()
+
+	reg := NewRegistry()
+	if err := reg.Register("bad", Policy{Masks: map[string]ColumnMask{
+		"ssn": func(Identity) (expr.Expr, error) { return Redact("ssn", nil), nil },
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		dataset string
+		want    string
+	}{
+		{"orders", `policy: no policy for dataset "orders"`},
+		{"bad", `policy: masked column "ssn" is not in DataFrame: ([tenant email total])`},
+	} {
+		if _, err := reg.Apply(Identity{}, tc.dataset, df); err == nil || err.Error() != tc.want {
+			t.Errorf("apply %q: got error %v, want %q", tc.dataset, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package prommetrics exposes aggregates of a DataFrame as Prometheus metrics.
+
+A Registry evaluates its metrics over the DataFrame returned by its Source
+every time it is scraped, and writes them in the Prometheus text exposition
+format. A metric aggregates an expression, optionally per group of label
+columns:
+
+	reg := prommetrics.NewRegistry(prommetrics.FromDataFrame(orders))
+	err := reg.Register(prommetrics.Metric{
+		Name:      "orders_amount_total",
+		Help:      "Total amount of the orders by region.",
+		Value:     expr.Col("amount"),
+		Aggregate: compute.AggSum,
+		Labels:    []string{"region"},
+	})
+	http.Handle("/metrics", reg)
+
+The source may build a new DataFrame on each scrape, for instance from the
+records of an iterator with FromRecords, so that the metrics follow live data.
+*/
+package prommetrics
@@ -0,0 +1,132 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prommetrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/dataframe"
+	"github.com/gomem/gomem/pkg/expr"
+)
+
+func TestRegistry(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := dataframe.NewDataFrameFromMem(pool, dataframe.Dict{
+		"region": []string{"east", "west", "east", "north \"n\""},
+		"amount": []float64{1.5, 2, 3, 0.25},
+		"items":  []int64{1, 4, 2, 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	reg := NewRegistry(FromDataFrame(df))
+	for _, m := range []Metric{
+		{Name: "orders", Help: "Number of orders.", Aggregate: compute.AggCount},
+		{Name: "orders_amount_total", Help: "Amount of the orders\nby region.", Type: Counter, Value: expr.Col("amount"), Aggregate: compute.AggSum, Labels: []string{"region"}},
+		{Name: "orders_items_max", Value: expr.Col("items"), Aggregate: compute.AggMax, Labels: []string{"region"}},
+	} {
+		if err := reg.Register(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, m := range []Metric{
+		{Name: "orders", Aggregate: compute.AggCount},
+		{Name: "bad-name", Aggregate: compute.AggCount},
+		{Name: "ok", Aggregate: compute.AggCount, Labels: []string{"__reserved"}},
+		{Name: "ok", Aggregate: compute.AggSum},
+	} {
+		if err := reg.Register(m); err == nil {
+			t.Errorf("expected an error registering %+v", m)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	reg.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if got := rec.Header().Get("Content-Type"); got != ContentType {
+		t.Fatalf("invalid content type: got=%q, want=%q", got, ContentType)
+	}
+
+	want := `# HELP orders Number of orders.
+# TYPE orders gauge
+orders 4
+# HELP orders_amount_total Amount of the orders\nby region.
+# TYPE orders_amount_total counter
+orders_amount_total{region="east"} 4.5
+orders_amount_total{region="north \"n\""} 0.25
+orders_amount_total{region="west"} 2
+# TYPE orders_items_max gauge
+orders_items_max{region="east"} 2
+orders_items_max{region="north \"n\""} 3
+orders_items_max{region="west"} 4
+`
+	if got := rec.Body.String(); got != want {
+		t.Fatalf("invalid exposition:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	bad := NewRegistry(FromDataFrame(df))
+	if err := bad.Register(Metric{Name: "x", Aggregate: compute.AggCount, Labels: []string{"missing"}}); err != nil {
+		t.Fatal(err)
+	}
+	rec = httptest.NewRecorder()
+	bad.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("invalid status: got=%d, want=%d", rec.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestFromRecords(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := dataframe.NewDataFrameFromMem(pool, dataframe.Dict{
+		"v": []int64{1, 2, 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	calls := 0
+	reg := NewRegistry(FromRecords(pool, func() (array.RecordReader, error) {
+		calls++
+		return array.NewTableReader(dataframe.NewTableFacade(df), 2), nil
+	}))
+	if err := reg.Register(Metric{Name: "v_mean", Value: expr.Col("v"), Aggregate: compute.AggMean}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		families, err := reg.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := families[0].Samples; len(got) != 1 || got[0].Value != 2 {
+			t.Fatalf("invalid samples: %+v", got)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("invalid number of source calls: got=%d, want=2", calls)
+	}
+}
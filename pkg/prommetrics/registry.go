@@ -0,0 +1,350 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prommetrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/float16"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/dataframe"
+	"github.com/gomem/gomem/pkg/expr"
+	"github.com/gomem/gomem/pkg/iterator"
+)
+
+// ContentType is the content type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Type is the Prometheus type of a metric.
+type Type int
+
+const (
+	// Gauge is a value that can go up and down. It is the default.
+	Gauge Type = iota
+	// Counter is a value that only goes up, such as a total.
+	Counter
+)
+
+func (t Type) String() string {
+	switch t {
+	case Gauge:
+		return "gauge"
+	case Counter:
+		return "counter"
+	default:
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+}
+
+// Metric is an aggregate of the rows of a DataFrame.
+type Metric struct {
+	// Name is the name of the metric.
+	Name string
+	// Help describes the metric.
+	Help string
+	// Type is the type of the metric.
+	Type Type
+	// Value is the aggregated expression. It may be nil to count the rows.
+	Value expr.Expr
+	// Aggregate is the aggregation of the values.
+	Aggregate compute.AggregateKind
+	// Labels are the columns the rows are grouped by, a sample per group.
+	Labels []string
+}
+
+var (
+	metricName = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelName  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Registry evaluates registered metrics over the DataFrame of a Source.
+// It is an http.Handler serving the metrics.
+type Registry struct {
+	source Source
+
+	mu      sync.RWMutex
+	metrics []Metric
+}
+
+// NewRegistry returns an empty Registry over source.
+func NewRegistry(source Source) *Registry {
+	return &Registry{source: source}
+}
+
+// Register adds m to the metrics of the registry.
+func (r *Registry) Register(m Metric) error {
+	if !metricName.MatchString(m.Name) {
+		return fmt.Errorf("prommetrics: invalid metric name %q", m.Name)
+	}
+	for _, label := range m.Labels {
+		if !labelName.MatchString(label) || strings.HasPrefix(label, "__") {
+			return fmt.Errorf("prommetrics: metric %s: invalid label name %q", m.Name, label)
+		}
+	}
+	if m.Value == nil && m.Aggregate != compute.AggCount {
+		return fmt.Errorf("prommetrics: metric %s: %v needs a value", m.Name, m.Aggregate)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, other := range r.metrics {
+		if other.Name == m.Name {
+			return fmt.Errorf("prommetrics: metric %s is already registered", m.Name)
+		}
+	}
+	r.metrics = append(r.metrics, m)
+	return nil
+}
+
+// Sample is a value of a metric.
+type Sample struct {
+	// Labels are the label names and values, in the order of Metric.Labels.
+	Labels [][2]string
+	Value  float64
+}
+
+// Family is a metric and its samples.
+type Family struct {
+	Metric  Metric
+	Samples []Sample
+}
+
+// Gather evaluates the metrics over the DataFrame of the source.
+func (r *Registry) Gather() ([]Family, error) {
+	r.mu.RLock()
+	metrics := append([]Metric(nil), r.metrics...)
+	r.mu.RUnlock()
+
+	df, err := r.source()
+	if err != nil {
+		return nil, fmt.Errorf("prommetrics: could not get DataFrame: %w", err)
+	}
+	defer df.Release()
+
+	families := make([]Family, 0, len(metrics))
+	for _, m := range metrics {
+		samples, err := evaluate(df, m)
+		if err != nil {
+			return nil, fmt.Errorf("prommetrics: metric %s: %w", m.Name, err)
+		}
+		families = append(families, Family{Metric: m, Samples: samples})
+	}
+	return families, nil
+}
+
+// WriteTo writes the metrics to w in the text exposition format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	families, err := r.Gather()
+	if err != nil {
+		return 0, err
+	}
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	for _, f := range families {
+		if f.Metric.Help != "" {
+			fmt.Fprintf(cw, "# HELP %s %s\n", f.Metric.Name, helpEscaper.Replace(f.Metric.Help))
+		}
+		fmt.Fprintf(cw, "# TYPE %s %v\n", f.Metric.Name, f.Metric.Type)
+		for _, s := range f.Samples {
+			cw.WriteString(f.Metric.Name)
+			if len(s.Labels) > 0 {
+				cw.WriteString("{")
+				for i, l := range s.Labels {
+					if i > 0 {
+						cw.WriteString(",")
+					}
+					fmt.Fprintf(cw, "%s=\"%s\"", l[0], labelEscaper.Replace(l[1]))
+				}
+				cw.WriteString("}")
+			}
+			fmt.Fprintf(cw, " %s\n", formatValue(s.Value))
+		}
+	}
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	return cw.n, cw.w.(*bufio.Writer).Flush()
+}
+
+// ServeHTTP serves the metrics in the text exposition format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var buf strings.Builder
+	if _, err := r.WriteTo(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", ContentType)
+	io.WriteString(w, buf.String())
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	w.err = err
+	return n, err
+}
+
+func (w *countingWriter) WriteString(s string) {
+	w.Write([]byte(s))
+}
+
+// evaluate returns the samples of m over df, sorted by labels. Groups with
+// a null aggregate, such as the minimum of null values, have no sample.
+func evaluate(df *dataframe.DataFrame, m Metric) ([]Sample, error) {
+	mem := df.Allocator()
+
+	var value *array.Column
+	if m.Value != nil {
+		var err error
+		if value, err = m.Value.Eval(mem, df); err != nil {
+			return nil, err
+		}
+		defer value.Release()
+	}
+
+	keys := make([]*array.Column, len(m.Labels))
+	for i, label := range m.Labels {
+		if keys[i] = df.Column(label); keys[i] == nil {
+			return nil, fmt.Errorf("unknown label column %q", label)
+		}
+	}
+
+	groups := &compute.Groups{IDs: make([]int32, df.NumRows()), First: []int64{0}}
+	if len(keys) > 0 {
+		var err error
+		if groups, err = compute.GroupRows(keys...); err != nil {
+			return nil, err
+		}
+	}
+
+	agg, err := compute.AggregateGroups(mem, value, groups, m.Aggregate)
+	if err != nil {
+		return nil, err
+	}
+	defer agg.Release()
+
+	cols := []array.Column{*agg}
+	if len(keys) > 0 {
+		bldr := array.NewInt64Builder(mem)
+		bldr.AppendValues(groups.First, nil)
+		first := bldr.NewInt64Array()
+		bldr.Release()
+		defer first.Release()
+
+		for _, key := range keys {
+			col, err := compute.Take(mem, key, first)
+			if err != nil {
+				return nil, err
+			}
+			defer col.Release()
+			cols = append(cols, *col)
+		}
+	}
+
+	it := iterator.NewStepIteratorForColumns(cols)
+	defer it.Release()
+
+	var samples []Sample
+	for it.Next() {
+		values := it.Values().Values
+		if values[0] == nil {
+			continue
+		}
+		v, err := toFloat(values[0])
+		if err != nil {
+			return nil, err
+		}
+		s := Sample{Value: v, Labels: make([][2]string, len(m.Labels))}
+		for i, label := range m.Labels {
+			s.Labels[i] = [2]string{label, ""}
+			if values[i+1] != nil {
+				s.Labels[i][1] = fmt.Sprint(values[i+1])
+			}
+		}
+		samples = append(samples, s)
+	}
+
+	sort.Slice(samples, func(i, j int) bool {
+		a, b := samples[i].Labels, samples[j].Labels
+		for k := range a {
+			if a[k][1] != b[k][1] {
+				return a[k][1] < b[k][1]
+			}
+		}
+		return false
+	})
+	return samples, nil
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case float16.Num:
+		return float64(v.Float32()), nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	default:
+		return 0, fmt.Errorf("value %v of type %T is not numeric", v, v)
+	}
+}
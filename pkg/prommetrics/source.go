@@ -0,0 +1,65 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prommetrics
+
+import (
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+// Source returns the DataFrame the metrics are evaluated over. The
+// DataFrame is released once the metrics are evaluated.
+type Source func() (*dataframe.DataFrame, error)
+
+// FromDataFrame returns a Source always evaluating the metrics over df.
+// The caller keeps its reference on df, which must outlive the Registry.
+func FromDataFrame(df *dataframe.DataFrame) Source {
+	return func() (*dataframe.DataFrame, error) {
+		df.Retain()
+		return df, nil
+	}
+}
+
+// FromRecords returns a Source evaluating the metrics over the records
+// of the reader returned by open, read on every evaluation.
+func FromRecords(mem memory.Allocator, open func() (array.RecordReader, error)) Source {
+	return func() (*dataframe.DataFrame, error) {
+		rdr, err := open()
+		if err != nil {
+			return nil, err
+		}
+		defer rdr.Release()
+
+		var recs []array.Record
+		defer func() {
+			for _, rec := range recs {
+				rec.Release()
+			}
+		}()
+		for rdr.Next() {
+			rec := rdr.Record()
+			rec.Retain()
+			recs = append(recs, rec)
+		}
+		if r, ok := rdr.(interface{ Err() error }); ok && r.Err() != nil {
+			return nil, r.Err()
+		}
+
+		tbl := array.NewTableFromRecords(rdr.Schema(), recs)
+		defer tbl.Release()
+		return dataframe.NewDataFrameFromTable(mem, tbl)
+	}
+}
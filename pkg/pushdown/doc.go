@@ -0,0 +1,37 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package pushdown describes the columns and the rows a reader is asked for, so
+that it can skip the rest instead of reading everything and filtering after.
+
+ReadOptions holds a projection, the names of the columns to read, and a
+Filter, a Predicate on the values of the rows. Predicates are comparisons of
+a column with a constant, combined with And:
+
+	ro := pushdown.ReadOptions{
+		Columns: []string{"region", "units"},
+		Filter:  pushdown.And(pushdown.Compare("units", pushdown.Greater, 10), pushdown.Compare("region", pushdown.Equal, "east")),
+	}
+
+A reader holding statistics on its chunks, the minimum and maximum of their
+columns, skips the chunks for which Predicate.MayMatch is false. Others parse
+only the columns they need and filter the rows of every record with Apply.
+StatsOf takes the statistics of a chunk built with statistics enabled, see
+array.Statistics, from the chunk instead of scanning its values.
+
+Readers accepting ReadOptions implement Source. NewReader applies ReadOptions
+to any array.RecordReader.
+*/
+package pushdown
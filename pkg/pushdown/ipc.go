@@ -0,0 +1,85 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pushdown
+
+import (
+	"sync/atomic"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/internal/debug"
+)
+
+// IPCFile is a Source reading the record batches of an Arrow IPC file.
+// Batches are decoded whole, then filtered and projected one at a time, so
+// only one batch of unselected rows is held in memory.
+type IPCFile struct {
+	mem  memory.Allocator
+	r    ipc.ReadAtSeeker
+	opts []ipc.Option
+}
+
+// NewIPCFile returns the Source of the records of the IPC file r, decoded
+// with mem and the given options.
+func NewIPCFile(mem memory.Allocator, r ipc.ReadAtSeeker, opts ...ipc.Option) *IPCFile {
+	return &IPCFile{mem: mem, r: r, opts: opts}
+}
+
+// Read returns a reader of the records of the file with ro applied.
+func (f *IPCFile) Read(ro ReadOptions) (array.RecordReader, error) {
+	opts := append([]ipc.Option{ipc.WithAllocator(f.mem)}, f.opts...)
+	fr, err := ipc.NewFileReader(f.r, opts...)
+	if err != nil {
+		return nil, err
+	}
+	batches := &fileRecords{refs: 1, r: fr}
+	defer batches.Release()
+	return NewReader(f.mem, batches, ro)
+}
+
+// fileRecords reads the records of an IPC file as an array.RecordReader.
+type fileRecords struct {
+	refs int64
+	r    *ipc.FileReader
+	next int
+	rec  array.Record
+	err  error
+}
+
+func (f *fileRecords) Retain() { atomic.AddInt64(&f.refs, 1) }
+
+func (f *fileRecords) Release() {
+	debug.Assert(atomic.LoadInt64(&f.refs) > 0, "too many releases")
+	if atomic.AddInt64(&f.refs, -1) == 0 {
+		f.r.Close()
+	}
+}
+
+func (f *fileRecords) Schema() *arrow.Schema { return f.r.Schema() }
+
+func (f *fileRecords) Next() bool {
+	if f.err != nil || f.next >= f.r.NumRecords() {
+		return false
+	}
+	f.rec, f.err = f.r.Record(f.next)
+	f.next++
+	return f.err == nil
+}
+
+func (f *fileRecords) Record() array.Record { return f.rec }
+
+func (f *fileRecords) Err() error { return f.err }
@@ -0,0 +1,314 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pushdown
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/object"
+	"github.com/gomem/gomem/pkg/scalar"
+)
+
+// Op is a comparison operator.
+type Op int
+
+const (
+	Equal Op = iota
+	NotEqual
+	Less
+	LessEqual
+	Greater
+	GreaterEqual
+)
+
+func (op Op) String() string {
+	switch op {
+	case Equal:
+		return "="
+	case NotEqual:
+		return "!="
+	case Less:
+		return "<"
+	case LessEqual:
+		return "<="
+	case Greater:
+		return ">"
+	case GreaterEqual:
+		return ">="
+	default:
+		return fmt.Sprintf("Op(%d)", int(op))
+	}
+}
+
+// Predicate is a condition on the rows of a record.
+type Predicate interface {
+	// Columns returns the names of the columns the predicate reads.
+	Columns() []string
+	// Eval returns whether each row of rec satisfies the predicate. Rows
+	// where a compared value is null do not.
+	Eval(mem memory.Allocator, rec array.Record) (*array.Boolean, error)
+	// MayMatch reports whether a chunk with the given statistics may hold
+	// rows satisfying the predicate. It is true when the statistics of a
+	// column read by the predicate are missing.
+	MayMatch(stats Stats) bool
+	// String returns a readable form of the predicate.
+	String() string
+}
+
+// Compare returns the predicate comparing the values of column with value, a
+// bool, an integer, a float, a string, an object.Object or a scalar.Scalar.
+// value is cast to the type of the column when the predicate is evaluated.
+func Compare(column string, op Op, value interface{}) Predicate {
+	return &comparison{column: column, op: op, value: value}
+}
+
+type comparison struct {
+	column string
+	op     Op
+	value  interface{}
+}
+
+func (c *comparison) Columns() []string { return []string{c.column} }
+
+func (c *comparison) String() string {
+	if s, ok := c.value.(string); ok {
+		return fmt.Sprintf("%s %s %q", c.column, c.op, s)
+	}
+	return fmt.Sprintf("%s %s %v", c.column, c.op, c.value)
+}
+
+// target returns the value compared with, as an object of type dtype.
+func (c *comparison) target(dtype arrow.DataType) (object.Object, error) {
+	var o object.Object
+	switch v := c.value.(type) {
+	case scalar.Scalar:
+		o = v.Object()
+	case object.Object:
+		o = v
+	case bool:
+		o = object.NewBoolean(v)
+	case int:
+		o = object.NewInt64(int64(v))
+	case int32:
+		o = object.NewInt64(int64(v))
+	case int64:
+		o = object.NewInt64(v)
+	case uint32:
+		o = object.NewUint64(uint64(v))
+	case uint64:
+		o = object.NewUint64(v)
+	case float32:
+		o = object.NewFloat64(float64(v))
+	case float64:
+		o = object.NewFloat64(v)
+	case string:
+		o = object.NewString(v)
+	default:
+		return nil, fmt.Errorf("pushdown: unsupported value type %T in %s", c.value, c)
+	}
+	s, err := scalar.FromObject(dtype, o)
+	if err != nil {
+		return nil, fmt.Errorf("pushdown: %s: %w", c, err)
+	}
+	if !s.IsValid() {
+		return nil, fmt.Errorf("pushdown: %s compares with null", c)
+	}
+	return s.Object(), nil
+}
+
+// test returns the result of the comparison of v with target.
+func (c *comparison) test(v, target object.Object) (bool, error) {
+	var (
+		ok  object.Boolean
+		err error
+	)
+	switch c.op {
+	case Equal:
+		ok, err = v.Eq(target)
+	case NotEqual:
+		ok, err = v.Neq(target)
+	case Less:
+		ok, err = v.Less(target)
+	case LessEqual:
+		ok, err = v.LessEq(target)
+	case Greater:
+		ok, err = v.Greater(target)
+	case GreaterEqual:
+		ok, err = v.GreaterEq(target)
+	default:
+		return false, fmt.Errorf("pushdown: invalid operator %v", c.op)
+	}
+	return bool(ok), err
+}
+
+func (c *comparison) Eval(mem memory.Allocator, rec array.Record) (*array.Boolean, error) {
+	arr, err := column(rec, c.column)
+	if err != nil {
+		return nil, err
+	}
+	target, err := c.target(arr.DataType())
+	if err != nil {
+		return nil, err
+	}
+
+	bldr := array.NewBooleanBuilder(mem)
+	defer bldr.Release()
+	bldr.Reserve(arr.Len())
+	for i := 0; i < arr.Len(); i++ {
+		v, err := scalar.Get(arr, i)
+		if err != nil {
+			return nil, err
+		}
+		if !v.IsValid() {
+			bldr.Append(false)
+			continue
+		}
+		ok, err := c.test(v.Object(), target)
+		if err != nil {
+			return nil, fmt.Errorf("pushdown: %s: %w", c, err)
+		}
+		bldr.Append(ok)
+	}
+	return bldr.NewBooleanArray(), nil
+}
+
+func (c *comparison) MayMatch(stats Stats) bool {
+	cs, ok := stats[c.column]
+	if !ok {
+		return true
+	}
+	if cs.Nulls == cs.Rows {
+		// Null values never satisfy a comparison.
+		return false
+	}
+	if cs.Min == nil || cs.Max == nil || !cs.Min.IsValid() || !cs.Max.IsValid() {
+		return true
+	}
+	target, err := c.target(cs.Min.DataType())
+	if err != nil {
+		return true
+	}
+	min, max := cs.Min.Object(), cs.Max.Object()
+	below := func(a, b object.Object) bool { ok, err := a.Less(b); return err == nil && bool(ok) }
+	switch c.op {
+	case Equal:
+		return !below(target, min) && !below(max, target)
+	case NotEqual:
+		eqMin, err1 := min.Eq(target)
+		eqMax, err2 := max.Eq(target)
+		return err1 != nil || err2 != nil || !bool(eqMin && eqMax)
+	case Less:
+		return below(min, target)
+	case LessEqual:
+		return !below(target, min)
+	case Greater:
+		return below(target, max)
+	case GreaterEqual:
+		return !below(max, target)
+	default:
+		return true
+	}
+}
+
+// And returns the predicate satisfied by the rows satisfying all of preds.
+func And(preds ...Predicate) Predicate {
+	return and(preds)
+}
+
+type and []Predicate
+
+func (a and) Columns() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, p := range a {
+		for _, name := range p.Columns() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+func (a and) String() string {
+	parts := make([]string, len(a))
+	for i, p := range a {
+		parts[i] = p.String()
+	}
+	return strings.Join(parts, " AND ")
+}
+
+func (a and) Eval(mem memory.Allocator, rec array.Record) (*array.Boolean, error) {
+	if len(a) == 0 {
+		keep := make([]bool, rec.NumRows())
+		for i := range keep {
+			keep[i] = true
+		}
+		bldr := array.NewBooleanBuilder(mem)
+		defer bldr.Release()
+		bldr.AppendValues(keep, nil)
+		return bldr.NewBooleanArray(), nil
+	}
+
+	keep, err := a[0].Eval(mem, rec)
+	if err != nil {
+		return nil, err
+	}
+	// Null rows are dropped, so the masks are combined with nulls as false.
+	for _, p := range a[1:] {
+		mask, err := p.Eval(mem, rec)
+		if err != nil {
+			keep.Release()
+			return nil, err
+		}
+		combined, err := compute.And(mem, keep, mask, compute.NullAsFalse)
+		keep.Release()
+		mask.Release()
+		if err != nil {
+			return nil, err
+		}
+		keep = combined
+	}
+	if keep.NullN() > 0 {
+		// The mask of a single predicate may still hold nulls.
+		defer keep.Release()
+		return compute.And(mem, keep, keep, compute.NullAsFalse)
+	}
+	return keep, nil
+}
+
+func (a and) MayMatch(stats Stats) bool {
+	for _, p := range a {
+		if !p.MayMatch(stats) {
+			return false
+		}
+	}
+	return true
+}
+
+// column returns the column of rec named name.
+func column(rec array.Record, name string) (array.Interface, error) {
+	indices := rec.Schema().FieldIndices(name)
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("pushdown: unknown column %q", name)
+	}
+	return rec.Column(indices[0]), nil
+}
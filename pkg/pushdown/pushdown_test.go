@@ -0,0 +1,280 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pushdown
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/scalar"
+)
+
+var schema = arrow.NewSchema([]arrow.Field{
+	{Name: "region", Type: arrow.BinaryTypes.String},
+	{Name: "units", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+}, nil)
+
+func newRecord(mem memory.Allocator, regions []string, units []int32, valid []bool) array.Record {
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+	b.Field(0).(*array.StringBuilder).AppendValues(regions, nil)
+	b.Field(1).(*array.Int32Builder).AppendValues(units, valid)
+	return b.NewRecord()
+}
+
+func format(rec array.Record) string {
+	var s string
+	for i, field := range rec.Schema().Fields() {
+		s += fmt.Sprintf("%s: %v\n", field.Name, rec.Column(i))
+	}
+	return s
+}
+
+func TestApply(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	rec := newRecord(pool, []string{"east", "west", "east", "north"}, []int32{3, 5, 0, 7}, []bool{true, true, false, true})
+	defer rec.Release()
+
+	for _, tc := range []struct {
+		ro   ReadOptions
+		want string
+	}{
+		{ReadOptions{}, "region: [\"east\" \"west\" \"east\" \"north\"]\nunits: [3 5 (null) 7]\n"},
+		{ReadOptions{Columns: []string{"units"}}, "units: [3 5 (null) 7]\n"},
+		{ReadOptions{Filter: Compare("units", Greater, 3)}, "region: [\"west\" \"north\"]\nunits: [5 7]\n"},
+		{ReadOptions{Columns: []string{"region"}, Filter: Compare("units", LessEqual, 5.5)}, "region: [\"east\" \"west\"]\n"},
+		{ReadOptions{Filter: And(Compare("region", Equal, "east"), Compare("units", NotEqual, 4))}, "region: [\"east\"]\nunits: [3]\n"},
+		{ReadOptions{Columns: []string{}, Filter: Compare("region", Less, "north")}, ""},
+	} {
+		got, err := Apply(pool, rec, tc.ro)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := format(got); s != tc.want {
+			t.Errorf("%v: got:\n%s\nwant:\n%s", tc.ro, s, tc.want)
+		}
+		got.Release()
+	}
+
+	for _, ro := range []ReadOptions{
+		{Columns: []string{"price"}},
+		{Filter: Compare("price", Equal, 1)},
+		{Filter: Compare("units", Equal, "many")},
+	} {
+		if got, err := Apply(pool, rec, ro); err == nil {
+			got.Release()
+			t.Errorf("%v: expected an error", ro)
+		}
+	}
+}
+
+func TestMayMatch(t *testing.T) {
+	stats := Stats{
+		"units":  {Rows: 10, Nulls: 2, Min: scalar.NewInt32(3), Max: scalar.NewInt32(8)},
+		"region": {Rows: 10, Nulls: 10, Min: scalar.String{}, Max: scalar.String{}},
+	}
+	for _, tc := range []struct {
+		p    Predicate
+		want bool
+	}{
+		{Compare("units", Equal, 3), true},
+		{Compare("units", Equal, 9), false},
+		{Compare("units", NotEqual, 5), true},
+		{Compare("units", Less, 3), false},
+		{Compare("units", LessEqual, 3), true},
+		{Compare("units", Greater, 8), false},
+		{Compare("units", GreaterEqual, 8), true},
+		{Compare("region", Equal, "east"), false},
+		{Compare("price", Equal, 1), true},
+		{And(Compare("units", Greater, 4), Compare("units", Less, 2)), false},
+	} {
+		if got := tc.p.MayMatch(stats); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.p, got, tc.want)
+		}
+	}
+
+	if got := Compare("units", NotEqual, 4).MayMatch(Stats{"units": {Rows: 2, Min: scalar.NewInt32(4), Max: scalar.NewInt32(4)}}); got {
+		t.Errorf("!= on a constant chunk: got %v, want false", got)
+	}
+}
+
+func TestStatsOfCached(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "region", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "units", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		{Name: "price", Type: arrow.PrimitiveTypes.Float32},
+		{Name: "at", Type: &arrow.TimestampType{Unit: arrow.Millisecond}},
+		{Name: "flag", Type: arrow.FixedWidthTypes.Boolean},
+	}, nil)
+	b := array.NewRecordBuilder(pool, schema)
+	defer b.Release()
+	for _, f := range b.Fields() {
+		f.SetStatistics(true)
+	}
+	valid := []bool{true, false, true, true}
+	b.Field(0).(*array.StringBuilder).AppendValues([]string{"west", "", "east", "west"}, valid)
+	b.Field(1).(*array.Int32Builder).AppendValues([]int32{7, 0, -2, 7}, valid)
+	b.Field(2).(*array.Float32Builder).AppendValues([]float32{1.5, 0.25, 3, 1.5}, nil)
+	b.Field(3).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{30, 10, 20, 10}, nil)
+	b.Field(4).(*array.BooleanBuilder).AppendValues([]bool{true, true, true, true}, nil)
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	for i, col := range rec.Columns() {
+		name := rec.ColumnName(i)
+		cached, ok := array.Statistics(col)
+		if !ok {
+			t.Fatalf("%s: no cached statistics", name)
+		}
+		if cs, ok := CachedStats(col); !ok || cs.Min == nil {
+			t.Errorf("%s: cached statistics of %+v not usable", name, cached)
+		}
+		// A slice does not share the statistics of its array.
+		slice := array.NewSlice(col, 0, int64(col.Len()))
+		if _, ok := array.Statistics(slice); ok {
+			t.Errorf("%s: slice has cached statistics", name)
+		}
+		got, want := StatsOf(col), StatsOf(slice)
+		slice.Release()
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s: got stats=%v, want=%v", name, got, want)
+		}
+		if got, want := cached.DistinctN, []int64{2, 2, 3, 3, 1}[i]; got != want {
+			t.Errorf("%s: got distinct=%d, want=%d", name, got, want)
+		}
+	}
+
+	cached, _ := array.Statistics(rec.Column(1))
+	if cached.NullN != 1 || cached.Min != int64(-2) || cached.Max != int64(7) {
+		t.Errorf("got units stats=%+v", cached)
+	}
+	cached, _ = array.Statistics(rec.Column(4))
+	if cached.Min != true || cached.Max != true {
+		t.Errorf("got flag stats=%+v", cached)
+	}
+}
+
+func TestIPCFile(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	var buf bytes.Buffer
+	w, err := ipc.NewFileWriter(&fileBuffer{&buf}, ipc.WithSchema(schema), ipc.WithAllocator(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, units := range [][]int32{{1, 2}, {10, 20}, {3, 30}} {
+		rec := newRecord(pool, []string{"a", "b"}, units, nil)
+		if err := w.Write(rec); err != nil {
+			t.Fatal(err)
+		}
+		rec.Release()
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	src := NewIPCFile(pool, bytes.NewReader(buf.Bytes()))
+	r, err := src.Read(ReadOptions{Columns: []string{"region"}, Filter: Compare("units", GreaterEqual, 10)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+	var got string
+	for r.Next() {
+		got += format(r.Record())
+	}
+	if err := r.(*Reader).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "region: [\"a\" \"b\"]\nregion: [\"b\"]\n"; got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestIPCFileUTF8Validation(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	var buf bytes.Buffer
+	w, err := ipc.NewFileWriter(&fileBuffer{&buf}, ipc.WithSchema(schema), ipc.WithAllocator(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := newRecord(pool, []string{"a", "b\xffc"}, []int32{1, 2}, nil)
+	err = w.Write(rec)
+	rec.Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		policy array.UTF8Policy
+		want   string
+		err    string
+	}{
+		{policy: array.UTF8Unchecked, want: "region: [\"a\" \"b\\xffc\"]\n"},
+		{policy: array.UTF8Replace, want: "region: [\"a\" \"b\uFFFDc\"]\n"},
+		{policy: array.UTF8Error, err: `arrow/ipc: string 1 of column "region" is not valid UTF-8`},
+	} {
+		src := NewIPCFile(pool, bytes.NewReader(buf.Bytes()), ipc.WithUTF8Validation(tc.policy))
+		r, err := src.Read(ReadOptions{Columns: []string{"region"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		for r.Next() {
+			got += format(r.Record())
+		}
+		err = r.(*Reader).Err()
+		r.Release()
+		switch {
+		case tc.err == "" && err != nil:
+			t.Fatalf("%v: %v", tc.policy, err)
+		case tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)):
+			t.Fatalf("%v: got error %v, want %q", tc.policy, err, tc.err)
+		case got != tc.want:
+			t.Fatalf("%v: got:\n%s\nwant:\n%s", tc.policy, got, tc.want)
+		}
+	}
+}
+
+// fileBuffer is an io.WriteSeeker over a bytes.Buffer, which ipc.FileWriter
+// only seeks to find its position.
+type fileBuffer struct {
+	*bytes.Buffer
+}
+
+func (b *fileBuffer) Seek(offset int64, whence int) (int64, error) {
+	if offset != 0 || whence != io.SeekCurrent {
+		return 0, fmt.Errorf("cannot seek in buffer")
+	}
+	return int64(b.Len()), nil
+}
@@ -0,0 +1,251 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pushdown
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/internal/debug"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// ReadOptions are the columns and the rows asked to a reader.
+type ReadOptions struct {
+	// Columns holds the names of the columns to read, in order. All the
+	// columns are read when it is nil.
+	Columns []string
+	// Filter selects the rows to read. All the rows are read when it is nil.
+	Filter Predicate
+}
+
+// ReadColumns returns the columns a reader must read to apply ro: the
+// projected columns, followed by the columns only read by the filter. It is
+// nil when all the columns are projected.
+func (ro ReadOptions) ReadColumns() []string {
+	if ro.Columns == nil {
+		return nil
+	}
+	names := append([]string(nil), ro.Columns...)
+	if ro.Filter != nil {
+		for _, name := range ro.Filter.Columns() {
+			if !contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// Schema returns the schema of the records read from a source of the given
+// schema with ro.
+func (ro ReadOptions) Schema(schema *arrow.Schema) (*arrow.Schema, error) {
+	if ro.Filter != nil {
+		for _, name := range ro.Filter.Columns() {
+			if !schema.HasField(name) {
+				return nil, fmt.Errorf("pushdown: unknown column %q in filter %s", name, ro.Filter)
+			}
+		}
+	}
+	if ro.Columns == nil {
+		return schema, nil
+	}
+	fields := make([]arrow.Field, len(ro.Columns))
+	for i, name := range ro.Columns {
+		found, ok := schema.FieldsByName(name)
+		if !ok {
+			return nil, fmt.Errorf("pushdown: unknown column %q", name)
+		}
+		fields[i] = found[0]
+	}
+	meta := schema.Metadata()
+	return arrow.NewSchema(fields, &meta), nil
+}
+
+// Apply returns the rows of rec selected by the filter of ro, with the
+// columns of ro. The caller is responsible for releasing the returned Record.
+func Apply(mem memory.Allocator, rec array.Record, ro ReadOptions) (array.Record, error) {
+	schema, err := ro.Schema(rec.Schema())
+	if err != nil {
+		return nil, err
+	}
+	cols := make([]array.Interface, 0, len(schema.Fields()))
+	defer func() {
+		for _, col := range cols {
+			col.Release()
+		}
+	}()
+
+	if ro.Filter == nil {
+		for _, field := range schema.Fields() {
+			arr, _ := column(rec, field.Name)
+			arr.Retain()
+			cols = append(cols, arr)
+		}
+		return array.NewRecord(schema, cols, rec.NumRows()), nil
+	}
+
+	mask, err := ro.Filter.Eval(mem, rec)
+	if err != nil {
+		return nil, err
+	}
+	maskCol := singleChunkColumn("mask", mask)
+	mask.Release()
+	defer maskCol.Release()
+
+	rows := int64(-1)
+	for _, field := range schema.Fields() {
+		arr, _ := column(rec, field.Name)
+		col := singleChunkColumn(field.Name, arr)
+		filtered, err := compute.Filter(mem, col, maskCol)
+		col.Release()
+		if err != nil {
+			return nil, err
+		}
+		chunks := filtered.Data().Chunks()
+		if len(chunks) != 1 {
+			filtered.Release()
+			return nil, fmt.Errorf("pushdown: filtered column %q has %d chunks", field.Name, len(chunks))
+		}
+		chunks[0].Retain()
+		cols = append(cols, chunks[0])
+		rows = int64(chunks[0].Len())
+		filtered.Release()
+	}
+	if rows < 0 {
+		// No column projected, count the selected rows.
+		rows = 0
+		for _, ok := range maskValues(maskCol) {
+			if ok {
+				rows++
+			}
+		}
+	}
+	return array.NewRecord(schema, cols, rows), nil
+}
+
+// Source is a source of records that reads only what ReadOptions ask for.
+type Source interface {
+	// Read returns a reader of the records of the source, with ro applied.
+	Read(ro ReadOptions) (array.RecordReader, error)
+}
+
+// Reader applies ReadOptions to the records of another reader. The records
+// without selected rows are skipped.
+type Reader struct {
+	refs int64
+
+	mem    memory.Allocator
+	r      array.RecordReader
+	ro     ReadOptions
+	schema *arrow.Schema
+
+	rec array.Record
+	err error
+}
+
+// NewReader returns a Reader of the records of r with ro applied. The
+// Reader retains r.
+func NewReader(mem memory.Allocator, r array.RecordReader, ro ReadOptions) (*Reader, error) {
+	schema, err := ro.Schema(r.Schema())
+	if err != nil {
+		return nil, err
+	}
+	r.Retain()
+	return &Reader{refs: 1, mem: mem, r: r, ro: ro, schema: schema}, nil
+}
+
+// Retain increases the reference count by 1.
+// Retain may be called simultaneously from multiple goroutines.
+func (r *Reader) Retain() {
+	atomic.AddInt64(&r.refs, 1)
+}
+
+// Release decreases the reference count by 1.
+// When the reference count goes to zero, the memory is freed.
+// Release may be called simultaneously from multiple goroutines.
+func (r *Reader) Release() {
+	debug.Assert(atomic.LoadInt64(&r.refs) > 0, "too many releases")
+
+	if atomic.AddInt64(&r.refs, -1) == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+		r.r.Release()
+	}
+}
+
+// Schema returns the schema of the records.
+func (r *Reader) Schema() *arrow.Schema { return r.schema }
+
+// Record returns the current record. It is valid until the next call to Next.
+func (r *Reader) Record() array.Record { return r.rec }
+
+// Err returns the error that stopped the Reader, if any.
+func (r *Reader) Err() error { return r.err }
+
+// Next reads the next record holding selected rows. It returns false at the
+// end of the input or when an error occurs, see Err.
+func (r *Reader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+	if r.err != nil {
+		return false
+	}
+	for r.r.Next() {
+		rec, err := Apply(r.mem, r.r.Record(), r.ro)
+		if err != nil {
+			r.err = err
+			return false
+		}
+		if rec.NumRows() == 0 {
+			rec.Release()
+			continue
+		}
+		r.rec = rec
+		return true
+	}
+	if e, ok := r.r.(interface{ Err() error }); ok {
+		r.err = e.Err()
+	}
+	return false
+}
+
+func singleChunkColumn(name string, arr array.Interface) *array.Column {
+	chunked := array.NewChunked(arr.DataType(), []array.Interface{arr})
+	defer chunked.Release()
+	return array.NewColumn(arrow.Field{Name: name, Type: arr.DataType(), Nullable: true}, chunked)
+}
+
+func maskValues(mask *array.Column) []bool {
+	var values []bool
+	for _, chunk := range mask.Data().Chunks() {
+		b := chunk.(*array.Boolean)
+		for i := 0; i < b.Len(); i++ {
+			values = append(values, b.IsValid(i) && b.Value(i))
+		}
+	}
+	return values
+}
+
+var (
+	_ array.RecordReader = (*Reader)(nil)
+)
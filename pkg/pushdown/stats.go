@@ -0,0 +1,134 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pushdown
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/object"
+	"github.com/gomem/gomem/pkg/scalar"
+)
+
+// ColumnStats are the statistics of a column of a chunk.
+type ColumnStats struct {
+	// Rows is the number of rows of the chunk, Nulls the number of null values.
+	Rows, Nulls int64
+	// Min and Max are the smallest and the largest non-null values, nil when
+	// they are not known.
+	Min, Max scalar.Scalar
+}
+
+// Stats are the statistics of the columns of a chunk, by name.
+type Stats map[string]ColumnStats
+
+// StatsOf returns the statistics of arr. Min and Max are nil for the types
+// whose values are not ordered. The statistics cached on arr by its builder
+// are used instead of scanning its values when they have bounds, see
+// CachedStats.
+func StatsOf(arr array.Interface) ColumnStats {
+	if cs, ok := CachedStats(arr); ok && cs.Min != nil {
+		return cs
+	}
+	cs := ColumnStats{Rows: int64(arr.Len()), Nulls: int64(arr.NullN())}
+	chunked := array.NewChunked(arr.DataType(), []array.Interface{arr})
+	defer chunked.Release()
+	col := array.NewColumn(arrow.Field{Name: "stats", Type: arr.DataType(), Nullable: true}, chunked)
+	defer col.Release()
+
+	min, max, err := compute.MinMax(col)
+	if err != nil {
+		return cs
+	}
+	dtype := arr.DataType()
+	if ree, ok := dtype.(*arrow.RunEndEncodedType); ok {
+		dtype = ree.ValueType
+	}
+	if cs.Min, err = scalar.FromObject(dtype, min); err != nil {
+		cs.Min = nil
+		return cs
+	}
+	if cs.Max, err = scalar.FromObject(dtype, max); err != nil {
+		cs.Min, cs.Max = nil, nil
+	}
+	return cs
+}
+
+// CachedStats returns the statistics cached on arr by its builder, see
+// array.Statistics, without scanning its values. It reports false when arr
+// has none. Min and Max are nil when the cached bounds cannot be given as
+// scalars of the type of arr.
+func CachedStats(arr array.Interface) (ColumnStats, bool) {
+	cached, ok := array.Statistics(arr)
+	if !ok {
+		return ColumnStats{}, false
+	}
+	cs := ColumnStats{Rows: int64(arr.Len()), Nulls: int64(cached.NullN)}
+	if cached.Min == nil || cached.Max == nil {
+		return cs, true
+	}
+	bound := func(v interface{}) (scalar.Scalar, error) {
+		var o object.Object
+		switch v := v.(type) {
+		case int64:
+			o = object.NewInt64(v)
+		case uint64:
+			o = object.NewUint64(v)
+		case float64:
+			o = object.NewFloat64(v)
+		case bool:
+			o = object.NewBoolean(v)
+		case string:
+			o = object.NewString(v)
+		default:
+			return nil, fmt.Errorf("pushdown: no scalar for a bound of type %T", v)
+		}
+		return scalar.FromObject(arr.DataType(), o)
+	}
+	min, err := bound(cached.Min)
+	if err != nil {
+		return cs, true
+	}
+	max, err := bound(cached.Max)
+	if err != nil {
+		return cs, true
+	}
+	cs.Min, cs.Max = min, max
+	return cs, true
+}
+
+// RecordStats returns the statistics of the named columns of rec, of all its
+// columns when names is empty.
+func RecordStats(rec array.Record, names ...string) Stats {
+	stats := make(Stats)
+	for i, field := range rec.Schema().Fields() {
+		if len(names) > 0 && !contains(names, field.Name) {
+			continue
+		}
+		stats[field.Name] = StatsOf(rec.Column(i))
+	}
+	return stats
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
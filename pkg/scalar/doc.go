@@ -0,0 +1,32 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package scalar provides typed single values of Arrow data types.
+
+A Scalar is a value together with its data type and validity, so a null keeps
+the type it is a null of. There is one scalar type per data type supported by
+pkg/object, holding the value in its native Go type: kernels switch on the
+concrete type once and then read the value without going through
+interface{}.
+
+Scalars broadcast against arrays: MakeArray repeats a scalar to the length of
+the array it is combined with.
+
+	s := scalar.NewFloat64(1.5)
+	arr, err := scalar.MakeArray(mem, s, col.Len())
+
+Scalars convert to and from pkg/object with Object and FromObject.
+*/
+package scalar
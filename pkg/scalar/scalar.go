@@ -0,0 +1,113 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalar
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/object"
+)
+
+// Scalar is a single value of an Arrow data type, or a null of that type.
+type Scalar interface {
+	// DataType returns the type of the scalar.
+	DataType() arrow.DataType
+	// IsValid reports whether the scalar holds a value rather than a null.
+	IsValid() bool
+	// Object returns the value of the scalar as an object, object.Null if it
+	// is null.
+	Object() object.Object
+	fmt.Stringer
+
+	// appendTo appends the scalar n times to bldr, a builder of its type.
+	appendTo(bldr array.Builder, n int)
+}
+
+// Null is the scalar of the null type.
+type Null struct{}
+
+// DataType returns arrow.Null.
+func (Null) DataType() arrow.DataType { return arrow.Null }
+
+// IsValid returns false.
+func (Null) IsValid() bool { return false }
+
+// Object returns object.Null.
+func (Null) Object() object.Object { return object.NewNull() }
+
+func (Null) String() string { return object.STRING_VALUE }
+
+func (Null) appendTo(bldr array.Builder, n int) {
+	for i := 0; i < n; i++ {
+		bldr.AppendNull()
+	}
+}
+
+// Get returns the value at index i of arr as a scalar of the type of arr.
+// Dictionary and run-end encoded arrays give scalars of the type of their
+// values.
+func Get(arr array.Interface, i int) (Scalar, error) {
+	switch a := arr.(type) {
+	case *array.Null:
+		return Null{}, nil
+	case *array.Dictionary:
+		if a.IsNull(i) {
+			return MakeNull(a.DataType().(*arrow.DictionaryType).ValueType)
+		}
+		return Get(a.Dictionary(), a.GetValueIndex(i))
+	case *array.RunEndEncoded:
+		return Get(a.Values(), a.GetPhysicalIndex(i))
+	default:
+		return get(arr, i)
+	}
+}
+
+// FromObject returns the scalar of dtype holding o cast to dtype. A nil o or
+// an object.Null gives the null of dtype.
+func FromObject(dtype arrow.DataType, o object.Object) (Scalar, error) {
+	if dtype.ID() == arrow.NULL {
+		return Null{}, nil
+	}
+	return fromObject(dtype, o)
+}
+
+// MakeNull returns the null of dtype.
+func MakeNull(dtype arrow.DataType) (Scalar, error) {
+	return FromObject(dtype, nil)
+}
+
+// Append appends s to bldr, a builder of the type of s.
+func Append(bldr array.Builder, s Scalar) {
+	s.appendTo(bldr, 1)
+}
+
+// MakeArray broadcasts s to an array of n values all equal to s, the
+// caller must release it.
+func MakeArray(mem memory.Allocator, s Scalar, n int) (array.Interface, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("scalar: negative array length %d", n)
+	}
+	bldr := array.NewBuilder(mem, s.DataType())
+	defer bldr.Release()
+	s.appendTo(bldr, n)
+	return bldr.NewArray(), nil
+}
+
+var (
+	_ Scalar = Null{}
+)
@@ -0,0 +1,118 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalar
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/object"
+)
+
+func TestMakeArray(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	tsType := &arrow.TimestampType{Unit: arrow.Millisecond}
+	for _, tc := range []struct {
+		s    Scalar
+		want string
+	}{
+		{NewInt64(7), "[7 7 7]"},
+		{NewFloat32(1.5), "[1.5 1.5 1.5]"},
+		{NewBoolean(true), "[true true true]"},
+		{NewString("a"), `["a" "a" "a"]`},
+		{NewTimestamp(12, tsType), "[12 12 12]"},
+		{Uint8{}, "[(null) (null) (null)]"},
+		{Null{}, "[(null) (null) (null)]"},
+	} {
+		arr, err := MakeArray(pool, tc.s, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !arrow.TypeEqual(arr.DataType(), tc.s.DataType()) {
+			t.Errorf("%T: got type %s, want %s", tc.s, arr.DataType(), tc.s.DataType())
+		}
+		if got := fmt.Sprint(arr); got != tc.want {
+			t.Errorf("%T: got=%s, want=%s", tc.s, got, tc.want)
+		}
+		arr.Release()
+	}
+
+	if _, err := MakeArray(pool, NewInt8(1), -1); err == nil {
+		t.Error("expected an error for a negative length")
+	}
+}
+
+func TestGet(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	b := array.NewInt16Builder(pool)
+	defer b.Release()
+	b.AppendValues([]int16{3, 0}, []bool{true, false})
+	arr := b.NewInt16Array()
+	defer arr.Release()
+
+	s, err := Get(arr, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s, NewInt16(3); got != want {
+		t.Errorf("got=%v, want=%v", got, want)
+	}
+	if s, err = Get(arr, 1); err != nil {
+		t.Fatal(err)
+	}
+	if s.IsValid() || s.String() != object.STRING_VALUE || s.DataType().ID() != arrow.INT16 {
+		t.Errorf("got=%#v, want a null int16", s)
+	}
+}
+
+func TestFromObject(t *testing.T) {
+	for _, tc := range []struct {
+		dtype arrow.DataType
+		o     object.Object
+		want  Scalar
+	}{
+		{arrow.PrimitiveTypes.Int64, object.NewInt64(5), NewInt64(5)},
+		{arrow.PrimitiveTypes.Float64, object.NewInt32(2), NewFloat64(2)},
+		{arrow.PrimitiveTypes.Uint16, object.NewNull(), Uint16{}},
+		{arrow.PrimitiveTypes.Int8, nil, Int8{}},
+		{arrow.Null, object.NewNull(), Null{}},
+	} {
+		got, err := FromObject(tc.dtype, tc.o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("FromObject(%s, %v): got=%#v, want=%#v", tc.dtype, tc.o, got, tc.want)
+		}
+		back, err := FromObject(got.DataType(), got.Object())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if back != got {
+			t.Errorf("round trip of %#v through its object gave %#v", got, back)
+		}
+	}
+
+	if _, err := FromObject(arrow.PrimitiveTypes.Int64, object.NewString("x")); err == nil {
+		t.Error("expected an error casting a string to int64")
+	}
+}
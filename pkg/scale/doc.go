@@ -0,0 +1,43 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package scale normalizes the numeric columns of DataFrames for machine
+learning preprocessing.
+
+Fit learns the parameters of the scaling from training data, the minimum and
+maximum of every column with MinMax or their mean and standard deviation with
+ZScore, and the Scaler it returns applies that same scaling to any DataFrame
+holding the columns:
+
+	scaler, err := scale.Fit(train, []string{"age", "income"}, scale.WithMethod(scale.ZScore))
+	if err != nil {
+		return err
+	}
+	scaled, err := scaler.Transform(train)
+
+The Scaler is the state of the preprocessing: it encodes to JSON, so it is
+saved next to the model and loaded by the service serving it, which scales the
+rows it receives exactly as the training rows were:
+
+	state, err := json.Marshal(scaler)
+	...
+	var scaler scale.Scaler
+	err := json.Unmarshal(state, &scaler)
+	features, err := scaler.Transform(request)
+
+Scaled columns become Float64 columns and keep their nulls. Inverse maps
+scaled values, such as predicted targets, back to the original scale.
+*/
+package scale
@@ -0,0 +1,49 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import "fmt"
+
+// Option is an option that may be passed to Fit.
+type Option func(interface{}) error
+
+type config struct {
+	method Method
+}
+
+func newConfig(opts ...Option) (*config, error) {
+	cfg := &config{method: MinMax}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// WithMethod selects the scaling fitted, MinMax by default.
+func WithMethod(method Method) Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply WithMethod to: %T", p)
+		}
+		if method != MinMax && method != ZScore {
+			return fmt.Errorf("scale: unknown method %d", method)
+		}
+		cfg.method = method
+		return nil
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/pkg/compute"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+// Method is a way of scaling the values of a column.
+type Method int
+
+const (
+	// MinMax maps the values of a column to [0, 1], the minimum fitted to 0
+	// and the maximum to 1.
+	MinMax Method = iota
+	// ZScore centers the values of a column on the mean fitted and divides
+	// them by the standard deviation fitted.
+	ZScore
+)
+
+func (m Method) String() string {
+	switch m {
+	case MinMax:
+		return "minmax"
+	case ZScore:
+		return "zscore"
+	default:
+		return fmt.Sprintf("Method(%d)", int(m))
+	}
+}
+
+// MarshalText encodes m by its name.
+func (m Method) MarshalText() ([]byte, error) {
+	if m != MinMax && m != ZScore {
+		return nil, fmt.Errorf("scale: unknown method %d", int(m))
+	}
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText decodes a method from its name.
+func (m *Method) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "minmax":
+		*m = MinMax
+	case "zscore":
+		*m = ZScore
+	default:
+		return fmt.Errorf("scale: unknown method %q", text)
+	}
+	return nil
+}
+
+// Column holds the parameters fitted for a column: its values x are scaled to
+// (x - Offset) / Scale.
+type Column struct {
+	Name   string  `json:"name"`
+	Offset float64 `json:"offset"`
+	Scale  float64 `json:"scale"`
+}
+
+// Scaler holds the parameters fitted by Fit. Its fields are exported and
+// tagged, so a Scaler fitted while training is saved with encoding/json and
+// loaded back to scale the data seen at inference time the same way.
+type Scaler struct {
+	Method  Method   `json:"method"`
+	Columns []Column `json:"columns"`
+}
+
+// Fit fits the scaling of the named numeric columns of df, with the method of
+// WithMethod. Null and NaN values are ignored. A column whose fitted range or
+// standard deviation is zero, or which holds no value, gets a Scale of 1 so
+// that its values are only shifted.
+func Fit(df *dataframe.DataFrame, cols []string, opts ...Option) (*Scaler, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	s := &Scaler{Method: cfg.method, Columns: make([]Column, len(cols))}
+	for i, name := range cols {
+		values, err := floats(df, name)
+		if err != nil {
+			return nil, err
+		}
+
+		var n, offset, spread float64
+		switch cfg.method {
+		case MinMax:
+			min, max := math.Inf(1), math.Inf(-1)
+			each(values, func(v float64) {
+				n++
+				min, max = math.Min(min, v), math.Max(max, v)
+			})
+			if n > 0 {
+				offset, spread = min, max-min
+			}
+		case ZScore:
+			// Welford's online algorithm.
+			var mean, m2 float64
+			each(values, func(v float64) {
+				n++
+				delta := v - mean
+				mean += delta / n
+				m2 += delta * (v - mean)
+			})
+			if n > 0 {
+				offset, spread = mean, math.Sqrt(m2/n)
+			}
+		}
+		values.Release()
+		if spread == 0 {
+			spread = 1
+		}
+		s.Columns[i] = Column{Name: name, Offset: offset, Scale: spread}
+	}
+	return s, nil
+}
+
+// Transform returns a DataFrame whose fitted columns are replaced by their
+// scaled values, as Float64 columns. The other columns are kept as they are.
+func (s *Scaler) Transform(df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+	return s.apply(df, func(c Column, v float64) float64 { return (v - c.Offset) / c.Scale })
+}
+
+// Inverse undoes Transform, returning a DataFrame whose fitted columns hold
+// the values before scaling, as Float64 columns.
+func (s *Scaler) Inverse(df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+	return s.apply(df, func(c Column, v float64) float64 { return v*c.Scale + c.Offset })
+}
+
+func (s *Scaler) apply(df *dataframe.DataFrame, fn func(c Column, v float64) float64) (*dataframe.DataFrame, error) {
+	mem := df.Allocator()
+	fitted := make(map[string]Column, len(s.Columns))
+	for _, c := range s.Columns {
+		if c.Scale == 0 {
+			return nil, fmt.Errorf("scale: column %q has a zero scale", c.Name)
+		}
+		fitted[c.Name] = c
+	}
+
+	cols := make([]array.Column, 0, df.NumCols())
+	var built []*array.Column
+	defer func() {
+		for _, col := range built {
+			col.Release()
+		}
+	}()
+	for _, col := range df.Columns() {
+		c, ok := fitted[col.Name()]
+		if !ok {
+			cols = append(cols, col)
+			continue
+		}
+		delete(fitted, c.Name)
+		values, err := floats(df, c.Name)
+		if err != nil {
+			return nil, err
+		}
+		bldr := array.NewFloat64Builder(mem)
+		bldr.Reserve(values.Len())
+		for _, chunk := range values.Data().Chunks() {
+			arr := chunk.(*array.Float64)
+			for i := 0; i < arr.Len(); i++ {
+				if arr.IsNull(i) {
+					bldr.AppendNull()
+					continue
+				}
+				bldr.Append(fn(c, arr.Value(i)))
+			}
+		}
+		values.Release()
+		arr := bldr.NewArray()
+		bldr.Release()
+		chunked := array.NewChunked(arr.DataType(), []array.Interface{arr})
+		arr.Release()
+		field := col.Field()
+		field.Type = arrow.PrimitiveTypes.Float64
+		scaled := array.NewColumn(field, chunked)
+		chunked.Release()
+		built = append(built, scaled)
+		cols = append(cols, *scaled)
+	}
+	for _, c := range s.Columns {
+		if _, ok := fitted[c.Name]; ok {
+			return nil, fmt.Errorf("scale: column %q not found: (%v)", c.Name, df.ColumnNames())
+		}
+	}
+	return dataframe.NewDataFrameFromShape(mem, cols, df.NumRows())
+}
+
+// floats returns the named numeric column of df cast to Float64.
+func floats(df *dataframe.DataFrame, name string) (*array.Column, error) {
+	col := df.Column(name)
+	if col == nil {
+		return nil, fmt.Errorf("scale: column %q not found: (%v)", name, df.ColumnNames())
+	}
+	switch col.DataType().ID() {
+	case arrow.INT8, arrow.INT16, arrow.INT32, arrow.INT64,
+		arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64,
+		arrow.FLOAT32, arrow.FLOAT64:
+	default:
+		return nil, fmt.Errorf("scale: column %q of type %s is not numeric", name, col.DataType())
+	}
+	return compute.Cast(df.Allocator(), col, arrow.PrimitiveTypes.Float64)
+}
+
+// each calls fn with the values of a Float64 column that are neither null nor
+// NaN.
+func each(col *array.Column, fn func(v float64)) {
+	for _, chunk := range col.Data().Chunks() {
+		arr := chunk.(*array.Float64)
+		for i := 0; i < arr.Len(); i++ {
+			if arr.IsValid(i) && !math.IsNaN(arr.Value(i)) {
+				fn(arr.Value(i))
+			}
+		}
+	}
+}
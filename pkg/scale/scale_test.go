@@ -0,0 +1,120 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+func newFrame(t *testing.T, mem memory.Allocator) *dataframe.DataFrame {
+	t.Helper()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.BinaryTypes.String},
+		{Name: "age", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "income", Type: arrow.PrimitiveTypes.Float32},
+	}, nil)
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+	b.Field(0).(*array.StringBuilder).AppendValues([]string{"a", "b", "c", "d"}, nil)
+	b.Field(1).(*array.Int64Builder).AppendValues([]int64{20, 40, 0, 60}, []bool{true, true, false, true})
+	b.Field(2).(*array.Float32Builder).AppendValues([]float32{0, 2, 0, 2}, nil)
+	rec := b.NewRecord()
+	defer rec.Release()
+	df, err := dataframe.NewDataFrameFromRecord(mem, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return df
+}
+
+func values(df *dataframe.DataFrame, name string) string {
+	return fmt.Sprint(df.Column(name).Data().Chunks())
+}
+
+func TestScaler(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df := newFrame(t, pool)
+	defer df.Release()
+
+	for _, tc := range []struct {
+		method      Method
+		age, income string
+	}{
+		{MinMax, "[[0 0.5 (null) 1]]", "[[0 1 0 1]]"},
+		{ZScore, "[[-1.224744871391589 0 (null) 1.224744871391589]]", "[[-1 1 -1 1]]"},
+	} {
+		scaler, err := Fit(df, []string{"age", "income"}, WithMethod(tc.method))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The scaler must survive a round trip through its serialized state.
+		state, err := json.Marshal(scaler)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var loaded Scaler
+		if err := json.Unmarshal(state, &loaded); err != nil {
+			t.Fatal(err)
+		}
+		if loaded.Method != tc.method {
+			t.Fatalf("%s: got method %s after decoding %s", tc.method, loaded.Method, state)
+		}
+
+		scaled, err := loaded.Transform(df)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := values(scaled, "age"); got != tc.age {
+			t.Errorf("%s: got age=%s, want=%s", tc.method, got, tc.age)
+		}
+		if got := values(scaled, "income"); got != tc.income {
+			t.Errorf("%s: got income=%s, want=%s", tc.method, got, tc.income)
+		}
+		if got, want := values(scaled, "id"), values(df, "id"); got != want {
+			t.Errorf("%s: got id=%s, want=%s", tc.method, got, want)
+		}
+
+		restored, err := loaded.Inverse(scaled)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := values(restored, "age"), "[[20 40 (null) 60]]"; got != want {
+			t.Errorf("%s: got restored age=%s, want=%s", tc.method, got, want)
+		}
+		restored.Release()
+		scaled.Release()
+	}
+
+	if _, err := Fit(df, []string{"id"}); err == nil {
+		t.Fatal("expected an error fitting a string column")
+	}
+	if _, err := Fit(df, []string{"missing"}); err == nil {
+		t.Fatal("expected an error fitting a missing column")
+	}
+	var m Method
+	if err := json.Unmarshal([]byte(`"robust"`), &m); err == nil {
+		t.Fatal("expected an error decoding an unknown method")
+	}
+}
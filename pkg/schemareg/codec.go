@@ -0,0 +1,207 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemareg
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// Option is an option that may be passed to NewEncoder and NewDecoder.
+type Option func(interface{}) error
+
+// WithAllocator specifies the allocator used to encode and decode records.
+func WithAllocator(mem memory.Allocator) Option {
+	return func(p interface{}) error {
+		switch p := p.(type) {
+		case *Encoder:
+			p.mem = mem
+		case *Decoder:
+			p.mem = mem
+		default:
+			return fmt.Errorf("cannot apply WithAllocator to: %T", p)
+		}
+		return nil
+	}
+}
+
+// WithReaderSchema makes the Decoder check that the records it decodes can
+// be read with schema, see CanRead.
+func WithReaderSchema(schema *arrow.Schema) Option {
+	return func(p interface{}) error {
+		d, ok := p.(*Decoder)
+		if !ok {
+			return fmt.Errorf("cannot apply WithReaderSchema to: %T", p)
+		}
+		d.reader = schema
+		return nil
+	}
+}
+
+// Encoder encodes records as the id of their schema in a Registry followed
+// by the IPC stream of the record without its schema.
+type Encoder struct {
+	reg     Registry
+	subject string
+	mem     memory.Allocator
+
+	mu  sync.Mutex
+	ids map[*arrow.Schema]schemaID
+}
+
+// schemaID is a registered schema and its schema message.
+type schemaID struct {
+	id     int64
+	schema *arrow.Schema
+	msg    []byte
+}
+
+// NewEncoder returns an Encoder registering the schemas of the records in
+// subject of reg.
+func NewEncoder(reg Registry, subject string, opts ...Option) (*Encoder, error) {
+	e := &Encoder{reg: reg, subject: subject, mem: memory.NewGoAllocator(), ids: make(map[*arrow.Schema]schemaID)}
+	for _, opt := range opts {
+		if err := opt(e); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// Encode returns the encoding of rec, registering its schema the first time
+// it is seen.
+func (e *Encoder) Encode(ctx context.Context, rec array.Record) ([]byte, error) {
+	sid, err := e.schemaID(ctx, rec.Schema())
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	var id [binary.MaxVarintLen64]byte
+	buf.Write(id[:binary.PutVarint(id[:], sid.id)])
+	start := buf.Len()
+
+	w := ipc.NewWriter(&buf, ipc.WithSchema(rec.Schema()), ipc.WithAllocator(e.mem))
+	if err := w.Write(rec); err != nil {
+		return nil, fmt.Errorf("schemareg: could not encode record: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("schemareg: could not encode record: %w", err)
+	}
+
+	data := buf.Bytes()
+	if !bytes.HasPrefix(data[start:], sid.msg) {
+		return nil, fmt.Errorf("schemareg: unexpected schema message for schema %d", sid.id)
+	}
+	return append(data[:start], data[start+len(sid.msg):]...), nil
+}
+
+func (e *Encoder) schemaID(ctx context.Context, schema *arrow.Schema) (schemaID, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if sid, ok := e.ids[schema]; ok {
+		return sid, nil
+	}
+	// Records usually share their schema pointer, other equal schemas are
+	// registered again, which returns the same id.
+	id, err := e.reg.Register(ctx, e.subject, schema)
+	if err != nil {
+		return schemaID{}, err
+	}
+	sid := schemaID{id: id, schema: schema, msg: schemaMessage(schema)}
+	e.ids[schema] = sid
+	return sid, nil
+}
+
+// Decoder decodes the records written by an Encoder, resolving their schema
+// in a Registry.
+type Decoder struct {
+	reg    Registry
+	mem    memory.Allocator
+	reader *arrow.Schema
+
+	mu  sync.Mutex
+	ids map[int64]schemaID
+}
+
+// NewDecoder returns a Decoder resolving the schemas in reg.
+func NewDecoder(reg Registry, opts ...Option) (*Decoder, error) {
+	d := &Decoder{reg: reg, mem: memory.NewGoAllocator(), ids: make(map[int64]schemaID)}
+	for _, opt := range opts {
+		if err := opt(d); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// Decode decodes a record written by Encoder.Encode. The record must be
+// released by the caller.
+func (d *Decoder) Decode(ctx context.Context, data []byte) (array.Record, error) {
+	id, n := binary.Varint(data)
+	if n <= 0 {
+		return nil, fmt.Errorf("schemareg: invalid schema id")
+	}
+	sid, err := d.schemaID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := make([]byte, 0, len(sid.msg)+len(data)-n)
+	stream = append(append(stream, sid.msg...), data[n:]...)
+	r, err := ipc.NewReader(bytes.NewReader(stream), ipc.WithAllocator(d.mem))
+	if err != nil {
+		return nil, fmt.Errorf("schemareg: could not decode record: %w", err)
+	}
+	defer r.Release()
+
+	if !r.Next() {
+		if err := r.Err(); err != nil {
+			return nil, fmt.Errorf("schemareg: could not decode record: %w", err)
+		}
+		return nil, fmt.Errorf("schemareg: no record")
+	}
+	rec := r.Record()
+	rec.Retain()
+	return rec, nil
+}
+
+func (d *Decoder) schemaID(ctx context.Context, id int64) (schemaID, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if sid, ok := d.ids[id]; ok {
+		return sid, nil
+	}
+	schema, err := d.reg.Schema(ctx, id)
+	if err != nil {
+		return schemaID{}, err
+	}
+	if d.reader != nil {
+		if err := CanRead(d.reader, schema); err != nil {
+			return schemaID{}, fmt.Errorf("schemareg: schema %d is not compatible with the reader schema: %w", id, err)
+		}
+	}
+	sid := schemaID{id: id, schema: schema, msg: schemaMessage(schema)}
+	d.ids[id] = sid
+	return sid, nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemareg
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+)
+
+// Compatibility is the rule a new schema of a subject must follow with
+// respect to the previous one.
+type Compatibility int
+
+const (
+	// Backward requires the new schema to read the data of the previous
+	// one. It is the default.
+	Backward Compatibility = iota
+	// Forward requires the previous schema to read the data of the new one.
+	Forward
+	// Full requires both Backward and Forward compatibility.
+	Full
+	// None accepts any new schema.
+	None
+)
+
+func (c Compatibility) String() string {
+	switch c {
+	case Backward:
+		return "backward"
+	case Forward:
+		return "forward"
+	case Full:
+		return "full"
+	case None:
+		return "none"
+	default:
+		return fmt.Sprintf("Compatibility(%d)", int(c))
+	}
+}
+
+// Check returns an error if next does not follow c with respect to prev.
+func (c Compatibility) Check(prev, next *arrow.Schema) error {
+	switch c {
+	case Backward:
+		return CanRead(next, prev)
+	case Forward:
+		return CanRead(prev, next)
+	case Full:
+		if err := CanRead(next, prev); err != nil {
+			return err
+		}
+		return CanRead(prev, next)
+	default:
+		return nil
+	}
+}
+
+// CanRead returns an error unless data written with the writer schema can
+// be read with the reader schema. The fields are matched by name: a field
+// of both schemas must have the same type and cannot become non-nullable,
+// a field only in the reader must be nullable, and the fields only in the
+// writer are ignored.
+func CanRead(reader, writer *arrow.Schema) error {
+	for _, rf := range reader.Fields() {
+		idx := writer.FieldIndices(rf.Name)
+		if len(idx) == 0 {
+			if !rf.Nullable {
+				return fmt.Errorf("schemareg: field %q is missing and not nullable", rf.Name)
+			}
+			continue
+		}
+		wf := writer.Field(idx[0])
+		if !arrow.TypeEqual(rf.Type, wf.Type) {
+			return fmt.Errorf("schemareg: field %q has type %v, expected %v", rf.Name, wf.Type, rf.Type)
+		}
+		if wf.Nullable && !rf.Nullable {
+			return fmt.Errorf("schemareg: field %q is nullable, expected not nullable", rf.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package schemareg exchanges Arrow records that reference their schema by id.
+
+An IPC stream starts with its schema, which for small and frequent messages
+such as gRPC or Flight batches can weigh more than the data. A Registry
+assigns ids to the schemas of a subject, typically a topic or an endpoint,
+and the Encoder replaces the schema of the messages it writes by that id.
+The Decoder resolves the id back to the schema, caching it, and checks it
+against the schema the reader expects before decoding the records.
+
+	enc, err := schemareg.NewEncoder(reg, "orders")
+	...
+	data, err := enc.Encode(ctx, rec)
+
+	dec, err := schemareg.NewDecoder(reg, schemareg.WithReaderSchema(schema))
+	...
+	rec, err := dec.Decode(ctx, data)
+
+Registries enforce a Compatibility mode on the successive schemas of a
+subject. MemoryRegistry is an in-process implementation; other backends,
+such as a Confluent-style HTTP service, only need to implement Registry.
+*/
+package schemareg
@@ -0,0 +1,128 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemareg
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+)
+
+// ErrNotFound is returned by a Registry for an unknown schema id.
+var ErrNotFound = errors.New("schemareg: schema not found")
+
+// Registry stores the schemas of subjects and assigns them ids.
+type Registry interface {
+	// Register returns the id of schema in subject, registering it if it
+	// is new, or an error if it breaks the compatibility of the subject.
+	Register(ctx context.Context, subject string, schema *arrow.Schema) (int64, error)
+
+	// Schema returns the schema of an id, or ErrNotFound.
+	Schema(ctx context.Context, id int64) (*arrow.Schema, error)
+}
+
+// Fingerprint returns a digest of the IPC encoding of schema, metadata
+// included, identifying it regardless of the registry.
+func Fingerprint(schema *arrow.Schema) string {
+	sum := sha256.Sum256(schemaMessage(schema))
+	return hex.EncodeToString(sum[:])
+}
+
+// schemaMessage returns the schema message starting the IPC streams of schema.
+func schemaMessage(schema *arrow.Schema) []byte {
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, ipc.WithSchema(schema))
+	// Closing writes the schema and the 8 bytes of the end of stream marker.
+	w.Close()
+	return buf.Bytes()[:buf.Len()-8]
+}
+
+// MemoryRegistry is a Registry keeping the schemas in memory.
+type MemoryRegistry struct {
+	mu       sync.RWMutex
+	compat   map[string]Compatibility
+	subjects map[string][]int64 // ids of the schemas of each subject, in order
+	schemas  []*arrow.Schema    // schema of each id, starting at 1
+	prints   []string           // fingerprint of each id
+}
+
+var _ Registry = (*MemoryRegistry)(nil)
+
+// NewMemoryRegistry returns an empty MemoryRegistry.
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{
+		compat:   make(map[string]Compatibility),
+		subjects: make(map[string][]int64),
+	}
+}
+
+// SetCompatibility sets the compatibility of the schemas of subject,
+// Backward by default.
+func (r *MemoryRegistry) SetCompatibility(subject string, c Compatibility) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.compat[subject] = c
+}
+
+// Register implements Registry.
+func (r *MemoryRegistry) Register(ctx context.Context, subject string, schema *arrow.Schema) (int64, error) {
+	fp := Fingerprint(schema)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := r.subjects[subject]
+	for _, id := range ids {
+		if r.prints[id-1] == fp {
+			return id, nil
+		}
+	}
+	if len(ids) > 0 {
+		prev := r.schemas[ids[len(ids)-1]-1]
+		if err := r.compat[subject].Check(prev, schema); err != nil {
+			return 0, fmt.Errorf("schemareg: schema of subject %q is not %v compatible: %w", subject, r.compat[subject], err)
+		}
+	}
+
+	r.schemas = append(r.schemas, schema)
+	r.prints = append(r.prints, fp)
+	id := int64(len(r.schemas))
+	r.subjects[subject] = append(ids, id)
+	return id, nil
+}
+
+// Schema implements Registry.
+func (r *MemoryRegistry) Schema(ctx context.Context, id int64) (*arrow.Schema, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if id < 1 || id > int64(len(r.schemas)) {
+		return nil, fmt.Errorf("%w: id %d", ErrNotFound, id)
+	}
+	return r.schemas[id-1], nil
+}
+
+// Versions returns the ids of the schemas of subject, oldest first.
+func (r *MemoryRegistry) Versions(subject string) []int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]int64(nil), r.subjects[subject]...)
+}
@@ -0,0 +1,180 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemareg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+var (
+	schemaV1 = arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	// schemaV2 adds a nullable field, which is backward compatible.
+	schemaV2 = arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "score", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+	}, nil)
+	// schemaV3 changes the type of id, which is not.
+	schemaV3 = arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.BinaryTypes.String},
+	}, nil)
+)
+
+func TestCompatibility(t *testing.T) {
+	required := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "score", Type: arrow.PrimitiveTypes.Float64},
+	}, nil)
+
+	for _, tc := range []struct {
+		compat     Compatibility
+		prev, next *arrow.Schema
+		ok         bool
+	}{
+		{Backward, schemaV1, schemaV2, true},
+		{Forward, schemaV1, schemaV2, true},
+		{Full, schemaV1, schemaV2, true},
+		{Backward, schemaV2, schemaV1, true},
+		{Backward, schemaV1, required, false},
+		{Forward, required, schemaV1, false},
+		{Backward, schemaV1, schemaV3, false},
+		{None, schemaV1, schemaV3, true},
+	} {
+		err := tc.compat.Check(tc.prev, tc.next)
+		if (err == nil) != tc.ok {
+			t.Errorf("%v check of %v then %v: got err=%v, want ok=%v", tc.compat, tc.prev, tc.next, err, tc.ok)
+		}
+	}
+}
+
+func TestMemoryRegistry(t *testing.T) {
+	ctx := context.Background()
+	reg := NewMemoryRegistry()
+
+	id1, err := reg.Register(ctx, "orders", schemaV1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, err := reg.Register(ctx, "orders", arrow.NewSchema(schemaV1.Fields(), nil)); err != nil || id != id1 {
+		t.Fatalf("registering an equal schema: got id=%d err=%v, want id=%d", id, err, id1)
+	}
+	id2, err := reg.Register(ctx, "orders", schemaV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reg.Register(ctx, "orders", schemaV3); err == nil {
+		t.Fatal("expected an error registering an incompatible schema")
+	}
+	reg.SetCompatibility("orders", None)
+	if _, err := reg.Register(ctx, "orders", schemaV3); err != nil {
+		t.Fatal(err)
+	}
+	if got := reg.Versions("orders"); len(got) != 3 || got[0] != id1 || got[1] != id2 {
+		t.Fatalf("invalid versions: %v", got)
+	}
+
+	schema, err := reg.Schema(ctx, id2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !schema.Equal(schemaV2) {
+		t.Fatalf("invalid schema: got=%v, want=%v", schema, schemaV2)
+	}
+	if _, err := reg.Schema(ctx, 42); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("invalid error: got=%v, want=%v", err, ErrNotFound)
+	}
+	if Fingerprint(schemaV1) == Fingerprint(schemaV2) {
+		t.Fatal("different schemas have the same fingerprint")
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+	ctx := context.Background()
+	reg := NewMemoryRegistry()
+
+	bldr := array.NewRecordBuilder(pool, schemaV2)
+	defer bldr.Release()
+	bldr.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2}, nil)
+	bldr.Field(1).(*array.StringBuilder).AppendValues([]string{"a", ""}, []bool{true, false})
+	bldr.Field(2).(*array.Float64Builder).AppendValues([]float64{0.5, 1}, nil)
+	rec := bldr.NewRecord()
+	defer rec.Release()
+
+	enc, err := NewEncoder(reg, "orders", WithAllocator(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := enc.Encode(ctx, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	full, err := encodeStream(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) >= len(full) {
+		t.Fatalf("encoded record is not smaller than its stream: %d >= %d", len(data), len(full))
+	}
+
+	dec, err := NewDecoder(reg, WithAllocator(pool), WithReaderSchema(schemaV1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		got, err := dec.Decode(ctx, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !array.RecordEqual(got, rec) {
+			t.Fatalf("invalid record: got=%v, want=%v", got, rec)
+		}
+		got.Release()
+	}
+
+	strict, err := NewDecoder(reg, WithReaderSchema(schemaV3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := strict.Decode(ctx, data); err == nil {
+		t.Fatal("expected an error decoding with an incompatible reader schema")
+	}
+	if _, err := strict.Decode(ctx, []byte{0x80}); err == nil {
+		t.Fatal("expected an error decoding an invalid id")
+	}
+}
+
+func encodeStream(rec array.Record) ([]byte, error) {
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, ipc.WithSchema(rec.Schema()))
+	if err := w.Write(rec); err != nil {
+		return nil, err
+	}
+	err := w.Close()
+	return buf.Bytes(), err
+}
@@ -0,0 +1,76 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+)
+
+// NullabilityError reports nulls in a field that is not nullable.
+type NullabilityError struct {
+	// Field is the name of the field, the names of nested struct fields are
+	// joined with dots.
+	Field string
+	// Nulls is the number of nulls found in a finished array, or 0 when a
+	// null was rejected as it was appended.
+	Nulls int
+}
+
+func (e *NullabilityError) Error() string {
+	if e.Nulls == 0 {
+		return fmt.Sprintf("builder/smartbuilder: field %q is not nullable", e.Field)
+	}
+	return fmt.Sprintf("builder/smartbuilder: field %q is not nullable but has %d nulls", e.Field, e.Nulls)
+}
+
+// checkNulls returns a NullabilityError if arr holds nulls that field does not
+// allow. The fields of a struct are only checked at the rows where the struct,
+// and every struct holding it, is valid. A nil valid means all rows are valid.
+func checkNulls(field arrow.Field, arr array.Interface, path []string, valid []bool) error {
+	path = append(path, field.Name)
+	if !field.Nullable && arr.NullN() > 0 {
+		nulls := 0
+		for i := 0; i < arr.Len(); i++ {
+			if (valid == nil || valid[i]) && arr.IsNull(i) {
+				nulls++
+			}
+		}
+		if nulls > 0 {
+			return &NullabilityError{Field: strings.Join(path, "."), Nulls: nulls}
+		}
+	}
+
+	st, ok := arr.(*array.Struct)
+	if !ok {
+		return nil
+	}
+	if st.NullN() > 0 {
+		parent := valid
+		valid = make([]bool, st.Len())
+		for i := range valid {
+			valid[i] = (parent == nil || parent[i]) && st.IsValid(i)
+		}
+	}
+	for i, f := range st.DataType().(*arrow.StructType).Fields() {
+		if err := checkNulls(f, st.Field(i), path, valid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartbuilder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestNullabilityEnforcement(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "point", Type: arrow.StructOf(
+			arrow.Field{Name: "x", Type: arrow.PrimitiveTypes.Int32},
+			arrow.Field{Name: "label", Type: arrow.BinaryTypes.String, Nullable: true},
+		), Nullable: true},
+	}, nil)
+
+	bldr := array.NewRecordBuilder(pool, schema)
+	defer bldr.Release()
+
+	// without enforcement the null is appended.
+	if err := NewSmartBuilder(bldr).Append(0, nil); err != nil {
+		t.Fatal(err)
+	}
+	bldr.Field(1).AppendNull()
+	rec := bldr.NewRecord()
+	if rec.Column(0).NullN() != 1 {
+		t.Fatalf("got %d nulls, want 1", rec.Column(0).NullN())
+	}
+	rec.Release()
+
+	sb := NewSmartBuilder(bldr, WithNullabilityEnforcement())
+	var nerr *NullabilityError
+	err := sb.Append(0, nil)
+	if !errors.As(err, &nerr) || nerr.Field != "id" || nerr.Nulls != 0 {
+		t.Fatalf("got error %v, want a NullabilityError for id", err)
+	}
+
+	// nulls appended behind the SmartBuilder are found when the record is built.
+	// The null point does not count, its x is not in use.
+	ids := bldr.Field(0).(*array.Int64Builder)
+	ids.AppendValues([]int64{1, 2, 3}, nil)
+	points := bldr.Field(1).(*array.StructBuilder)
+	points.AppendValues([]bool{true, false, true})
+	points.FieldBuilder(0).(*array.Int32Builder).AppendValues([]int32{1, 0, 0}, []bool{true, false, false})
+	points.FieldBuilder(1).(*array.StringBuilder).AppendValues([]string{"a", "", ""}, []bool{true, false, false})
+
+	rec, err = sb.NewRecord()
+	if rec != nil {
+		rec.Release()
+	}
+	if !errors.As(err, &nerr) || nerr.Field != "point.x" || nerr.Nulls != 1 {
+		t.Fatalf("got error %v, want a NullabilityError for point.x with 1 null", err)
+	}
+}
+
+func TestRecordBuilderNullability(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+
+	b, err := NewRecordBuilder(pool, schema, WithNullabilityEnforcement())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Release()
+
+	var nerr *NullabilityError
+	if err := b.AppendRow(nil, "a"); !errors.As(err, &nerr) || nerr.Field != "id" {
+		t.Fatalf("got error %v, want a NullabilityError for id", err)
+	}
+	if err := b.AppendRow(int64(1), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := b.NewRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		for _, rec := range recs {
+			rec.Release()
+		}
+	}()
+	if len(recs) != 1 || recs[0].NumRows() != 1 {
+		t.Fatalf("got %d records, want 1 record of 1 row", len(recs))
+	}
+}
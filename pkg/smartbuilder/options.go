@@ -0,0 +1,134 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartbuilder
+
+import "fmt"
+
+// Option is an option that may be passed to NewRecordBuilder and NewSmartBuilder.
+type Option func(interface{}) error
+
+const defaultBatchSize = 1024
+
+type config struct {
+	batchSize    int
+	enforceNulls bool
+	sizeHint     int
+	shrinkToFit  bool
+	strings      *StringPool
+	statistics   bool
+}
+
+func newConfig(opts ...Option) (*config, error) {
+	cfg := &config{batchSize: defaultBatchSize}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// WithBatchSize specifies the number of rows of the records built, 1024 by default.
+func WithBatchSize(n int) Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply WithBatchSize to: %T", p)
+		}
+		if n <= 0 {
+			return fmt.Errorf("batch size must be positive, got %d", n)
+		}
+		cfg.batchSize = n
+		return nil
+	}
+}
+
+// WithNullabilityEnforcement rejects nulls appended to non-nullable fields and
+// checks the finished records against the nullability of the schema, reporting
+// violations with a NullabilityError.
+func WithNullabilityEnforcement() Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply WithNullabilityEnforcement to: %T", p)
+		}
+		cfg.enforceNulls = true
+		return nil
+	}
+}
+
+// WithSizeHint specifies the number of rows the records are expected to hold.
+// The field builders then grow straight to n rows instead of doubling their
+// capacity, which avoids over-allocating when the final size is known.
+func WithSizeHint(n int) Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply WithSizeHint to: %T", p)
+		}
+		if n < 0 {
+			return fmt.Errorf("size hint must not be negative, got %d", n)
+		}
+		cfg.sizeHint = n
+		return nil
+	}
+}
+
+// WithShrinkToFit trims the buffers of the records built to their length, so
+// they do not keep the slack left by growing the builders. It applies to the
+// builders of nested fields too.
+func WithShrinkToFit() Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply WithShrinkToFit to: %T", p)
+		}
+		cfg.shrinkToFit = true
+		return nil
+	}
+}
+
+// WithStringPool interns the values appended to the string fields of the
+// records in pool. They are held as shared strings until a record is built,
+// and then copied once into buffers of the exact size, so that repeated values
+// are not copied on every append. The records must be built with
+// SmartBuilder.NewRecord.
+func WithStringPool(pool *StringPool) Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply WithStringPool to: %T", p)
+		}
+		if pool == nil {
+			return fmt.Errorf("string pool must not be nil")
+		}
+		cfg.strings = pool
+		return nil
+	}
+}
+
+// WithStatistics computes the statistics of the columns of the records built,
+// which array.Statistics then returns for each of them. They are computed for
+// the top-level fields only.
+func WithStatistics() Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply WithStatistics to: %T", p)
+		}
+		cfg.statistics = true
+		return nil
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartbuilder
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// RecordBuilder builds the records of a schema one row at a time.
+//
+// Each row is validated before any of its values is appended: it must have
+// one value per field, no nil value for a non-nullable field and values the
+// SmartBuilder can convert to the type of their field. A nil value for a
+// non-nullable field is reported with a NullabilityError. A rejected row
+// leaves the builder unchanged.
+//
+// A record is cut every time the batch size is reached. With
+// WithNullabilityEnforcement, every record cut is also checked against the
+// nullability of the schema fields, including the fields of structs.
+type RecordBuilder struct {
+	schema    *arrow.Schema
+	bldr      *array.RecordBuilder
+	sb        *SmartBuilder
+	batchSize int
+	rows      int
+	records   []array.Record
+}
+
+// NewRecordBuilder returns a RecordBuilder of records matching schema.
+func NewRecordBuilder(mem memory.Allocator, schema *arrow.Schema, opts ...Option) (*RecordBuilder, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	bldr := array.NewRecordBuilder(mem, schema)
+	return &RecordBuilder{
+		schema:    schema,
+		bldr:      bldr,
+		sb:        NewSmartBuilder(bldr, opts...),
+		batchSize: cfg.batchSize,
+	}, nil
+}
+
+// Schema returns the schema of the records built.
+func (b *RecordBuilder) Schema() *arrow.Schema { return b.schema }
+
+// AppendRow appends a row holding one value per field, nil being null.
+func (b *RecordBuilder) AppendRow(values ...interface{}) error {
+	fields := b.schema.Fields()
+	if len(values) != len(fields) {
+		return fmt.Errorf("builder/smartbuilder: row has %d values, schema has %d fields", len(values), len(fields))
+	}
+	for i, v := range values {
+		if v == nil {
+			if !fields[i].Nullable {
+				return &NullabilityError{Field: fields[i].Name}
+			}
+			continue
+		}
+		if err := b.sb.checkValue(b.bldr.Field(i), v); err != nil {
+			return fmt.Errorf("builder/smartbuilder: field %q: %w", fields[i].Name, err)
+		}
+	}
+
+	for i, v := range values {
+		if err := b.sb.Append(i, v); err != nil {
+			// checkValue accepted the value, so this cannot happen.
+			panic(err)
+		}
+	}
+	b.rows++
+	if b.rows == b.batchSize {
+		return b.cut()
+	}
+	return nil
+}
+
+// NumRows returns the number of rows appended since the last record was cut.
+func (b *RecordBuilder) NumRows() int { return b.rows }
+
+// cut turns the pending rows into a record. The rows are dropped if the
+// record is rejected.
+func (b *RecordBuilder) cut() error {
+	b.rows = 0
+	rec, err := b.sb.NewRecord()
+	if err != nil {
+		return err
+	}
+	b.records = append(b.records, rec)
+	return nil
+}
+
+// NewRecords cuts the pending rows, if any, and returns the records built
+// since the last call. The caller must release them.
+func (b *RecordBuilder) NewRecords() ([]array.Record, error) {
+	if b.rows > 0 {
+		if err := b.cut(); err != nil {
+			return nil, err
+		}
+	}
+	recs := b.records
+	b.records = nil
+	return recs, nil
+}
+
+// Release releases the builders and the records not yet returned by NewRecords.
+func (b *RecordBuilder) Release() {
+	for _, rec := range b.records {
+		rec.Release()
+	}
+	b.records = nil
+	b.bldr.Release()
+}
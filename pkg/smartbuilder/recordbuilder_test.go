@@ -0,0 +1,275 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartbuilder
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func TestRecordBuilder(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "tags", Type: arrow.ListOf(arrow.BinaryTypes.String), Nullable: true},
+	}, nil)
+
+	b, err := NewRecordBuilder(pool, schema, WithBatchSize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Release()
+
+	for i, tc := range []struct {
+		row []interface{}
+		err string
+	}{
+		{row: []interface{}{int64(1), "a", []string{"x"}}},
+		{row: []interface{}{int64(2)}, err: "row has 1 values, schema has 3 fields"},
+		{row: []interface{}{nil, "b", nil}, err: `field "id" is not nullable`},
+		{row: []interface{}{int64(3), "c", 4}, err: `field "tags": cannot cast int to a list`},
+		{row: []interface{}{int64(4), nil, []string{}}},
+		{row: []interface{}{int64(5), "e", nil}},
+	} {
+		err := b.AppendRow(tc.row...)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Fatalf("row %d: %v", i, err)
+		case tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)):
+			t.Fatalf("row %d: got error %v, want %q", i, err, tc.err)
+		}
+	}
+	if got, want := b.NumRows(), 1; got != want {
+		t.Fatalf("got %d pending rows, want %d", got, want)
+	}
+
+	recs, err := b.NewRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		for _, rec := range recs {
+			rec.Release()
+		}
+	}()
+
+	var got []string
+	for _, rec := range recs {
+		for i, col := range rec.Columns() {
+			got = append(got, fmt.Sprintf("%s: %v", rec.ColumnName(i), col))
+		}
+	}
+	want := []string{
+		`id: [1 4]`,
+		`name: ["a" (null)]`,
+		`tags: [["x"] []]`,
+		`id: [5]`,
+		`name: ["e"]`,
+		`tags: [(null)]`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("\ngot=\n%s\nwant=\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if recs, _ := b.NewRecords(); len(recs) != 0 {
+		t.Fatalf("got %d records, want none", len(recs))
+	}
+}
+
+func TestRecordBuilderShrinkToFit(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "tags", Type: arrow.ListOf(arrow.BinaryTypes.String), Nullable: true},
+	}, nil)
+
+	const rows = 100
+	b, err := NewRecordBuilder(pool, schema, WithBatchSize(rows), WithSizeHint(rows), WithShrinkToFit())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Release()
+
+	for i := 0; i < rows; i++ {
+		if err := b.AppendRow(int64(i), strings.Repeat("n", i%7), []string{"x", "yz"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recs, err := b.NewRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		for _, rec := range recs {
+			rec.Release()
+		}
+	}()
+	if len(recs) != 1 {
+		t.Fatalf("got %d records, want 1", len(recs))
+	}
+
+	var check func(name string, data *array.Data)
+	check = func(name string, data *array.Data) {
+		for i, buf := range data.Buffers() {
+			if buf != nil && buf.Cap()-buf.Len() >= 64 {
+				t.Errorf("%s: buffer %d has length %d and capacity %d", name, i, buf.Len(), buf.Cap())
+			}
+		}
+		for _, child := range data.Children() {
+			check(name+".item", child)
+		}
+	}
+	for i, col := range recs[0].Columns() {
+		check(recs[0].ColumnName(i), col.Data())
+	}
+}
+
+func TestWithSizeHintInvalid(t *testing.T) {
+	if _, err := NewRecordBuilder(memory.NewGoAllocator(), arrow.NewSchema(nil, nil), WithSizeHint(-1)); err == nil {
+		t.Fatal("expected an error for a negative size hint")
+	}
+}
+
+func TestRecordBuilderStringPool(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "city", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "country", Type: arrow.BinaryTypes.String},
+		{Name: "n", Type: arrow.PrimitiveTypes.Int64},
+	}, nil)
+
+	strs := NewStringPool()
+	b, err := NewRecordBuilder(pool, schema, WithBatchSize(3), WithStringPool(strs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Release()
+
+	for i, row := range [][]interface{}{
+		{"Paris", "France", int64(1)},
+		{nil, "France", int64(2)},
+		{"Lyon", "France", int64(3)},
+		{"Paris", "France", int64(4)},
+	} {
+		if err := b.AppendRow(row...); err != nil {
+			t.Fatalf("row %d: %v", i, err)
+		}
+	}
+	recs, err := b.NewRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		for _, rec := range recs {
+			rec.Release()
+		}
+	}()
+
+	var got []string
+	for _, rec := range recs {
+		for i, col := range rec.Columns() {
+			got = append(got, fmt.Sprintf("%s: %v", rec.ColumnName(i), col))
+		}
+	}
+	want := []string{
+		`city: ["Paris" (null) "Lyon"]`,
+		`country: ["France" "France" "France"]`,
+		`n: [1 2 3]`,
+		`city: ["Paris"]`,
+		`country: ["France"]`,
+		`n: [4]`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if got, want := strs.Len(), 3; got != want {
+		t.Fatalf("got %d interned strings, want %d", got, want)
+	}
+	if got, want := strs.Size(), int64(len("Paris")+len("France")+len("Lyon")); got != want {
+		t.Fatalf("got %d interned bytes, want %d", got, want)
+	}
+	if got := strs.Intern("Lyon"); got != "Lyon" || strs.Len() != 3 {
+		t.Fatalf("got %q and %d interned strings, want %q and 3", got, strs.Len(), "Lyon")
+	}
+}
+
+func TestRecordBuilderStatistics(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+
+	const rows = 20000
+	b, err := NewRecordBuilder(pool, schema, WithBatchSize(rows), WithStatistics())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Release()
+
+	for i := 0; i < rows; i++ {
+		var name interface{}
+		if i%4 != 0 {
+			name = fmt.Sprintf("n%03d", i%500)
+		}
+		if err := b.AppendRow(int64(rows-i), name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recs, err := b.NewRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		for _, rec := range recs {
+			rec.Release()
+		}
+	}()
+
+	ids, ok := array.Statistics(recs[0].Column(0))
+	if !ok {
+		t.Fatal("no statistics for id")
+	}
+	if ids.NullN != 0 || ids.Min != int64(1) || ids.Max != int64(rows) {
+		t.Errorf("got id stats=%+v", ids)
+	}
+	// Past the size of the sketch the distinct count is an estimate.
+	if ids.DistinctN < rows*9/10 || ids.DistinctN > rows*11/10 {
+		t.Errorf("got id distinct=%d, want about %d", ids.DistinctN, rows)
+	}
+
+	names, ok := array.Statistics(recs[0].Column(1))
+	if !ok {
+		t.Fatal("no statistics for name")
+	}
+	if names.NullN != rows/4 || names.Min != "n001" || names.Max != "n499" || names.DistinctN != 375 {
+		t.Errorf("got name stats=%+v", names)
+	}
+}
@@ -20,7 +20,7 @@ import (
 	"fmt"
 	"reflect"
 
-	"github.com/apache/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
 	"github.com/gomem/gomem/internal/debug"
 	"github.com/gomem/gomem/pkg/object"
 )
@@ -121,6 +121,13 @@ func (sb *SmartBuilder) appendValue(bldr array.Builder, v interface{}) error {
 		}
 		b.Append(vT.Value())
 
+	case *array.MonthDayNanoIntervalBuilder:
+		vT, ok := object.CastToMonthDayNanoInterval(v)
+		if !ok {
+			return fmt.Errorf("cannot cast %T to object.MonthDayNanoInterval", v)
+		}
+		b.Append(vT.Value())
+
 	case *array.MonthIntervalBuilder:
 		vT, ok := object.CastToMonthInterval(v)
 		if !ok {
@@ -214,3 +221,146 @@ func (sb *SmartBuilder) appendValue(bldr array.Builder, v interface{}) error {
 
 	return nil
 }
+
+// checkValue returns the error appendValue would return for v, without appending it.
+func (sb *SmartBuilder) checkValue(bldr array.Builder, v interface{}) error {
+	switch b := bldr.(type) {
+
+	case *array.BooleanBuilder:
+		if _, ok := object.CastToBoolean(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Boolean", v)
+		}
+
+	case *array.Date32Builder:
+		if _, ok := object.CastToDate32(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Date32", v)
+		}
+
+	case *array.Date64Builder:
+		if _, ok := object.CastToDate64(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Date64", v)
+		}
+
+	case *array.DayTimeIntervalBuilder:
+		if _, ok := object.CastToDayTimeInterval(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.DayTimeInterval", v)
+		}
+
+	case *array.Decimal128Builder:
+		if _, ok := object.CastToDecimal128(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Decimal128", v)
+		}
+
+	case *array.DurationBuilder:
+		if _, ok := object.CastToDuration(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Duration", v)
+		}
+
+	case *array.Float16Builder:
+		if _, ok := object.CastToFloat16(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Float16", v)
+		}
+
+	case *array.Float32Builder:
+		if _, ok := object.CastToFloat32(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Float32", v)
+		}
+
+	case *array.Float64Builder:
+		if _, ok := object.CastToFloat64(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Float64", v)
+		}
+
+	case *array.Int16Builder:
+		if _, ok := object.CastToInt16(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Int16", v)
+		}
+
+	case *array.Int32Builder:
+		if _, ok := object.CastToInt32(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Int32", v)
+		}
+
+	case *array.Int64Builder:
+		if _, ok := object.CastToInt64(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Int64", v)
+		}
+
+	case *array.Int8Builder:
+		if _, ok := object.CastToInt8(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Int8", v)
+		}
+
+	case *array.MonthDayNanoIntervalBuilder:
+		if _, ok := object.CastToMonthDayNanoInterval(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.MonthDayNanoInterval", v)
+		}
+
+	case *array.MonthIntervalBuilder:
+		if _, ok := object.CastToMonthInterval(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.MonthInterval", v)
+		}
+
+	case *array.StringBuilder:
+		if _, ok := object.CastToString(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.String", v)
+		}
+
+	case *array.Time32Builder:
+		if _, ok := object.CastToTime32(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Time32", v)
+		}
+
+	case *array.Time64Builder:
+		if _, ok := object.CastToTime64(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Time64", v)
+		}
+
+	case *array.TimestampBuilder:
+		if _, ok := object.CastToTimestamp(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Timestamp", v)
+		}
+
+	case *array.Uint16Builder:
+		if _, ok := object.CastToUint16(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Uint16", v)
+		}
+
+	case *array.Uint32Builder:
+		if _, ok := object.CastToUint32(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Uint32", v)
+		}
+
+	case *array.Uint64Builder:
+		if _, ok := object.CastToUint64(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Uint64", v)
+		}
+
+	case *array.Uint8Builder:
+		if _, ok := object.CastToUint8(v); !ok {
+			return fmt.Errorf("cannot cast %T to object.Uint8", v)
+		}
+
+	case *array.ListBuilder:
+		return sb.checkElements(b.ValueBuilder(), v)
+
+	case *array.FixedSizeListBuilder:
+		return sb.checkElements(b.ValueBuilder(), v)
+
+	case *array.StructBuilder:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Struct || rv.NumField() < b.NumField() {
+			return fmt.Errorf("cannot cast %T to a struct of %d fields", v, b.NumField())
+		}
+		for i := 0; i < b.NumField(); i++ {
+			if err := sb.checkValue(b.FieldBuilder(i), rv.Field(i).Interface()); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("builder/smartbuilder: unhandled Arrow builder type %T", b)
+	}
+
+	return nil
+}
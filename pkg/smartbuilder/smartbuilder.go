@@ -15,35 +15,128 @@
 package smartbuilder
 
 import (
-	"github.com/apache/arrow/go/arrow/array"
+	"fmt"
+	"reflect"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
 	"github.com/gomem/gomem/internal/debug"
+	"github.com/gomem/gomem/pkg/object"
 )
 
 // SmartBuilder knows how to convert to the correct type when building.
 type SmartBuilder struct {
 	recordBuilder *array.RecordBuilder
+	enforceNulls  bool
+	strings       *StringPool
+	interned      []*internedColumn // by field, for the string fields when interning
 }
 
 // NewSmartBuilder creates a SmartBuilder that knows how to convert to the correct type when building.
-func NewSmartBuilder(recordBuilder *array.RecordBuilder) *SmartBuilder {
+// It panics if an option is invalid.
+func NewSmartBuilder(recordBuilder *array.RecordBuilder, opts ...Option) *SmartBuilder {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		panic(err)
+	}
 	// lenFields := len(recordBuilder.Fields())
 	sb := &SmartBuilder{
 		recordBuilder: recordBuilder,
+		enforceNulls:  cfg.enforceNulls,
+		strings:       cfg.strings,
+	}
+	if sb.strings != nil {
+		sb.interned = make([]*internedColumn, len(recordBuilder.Fields()))
+	}
+	for i, bldr := range recordBuilder.Fields() {
+		if _, ok := bldr.(*array.StringBuilder); ok && sb.strings != nil {
+			sb.interned[i] = &internedColumn{}
+		}
+		if cfg.sizeHint > 0 {
+			bldr.SetSizeHint(cfg.sizeHint)
+		}
+		if cfg.shrinkToFit {
+			shrinkToFit(bldr)
+		}
+		if cfg.statistics {
+			bldr.SetStatistics(true)
+		}
 	}
 
 	return sb
 }
 
+// shrinkToFit enables shrinking on bldr and the builders of its children.
+func shrinkToFit(bldr array.Builder) {
+	bldr.SetShrinkToFit(true)
+	switch b := bldr.(type) {
+	case *array.ListBuilder:
+		shrinkToFit(b.ValueBuilder())
+	case *array.FixedSizeListBuilder:
+		shrinkToFit(b.ValueBuilder())
+	case *array.StructBuilder:
+		for i := 0; i < b.NumField(); i++ {
+			shrinkToFit(b.FieldBuilder(i))
+		}
+	}
+}
+
 func (sb *SmartBuilder) Append(fieldIndex int, v interface{}) error {
 	builder := sb.recordBuilder.Field(fieldIndex)
 	debug.Assert(builder != nil, "Append/builder is nil")
 	if v == nil {
+		if field := sb.recordBuilder.Schema().Field(fieldIndex); sb.enforceNulls && !field.Nullable {
+			return &NullabilityError{Field: field.Name}
+		}
+		if col := sb.internedColumn(fieldIndex); col != nil {
+			col.appendNull()
+			return nil
+		}
 		builder.AppendNull()
 		return nil
 	}
+	if col := sb.internedColumn(fieldIndex); col != nil {
+		s, ok := object.CastToString(v)
+		if !ok {
+			return fmt.Errorf("cannot cast %T to object.String", v)
+		}
+		col.append(sb.strings.Intern(s.Value()))
+		return nil
+	}
 	return sb.appendValue(builder, v)
 }
 
+// internedColumn returns the strings held for a field, or nil when its values
+// are not interned.
+func (sb *SmartBuilder) internedColumn(fieldIndex int) *internedColumn {
+	if sb.interned == nil {
+		return nil
+	}
+	return sb.interned[fieldIndex]
+}
+
+// NewRecord returns the record built so far, the caller must release it.
+// When nullability is enforced, the record is checked against the nullability
+// of the schema fields and a NullabilityError is returned if it holds nulls
+// where the schema does not allow them.
+func (sb *SmartBuilder) NewRecord() (array.Record, error) {
+	for i, col := range sb.interned {
+		if col != nil {
+			col.flush(sb.recordBuilder.Field(i).(*array.StringBuilder))
+		}
+	}
+	rec := sb.recordBuilder.NewRecord()
+	if !sb.enforceNulls {
+		return rec, nil
+	}
+	for i, field := range rec.Schema().Fields() {
+		if err := checkNulls(field, rec.Column(i), nil, nil); err != nil {
+			rec.Release()
+			return nil, err
+		}
+	}
+	return rec, nil
+}
+
 // If the type of v is a pointer return the pointer as a value,
 // otherwise create a new pointer to the value.
 // func reflectValueOfNonPointer(v interface{}) reflect.Value {
@@ -58,3 +151,17 @@ func (sb *SmartBuilder) Append(fieldIndex int, v interface{}) error {
 // 	}
 // 	return ptr
 // }
+
+// checkElements checks every element of the slice or array v against bldr.
+func (sb *SmartBuilder) checkElements(bldr array.Builder, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return fmt.Errorf("cannot cast %T to a list", v)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := sb.checkValue(bldr, rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -19,9 +19,9 @@ package smartbuilder
 import (
 	"strconv"
 
-	"github.com/apache/arrow/go/arrow"
-	"github.com/apache/arrow/go/arrow/decimal128"
-	"github.com/apache/arrow/go/arrow/float16"
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/decimal128"
+	"github.com/gomem/gomem/arrow/go/arrow/float16"
 	"github.com/gomem/gomem/pkg/object"
 )
 
@@ -114,6 +114,11 @@ func GenerateSmartBuilderTestCases() []SmartBuilderTestCase {
 			Dtype:  arrow.PrimitiveTypes.Int8,
 			Want:   `col[0][col-int8]: [0 1 2 3 4 5 6 7 8 (null)]`,
 		},
+		{
+			Values: MonthDayNanoIntervalGen(),
+			Dtype:  arrow.FixedWidthTypes.MonthDayNanoInterval,
+			Want:   `col[0][col-month_day_nano_interval]: [{0 0 0} {1 2 3} {2 4 6} {3 6 9} {4 8 12} {5 10 15} {6 12 18} {7 14 21} {8 16 24} (null)]`,
+		},
 		{
 			Values: MonthIntervalGen(),
 			Dtype:  arrow.FixedWidthTypes.MonthInterval,
@@ -315,6 +320,14 @@ func Int8Gen() []interface{} {
 	return vals
 }
 
+func MonthDayNanoIntervalGen() []interface{} {
+	vals := make([]interface{}, 9)
+	for i := range vals {
+		vals[i] = object.NewMonthDayNanoInterval(arrow.MonthDayNanoInterval{Months: int32(i), Days: int32(i * 2), Nanoseconds: int64(i * 3)})
+	}
+	return vals
+}
+
 func MonthIntervalGen() []interface{} {
 	vals := make([]interface{}, 9)
 	for i := range vals {
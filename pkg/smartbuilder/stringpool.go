@@ -0,0 +1,96 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartbuilder
+
+import (
+	"sync"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+)
+
+// StringPool interns strings, so that equal strings share their bytes. A pool
+// is meant to be shared by the builders of an ingestion session, through
+// WithStringPool, across their columns and their records, and is safe for
+// concurrent use. It keeps every distinct string it is given, so it should be
+// dropped with the session.
+type StringPool struct {
+	mu     sync.Mutex
+	values map[string]string
+	size   int64
+}
+
+// NewStringPool returns an empty StringPool.
+func NewStringPool() *StringPool {
+	return &StringPool{values: make(map[string]string)}
+}
+
+// Intern returns the string of the pool equal to s, adding a copy of s to the
+// pool when there is none. The copy does not keep alive the memory s may be
+// a part of.
+func (p *StringPool) Intern(s string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if v, ok := p.values[s]; ok {
+		return v
+	}
+	v := string([]byte(s))
+	p.values[v] = v
+	p.size += int64(len(v))
+	return v
+}
+
+// Len returns the number of distinct strings of the pool.
+func (p *StringPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.values)
+}
+
+// Size returns the number of bytes of the distinct strings of the pool.
+func (p *StringPool) Size() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.size
+}
+
+// internedColumn holds the interned strings appended to a string field until
+// the record is built.
+type internedColumn struct {
+	values []string
+	valid  []bool
+	size   int
+}
+
+func (c *internedColumn) append(s string) {
+	c.values = append(c.values, s)
+	c.valid = append(c.valid, true)
+	c.size += len(s)
+}
+
+func (c *internedColumn) appendNull() {
+	c.values = append(c.values, "")
+	c.valid = append(c.valid, false)
+}
+
+// flush appends the strings held to bldr, reserving the exact space they need.
+func (c *internedColumn) flush(bldr *array.StringBuilder) {
+	bldr.Reserve(len(c.values))
+	bldr.ReserveData(c.size)
+	bldr.AppendValues(c.values, c.valid)
+	for i := range c.values {
+		c.values[i] = ""
+	}
+	c.values, c.valid, c.size = c.values[:0], c.valid[:0], 0
+}
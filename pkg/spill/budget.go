@@ -0,0 +1,78 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spill
+
+import (
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// allocStats is implemented by allocators reporting the number of bytes they
+// hold, such as memory.CheckedAllocator.
+type allocStats interface {
+	CurrentAlloc() int
+}
+
+// budget tracks the memory used by the records an operator buffers.
+type budget struct {
+	limit int64
+	stats allocStats
+	base  int
+	held  int64
+}
+
+func newBudget(mem memory.Allocator, limit int64) *budget {
+	b := &budget{limit: limit}
+	if stats, ok := mem.(allocStats); ok {
+		b.stats = stats
+		b.base = stats.CurrentAlloc()
+	}
+	return b
+}
+
+// add accounts for a buffered record.
+func (b *budget) add(rec array.Record) {
+	for _, col := range rec.Columns() {
+		b.held += dataSize(col.Data())
+	}
+}
+
+// reset forgets the buffered records, after they were spilled.
+func (b *budget) reset() { b.held = 0 }
+
+// exceeded reports whether the memory used is over the limit.
+func (b *budget) exceeded() bool {
+	used := b.held
+	if b.stats != nil {
+		if grown := int64(b.stats.CurrentAlloc() - b.base); grown > used {
+			used = grown
+		}
+	}
+	return used > b.limit
+}
+
+// dataSize returns the number of bytes of the buffers of data and its children.
+func dataSize(data *array.Data) int64 {
+	var n int64
+	for _, buf := range data.Buffers() {
+		if buf != nil {
+			n += int64(buf.Len())
+		}
+	}
+	for _, child := range data.Children() {
+		n += dataSize(child)
+	}
+	return n
+}
@@ -0,0 +1,164 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spill
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// CrossJoin returns a Reader over the cross join of left and right: every left
+// row paired with every right row, in left row order. The records hold the
+// columns of left followed by the columns of right, which must have other names.
+//
+// right is read to the end and buffered before CrossJoin returns, and an error
+// is returned when it does not fit in the memory budget. left is read one
+// record at a time while the Reader is read, and the pairs of each left record
+// are generated a batch at a time, so only one batch of output rows is held in
+// memory. Reading fails before the rows of a left record are generated when the
+// join would exceed maxRows rows, so an accidental cartesian product fails fast.
+func CrossJoin(mem memory.Allocator, left, right array.RecordReader, maxRows int64, opts ...Option) (*Reader, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if maxRows <= 0 {
+		return nil, fmt.Errorf("spill: CrossJoin maximum number of rows must be > 0, got %d", maxRows)
+	}
+
+	fields := append([]arrow.Field(nil), left.Schema().Fields()...)
+	names := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		names[f.Name] = true
+	}
+	for _, f := range right.Schema().Fields() {
+		if names[f.Name] {
+			return nil, fmt.Errorf("spill: column %q is on both sides of the join", f.Name)
+		}
+		fields = append(fields, f)
+	}
+
+	var recs []array.Record
+	defer func() { releaseRecords(recs) }()
+	var rightRows int64
+	b := newBudget(mem, cfg.budget)
+	for right.Next() {
+		rec := right.Record()
+		if rec.NumRows() == 0 {
+			continue
+		}
+		rec.Retain()
+		recs = append(recs, rec)
+		rightRows += rec.NumRows()
+		b.add(rec)
+		if b.exceeded() {
+			return nil, fmt.Errorf("spill: right side of CrossJoin exceeds the memory budget of %d bytes", cfg.budget)
+		}
+	}
+	if err := readerErr(right); err != nil {
+		return nil, err
+	}
+
+	c := &crossJoiner{
+		mem:       mem,
+		cfg:       cfg,
+		maxRows:   maxRows,
+		schema:    arrow.NewSchema(fields, nil),
+		left:      left,
+		right:     recordColumns(right.Schema(), recs),
+		rightRows: rightRows,
+	}
+	left.Retain()
+	return newReader(c.schema, c.next, c.close), nil
+}
+
+type crossJoiner struct {
+	mem     memory.Allocator
+	cfg     *config
+	maxRows int64
+	schema  *arrow.Schema
+
+	left      array.RecordReader
+	right     []*array.Column
+	rightRows int64
+	// leftRows is the number of left rows read so far.
+	leftRows int64
+
+	// cur holds the columns of the current left record, of curRows rows, and
+	// pos is the next of its pairs.
+	cur     []*array.Column
+	curRows int64
+	pos     int64
+}
+
+// next returns the next batch of joined rows, or nil after the last one.
+func (c *crossJoiner) next() (array.Record, error) {
+	for c.cur == nil || c.pos == c.curRows*c.rightRows {
+		if c.cur != nil {
+			releaseColumns(c.cur)
+			c.cur = nil
+		}
+		if !c.left.Next() {
+			return nil, readerErr(c.left)
+		}
+		rec := c.left.Record()
+		if rec.NumRows() == 0 {
+			continue
+		}
+		if _, err := compute.CrossJoinSize(c.leftRows+rec.NumRows(), c.rightRows, c.maxRows); err != nil {
+			return nil, err
+		}
+		c.leftRows += rec.NumRows()
+		c.cur = recordColumns(c.left.Schema(), []array.Record{rec})
+		c.curRows, c.pos = rec.NumRows(), 0
+	}
+
+	n := c.curRows*c.rightRows - c.pos
+	if n > int64(c.cfg.batchSize) {
+		n = int64(c.cfg.batchSize)
+	}
+	li, ri, err := compute.CrossJoinRows(c.mem, c.curRows, c.rightRows, c.pos, n)
+	if err != nil {
+		return nil, err
+	}
+	defer li.Release()
+	defer ri.Release()
+	c.pos += n
+
+	larrs, err := takeArrays(c.mem, c.cur, li)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseArrays(larrs)
+	rarrs, err := takeArrays(c.mem, c.right, ri)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseArrays(rarrs)
+
+	return array.NewRecord(c.schema, append(larrs, rarrs...), n), nil
+}
+
+func (c *crossJoiner) close() {
+	releaseColumns(c.cur)
+	c.cur = nil
+	releaseColumns(c.right)
+	c.right = nil
+	c.left.Release()
+}
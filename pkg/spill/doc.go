@@ -0,0 +1,58 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package spill provides sort, join and group by operators over streams of
+Arrow records that spill to disk when their input does not fit in a memory
+budget.
+
+The operators buffer their input until the budget is exceeded. Sort then
+writes each buffer as a sorted run to a temporary Arrow IPC file and merges the
+runs, while HashJoin writes the rows of both sides to hash partitions and joins
+each pair of partitions in memory. When the input fits in the budget nothing is
+written to disk. A HashJoin whose right side is small, such as a dimension
+table, broadcasts it instead: its hash table is built once and the left side is
+streamed against it without being buffered.
+
+GroupBy aggregates the rows with equal keys like compute.AggregateGroups,
+writing the rows to hash partitions of their keys like HashJoin does, so that
+every group is aggregated from a single partition. The Partitioner used by
+both is exported for other operators grouping rows by key.
+
+MergeJoin joins inputs already sorted by their keys without buffering them:
+Sort marks the key fields of its output as sorted, and compute.WithSortOrder
+marks the fields of data stored sorted, such as partitions sorted by time.
+
+CrossJoin buffers its right side and streams its left side, generating the
+pairs of rows a batch at a time. It takes a maximum number of rows and fails
+as soon as the join would exceed it, rather than running out of memory.
+
+Memory use is the larger of the growth of the allocator, when it reports the
+bytes it holds like memory.CheckedAllocator does, and the size of the buffered
+records.
+
+	r, err := spill.Sort(mem, rdr, []spill.SortKey{{Name: "ts"}}, spill.WithMemoryBudget(512<<20))
+	if err != nil {
+		return err
+	}
+	defer r.Release()
+	for r.Next() {
+		rec := r.Record()
+		// ...
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+*/
+package spill
@@ -0,0 +1,75 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spill
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+// spillFile is a temporary Arrow IPC file holding records of a single schema.
+type spillFile struct {
+	f *os.File
+	w *ipc.FileWriter
+}
+
+func newSpillFile(mem memory.Allocator, dir string, schema *arrow.Schema) (*spillFile, error) {
+	f, err := ioutil.TempFile(dir, "gomem-spill-*.arrow")
+	if err != nil {
+		return nil, fmt.Errorf("spill: could not create temporary file: %w", err)
+	}
+	w, err := ipc.NewFileWriter(f, ipc.WithSchema(schema), ipc.WithAllocator(mem))
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("spill: could not create IPC writer: %w", err)
+	}
+	return &spillFile{f: f, w: w}, nil
+}
+
+func (s *spillFile) write(rec array.Record) error {
+	if err := s.w.Write(rec); err != nil {
+		return fmt.Errorf("spill: could not write %s: %w", s.f.Name(), err)
+	}
+	return nil
+}
+
+// open finishes writing the file and returns a reader over its records.
+// The reader must be closed before the file is removed.
+func (s *spillFile) open(mem memory.Allocator) (*ipc.FileReader, error) {
+	if s.w != nil {
+		if err := s.w.Close(); err != nil {
+			return nil, fmt.Errorf("spill: could not finish %s: %w", s.f.Name(), err)
+		}
+		s.w = nil
+	}
+	r, err := ipc.NewFileReader(s.f, ipc.WithAllocator(mem))
+	if err != nil {
+		return nil, fmt.Errorf("spill: could not read %s: %w", s.f.Name(), err)
+	}
+	return r, nil
+}
+
+// remove closes and deletes the file.
+func (s *spillFile) remove() error {
+	s.f.Close()
+	return os.Remove(s.f.Name())
+}
@@ -0,0 +1,262 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spill
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// Aggregate is an aggregate of a column computed by GroupBy for every group.
+type Aggregate struct {
+	// Column is the aggregated column. It may be empty for compute.AggCount,
+	// which then counts the rows of each group.
+	Column string
+	Kind   compute.AggregateKind
+	// Name is the name of the output column, Column or the name of Kind when
+	// Column is empty by default.
+	Name string
+}
+
+// GroupBy returns a Reader over a row per group of the rows of rdr with equal
+// keys, holding the key columns followed by the aggregates, computed like
+// compute.AggregateGroups does. Nulls are equal to each other, so the rows with
+// null keys form groups of their own.
+//
+// When the buffered records exceed the memory budget their rows are written to
+// hash partitions of their keys, so every group is in a single partition, and
+// each partition is grouped in memory while the Reader is read. The groups are
+// then ordered by partition instead of by first appearance. rdr is read to the
+// end before GroupBy returns.
+func GroupBy(mem memory.Allocator, rdr array.RecordReader, keys []string, aggs []Aggregate, opts ...Option) (*Reader, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := newJoinSide(rdr.Schema(), keys)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &grouper{mem: mem, cfg: cfg, rows: rows, aggs: aggs, parts: 1}
+	if err := g.buildSchema(); err != nil {
+		return nil, err
+	}
+
+	b := newBudget(mem, cfg.budget)
+	for rdr.Next() {
+		rec := rdr.Record()
+		if rec.NumRows() == 0 {
+			continue
+		}
+		if err := rows.add(rec); err != nil {
+			g.close()
+			return nil, err
+		}
+		if g.parts > 1 {
+			continue
+		}
+		b.add(rec)
+		if b.exceeded() {
+			if err := rows.spill(mem, opts); err != nil {
+				g.close()
+				return nil, err
+			}
+			g.parts = cfg.partitions
+		}
+	}
+	if err := readerErr(rdr); err != nil {
+		g.close()
+		return nil, err
+	}
+
+	return newReader(g.schema, g.next, g.close), nil
+}
+
+type grouper struct {
+	mem    memory.Allocator
+	cfg    *config
+	schema *arrow.Schema
+	rows   *joinSide
+	aggs   []Aggregate
+	// cols are the aggregated columns of rows, -1 for the counts of rows.
+	cols []int
+
+	// parts is the number of partitions, 1 while nothing was spilled.
+	parts int
+	part  int
+	cur   array.Record
+	beg   int64
+}
+
+func (g *grouper) buildSchema() error {
+	fields := make([]arrow.Field, 0, len(g.rows.keys)+len(g.aggs))
+	names := make(map[string]bool, cap(fields))
+	for _, k := range g.rows.keys {
+		f := g.rows.schema.Field(k)
+		names[f.Name] = true
+		fields = append(fields, f)
+	}
+
+	g.cols = make([]int, len(g.aggs))
+	for i, agg := range g.aggs {
+		g.cols[i] = -1
+		if agg.Column != "" {
+			idx, err := columnIndices(g.rows.schema, []string{agg.Column})
+			if err != nil {
+				return err
+			}
+			g.cols[i] = idx[0]
+		}
+	}
+
+	// Aggregating no rows gives the types of the aggregates, and rejects
+	// the aggregates the columns do not support.
+	empty := make([]array.Interface, len(g.rows.schema.Fields()))
+	for i, f := range g.rows.schema.Fields() {
+		bldr := array.NewBuilder(g.mem, f.Type)
+		empty[i] = bldr.NewArray()
+		bldr.Release()
+	}
+	rec := array.NewRecord(g.rows.schema, empty, 0)
+	releaseArrays(empty)
+	cols := recordColumns(g.rows.schema, []array.Record{rec})
+	rec.Release()
+	defer releaseColumns(cols)
+	arrs, err := g.aggregates(cols, &compute.Groups{})
+	if err != nil {
+		return err
+	}
+	defer releaseArrays(arrs)
+
+	for i, agg := range g.aggs {
+		name := agg.Name
+		switch {
+		case name != "":
+		case agg.Column != "":
+			name = agg.Column
+		default:
+			name = agg.Kind.String()
+		}
+		if names[name] {
+			return fmt.Errorf("spill: column %q is in the output of GroupBy twice", name)
+		}
+		names[name] = true
+		nullable := agg.Kind != compute.AggCount && agg.Kind != compute.AggCountDistinct
+		fields = append(fields, arrow.Field{Name: name, Type: arrs[i].DataType(), Nullable: nullable})
+	}
+
+	g.schema = arrow.NewSchema(fields, nil)
+	return nil
+}
+
+// aggregates returns the aggregates of cols for every group of groups.
+// The arrays must be released.
+func (g *grouper) aggregates(cols []*array.Column, groups *compute.Groups) ([]array.Interface, error) {
+	arrs := make([]array.Interface, 0, len(g.aggs))
+	for i, agg := range g.aggs {
+		var col *array.Column
+		if g.cols[i] >= 0 {
+			col = cols[g.cols[i]]
+		}
+		res, err := compute.AggregateGroups(g.mem, col, groups, agg.Kind)
+		if err != nil {
+			releaseArrays(arrs)
+			return nil, fmt.Errorf("spill: aggregate %v of %q: %w", agg.Kind, agg.Column, err)
+		}
+		arr := res.Data().Chunk(0)
+		arr.Retain()
+		res.Release()
+		arrs = append(arrs, arr)
+	}
+	return arrs, nil
+}
+
+// group groups the rows of partition i, leaving cur nil when it has none.
+func (g *grouper) group(i int) error {
+	cols, n, err := g.rows.partition(i)
+	if err != nil {
+		return err
+	}
+	defer releaseColumns(cols)
+	if n == 0 {
+		return nil
+	}
+
+	keys := g.rows.keyColumns(cols)
+	groups, err := compute.GroupRows(keys...)
+	if err != nil {
+		return err
+	}
+
+	bldr := array.NewInt64Builder(g.mem)
+	bldr.AppendValues(groups.First, nil)
+	first := bldr.NewInt64Array()
+	bldr.Release()
+	defer first.Release()
+
+	arrs, err := takeArrays(g.mem, keys, first)
+	if err != nil {
+		return err
+	}
+	defer releaseArrays(arrs)
+	aggs, err := g.aggregates(cols, groups)
+	if err != nil {
+		return err
+	}
+	defer releaseArrays(aggs)
+
+	g.cur = array.NewRecord(g.schema, append(arrs, aggs...), int64(groups.NumGroups()))
+	g.beg = 0
+	return nil
+}
+
+// next returns the next batch of groups, or nil after the last one.
+func (g *grouper) next() (array.Record, error) {
+	for {
+		if g.cur != nil {
+			if g.beg < g.cur.NumRows() {
+				end := g.beg + int64(g.cfg.batchSize)
+				if end > g.cur.NumRows() {
+					end = g.cur.NumRows()
+				}
+				rec := g.cur.NewSlice(g.beg, end)
+				g.beg = end
+				return rec, nil
+			}
+			g.cur.Release()
+			g.cur = nil
+		}
+		if g.part == g.parts {
+			return nil, nil
+		}
+		if err := g.group(g.part); err != nil {
+			return nil, err
+		}
+		g.part++
+	}
+}
+
+func (g *grouper) close() {
+	if g.cur != nil {
+		g.cur.Release()
+		g.cur = nil
+	}
+	g.rows.close()
+}
@@ -0,0 +1,456 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spill
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// HashJoin returns a Reader over the rows of left joined with the rows of right,
+// matching the leftKeys columns of left with the rightKeys columns of right
+// like compute.HashJoin does. The records hold the columns of left followed by
+// the columns of right other than its keys, which are nullable for a LeftJoin.
+//
+// right is read to the end and buffered first. When it is no larger than the
+// broadcast threshold, see WithBroadcastThreshold, its hash table is built once
+// and the records of left are joined against it one at a time while the Reader
+// is read, so the output follows the order of left and left is never buffered.
+// This suits joining a large fact table with a small dimension table.
+//
+// Otherwise left is buffered too. When both sides exceed the memory budget the
+// rows of both sides are written to hash partitions of their keys, and each
+// pair of partitions is joined in memory while the Reader is read, so the
+// output is grouped by partition instead of following the order of left. Both
+// sides are then read to the end before HashJoin returns.
+//
+// WithJoinStrategy overrides the choice between the two.
+func HashJoin(mem memory.Allocator, left, right array.RecordReader, leftKeys, rightKeys []string, how compute.JoinType, opts ...Option) (*Reader, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(leftKeys) != len(rightKeys) {
+		return nil, fmt.Errorf("spill: HashJoin needs the same number of left and right keys (%d != %d)", len(leftKeys), len(rightKeys))
+	}
+
+	j := &joiner{mem: mem, cfg: cfg, how: how, parts: 1}
+	if j.left, err = newJoinSide(left.Schema(), leftKeys); err != nil {
+		return nil, err
+	}
+	if j.right, err = newJoinSide(right.Schema(), rightKeys); err != nil {
+		return nil, err
+	}
+	if err := j.buildSchema(); err != nil {
+		return nil, err
+	}
+
+	b := newBudget(mem, cfg.budget)
+	read := func(rdr array.RecordReader, side *joinSide) error {
+		for rdr.Next() {
+			rec := rdr.Record()
+			if rec.NumRows() == 0 {
+				continue
+			}
+			if err := side.add(rec); err != nil {
+				return err
+			}
+			if j.parts > 1 {
+				continue
+			}
+			b.add(rec)
+			if b.exceeded() {
+				if cfg.strategy == BroadcastStrategy {
+					return fmt.Errorf("spill: right side of a broadcast HashJoin exceeds the memory budget of %d bytes", cfg.budget)
+				}
+				if err := j.spill(opts); err != nil {
+					return err
+				}
+			}
+		}
+		return readerErr(rdr)
+	}
+
+	if err := read(right, j.right); err != nil {
+		j.close()
+		return nil, err
+	}
+	switch {
+	case cfg.strategy == BroadcastStrategy,
+		cfg.strategy == AutoStrategy && j.parts == 1 && b.held <= cfg.broadcastThreshold:
+		if err := j.broadcast(left); err != nil {
+			j.close()
+			return nil, err
+		}
+	default:
+		if err := read(left, j.left); err != nil {
+			j.close()
+			return nil, err
+		}
+	}
+
+	return newReader(j.schema, j.next, j.close), nil
+}
+
+// JoinStrategy selects how HashJoin joins its sides. It is a hint for the
+// cases where the size of the right side is not a good guide, such as a right
+// side known to be small but above the broadcast threshold.
+type JoinStrategy int
+
+const (
+	// AutoStrategy broadcasts the right side when it is no larger than the
+	// broadcast threshold, and partitions both sides otherwise.
+	AutoStrategy JoinStrategy = iota
+	// BroadcastStrategy builds the hash table of the right side once and
+	// streams the left side against it. The right side must fit in the memory
+	// budget.
+	BroadcastStrategy
+	// PartitionStrategy buffers both sides, spilling them to hash partitions
+	// when they exceed the memory budget.
+	PartitionStrategy
+)
+
+func (s JoinStrategy) String() string {
+	switch s {
+	case AutoStrategy:
+		return "auto"
+	case BroadcastStrategy:
+		return "broadcast"
+	case PartitionStrategy:
+		return "partition"
+	default:
+		return fmt.Sprintf("JoinStrategy(%d)", int(s))
+	}
+}
+
+// joinSide holds the rows of one side of a join, in memory or in partitions.
+type joinSide struct {
+	schema *arrow.Schema
+	names  []string
+	keys   []int
+	recs   []array.Record
+	parts  *Partitioner
+}
+
+func newJoinSide(schema *arrow.Schema, keys []string) (*joinSide, error) {
+	idx, err := columnIndices(schema, keys)
+	if err != nil {
+		return nil, err
+	}
+	return &joinSide{schema: schema, names: keys, keys: idx}, nil
+}
+
+func (s *joinSide) add(rec array.Record) error {
+	if s.parts != nil {
+		return s.parts.Write(rec)
+	}
+	rec.Retain()
+	s.recs = append(s.recs, rec)
+	return nil
+}
+
+// spill moves the buffered rows to partitions.
+func (s *joinSide) spill(mem memory.Allocator, opts []Option) error {
+	parts, err := NewPartitioner(mem, s.schema, s.names, opts...)
+	if err != nil {
+		return err
+	}
+	s.parts = parts
+	for _, rec := range s.recs {
+		if err := parts.Write(rec); err != nil {
+			return err
+		}
+	}
+	releaseRecords(s.recs)
+	s.recs = nil
+	return nil
+}
+
+// partition returns the columns of the rows of partition i.
+// The columns must be released.
+func (s *joinSide) partition(i int) ([]*array.Column, int, error) {
+	recs := s.recs
+	if s.parts != nil {
+		var err error
+		if recs, err = s.parts.Records(i); err != nil {
+			return nil, 0, err
+		}
+		defer releaseRecords(recs)
+	}
+	return recordColumns(s.schema, recs), len(recs), nil
+}
+
+func (s *joinSide) keyColumns(cols []*array.Column) []*array.Column {
+	keys := make([]*array.Column, len(s.keys))
+	for i, k := range s.keys {
+		keys[i] = cols[k]
+	}
+	return keys
+}
+
+func (s *joinSide) close() {
+	releaseRecords(s.recs)
+	s.recs = nil
+	if s.parts != nil {
+		s.parts.Close()
+	}
+}
+
+type joiner struct {
+	mem    memory.Allocator
+	cfg    *config
+	how    compute.JoinType
+	schema *arrow.Schema
+	left   *joinSide
+	right  *joinSide
+	// values are the columns of right in the output.
+	values []int
+
+	// parts is the number of partitions, 1 while nothing was spilled.
+	parts int
+	part  int
+	cur   *joinedPartition
+
+	// table is the hash table of right when it is broadcast, joined with the
+	// records of stream, and rvalues are its columns in the output.
+	table   *compute.HashTable
+	rvalues []*array.Column
+	stream  array.RecordReader
+}
+
+// joinedPartition holds the matching rows of a pair of partitions.
+type joinedPartition struct {
+	left, right []*array.Column
+	li, ri      *array.Int64
+	beg         int
+}
+
+func (p *joinedPartition) release() {
+	releaseColumns(p.left)
+	releaseColumns(p.right)
+	p.li.Release()
+	p.ri.Release()
+}
+
+func (j *joiner) buildSchema() error {
+	if j.how != compute.InnerJoin && j.how != compute.LeftJoin {
+		return fmt.Errorf("spill: %s joins are not supported", j.how)
+	}
+	fields := append([]arrow.Field(nil), j.left.schema.Fields()...)
+	names := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		names[f.Name] = true
+	}
+
+	for i, k := range j.left.keys {
+		l := j.left.schema.Field(k)
+		r := j.right.schema.Field(j.right.keys[i])
+		if !arrow.TypeEqual(l.Type, r.Type) {
+			return fmt.Errorf("spill: key column %q is %s but %q is %s", l.Name, l.Type, r.Name, r.Type)
+		}
+	}
+
+	isKey := make(map[int]bool, len(j.right.keys))
+	for _, k := range j.right.keys {
+		isKey[k] = true
+	}
+	for i, f := range j.right.schema.Fields() {
+		if isKey[i] {
+			continue
+		}
+		if names[f.Name] {
+			return fmt.Errorf("spill: column %q is on both sides of the join", f.Name)
+		}
+		if j.how == compute.LeftJoin {
+			f.Nullable = true
+		}
+		fields = append(fields, f)
+		j.values = append(j.values, i)
+	}
+
+	j.schema = arrow.NewSchema(fields, nil)
+	return nil
+}
+
+// spill moves the buffered rows of both sides to partitions.
+func (j *joiner) spill(opts []Option) error {
+	if err := j.right.spill(j.mem, opts); err != nil {
+		return err
+	}
+	if err := j.left.spill(j.mem, opts); err != nil {
+		return err
+	}
+	j.parts = j.cfg.partitions
+	return nil
+}
+
+// next returns the next batch of joined rows, or nil after the last one.
+func (j *joiner) next() (array.Record, error) {
+	for {
+		if j.cur != nil {
+			rec, err := j.batch()
+			if rec != nil || err != nil {
+				return rec, err
+			}
+			j.cur.release()
+			j.cur = nil
+		}
+		if j.table != nil {
+			if err := j.probe(); err != nil {
+				return nil, err
+			}
+			if j.cur == nil {
+				return nil, nil
+			}
+			continue
+		}
+		if j.part == j.parts {
+			return nil, nil
+		}
+		if err := j.join(j.part); err != nil {
+			return nil, err
+		}
+		j.part++
+	}
+}
+
+// broadcast builds the hash table of the buffered rows of right, to join the
+// records of left with while the Reader is read.
+func (j *joiner) broadcast(left array.RecordReader) error {
+	rcols, _, err := j.right.partition(0)
+	if err != nil {
+		return err
+	}
+	defer releaseColumns(rcols)
+	if j.table, err = compute.NewHashTable(j.right.keyColumns(rcols)); err != nil {
+		return err
+	}
+	for _, c := range j.values {
+		rcols[c].Retain()
+		j.rvalues = append(j.rvalues, rcols[c])
+	}
+	j.right.close()
+
+	left.Retain()
+	j.stream = left
+	return nil
+}
+
+// probe joins the next record of stream with the hash table of right, leaving
+// cur nil after the last one.
+func (j *joiner) probe() error {
+	for j.stream.Next() {
+		rec := j.stream.Record()
+		if rec.NumRows() == 0 {
+			continue
+		}
+		lcols := recordColumns(j.left.schema, []array.Record{rec})
+		li, ri, err := j.table.Join(j.mem, j.left.keyColumns(lcols), j.how)
+		if err != nil {
+			releaseColumns(lcols)
+			return err
+		}
+		for _, col := range j.rvalues {
+			col.Retain()
+		}
+		values := append([]*array.Column(nil), j.rvalues...)
+		j.cur = &joinedPartition{left: lcols, right: values, li: li, ri: ri}
+		return nil
+	}
+	return readerErr(j.stream)
+}
+
+// join joins partition i of both sides.
+func (j *joiner) join(i int) error {
+	lcols, n, err := j.left.partition(i)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		releaseColumns(lcols)
+		return nil
+	}
+	rcols, _, err := j.right.partition(i)
+	if err != nil {
+		releaseColumns(lcols)
+		return err
+	}
+
+	li, ri, err := compute.HashJoin(j.mem, j.left.keyColumns(lcols), j.right.keyColumns(rcols), j.how)
+	if err != nil {
+		releaseColumns(lcols)
+		releaseColumns(rcols)
+		return err
+	}
+
+	values := make([]*array.Column, len(j.values))
+	for v, c := range j.values {
+		values[v] = rcols[c]
+		values[v].Retain()
+	}
+	releaseColumns(rcols)
+
+	j.cur = &joinedPartition{left: lcols, right: values, li: li, ri: ri}
+	return nil
+}
+
+// batch returns the next batch of rows of the current partition, or nil after the last one.
+func (j *joiner) batch() (array.Record, error) {
+	p := j.cur
+	if p.beg >= p.li.Len() {
+		return nil, nil
+	}
+	end := p.beg + j.cfg.batchSize
+	if end > p.li.Len() {
+		end = p.li.Len()
+	}
+	li := array.NewSlice(p.li, int64(p.beg), int64(end)).(*array.Int64)
+	defer li.Release()
+	ri := array.NewSlice(p.ri, int64(p.beg), int64(end)).(*array.Int64)
+	defer ri.Release()
+	p.beg = end
+
+	larrs, err := takeArrays(j.mem, p.left, li)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseArrays(larrs)
+	rarrs, err := takeArrays(j.mem, p.right, ri)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseArrays(rarrs)
+
+	return array.NewRecord(j.schema, append(larrs, rarrs...), int64(li.Len())), nil
+}
+
+func (j *joiner) close() {
+	if j.cur != nil {
+		j.cur.release()
+		j.cur = nil
+	}
+	j.left.close()
+	j.right.close()
+	releaseColumns(j.rvalues)
+	j.rvalues = nil
+	if j.stream != nil {
+		j.stream.Release()
+		j.stream = nil
+	}
+}
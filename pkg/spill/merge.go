@@ -0,0 +1,374 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spill
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// MergeJoin returns a Reader over the rows of left joined with the rows of
+// right, matching the leftKeys columns of left with the rightKeys columns of
+// right. The records have the same columns as the ones of HashJoin.
+//
+// Both sides must be sorted by their keys, as recorded in the metadata of the
+// key fields by compute.WithSortOrder or by Sort, in the same orders. The sides
+// are then read side by side one record at a time, so only the records holding
+// the current left row and the right rows with the current key are held in
+// memory, and the output follows the order of left. An error is returned when
+// the rows turn out not to be sorted.
+func MergeJoin(mem memory.Allocator, left, right array.RecordReader, leftKeys, rightKeys []string, how compute.JoinType, opts ...Option) (*Reader, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(leftKeys) != len(rightKeys) {
+		return nil, fmt.Errorf("spill: MergeJoin needs the same number of left and right keys (%d != %d)", len(leftKeys), len(rightKeys))
+	}
+	if len(leftKeys) == 0 {
+		return nil, errors.New("spill: MergeJoin needs at least one key")
+	}
+
+	// The output schema is the one of HashJoin.
+	j := &joiner{how: how}
+	if j.left, err = newJoinSide(left.Schema(), leftKeys); err != nil {
+		return nil, err
+	}
+	if j.right, err = newJoinSide(right.Schema(), rightKeys); err != nil {
+		return nil, err
+	}
+	if err := j.buildSchema(); err != nil {
+		return nil, err
+	}
+
+	orders := make([]compute.SortOrder, len(leftKeys))
+	for i := range orders {
+		l := left.Schema().Field(j.left.keys[i])
+		r := right.Schema().Field(j.right.keys[i])
+		lo, lok := compute.FieldSortOrder(l)
+		ro, rok := compute.FieldSortOrder(r)
+		switch {
+		case !lok:
+			return nil, fmt.Errorf("spill: left key column %q is not marked as sorted", l.Name)
+		case !rok:
+			return nil, fmt.Errorf("spill: right key column %q is not marked as sorted", r.Name)
+		case lo != ro:
+			return nil, fmt.Errorf("spill: key column %q is sorted %s but %q is sorted %s", l.Name, lo, r.Name, ro)
+		}
+		orders[i] = lo
+	}
+
+	m := &mergeJoiner{
+		how:    how,
+		cfg:    cfg,
+		values: j.values,
+		left:   &mergeSide{rdr: left, keys: j.left.keys},
+		right:  &mergeSide{rdr: right, keys: j.right.keys},
+		bldr:   array.NewRecordBuilder(mem, j.schema),
+		ll:     &pairComparator{orders: orders},
+		lr:     &pairComparator{orders: orders},
+		rr:     &pairComparator{orders: orders},
+	}
+	left.Retain()
+	right.Retain()
+	return newReader(j.schema, m.next, m.close), nil
+}
+
+// rowRef addresses a row of a record.
+type rowRef struct {
+	rec array.Record
+	row int
+}
+
+// mergeSide reads the rows of one side of a merge join in order.
+type mergeSide struct {
+	rdr  array.RecordReader
+	keys []int
+	cur  rowRef
+	// prev is the previous row, retained to check the order of the rows.
+	prev rowRef
+}
+
+// next moves to the next row, returning false after the last one.
+func (s *mergeSide) next() (bool, error) {
+	if s.cur.rec != nil {
+		s.cur.row++
+		if s.cur.row < int(s.cur.rec.NumRows()) {
+			return true, nil
+		}
+		s.cur.rec.Release()
+		s.cur.rec = nil
+	}
+	for s.rdr.Next() {
+		rec := s.rdr.Record()
+		if rec.NumRows() == 0 {
+			continue
+		}
+		rec.Retain()
+		s.cur = rowRef{rec: rec}
+		return true, nil
+	}
+	return false, readerErr(s.rdr)
+}
+
+// hasNullKey reports whether a key of the current row is null. Null keys never match.
+func (s *mergeSide) hasNullKey() bool {
+	for _, k := range s.keys {
+		if s.cur.rec.Column(k).IsNull(s.cur.row) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOrder checks that the current row does not sort before the previous one.
+func (s *mergeSide) checkOrder(cmp *pairComparator, side string) error {
+	if s.prev.rec != nil {
+		c, err := cmp.compare(s.prev, s.keys, s.cur, s.keys)
+		if err != nil {
+			return err
+		}
+		if c > 0 {
+			return fmt.Errorf("spill: %s side of MergeJoin is not sorted by its keys", side)
+		}
+		s.prev.rec.Release()
+	}
+	s.cur.rec.Retain()
+	s.prev = s.cur
+	return nil
+}
+
+func (s *mergeSide) close() {
+	for _, ref := range []rowRef{s.cur, s.prev} {
+		if ref.rec != nil {
+			ref.rec.Release()
+		}
+	}
+	s.cur, s.prev = rowRef{}, rowRef{}
+	s.rdr.Release()
+}
+
+type mergeJoiner struct {
+	how    compute.JoinType
+	cfg    *config
+	values []int
+	left   *mergeSide
+	right  *mergeSide
+	bldr   *array.RecordBuilder
+
+	// ll, lr and rr compare left rows together, left rows with right rows and
+	// right rows together.
+	ll, lr, rr *pairComparator
+
+	// active is true while the current left row is being joined, and pos is
+	// the next row of group to pair it with.
+	active bool
+	pos    int
+	// group holds the right rows with the key of group[0], each retained.
+	group []rowRef
+	// pending is true when the current row of right is not in group yet.
+	pending   bool
+	rightDone bool
+}
+
+// next returns the next batch of joined rows, or nil after the last one.
+func (m *mergeJoiner) next() (array.Record, error) {
+	rows := 0
+	for rows < m.cfg.batchSize {
+		if !m.active {
+			ok, err := m.left.next()
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				break
+			}
+			if err := m.left.checkOrder(m.ll, "left"); err != nil {
+				return nil, err
+			}
+			matched, err := m.match()
+			if err != nil {
+				return nil, err
+			}
+			if !matched {
+				if m.how == compute.LeftJoin {
+					if err := m.emit(nil); err != nil {
+						return nil, err
+					}
+					rows++
+				}
+				continue
+			}
+			m.active, m.pos = true, 0
+		}
+
+		for ; m.pos < len(m.group) && rows < m.cfg.batchSize; m.pos++ {
+			if err := m.emit(&m.group[m.pos]); err != nil {
+				return nil, err
+			}
+			rows++
+		}
+		if m.pos == len(m.group) {
+			m.active = false
+		}
+	}
+	if rows == 0 {
+		return nil, nil
+	}
+	return m.bldr.NewRecord(), nil
+}
+
+// match moves group to the right rows with the key of the current left row,
+// and reports whether there are any.
+func (m *mergeJoiner) match() (bool, error) {
+	if m.left.hasNullKey() {
+		return false, nil
+	}
+	for {
+		if len(m.group) == 0 {
+			if m.rightDone {
+				return false, nil
+			}
+			if err := m.loadGroup(); err != nil {
+				return false, err
+			}
+			continue
+		}
+		c, err := m.lr.compare(m.left.cur, m.left.keys, m.group[0], m.right.keys)
+		if err != nil {
+			return false, err
+		}
+		if c > 0 {
+			m.releaseGroup()
+			continue
+		}
+		return c == 0, nil
+	}
+}
+
+// loadGroup reads the right rows sharing the key of the next right row into group.
+func (m *mergeJoiner) loadGroup() error {
+	for {
+		if !m.pending {
+			ok, err := m.right.next()
+			if err != nil {
+				return err
+			}
+			if !ok {
+				m.rightDone = true
+				return nil
+			}
+			if err := m.right.checkOrder(m.rr, "right"); err != nil {
+				return err
+			}
+			m.pending = true
+		}
+		if m.right.hasNullKey() {
+			m.pending = false
+			continue
+		}
+		if len(m.group) > 0 {
+			c, err := m.rr.compare(m.group[0], m.right.keys, m.right.cur, m.right.keys)
+			if err != nil {
+				return err
+			}
+			if c != 0 {
+				return nil
+			}
+		}
+		m.right.cur.rec.Retain()
+		m.group = append(m.group, m.right.cur)
+		m.pending = false
+	}
+}
+
+func (m *mergeJoiner) releaseGroup() {
+	for _, ref := range m.group {
+		ref.rec.Release()
+	}
+	m.group = m.group[:0]
+}
+
+// emit appends the current left row joined with the right row r, or with
+// nulls when r is nil.
+func (m *mergeJoiner) emit(r *rowRef) error {
+	l := m.left.cur
+	ncols := int(l.rec.NumCols())
+	for i := 0; i < ncols; i++ {
+		if err := compute.AppendValue(m.bldr.Field(i), l.rec.Column(i), l.row); err != nil {
+			return err
+		}
+	}
+	for v, c := range m.values {
+		if r == nil {
+			m.bldr.Field(ncols + v).AppendNull()
+			continue
+		}
+		if err := compute.AppendValue(m.bldr.Field(ncols+v), r.rec.Column(c), r.row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mergeJoiner) close() {
+	m.releaseGroup()
+	m.left.close()
+	m.right.close()
+	m.ll.release()
+	m.lr.release()
+	m.rr.release()
+	m.bldr.Release()
+}
+
+// pairComparator compares the keys of rows of two records, keeping the
+// comparator of the last pair of records it was given.
+type pairComparator struct {
+	orders []compute.SortOrder
+	a, b   array.Record
+	cmp    *compute.RowComparator
+}
+
+func (p *pairComparator) compare(a rowRef, akeys []int, b rowRef, bkeys []int) (int, error) {
+	if a.rec != p.a || b.rec != p.b {
+		keys := make([][]array.Interface, len(akeys))
+		for k := range akeys {
+			keys[k] = []array.Interface{a.rec.Column(akeys[k]), b.rec.Column(bkeys[k])}
+		}
+		cmp, err := compute.NewRowComparator(keys, p.orders)
+		if err != nil {
+			return 0, err
+		}
+		// The records are retained so that their addresses are not reused by
+		// other records while the comparator is cached.
+		a.rec.Retain()
+		b.rec.Retain()
+		p.release()
+		p.a, p.b, p.cmp = a.rec, b.rec, cmp
+	}
+	return p.cmp.Compare(0, a.row, 1, b.row), nil
+}
+
+func (p *pairComparator) release() {
+	if p.a != nil {
+		p.a.Release()
+		p.b.Release()
+		p.a, p.b, p.cmp = nil, nil, nil
+	}
+}
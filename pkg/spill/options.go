@@ -0,0 +1,127 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spill
+
+import (
+	"fmt"
+)
+
+// Option is an option that may be passed to the operators of the package.
+type Option func(interface{}) error
+
+type config struct {
+	budget     int64
+	dir        string
+	batchSize  int
+	partitions int
+
+	strategy           JoinStrategy
+	broadcastThreshold int64
+}
+
+func newConfig(opts ...Option) (*config, error) {
+	cfg := &config{
+		budget:             256 << 20,
+		batchSize:          1024,
+		partitions:         16,
+		broadcastThreshold: 16 << 20,
+	}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// option returns an Option applying fn to the operator configuration.
+func option(name string, fn func(cfg *config) error) Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply %s to: %T", name, p)
+		}
+		return fn(cfg)
+	}
+}
+
+// WithMemoryBudget specifies the number of bytes an operator may buffer before
+// spilling to disk, 256MiB by default.
+func WithMemoryBudget(bytes int64) Option {
+	return option("WithMemoryBudget", func(cfg *config) error {
+		if bytes <= 0 {
+			return fmt.Errorf("spill: memory budget must be > 0, got %d", bytes)
+		}
+		cfg.budget = bytes
+		return nil
+	})
+}
+
+// WithTempDir specifies the directory of the temporary files, the default
+// directory for temporary files of the system by default.
+func WithTempDir(dir string) Option {
+	return option("WithTempDir", func(cfg *config) error {
+		cfg.dir = dir
+		return nil
+	})
+}
+
+// WithBatchSize specifies the maximum number of rows of the records written to
+// disk and of the records returned, 1024 by default.
+func WithBatchSize(n int) Option {
+	return option("WithBatchSize", func(cfg *config) error {
+		if n <= 0 {
+			return fmt.Errorf("spill: batch size must be > 0, got %d", n)
+		}
+		cfg.batchSize = n
+		return nil
+	})
+}
+
+// WithPartitions specifies the number of hash partitions rows are spilled to, 16 by default.
+func WithPartitions(n int) Option {
+	return option("WithPartitions", func(cfg *config) error {
+		if n <= 0 {
+			return fmt.Errorf("spill: number of partitions must be > 0, got %d", n)
+		}
+		cfg.partitions = n
+		return nil
+	})
+}
+
+// WithBroadcastThreshold specifies the number of bytes up to which the right
+// side of HashJoin is broadcast, 16MiB by default.
+func WithBroadcastThreshold(bytes int64) Option {
+	return option("WithBroadcastThreshold", func(cfg *config) error {
+		if bytes < 0 {
+			return fmt.Errorf("spill: broadcast threshold must be >= 0, got %d", bytes)
+		}
+		cfg.broadcastThreshold = bytes
+		return nil
+	})
+}
+
+// WithJoinStrategy specifies how HashJoin joins its sides, AutoStrategy by default.
+func WithJoinStrategy(s JoinStrategy) Option {
+	return option("WithJoinStrategy", func(cfg *config) error {
+		switch s {
+		case AutoStrategy, BroadcastStrategy, PartitionStrategy:
+		default:
+			return fmt.Errorf("spill: unknown join strategy %v", s)
+		}
+		cfg.strategy = s
+		return nil
+	})
+}
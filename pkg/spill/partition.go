@@ -0,0 +1,162 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spill
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/internal/hashing"
+)
+
+// Partitioner splits records into partitions by the hash of their key columns
+// and writes each partition to its own temporary file, so rows with equal keys
+// end up in the same partition. Operators grouping rows by key can then
+// process the partitions one at a time.
+type Partitioner struct {
+	mem    memory.Allocator
+	cfg    *config
+	schema *arrow.Schema
+	keys   []int
+	files  []*spillFile
+}
+
+// NewPartitioner creates a Partitioner for records of schema, hashing the named
+// key columns. The number of partitions is set with WithPartitions.
+func NewPartitioner(mem memory.Allocator, schema *arrow.Schema, keys []string, opts ...Option) (*Partitioner, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	keyIdx, err := columnIndices(schema, keys)
+	if err != nil {
+		return nil, err
+	}
+	return &Partitioner{
+		mem:    mem,
+		cfg:    cfg,
+		schema: schema,
+		keys:   keyIdx,
+		files:  make([]*spillFile, cfg.partitions),
+	}, nil
+}
+
+// NumPartitions returns the number of partitions.
+func (p *Partitioner) NumPartitions() int { return len(p.files) }
+
+// Write writes the rows of rec to their partitions.
+func (p *Partitioner) Write(rec array.Record) error {
+	if !rec.Schema().Equal(p.schema) {
+		return fmt.Errorf("spill: record schema does not match the partitioner schema")
+	}
+
+	hashes := make([]uint64, rec.NumRows())
+	for _, k := range p.keys {
+		err := hashing.Array(rec.Column(k), func(i int, h uint64, _ bool) {
+			hashes[i] = hashing.Combine(hashes[i], h)
+		})
+		if err != nil {
+			return fmt.Errorf("spill: key column %q: %w", p.schema.Field(k).Name, err)
+		}
+	}
+
+	rows := make([][]int64, len(p.files))
+	for i, h := range hashes {
+		part := h % uint64(len(p.files))
+		rows[part] = append(rows[part], int64(i))
+	}
+
+	cols := recordColumns(p.schema, []array.Record{rec})
+	defer releaseColumns(cols)
+
+	bldr := array.NewInt64Builder(p.mem)
+	defer bldr.Release()
+	for part, indices := range rows {
+		if len(indices) == 0 {
+			continue
+		}
+		if err := p.write(part, cols, bldr, indices); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Partitioner) write(part int, cols []*array.Column, bldr *array.Int64Builder, rows []int64) error {
+	if p.files[part] == nil {
+		f, err := newSpillFile(p.mem, p.cfg.dir, p.schema)
+		if err != nil {
+			return err
+		}
+		p.files[part] = f
+	}
+
+	bldr.AppendValues(rows, nil)
+	indices := bldr.NewInt64Array()
+	defer indices.Release()
+
+	rec, err := takeRecord(p.mem, p.schema, cols, indices)
+	if err != nil {
+		return err
+	}
+	defer rec.Release()
+	return p.files[part].write(rec)
+}
+
+// Records reads back the records of partition i. Partitions without rows
+// have no records. The records must be released.
+func (p *Partitioner) Records(i int) ([]array.Record, error) {
+	if i < 0 || i >= len(p.files) {
+		return nil, fmt.Errorf("spill: partition %d out of range [0, %d)", i, len(p.files))
+	}
+	if p.files[i] == nil {
+		return nil, nil
+	}
+
+	r, err := p.files[i].open(p.mem)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	recs := make([]array.Record, 0, r.NumRecords())
+	for j := 0; j < r.NumRecords(); j++ {
+		rec, err := r.Record(j)
+		if err != nil {
+			releaseRecords(recs)
+			return nil, fmt.Errorf("spill: could not read partition %d: %w", i, err)
+		}
+		rec.Retain()
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+// Close deletes the temporary files of the partitions.
+func (p *Partitioner) Close() error {
+	var err error
+	for i, f := range p.files {
+		if f == nil {
+			continue
+		}
+		if e := f.remove(); e != nil && err == nil {
+			err = e
+		}
+		p.files[i] = nil
+	}
+	return err
+}
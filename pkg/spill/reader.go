@@ -0,0 +1,97 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spill
+
+import (
+	"sync/atomic"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/internal/debug"
+)
+
+// Reader is a RecordReader over the records produced by an operator.
+// Releasing the Reader deletes the temporary files of the operator.
+type Reader struct {
+	refs    int64
+	schema  *arrow.Schema
+	next    func() (array.Record, error)
+	cleanup func()
+	rec     array.Record
+	err     error
+}
+
+// newReader returns a Reader returning the records of next until it returns
+// a nil record. cleanup is called when the Reader is released.
+func newReader(schema *arrow.Schema, next func() (array.Record, error), cleanup func()) *Reader {
+	return &Reader{refs: 1, schema: schema, next: next, cleanup: cleanup}
+}
+
+// Retain increases the reference count by 1.
+// Retain may be called simultaneously from multiple goroutines.
+func (r *Reader) Retain() {
+	atomic.AddInt64(&r.refs, 1)
+}
+
+// Release decreases the reference count by 1.
+// When the reference count goes to zero, the memory and the temporary files are freed.
+// Release may be called simultaneously from multiple goroutines.
+func (r *Reader) Release() {
+	refs := atomic.AddInt64(&r.refs, -1)
+	debug.Assert(refs >= 0, "too many releases")
+
+	if refs == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+		if r.cleanup != nil {
+			r.cleanup()
+			r.cleanup = nil
+		}
+	}
+}
+
+// Schema returns the schema of the records.
+func (r *Reader) Schema() *arrow.Schema { return r.schema }
+
+// Next moves to the next record, returning false at the end of the records
+// or when an error occurred.
+func (r *Reader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+	if r.next == nil || r.err != nil {
+		return false
+	}
+
+	r.rec, r.err = r.next()
+	if r.rec == nil {
+		r.next = nil
+		return false
+	}
+	return true
+}
+
+// Record returns the current record. It is valid until the next call to Next.
+func (r *Reader) Record() array.Record { return r.rec }
+
+// Err returns the error that stopped Next, if any.
+func (r *Reader) Err() error { return r.err }
+
+var (
+	_ array.RecordReader = (*Reader)(nil)
+)
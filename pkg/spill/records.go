@@ -0,0 +1,109 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spill
+
+import (
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// columnIndices returns the index of each named column of schema.
+func columnIndices(schema *arrow.Schema, names []string) ([]int, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("spill: at least one key column is required")
+	}
+	indices := make([]int, len(names))
+	for i, name := range names {
+		found := schema.FieldIndices(name)
+		if len(found) == 0 {
+			return nil, fmt.Errorf("spill: unknown column %q", name)
+		}
+		indices[i] = found[0]
+	}
+	return indices, nil
+}
+
+// recordColumns returns the columns of recs, each record being a chunk.
+// The columns must be released.
+func recordColumns(schema *arrow.Schema, recs []array.Record) []*array.Column {
+	cols := make([]*array.Column, len(schema.Fields()))
+	for i, field := range schema.Fields() {
+		chunks := make([]array.Interface, len(recs))
+		for j, rec := range recs {
+			chunks[j] = rec.Column(i)
+		}
+		chunked := array.NewChunked(field.Type, chunks)
+		cols[i] = array.NewColumn(field, chunked)
+		chunked.Release()
+	}
+	return cols
+}
+
+// takeRecord builds a record from the rows of cols at indices.
+func takeRecord(mem memory.Allocator, schema *arrow.Schema, cols []*array.Column, indices *array.Int64) (array.Record, error) {
+	arrs, err := takeArrays(mem, cols, indices)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseArrays(arrs)
+	return array.NewRecord(schema, arrs, int64(indices.Len())), nil
+}
+
+// takeArrays returns the rows of each of cols at indices. The arrays must be released.
+func takeArrays(mem memory.Allocator, cols []*array.Column, indices *array.Int64) ([]array.Interface, error) {
+	arrs := make([]array.Interface, 0, len(cols))
+	for _, col := range cols {
+		taken, err := compute.Take(mem, col, indices)
+		if err != nil {
+			releaseArrays(arrs)
+			return nil, fmt.Errorf("spill: column %q: %w", col.Name(), err)
+		}
+		arr := taken.Data().Chunk(0)
+		arr.Retain()
+		taken.Release()
+		arrs = append(arrs, arr)
+	}
+	return arrs, nil
+}
+
+func releaseArrays(arrs []array.Interface) {
+	for _, arr := range arrs {
+		arr.Release()
+	}
+}
+
+func releaseColumns(cols []*array.Column) {
+	for _, col := range cols {
+		col.Release()
+	}
+}
+
+func releaseRecords(recs []array.Record) {
+	for _, rec := range recs {
+		rec.Release()
+	}
+}
+
+// readerErr returns the error reported by rdr, if it reports errors.
+func readerErr(rdr array.RecordReader) error {
+	if r, ok := rdr.(interface{ Err() error }); ok {
+		return r.Err()
+	}
+	return nil
+}
@@ -0,0 +1,357 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spill
+
+import (
+	"container/heap"
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// SortKey is a column to sort by and the order of its values.
+type SortKey struct {
+	Name  string
+	Order compute.SortOrder
+}
+
+// Sort returns a Reader over the rows of rdr ordered by keys, like
+// compute.SortIndices orders them: nulls last and rows with equal keys in
+// input order.
+//
+// Whenever the buffered records exceed the memory budget they are sorted and
+// written to a temporary file as a run, and the runs are merged while the
+// Reader is read. rdr is read to the end before Sort returns.
+//
+// The fields of the keys record their order with compute.WithSortOrder, which
+// lets MergeJoin check that its inputs are sorted.
+func Sort(mem memory.Allocator, rdr array.RecordReader, keys []SortKey, opts ...Option) (*Reader, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(keys))
+	orders := make([]compute.SortOrder, len(keys))
+	for i, key := range keys {
+		names[i] = key.Name
+		orders[i] = key.Order
+	}
+	keyIdx, err := columnIndices(rdr.Schema(), names)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := append([]arrow.Field(nil), rdr.Schema().Fields()...)
+	for i, k := range keyIdx {
+		fields[k] = compute.WithSortOrder(fields[k], orders[i])
+	}
+	md := rdr.Schema().Metadata()
+	schema := arrow.NewSchema(fields, &md)
+
+	s := &sorter{mem: mem, cfg: cfg, schema: schema, keys: keyIdx, orders: orders}
+	b := newBudget(mem, cfg.budget)
+	var buf []array.Record
+	for rdr.Next() {
+		rec := rdr.Record()
+		if rec.NumRows() == 0 {
+			continue
+		}
+		rec.Retain()
+		buf = append(buf, rec)
+		b.add(rec)
+
+		if b.exceeded() {
+			err := s.spill(buf)
+			releaseRecords(buf)
+			buf = nil
+			b.reset()
+			if err != nil {
+				s.removeRuns()
+				return nil, err
+			}
+		}
+	}
+	if err := readerErr(rdr); err != nil {
+		releaseRecords(buf)
+		s.removeRuns()
+		return nil, err
+	}
+
+	if len(s.runs) == 0 {
+		next, cleanup, err := s.sorted(buf)
+		releaseRecords(buf)
+		if err != nil {
+			return nil, err
+		}
+		return newReader(schema, next, cleanup), nil
+	}
+
+	if len(buf) > 0 {
+		err := s.spill(buf)
+		releaseRecords(buf)
+		if err != nil {
+			s.removeRuns()
+			return nil, err
+		}
+	}
+
+	m, err := newMerger(s)
+	if err != nil {
+		s.removeRuns()
+		return nil, err
+	}
+	return newReader(schema, m.next, m.close), nil
+}
+
+type sorter struct {
+	mem    memory.Allocator
+	cfg    *config
+	schema *arrow.Schema
+	keys   []int
+	orders []compute.SortOrder
+	runs   []*spillFile
+}
+
+// sorted returns a function returning the rows of recs in order, one batch at
+// a time, and a function releasing the memory it holds.
+func (s *sorter) sorted(recs []array.Record) (func() (array.Record, error), func(), error) {
+	cols := recordColumns(s.schema, recs)
+	keys := make([]*array.Column, len(s.keys))
+	for i, k := range s.keys {
+		keys[i] = cols[k]
+	}
+	indices, err := compute.SortIndices(s.mem, keys, s.orders)
+	if err != nil {
+		releaseColumns(cols)
+		return nil, nil, err
+	}
+
+	beg := 0
+	next := func() (array.Record, error) {
+		if beg >= indices.Len() {
+			return nil, nil
+		}
+		end := beg + s.cfg.batchSize
+		if end > indices.Len() {
+			end = indices.Len()
+		}
+		batch := array.NewSlice(indices, int64(beg), int64(end)).(*array.Int64)
+		defer batch.Release()
+		beg = end
+		return takeRecord(s.mem, s.schema, cols, batch)
+	}
+	cleanup := func() {
+		indices.Release()
+		releaseColumns(cols)
+	}
+	return next, cleanup, nil
+}
+
+// spill sorts recs and writes them to a new run.
+func (s *sorter) spill(recs []array.Record) error {
+	next, cleanup, err := s.sorted(recs)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	run, err := newSpillFile(s.mem, s.cfg.dir, s.schema)
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, run)
+
+	for {
+		rec, err := next()
+		if err != nil {
+			return err
+		}
+		if rec == nil {
+			return nil
+		}
+		err = run.write(rec)
+		rec.Release()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *sorter) removeRuns() {
+	for _, run := range s.runs {
+		run.remove()
+	}
+	s.runs = nil
+}
+
+// runCursor is the position of a merger in a run.
+type runCursor struct {
+	r    *ipc.FileReader
+	n    int
+	rec  array.Record
+	row  int
+	done bool
+}
+
+// load moves to the next record of the run, marking the cursor done after the
+// last one. The last record is kept so the comparator always has a chunk per run.
+func (c *runCursor) load() error {
+	if c.n == c.r.NumRecords() {
+		c.done = true
+		return nil
+	}
+	rec, err := c.r.Record(c.n)
+	if err != nil {
+		return fmt.Errorf("spill: could not read run: %w", err)
+	}
+	rec.Retain()
+	if c.rec != nil {
+		c.rec.Release()
+	}
+	c.rec = rec
+	c.n++
+	c.row = 0
+	return nil
+}
+
+// merger merges the sorted runs of a sorter.
+type merger struct {
+	s       *sorter
+	cursors []*runCursor
+	cmp     *compute.RowComparator
+	heap    []int
+	bldr    *array.RecordBuilder
+}
+
+func newMerger(s *sorter) (*merger, error) {
+	m := &merger{s: s, cursors: make([]*runCursor, len(s.runs))}
+	for i, run := range s.runs {
+		r, err := run.open(s.mem)
+		if err != nil {
+			m.close()
+			return nil, err
+		}
+		m.cursors[i] = &runCursor{r: r}
+		if err := m.cursors[i].load(); err != nil {
+			m.close()
+			return nil, err
+		}
+	}
+	if err := m.compile(); err != nil {
+		m.close()
+		return nil, err
+	}
+
+	for i, c := range m.cursors {
+		if !c.done {
+			m.heap = append(m.heap, i)
+		}
+	}
+	heap.Init(m)
+	m.bldr = array.NewRecordBuilder(s.mem, s.schema)
+	return m, nil
+}
+
+// compile builds the comparator over the current record of every run.
+func (m *merger) compile() error {
+	keys := make([][]array.Interface, len(m.s.keys))
+	for k, col := range m.s.keys {
+		keys[k] = make([]array.Interface, len(m.cursors))
+		for i, c := range m.cursors {
+			keys[k][i] = c.rec.Column(col)
+		}
+	}
+	cmp, err := compute.NewRowComparator(keys, m.s.orders)
+	if err != nil {
+		return err
+	}
+	m.cmp = cmp
+	return nil
+}
+
+func (m *merger) Len() int { return len(m.heap) }
+func (m *merger) Less(i, j int) bool {
+	a, b := m.heap[i], m.heap[j]
+	if c := m.cmp.Compare(a, m.cursors[a].row, b, m.cursors[b].row); c != 0 {
+		return c < 0
+	}
+	// Runs hold consecutive input rows, so ties go to the earlier run.
+	return a < b
+}
+func (m *merger) Swap(i, j int)      { m.heap[i], m.heap[j] = m.heap[j], m.heap[i] }
+func (m *merger) Push(x interface{}) { m.heap = append(m.heap, x.(int)) }
+func (m *merger) Pop() interface{} {
+	n := len(m.heap) - 1
+	x := m.heap[n]
+	m.heap = m.heap[:n]
+	return x
+}
+
+// next returns the next batch of merged rows, or nil after the last one.
+func (m *merger) next() (array.Record, error) {
+	rows := 0
+	for rows < m.s.cfg.batchSize && len(m.heap) > 0 {
+		c := m.cursors[m.heap[0]]
+		for i := range m.s.schema.Fields() {
+			if err := compute.AppendValue(m.bldr.Field(i), c.rec.Column(i), c.row); err != nil {
+				return nil, err
+			}
+		}
+		rows++
+
+		c.row++
+		if c.row < int(c.rec.NumRows()) {
+			heap.Fix(m, 0)
+			continue
+		}
+		if err := c.load(); err != nil {
+			return nil, err
+		}
+		if c.done {
+			heap.Pop(m)
+			continue
+		}
+		if err := m.compile(); err != nil {
+			return nil, err
+		}
+		heap.Fix(m, 0)
+	}
+
+	if rows == 0 {
+		return nil, nil
+	}
+	return m.bldr.NewRecord(), nil
+}
+
+func (m *merger) close() {
+	if m.bldr != nil {
+		m.bldr.Release()
+	}
+	for _, c := range m.cursors {
+		if c == nil {
+			continue
+		}
+		if c.rec != nil {
+			c.rec.Release()
+		}
+		c.r.Close()
+	}
+	m.s.removeRuns()
+}
@@ -0,0 +1,551 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spill
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/compute"
+)
+
+// newTestReader returns a RecordReader over records of ids and names, batch rows at a time.
+func newTestReader(t *testing.T, mem memory.Allocator, ids []int64, names []string, batch int) array.RecordReader {
+	t.Helper()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "name", Type: arrow.BinaryTypes.String},
+	}, nil)
+
+	bldr := array.NewRecordBuilder(mem, schema)
+	defer bldr.Release()
+
+	var recs []array.Record
+	for beg := 0; beg < len(ids); beg += batch {
+		end := beg + batch
+		if end > len(ids) {
+			end = len(ids)
+		}
+		for i := beg; i < end; i++ {
+			if ids[i] < 0 {
+				bldr.Field(0).AppendNull()
+			} else {
+				bldr.Field(0).(*array.Int64Builder).Append(ids[i])
+			}
+			bldr.Field(1).(*array.StringBuilder).Append(names[i])
+		}
+		recs = append(recs, bldr.NewRecord())
+	}
+
+	rdr, err := array.NewRecordReader(schema, recs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range recs {
+		rec.Release()
+	}
+	return rdr
+}
+
+// readAll returns the rows of r formatted one per line.
+func readAll(t *testing.T, r *Reader) []string {
+	t.Helper()
+	var rows []string
+	for r.Next() {
+		rec := r.Record()
+		for i := 0; i < int(rec.NumRows()); i++ {
+			var row []string
+			for _, col := range rec.Columns() {
+				if col.IsNull(i) {
+					row = append(row, "null")
+					continue
+				}
+				switch col := col.(type) {
+				case *array.Int64:
+					row = append(row, fmt.Sprint(col.Value(i)))
+				case *array.String:
+					row = append(row, col.Value(i))
+				}
+			}
+			rows = append(rows, strings.Join(row, " "))
+		}
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func newTempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "spill-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func assertEmptyDir(t *testing.T, dir string) {
+	t.Helper()
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Fatalf("expected the temporary files to be removed, found %d", len(files))
+	}
+}
+
+func TestSort(t *testing.T) {
+	ids := []int64{5, 3, -1, 8, 1, 3, 9, 0, 7, 3, 2, 6}
+	names := []string{"e", "c1", "n", "h", "a", "c2", "i", "z", "g", "c3", "b", "f"}
+	want := []string{
+		"9 i", "8 h", "7 g", "6 f", "5 e", "3 c1", "3 c2", "3 c3", "2 b", "1 a", "0 z", "null n",
+	}
+
+	for _, tc := range []struct {
+		name   string
+		budget int64
+	}{
+		{name: "in memory", budget: 1 << 20},
+		{name: "spilled", budget: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer pool.AssertSize(t, 0)
+			dir := newTempDir(t)
+			defer os.RemoveAll(dir)
+
+			rdr := newTestReader(t, pool, ids, names, 3)
+			defer rdr.Release()
+
+			r, err := Sort(pool, rdr, []SortKey{{Name: "id", Order: compute.Descending}},
+				WithMemoryBudget(tc.budget), WithTempDir(dir), WithBatchSize(5))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := readAll(t, r)
+			r.Release()
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Fatalf("got=%v, want=%v", got, want)
+			}
+			assertEmptyDir(t, dir)
+		})
+	}
+}
+
+func TestHashJoin(t *testing.T) {
+	want := map[compute.JoinType][]string{
+		compute.InnerJoin: {"1 a 1 x", "3 c 3 y", "3 c 3 z", "3 d 3 y", "3 d 3 z"},
+		compute.LeftJoin:  {"1 a 1 x", "2 b null null", "3 c 3 y", "3 c 3 z", "3 d 3 y", "3 d 3 z", "null e null null"},
+	}
+
+	for how, want := range want {
+		for _, tc := range []struct {
+			name     string
+			budget   int64
+			strategy JoinStrategy
+		}{
+			{name: "broadcast", budget: 1 << 20},
+			{name: "in memory", budget: 1 << 20, strategy: PartitionStrategy},
+			{name: "spilled", budget: 1},
+			{name: "broadcast hint", budget: 1 << 20, strategy: BroadcastStrategy},
+		} {
+			t.Run(fmt.Sprintf("%d/%s", how, tc.name), func(t *testing.T) {
+				pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+				defer pool.AssertSize(t, 0)
+				dir := newTempDir(t)
+				defer os.RemoveAll(dir)
+
+				left := newTestReader(t, pool, []int64{1, 2, 3, 3, -1}, []string{"a", "b", "c", "d", "e"}, 2)
+				defer left.Release()
+				right := newRightReader(t, pool)
+				defer right.Release()
+
+				r, err := HashJoin(pool, left, right, []string{"id"}, []string{"key"}, how,
+					WithMemoryBudget(tc.budget), WithTempDir(dir), WithPartitions(4), WithJoinStrategy(tc.strategy))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if got, want := len(r.Schema().Fields()), 4; got != want {
+					t.Fatalf("got=%d, want=%d fields", got, want)
+				}
+				got := readAll(t, r)
+				r.Release()
+				sort.Strings(got)
+				if strings.Join(got, ",") != strings.Join(want, ",") {
+					t.Fatalf("got=%v, want=%v", got, want)
+				}
+				assertEmptyDir(t, dir)
+			})
+		}
+	}
+}
+
+func TestHashJoinBroadcast(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	// A broadcast join streams left, so the output follows its order.
+	left := newTestReader(t, pool, []int64{3, 1, 2, 3}, []string{"a", "b", "c", "d"}, 1)
+	defer left.Release()
+	right := newRightReader(t, pool)
+	defer right.Release()
+	r, err := HashJoin(pool, left, right, []string{"id"}, []string{"key"}, compute.InnerJoin, WithBatchSize(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := readAll(t, r)
+	r.Release()
+	want := []string{"3 a 3 y", "3 a 3 z", "1 b 1 x", "3 d 3 y", "3 d 3 z"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got=%v, want=%v", got, want)
+	}
+
+	// A right side over the memory budget cannot be broadcast.
+	left = newTestReader(t, pool, []int64{1}, []string{"a"}, 1)
+	defer left.Release()
+	right = newRightReader(t, pool)
+	defer right.Release()
+	_, err = HashJoin(pool, left, right, []string{"id"}, []string{"key"}, compute.InnerJoin,
+		WithMemoryBudget(1), WithJoinStrategy(BroadcastStrategy))
+	if err == nil || err.Error() != "spill: right side of a broadcast HashJoin exceeds the memory budget of 1 bytes" {
+		t.Fatalf("got error %v", err)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	ids := []int64{3, 1, -1, 3, 2, 1, 3, -1}
+	names := []string{"c", "a", "n", "c2", "b", "a", "c", "m"}
+	aggs := []Aggregate{
+		{Kind: compute.AggCount},
+		{Column: "name", Kind: compute.AggMin},
+		{Column: "name", Kind: compute.AggCountDistinct, Name: "distinct"},
+		{Column: "id", Kind: compute.AggSum, Name: "sum"},
+	}
+	// In memory, the groups are in order of first appearance.
+	want := []string{"3 3 c 2 9", "1 2 a 1 2", "null 2 m 2 null", "2 1 b 1 2"}
+
+	for _, tc := range []struct {
+		name   string
+		budget int64
+	}{
+		{name: "in memory", budget: 1 << 20},
+		{name: "spilled", budget: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer pool.AssertSize(t, 0)
+			dir := newTempDir(t)
+			defer os.RemoveAll(dir)
+
+			rdr := newTestReader(t, pool, ids, names, 3)
+			defer rdr.Release()
+
+			r, err := GroupBy(pool, rdr, []string{"id"}, aggs,
+				WithMemoryBudget(tc.budget), WithTempDir(dir), WithPartitions(4), WithBatchSize(3))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var fields []string
+			for _, f := range r.Schema().Fields() {
+				fields = append(fields, fmt.Sprintf("%s:%s:%v", f.Name, f.Type, f.Nullable))
+			}
+			if got, want := strings.Join(fields, ","), "id:int64:true,count:int64:false,name:utf8:true,distinct:int64:false,sum:int64:true"; got != want {
+				t.Fatalf("schema=%s, want=%s", got, want)
+			}
+
+			got := readAll(t, r)
+			r.Release()
+			want := want
+			if tc.budget == 1 {
+				got = append([]string(nil), got...)
+				want = append([]string(nil), want...)
+				sort.Strings(got)
+				sort.Strings(want)
+			}
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Fatalf("got=%v, want=%v", got, want)
+			}
+			assertEmptyDir(t, dir)
+		})
+	}
+}
+
+func TestGroupByErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		keys []string
+		aggs []Aggregate
+		want string
+	}{
+		{"no keys", nil, nil, "spill: at least one key column is required"},
+		{"unknown key", []string{"key"}, nil, `spill: unknown column "key"`},
+		{"unknown column", []string{"id"}, []Aggregate{{Column: "value", Kind: compute.AggSum}}, `spill: unknown column "value"`},
+		{"sum of strings", []string{"id"}, []Aggregate{{Column: "name", Kind: compute.AggSum}}, `spill: aggregate sum of "name": `},
+		{"sum of rows", []string{"id"}, []Aggregate{{Kind: compute.AggSum}}, `spill: aggregate sum of "": compute: sum needs a column`},
+		{"key name", []string{"id"}, []Aggregate{{Column: "id", Kind: compute.AggMax}}, `spill: column "id" is in the output of GroupBy twice`},
+		{"same name", []string{"id"}, []Aggregate{{Kind: compute.AggCount}, {Column: "name", Kind: compute.AggCount, Name: "count"}}, `spill: column "count" is in the output of GroupBy twice`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer pool.AssertSize(t, 0)
+
+			rdr := newTestReader(t, pool, []int64{1}, []string{"a"}, 1)
+			defer rdr.Release()
+			r, err := GroupBy(pool, rdr, tc.keys, tc.aggs)
+			if err == nil {
+				r.Release()
+				t.Fatalf("GroupBy did not fail")
+			}
+			if !strings.HasPrefix(err.Error(), tc.want) {
+				t.Fatalf("error=%q, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestMergeJoin(t *testing.T) {
+	want := map[compute.JoinType][]string{
+		compute.InnerJoin: {"1 a 1 x", "3 c 3 y", "3 c 3 z", "3 d 3 y", "3 d 3 z"},
+		compute.LeftJoin:  {"1 a 1 x", "2 b null null", "3 c 3 y", "3 c 3 z", "3 d 3 y", "3 d 3 z", "5 f null null", "null e null null"},
+	}
+
+	for how, want := range want {
+		t.Run(fmt.Sprint(how), func(t *testing.T) {
+			pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer pool.AssertSize(t, 0)
+
+			left := newTestReader(t, pool, []int64{3, -1, 1, 5, 2, 3}, []string{"c", "e", "a", "f", "b", "d"}, 2)
+			defer left.Release()
+			sortedLeft, err := Sort(pool, left, []SortKey{{Name: "id"}}, WithBatchSize(1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sortedLeft.Release()
+
+			right := newRightReader(t, pool)
+			defer right.Release()
+			sortedRight, err := Sort(pool, right, []SortKey{{Name: "key"}}, WithBatchSize(2))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sortedRight.Release()
+
+			r, err := MergeJoin(pool, sortedLeft, sortedRight, []string{"id"}, []string{"key"}, how, WithBatchSize(3))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := readAll(t, r)
+			r.Release()
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Fatalf("got=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestMergeJoinErrors(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	left := newTestReader(t, pool, []int64{1, 2}, []string{"a", "b"}, 2)
+	defer left.Release()
+	right := newRightReader(t, pool)
+	defer right.Release()
+	if _, err := MergeJoin(pool, left, right, []string{"id"}, []string{"key"}, compute.InnerJoin); err == nil ||
+		err.Error() != `spill: left key column "id" is not marked as sorted` {
+		t.Fatalf("got error %v", err)
+	}
+
+	// A side marked as sorted whose rows are not is detected while reading.
+	schema := arrow.NewSchema([]arrow.Field{
+		compute.WithSortOrder(arrow.Field{Name: "id", Type: arrow.PrimitiveTypes.Int64}, compute.Ascending),
+	}, nil)
+	bldr := array.NewRecordBuilder(pool, schema)
+	defer bldr.Release()
+	bldr.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 3, 2}, nil)
+	rec := bldr.NewRecord()
+	defer rec.Release()
+	unsorted, err := array.NewRecordReader(schema, []array.Record{rec})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unsorted.Release()
+	sortedRight, err := Sort(pool, right, []SortKey{{Name: "key"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sortedRight.Release()
+
+	r, err := MergeJoin(pool, unsorted, sortedRight, []string{"id"}, []string{"key"}, compute.InnerJoin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+	for r.Next() {
+	}
+	if err := r.Err(); err == nil || err.Error() != "spill: left side of MergeJoin is not sorted by its keys" {
+		t.Fatalf("got error %v", err)
+	}
+}
+
+func TestCrossJoin(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	left := newTestReader(t, pool, []int64{1, 2, 3}, []string{"a", "b", "c"}, 2)
+	defer left.Release()
+	right := newRightReader(t, pool)
+	defer right.Release()
+
+	r, err := CrossJoin(pool, left, right, 12, WithBatchSize(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sizes []int64
+	var got []string
+	for r.Next() {
+		rec := r.Record()
+		sizes = append(sizes, rec.NumRows())
+		for i := 0; i < int(rec.NumRows()); i++ {
+			got = append(got, fmt.Sprintf("%s%s",
+				rec.Column(1).(*array.String).Value(i), rec.Column(4).(*array.String).Value(i)))
+		}
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	r.Release()
+	// The pairs of each left record are generated in batches of at most 5 rows.
+	if got, want := fmt.Sprint(sizes), "[5 3 4]"; got != want {
+		t.Errorf("got batches of %s rows, want=%s", got, want)
+	}
+	want := "ax ay az aw bx by bz bw cx cy cz cw"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("got=%v, want=%v", got, want)
+	}
+
+	// The join fails once a left record would take it over the maximum.
+	left = newTestReader(t, pool, []int64{1, 2, 3}, []string{"a", "b", "c"}, 2)
+	defer left.Release()
+	right = newRightReader(t, pool)
+	defer right.Release()
+	r, err = CrossJoin(pool, left, right, 11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+	n := 0
+	for r.Next() {
+		n += int(r.Record().NumRows())
+	}
+	if err := r.Err(); err == nil || err.Error() != "compute: cross join of 3 and 4 rows exceeds the maximum of 11 rows" {
+		t.Fatalf("got error %v", err)
+	}
+	if n != 8 {
+		t.Fatalf("got=%d rows before the error, want=8", n)
+	}
+
+	if _, err := CrossJoin(pool, left, left, 100); err == nil {
+		t.Fatal("expected an error joining columns of the same name")
+	}
+}
+
+// newRightReader returns a RecordReader over key and tag columns.
+func newRightReader(t *testing.T, mem memory.Allocator) array.RecordReader {
+	t.Helper()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "key", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "key2", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "tag", Type: arrow.BinaryTypes.String},
+	}, nil)
+
+	bldr := array.NewRecordBuilder(mem, schema)
+	defer bldr.Release()
+	bldr.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 3, 3, 4}, nil)
+	bldr.Field(1).(*array.Int64Builder).AppendValues([]int64{1, 3, 3, 4}, nil)
+	bldr.Field(2).(*array.StringBuilder).AppendValues([]string{"x", "y", "z", "w"}, nil)
+	rec := bldr.NewRecord()
+	defer rec.Release()
+
+	rdr, err := array.NewRecordReader(schema, []array.Record{rec})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rdr
+}
+
+func TestPartitioner(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+	dir := newTempDir(t)
+	defer os.RemoveAll(dir)
+
+	rdr := newTestReader(t, pool, []int64{1, 2, 3, 1, 2, 3, -1, -1}, []string{"a", "b", "c", "d", "e", "f", "g", "h"}, 3)
+	defer rdr.Release()
+
+	p, err := NewPartitioner(pool, rdr.Schema(), []string{"id"}, WithPartitions(3), WithTempDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rdr.Next() {
+		if err := p.Write(rdr.Record()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	partOf := make(map[string]int)
+	rows := 0
+	for i := 0; i < p.NumPartitions(); i++ {
+		recs, err := p.Records(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, rec := range recs {
+			ids := rec.Column(0).(*array.Int64)
+			for j := 0; j < ids.Len(); j++ {
+				key := "null"
+				if ids.IsValid(j) {
+					key = fmt.Sprint(ids.Value(j))
+				}
+				if part, ok := partOf[key]; ok && part != i {
+					t.Fatalf("key %s found in partitions %d and %d", key, part, i)
+				}
+				partOf[key] = i
+				rows++
+			}
+			rec.Release()
+		}
+	}
+	if rows != 8 {
+		t.Fatalf("got=%d, want=8 rows", rows)
+	}
+
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	assertEmptyDir(t, dir)
+}
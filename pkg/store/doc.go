@@ -0,0 +1,49 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package store persists successive versions of a DataFrame in a directory and
+opens them again, as of a version number or a point in time.
+
+Every chunk of every column is written as an Arrow IPC file named after the
+SHA-256 hash of its content, and a manifest lists the chunks of each version.
+Chunks holding the same values are stored once, so committing a DataFrame
+whose columns are mostly unchanged only writes the changed chunks:
+
+	s, err := store.Open("features")
+	if err != nil {
+		return err
+	}
+	v, err := s.Commit(df)
+	if err != nil {
+		return err
+	}
+
+	// Later, the DataFrame as it was yesterday.
+	old, err := s.LoadAsOf(mem, time.Now().Add(-24*time.Hour))
+
+LoadWith loads the columns and the rows selected by a pushdown.ReadOptions.
+The manifest keeps the number of rows, of nulls, and the minimum and maximum
+of every chunk, so only the chunks of the columns read are opened, and the
+chunks the filter cannot match are skipped:
+
+	cheap, err := s.LoadWith(mem, v.Number, pushdown.ReadOptions{
+		Columns: []string{"id"},
+		Filter:  pushdown.Compare("price", pushdown.Less, 10),
+	})
+
+The files of a store are never modified, only the manifest is replaced, so a
+store can be copied or backed up while it is written.
+*/
+package store
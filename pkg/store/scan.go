@@ -0,0 +1,260 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/arrjson"
+	"github.com/gomem/gomem/pkg/dataframe"
+	"github.com/gomem/gomem/pkg/pushdown"
+	"github.com/gomem/gomem/pkg/scalar"
+)
+
+// scanChunk is the number of rows filtered at once when the chunks of the
+// columns read do not line up.
+const scanChunk = 64 * 1024
+
+// LoadWith returns the columns and the rows of version number selected by ro.
+// Only the chunks of the columns read by ro are read, and the chunks whose
+// statistics show they hold no selected row are skipped.
+func (s *Store) LoadWith(mem memory.Allocator, number int, ro pushdown.ReadOptions) (*dataframe.DataFrame, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if number < 1 || number > len(s.manifest.Versions) {
+		return nil, fmt.Errorf("store: no version %d", number)
+	}
+	v := s.manifest.Versions[number-1]
+
+	schema, _, err := s.readObject(mem, v.Schema)
+	if err != nil {
+		return nil, err
+	}
+	if len(v.Columns) != len(schema.Fields()) {
+		return nil, fmt.Errorf("store: version %d has %d columns, want %d", v.Number, len(v.Columns), len(schema.Fields()))
+	}
+	out, err := ro.Schema(schema)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	// The columns read, by index in the schema.
+	names := ro.ReadColumns()
+	if names == nil {
+		for _, field := range schema.Fields() {
+			names = append(names, field.Name)
+		}
+	}
+	read := make([]int, len(names))
+	fields := make([]arrow.Field, len(names))
+	for i, name := range names {
+		read[i] = schema.FieldIndices(name)[0]
+		fields[i] = schema.Field(read[i])
+	}
+	readSchema := arrow.NewSchema(fields, nil)
+
+	var recs []array.Record
+	defer func() {
+		for _, rec := range recs {
+			rec.Release()
+		}
+	}()
+	keep := func(rec array.Record) error {
+		filtered, err := pushdown.Apply(mem, rec, ro)
+		if err != nil {
+			return fmt.Errorf("store: %w", err)
+		}
+		recs = append(recs, filtered)
+		return nil
+	}
+
+	stats, err := s.chunkStats(mem, v, read, schema)
+	if err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		// The chunks of the columns do not line up, read them whole and
+		// filter them in records of aligned slices.
+		table, err := s.readTable(mem, v, read, readSchema)
+		if err != nil {
+			return nil, err
+		}
+		defer table.Release()
+		tr := array.NewTableReader(table, scanChunk)
+		defer tr.Release()
+		for tr.Next() {
+			if err := keep(tr.Record()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for k, chunkStats := range stats {
+		if ro.Filter != nil && !ro.Filter.MayMatch(chunkStats) {
+			continue
+		}
+		rec, err := s.readChunks(mem, v, read, readSchema, k)
+		if err != nil {
+			return nil, err
+		}
+		err = keep(rec)
+		rec.Release()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	table := array.NewTableFromRecords(out, recs)
+	defer table.Release()
+	return dataframe.NewDataFrameFromTable(mem, table)
+}
+
+// chunkStats returns the statistics of the chunks of the read columns of v,
+// by chunk. It returns nil when the chunks of the columns do not hold the
+// same rows, or when v has no statistics.
+func (s *Store) chunkStats(mem memory.Allocator, v version, read []int, schema *arrow.Schema) ([]pushdown.Stats, error) {
+	if len(v.Stats) != len(v.Columns) {
+		return nil, nil
+	}
+	var stats []pushdown.Stats
+	for _, i := range read {
+		arr, err := arrjson.UnmarshalArray(mem, v.Stats[i])
+		if err != nil {
+			return nil, fmt.Errorf("store: could not decode statistics: %w", err)
+		}
+		st, ok := arr.(*array.Struct)
+		if !ok || st.Len() != len(v.Columns[i]) {
+			arr.Release()
+			return nil, fmt.Errorf("store: invalid statistics for column %q", schema.Field(i).Name)
+		}
+
+		if stats == nil {
+			stats = make([]pushdown.Stats, st.Len())
+			for k := range stats {
+				stats[k] = make(pushdown.Stats)
+			}
+		}
+		aligned := len(stats) == st.Len()
+		name := schema.Field(i).Name
+		rows, nulls := st.Field(0).(*array.Int64), st.Field(1).(*array.Int64)
+		for k := 0; aligned && k < st.Len(); k++ {
+			cs := pushdown.ColumnStats{Rows: rows.Value(k), Nulls: nulls.Value(k)}
+			if st.NumField() == 4 {
+				cs.Min, _ = scalar.Get(st.Field(2), k)
+				cs.Max, _ = scalar.Get(st.Field(3), k)
+			}
+			for _, other := range stats[k] {
+				aligned = other.Rows == cs.Rows
+				break
+			}
+			stats[k][name] = cs
+		}
+		arr.Release()
+		if !aligned {
+			return nil, nil
+		}
+	}
+	return stats, nil
+}
+
+// readChunks returns the record of the k-th chunks of the read columns.
+func (s *Store) readChunks(mem memory.Allocator, v version, read []int, schema *arrow.Schema, k int) (array.Record, error) {
+	cols := make([]array.Interface, 0, len(read))
+	defer func() { releaseArrays(cols) }()
+	for _, i := range read {
+		_, chunk, err := s.readObject(mem, v.Columns[i][k])
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, chunk)
+	}
+	rows := int64(0)
+	if len(cols) > 0 {
+		rows = int64(cols[0].Len())
+	}
+	return array.NewRecord(schema, cols, rows), nil
+}
+
+// readTable returns the table of the read columns.
+func (s *Store) readTable(mem memory.Allocator, v version, read []int, schema *arrow.Schema) (array.Table, error) {
+	cols := make([]array.Column, 0, len(read))
+	defer func() {
+		for i := range cols {
+			cols[i].Release()
+		}
+	}()
+	for j, i := range read {
+		chunks := make([]array.Interface, 0, len(v.Columns[i]))
+		for _, hash := range v.Columns[i] {
+			_, chunk, err := s.readObject(mem, hash)
+			if err != nil {
+				releaseArrays(chunks)
+				return nil, err
+			}
+			chunks = append(chunks, chunk)
+		}
+		chunked := array.NewChunked(schema.Field(j).Type, chunks)
+		releaseArrays(chunks)
+		cols = append(cols, *array.NewColumn(schema.Field(j), chunked))
+		chunked.Release()
+	}
+	return array.NewTable(schema, cols, v.Rows), nil
+}
+
+// encodeStats returns the statistics of the chunks of col, as an arrjson
+// array of structs with the rows, the nulls, and for the ordered types the
+// minimum and the maximum of every chunk.
+func encodeStats(mem memory.Allocator, col *array.Column) (json.RawMessage, error) {
+	chunks := col.Data().Chunks()
+	stats := make([]pushdown.ColumnStats, len(chunks))
+	ordered := true
+	for k, chunk := range chunks {
+		stats[k] = pushdown.StatsOf(chunk)
+		ordered = ordered && stats[k].Min != nil
+	}
+
+	fields := []arrow.Field{
+		{Name: "rows", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "nulls", Type: arrow.PrimitiveTypes.Int64},
+	}
+	if ordered && len(chunks) > 0 {
+		dtype := stats[0].Min.DataType()
+		fields = append(fields,
+			arrow.Field{Name: "min", Type: dtype, Nullable: true},
+			arrow.Field{Name: "max", Type: dtype, Nullable: true})
+	}
+	bldr := array.NewStructBuilder(mem, arrow.StructOf(fields...))
+	defer bldr.Release()
+	for _, cs := range stats {
+		bldr.Append(true)
+		bldr.FieldBuilder(0).(*array.Int64Builder).Append(cs.Rows)
+		bldr.FieldBuilder(1).(*array.Int64Builder).Append(cs.Nulls)
+		if len(fields) == 4 {
+			scalar.Append(bldr.FieldBuilder(2), cs.Min)
+			scalar.Append(bldr.FieldBuilder(3), cs.Max)
+		}
+	}
+	arr := bldr.NewArray()
+	defer arr.Release()
+
+	data, err := arrjson.MarshalArray("stats", arr)
+	if err != nil {
+		return nil, fmt.Errorf("store: could not encode statistics: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/dataframe"
+	"github.com/gomem/gomem/pkg/pushdown"
+)
+
+func TestLoadWith(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	dir, err := ioutil.TempDir("", "gomem-store-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A frame of two chunks per column.
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	b := array.NewRecordBuilder(pool, schema)
+	defer b.Release()
+	b.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2, 3}, nil)
+	b.Field(1).(*array.StringBuilder).AppendValues([]string{"a", "", "c"}, []bool{true, false, true})
+	rec1 := b.NewRecord()
+	defer rec1.Release()
+	b.Field(0).(*array.Int64Builder).AppendValues([]int64{4, 5, 6}, nil)
+	b.Field(1).(*array.StringBuilder).AppendValues([]string{"d", "e", "f"}, nil)
+	rec2 := b.NewRecord()
+	defer rec2.Release()
+	table := array.NewTableFromRecords(schema, []array.Record{rec1, rec2})
+	defer table.Release()
+	df, err := dataframe.NewDataFrameFromTable(pool, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+	if _, err := s.Commit(df); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first chunk of id holds no selected row, so it is never read.
+	if err := os.Remove(s.objectPath(s.manifest.Versions[0].Columns[0][0])); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		ro   pushdown.ReadOptions
+		want string
+	}{
+		{
+			pushdown.ReadOptions{Filter: pushdown.Compare("id", pushdown.Greater, 4)},
+			"rec[0][\"id\"]: [5 6]\nrec[0][\"name\"]: [\"e\" \"f\"]\n",
+		},
+		{
+			pushdown.ReadOptions{Columns: []string{"name"}, Filter: pushdown.Compare("id", pushdown.GreaterEqual, 4)},
+			"rec[0][\"name\"]: [\"d\" \"e\" \"f\"]\n",
+		},
+	} {
+		got, err := s.LoadWith(pool, 1, tc.ro)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := got.Display(-1); s != tc.want {
+			t.Errorf("%v: got=\n%s\nwant=\n%s", tc.ro, s, tc.want)
+		}
+		got.Release()
+	}
+
+	if _, err := s.LoadWith(pool, 1, pushdown.ReadOptions{Filter: pushdown.Compare("id", pushdown.Less, 4)}); err == nil {
+		t.Fatal("expected an error reading a removed chunk")
+	}
+	if _, err := s.LoadWith(pool, 1, pushdown.ReadOptions{Columns: []string{"score"}}); err == nil {
+		t.Fatal("expected an error reading an unknown column")
+	}
+}
@@ -0,0 +1,336 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+const (
+	manifestName = "manifest.json"
+	objectsDir   = "objects"
+	objectExt    = ".arrow"
+)
+
+// Version describes a committed version of the DataFrame.
+type Version struct {
+	// Number is the number of the version, starting at 1.
+	Number int `json:"number"`
+	// Time is when the version was committed.
+	Time time.Time `json:"time"`
+	// Rows is the number of rows of the DataFrame.
+	Rows int64 `json:"rows"`
+}
+
+// version is the manifest entry of a Version.
+type version struct {
+	Version
+	// Schema is the hash of the IPC file holding the schema of the DataFrame.
+	Schema string `json:"schema"`
+	// Columns holds the hashes of the chunks of every column.
+	Columns [][]string `json:"columns"`
+	// Stats holds the statistics of the chunks of every column, see
+	// encodeStats. Versions committed before statistics were kept have none.
+	Stats []json.RawMessage `json:"stats,omitempty"`
+}
+
+type manifest struct {
+	Versions []version `json:"versions"`
+}
+
+// Store is a directory holding the versions of a DataFrame.
+// It is safe for concurrent use, but a directory must only be written by one Store.
+type Store struct {
+	dir string
+	cfg *config
+
+	mu       sync.RWMutex
+	manifest manifest
+}
+
+// Open opens the store in dir, creating it when it does not exist.
+func Open(dir string, opts ...Option) (*Store, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Join(dir, objectsDir), 0755); err != nil {
+		return nil, fmt.Errorf("store: could not create store: %w", err)
+	}
+
+	s := &Store{dir: dir, cfg: cfg}
+	data, err := ioutil.ReadFile(filepath.Join(dir, manifestName))
+	switch {
+	case os.IsNotExist(err):
+		return s, nil
+	case err != nil:
+		return nil, fmt.Errorf("store: could not read manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.manifest); err != nil {
+		return nil, fmt.Errorf("store: could not decode manifest: %w", err)
+	}
+	return s, nil
+}
+
+// Versions returns the committed versions, oldest first.
+func (s *Store) Versions() []Version {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	versions := make([]Version, len(s.manifest.Versions))
+	for i, v := range s.manifest.Versions {
+		versions[i] = v.Version
+	}
+	return versions
+}
+
+// Commit stores df as a new version. Only the chunks not already held by the
+// store are written.
+func (s *Store) Commit(df *dataframe.DataFrame) (Version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v := version{
+		Version: Version{
+			Number: len(s.manifest.Versions) + 1,
+			Time:   s.cfg.now().UTC(),
+			Rows:   df.NumRows(),
+		},
+		Columns: make([][]string, df.NumCols()),
+	}
+	if n := len(s.manifest.Versions); n > 0 && v.Time.Before(s.manifest.Versions[n-1].Time) {
+		return Version{}, fmt.Errorf("store: version %d is older than version %d", v.Number, n)
+	}
+
+	schema, err := s.writeObject(df.Schema(), nil)
+	if err != nil {
+		return Version{}, err
+	}
+	v.Schema = schema
+
+	for i, col := range df.Columns() {
+		for _, chunk := range col.Data().Chunks() {
+			hash, err := s.writeObject(chunkSchema(col.DataType()), chunk)
+			if err != nil {
+				return Version{}, err
+			}
+			v.Columns[i] = append(v.Columns[i], hash)
+		}
+		stats, err := encodeStats(df.Allocator(), &col)
+		if err != nil {
+			return Version{}, err
+		}
+		v.Stats = append(v.Stats, stats)
+	}
+
+	m := manifest{Versions: append(s.manifest.Versions[:len(s.manifest.Versions):len(s.manifest.Versions)], v)}
+	if err := s.writeManifest(m); err != nil {
+		return Version{}, err
+	}
+	s.manifest = m
+	return v.Version, nil
+}
+
+// Load returns the DataFrame committed as version number.
+func (s *Store) Load(mem memory.Allocator, number int) (*dataframe.DataFrame, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if number < 1 || number > len(s.manifest.Versions) {
+		return nil, fmt.Errorf("store: no version %d", number)
+	}
+	return s.load(mem, s.manifest.Versions[number-1])
+}
+
+// LoadAsOf returns the last DataFrame committed at or before t.
+func (s *Store) LoadAsOf(mem memory.Allocator, t time.Time) (*dataframe.DataFrame, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	versions := s.manifest.Versions
+	n := sort.Search(len(versions), func(i int) bool { return versions[i].Time.After(t) })
+	if n == 0 {
+		return nil, fmt.Errorf("store: no version as of %v", t)
+	}
+	return s.load(mem, versions[n-1])
+}
+
+func (s *Store) load(mem memory.Allocator, v version) (*dataframe.DataFrame, error) {
+	schema, _, err := s.readObject(mem, v.Schema)
+	if err != nil {
+		return nil, err
+	}
+	if len(v.Columns) != len(schema.Fields()) {
+		return nil, fmt.Errorf("store: version %d has %d columns, want %d", v.Number, len(v.Columns), len(schema.Fields()))
+	}
+
+	cols := make([]array.Column, 0, len(v.Columns))
+	defer func() {
+		for i := range cols {
+			cols[i].Release()
+		}
+	}()
+	for i, field := range schema.Fields() {
+		chunks := make([]array.Interface, 0, len(v.Columns[i]))
+		for _, hash := range v.Columns[i] {
+			_, chunk, err := s.readObject(mem, hash)
+			if err != nil {
+				releaseArrays(chunks)
+				return nil, err
+			}
+			chunks = append(chunks, chunk)
+		}
+		chunked := array.NewChunked(field.Type, chunks)
+		releaseArrays(chunks)
+		cols = append(cols, *array.NewColumn(field, chunked))
+		chunked.Release()
+	}
+	return dataframe.NewDataFrameFromShape(mem, cols, v.Rows)
+}
+
+// chunkSchema is the schema of the files holding chunks of type dtype. Chunks
+// are stored without the name and the metadata of their column, which are kept
+// by the schema of the version, so renamed columns share their chunks.
+func chunkSchema(dtype arrow.DataType) *arrow.Schema {
+	return arrow.NewSchema([]arrow.Field{{Name: "chunk", Type: dtype, Nullable: true}}, nil)
+}
+
+// writeObject writes an IPC file holding schema and, when not nil, a record
+// of chunk, and returns its hash. Files already in the store are not written again.
+func (s *Store) writeObject(schema *arrow.Schema, chunk array.Interface) (string, error) {
+	var buf fileBuffer
+	w, err := ipc.NewFileWriter(&buf, ipc.WithSchema(schema))
+	if err != nil {
+		return "", fmt.Errorf("store: could not encode object: %w", err)
+	}
+	if chunk != nil {
+		rec := array.NewRecord(schema, []array.Interface{chunk}, int64(chunk.Len()))
+		err = w.Write(rec)
+		rec.Release()
+		if err != nil {
+			return "", fmt.Errorf("store: could not encode object: %w", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("store: could not encode object: %w", err)
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	hash := hex.EncodeToString(sum[:])
+	path := s.objectPath(hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+	if err := writeFile(path, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("store: could not write object: %w", err)
+	}
+	return hash, nil
+}
+
+// readObject reads the IPC file named hash, returning its schema and its
+// record's only column, if any.
+func (s *Store) readObject(mem memory.Allocator, hash string) (*arrow.Schema, array.Interface, error) {
+	data, err := ioutil.ReadFile(s.objectPath(hash))
+	if err != nil {
+		return nil, nil, fmt.Errorf("store: could not read object: %w", err)
+	}
+	if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != hash {
+		return nil, nil, fmt.Errorf("store: object %s is corrupted", hash)
+	}
+
+	r, err := ipc.NewFileReader(bytes.NewReader(data), ipc.WithAllocator(mem))
+	if err != nil {
+		return nil, nil, fmt.Errorf("store: could not decode object %s: %w", hash, err)
+	}
+	defer r.Close()
+	if r.NumRecords() == 0 {
+		return r.Schema(), nil, nil
+	}
+	rec, err := r.Record(0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("store: could not decode object %s: %w", hash, err)
+	}
+	chunk := rec.Column(0)
+	chunk.Retain()
+	return r.Schema(), chunk, nil
+}
+
+func (s *Store) objectPath(hash string) string {
+	return filepath.Join(s.dir, objectsDir, hash+objectExt)
+}
+
+func (s *Store) writeManifest(m manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("store: could not encode manifest: %w", err)
+	}
+	if err := writeFile(filepath.Join(s.dir, manifestName), data); err != nil {
+		return fmt.Errorf("store: could not write manifest: %w", err)
+	}
+	return nil
+}
+
+// writeFile writes data to a temporary file renamed to path, so readers never
+// see a partial file.
+func writeFile(path string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func releaseArrays(arrs []array.Interface) {
+	for _, arr := range arrs {
+		arr.Release()
+	}
+}
+
+// fileBuffer is an in-memory io.WriteSeeker for ipc.FileWriter, which only
+// seeks to find its current position.
+type fileBuffer struct {
+	bytes.Buffer
+}
+
+func (b *fileBuffer) Seek(offset int64, whence int) (int64, error) {
+	if offset != 0 || whence != io.SeekCurrent {
+		return 0, errors.New("store: cannot seek in buffer")
+	}
+	return int64(b.Len()), nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+func newFrame(t *testing.T, mem memory.Allocator, scores []float64) *dataframe.DataFrame {
+	t.Helper()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "score", Type: arrow.PrimitiveTypes.Float64},
+	}, nil)
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+	b.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2, 3}, nil)
+	b.Field(1).(*array.StringBuilder).AppendValues([]string{"a", "", "c"}, []bool{true, false, true})
+	b.Field(2).(*array.Float64Builder).AppendValues(scores, nil)
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	df, err := dataframe.NewDataFrameFromRecord(mem, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return df
+}
+
+func countObjects(t *testing.T, dir string) int {
+	t.Helper()
+	files, err := ioutil.ReadDir(filepath.Join(dir, objectsDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(files)
+}
+
+func TestStore(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	dir, err := ioutil.TempDir("", "gomem-store-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	s, err := Open(dir, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v1 := newFrame(t, pool, []float64{0.5, 0.25, 1})
+	defer v1.Release()
+	if _, err := s.Commit(v1); err != nil {
+		t.Fatal(err)
+	}
+	// The schema and a chunk per column.
+	if got, want := countObjects(t, dir), 4; got != want {
+		t.Fatalf("got %d objects, want %d", got, want)
+	}
+
+	now = now.Add(time.Hour)
+	v2 := newFrame(t, pool, []float64{0.5, 0.75, 1})
+	defer v2.Release()
+	if _, err := s.Commit(v2); err != nil {
+		t.Fatal(err)
+	}
+	// Only the changed column is written.
+	if got, want := countObjects(t, dir), 5; got != want {
+		t.Fatalf("got %d objects, want %d", got, want)
+	}
+
+	// A reopened store sees the versions.
+	s, err = Open(dir, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := s.Versions()
+	if len(versions) != 2 || versions[1].Number != 2 || !versions[1].Time.Equal(now) || versions[1].Rows != 3 {
+		t.Fatalf("invalid versions: %+v", versions)
+	}
+
+	for _, tc := range []struct {
+		load func() (*dataframe.DataFrame, error)
+		want *dataframe.DataFrame
+	}{
+		{func() (*dataframe.DataFrame, error) { return s.Load(pool, 1) }, v1},
+		{func() (*dataframe.DataFrame, error) { return s.Load(pool, 2) }, v2},
+		{func() (*dataframe.DataFrame, error) { return s.LoadAsOf(pool, now.Add(-time.Minute)) }, v1},
+		{func() (*dataframe.DataFrame, error) { return s.LoadAsOf(pool, now) }, v2},
+	} {
+		got, err := tc.load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Display(-1) != tc.want.Display(-1) || !got.Schema().Equal(tc.want.Schema()) {
+			t.Errorf("got=\n%v\nwant=\n%v", got.Display(-1), tc.want.Display(-1))
+		}
+		got.Release()
+	}
+
+	if _, err := s.Load(pool, 3); err == nil || err.Error() != "store: no version 3" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if _, err := s.LoadAsOf(pool, now.Add(-2*time.Hour)); err == nil {
+		t.Error("loaded a version before the first one")
+	}
+
+	now = now.Add(-time.Minute)
+	if _, err := s.Commit(v2); err == nil || err.Error() != "store: version 3 is older than version 2" {
+		t.Errorf("invalid error: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+)
+
+// AESGCM returns a Transform encrypting values with AES-GCM under key, which
+// is 16, 24 or 32 bytes long. Every value is sealed with a random nonce, so
+// equal values encrypt differently, and is encoded in base64 so encrypted
+// string columns remain valid text.
+func AESGCM(key []byte) (Transform, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return aesGCM{aead: aead}, nil
+}
+
+type aesGCM struct {
+	aead cipher.AEAD
+}
+
+func (t aesGCM) Encode(value []byte) ([]byte, error) {
+	n := t.aead.NonceSize()
+	sealed := make([]byte, n, n+len(value)+t.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, sealed); err != nil {
+		return nil, err
+	}
+	sealed = t.aead.Seal(sealed, sealed[:n], value, nil)
+
+	out := make([]byte, base64.StdEncoding.EncodedLen(len(sealed)))
+	base64.StdEncoding.Encode(out, sealed)
+	return out, nil
+}
+
+func (t aesGCM) Decode(value []byte) ([]byte, error) {
+	sealed := make([]byte, base64.StdEncoding.DecodedLen(len(value)))
+	n, err := base64.StdEncoding.Decode(sealed, value)
+	if err != nil {
+		return nil, err
+	}
+	sealed = sealed[:n]
+	if len(sealed) < t.aead.NonceSize() {
+		return nil, errors.New("encrypted value is too short")
+	}
+	nonce, data := sealed[:t.aead.NonceSize()], sealed[t.aead.NonceSize():]
+	return t.aead.Open(nil, nonce, data, nil)
+}
+
+// Tokenize returns a Transform replacing values by the hex encoded HMAC-SHA256
+// of key and the value. Equal values have equal tokens, so tokenized columns
+// can still be joined and grouped on, but tokens cannot be decoded: Decode
+// returns them as they are.
+func Tokenize(key []byte) Transform {
+	return Func(func(value []byte) ([]byte, error) {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(value)
+		sum := mac.Sum(nil)
+		out := make([]byte, hex.EncodedLen(len(sum)))
+		hex.Encode(out, sum)
+		return out, nil
+	}, nil)
+}
@@ -0,0 +1,50 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package transform protects the values of sensitive columns, such as personal
+data, when records are written and read through Arrow IPC.
+
+A field declares the transform encoding its values with Protect, and a
+Registry maps the names of the transforms to their implementation, holding
+their keys:
+
+	key := make([]byte, 32) // loaded from a secret store
+	enc, err := transform.AESGCM(key)
+	if err != nil {
+		return err
+	}
+	reg := transform.NewRegistry()
+	if err := reg.Register("pii", enc); err != nil {
+		return err
+	}
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		transform.Protect(arrow.Field{Name: "email", Type: arrow.BinaryTypes.String}, "pii"),
+	}, nil)
+
+Writers created with reg.WriteOption encode the protected columns of every
+record and readers created with reg.ReadOption decode them, so the values
+never leave the process in clear:
+
+	w := ipc.NewWriter(out, ipc.WithSchema(schema), reg.WriteOption(mem))
+	r, err := ipc.NewReader(in, reg.ReadOption(mem))
+
+The grpcio and httpio packages take a Registry with their WithTransforms option.
+
+Transforms apply to string and binary columns and keep their type. AESGCM
+encrypts values and decrypts them back, while Tokenize replaces them by keyed
+hashes which can be joined and grouped on but not reversed.
+*/
+package transform
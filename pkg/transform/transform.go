@@ -0,0 +1,189 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/metadata"
+)
+
+// Transform encodes the values of a column when they are written and decodes
+// them when they are read. Null values are not transformed.
+type Transform interface {
+	Encode(value []byte) ([]byte, error)
+	Decode(value []byte) ([]byte, error)
+}
+
+// Func returns a Transform calling encode and decode. A nil decode leaves the
+// encoded values as they are read.
+func Func(encode, decode func(value []byte) ([]byte, error)) Transform {
+	return funcTransform{encode: encode, decode: decode}
+}
+
+type funcTransform struct {
+	encode, decode func([]byte) ([]byte, error)
+}
+
+func (t funcTransform) Encode(value []byte) ([]byte, error) { return t.encode(value) }
+func (t funcTransform) Decode(value []byte) ([]byte, error) {
+	if t.decode == nil {
+		return value, nil
+	}
+	return t.decode(value)
+}
+
+// Protect returns field declaring that its values are encoded with the
+// transform registered as name.
+func Protect(field arrow.Field, name string) arrow.Field {
+	field.Metadata = metadata.WithTransform(field.Metadata, name)
+	return field
+}
+
+// Registry maps the names declared by the fields to transforms.
+// It is safe for concurrent use.
+type Registry struct {
+	mu         sync.RWMutex
+	transforms map[string]Transform
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{transforms: make(map[string]Transform)}
+}
+
+// Register makes t the transform of the fields declaring name. Registering a
+// name twice is an error.
+func (r *Registry) Register(name string, t Transform) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.transforms[name]; ok {
+		return fmt.Errorf("transform: %q is already registered", name)
+	}
+	r.transforms[name] = t
+	return nil
+}
+
+// Lookup returns the transform registered as name.
+func (r *Registry) Lookup(name string) (Transform, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.transforms[name]
+	return t, ok
+}
+
+// Encode returns rec with the values of its protected columns encoded.
+// The caller releases the returned record.
+func (r *Registry) Encode(mem memory.Allocator, rec array.Record) (array.Record, error) {
+	return r.apply(mem, rec, Transform.Encode)
+}
+
+// Decode returns rec with the values of its protected columns decoded.
+// The caller releases the returned record.
+func (r *Registry) Decode(mem memory.Allocator, rec array.Record) (array.Record, error) {
+	return r.apply(mem, rec, Transform.Decode)
+}
+
+// WriteOption returns the option making IPC writers encode the records they write.
+func (r *Registry) WriteOption(mem memory.Allocator) ipc.Option {
+	return ipc.WithRecordTransform(func(rec array.Record) (array.Record, error) {
+		return r.Encode(mem, rec)
+	})
+}
+
+// ReadOption returns the option making IPC readers decode the records they read.
+func (r *Registry) ReadOption(mem memory.Allocator) ipc.Option {
+	return ipc.WithRecordTransform(func(rec array.Record) (array.Record, error) {
+		return r.Decode(mem, rec)
+	})
+}
+
+func (r *Registry) apply(mem memory.Allocator, rec array.Record, fn func(Transform, []byte) ([]byte, error)) (array.Record, error) {
+	fields := rec.Schema().Fields()
+	cols := make([]array.Interface, len(fields))
+	defer func() {
+		for _, col := range cols {
+			if col != nil {
+				col.Release()
+			}
+		}
+	}()
+
+	for i, field := range fields {
+		name, ok := metadata.Transform(field.Metadata)
+		if !ok {
+			cols[i] = rec.Column(i)
+			cols[i].Retain()
+			continue
+		}
+		t, ok := r.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("transform: column %q uses unknown transform %q", field.Name, name)
+		}
+		col, err := transformColumn(mem, rec.Column(i), func(v []byte) ([]byte, error) { return fn(t, v) })
+		if err != nil {
+			return nil, fmt.Errorf("transform: column %q: %w", field.Name, err)
+		}
+		cols[i] = col
+	}
+	return array.NewRecord(rec.Schema(), cols, rec.NumRows()), nil
+}
+
+// transformColumn returns the values of col passed through fn.
+func transformColumn(mem memory.Allocator, col array.Interface, fn func([]byte) ([]byte, error)) (array.Interface, error) {
+	switch col := col.(type) {
+	case *array.String:
+		bldr := array.NewStringBuilder(mem)
+		defer bldr.Release()
+		bldr.Reserve(col.Len())
+		for i := 0; i < col.Len(); i++ {
+			if col.IsNull(i) {
+				bldr.AppendNull()
+				continue
+			}
+			v, err := fn([]byte(col.Value(i)))
+			if err != nil {
+				return nil, err
+			}
+			bldr.Append(string(v))
+		}
+		return bldr.NewArray(), nil
+
+	case *array.Binary:
+		bldr := array.NewBinaryBuilder(mem, col.DataType().(arrow.BinaryDataType))
+		defer bldr.Release()
+		bldr.Reserve(col.Len())
+		for i := 0; i < col.Len(); i++ {
+			if col.IsNull(i) {
+				bldr.AppendNull()
+				continue
+			}
+			v, err := fn(col.Value(i))
+			if err != nil {
+				return nil, err
+			}
+			bldr.Append(v)
+		}
+		return bldr.NewArray(), nil
+
+	default:
+		return nil, fmt.Errorf("cannot transform values of type %s", col.DataType())
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func newRegistry(t *testing.T) *Registry {
+	t.Helper()
+	enc, err := AESGCM(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := NewRegistry()
+	if err := reg.Register("pii", enc); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Register("token", Tokenize([]byte("secret"))); err != nil {
+		t.Fatal(err)
+	}
+	return reg
+}
+
+func newRecord(mem memory.Allocator, schema *arrow.Schema) array.Record {
+	bldr := array.NewRecordBuilder(mem, schema)
+	defer bldr.Release()
+	bldr.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2, 3}, nil)
+	bldr.Field(1).(*array.StringBuilder).AppendValues([]string{"a@x.org", "", "a@x.org"}, []bool{true, false, true})
+	bldr.Field(2).(*array.BinaryBuilder).AppendValues([][]byte{[]byte("555-01"), []byte("555-02"), []byte("555-01")}, nil)
+	return bldr.NewRecord()
+}
+
+func TestIPCRoundTrip(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	reg := newRegistry(t)
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		Protect(arrow.Field{Name: "email", Type: arrow.BinaryTypes.String, Nullable: true}, "pii"),
+		Protect(arrow.Field{Name: "phone", Type: arrow.BinaryTypes.Binary}, "token"),
+	}, nil)
+	rec := newRecord(pool, schema)
+	defer rec.Release()
+
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, ipc.WithSchema(schema), ipc.WithAllocator(pool), reg.WriteOption(pool))
+	if err := w.Write(rec); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("a@x.org")) || bytes.Contains(buf.Bytes(), []byte("555-01")) {
+		t.Fatal("protected values are written in clear")
+	}
+
+	// Without the transforms, the encoded values are read.
+	raw, err := ipc.NewReader(bytes.NewReader(buf.Bytes()), ipc.WithAllocator(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !raw.Next() {
+		t.Fatal(raw.Err())
+	}
+	emails := raw.Record().Column(1).(*array.String)
+	if emails.Value(0) == emails.Value(2) {
+		t.Errorf("equal values encrypt to the same value %q", emails.Value(0))
+	}
+	if emails.IsValid(1) {
+		t.Errorf("null value is encrypted")
+	}
+	phones := raw.Record().Column(2).(*array.Binary)
+	if !bytes.Equal(phones.Value(0), phones.Value(2)) || len(phones.Value(0)) != 64 {
+		t.Errorf("invalid tokens %q and %q", phones.Value(0), phones.Value(2))
+	}
+	raw.Release()
+
+	r, err := ipc.NewReader(bytes.NewReader(buf.Bytes()), ipc.WithAllocator(pool), reg.ReadOption(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+	if !r.Next() {
+		t.Fatal(r.Err())
+	}
+	got := r.Record()
+	for i, want := range []string{"[1 2 3]", `["a@x.org" (null) "a@x.org"]`} {
+		if s := fmt.Sprint(got.Column(i)); s != want {
+			t.Errorf("column %d: got=%s, want=%s", i, s, want)
+		}
+	}
+	if s := string(got.Column(2).(*array.Binary).Value(0)); s == "555-01" {
+		t.Errorf("tokens are decoded")
+	}
+}
+
+func TestFileRoundTrip(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	reg := newRegistry(t)
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		Protect(arrow.Field{Name: "email", Type: arrow.BinaryTypes.String, Nullable: true}, "pii"),
+		{Name: "phone", Type: arrow.BinaryTypes.Binary},
+	}, nil)
+	rec := newRecord(pool, schema)
+	defer rec.Release()
+
+	f := &seekBuffer{}
+	w, err := ipc.NewFileWriter(f, ipc.WithSchema(schema), ipc.WithAllocator(pool), reg.WriteOption(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(rec); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := ipc.NewFileReader(bytes.NewReader(f.buf), ipc.WithAllocator(pool), reg.ReadOption(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	got, err := r.Record(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !array.RecordEqual(got, rec) {
+		t.Errorf("got=%v, want=%v", got, rec)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	reg := newRegistry(t)
+	if err := reg.Register("pii", Tokenize(nil)); err == nil {
+		t.Error("registered a name twice")
+	}
+	if _, err := AESGCM([]byte("short")); err == nil {
+		t.Error("accepted an invalid key")
+	}
+
+	for _, tc := range []struct {
+		field arrow.Field
+		want  string
+	}{
+		{Protect(arrow.Field{Name: "id", Type: arrow.PrimitiveTypes.Int64}, "pii"), `transform: column "id": cannot transform values of type int64`},
+		{Protect(arrow.Field{Name: "id", Type: arrow.PrimitiveTypes.Int64}, "ssn"), `transform: column "id" uses unknown transform "ssn"`},
+	} {
+		schema := arrow.NewSchema([]arrow.Field{
+			tc.field,
+			{Name: "email", Type: arrow.BinaryTypes.String, Nullable: true},
+			{Name: "phone", Type: arrow.BinaryTypes.Binary},
+		}, nil)
+		rec := newRecord(pool, schema)
+		var buf bytes.Buffer
+		w := ipc.NewWriter(&buf, ipc.WithSchema(schema), ipc.WithAllocator(pool), reg.WriteOption(pool))
+		err := w.Write(rec)
+		if err == nil || !strings.HasSuffix(err.Error(), tc.want) {
+			t.Errorf("got error %v, want %q", err, tc.want)
+		}
+		w.Close()
+		rec.Release()
+	}
+
+	// Values encrypted under another key do not decrypt.
+	other, err := AESGCM(bytes.Repeat([]byte{8}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, _ := reg.Lookup("pii")
+	sealed, err := other.Encode([]byte("a@x.org"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := enc.Decode(sealed); err == nil {
+		t.Error("decrypted a value encrypted under another key")
+	}
+}
+
+// seekBuffer is an in-memory io.WriteSeeker.
+type seekBuffer struct {
+	buf []byte
+	pos int
+}
+
+func (b *seekBuffer) Write(p []byte) (int, error) {
+	if n := b.pos + len(p); n > len(b.buf) {
+		b.buf = append(b.buf, make([]byte, n-len(b.buf))...)
+	}
+	b.pos += copy(b.buf[b.pos:], p)
+	return len(p), nil
+}
+
+func (b *seekBuffer) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case 0:
+		b.pos = int(offset)
+	case 1:
+		b.pos += int(offset)
+	case 2:
+		b.pos = len(b.buf) + int(offset)
+	}
+	return int64(b.pos), nil
+}
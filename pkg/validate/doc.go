@@ -0,0 +1,37 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package validate checks DataFrames against declarative data quality rules.
+
+Rules such as NotNull, Unique, InRange and MatchesRegex constrain the values of
+a column, while Custom rules apply an arbitrary predicate. Validate runs the
+rules and returns a Report holding, for every rule, the number of violations
+and the offending rows as an index array usable with DataFrame.Take.
+
+	report, err := validate.Validate(df,
+		validate.NotNull("id"),
+		validate.Unique("id"),
+		validate.InRange("age", 0, 150),
+		validate.MatchesRegex("email", regexp.MustCompile(`^[^@]+@[^@]+$`)),
+	)
+	if err != nil {
+		return err
+	}
+	defer report.Release()
+	if !report.OK() {
+		return errors.New(report.String())
+	}
+*/
+package validate
@@ -0,0 +1,280 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/pkg/dataframe"
+	"github.com/gomem/gomem/pkg/iterator"
+)
+
+// Rule is a constraint on the rows of a DataFrame.
+type Rule interface {
+	// Name describes the rule in reports.
+	Name() string
+	// Violations returns the rows of df violating the rule, in increasing order.
+	Violations(df *dataframe.DataFrame) ([]int64, error)
+}
+
+// Result is the outcome of a single rule.
+type Result struct {
+	Rule       string
+	Violations int64
+	// Rows holds the indices of the offending rows.
+	Rows *array.Int64
+}
+
+// Report is the outcome of Validate, with one Result per rule in the order
+// the rules were given.
+type Report struct {
+	Results []Result
+}
+
+// OK reports whether no rule was violated.
+func (r *Report) OK() bool {
+	for _, res := range r.Results {
+		if res.Violations > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Release releases the row indices of the report.
+func (r *Report) Release() {
+	for _, res := range r.Results {
+		res.Rows.Release()
+	}
+}
+
+// String summarizes the violated rules.
+func (r *Report) String() string {
+	var b strings.Builder
+	for _, res := range r.Results {
+		if res.Violations == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("; ")
+		}
+		fmt.Fprintf(&b, "%s: %d violations", res.Rule, res.Violations)
+	}
+	if b.Len() == 0 {
+		return "ok"
+	}
+	return b.String()
+}
+
+// Validate checks df against rules.
+// The caller is responsible for releasing the returned Report.
+func Validate(df *dataframe.DataFrame, rules ...Rule) (*Report, error) {
+	report := &Report{Results: make([]Result, 0, len(rules))}
+	for _, rule := range rules {
+		rows, err := rule.Violations(df)
+		if err != nil {
+			report.Release()
+			return nil, fmt.Errorf("validate: %s: %w", rule.Name(), err)
+		}
+
+		bldr := array.NewInt64Builder(df.Allocator())
+		bldr.AppendValues(rows, nil)
+		report.Results = append(report.Results, Result{
+			Rule:       rule.Name(),
+			Violations: int64(len(rows)),
+			Rows:       bldr.NewInt64Array(),
+		})
+		bldr.Release()
+	}
+	return report, nil
+}
+
+// NotNull returns a Rule rejecting the nulls of the column name.
+func NotNull(name string) Rule {
+	return &columnRule{
+		name:   fmt.Sprintf("NotNull(%q)", name),
+		column: name,
+		nulls:  true,
+		check:  func(v interface{}) (bool, error) { return v != nil, nil },
+	}
+}
+
+// Unique returns a Rule rejecting the rows of the column name holding a value
+// that appears more than once. Nulls are ignored.
+func Unique(name string) Rule {
+	return uniqueRule{column: name}
+}
+
+type uniqueRule struct {
+	column string
+}
+
+func (r uniqueRule) Name() string {
+	return fmt.Sprintf("Unique(%q)", r.column)
+}
+
+func (r uniqueRule) Violations(df *dataframe.DataFrame) ([]int64, error) {
+	first := make(map[interface{}]int64)
+	flagged := make(map[interface{}]bool)
+	var rows []int64
+	err := forEach(df, r.column, func(row int64, v interface{}) error {
+		if v == nil {
+			return nil
+		}
+		prev, dup := first[v]
+		if !dup {
+			first[v] = row
+			return nil
+		}
+		if !flagged[v] {
+			flagged[v] = true
+			rows = append(rows, prev)
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortRows(rows)
+	return rows, nil
+}
+
+// InRange returns a Rule rejecting the values of the numeric column name
+// outside [min, max]. Nulls are ignored.
+func InRange(name string, min, max float64) Rule {
+	return &columnRule{
+		name:   fmt.Sprintf("InRange(%q, %v, %v)", name, min, max),
+		column: name,
+		check: func(v interface{}) (bool, error) {
+			f, ok := toFloat(v)
+			if !ok {
+				return false, fmt.Errorf("value %v of type %T is not a number", v, v)
+			}
+			return f >= min && f <= max, nil
+		},
+	}
+}
+
+// MatchesRegex returns a Rule rejecting the values of the string column name
+// not matching re. Nulls are ignored.
+func MatchesRegex(name string, re *regexp.Regexp) Rule {
+	return &columnRule{
+		name:   fmt.Sprintf("MatchesRegex(%q, %q)", name, re),
+		column: name,
+		check: func(v interface{}) (bool, error) {
+			s, ok := v.(string)
+			if !ok {
+				return false, fmt.Errorf("value %v of type %T is not a string", v, v)
+			}
+			return re.MatchString(s), nil
+		},
+	}
+}
+
+// Custom returns a Rule named name rejecting the values of the column column
+// for which fn returns false. fn is called with nil for nulls.
+func Custom(name, column string, fn func(value interface{}) bool) Rule {
+	return &columnRule{
+		name:   name,
+		column: column,
+		nulls:  true,
+		check:  func(v interface{}) (bool, error) { return fn(v), nil },
+	}
+}
+
+// columnRule checks the values of a column one at a time.
+type columnRule struct {
+	name   string
+	column string
+	// nulls is true when check is called for nulls too, otherwise nulls
+	// never violate the rule.
+	nulls bool
+	check func(v interface{}) (bool, error)
+}
+
+func (r *columnRule) Name() string {
+	return r.name
+}
+
+func (r *columnRule) Violations(df *dataframe.DataFrame) ([]int64, error) {
+	var rows []int64
+	err := forEach(df, r.column, func(row int64, v interface{}) error {
+		if v == nil && !r.nulls {
+			return nil
+		}
+		ok, err := r.check(v)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			rows = append(rows, row)
+		}
+		return nil
+	})
+	return rows, err
+}
+
+// forEach calls fn with the value of every row of the column name, nil for
+// nulls.
+func forEach(df *dataframe.DataFrame, name string, fn func(row int64, v interface{}) error) error {
+	col := df.Column(name)
+	if col == nil {
+		return fmt.Errorf("unknown column %q", name)
+	}
+	it := iterator.NewValueIterator(col)
+	defer it.Release()
+	for row := int64(0); row < df.NumRows() && it.Next(); row++ {
+		if err := fn(row, it.ValueInterface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+func sortRows(rows []int64) {
+	sort.Slice(rows, func(i, j int) bool { return rows[i] < rows[j] })
+}
@@ -0,0 +1,138 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+func TestValidate(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "age", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		{Name: "email", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	bldr := array.NewRecordBuilder(pool, schema)
+	defer bldr.Release()
+	bldr.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2, 2, 0, 5, 1}, []bool{true, true, true, false, true, true})
+	bldr.Field(1).(*array.Int32Builder).AppendValues([]int32{30, -1, 40, 0, 200, 20}, []bool{true, true, true, false, true, true})
+	bldr.Field(2).(*array.StringBuilder).AppendValues([]string{"a@x", "b", "c@y", "", "e@z", "f@"}, []bool{true, true, true, false, true, true})
+	rec := bldr.NewRecord()
+	defer rec.Release()
+
+	df, err := dataframe.NewDataFrameFromRecord(pool, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	report, err := Validate(df,
+		NotNull("id"),
+		Unique("id"),
+		InRange("age", 0, 150),
+		MatchesRegex("email", regexp.MustCompile(`^[^@]+@[^@]+$`)),
+		Custom("even id", "id", func(v interface{}) bool { return v == nil || v.(int64)%2 == 0 }),
+		NotNull("id"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer report.Release()
+
+	want := []string{
+		`NotNull("id"): 1 [3]`,
+		`Unique("id"): 4 [0 1 2 5]`,
+		`InRange("age", 0, 150): 2 [1 4]`,
+		`MatchesRegex("email", "^[^@]+@[^@]+$"): 2 [1 5]`,
+		`even id: 3 [0 4 5]`,
+		`NotNull("id"): 1 [3]`,
+	}
+	if len(report.Results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(report.Results), len(want))
+	}
+	for i, res := range report.Results {
+		got := fmt.Sprintf("%s: %d %v", res.Rule, res.Violations, res.Rows)
+		if got != want[i] {
+			t.Errorf("result %d: got %s, want %s", i, got, want[i])
+		}
+	}
+	if report.OK() {
+		t.Error("expected violations")
+	}
+	if got := report.String(); !strings.HasPrefix(got, `NotNull("id"): 1 violations; Unique("id"): 4 violations`) {
+		t.Errorf("unexpected summary %q", got)
+	}
+
+	// The offending rows can be selected from the DataFrame.
+	bad, err := df.Take(report.Results[2].Rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bad.Release()
+	if got, want := fmt.Sprint(bad.Column("age").Data().Chunk(0)), "[-1 200]"; got != want {
+		t.Errorf("got ages %s, want %s", got, want)
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df, err := dataframe.NewDataFrameFromMem(pool, dataframe.Dict{
+		"s": []string{"a"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer df.Release()
+
+	for _, tc := range []struct {
+		rule Rule
+		want string
+	}{
+		{NotNull("t"), `validate: NotNull("t"): unknown column "t"`},
+		{InRange("s", 0, 1), `validate: InRange("s", 0, 1): value a of type string is not a number`},
+	} {
+		report, err := Validate(df, NotNull("s"), tc.rule)
+		if err == nil {
+			report.Release()
+			t.Errorf("%s: expected an error", tc.rule.Name())
+			continue
+		}
+		if err.Error() != tc.want {
+			t.Errorf("got error %q, want %q", err, tc.want)
+		}
+	}
+
+	report, err := Validate(df, NotNull("s"), Unique("s"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer report.Release()
+	if !report.OK() || report.String() != "ok" {
+		t.Errorf("got %s, want ok", report)
+	}
+}
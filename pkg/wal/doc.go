@@ -0,0 +1,46 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package wal is an append-only log of Arrow records, so streaming ingestion
+survives process restarts without an external database.
+
+A Writer appends every record to a segment file of the log directory as a
+frame: the size of the frame and a CRC-32C checksum of its payload, followed
+by the payload, a self-contained IPC stream holding the record. Appended
+records are synced to disk before Append returns, unless WithSync(false) is
+given.
+
+	w, err := wal.Create("ingest")
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	for rdr.Next() {
+		if err := w.Append(rdr.Record()); err != nil {
+			return err
+		}
+	}
+
+After a restart, Recover reads the records of every segment back to a
+DataFrame. A frame cut short at the end of a segment, as left by a crash
+during an append, ends the segment; any other damaged frame is reported as
+ErrCorrupt.
+
+	df, err := wal.Recover("ingest", wal.WithAllocator(mem))
+
+Every Writer starts a new segment, and Remove deletes the log once its
+records are stored elsewhere.
+*/
+package wal
@@ -0,0 +1,249 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/ipc"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+const (
+	segmentPrefix = "wal-"
+	segmentExt    = ".log"
+	headerSize    = 8
+)
+
+var (
+	// ErrCorrupt is returned by Recover when a frame of the log is damaged.
+	ErrCorrupt = errors.New("wal: corrupt log")
+	// ErrEmpty is returned by Recover when the log holds no record.
+	ErrEmpty = errors.New("wal: empty log")
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Writer appends records to a segment of a log.
+type Writer struct {
+	f   *os.File
+	cfg *config
+	buf bytes.Buffer
+}
+
+// Create creates the log directory dir if needed and returns a Writer
+// appending to a new segment, after the segments already in dir.
+func Create(dir string, opts ...Option) (*Writer, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("wal: could not create log: %w", err)
+	}
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	seq := 1
+	if n := len(segments); n > 0 {
+		seq = segments[n-1].seq + 1
+	}
+
+	f, err := os.OpenFile(segmentPath(dir, seq), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("wal: could not create segment: %w", err)
+	}
+	return &Writer{f: f, cfg: cfg}, nil
+}
+
+// Append appends rec to the log.
+func (w *Writer) Append(rec array.Record) error {
+	w.buf.Reset()
+	w.buf.Write(make([]byte, headerSize))
+	iw := ipc.NewWriter(&w.buf, ipc.WithSchema(rec.Schema()), ipc.WithAllocator(w.cfg.mem))
+	if err := iw.Write(rec); err != nil {
+		return fmt.Errorf("wal: could not encode record: %w", err)
+	}
+	if err := iw.Close(); err != nil {
+		return fmt.Errorf("wal: could not encode record: %w", err)
+	}
+
+	frame := w.buf.Bytes()
+	payload := frame[headerSize:]
+	binary.LittleEndian.PutUint32(frame[0:], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(frame[4:], crc32.Checksum(payload, castagnoli))
+	if _, err := w.f.Write(frame); err != nil {
+		return fmt.Errorf("wal: could not append record: %w", err)
+	}
+	if w.cfg.sync {
+		if err := w.f.Sync(); err != nil {
+			return fmt.Errorf("wal: could not sync segment: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the segment.
+func (w *Writer) Close() error {
+	return w.f.Close()
+}
+
+// Recover returns a DataFrame holding the records of the log in dir, in the
+// order they were appended. The records must share their schema.
+func Recover(dir string, opts ...Option) (*dataframe.DataFrame, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var recs []array.Record
+	defer func() {
+		for _, rec := range recs {
+			rec.Release()
+		}
+	}()
+	for _, seg := range segments {
+		err := readSegment(cfg, seg.path, func(rec array.Record) error {
+			if len(recs) > 0 && !rec.Schema().Equal(recs[0].Schema()) {
+				return fmt.Errorf("wal: segment %s holds records of schema %v, want %v", seg.path, rec.Schema(), recs[0].Schema())
+			}
+			rec.Retain()
+			recs = append(recs, rec)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(recs) == 0 {
+		return nil, ErrEmpty
+	}
+
+	tbl := array.NewTableFromRecords(recs[0].Schema(), recs)
+	defer tbl.Release()
+	return dataframe.NewDataFrameFromTable(cfg.mem, tbl)
+}
+
+// Remove deletes the segments of the log in dir.
+func Remove(dir string) error {
+	segments, err := listSegments(dir)
+	if err != nil {
+		return err
+	}
+	for _, seg := range segments {
+		if err := os.Remove(seg.path); err != nil {
+			return fmt.Errorf("wal: could not remove segment: %w", err)
+		}
+	}
+	return nil
+}
+
+// readSegment calls fn with the records of the segment at path.
+func readSegment(cfg *config, path string, fn func(rec array.Record) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("wal: could not open segment: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var header [headerSize]byte
+	for offset := int64(0); ; {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return fmt.Errorf("wal: could not read segment: %w", err)
+		}
+		payload := make([]byte, binary.LittleEndian.Uint32(header[0:]))
+		if _, err := io.ReadFull(r, payload); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return fmt.Errorf("wal: could not read segment: %w", err)
+		}
+		if crc32.Checksum(payload, castagnoli) != binary.LittleEndian.Uint32(header[4:]) {
+			return fmt.Errorf("%w: checksum mismatch in %s at offset %d", ErrCorrupt, path, offset)
+		}
+
+		if err := readFrame(cfg, payload, fn); err != nil {
+			return fmt.Errorf("%w: %s at offset %d: %v", ErrCorrupt, path, offset, err)
+		}
+		offset += headerSize + int64(len(payload))
+	}
+}
+
+func readFrame(cfg *config, payload []byte, fn func(rec array.Record) error) error {
+	r, err := ipc.NewReader(bytes.NewReader(payload), ipc.WithAllocator(cfg.mem))
+	if err != nil {
+		return err
+	}
+	defer r.Release()
+	for r.Next() {
+		if err := fn(r.Record()); err != nil {
+			return err
+		}
+	}
+	return r.Err()
+}
+
+type segment struct {
+	seq  int
+	path string
+}
+
+// listSegments returns the segments of the log in dir, in order.
+func listSegments(dir string) ([]segment, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("wal: could not list segments: %w", err)
+	}
+	var segments []segment
+	for _, file := range files {
+		name := file.Name()
+		if !strings.HasPrefix(name, segmentPrefix) || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		seq, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, segmentPrefix), segmentExt))
+		if err != nil {
+			continue
+		}
+		segments = append(segments, segment{seq: seq, path: filepath.Join(dir, name)})
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].seq < segments[j].seq })
+	return segments, nil
+}
+
+func segmentPath(dir string, seq int) string {
+	return filepath.Join(dir, fmt.Sprintf("%s%06d%s", segmentPrefix, seq, segmentExt))
+}
@@ -0,0 +1,116 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+)
+
+func appendRecords(t *testing.T, mem memory.Allocator, dir string, ids ...[]int64) {
+	t.Helper()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+	}, nil)
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+
+	w, err := Create(dir, WithAllocator(mem))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	for _, values := range ids {
+		b.Field(0).(*array.Int64Builder).AppendValues(values, nil)
+		rec := b.NewRecord()
+		err := w.Append(rec)
+		rec.Release()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRecover(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	dir, err := ioutil.TempDir("", "gomem-wal-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := Recover(dir); err != ErrEmpty {
+		t.Fatalf("got error %v, want %v", err, ErrEmpty)
+	}
+
+	appendRecords(t, pool, dir, []int64{1, 2}, []int64{3})
+	appendRecords(t, pool, dir, []int64{4, 5})
+
+	// A crash during an append leaves a partial frame at the end of the segment.
+	segments, err := listSegments(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(segments[1].path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte{100, 0, 0, 0, 1, 2, 3, 4, 5}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	df, err := Recover(dir, WithAllocator(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := df.Display(-1)
+	df.Release()
+	want := `rec[0]["id"]: [1 2]
+rec[1]["id"]: [3]
+rec[2]["id"]: [4 5]
+`
+	if got != want {
+		t.Fatalf("\ngot=\n%v\nwant=\n%v", got, want)
+	}
+
+	// A damaged frame before the end is an error.
+	data, err := ioutil.ReadFile(segments[0].path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)/2] ^= 0xff
+	if err := ioutil.WriteFile(segments[0].path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Recover(dir, WithAllocator(pool)); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("got error %v, want %v", err, ErrCorrupt)
+	}
+
+	if err := Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Recover(dir); err != ErrEmpty {
+		t.Fatalf("got error %v, want %v", err, ErrEmpty)
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package xlsxio reads and writes DataFrames as Excel (xlsx) workbooks.
+
+It is meant for the small frames exchanged with spreadsheet users: a sheet
+is read into memory, its first row naming the columns, and each column gets
+the type holding all of its cells.
+
+	df, err := xlsxio.ReadFile(pool, "report.xlsx", xlsxio.WithSheet("Q3"))
+	...
+	err = xlsxio.WriteFile("out.xlsx", df)
+
+Cells map to Arrow types as follows:
+
+	number            int64 when every number is integral, float64 otherwise
+	date              date32 when every date is a whole day, timestamp[ms] otherwise
+	boolean           bool
+	text or mixed     string
+	empty             null
+*/
+package xlsxio
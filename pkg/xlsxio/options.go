@@ -0,0 +1,61 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xlsxio
+
+import "fmt"
+
+// Option is an option that may be passed to the readers and writers.
+type Option func(interface{}) error
+
+type config struct {
+	sheet  string
+	header bool
+}
+
+func newConfig(opts ...Option) (*config, error) {
+	cfg := &config{header: true}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// WithSheet selects the sheet to read, or names the sheet written.
+// The first sheet is read by default and "Sheet1" is written.
+func WithSheet(name string) Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply WithSheet to: %T", p)
+		}
+		cfg.sheet = name
+		return nil
+	}
+}
+
+// WithHeader specifies whether the first row holds the column names, true by default.
+// Without a header the columns are named after their letters (A, B, ...).
+func WithHeader(header bool) Option {
+	return func(p interface{}) error {
+		cfg, ok := p.(*config)
+		if !ok {
+			return fmt.Errorf("cannot apply WithHeader to: %T", p)
+		}
+		cfg.header = header
+		return nil
+	}
+}
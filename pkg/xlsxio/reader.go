@@ -0,0 +1,447 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xlsxio
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+// cellKind is the kind of value held by a cell.
+type cellKind int
+
+const (
+	emptyCell cellKind = iota
+	numberCell
+	dateCell
+	boolCell
+	textCell
+)
+
+type cell struct {
+	kind cellKind
+	num  float64 // numbers, dates as Excel serials and booleans as 0 or 1
+	text string
+}
+
+// ReadFile reads a sheet of the workbook at filename into a DataFrame.
+func ReadFile(mem memory.Allocator, filename string, opts ...Option) (*dataframe.DataFrame, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return Read(mem, f, fi.Size(), opts...)
+}
+
+// Read reads a sheet of the workbook held by the size bytes of r into a DataFrame.
+func Read(mem memory.Allocator, r io.ReaderAt, size int64, opts ...Option) (*dataframe.DataFrame, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("xlsxio: not an xlsx workbook: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := findSheet(files, cfg.sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	var sst xlsxSharedStrings
+	if err := decodePart(files, "xl/sharedStrings.xml", &sst, true); err != nil {
+		return nil, err
+	}
+	var styles xlsxStyleSheet
+	if err := decodePart(files, "xl/styles.xml", &styles, true); err != nil {
+		return nil, err
+	}
+	var ws xlsxWorksheet
+	if err := decodePart(files, sheetPath, &ws, false); err != nil {
+		return nil, err
+	}
+
+	rows, err := readCells(&ws, &sst, newDateStyles(&styles))
+	if err != nil {
+		return nil, err
+	}
+	return newDataFrame(mem, rows, cfg.header)
+}
+
+// findSheet returns the path of the named sheet, or of the first sheet when name is empty.
+func findSheet(files map[string]*zip.File, name string) (string, error) {
+	var wb xlsxWorkbook
+	if err := decodePart(files, "xl/workbook.xml", &wb, false); err != nil {
+		return "", err
+	}
+	var rels xlsxRelationships
+	if err := decodePart(files, "xl/_rels/workbook.xml.rels", &rels, false); err != nil {
+		return "", err
+	}
+
+	for _, sheet := range wb.Sheets {
+		if name != "" && sheet.Name != name {
+			continue
+		}
+		for _, rel := range rels.Relationships {
+			if rel.ID != sheet.RID {
+				continue
+			}
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+			return path.Join("xl", rel.Target), nil
+		}
+		return "", fmt.Errorf("xlsxio: sheet %q has no part", sheet.Name)
+	}
+	if name == "" {
+		return "", fmt.Errorf("xlsxio: workbook has no sheet")
+	}
+	return "", fmt.Errorf("xlsxio: no sheet named %q", name)
+}
+
+// decodePart decodes the XML part at name into v.
+func decodePart(files map[string]*zip.File, name string, v interface{}, optional bool) error {
+	f, ok := files[name]
+	if !ok {
+		if optional {
+			return nil
+		}
+		return fmt.Errorf("xlsxio: missing part %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("xlsxio: could not decode %s: %w", name, err)
+	}
+	return nil
+}
+
+// dateStyles tells, for each cell style, whether its number format displays a date.
+type dateStyles []bool
+
+func newDateStyles(styles *xlsxStyleSheet) dateStyles {
+	custom := make(map[int]string, len(styles.NumFmts))
+	for _, f := range styles.NumFmts {
+		custom[f.ID] = f.FormatCode
+	}
+	ds := make(dateStyles, len(styles.CellXfs))
+	for i, xf := range styles.CellXfs {
+		id := xf.NumFmtID
+		switch {
+		case id >= 14 && id <= 22, id >= 45 && id <= 47:
+			// built-in date and time formats
+			ds[i] = true
+		default:
+			if code, ok := custom[id]; ok {
+				ds[i] = isDateFormat(code)
+			}
+		}
+	}
+	return ds
+}
+
+func (ds dateStyles) isDate(style int) bool {
+	return style >= 0 && style < len(ds) && ds[style]
+}
+
+// isDateFormat reports whether a number format code displays a date or a time,
+// ignoring quoted literals and bracketed sections such as colors.
+func isDateFormat(code string) bool {
+	var (
+		quoted  bool
+		bracket bool
+	)
+	for _, r := range code {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '[':
+			bracket = true
+		case r == ']':
+			bracket = false
+		case bracket:
+		case strings.ContainsRune("dDmMyYhHsS", r):
+			return true
+		}
+	}
+	return false
+}
+
+// readCells returns the cells of the sheet row by row. Missing rows are empty
+// and every row is padded to the same width.
+func readCells(ws *xlsxWorksheet, sst *xlsxSharedStrings, ds dateStyles) ([][]cell, error) {
+	var (
+		rows  [][]cell
+		width int
+	)
+	for _, xr := range ws.Rows {
+		if xr.R > 0 {
+			for len(rows) < xr.R-1 {
+				rows = append(rows, nil)
+			}
+		}
+		var row []cell
+		for _, xc := range xr.Cells {
+			col := len(row)
+			if xc.R != "" {
+				var err error
+				if col, err = columnIndex(xc.R); err != nil {
+					return nil, err
+				}
+			}
+			c, err := parseCell(&xc, sst, ds)
+			if err != nil {
+				return nil, err
+			}
+			for len(row) <= col {
+				row = append(row, cell{})
+			}
+			row[col] = c
+		}
+		if len(row) > width {
+			width = len(row)
+		}
+		rows = append(rows, row)
+	}
+	for i := range rows {
+		for len(rows[i]) < width {
+			rows[i] = append(rows[i], cell{})
+		}
+	}
+	return rows, nil
+}
+
+func parseCell(xc *xlsxCell, sst *xlsxSharedStrings, ds dateStyles) (cell, error) {
+	switch xc.T {
+	case "s":
+		i, err := strconv.Atoi(xc.V)
+		if err != nil || i < 0 || i >= len(sst.Items) {
+			return cell{}, fmt.Errorf("xlsxio: cell %s: invalid shared string %q", xc.R, xc.V)
+		}
+		return textOrEmpty(sst.Items[i].String()), nil
+	case "inlineStr":
+		return textOrEmpty(xc.Inline.String()), nil
+	case "str", "e":
+		return textOrEmpty(xc.V), nil
+	case "b":
+		return cell{kind: boolCell, num: map[bool]float64{true: 1}[xc.V == "1"]}, nil
+	default:
+		if xc.V == "" {
+			return cell{}, nil
+		}
+		v, err := strconv.ParseFloat(xc.V, 64)
+		if err != nil {
+			return cell{}, fmt.Errorf("xlsxio: cell %s: invalid number %q", xc.R, xc.V)
+		}
+		if ds.isDate(xc.S) {
+			return cell{kind: dateCell, num: v}, nil
+		}
+		return cell{kind: numberCell, num: v}, nil
+	}
+}
+
+func textOrEmpty(s string) cell {
+	if s == "" {
+		return cell{}
+	}
+	return cell{kind: textCell, text: s}
+}
+
+// columnIndex returns the zero based column of a cell reference such as "AB12".
+func columnIndex(ref string) (int, error) {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		n++
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("xlsxio: invalid cell reference %q", ref)
+	}
+	return col - 1, nil
+}
+
+// columnName returns the letters of the zero based column i.
+func columnName(i int) string {
+	var b []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		b = append([]byte{byte('A' + (i-1)%26)}, b...)
+	}
+	return string(b)
+}
+
+// excelEpoch is day 0 of the Excel 1900 date system, accounting for its
+// fictitious 1900-02-29.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+func serialToTime(serial float64) time.Time {
+	ms := math.Round(serial * 86400 * 1000)
+	return excelEpoch.Add(time.Duration(ms) * time.Millisecond)
+}
+
+func timeToSerial(t time.Time) float64 {
+	return float64(t.Sub(excelEpoch)) / float64(24*time.Hour)
+}
+
+// newDataFrame builds a DataFrame with one column per sheet column.
+func newDataFrame(mem memory.Allocator, rows [][]cell, header bool) (*dataframe.DataFrame, error) {
+	var names []string
+	width := 0
+	if len(rows) > 0 {
+		width = len(rows[0])
+	}
+	for i := 0; i < width; i++ {
+		name := columnName(i)
+		if header && rows[0][i].kind != emptyCell {
+			name = cellText(rows[0][i])
+		}
+		names = append(names, name)
+	}
+	if header && len(rows) > 0 {
+		rows = rows[1:]
+	}
+
+	fields := make([]arrow.Field, width)
+	arrs := make([]array.Interface, width)
+	defer func() {
+		for _, arr := range arrs {
+			if arr != nil {
+				arr.Release()
+			}
+		}
+	}()
+	for i := range fields {
+		arr := buildColumn(mem, rows, i)
+		fields[i] = arrow.Field{Name: names[i], Type: arr.DataType(), Nullable: true}
+		arrs[i] = arr
+	}
+
+	return dataframe.NewDataFrame(mem, arrow.NewSchema(fields, nil), arrs)
+}
+
+// buildColumn builds the array of column i, using the narrowest type holding every cell.
+func buildColumn(mem memory.Allocator, rows [][]cell, i int) array.Interface {
+	kind := emptyCell
+	integral, wholeDays := true, true
+	for _, row := range rows {
+		c := row[i]
+		switch {
+		case c.kind == emptyCell:
+			continue
+		case kind == emptyCell:
+			kind = c.kind
+		case kind != c.kind:
+			kind = textCell
+		}
+		if c.num != math.Trunc(c.num) || math.Abs(c.num) > 1<<53 {
+			integral, wholeDays = false, false
+		}
+	}
+
+	var bldr array.Builder
+	var appendCell func(c cell)
+	switch kind {
+	case numberCell:
+		if integral {
+			b := array.NewInt64Builder(mem)
+			bldr, appendCell = b, func(c cell) { b.Append(int64(c.num)) }
+		} else {
+			b := array.NewFloat64Builder(mem)
+			bldr, appendCell = b, func(c cell) { b.Append(c.num) }
+		}
+	case dateCell:
+		if wholeDays {
+			b := array.NewDate32Builder(mem)
+			bldr, appendCell = b, func(c cell) {
+				b.Append(arrow.Date32(serialToTime(c.num).Unix() / 86400))
+			}
+		} else {
+			b := array.NewTimestampBuilder(mem, &arrow.TimestampType{Unit: arrow.Millisecond})
+			bldr, appendCell = b, func(c cell) {
+				b.Append(arrow.Timestamp(serialToTime(c.num).UnixNano() / int64(time.Millisecond)))
+			}
+		}
+	case boolCell:
+		b := array.NewBooleanBuilder(mem)
+		bldr, appendCell = b, func(c cell) { b.Append(c.num != 0) }
+	default:
+		b := array.NewStringBuilder(mem)
+		bldr, appendCell = b, func(c cell) { b.Append(cellText(c)) }
+	}
+	defer bldr.Release()
+
+	bldr.Reserve(len(rows))
+	for _, row := range rows {
+		if row[i].kind == emptyCell {
+			bldr.AppendNull()
+			continue
+		}
+		appendCell(row[i])
+	}
+	return bldr.NewArray()
+}
+
+// cellText returns the text displayed for a cell in a text column.
+func cellText(c cell) string {
+	switch c.kind {
+	case numberCell:
+		return strconv.FormatFloat(c.num, 'f', -1, 64)
+	case dateCell:
+		t := serialToTime(c.num)
+		if c.num == math.Trunc(c.num) {
+			return t.Format("2006-01-02")
+		}
+		return t.Format("2006-01-02 15:04:05")
+	case boolCell:
+		if c.num != 0 {
+			return "TRUE"
+		}
+		return "FALSE"
+	default:
+		return c.text
+	}
+}
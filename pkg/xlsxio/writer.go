@@ -0,0 +1,292 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xlsxio
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+// Cell styles of the written workbook, indices into the cellXfs of stylesXML.
+const (
+	dateStyle     = 1
+	datetimeStyle = 2
+)
+
+const contentTypesXML = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const rootRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="` + nsRelationships + `/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const workbookRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="` + nsRelationships + `/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="` + nsRelationships + `/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+const stylesXML = xml.Header + `<styleSheet xmlns="` + nsMain + `">` +
+	`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="1"><fill><patternFill patternType="none"/></fill></fills>` +
+	`<borders count="1"><border/></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="22" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`</styleSheet>`
+
+// WriteFile writes df to a new workbook at filename.
+func WriteFile(filename string, df *dataframe.DataFrame, opts ...Option) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := Write(f, df, opts...); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write writes df to w as a workbook with a single sheet. The first row
+// holds the column names unless WithHeader(false) is given.
+//
+// Numbers and booleans are written as such, strings as inline strings and
+// dates and timestamps as serials with a date format. Nulls are left empty.
+func Write(w io.Writer, df *dataframe.DataFrame, opts ...Option) error {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return err
+	}
+	sheet := cfg.sheet
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+
+	cols := df.Columns()
+	writers := make([]cellWriter, len(cols))
+	for i := range cols {
+		if writers[i], err = newCellWriter(cols[i].DataType()); err != nil {
+			return fmt.Errorf("xlsxio: column %q: %w", cols[i].Name(), err)
+		}
+	}
+
+	zw := zip.NewWriter(w)
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", workbookXML(sheet)},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML},
+		{"xl/styles.xml", stylesXML},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if err := writeSheet(f, df, writers, cfg.header); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func workbookXML(sheet string) string {
+	return xml.Header + `<workbook xmlns="` + nsMain + `" xmlns:r="` + nsRelationships + `">` +
+		`<sheets><sheet name="` + escape(sheet) + `" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`
+}
+
+func writeSheet(w io.Writer, df *dataframe.DataFrame, writers []cellWriter, header bool) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xml.Header + `<worksheet xmlns="` + nsMain + `"><sheetData>`)
+
+	row := 1
+	if header {
+		fmt.Fprintf(bw, `<row r="%d">`, row)
+		for i, name := range df.ColumnNames() {
+			fmt.Fprintf(bw, `<c r="%s%d" t="inlineStr"><is><t>%s</t></is></c>`, columnName(i), row, escape(name))
+		}
+		bw.WriteString(`</row>`)
+		row++
+	}
+
+	cols := df.Columns()
+	cursors := make([]*chunkCursor, len(cols))
+	for i := range cols {
+		cursors[i] = &chunkCursor{chunks: cols[i].Data().Chunks()}
+	}
+	for n := int64(0); n < df.NumRows(); n++ {
+		fmt.Fprintf(bw, `<row r="%d">`, row)
+		for i, cur := range cursors {
+			arr, j := cur.next()
+			if arr.IsNull(j) {
+				continue
+			}
+			writers[i](bw, columnName(i)+strconv.Itoa(row), arr, j)
+		}
+		bw.WriteString(`</row>`)
+		row++
+	}
+
+	bw.WriteString(`</sheetData></worksheet>`)
+	return bw.Flush()
+}
+
+// chunkCursor walks the rows of a chunked column in order.
+type chunkCursor struct {
+	chunks []array.Interface
+	chunk  int
+	index  int
+}
+
+func (c *chunkCursor) next() (array.Interface, int) {
+	for c.index >= c.chunks[c.chunk].Len() {
+		c.chunk++
+		c.index = 0
+	}
+	i := c.index
+	c.index++
+	return c.chunks[c.chunk], i
+}
+
+// cellWriter writes the non-null element i of arr as the cell ref.
+type cellWriter func(w *bufio.Writer, ref string, arr array.Interface, i int)
+
+func newCellWriter(dtype arrow.DataType) (cellWriter, error) {
+	number := func(f func(arr array.Interface, i int) string) cellWriter {
+		return func(w *bufio.Writer, ref string, arr array.Interface, i int) {
+			fmt.Fprintf(w, `<c r="%s"><v>%s</v></c>`, ref, f(arr, i))
+		}
+	}
+	date := func(style int, f func(arr array.Interface, i int) time.Time) cellWriter {
+		return func(w *bufio.Writer, ref string, arr array.Interface, i int) {
+			serial := strconv.FormatFloat(timeToSerial(f(arr, i)), 'f', -1, 64)
+			fmt.Fprintf(w, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, serial)
+		}
+	}
+
+	switch dtype := dtype.(type) {
+	case *arrow.Int8Type:
+		return number(func(arr array.Interface, i int) string {
+			return strconv.FormatInt(int64(arr.(*array.Int8).Value(i)), 10)
+		}), nil
+	case *arrow.Int16Type:
+		return number(func(arr array.Interface, i int) string {
+			return strconv.FormatInt(int64(arr.(*array.Int16).Value(i)), 10)
+		}), nil
+	case *arrow.Int32Type:
+		return number(func(arr array.Interface, i int) string {
+			return strconv.FormatInt(int64(arr.(*array.Int32).Value(i)), 10)
+		}), nil
+	case *arrow.Int64Type:
+		return number(func(arr array.Interface, i int) string {
+			return strconv.FormatInt(arr.(*array.Int64).Value(i), 10)
+		}), nil
+	case *arrow.Uint8Type:
+		return number(func(arr array.Interface, i int) string {
+			return strconv.FormatUint(uint64(arr.(*array.Uint8).Value(i)), 10)
+		}), nil
+	case *arrow.Uint16Type:
+		return number(func(arr array.Interface, i int) string {
+			return strconv.FormatUint(uint64(arr.(*array.Uint16).Value(i)), 10)
+		}), nil
+	case *arrow.Uint32Type:
+		return number(func(arr array.Interface, i int) string {
+			return strconv.FormatUint(uint64(arr.(*array.Uint32).Value(i)), 10)
+		}), nil
+	case *arrow.Uint64Type:
+		return number(func(arr array.Interface, i int) string {
+			return strconv.FormatUint(arr.(*array.Uint64).Value(i), 10)
+		}), nil
+	case *arrow.Float32Type:
+		return number(func(arr array.Interface, i int) string {
+			return strconv.FormatFloat(float64(arr.(*array.Float32).Value(i)), 'g', -1, 32)
+		}), nil
+	case *arrow.Float64Type:
+		return number(func(arr array.Interface, i int) string {
+			return strconv.FormatFloat(arr.(*array.Float64).Value(i), 'g', -1, 64)
+		}), nil
+	case *arrow.BooleanType:
+		return func(w *bufio.Writer, ref string, arr array.Interface, i int) {
+			v := "0"
+			if arr.(*array.Boolean).Value(i) {
+				v = "1"
+			}
+			fmt.Fprintf(w, `<c r="%s" t="b"><v>%s</v></c>`, ref, v)
+		}, nil
+	case *arrow.StringType:
+		return func(w *bufio.Writer, ref string, arr array.Interface, i int) {
+			fmt.Fprintf(w, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`,
+				ref, escape(arr.(*array.String).Value(i)))
+		}, nil
+	case *arrow.Date32Type:
+		return date(dateStyle, func(arr array.Interface, i int) time.Time {
+			return time.Unix(int64(arr.(*array.Date32).Value(i))*86400, 0).UTC()
+		}), nil
+	case *arrow.Date64Type:
+		return date(dateStyle, func(arr array.Interface, i int) time.Time {
+			return time.Unix(0, int64(arr.(*array.Date64).Value(i))*int64(time.Millisecond)).UTC()
+		}), nil
+	case *arrow.TimestampType:
+		scale := int64(time.Nanosecond)
+		switch dtype.Unit {
+		case arrow.Second:
+			scale = int64(time.Second)
+		case arrow.Millisecond:
+			scale = int64(time.Millisecond)
+		case arrow.Microsecond:
+			scale = int64(time.Microsecond)
+		}
+		return date(datetimeStyle, func(arr array.Interface, i int) time.Time {
+			return time.Unix(0, int64(arr.(*array.Timestamp).Value(i))*scale).UTC()
+		}), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", dtype)
+	}
+}
+
+// escape returns s with the XML special characters escaped.
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,156 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xlsxio
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gomem/gomem/arrow/go/arrow"
+	"github.com/gomem/gomem/arrow/go/arrow/array"
+	"github.com/gomem/gomem/arrow/go/arrow/memory"
+	"github.com/gomem/gomem/pkg/dataframe"
+)
+
+func newTestDataFrame(t *testing.T, mem memory.Allocator) *dataframe.DataFrame {
+	t.Helper()
+
+	ints := array.NewInt64Builder(mem)
+	defer ints.Release()
+	ints.AppendValues([]int64{1, 2, 3}, []bool{true, false, true})
+
+	floats := array.NewFloat64Builder(mem)
+	defer floats.Release()
+	floats.AppendValues([]float64{1.5, -2, 0.25}, nil)
+
+	strs := array.NewStringBuilder(mem)
+	defer strs.Release()
+	strs.AppendValues([]string{"a", "<b & c>", ""}, []bool{true, true, false})
+
+	bools := array.NewBooleanBuilder(mem)
+	defer bools.Release()
+	bools.AppendValues([]bool{true, false, true}, nil)
+
+	dates := array.NewDate32Builder(mem)
+	defer dates.Release()
+	dates.AppendValues([]arrow.Date32{18262, 18263, -1}, nil)
+
+	tsType := &arrow.TimestampType{Unit: arrow.Millisecond}
+	stamps := array.NewTimestampBuilder(mem, tsType)
+	defer stamps.Release()
+	stamps.AppendValues([]arrow.Timestamp{1577880000123, 1577883600000, 0}, nil)
+
+	arrs := []array.Interface{ints.NewArray(), floats.NewArray(), strs.NewArray(), bools.NewArray(), dates.NewArray(), stamps.NewArray()}
+	defer func() {
+		for _, arr := range arrs {
+			arr.Release()
+		}
+	}()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "int", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "float", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "string", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "bool", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+		{Name: "date", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+		{Name: "timestamp", Type: tsType, Nullable: true},
+	}, nil)
+
+	df, err := dataframe.NewDataFrame(mem, schema, arrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return df
+}
+
+func TestRoundTrip(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df := newTestDataFrame(t, pool)
+	defer df.Release()
+
+	var buf bytes.Buffer
+	if err := Write(&buf, df, WithSheet("data")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Read(pool, bytes.NewReader(buf.Bytes()), int64(buf.Len()), WithSheet("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Release()
+
+	if !got.Schema().Equal(df.Schema()) {
+		t.Fatalf("got schema=\n%v\nwant=\n%v", got.Schema(), df.Schema())
+	}
+	if got, want := got.Display(0), df.Display(0); got != want {
+		t.Fatalf("got=\n%s\nwant=\n%s", got, want)
+	}
+
+	if _, err := Read(pool, bytes.NewReader(buf.Bytes()), int64(buf.Len()), WithSheet("missing")); err == nil {
+		t.Fatal("expected an error for a missing sheet")
+	}
+}
+
+func TestReadWithoutHeader(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	df := newTestDataFrame(t, pool)
+	defer df.Release()
+
+	var buf bytes.Buffer
+	if err := Write(&buf, df, WithHeader(false)); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Read(pool, bytes.NewReader(buf.Bytes()), int64(buf.Len()), WithHeader(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Release()
+
+	names := got.ColumnNames()
+	want := []string{"A", "B", "C", "D", "E", "F"}
+	if len(names) != len(want) {
+		t.Fatalf("got columns %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("got columns %v, want %v", names, want)
+		}
+	}
+	if got.NumRows() != 3 {
+		t.Fatalf("got %d rows, want 3", got.NumRows())
+	}
+}
+
+func TestIsDateFormat(t *testing.T) {
+	for _, tc := range []struct {
+		code string
+		want bool
+	}{
+		{"yyyy-mm-dd", true},
+		{"h:mm AM/PM", true},
+		{"0.00", false},
+		{`[Red]0.00`, false},
+		{`"Day "0`, false},
+		{"#,##0", false},
+	} {
+		if got := isDateFormat(tc.code); got != tc.want {
+			t.Errorf("isDateFormat(%q) = %v, want %v", tc.code, got, tc.want)
+		}
+	}
+}
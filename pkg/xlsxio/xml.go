@@ -0,0 +1,99 @@
+// Copyright 2019 Nick Poorman
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xlsxio
+
+import "encoding/xml"
+
+// The subset of the SpreadsheetML parts read and written by this package.
+
+const (
+	nsMain          = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	nsRelationships = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+)
+
+type xlsxWorkbook struct {
+	Sheets []xlsxSheet `xml:"sheets>sheet"`
+}
+
+type xlsxSheet struct {
+	Name string `xml:"name,attr"`
+	RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+}
+
+type xlsxRelationships struct {
+	Relationships []xlsxRelationship `xml:"Relationship"`
+}
+
+type xlsxRelationship struct {
+	ID     string `xml:"Id,attr"`
+	Target string `xml:"Target,attr"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+// xlsxRichText is either plain text or a list of formatted runs.
+type xlsxRichText struct {
+	Text string        `xml:"t"`
+	Runs []xlsxRichRun `xml:"r"`
+}
+
+type xlsxRichRun struct {
+	Text string `xml:"t"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	s := t.Text
+	for _, r := range t.Runs {
+		s += r.Text
+	}
+	return s
+}
+
+type xlsxStyleSheet struct {
+	NumFmts []xlsxNumFmt `xml:"numFmts>numFmt"`
+	CellXfs []xlsxXf     `xml:"cellXfs>xf"`
+}
+
+type xlsxNumFmt struct {
+	ID         int    `xml:"numFmtId,attr"`
+	FormatCode string `xml:"formatCode,attr"`
+}
+
+type xlsxXf struct {
+	NumFmtID int `xml:"numFmtId,attr"`
+}
+
+type xlsxWorksheet struct {
+	XMLName xml.Name  `xml:"worksheet"`
+	Rows    []xlsxRow `xml:"sheetData>row"`
+}
+
+type xlsxRow struct {
+	R     int        `xml:"r,attr"`
+	Cells []xlsxCell `xml:"c"`
+}
+
+type xlsxCell struct {
+	R      string       `xml:"r,attr"`
+	T      string       `xml:"t,attr"`
+	S      int          `xml:"s,attr"`
+	V      string       `xml:"v"`
+	Inline xlsxRichText `xml:"is"`
+}